@@ -0,0 +1,115 @@
+//! Representative corpora for the throughput benchmark and its regression test.
+//!
+//! Every corpus is generated from fixed patterns (no randomness), so a given
+//! `target_bytes` always yields the same input on every machine and results can be
+//! compared across runs and commits.
+
+use jsonrepair::options::EngineKind;
+use jsonrepair::{Options, StrayTokenPolicy};
+
+/// Default corpus size: 1 MiB.
+pub const CORPUS_BYTES: usize = 1 << 20;
+
+/// Options the corpora are repaired with on `engine`: bare words in arrays (`huge_array`)
+/// are quoted, as Python json_repair does, instead of failing the repair.
+pub fn options(engine: EngineKind) -> Options {
+    Options {
+        engine,
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Options::default()
+    }
+}
+
+/// Names of the corpora, in the order `corpus` and the thresholds file use.
+pub const NAMES: [&str; 4] = ["clean", "broken", "huge_array", "deep_nested"];
+
+/// Build the corpus `name` with at least `target_bytes` bytes.
+pub fn corpus(name: &str, target_bytes: usize) -> String {
+    match name {
+        "clean" => clean(target_bytes),
+        "broken" => broken(target_bytes),
+        "huge_array" => huge_array(target_bytes),
+        "deep_nested" => deep_nested(target_bytes),
+        _ => panic!("unknown corpus {name:?}"),
+    }
+}
+
+// Valid JSON: an array of API-style records. Takes the valid-JSON fast path.
+fn clean(target_bytes: usize) -> String {
+    let mut s = String::from("[");
+    let mut i = 0usize;
+    while s.len() < target_bytes {
+        if i > 0 {
+            s.push(',');
+        }
+        s.push_str(&format!(
+            r#"{{"id":{i},"name":"user {i}","email":"user{i}@example.com","active":{},"score":{}.{},"tags":["a","b{}"],"note":null}}"#,
+            i % 3 == 0,
+            i % 100,
+            i % 7,
+            i % 10
+        ));
+        i += 1;
+    }
+    s.push(']');
+    s
+}
+
+// The same records the way LLMs and hand-edited configs break them: unquoted keys,
+// single quotes, Python keywords, comments, trailing and missing commas, and no closer.
+fn broken(target_bytes: usize) -> String {
+    let mut s = String::from("[ // records\n");
+    let mut i = 0usize;
+    while s.len() < target_bytes {
+        s.push_str(&format!(
+            "{{id: {i}, name: 'user {i}', email: \"user{i}@example.com\" active: {}, /* score */ score: {}.{}, tags: ['a', 'b{}',], note: None,}},\n",
+            if i % 3 == 0 { "True" } else { "False" },
+            i % 100,
+            i % 7,
+            i % 10
+        ));
+        i += 1;
+    }
+    s
+}
+
+// One flat array of numbers, bare words and strings, with a trailing comma and no `]`.
+fn huge_array(target_bytes: usize) -> String {
+    let mut s = String::from("[");
+    let mut i = 0usize;
+    while s.len() < target_bytes {
+        match i % 3 {
+            0 => s.push_str(&i.to_string()),
+            1 => s.push_str(&format!("item{i}")),
+            _ => s.push_str(&format!("\"s{i}\"")),
+        }
+        s.push_str(", ");
+        i += 1;
+    }
+    s
+}
+
+// An unclosed array of values nested 100 levels deep through alternating objects and
+// arrays, separated by newlines only, with unquoted keys, a single-quoted leaf and trailing
+// commas on the way out.
+fn deep_nested(target_bytes: usize) -> String {
+    const DEPTH: usize = 100;
+    let mut doc = String::new();
+    for level in 0..DEPTH {
+        if level % 2 == 0 {
+            doc.push_str(&format!("{{k{level}: "));
+        } else {
+            doc.push('[');
+        }
+    }
+    doc.push_str("'leaf'");
+    for level in (0..DEPTH).rev() {
+        doc.push_str(if level % 2 == 0 { ",}" } else { ",]" });
+    }
+    doc.push('\n');
+    let mut s = String::from("[");
+    while s.len() < target_bytes {
+        s.push_str(&doc);
+    }
+    s
+}
@@ -0,0 +1,57 @@
+use criterion::{Criterion, SamplingMode, Throughput, criterion_group, criterion_main};
+use jsonrepair::{Options, repair_extract, repair_to_string};
+use std::time::Duration;
+
+// A small header followed by a large list of records, as in a tool response where only the
+// status is wanted.
+fn gen_input(records: usize) -> String {
+    let mut s = String::from("{status: 'ok', meta: {page: 1, total: ");
+    s.push_str(&records.to_string());
+    s.push_str("}, items: [\n");
+    for i in 0..records {
+        s.push_str(&format!(
+            "  {{id: {i}, tags: ['a', 'b'], note: 'record {i}',}},\n"
+        ));
+    }
+    s.push_str("]}");
+    s
+}
+
+fn bench_extract(c: &mut Criterion) {
+    let input = gen_input(20_000);
+    let opts = Options::default();
+    let last = format!("/items/{}/note", 20_000 - 1);
+
+    let mut g = c.benchmark_group("extract_large_document");
+    g.sampling_mode(SamplingMode::Flat);
+    g.sample_size(10);
+    g.measurement_time(Duration::from_secs(4));
+    g.throughput(Throughput::Bytes(input.len() as u64));
+
+    // What extracting cost before: the whole document repaired, then the target sliced out.
+    g.bench_function("whole_repair", |b| {
+        b.iter(|| {
+            let s = repair_to_string(std::hint::black_box(&input), &opts).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.bench_function("extract_near_start", |b| {
+        b.iter(|| {
+            let s = repair_extract(std::hint::black_box(&input), "/meta/total", &opts).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.bench_function("extract_at_end", |b| {
+        b.iter(|| {
+            let s = repair_extract(std::hint::black_box(&input), &last, &opts).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.finish();
+}
+
+criterion_group!(benches, bench_extract);
+criterion_main!(benches);
@@ -0,0 +1,56 @@
+use criterion::{Criterion, SamplingMode, Throughput, criterion_group, criterion_main};
+use jsonrepair::{Options, repair_first, repair_to_string};
+use std::time::Duration;
+
+// A small first value followed by a large remainder of further records, as in a protocol
+// where only the first value is wanted.
+fn gen_input(records: usize) -> String {
+    let mut s = String::from("{id: 1, op: 'ping'}\n");
+    for i in 0..records {
+        s.push_str(&format!(
+            "{{id: {i}, tags: ['a', 'b'], note: 'record {i}'}}\n"
+        ));
+    }
+    s
+}
+
+fn bench_first_value(c: &mut Criterion) {
+    let input = gen_input(20_000);
+    let all = Options::default();
+    let first = Options {
+        stop_after_first: true,
+        ..Default::default()
+    };
+
+    let mut g = c.benchmark_group("first_value_large_remainder");
+    g.sampling_mode(SamplingMode::Flat);
+    g.sample_size(10);
+    g.measurement_time(Duration::from_secs(4));
+    g.throughput(Throughput::Bytes(input.len() as u64));
+
+    g.bench_function("whole_input", |b| {
+        b.iter(|| {
+            let s = repair_to_string(std::hint::black_box(&input), &all).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.bench_function("repair_first", |b| {
+        b.iter(|| {
+            let s = repair_first(std::hint::black_box(&input), &all).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.bench_function("stop_after_first", |b| {
+        b.iter(|| {
+            let s = repair_to_string(std::hint::black_box(&input), &first).unwrap();
+            std::hint::black_box(s);
+        })
+    });
+
+    g.finish();
+}
+
+criterion_group!(benches, bench_first_value);
+criterion_main!(benches);
@@ -0,0 +1,75 @@
+use criterion::{
+    BenchmarkId, Criterion, SamplingMode, Throughput, criterion_group, criterion_main,
+};
+use jsonrepair::options::EngineKind;
+use jsonrepair::{Options, repair_to_string};
+use std::time::Duration;
+
+fn engines() -> Vec<(&'static str, EngineKind)> {
+    let mut engines = vec![("recursive", EngineKind::Recursive)];
+    if cfg!(feature = "llm-compat") {
+        engines.push(("llm", EngineKind::LlmCompat));
+    }
+    engines
+}
+
+// Arrays and objects nested `depth` levels deep, alternating, where every container ends
+// in a trailing comma: `[1, {k: [1, ... 0 ..., 2,], b: 3,}, 2,]`.
+fn gen_trailing(depth: usize) -> String {
+    let mut s = String::new();
+    for i in 0..depth {
+        s.push_str(if i % 2 == 0 { "[1, " } else { "{k: " });
+    }
+    s.push('0');
+    for i in (0..depth).rev() {
+        s.push_str(if i % 2 == 0 { ", 2,]" } else { ", b: 3,}" });
+    }
+    s
+}
+
+// The same nesting with every comma missing: `[1 {"k": [1 ... 0 ... 2] "b": 3} 2]`.
+fn gen_missing(depth: usize) -> String {
+    let mut s = String::new();
+    for i in 0..depth {
+        s.push_str(if i % 2 == 0 { "[1 " } else { "{\"k\": " });
+    }
+    s.push('0');
+    for i in (0..depth).rev() {
+        s.push_str(if i % 2 == 0 { " 2]" } else { " \"b\": 3}" });
+    }
+    s
+}
+
+// Throughput per depth: with linear comma handling the MiB/s stay flat as depth grows.
+fn bench_nested_commas(c: &mut Criterion) {
+    let mut g = c.benchmark_group("nested_commas");
+    g.sampling_mode(SamplingMode::Flat);
+    g.sample_size(10);
+    g.measurement_time(Duration::from_secs(3));
+
+    for depth in [1_000usize, 4_000, 16_000, 64_000] {
+        for (shape, input) in [
+            ("trailing", gen_trailing(depth)),
+            ("missing", gen_missing(depth)),
+        ] {
+            g.throughput(Throughput::Bytes(input.len() as u64));
+            for (engine_name, engine) in engines() {
+                let opts = Options {
+                    engine,
+                    ..Options::default()
+                };
+                let id = BenchmarkId::new(format!("{shape}/{engine_name}"), depth);
+                g.bench_with_input(id, &input, |b, s| {
+                    b.iter(|| {
+                        let out = repair_to_string(std::hint::black_box(s), &opts).unwrap();
+                        std::hint::black_box(out);
+                    })
+                });
+            }
+        }
+    }
+    g.finish();
+}
+
+criterion_group!(benches, bench_nested_commas);
+criterion_main!(benches);
@@ -0,0 +1,69 @@
+use criterion::{
+    BenchmarkId, Criterion, SamplingMode, Throughput, criterion_group, criterion_main,
+};
+use jsonrepair::options::EngineKind;
+use jsonrepair::repair_to_string;
+use std::env;
+use std::time::Duration;
+
+mod corpus;
+
+fn engines() -> Vec<(&'static str, EngineKind)> {
+    let mut engines = vec![("recursive", EngineKind::Recursive)];
+    if cfg!(feature = "llm-compat") {
+        engines.push(("llm", EngineKind::LlmCompat));
+    }
+    engines
+}
+
+fn throughput_bench(c: &mut Criterion) {
+    let mut group = c.benchmark_group("throughput");
+    group.sampling_mode(SamplingMode::Flat);
+    if let Some(ss) = env::var("JR_SAMPLE_SIZE")
+        .ok()
+        .and_then(|v| v.parse::<usize>().ok())
+    {
+        group.sample_size(ss.max(1));
+    } else {
+        group.sample_size(10);
+    }
+    if let Some(meas) = env::var("JR_MEAS_SEC")
+        .ok()
+        .and_then(|v| v.parse::<u64>().ok())
+    {
+        group.measurement_time(Duration::from_secs(meas));
+    } else {
+        group.measurement_time(Duration::from_secs(6));
+    }
+    if let Some(warm) = env::var("JR_WARMUP_SEC")
+        .ok()
+        .and_then(|v| v.parse::<u64>().ok())
+    {
+        group.warm_up_time(Duration::from_secs(warm));
+    } else {
+        group.warm_up_time(Duration::from_secs(2));
+    }
+    let bytes = env::var("JR_MIN_BYTES")
+        .ok()
+        .and_then(|v| v.parse::<usize>().ok())
+        .unwrap_or(corpus::CORPUS_BYTES);
+
+    for name in corpus::NAMES {
+        let input = corpus::corpus(name, bytes);
+        group.throughput(Throughput::Bytes(input.len() as u64));
+        for (engine_name, engine) in engines() {
+            let opts = corpus::options(engine);
+            group.bench_with_input(BenchmarkId::new(name, engine_name), &input, |b, s| {
+                b.iter(|| {
+                    let out = repair_to_string(s, &opts).unwrap();
+                    std::hint::black_box(out);
+                })
+            });
+        }
+    }
+
+    group.finish();
+}
+
+criterion_group!(benches, throughput_bench);
+criterion_main!(benches);
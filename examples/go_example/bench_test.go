@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The corpora of benches/corpus in the Rust crate, built from the same fixed
+// patterns, so a Go result can be set against `cargo bench --bench
+// throughput_bench` on the same machine: the difference is the binding.
+const corpusBytes = 1 << 20
+
+var corpusNames = []string{"clean", "broken", "huge_array", "deep_nested"}
+
+func corpus(name string, targetBytes int) string {
+	var s strings.Builder
+	switch name {
+	case "clean":
+		// Valid JSON: an array of API-style records.
+		s.WriteString("[")
+		for i := 0; s.Len() < targetBytes; i++ {
+			if i > 0 {
+				s.WriteString(",")
+			}
+			fmt.Fprintf(&s, `{"id":%d,"name":"user %d","email":"user%d@example.com","active":%t,"score":%d.%d,"tags":["a","b%d"],"note":null}`,
+				i, i, i, i%3 == 0, i%100, i%7, i%10)
+		}
+		s.WriteString("]")
+	case "broken":
+		// The same records with unquoted keys, single quotes, Python keywords,
+		// comments, trailing and missing commas, and no closer.
+		s.WriteString("[ // records\n")
+		for i := 0; s.Len() < targetBytes; i++ {
+			active := "False"
+			if i%3 == 0 {
+				active = "True"
+			}
+			fmt.Fprintf(&s, "{id: %d, name: 'user %d', email: \"user%d@example.com\" active: %s, /* score */ score: %d.%d, tags: ['a', 'b%d',], note: None,},\n",
+				i, i, i, active, i%100, i%7, i%10)
+		}
+	case "huge_array":
+		// One flat array of numbers, bare words and strings, unclosed.
+		s.WriteString("[")
+		for i := 0; s.Len() < targetBytes; i++ {
+			switch i % 3 {
+			case 0:
+				s.WriteString(strconv.Itoa(i))
+			case 1:
+				fmt.Fprintf(&s, "item%d", i)
+			default:
+				fmt.Fprintf(&s, `"s%d"`, i)
+			}
+			s.WriteString(", ")
+		}
+	case "deep_nested":
+		// Values nested 100 levels deep through alternating objects and arrays.
+		const depth = 100
+		var doc strings.Builder
+		for level := 0; level < depth; level++ {
+			if level%2 == 0 {
+				fmt.Fprintf(&doc, "{k%d: ", level)
+			} else {
+				doc.WriteString("[")
+			}
+		}
+		doc.WriteString("'leaf'")
+		for level := depth - 1; level >= 0; level-- {
+			if level%2 == 0 {
+				doc.WriteString(",}")
+			} else {
+				doc.WriteString(",]")
+			}
+		}
+		doc.WriteString("\n")
+		s.WriteString("[")
+		for s.Len() < targetBytes {
+			s.WriteString(doc.String())
+		}
+	default:
+		panic("unknown corpus " + name)
+	}
+	return s.String()
+}
+
+// BenchmarkRepairCorpora repairs each corpus whole, as throughput_bench does
+// with the recursive engine. Bare words in arrays are quoted, as the Rust
+// corpus options have them.
+func BenchmarkRepairCorpora(b *testing.B) {
+	opts := RepairOptions{StrayTokens: StrayQuote}
+	for _, name := range corpusNames {
+		input := corpus(name, corpusBytes)
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				if _, err := Repair(input, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrRepair wraps the repair failure returned by RepairInto. The library
+	// error stays in the chain, so errors.Is(err, ErrTimeout) still works.
+	ErrRepair = errors.New("jsonrepair: repair")
+	// ErrUnmarshal wraps the encoding/json error returned by RepairInto when the
+	// repaired document does not fit T (errors.As reaches *json.UnmarshalTypeError).
+	ErrUnmarshal = errors.New("jsonrepair: unmarshal")
+)
+
+// RepairInto repairs data with default options and unmarshals the result into
+// a new T. On failure it returns the zero T and an error wrapping either
+// ErrRepair or ErrUnmarshal, so callers can tell broken input from a schema
+// mismatch.
+func RepairInto[T any](data []byte) (T, error) {
+	var zero T
+	repaired, err := Repair(string(data), RepairOptions{})
+	if err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrRepair, err)
+	}
+	var v T
+	if err := json.Unmarshal([]byte(repaired), &v); err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	}
+	return v, nil
+}
+
+// Envelope is the output of the Envelope option: the repaired value with
+// whether it differs from the input and how many repairs it took. Value is
+// null for empty input.
+type Envelope struct {
+	Value    json.RawMessage `json:"value"`
+	Repaired bool            `json:"repaired"`
+	Repairs  int             `json:"repairs"`
+}
+
+// RepairEnvelope repairs data with opts and the Envelope option set and
+// decodes the result. Errors wrap ErrRepair or ErrUnmarshal, as with
+// RepairInto.
+func RepairEnvelope(data []byte, opts RepairOptions) (Envelope, error) {
+	opts.Envelope = true
+	out, err := Repair(string(data), opts)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: %w", ErrRepair, err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(bytes.TrimPrefix([]byte(out), []byte("\uFEFF")), &env); err != nil {
+		return Envelope{}, fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	}
+	return env, nil
+}
+
+// OrderedMap is a JSON object that keeps its keys in document order, for
+// configs that are edited and written back. Nested objects decode as
+// *OrderedMap, arrays as []any and numbers as json.Number, so MarshalJSON
+// writes the values back as they were read.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// RepairToOrderedMap repairs data with default options and decodes the root
+// object into an OrderedMap. The repair keeps members in input order. A root
+// that is not an object fails with an error wrapping ErrUnmarshal; a broken
+// input wraps ErrRepair, as with RepairInto.
+//
+// Default options keep every duplicate key (DedupKeepAll). The map lists a
+// duplicate once, at its first position, with its last value, which is what
+// DedupFirstPosition produces. For the DedupLastPosition order, call Repair
+// with that option and json.Unmarshal the result into an OrderedMap.
+func RepairToOrderedMap(data []byte) (*OrderedMap, error) {
+	repaired, err := Repair(string(data), RepairOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRepair, err)
+	}
+	m := &OrderedMap{}
+	if err := m.UnmarshalJSON([]byte(repaired)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	}
+	return m, nil
+}
+
+// Keys returns the keys in document order.
+func (m *OrderedMap) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Get returns the value of key and whether the object has it.
+func (m *OrderedMap) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// UnmarshalJSON reads a JSON object into m, replacing its contents. A
+// duplicate key keeps its first position and takes its last value.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return &json.UnmarshalTypeError{Value: jsonKind(tok), Type: reflect.TypeOf(m)}
+	}
+	return m.decodeMembers(dec)
+}
+
+// MarshalJSON writes the object with its keys in order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeMembers reads the members of an object whose '{' dec has consumed,
+// through its '}'.
+func (m *OrderedMap) decodeMembers(dec *json.Decoder) error {
+	m.keys = nil
+	m.values = map[string]any{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		value, err := decodeOrdered(dec)
+		if err != nil {
+			return err
+		}
+		if _, dup := m.values[key]; !dup {
+			m.keys = append(m.keys, key)
+		}
+		m.values[key] = value
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// decodeOrdered reads the next value, with objects as *OrderedMap.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := &OrderedMap{}
+		return m, m.decodeMembers(dec)
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+// jsonKind names the JSON type of a value's first token, for type errors.
+func jsonKind(tok json.Token) string {
+	switch tok.(type) {
+	case json.Delim:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return "null"
+}
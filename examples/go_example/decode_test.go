@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMapKeepsKeyOrder(t *testing.T) {
+	input := "{z: 1, a: {y: 2, b: 3}, m: [{q: 1, c: 2.50}], k: 'v'}"
+	m, err := RepairToOrderedMap([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"z", "a", "m", "k"}) {
+		t.Errorf("Keys() = %q", keys)
+	}
+	a, _ := m.Get("a")
+	if keys := a.(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"y", "b"}) {
+		t.Errorf("nested Keys() = %q", keys)
+	}
+	list, _ := m.Get("m")
+	if keys := list.([]any)[0].(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"q", "c"}) {
+		t.Errorf("Keys() in array = %q", keys)
+	}
+
+	// Writing back gives the repaired document, numbers as they were spelled.
+	out, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"z":1,"a":{"y":2,"b":3},"m":[{"q":1,"c":2.50}],"k":"v"}`; string(out) != want {
+		t.Errorf("MarshalJSON = %s, want %s", out, want)
+	}
+}
+
+func TestOrderedMapDuplicateKeys(t *testing.T) {
+	// A duplicate is listed once, at its first position, with its last value.
+	m, err := RepairToOrderedMap([]byte("{a: 1, b: 2, a: 3}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %q", keys)
+	}
+	if v, ok := m.Get("a"); !ok || v != json.Number("3") {
+		t.Errorf(`Get("a") = %v, %v; want 3, true`, v, ok)
+	}
+	if _, ok := m.Get("c"); ok {
+		t.Error(`Get("c") reports a missing key as present`)
+	}
+
+	// The DedupLastPosition order comes from repairing with that option.
+	out, err := Repair("{a: 1, b: 2, a: 3}", RepairOptions{DedupPosition: DedupLastPosition})
+	if errors.Is(err, ErrUnsupportedOption) {
+		t.Skip("DedupPosition needs the Rust library")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last OrderedMap
+	if err := json.Unmarshal([]byte(out), &last); err != nil {
+		t.Fatal(err)
+	}
+	if keys := last.Keys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Errorf("DedupLastPosition Keys() = %q", keys)
+	}
+}
+
+func TestRepairToOrderedMapErrors(t *testing.T) {
+	var typeErr *json.UnmarshalTypeError
+	if _, err := RepairToOrderedMap([]byte("[1, 2]")); !errors.Is(err, ErrUnmarshal) || !errors.As(err, &typeErr) {
+		t.Errorf("array root: err = %v, want ErrUnmarshal with *json.UnmarshalTypeError", err)
+	}
+	if _, err := RepairToOrderedMap([]byte("{a: \"\xff\"}")); !errors.Is(err, ErrRepair) || !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("invalid UTF-8: err = %v, want ErrRepair wrapping ErrInvalidUTF8", err)
+	}
+}
+
+func TestRepairInto(t *testing.T) {
+	type config struct {
+		Name  string   `json:"name"`
+		Ports []int    `json:"ports"`
+		Tags  []string `json:"tags"`
+	}
+	got, err := RepairInto[config]([]byte("{name: 'api', ports: [80, 443,], tags: ['a']"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config{Name: "api", Ports: []int{80, 443}, Tags: []string{"a"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RepairInto = %+v, want %+v", got, want)
+	}
+}
+
+func TestRepairIntoErrors(t *testing.T) {
+	type config struct {
+		Name  string `json:"name"`
+		Ports []int  `json:"ports"`
+	}
+	// A schema mismatch returns the zero T, not the partly decoded one.
+	got, err := RepairInto[config]([]byte("{name: 'api', ports: 'x'}"))
+	var typeErr *json.UnmarshalTypeError
+	if !errors.Is(err, ErrUnmarshal) || errors.Is(err, ErrRepair) || !errors.As(err, &typeErr) {
+		t.Errorf("mismatch: err = %v, want ErrUnmarshal with *json.UnmarshalTypeError", err)
+	}
+	if !reflect.DeepEqual(got, config{}) {
+		t.Errorf("mismatch: RepairInto = %+v, want the zero value", got)
+	}
+
+	// Broken input wraps ErrRepair with the library error still in the chain.
+	got, err = RepairInto[config]([]byte("{name: \"\xff\"}"))
+	var re *RepairError
+	if !errors.Is(err, ErrRepair) || errors.Is(err, ErrUnmarshal) || !errors.Is(err, ErrInvalidUTF8) || !errors.As(err, &re) {
+		t.Errorf("invalid UTF-8: err = %v, want ErrRepair wrapping ErrInvalidUTF8", err)
+	}
+	if !reflect.DeepEqual(got, config{}) {
+		t.Errorf("invalid UTF-8: RepairInto = %+v, want the zero value", got)
+	}
+
+	n, err := RepairInto[int]([]byte("[1, 2"))
+	if !errors.Is(err, ErrUnmarshal) || n != 0 {
+		t.Errorf("array into int: RepairInto = %d, %v; want 0 and ErrUnmarshal", n, err)
+	}
+}
+
+func TestRepairEnvelope(t *testing.T) {
+	env, err := RepairEnvelope([]byte("{a: 1"), RepairOptions{})
+	if errors.Is(err, ErrUnsupportedOption) {
+		t.Skip("Envelope needs the Rust library")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(env.Value) != `{"a":1}` || !env.Repaired || env.Repairs == 0 {
+		t.Errorf("RepairEnvelope = %+v", env)
+	}
+	env, err = RepairEnvelope([]byte(`{"a":1}`), RepairOptions{})
+	if err != nil || string(env.Value) != `{"a":1}` || env.Repaired || env.Repairs != 0 {
+		t.Errorf("valid input: RepairEnvelope = %+v, %v", env, err)
+	}
+}
+
+func TestRepairEnvelopeErrors(t *testing.T) {
+	env, err := RepairEnvelope([]byte("{a: \"\xff\"}"), RepairOptions{})
+	if errors.Is(err, ErrUnsupportedOption) {
+		t.Skip("Envelope needs the Rust library")
+	}
+	var re *RepairError
+	if !errors.Is(err, ErrRepair) || errors.Is(err, ErrUnmarshal) || !errors.As(err, &re) {
+		t.Errorf("invalid UTF-8: err = %v, want ErrRepair with a *RepairError", err)
+	}
+	if !reflect.DeepEqual(env, Envelope{}) {
+		t.Errorf("invalid UTF-8: RepairEnvelope = %+v, want the zero value", env)
+	}
+
+	// JSON5 output is not JSON, so the envelope does not decode.
+	env, err = RepairEnvelope([]byte("{a: 1}"), RepairOptions{OutputFormat: FormatJSON5})
+	if !errors.Is(err, ErrUnmarshal) || errors.Is(err, ErrRepair) {
+		t.Errorf("JSON5: err = %v, want ErrUnmarshal", err)
+	}
+	if !reflect.DeepEqual(env, Envelope{}) {
+		t.Errorf("JSON5: RepairEnvelope = %+v, want the zero value", env)
+	}
+}
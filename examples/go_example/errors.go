@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sentinel errors matched with errors.Is against an *RepairError returned by the wrappers.
+var (
+	// ErrRepairFailed is the generic failure when the library reports no details.
+	ErrRepairFailed = errors.New("jsonrepair: repair failed")
+	// ErrInvalidJSON is returned when RejectIfInvalid is set and the input is not valid JSON.
+	ErrInvalidJSON = errors.New("jsonrepair: invalid JSON")
+	// ErrTimeout is returned when a repair exceeds RepairOptions.Timeout.
+	ErrTimeout = errors.New("jsonrepair: timed out")
+	// ErrTooManyRepairs is returned when input needs more than RepairOptions.MaxRepairs fixes.
+	ErrTooManyRepairs = errors.New("jsonrepair: too many repairs")
+	// ErrTooManyElements is returned when an array or object has more than
+	// RepairOptions.MaxElements members.
+	ErrTooManyElements = errors.New("jsonrepair: too many elements")
+	// ErrKeyTooLong is returned when an object key is longer than
+	// RepairOptions.MaxKeyLen bytes and LongKeys is LongKeysError.
+	ErrKeyTooLong = errors.New("jsonrepair: key too long")
+	// ErrInputTooLarge is returned, before any repair work, when the input is
+	// longer than RepairOptions.MaxInputBytes.
+	ErrInputTooLarge = errors.New("jsonrepair: input too large")
+	// ErrPointerNotFound is returned by RepairExtract when the pointer selects nothing.
+	ErrPointerNotFound = errors.New("jsonrepair: pointer not found")
+	// ErrStreamBufferOverflow is returned by StreamRepairer.Push once the buffered
+	// incomplete value exceeds the SetMaxBuffer cap.
+	ErrStreamBufferOverflow = errors.New("jsonrepair: stream buffer overflow")
+	// ErrInvalidUTF8 is returned when the input is not valid UTF-8; Position is the
+	// offset of the first invalid byte.
+	ErrInvalidUTF8 = errors.New("jsonrepair: invalid UTF-8")
+	// ErrUnsupportedOption is returned by the pure-Go fallback (see purego.go)
+	// for a RepairOptions field it does not implement; the message names the
+	// field.
+	ErrUnsupportedOption = errors.New("jsonrepair: option not supported by the pure-Go fallback")
+)
+
+// RepairError carries the details reported by the C API (`JsonRepairError`) or the
+// pure-Go fallback. Match it with errors.As to read where in the input a
+// failure happened. Line and Column are 1-based (Column counts runes) and are
+// filled in by the wrappers that take the whole input; they are 0 for stream
+// chunks.
+type RepairError struct {
+	Code     int
+	Message  string
+	Position int64
+	Line     int
+	Column   int
+}
+
+func (e *RepairError) Error() string {
+	return fmt.Sprintf("jsonrepair: %s (code %d)", e.Message, e.Code)
+}
+
+// locate fills in Line and Column from Position, an offset in input.
+func (e *RepairError) locate(input string) {
+	pos := min(int(e.Position), len(input))
+	lineStart := strings.LastIndexByte(input[:pos], '\n') + 1
+	e.Line = strings.Count(input[:pos], "\n") + 1
+	e.Column = utf8.RuneCountInString(input[lineStart:pos]) + 1
+}
@@ -0,0 +1,65 @@
+//go:build cgo && !purego
+
+package main
+
+/*
+#include "../../include/jsonrepair.h"
+*/
+import "C"
+
+// Unwrap maps the C error code to one of the sentinel errors, if any.
+func (e *RepairError) Unwrap() error {
+	switch e.Code {
+	case int(C.INVALID_JSON):
+		return ErrInvalidJSON
+	case int(C.TIMEOUT):
+		return ErrTimeout
+	case int(C.TOO_MANY_REPAIRS):
+		return ErrTooManyRepairs
+	case int(C.TOO_MANY_ELEMENTS):
+		return ErrTooManyElements
+	case int(C.KEY_TOO_LONG):
+		return ErrKeyTooLong
+	case int(C.INPUT_TOO_LARGE):
+		return ErrInputTooLarge
+	case int(C.POINTER_NOT_FOUND):
+		return ErrPointerNotFound
+	case int(C.BUFFER_OVERFLOW):
+		return ErrStreamBufferOverflow
+	case int(C.INVALID_UTF8):
+		return ErrInvalidUTF8
+	}
+	return nil
+}
+
+// errorFromC copies a filled `JsonRepairError` into a Go error without freeing it.
+func errorFromC(cErr *C.JsonRepairError) error {
+	if cErr.code == C.OK {
+		return nil
+	}
+	e := &RepairError{Code: int(cErr.code), Position: int64(cErr.position)}
+	if cErr.message != nil {
+		e.Message = C.GoString(cErr.message)
+	}
+	return e
+}
+
+// takeError converts a filled `JsonRepairError` into a Go error and frees its message.
+func takeError(cErr *C.JsonRepairError) error {
+	err := errorFromC(cErr)
+	if cErr.message != nil {
+		C.jsonrepair_free(cErr.message)
+		cErr.message = nil
+	}
+	return err
+}
+
+// takeInputError is takeError for a failure in input, adding the line and
+// column of the reported position.
+func takeInputError(cErr *C.JsonRepairError, input string) error {
+	err := takeError(cErr)
+	if e, ok := err.(*RepairError); ok {
+		e.locate(input)
+	}
+	return err
+}
@@ -0,0 +1,985 @@
+//go:build cgo && !purego
+
+package main
+
+/*
+#cgo LDFLAGS: -L../../target/release -ljsonrepair
+#include "../../include/jsonrepair.h"
+#include <stdlib.h>
+#include <string.h>
+*/
+import "C"
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// newCOptions allocates C options from opts; free with C.jsonrepair_options_free.
+func newCOptions(opts RepairOptions) *C.Options {
+	cOpts := C.jsonrepair_options_new()
+	C.jsonrepair_options_set_ensure_ascii(cOpts, C.bool(opts.EnsureASCII))
+	C.jsonrepair_options_set_ascii_scope(cOpts, cEnum(opts.ASCIIScope))
+	C.jsonrepair_options_set_allow_python_keywords(cOpts, C.bool(!opts.DisablePythonKeywords))
+	C.jsonrepair_options_set_case_insensitive_keywords(cOpts, C.bool(!opts.DisableCaseInsensitiveKeywords))
+	C.jsonrepair_options_set_abbreviated_keywords(cOpts, C.bool(opts.AbbreviatedKeywords))
+	if len(opts.NullTokens) > 0 {
+		setNullTokens(cOpts, opts.NullTokens)
+	}
+	C.jsonrepair_options_set_tolerate_hash_comments(cOpts, C.bool(!opts.DisableHashComments))
+	C.jsonrepair_options_set_tolerate_sql_comments(cOpts, C.bool(opts.SQLComments))
+	C.jsonrepair_options_set_repair_undefined(cOpts, C.bool(!opts.DisableUndefinedRepair))
+	C.jsonrepair_options_set_fenced_code_blocks(cOpts, C.bool(!opts.DisableFencedCodeBlocks))
+	C.jsonrepair_options_set_normalize_js_nonfinite(cOpts, C.bool(!opts.DisableNonFiniteNormalization))
+	C.jsonrepair_options_set_number_tolerance_leading_dot(cOpts, C.bool(!opts.DisableLeadingDotNumbers))
+	C.jsonrepair_options_set_number_tolerance_trailing_dot(cOpts, C.bool(!opts.DisableTrailingDotNumbers))
+	C.jsonrepair_options_set_logging(cOpts, C.bool(opts.Logging))
+	C.jsonrepair_options_set_python_style_separators(cOpts, C.bool(opts.PythonStyleSeparators))
+	C.jsonrepair_options_set_aggressive_truncation_fix(cOpts, C.bool(opts.AggressiveTruncationFix))
+	C.jsonrepair_options_set_reject_if_invalid(cOpts, C.bool(opts.RejectIfInvalid))
+	C.jsonrepair_options_set_output_bom(cOpts, C.bool(opts.OutputBOM))
+	C.jsonrepair_options_set_raw_message_safe(cOpts, C.bool(opts.RawMessageSafe))
+	if opts.MaxRepairs > 0 {
+		C.jsonrepair_options_set_max_repairs(cOpts, C.size_t(opts.MaxRepairs))
+	}
+	if opts.MaxElements > 0 {
+		C.jsonrepair_options_set_max_elements(cOpts, C.size_t(opts.MaxElements))
+	}
+	if opts.MaxKeyLen > 0 {
+		C.jsonrepair_options_set_max_key_len(cOpts, C.size_t(opts.MaxKeyLen))
+	}
+	C.jsonrepair_options_set_long_keys(cOpts, cEnum(opts.LongKeys))
+	if opts.MaxInputBytes > 0 {
+		C.jsonrepair_options_set_max_input_bytes(cOpts, C.size_t(opts.MaxInputBytes))
+	}
+	C.jsonrepair_options_set_trim_keys(cOpts, C.bool(opts.TrimKeys))
+	C.jsonrepair_options_set_unwrap_escaped_json(cOpts, C.bool(opts.UnwrapEscapedJSON))
+	C.jsonrepair_options_set_wrap_fragments(cOpts, C.bool(opts.WrapFragments))
+	C.jsonrepair_options_set_extract_embedded(cOpts, C.bool(opts.ExtractEmbedded))
+	C.jsonrepair_options_set_normalize_numbers(cOpts, C.bool(opts.NormalizeNumbers))
+	C.jsonrepair_options_set_overflow(cOpts, cEnum(opts.Overflow))
+	C.jsonrepair_options_set_number_suffix(cOpts, cEnum(opts.NumberSuffix))
+	C.jsonrepair_options_set_negative_zero(cOpts, cEnum(opts.NegativeZero))
+	C.jsonrepair_options_set_safe_integers(cOpts, cEnum(opts.SafeIntegers))
+	if opts.DisableBuiltinUnwrap {
+		C.jsonrepair_options_clear_unwrap_functions(cOpts)
+	}
+	for name, mode := range opts.UnwrapFunctions {
+		cName := C.CString(name)
+		C.jsonrepair_options_add_unwrap_function(cOpts, cName, cEnum(mode))
+		C.free(unsafe.Pointer(cName))
+	}
+	for _, alias := range opts.BracketAliases {
+		cOpen, cClose := C.CString(alias.Open), C.CString(alias.Close)
+		C.jsonrepair_options_add_bracket_alias(cOpts, cOpen, cClose, cEnum(alias.Kind))
+		C.free(unsafe.Pointer(cOpen))
+		C.free(unsafe.Pointer(cClose))
+	}
+	C.jsonrepair_options_set_error_as_json(cOpts, C.bool(opts.ErrorAsJSON))
+	C.jsonrepair_options_set_strip_ellipsis(cOpts, C.bool(!opts.DisableStripEllipsis))
+	C.jsonrepair_options_set_equals_separators(cOpts, C.bool(opts.EqualsSeparators))
+	C.jsonrepair_options_set_missing_values(cOpts, cEnum(opts.MissingValues))
+	C.jsonrepair_options_set_dedup_position(cOpts, cEnum(opts.DedupPosition))
+	C.jsonrepair_options_set_decode_base64(cOpts, C.bool(opts.DecodeBase64))
+	C.jsonrepair_options_set_annotate_source(cOpts, C.bool(opts.AnnotateSource))
+	C.jsonrepair_options_set_stray_tokens(cOpts, cEnum(opts.StrayTokens))
+	C.jsonrepair_options_set_force_container(cOpts, cEnum(opts.ForceContainer))
+	C.jsonrepair_options_set_envelope(cOpts, C.bool(opts.Envelope))
+	C.jsonrepair_options_set_fix_mojibake(cOpts, C.bool(opts.FixMojibake))
+	if opts.AltQuoteChars != "" {
+		cPairs := C.CString(opts.AltQuoteChars)
+		C.jsonrepair_options_set_alt_quote_chars(cOpts, cPairs)
+		C.free(unsafe.Pointer(cPairs))
+	}
+	C.jsonrepair_options_set_line_continuations(cOpts, cEnum(opts.LineContinuations))
+	C.jsonrepair_options_set_fix_backslashes(cOpts, C.bool(opts.FixBackslashes))
+	C.jsonrepair_options_set_salvage(cOpts, cEnum(opts.Salvage))
+	if opts.DropPlaceholder != "" {
+		cPlaceholder := C.CString(opts.DropPlaceholder)
+		C.jsonrepair_options_set_drop_placeholder(cOpts, cPlaceholder)
+		C.free(unsafe.Pointer(cPlaceholder))
+	}
+	C.jsonrepair_options_set_strictness(cOpts, cEnum(opts.Strictness))
+	C.jsonrepair_options_set_brackets_as_objects(cOpts, C.bool(opts.BracketsAsObjects))
+	C.jsonrepair_options_set_utf8_strictness(cOpts, cEnum(opts.UTF8Strictness))
+	C.jsonrepair_options_set_output_format(cOpts, cEnum(opts.OutputFormat))
+	C.jsonrepair_options_set_comma_decimal(cOpts, C.bool(opts.CommaDecimal))
+	C.jsonrepair_options_set_eval_fractions(cOpts, C.bool(opts.EvalFractions))
+	C.jsonrepair_options_set_dedup_arrays(cOpts, C.bool(opts.DedupArrays))
+	C.jsonrepair_options_set_sort_keys(cOpts, C.bool(opts.SortKeys))
+	C.jsonrepair_options_set_escape_slashes(cOpts, C.bool(opts.EscapeSlashes))
+	C.jsonrepair_options_set_minimal_escapes(cOpts, C.bool(opts.MinimalEscapes))
+	C.jsonrepair_options_set_short_escapes(cOpts, C.bool(!opts.DisableShortEscapes))
+	C.jsonrepair_options_set_strip_trailing_line_ws(cOpts, C.bool(opts.StripTrailingLineWS))
+	C.jsonrepair_options_set_collapse_ws(cOpts, C.bool(opts.CollapseWS))
+	C.jsonrepair_options_set_collapse_ws_newlines(cOpts, C.bool(opts.CollapseWSNewlines))
+	C.jsonrepair_options_set_add_missing_brackets(cOpts, C.bool(opts.AddMissingBrackets))
+	C.jsonrepair_options_set_parens_as_arrays(cOpts, C.bool(opts.ParensAsArrays))
+	C.jsonrepair_options_set_compact_spacing(cOpts, cEnum(opts.CompactSpacing))
+	C.jsonrepair_options_set_indent_detect(cOpts, C.bool(opts.IndentDetect))
+	C.jsonrepair_options_set_align_values(cOpts, C.bool(opts.AlignValues))
+	C.jsonrepair_options_set_crlf(cOpts, C.bool(opts.CRLF))
+	C.jsonrepair_options_set_concat_adjacent_strings(cOpts, C.bool(opts.ConcatAdjacentStrings))
+	C.jsonrepair_options_set_stream_ndjson_aggregate(cOpts, C.bool(opts.StreamNDJSONAggregate))
+	C.jsonrepair_options_set_lines_to_array(cOpts, C.bool(opts.LinesToArray))
+	C.jsonrepair_options_set_stop_after_first(cOpts, C.bool(opts.StopAfterFirst))
+	C.jsonrepair_options_set_stream_validate_only(cOpts, C.bool(opts.StreamValidateOnly))
+	if opts.Timeout > 0 {
+		ms := opts.Timeout.Milliseconds()
+		if ms == 0 {
+			ms = 1
+		}
+		C.jsonrepair_options_set_timeout_ms(cOpts, C.uint64_t(ms))
+	}
+	return cOpts
+}
+
+// cEnum converts an enum option for a setter taking uint32_t. A value outside
+// the uint32 range would wrap to some other variant, so it is passed as
+// math.MaxUint32, which names none and is ignored by the library.
+func cEnum[T ~int](v T) C.uint32_t {
+	if v < 0 || uint64(v) > math.MaxUint32 {
+		return C.uint32_t(math.MaxUint32)
+	}
+	return C.uint32_t(v)
+}
+
+// setNullTokens passes tokens to the library as a C array of C strings, which
+// it copies.
+func setNullTokens(cOpts *C.Options, tokens []string) {
+	cTokens := make([]*C.char, len(tokens))
+	for i, token := range tokens {
+		cTokens[i] = C.CString(token)
+		defer C.free(unsafe.Pointer(cTokens[i]))
+	}
+	C.jsonrepair_options_set_null_tokens(cOpts, &cTokens[0], C.size_t(len(cTokens)))
+}
+
+// RepairJSON repairs a broken JSON string using default options. Failures are
+// returned as *RepairError, like Repair.
+func RepairJSON(input string) (string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_ex(cInput, nil, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// RepairJSONWithOptions repairs JSON with custom options
+func RepairJSONWithOptions(input string, ensureASCII bool) (string, error) {
+	return Repair(input, RepairOptions{EnsureASCII: ensureASCII})
+}
+
+// Repair repairs input with opts and is the recommended entry point: the C
+// options are built and freed internally. Failures are returned as *RepairError,
+// which matches sentinels such as ErrTimeout via errors.Is. With ErrorAsJSON
+// a failure returns the error object together with the error.
+func Repair(input string, opts RepairOptions) (string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	cOpts := newCOptions(opts)
+	defer C.jsonrepair_options_free(cOpts)
+	if opts.OnProgress != nil {
+		defer setProgress(cOpts, opts.OnProgress)()
+	}
+	if opts.FormatNumbers != nil {
+		defer setNumberFormat(cOpts, opts.FormatNumbers)()
+	}
+	if opts.Trace != nil {
+		C.jsonrepair_options_set_trace(cOpts, true)
+		defer writeTrace(opts.Trace, cOpts)
+	}
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_ex(cInput, cOpts, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), takeInputError(&cErr, input)
+}
+
+// writeTrace copies the trace recorded with cOpts to w.
+func writeTrace(w io.Writer, cOpts *C.Options) {
+	cTrace := C.jsonrepair_options_take_trace(cOpts)
+	if cTrace == nil {
+		return
+	}
+	defer C.jsonrepair_free(cTrace)
+	io.WriteString(w, C.GoString(cTrace))
+}
+
+// RepairHashed repairs input with default options and also returns the
+// SHA-256 digest of the repaired bytes, computed by the library in the same
+// call. The digest is stable for equal output, so it works as a cache key.
+func RepairHashed(input string) (string, [32]byte, error) {
+	var hash [32]byte
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_hashed(cInput, nil, (*C.uint8_t)(unsafe.Pointer(&hash[0])), &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", hash, err
+		}
+		return "", hash, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), hash, nil
+}
+
+// ValueKind is the JSON type of a repaired document's root value. The values
+// match the C JsonRepairValueKind enum.
+type ValueKind int
+
+const (
+	ValueKindObject ValueKind = iota
+	ValueKindArray
+	ValueKindString
+	ValueKindNumber
+	ValueKindBoolean
+	ValueKindNull
+	// ValueKindEmpty means the input held no value and the output is empty.
+	ValueKindEmpty
+)
+
+// RepairWithType repairs input with default options and also reports the type
+// of the repaired root value, so the result can be routed without parsing it
+// again. Several root values wrapped into one array report ValueKindArray.
+func RepairWithType(input string) (string, ValueKind, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cKind C.enum_JsonRepairValueKind
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_with_kind(cInput, nil, &cKind, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", ValueKindEmpty, err
+		}
+		return "", ValueKindEmpty, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), ValueKind(cKind), nil
+}
+
+// RepairChanged repairs input with default options and also reports whether
+// the output differs from input in any byte. Valid JSON is copied through as
+// written, so byte-identical input reports false.
+func RepairChanged(input string) (string, bool, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cChanged C.bool
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_changed(cInput, nil, &cChanged, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", false, err
+		}
+		return "", false, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), bool(cChanged), nil
+}
+
+// RepairSkeleton repairs input with default options and returns its type
+// skeleton: the same structure with every scalar replaced by "string",
+// "number", "boolean" or "null". An array lists the distinct skeletons of its
+// elements once each, in order of first appearance, so [1, "a", 2] gives
+// ["number","string"].
+func RepairSkeleton(input string) (string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_skeleton(cInput, nil, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// RepairBoth repairs input with default options once and returns it both
+// minified and pretty-printed with indent spaces per level. The pretty form is
+// laid out from the compact one, so both hold the same values.
+func RepairBoth(input string, indent int) (compact, pretty string, err error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cPretty *C.char
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_both(cInput, C.size_t(indent), nil, &cPretty, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+	defer C.jsonrepair_free(cPretty)
+
+	return C.GoString(cResult), C.GoString(cPretty), nil
+}
+
+// RepairWithComments repairs input with default options and returns the
+// output without comments, plus the comments keyed by the JSON Pointer of the
+// value they belong to ("" for comments before the document). A comment belongs
+// to the value that starts before it on the same line, otherwise to the next
+// value; several comments of one value are joined with "\n".
+func RepairWithComments(input string) (string, map[string]string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cComments *C.char
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_with_comments(cInput, nil, &cComments, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", nil, err
+		}
+		return "", nil, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+	defer C.jsonrepair_free(cComments)
+
+	comments := map[string]string{}
+	if err := json.Unmarshal([]byte(C.GoString(cComments)), &comments); err != nil {
+		return "", nil, err
+	}
+	return C.GoString(cResult), comments, nil
+}
+
+// RepairAffectedPaths repairs input with default options and lists the paths
+// of the values it repaired, such as $["items"][2]["name"], once each in the
+// order of their first repair, for targeted review. A repair to a container's
+// own syntax (a key, comma or bracket) gives the container's path. Valid input
+// gives no paths.
+func RepairAffectedPaths(input string) (string, []string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var list *C.JsonRepairStringList
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_affected_paths(cInput, nil, &list, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", nil, err
+		}
+		return "", nil, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+	defer C.jsonrepair_string_list_free(list)
+
+	items := unsafe.Slice(list.items, int(list.len))
+	paths := make([]string, len(items))
+	for i, p := range items {
+		paths[i] = C.GoString(p)
+	}
+	return C.GoString(cResult), paths, nil
+}
+
+// RepairExtract repairs input and returns only the value at the JSON Pointer
+// (RFC 6901, e.g. "/result"). A missing target yields ErrPointerNotFound.
+func RepairExtract(input, pointer string) (string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+	cPointer := C.CString(pointer)
+	defer C.free(unsafe.Pointer(cPointer))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_extract(cInput, cPointer, nil, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// RepairSplit repairs a document of concatenated values ("{a:1}{b:2}[1,2]")
+// and returns each root value separately, tolerating whitespace, newlines and
+// commas between them. It is the one-shot counterpart to StreamRepairer.
+func RepairSplit(input string) ([]string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cErr C.JsonRepairError
+	list := C.jsonrepair_repair_split(cInput, nil, &cErr)
+	if list == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return nil, err
+		}
+		return nil, ErrRepairFailed
+	}
+	defer C.jsonrepair_string_list_free(list)
+
+	items := unsafe.Slice(list.items, int(list.len))
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = C.GoString(item)
+	}
+	return values, nil
+}
+
+// RepairFirst repairs only the first value in input and reports how many
+// input bytes it used, so a protocol reader can frame the next message. The
+// count includes leading whitespace; nothing after the value is parsed.
+func RepairFirst(input string) (value string, consumed int, err error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cConsumed C.size_t
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_first(cInput, nil, &cConsumed, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", 0, err
+		}
+		return "", 0, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), int(cConsumed), nil
+}
+
+// RepairWithEnd repairs input with opts like Repair and also reports the byte
+// offset where the last repaired value ends, so a reader can keep
+// input[endOffset:] for later. Trailing data the repair ignores is not
+// counted; with StopAfterFirst the offset is the end of the first value.
+func RepairWithEnd(input string, opts RepairOptions) (out string, endOffset int, err error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	cOpts := newCOptions(opts)
+	defer C.jsonrepair_options_free(cOpts)
+	if opts.OnProgress != nil {
+		defer setProgress(cOpts, opts.OnProgress)()
+	}
+	if opts.FormatNumbers != nil {
+		defer setNumberFormat(cOpts, opts.FormatNumbers)()
+	}
+
+	var cEnd C.size_t
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_with_end(cInput, cOpts, &cEnd, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", 0, err
+		}
+		return "", 0, ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), int(cEnd), nil
+}
+
+// RepairUTF16 repairs UTF-16 encoded input and returns UTF-8 output. A
+// leading BOM selects the byte order; BOM-less input is read as little-endian.
+// Malformed UTF-16 (an odd byte count or an unpaired surrogate) is an error.
+func RepairUTF16(data []byte) (string, error) {
+	return repairUTF16(data, C.UTF16_LE)
+}
+
+// RepairUTF16BE is RepairUTF16 for big-endian sources: BOM-less input is read
+// as big-endian, while a leading BOM still selects the byte order.
+func RepairUTF16BE(data []byte) (string, error) {
+	return repairUTF16(data, C.UTF16_BE)
+}
+
+// repairUTF16 repairs data, reading BOM-less input in the endian byte order.
+func repairUTF16(data []byte, endian C.enum_JsonRepairUtf16Endian) (string, error) {
+	var cInput *C.uint8_t
+	if len(data) > 0 {
+		cInput = (*C.uint8_t)(unsafe.Pointer(&data[0]))
+	}
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_repair_utf16(cInput, C.size_t(len(data)), C.uint32_t(endian), nil, &cErr)
+	if cResult == nil {
+		if err := takeError(&cErr); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// Minify removes insignificant whitespace from input, which must already be
+// valid JSON. Invalid input is rejected with an error matching ErrInvalidJSON
+// instead of being repaired.
+func Minify(input string) (string, error) {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_minify_ex(cInput, &cErr)
+	if cResult == nil {
+		if err := takeInputError(&cErr, input); err != nil {
+			return "", err
+		}
+		return "", ErrRepairFailed
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// Version returns the version of the linked library.
+func Version() string {
+	return C.GoString(C.jsonrepair_version())
+}
+
+// Features describes the linked library build, as decoded from
+// jsonrepair_features(): "version", "features" (Cargo features as booleans),
+// "target", "max_depth" (nil when unlimited) and "unwrap_escaped_max_depth".
+// Include it in bug reports.
+func Features() (map[string]any, error) {
+	var features map[string]any
+	if err := json.Unmarshal([]byte(C.GoString(C.jsonrepair_features())), &features); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// RepairCategories returns the names of the repairs the linked library can
+// log, so tools explaining fixes stay in sync with the library version.
+func RepairCategories() []string {
+	list := C.jsonrepair_list_repair_categories()
+	defer C.jsonrepair_string_list_free(list)
+
+	items := unsafe.Slice(list.items, int(list.len))
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = C.GoString(item)
+	}
+	return names
+}
+
+// OptionID names an option for OptionSupported. The values match the C
+// JsonRepairOption enum.
+type OptionID int
+
+const (
+	OptionEnsureASCII OptionID = iota
+	OptionAllowPythonKeywords
+	OptionTolerateHashComments
+	OptionRepairUndefined
+	OptionFencedCodeBlocks
+	OptionNormalizeJSNonfinite
+	OptionStreamNDJSONAggregate
+	OptionLogging
+	OptionNumberToleranceLeadingDot
+	OptionNumberToleranceTrailingDot
+	OptionPythonStyleSeparators
+	OptionAggressiveTruncationFix
+	OptionRejectIfInvalid
+	OptionTimeoutMs
+	OptionOutputBOM
+	OptionStreamValidateOnly
+	OptionTrimKeys
+	OptionUnwrapEscapedJSON
+	OptionMaxRepairs
+	OptionWrapFragments
+	OptionMissingValues
+	OptionASCIIScope
+	OptionNormalizeNumbers
+	OptionEqualsSeparators
+	OptionDedupPosition
+	OptionDecodeBase64
+	OptionAnnotateSource
+	OptionStrayTokens
+	OptionForceContainer
+	OptionFixMojibake
+	OptionSalvage
+	OptionOutputFormat
+	OptionCommaDecimal
+	OptionDedupArrays
+	OptionEscapeSlashes
+	OptionAddMissingBrackets
+	OptionCompactSpacing
+	OptionIndentDetect
+	OptionAlignValues
+	OptionConcatAdjacentStrings
+	OptionProgressCallback
+	OptionMaxElements
+	OptionTolerateSqlComments
+	OptionOverflow
+	OptionNumberSuffix
+	OptionUnwrapFunctions
+	OptionErrorAsJSON
+	OptionStripEllipsis
+	OptionNegativeZero
+	OptionShortEscapes
+	OptionStripTrailingLineWS
+	OptionParensAsArrays
+	OptionSafeIntegers
+	OptionExtractEmbedded
+	OptionMinimalEscapes
+	OptionStrictness
+	OptionMaxKeyLen
+	OptionLongKeys
+	OptionCaseInsensitiveKeywords
+	OptionCollapseWS
+	OptionCollapseWSNewlines
+	OptionTrace
+	OptionCRLF
+	OptionAltQuoteChars
+	OptionLinesToArray
+	OptionLineContinuations
+	OptionDropPlaceholder
+	OptionRawMessageSafe
+	OptionUTF8Strictness
+	OptionBracketAliases
+	OptionEvalFractions
+	OptionFixBackslashes
+	OptionStopAfterFirst
+	OptionMaxInputBytes
+	OptionAbbreviatedKeywords
+	OptionEnvelope
+	OptionNumberFormatCallback
+	OptionNullTokens
+	OptionSortKeys
+	OptionBracketsAsObjects
+)
+
+// OptionSupported reports whether the linked library applies the option id. An
+// option behind a Cargo feature that was compiled out (OptionLogging without
+// "logging") is accepted by RepairOptions but has no effect, and ids newer than
+// the library are reported as unsupported.
+func OptionSupported(id OptionID) bool {
+	return bool(C.jsonrepair_option_supported(C.uint32_t(id)))
+}
+
+// StreamRepairer wraps the C streaming API
+type StreamRepairer struct {
+	stream       *C.StreamRepairer
+	onSkipped    func(Status)
+	onValueRange func(value string, start, end int64)
+	onHeartbeat  func(bytesIn int64, buffered int)
+}
+
+// NewStreamRepairer creates a new streaming repairer
+func NewStreamRepairer() *StreamRepairer {
+	return &StreamRepairer{
+		stream: C.jsonrepair_stream_new(nil),
+	}
+}
+
+// NewStreamRepairerWithOptions creates a streaming repairer configured by opts.
+// The library copies the options, so nothing needs to outlive this call.
+func NewStreamRepairerWithOptions(opts RepairOptions) *StreamRepairer {
+	cOpts := newCOptions(opts)
+	defer C.jsonrepair_options_free(cOpts)
+
+	return &StreamRepairer{
+		stream: C.jsonrepair_stream_new(cOpts),
+	}
+}
+
+// Push pushes a chunk and returns completed JSON if any
+func (s *StreamRepairer) Push(chunk string) (string, error) {
+	cChunk := C.CString(chunk)
+	defer C.free(unsafe.Pointer(cChunk))
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_stream_push_ex(s.stream, cChunk, &cErr)
+	s.reportSkipped()
+	s.reportRanges()
+	s.reportHeartbeats()
+	if err := takeError(&cErr); err != nil {
+		return "", err
+	}
+	if cResult == nil {
+		return "", nil // No complete value yet
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// Flush flushes remaining data
+func (s *StreamRepairer) Flush() (string, error) {
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_stream_flush_ex(s.stream, &cErr)
+	s.reportSkipped()
+	s.reportRanges()
+	s.reportHeartbeats()
+	if err := takeError(&cErr); err != nil {
+		return "", err
+	}
+	if cResult == nil {
+		return "", nil
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// Finalize flushes the remaining output and resets the stream for the next
+// document in a single library call. It is equivalent to Flush followed by
+// starting over with a new stream of the same options; SetRecover,
+// SetMaxBuffer, OnValueRange and OnHeartbeat settings are kept, and their
+// callbacks see what the flush completed. The stream is reset even when an
+// error is returned.
+func (s *StreamRepairer) Finalize() (string, error) {
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_stream_finalize(s.stream, &cErr)
+	s.reportSkipped()
+	s.reportRanges()
+	s.reportHeartbeats()
+	if err := takeError(&cErr); err != nil {
+		return "", err
+	}
+	if cResult == nil {
+		return "", nil
+	}
+	defer C.jsonrepair_free(cResult)
+
+	return C.GoString(cResult), nil
+}
+
+// PushTo pushes chunk and writes any values it completes straight to w. An
+// unfinished value stays buffered in the stream until a later PushTo or
+// FlushTo completes it. Output is written from the library's buffer, with no
+// intermediate Go string. chunk must not contain NUL bytes.
+func (s *StreamRepairer) PushTo(w io.Writer, chunk []byte) error {
+	cChunk := (*C.char)(C.malloc(C.size_t(len(chunk) + 1)))
+	defer C.free(unsafe.Pointer(cChunk))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(cChunk)), len(chunk)+1)
+	copy(buf, chunk)
+	buf[len(chunk)] = 0
+
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_stream_push_ex(s.stream, cChunk, &cErr)
+	s.reportSkipped()
+	s.reportRanges()
+	s.reportHeartbeats()
+	if err := takeError(&cErr); err != nil {
+		return err
+	}
+	return writeResult(w, cResult)
+}
+
+// FlushTo writes whatever the stream still buffers to w, completing the
+// output of a PushTo sequence.
+func (s *StreamRepairer) FlushTo(w io.Writer) error {
+	var cErr C.JsonRepairError
+	cResult := C.jsonrepair_stream_flush_ex(s.stream, &cErr)
+	s.reportSkipped()
+	s.reportRanges()
+	s.reportHeartbeats()
+	if err := takeError(&cErr); err != nil {
+		return err
+	}
+	return writeResult(w, cResult)
+}
+
+// writeResult writes a library-owned C string to w and frees it.
+func writeResult(w io.Writer, cResult *C.char) error {
+	if cResult == nil {
+		return nil
+	}
+	defer C.jsonrepair_free(cResult)
+	_, err := w.Write(unsafe.Slice((*byte)(unsafe.Pointer(cResult)), C.strlen(cResult)))
+	return err
+}
+
+// SetRecover turns on error recovery: a value that cannot be repaired is
+// skipped, along with input up to the next '{' or '[', and the stream keeps
+// going. Each skipped region is passed to onSkipped (Valid is false and Err
+// holds the reason) from the Push or Flush call that finished it. A nil
+// callback turns recovery off.
+func (s *StreamRepairer) SetRecover(onSkipped func(skipped Status)) {
+	s.onSkipped = onSkipped
+	C.jsonrepair_stream_set_recover(s.stream, C.bool(onSkipped != nil))
+}
+
+// SetMaxBuffer caps the bytes buffered for a value that has not completed
+// yet; 0 means unlimited. A Push that goes over the cap returns
+// ErrStreamBufferOverflow and drops the incomplete value.
+func (s *StreamRepairer) SetMaxBuffer(bytes int) {
+	C.jsonrepair_stream_set_max_buffer(s.stream, C.size_t(bytes))
+}
+
+// reportSkipped hands regions dropped in recovery mode to the callback.
+func (s *StreamRepairer) reportSkipped() {
+	if s.onSkipped == nil {
+		return
+	}
+	var cErr C.JsonRepairError
+	skipped, _ := takeStatuses(C.jsonrepair_stream_take_skipped(s.stream), &cErr)
+	for _, st := range skipped {
+		s.onSkipped(st)
+	}
+}
+
+// OnValueRange passes each repaired value to f together with the input bytes
+// it came from: start and end are byte offsets counted from the start of the
+// stream, across all chunks, leaving out surrounding whitespace and comments.
+// f is called from the Push or Flush call that completed the value. A nil f
+// turns range tracking off.
+func (s *StreamRepairer) OnValueRange(f func(value string, start, end int64)) {
+	s.onValueRange = f
+	C.jsonrepair_stream_set_track_ranges(s.stream, C.bool(f != nil))
+}
+
+// reportRanges hands recorded value ranges to the OnValueRange callback.
+func (s *StreamRepairer) reportRanges() {
+	if s.onValueRange == nil {
+		return
+	}
+	list := C.jsonrepair_stream_take_ranges(s.stream)
+	if list == nil {
+		return
+	}
+	defer C.jsonrepair_value_range_list_free(list)
+	for _, r := range unsafe.Slice(list.items, int(list.len)) {
+		s.onValueRange(C.GoString(r.value), int64(r.start), int64(r.end))
+	}
+}
+
+// OnHeartbeat calls f every interval input bytes pushed without a value
+// completing, so a server can tell a stream whose value is still growing from
+// a stalled one. bytesIn is the number of bytes pushed so far and buffered the
+// number held for the unfinished value. f is called from the Push call that
+// crossed the interval, at most once per call; the output is unchanged. A nil
+// f or an interval of 0 turns heartbeats off.
+func (s *StreamRepairer) OnHeartbeat(interval int, f func(bytesIn int64, buffered int)) {
+	if f == nil {
+		interval = 0
+	}
+	s.onHeartbeat = f
+	C.jsonrepair_stream_set_heartbeat(s.stream, C.size_t(interval))
+}
+
+// reportHeartbeats hands recorded heartbeats to the OnHeartbeat callback.
+func (s *StreamRepairer) reportHeartbeats() {
+	if s.onHeartbeat == nil {
+		return
+	}
+	list := C.jsonrepair_stream_take_heartbeats(s.stream)
+	if list == nil {
+		return
+	}
+	defer C.jsonrepair_heartbeat_list_free(list)
+	for _, h := range unsafe.Slice(list.items, int(list.len)) {
+		s.onHeartbeat(int64(h.bytes_in), int(h.buffered))
+	}
+}
+
+// StreamStats holds the totals of a streaming session.
+type StreamStats struct {
+	// Values is the number of root values repaired and emitted.
+	Values uint64
+	// Repairs is the number of repairs applied to those values.
+	Repairs uint64
+	// BytesIn is the number of input bytes pushed so far.
+	BytesIn uint64
+	// BytesOut is the number of bytes of repaired values emitted, without
+	// the BOM or NDJSON aggregation brackets.
+	BytesOut uint64
+}
+
+// Stats returns the totals of the session so far. Values skipped in recovery
+// mode and validate-only statuses are not counted.
+func (s *StreamRepairer) Stats() StreamStats {
+	st := C.jsonrepair_stream_stats(s.stream)
+	return StreamStats{
+		Values:   uint64(st.values),
+		Repairs:  uint64(st.repairs),
+		BytesIn:  uint64(st.bytes_in),
+		BytesOut: uint64(st.bytes_out),
+	}
+}
+
+// Close frees the stream
+func (s *StreamRepairer) Close() {
+	if s.stream != nil {
+		C.jsonrepair_stream_free(s.stream)
+		s.stream = nil
+	}
+}
+
+// Status is the validation result for one completed root value, or a region
+// skipped in recovery mode (see SetRecover).
+type Status struct {
+	// Valid reports whether Text is valid JSON as-is.
+	Valid bool
+	// Text is the value's source text, unchanged.
+	Text string
+	// Err describes the first problem when Valid is false; for validation it
+	// matches ErrInvalidJSON.
+	Err error
+}
+
+// PushValidate pushes a chunk and returns the validation status of each value it
+// completes, without repairing anything. The stream switches to validate-only
+// mode on the first call, after which Push and Flush produce no output.
+func (s *StreamRepairer) PushValidate(chunk string) ([]Status, error) {
+	cChunk := C.CString(chunk)
+	defer C.free(unsafe.Pointer(cChunk))
+
+	var cErr C.JsonRepairError
+	return takeStatuses(C.jsonrepair_stream_push_validate(s.stream, cChunk, &cErr), &cErr)
+}
+
+// FlushValidate validates any buffered remainder and returns its statuses.
+func (s *StreamRepairer) FlushValidate() ([]Status, error) {
+	var cErr C.JsonRepairError
+	return takeStatuses(C.jsonrepair_stream_flush_validate(s.stream, &cErr), &cErr)
+}
+
+// takeStatuses converts a C status list into Go values and frees it.
+func takeStatuses(list *C.JsonRepairValueStatusList, cErr *C.JsonRepairError) ([]Status, error) {
+	if list == nil {
+		if err := takeError(cErr); err != nil {
+			return nil, err
+		}
+		return nil, ErrRepairFailed
+	}
+	defer C.jsonrepair_value_status_list_free(list)
+
+	items := unsafe.Slice(list.items, int(list.len))
+	statuses := make([]Status, len(items))
+	for i := range items {
+		statuses[i] = Status{
+			Valid: bool(items[i].valid),
+			Text:  C.GoString(items[i].text),
+		}
+		if !statuses[i].Valid {
+			// Freed together with the list.
+			statuses[i].Err = errorFromC(&items[i].error)
+		}
+	}
+	return statuses, nil
+}
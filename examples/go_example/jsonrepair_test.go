@@ -0,0 +1,210 @@
+//go:build cgo && !purego
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorUnwrapCodes(t *testing.T) {
+	// Codes from the C JsonRepairErrorCode enum.
+	for code, want := range map[int]error{
+		7:  ErrInvalidJSON,
+		8:  ErrTimeout,
+		9:  ErrTooManyRepairs,
+		10: ErrPointerNotFound,
+		11: ErrStreamBufferOverflow,
+		12: ErrTooManyElements,
+		13: ErrInvalidUTF8,
+		14: ErrKeyTooLong,
+		15: ErrInputTooLarge,
+	} {
+		var err error = &RepairError{Code: code}
+		if !errors.Is(err, want) {
+			t.Errorf("code %d: errors.Is(%v) = false", code, want)
+		}
+	}
+	for _, code := range []int{1, 2, 6} {
+		if got := (&RepairError{Code: code}).Unwrap(); got != nil {
+			t.Errorf("code %d: Unwrap() = %v, want nil", code, got)
+		}
+	}
+}
+
+func TestErrorSentinels(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{"invalid JSON", func() error {
+			_, err := Repair("{a:1}", RepairOptions{RejectIfInvalid: true})
+			return err
+		}, ErrInvalidJSON},
+		{"too many repairs", func() error {
+			_, err := Repair("{a:1,b:2,c:3}", RepairOptions{MaxRepairs: 1})
+			return err
+		}, ErrTooManyRepairs},
+		{"too many elements", func() error {
+			_, err := Repair("[1,2,3]", RepairOptions{MaxElements: 2})
+			return err
+		}, ErrTooManyElements},
+		{"key too long", func() error {
+			_, err := Repair(`{"abcdef":1}`, RepairOptions{MaxKeyLen: 3})
+			return err
+		}, ErrKeyTooLong},
+		{"input too large", func() error {
+			_, err := Repair("[1,2,3]", RepairOptions{MaxInputBytes: 4})
+			return err
+		}, ErrInputTooLarge},
+		{"pointer not found", func() error {
+			_, err := RepairExtract("{a:1}", "/b")
+			return err
+		}, ErrPointerNotFound},
+		{"invalid UTF-8", func() error {
+			_, err := RepairJSON("[\"a\xff\"]")
+			return err
+		}, ErrInvalidUTF8},
+		{"stream buffer overflow", func() error {
+			s := NewStreamRepairer()
+			defer s.Close()
+			s.SetMaxBuffer(8)
+			_, err := s.Push(`{"a": "` + strings.Repeat("x", 32))
+			return err
+		}, ErrStreamBufferOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var e *RepairError
+			if !errors.As(err, &e) {
+				t.Fatalf("errors.As(%v, *RepairError) = false", err)
+			}
+		})
+	}
+}
+
+func TestErrorLineColumn(t *testing.T) {
+	// Column counts runes, so the two-byte "é" and "ü" count once each.
+	input := "{\n  \"é\": \"ü\xff\"\n}"
+	_, err := RepairJSON(input)
+	var e *RepairError
+	if !errors.As(err, &e) {
+		t.Fatalf("errors.As(%v, *RepairError) = false", err)
+	}
+	if want := int64(strings.IndexByte(input, 0xff)); e.Position != want {
+		t.Errorf("Position = %d, want %d", e.Position, want)
+	}
+	if e.Line != 2 || e.Column != 10 {
+		t.Errorf("Line:Column = %d:%d, want 2:10", e.Line, e.Column)
+	}
+
+	// A failure on the first line, and one after a CRLF line break.
+	for _, tt := range []struct {
+		input        string
+		line, column int
+	}{
+		{"\xff", 1, 1},
+		{"[\"日本\",\r\n \"語\xff\"]", 2, 4},
+	} {
+		_, err := RepairJSON(tt.input)
+		if !errors.As(err, &e) {
+			t.Fatalf("%q: errors.As(%v, *RepairError) = false", tt.input, err)
+		}
+		if e.Line != tt.line || e.Column != tt.column {
+			t.Errorf("%q: Line:Column = %d:%d, want %d:%d", tt.input, e.Line, e.Column, tt.line, tt.column)
+		}
+	}
+}
+
+// failingWriter fails every Write with err.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestStreamPushToMatchesPush(t *testing.T) {
+	chunks := []string{"{a:", "1}{b: 'x", "'} [1, 2", ", 3", "] {c: [", "true"}
+
+	s := NewStreamRepairer()
+	defer s.Close()
+	var want strings.Builder
+	for _, chunk := range chunks {
+		out, err := s.Push(chunk)
+		if err != nil {
+			t.Fatalf("Push(%q): %v", chunk, err)
+		}
+		want.WriteString(out)
+	}
+	out, err := s.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want.WriteString(out)
+
+	w := NewStreamRepairer()
+	defer w.Close()
+	var got bytes.Buffer
+	for _, chunk := range chunks {
+		if err := w.PushTo(&got, []byte(chunk)); err != nil {
+			t.Fatalf("PushTo(%q): %v", chunk, err)
+		}
+	}
+	if err := w.FlushTo(&got); err != nil {
+		t.Fatalf("FlushTo: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("PushTo/FlushTo wrote %q, Push/Flush returned %q", got.String(), want.String())
+	}
+	if !strings.HasSuffix(got.String(), `{"c":[true]}`) {
+		t.Errorf("output %q does not end with the flushed value", got.String())
+	}
+}
+
+func TestStreamPushToWriterError(t *testing.T) {
+	errWrite := errors.New("disk full")
+	s := NewStreamRepairer()
+	defer s.Close()
+	if err := s.PushTo(failingWriter{errWrite}, []byte("{a:")); err != nil {
+		t.Fatalf("PushTo without output: %v", err)
+	}
+	if err := s.PushTo(failingWriter{errWrite}, []byte("1}")); !errors.Is(err, errWrite) {
+		t.Errorf("PushTo err = %v, want %v", err, errWrite)
+	}
+	if err := s.PushTo(failingWriter{errWrite}, []byte("[1,")); err != nil {
+		t.Fatalf("PushTo without output: %v", err)
+	}
+	if err := s.FlushTo(failingWriter{errWrite}); !errors.Is(err, errWrite) {
+		t.Errorf("FlushTo err = %v, want %v", err, errWrite)
+	}
+}
+
+func TestRepairUTF16ByteOrder(t *testing.T) {
+	le := []byte{'{', 0, 'a', 0, ':', 0, '1', 0}
+	be := []byte{0, '{', 0, 'a', 0, ':', 0, '1'}
+	for _, tt := range []struct {
+		name   string
+		repair func([]byte) (string, error)
+		data   []byte
+	}{
+		{"LE", RepairUTF16, le},
+		{"BE", RepairUTF16BE, be},
+		// A BOM overrides the default byte order of either function.
+		{"LE with BOM", RepairUTF16BE, append([]byte{0xFF, 0xFE}, le...)},
+		{"BE with BOM", RepairUTF16, append([]byte{0xFE, 0xFF}, be...)},
+	} {
+		out, err := tt.repair(tt.data)
+		if err != nil || out != `{"a":1}` {
+			t.Errorf("%s: got %q, %v; want %q", tt.name, out, err, `{"a":1}`)
+		}
+	}
+	// Big-endian bytes read as little-endian are other characters.
+	if out, _ := RepairUTF16(be); out == `{"a":1}` {
+		t.Errorf("RepairUTF16 read BOM-less input as big-endian")
+	}
+}
@@ -1,97 +1,21 @@
+//go:build cgo && !purego
+
 package main
 
-/*
-#cgo LDFLAGS: -L../../target/release -ljsonrepair
-#include "../../include/jsonrepair.h"
-#include <stdlib.h>
-*/
-import "C"
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"unsafe"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// RepairJSON repairs a broken JSON string using default options
-func RepairJSON(input string) (string, error) {
-	cInput := C.CString(input)
-	defer C.free(unsafe.Pointer(cInput))
-
-	cResult := C.jsonrepair_repair(cInput)
-	if cResult == nil {
-		return "", fmt.Errorf("repair failed")
-	}
-	defer C.jsonrepair_free(cResult)
-
-	return C.GoString(cResult), nil
-}
-
-// RepairJSONWithOptions repairs JSON with custom options
-func RepairJSONWithOptions(input string, ensureASCII bool) (string, error) {
-	cInput := C.CString(input)
-	defer C.free(unsafe.Pointer(cInput))
-
-	opts := C.jsonrepair_options_new()
-	defer C.jsonrepair_options_free(opts)
-
-	C.jsonrepair_options_set_ensure_ascii(opts, C.bool(ensureASCII))
-
-	cResult := C.jsonrepair_repair_with_options(cInput, opts)
-	if cResult == nil {
-		return "", fmt.Errorf("repair failed")
-	}
-	defer C.jsonrepair_free(cResult)
-
-	return C.GoString(cResult), nil
-}
-
-// StreamRepairer wraps the C streaming API
-type StreamRepairer struct {
-	stream *C.JsonRepairStream
-}
-
-// NewStreamRepairer creates a new streaming repairer
-func NewStreamRepairer() *StreamRepairer {
-	return &StreamRepairer{
-		stream: C.jsonrepair_stream_new(nil),
-	}
-}
-
-// Push pushes a chunk and returns completed JSON if any
-func (s *StreamRepairer) Push(chunk string) (string, error) {
-	cChunk := C.CString(chunk)
-	defer C.free(unsafe.Pointer(cChunk))
-
-	cResult := C.jsonrepair_stream_push(s.stream, cChunk)
-	if cResult == nil {
-		return "", nil // No complete value yet
-	}
-	defer C.jsonrepair_free(cResult)
-
-	return C.GoString(cResult), nil
-}
-
-// Flush flushes remaining data
-func (s *StreamRepairer) Flush() (string, error) {
-	cResult := C.jsonrepair_stream_flush(s.stream)
-	if cResult == nil {
-		return "", nil
-	}
-	defer C.jsonrepair_free(cResult)
-
-	return C.GoString(cResult), nil
-}
-
-// Close frees the stream
-func (s *StreamRepairer) Close() {
-	if s.stream != nil {
-		C.jsonrepair_stream_free(s.stream)
-		s.stream = nil
-	}
-}
-
 func main() {
 	fmt.Println("jsonrepair Go Example")
-	fmt.Println("=====================\n")
+	fmt.Println("=====================")
+	fmt.Println()
 
 	// Example 1: Simple repair
 	fmt.Println("=== Simple Repair ===")
@@ -106,9 +30,9 @@ func main() {
 	fmt.Println()
 
 	// Example 2: With options
-	fmt.Println("=== With Options (ensure_ascii) ===")
-	broken = "{name: '统一码'}"
-	repaired, err = RepairJSONWithOptions(broken, true)
+	fmt.Println("=== With Options (EnsureASCII, TrimKeys) ===")
+	broken = "{'name ': '统一码'}"
+	repaired, err = Repair(broken, RepairOptions{EnsureASCII: true, TrimKeys: true})
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 	} else {
@@ -143,12 +67,383 @@ func main() {
 	}
 	fmt.Println()
 
-	// Example 4: Version
+	// Example 4: Minify (valid JSON only)
+	fmt.Println("=== Minify ===")
+	compact, err := Minify("{ \"a\": [1, 2],\n  \"b\": \"x y\" }")
+	fmt.Printf("Minified: %s (err: %v)\n", compact, err)
+	if _, err := Minify("{a: 1}"); errors.Is(err, ErrInvalidJSON) {
+		fmt.Printf("Rejected: %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 5: Version
 	fmt.Println("=== Version ===")
-	version := C.GoString(C.jsonrepair_version())
-	fmt.Printf("jsonrepair version: %s\n", version)
+	fmt.Printf("jsonrepair version: %s\n", Version())
+	if features, err := Features(); err == nil {
+		fmt.Printf("built with: %v\n", features["features"])
+	}
+	fmt.Println()
+
+	// Example 6: Server-Sent Events
+	fmt.Println("=== SSE Stream ===")
+	sse := NewSSEStreamRepairer()
+	defer sse.Close()
+
+	events := []string{
+		"event: delta\ndata: {\"answer\": \"he",
+		"llo\", n: 1}\n\ndata: {more:",
+		" true}\n\ndata: [DONE]\n\n",
+	}
+	for _, ev := range events {
+		out, err := sse.Push(ev)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else if out != "" {
+			fmt.Printf("  -> Got: %s\n", out)
+		}
+	}
+	if tail, _ := sse.Flush(); tail != "" {
+		fmt.Printf("Flush -> %s\n", tail)
+	}
+	fmt.Printf("Done: %v\n", sse.Done())
+	fmt.Println()
+
+	// Example 7: Validate-only streaming
+	fmt.Println("=== Stream Validation ===")
+	validator := NewStreamRepairer()
+	defer validator.Close()
+
+	statuses, err := validator.PushValidate("{\"ok\": true}\n{broken: 1}\n")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	for _, st := range statuses {
+		if st.Valid {
+			fmt.Printf("  valid:   %s\n", st.Text)
+		} else {
+			fmt.Printf("  invalid: %s (%v)\n", st.Text, st.Err)
+		}
+	}
+	fmt.Println()
+
+	// Example 8: Timeout
+	fmt.Println("=== Timeout ===")
+	huge := "[" + strings.Repeat("{a:1, b:'x'},", 300000)
+	_, err = Repair(huge, RepairOptions{Timeout: time.Millisecond})
+	if errors.Is(err, ErrTimeout) {
+		fmt.Printf("Timed out as expected: %v\n", err)
+	} else {
+		fmt.Printf("Unexpected result: %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 9: Garbage detector
+	fmt.Println("=== Max Repairs ===")
+	sloppy := "{name: bob, age: unknown, city: paris}"
+	_, err = Repair(sloppy, RepairOptions{MaxRepairs: 3})
+	if errors.Is(err, ErrTooManyRepairs) {
+		fmt.Printf("Rejected as garbage: %v\n", err)
+	} else {
+		fmt.Printf("Unexpected result: %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 10: Hashed repair
+	fmt.Println("=== Hashed ===")
+	out1, sum1, _ := RepairHashed("{a: 1}")
+	_, sum2, _ := RepairHashed("{'a': 1,}")
+	fmt.Printf("%s sha256=%x (same for both spellings: %v)\n", out1, sum1[:8], sum1 == sum2)
+	fmt.Println()
+
+	// Example 11: Extract one field
+	fmt.Println("=== Extract ===")
+	field, err := RepairExtract("{status: 'ok', result: {items: [1, 2,]}", "/result/items")
+	fmt.Printf("/result/items -> %s (err: %v)\n", field, err)
+	if _, err := RepairExtract("{a: 1}", "/b"); errors.Is(err, ErrPointerNotFound) {
+		fmt.Println("/b -> not found")
+	}
+	fmt.Println()
+
+	// Example 12: UTF-16 input
+	fmt.Println("=== UTF-16 ===")
+	utf16 := []byte{0xFF, 0xFE, '{', 0, 'a', 0, ':', 0, '1', 0, '}', 0}
+	repaired, err = RepairUTF16(utf16)
+	fmt.Printf("UTF-16LE (BOM) -> %s (err: %v)\n", repaired, err)
+	repaired, err = RepairUTF16BE([]byte{0, '[', 0, '1', 0, ','})
+	fmt.Printf("UTF-16BE (no BOM) -> %s (err: %v)\n", repaired, err)
+	fmt.Println()
+
+	// Example 13: Stream recovery
+	fmt.Println("=== Stream Recovery ===")
+	resilient := NewStreamRepairerWithOptions(RepairOptions{MaxRepairs: 2})
+	defer resilient.Close()
+	resilient.SetRecover(func(skipped Status) {
+		fmt.Printf("  skipped: %q (%v)\n", skipped.Text, skipped.Err)
+	})
+	for _, chunk := range []string{"{\"a\": 1}\n", "{x y z w}\nnoise ", "{\"b\": 2}\n"} {
+		if out, _ := resilient.Push(chunk); out != "" {
+			fmt.Printf("  -> Got: %s\n", out)
+		}
+	}
+	fmt.Println()
+
+	// Example 14: Repair into a typed value
+	fmt.Println("=== RepairInto ===")
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	u, err := RepairInto[user]([]byte("{name: 'John', age: 30,}"))
+	fmt.Printf("%+v (err: %v)\n", u, err)
+	if _, err := RepairInto[user]([]byte("[1, 2]")); errors.Is(err, ErrUnmarshal) {
+		fmt.Printf("[1, 2] -> %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 15: Stream straight into an io.Writer
+	fmt.Println("=== PushTo ===")
+	piped := NewStreamRepairer()
+	defer piped.Close()
+	for _, chunk := range []string{"{a: 1", "}\n[1, 2", ",]\n"} {
+		if err := piped.PushTo(os.Stdout, []byte(chunk)); err != nil {
+			fmt.Println("push error:", err)
+		}
+	}
+	if err := piped.FlushTo(os.Stdout); err != nil {
+		fmt.Println("flush error:", err)
+	}
+	fmt.Println()
+	fmt.Println()
+
+	// Example 16: Split concatenated values
+	fmt.Println("=== RepairSplit ===")
+	values, err := RepairSplit("{a:1}{b:2}\n[1,2,]")
+	if err != nil {
+		fmt.Println("split error:", err)
+	}
+	for i, v := range values {
+		fmt.Printf("%d: %s\n", i, v)
+	}
+	fmt.Println()
+
+	// Example 17: Frame the first value of a buffer
+	fmt.Println("=== RepairFirst ===")
+	buffer := "{id: 1, op: 'ping'}{id: 2"
+	first, used, err := RepairFirst(buffer)
+	if err != nil {
+		fmt.Println("first error:", err)
+	}
+	fmt.Printf("%s (consumed %d, rest %q)\n", first, used, buffer[used:])
+	fmt.Println()
+
+	// Example 18: Report progress while repairing a large input
+	fmt.Println("=== OnProgress ===")
+	large := "[" + strings.Repeat("{id: 1, tags: ['a', 'b']},", 5000) + "]"
+	reports := 0
+	_, err = Repair(large, RepairOptions{OnProgress: func(done, total int64) {
+		reports++
+		if done == total {
+			fmt.Printf("done: %d bytes after %d reports\n", total, reports)
+		}
+	}})
+	if err != nil {
+		fmt.Println("progress error:", err)
+	}
+	fmt.Println()
+
+	// Example 19: Bound the width of untrusted input
+	fmt.Println("=== Max Elements ===")
+	wide := "[" + strings.Repeat("1,", 10000) + "]"
+	_, err = Repair(wide, RepairOptions{MaxElements: 1000})
+	if errors.Is(err, ErrTooManyElements) {
+		fmt.Printf("Rejected as too wide: %v\n", err)
+	} else {
+		fmt.Printf("Unexpected result: %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 20: Repair categories known to the linked library
+	fmt.Println("=== Repair Categories ===")
+	categories := RepairCategories()
+	fmt.Printf("%d categories, e.g. %q\n", len(categories), categories[0])
+	fmt.Println()
+
+	// Example 21: Map streamed values back to their input bytes
+	fmt.Println("=== Value Ranges ===")
+	input := "{a: 1}\n[1, 2,"
+	ranged := NewStreamRepairer()
+	ranged.OnValueRange(func(value string, start, end int64) {
+		fmt.Printf("%s <- %q\n", value, input[start:end])
+	})
+	ranged.Push(input[:9])
+	ranged.Push(input[9:])
+	ranged.Flush()
+	ranged.Close()
+	fmt.Println()
+
+	// Example 22: Check which options this build applies
+	fmt.Println("=== Supported Options ===")
+	fmt.Printf("logging: %v, error-as-json: %v\n",
+		OptionSupported(OptionLogging), OptionSupported(OptionErrorAsJSON))
+	fmt.Println()
+
+	// Example 23: Compact and pretty output from one repair
+	fmt.Println("=== Compact and Pretty ===")
+	compact, pretty, err := RepairBoth("{name: 'demo', tags: [a, b,]}", 2)
+	fmt.Printf("compact: %s\npretty:\n%s\n(err: %v)\n", compact, pretty, err)
+	fmt.Println()
+
+	// Example 24: Typed errors with a location
+	fmt.Println("=== Error Details ===")
+	_, err = RepairJSON("{\n  \"a\": \"x\xff\"\n}")
+	var repairErr *RepairError
+	if errors.As(err, &repairErr) && errors.Is(err, ErrInvalidUTF8) {
+		fmt.Printf("invalid UTF-8 at line %d, column %d (byte %d)\n",
+			repairErr.Line, repairErr.Column, repairErr.Position)
+	} else {
+		fmt.Printf("Unexpected result: %v\n", err)
+	}
+	fmt.Println()
+
+	// Example 25: Write one large repaired value in chunks
+	fmt.Println("=== RepairToWriter ===")
+	counter := &chunkCounter{}
+	err = RepairToWriter(counter, "["+strings.Repeat("{k: 'v'},", 20000)+"]", RepairOptions{})
+	fmt.Printf("%d bytes in %d chunks (err: %v)\n", counter.bytes, counter.chunks, err)
+	fmt.Println()
+
+	// Example 26: Keep comments next to the clean output
+	fmt.Println("=== RepairWithComments ===")
+	clean, comments, err := RepairWithComments("{\n  // retries before giving up\n  retries: 3,\n  delay: 10, // ms\n}")
+	fmt.Printf("%s (err: %v)\n", clean, err)
+	for _, p := range []string{"/retries", "/delay"} {
+		fmt.Printf("  %s: %s\n", p, comments[p])
+	}
+	fmt.Println()
+
+	// Example 27: Route on the type of the repaired value
+	fmt.Println("=== RepairWithType ===")
+	for _, in := range []string{"{id: 1}", "[1, 2,", "'hi'"} {
+		out, kind, _ := RepairWithType(in)
+		switch kind {
+		case ValueKindObject:
+			fmt.Println("object:", out)
+		case ValueKindArray:
+			fmt.Println("array:", out)
+		default:
+			fmt.Println("scalar:", out)
+		}
+	}
+	fmt.Println()
+
+	// Example 28: Trace the parser's decisions
+	fmt.Println("=== Trace ===")
+	var trace strings.Builder
+	repaired, err = Repair("{a: 1 b: 'x'}", RepairOptions{Trace: &trace})
+	fmt.Printf("%s (err: %v)\n%s", repaired, err, trace.String())
+	fmt.Println()
+
+	// Example 29: Detect whether a repair changed anything
+	fmt.Println("=== RepairChanged ===")
+	for _, in := range []string{`{"a": 1}`, "{a: 1}"} {
+		out, changed, _ := RepairChanged(in)
+		fmt.Printf("%s -> %s (changed: %v)\n", in, out, changed)
+	}
+	fmt.Println()
+
+	// Example 30: Keep key order for a config that is written back
+	fmt.Println("=== RepairToOrderedMap ===")
+	config, err := RepairToOrderedMap([]byte("{zeta: 1, alpha: {b: 2, a: 3}, zeta: 4}"))
+	if err == nil {
+		zeta, _ := config.Get("zeta")
+		written, _ := json.Marshal(config)
+		fmt.Printf("keys %v, zeta=%v\n%s\n", config.Keys(), zeta, written)
+	}
+	fmt.Println()
+
+	// Example 31: See the shape of unfamiliar data
+	fmt.Println("=== RepairSkeleton ===")
+	skeleton, err := RepairSkeleton("{id: 7, tags: ['a', 'b'], items: [{n: 1}, {n: 2}, null]}")
+	fmt.Printf("%s (err: %v)\n", skeleton, err)
+	fmt.Println()
+
+	// Example 32: Keep-alive reports while a large value is still arriving
+	fmt.Println("=== Heartbeat ===")
+	slow := NewStreamRepairer()
+	slow.OnHeartbeat(1000, func(bytesIn int64, buffered int) {
+		fmt.Printf("  alive: %d bytes in, %d buffered\n", bytesIn, buffered)
+	})
+	slow.Push("{blob: '")
+	for i := 0; i < 3; i++ {
+		slow.Push(strings.Repeat("x", 600))
+	}
+	done, _ := slow.Push("'}\n")
+	fmt.Printf("  -> Got %d bytes\n", len(done))
+	slow.Close()
+	fmt.Println()
+
+	// Example 33: Review only the values that were repaired
+	fmt.Println("=== RepairAffectedPaths ===")
+	reviewed, paths, err := RepairAffectedPaths(`{"user": {"name": 'Ann', "age": 30}, "tags": ["a", tru]}`)
+	fmt.Printf("%s (err: %v)\n", reviewed, err)
+	for _, p := range paths {
+		fmt.Printf("  repaired: %s\n", p)
+	}
+	fmt.Println()
+
+	// Example 34: Pass repair metadata along with the value
+	fmt.Println("=== RepairEnvelope ===")
+	for _, in := range []string{`{"a": 1}`, `{a: 1, b: 'x'}`} {
+		env, err := RepairEnvelope([]byte(in), RepairOptions{})
+		fmt.Printf("%s -> %s (repaired: %v, repairs: %d, err: %v)\n", in, env.Value, env.Repaired, env.Repairs, err)
+	}
+	fmt.Println()
+
+	// Example 35: Advance a read cursor past the values in a buffer
+	fmt.Println("=== RepairWithEnd ===")
+	buffered := "{id: 1} {id: 2}\n<partial frame"
+	for _, opts := range []RepairOptions{{}, {StopAfterFirst: true}} {
+		out, end, err := RepairWithEnd(buffered, opts)
+		fmt.Printf("%s (end %d, rest %q, err: %v)\n", out, end, buffered[end:], err)
+	}
+	fmt.Println()
+
+	// Example 36: Give prices two decimals
+	fmt.Println("=== FormatNumbers ===")
+	twoDecimals := func(raw string) string {
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return raw
+		}
+		return strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	priced, err := Repair("{price: 12.5, qty: 3, sku: '100'}", RepairOptions{FormatNumbers: twoDecimals})
+	fmt.Printf("%s (err: %v)\n", priced, err)
+	fmt.Println()
+
+	// Example 37: Read export sentinels as null
+	fmt.Println("=== NullTokens ===")
+	exported, err := Repair("[{id: 1, price: N/A}, {id: 2, price: -}]", RepairOptions{NullTokens: []string{"N/A", "-"}})
+	fmt.Printf("%s (err: %v)\n", exported, err)
+	fmt.Println()
+
+	// Example 38: Store equal data as equal bytes
+	fmt.Println("=== StorageOptions ===")
+	stored, _ := Repair("{\n  \"b\": 1.50E+03,\n  \"a\": \"caf\\u00e9\"\n}\n", StorageOptions())
+	respelled, _ := Repair("{a: 'café', b: 1.5e3}", StorageOptions())
+	fmt.Printf("%s (same bytes: %v)\n", stored, stored == respelled)
 	fmt.Println()
 
 	fmt.Println("All examples completed!")
 }
 
+// chunkCounter counts the writes it receives and the bytes in them.
+type chunkCounter struct {
+	chunks, bytes int
+}
+
+func (c *chunkCounter) Write(p []byte) (int, error) {
+	c.chunks++
+	c.bytes += len(p)
+	return len(p), nil
+}
@@ -0,0 +1,48 @@
+//go:build !cgo || purego
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// main runs the examples the pure-Go fallback supports; main.go has the full
+// set, which needs the Rust library.
+func main() {
+	fmt.Println("jsonrepair Go Example (pure-Go fallback)")
+	fmt.Println("=========================================")
+	fmt.Println()
+
+	// Example 1: Simple repair
+	fmt.Println("=== Simple Repair ===")
+	input := "{a:1, b:'hello', // note\n tags: [True, None,], c: it's}"
+	output, err := RepairJSON(input)
+	fmt.Printf("Input:  %s\n", input)
+	fmt.Printf("Output: %s (err: %v)\n", output, err)
+	fmt.Println()
+
+	// Example 2: EnsureASCII
+	fmt.Println("=== With Options (EnsureASCII) ===")
+	output, err = RepairJSONWithOptions("{'name': '统一码'}", true)
+	fmt.Printf("Output: %s (err: %v)\n", output, err)
+	fmt.Println()
+
+	// Example 3: Streaming
+	fmt.Println("=== Streaming ===")
+	stream := NewStreamRepairer()
+	defer stream.Close()
+	for _, chunk := range []string{"{a:", "1}", "{b:", "2}"} {
+		out, err := stream.Push(chunk)
+		fmt.Printf("Push: %-4s -> %q (err: %v)\n", chunk, out, err)
+	}
+	fmt.Println()
+
+	// Example 4: Options the fallback does not implement
+	fmt.Println("=== Unsupported Option ===")
+	_, err = Repair("{a: 1}", RepairOptions{SortKeys: true})
+	fmt.Printf("%v (unsupported: %v)\n", err, errors.Is(err, ErrUnsupportedOption))
+	fmt.Println()
+
+	fmt.Println("All examples completed!")
+}
@@ -0,0 +1,48 @@
+//go:build cgo && !purego
+
+package main
+
+/*
+#include "../../include/jsonrepair.h"
+#include <stdint.h>
+#include <stdlib.h>
+
+extern char *jsonrepairGoFormatNumber(char *raw, void *userdata);
+*/
+import "C"
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+// numberFormat is the state behind a number format callback: the Go function
+// and the C copy of its last result, which the library reads before the next
+// call.
+type numberFormat struct {
+	format func(raw string) string
+	last   *C.char
+}
+
+// setNumberFormat installs format as the number format callback of cOpts and
+// returns the function that releases it, as setProgress does.
+func setNumberFormat(cOpts *C.Options, format func(raw string) string) (release func()) {
+	state := &numberFormat{format: format}
+	h := cgo.NewHandle(state)
+	slot := (*C.uintptr_t)(C.malloc(C.sizeof_uintptr_t))
+	*slot = C.uintptr_t(h)
+	C.jsonrepair_options_set_number_format_callback(cOpts, C.JsonRepairNumberFormatFn(C.jsonrepairGoFormatNumber), unsafe.Pointer(slot))
+	return func() {
+		C.jsonrepair_options_set_number_format_callback(cOpts, nil, nil)
+		C.free(unsafe.Pointer(slot))
+		C.free(unsafe.Pointer(state.last))
+		h.Delete()
+	}
+}
+
+//export jsonrepairGoFormatNumber
+func jsonrepairGoFormatNumber(raw *C.char, userdata unsafe.Pointer) *C.char {
+	state := cgo.Handle(*(*C.uintptr_t)(userdata)).Value().(*numberFormat)
+	C.free(unsafe.Pointer(state.last))
+	state.last = C.CString(state.format(C.GoString(raw)))
+	return state.last
+}
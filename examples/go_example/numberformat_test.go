@@ -0,0 +1,55 @@
+//go:build cgo && !purego
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func twoDecimals(raw string) string {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func TestFormatNumbers(t *testing.T) {
+	var seen []string
+	opts := RepairOptions{FormatNumbers: func(raw string) string {
+		seen = append(seen, raw)
+		return twoDecimals(raw)
+	}}
+	input := "{price: 12.5, qty: 3, sku: '100', 7: [-2e1]}"
+	want := `{"price":12.50,"qty":3.00,"sku":"100","7":[-20.00]}`
+
+	out, err := Repair(input, opts)
+	if err != nil || out != want {
+		t.Fatalf("Repair = %q, %v; want %q", out, err, want)
+	}
+	// Quoted digits and keys are not passed to the callback.
+	if wantSeen := []string{"12.5", "3", "-2e1"}; !reflect.DeepEqual(seen, wantSeen) {
+		t.Errorf("callback saw %q, want %q", seen, wantSeen)
+	}
+
+	out, _, err = RepairWithEnd(input, opts)
+	if err != nil || out != want {
+		t.Errorf("RepairWithEnd = %q, %v; want %q", out, err, want)
+	}
+	var buf bytes.Buffer
+	if err := RepairToWriter(&buf, input, opts); err != nil || buf.String() != want {
+		t.Errorf("RepairToWriter = %q, %v; want %q", buf.String(), err, want)
+	}
+}
+
+func TestFormatNumbersInvalidResult(t *testing.T) {
+	_, err := Repair("[1, 2]", RepairOptions{FormatNumbers: func(string) string { return "one" }})
+	var e *RepairError
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %v, want an *RepairError", err)
+	}
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"io"
+	"time"
+)
+
+// MissingValues selects how an object key without a value ({"a":} or {"a"})
+// is repaired. The values match the C JsonRepairMissingValues enum.
+type MissingValues int
+
+const (
+	// MissingAsEmptyString fills the value with "" (library default).
+	MissingAsEmptyString MissingValues = iota
+	// MissingAsNull fills the value with null.
+	MissingAsNull
+	// MissingDropKey drops the key.
+	MissingDropKey
+)
+
+// ASCIIScope selects which strings get non-ASCII characters escaped as
+// \uXXXX. The values match the C JsonRepairAsciiScope enum.
+type ASCIIScope int
+
+const (
+	// ASCIINone keeps UTF-8 everywhere (library default).
+	ASCIINone ASCIIScope = iota
+	// ASCIIKeysOnly escapes object keys only.
+	ASCIIKeysOnly
+	// ASCIIValuesOnly escapes values only.
+	ASCIIValuesOnly
+	// ASCIIAll escapes keys and values, like EnsureASCII.
+	ASCIIAll
+)
+
+// DedupPosition selects whether and where duplicate object keys collapse to
+// their last value. The values match the C JsonRepairDedupPosition enum.
+type DedupPosition int
+
+const (
+	// DedupKeepAll keeps every duplicate (library default).
+	DedupKeepAll DedupPosition = iota
+	// DedupFirstPosition keeps the last value at the key's first position.
+	DedupFirstPosition
+	// DedupLastPosition keeps the last value at its own position.
+	DedupLastPosition
+)
+
+// StrayTokens selects how a bare non-keyword array element ([1, garbage, 2])
+// is repaired. The values match the C JsonRepairStrayTokens enum.
+type StrayTokens int
+
+const (
+	// StrayError fails with an *RepairError (PARSE code) positioned at the token
+	// (library default).
+	StrayError StrayTokens = iota
+	// StrayQuote quotes the token as a string.
+	StrayQuote
+	// StrayDrop drops the element.
+	StrayDrop
+)
+
+// ForceContainer selects how a scalar top-level result is wrapped so the
+// output is always an object or array. The values match the C
+// JsonRepairForceContainer enum.
+type ForceContainer int
+
+const (
+	// ForceOff leaves scalars as they are (library default).
+	ForceOff ForceContainer = iota
+	// ForceArray wraps a scalar as [value].
+	ForceArray
+	// ForceObject wraps a scalar as {"value":value}.
+	ForceObject
+)
+
+// Salvage selects what replaces an array element or object value that cannot
+// be repaired. The values match the C JsonRepairSalvage enum.
+type Salvage int
+
+const (
+	// SalvageFail fails the whole repair (library default).
+	SalvageFail Salvage = iota
+	// SalvageNull substitutes null for the bad value.
+	SalvageNull
+	// SalvageMarker substitutes {"$unrepairable": "<source text>"}.
+	SalvageMarker
+)
+
+// LineContinuation selects what a backslash directly before a line break
+// inside a string becomes. The values match the C JsonRepairLineContinuations
+// enum.
+type LineContinuation int
+
+const (
+	// ContinuationElide joins the lines (library default).
+	ContinuationElide LineContinuation = iota
+	// ContinuationKeep keeps the line break, escaped as \n.
+	ContinuationKeep
+)
+
+// Strictness selects how far repairs that guess at structure go. The values
+// match the C JsonRepairStrictness enum.
+type Strictness int
+
+const (
+	// StrictnessConservative fails where the structure is ambiguous, such as
+	// {a: b: c} (library default).
+	StrictnessConservative Strictness = iota
+	// StrictnessAggressive reads {a: b: c} as {"a":{"b":"c"}}, [a: 1, b: 2] as
+	// [{"a":1},{"b":2}] and {1, 2} as [1,2].
+	StrictnessAggressive
+)
+
+// UTF8Strictness selects what happens to input that is not valid UTF-8. The
+// values match the C JsonRepairUtf8Strictness enum.
+type UTF8Strictness int
+
+const (
+	// UTF8Strict rejects invalid input, overlong encodings included, with an
+	// error matching ErrInvalidUTF8 (library default).
+	UTF8Strict UTF8Strictness = iota
+	// UTF8Lenient replaces each invalid sequence with U+FFFD.
+	UTF8Lenient
+)
+
+// LongKeys selects what happens to a key longer than MaxKeyLen. The values
+// match the C JsonRepairLongKeys enum.
+type LongKeys int
+
+const (
+	// LongKeysError fails with ErrKeyTooLong (library default).
+	LongKeysError LongKeys = iota
+	// LongKeysTruncate cuts the key at the last UTF-8 character boundary
+	// within the limit.
+	LongKeysTruncate
+)
+
+// OutputFormat selects the output syntax. The values match the C
+// JsonRepairOutputFormat enum.
+type OutputFormat int
+
+const (
+	// FormatJSON emits strict JSON (library default).
+	FormatJSON OutputFormat = iota
+	// FormatJSON5 emits JSON5: identifier keys unquoted, strings single-quoted.
+	FormatJSON5
+)
+
+// CompactSpacing selects the whitespace between tokens. The values match the C
+// JsonRepairCompactSpacing enum.
+type CompactSpacing int
+
+const (
+	// SpacingNone emits no whitespace (library default).
+	SpacingNone CompactSpacing = iota
+	// SpacingMinimal emits one space after every ':' and ','.
+	SpacingMinimal
+)
+
+// Overflow selects what happens to a number too large for a float64, such as
+// 1e400. The values match the C JsonRepairOverflow enum.
+type Overflow int
+
+const (
+	// OverflowKeep keeps the literal (library default).
+	OverflowKeep Overflow = iota
+	// OverflowQuote quotes the literal as a string, keeping every digit.
+	OverflowQuote
+	// OverflowNull replaces the number with null.
+	OverflowNull
+)
+
+// NumberSuffix selects what happens to a number with a unit suffix, such as
+// 30s or 10MB. The values match the C JsonRepairNumberSuffix enum.
+type NumberSuffix int
+
+const (
+	// SuffixKeep applies the general number rules (library default).
+	SuffixKeep NumberSuffix = iota
+	// SuffixQuote quotes the whole token: 30s -> "30s".
+	SuffixQuote
+	// SuffixStrip keeps the number and drops the suffix: 10MB -> 10.
+	SuffixStrip
+)
+
+// NegativeZero selects what happens to the sign of a zero such as -0. The
+// values match the C JsonRepairNegativeZero enum.
+type NegativeZero int
+
+const (
+	// NegativeZeroPreserve keeps the sign as written (library default).
+	NegativeZeroPreserve NegativeZero = iota
+	// NegativeZeroNormalize drops the sign: -0 -> 0, -0.0 -> 0.0.
+	NegativeZeroNormalize
+)
+
+// SafeIntegers selects what happens to an integer beyond the JavaScript safe
+// range, 2^53-1. The values match the C JsonRepairSafeIntegers enum.
+type SafeIntegers int
+
+const (
+	// SafeIntegersPassthrough keeps the integer as written (library default).
+	SafeIntegersPassthrough SafeIntegers = iota
+	// SafeIntegersClamp replaces it with 9007199254740991 or -9007199254740991.
+	SafeIntegersClamp
+	// SafeIntegersQuote quotes it as a string, keeping every digit.
+	SafeIntegersQuote
+)
+
+// UnwrapMode selects how a typed wrapper such as UUID("...") is unwrapped.
+// The values match the C JsonRepairUnwrapMode enum.
+type UnwrapMode int
+
+const (
+	// UnwrapString keeps the argument as a string.
+	UnwrapString UnwrapMode = iota
+	// UnwrapNumber makes a numeric argument a number (NumberLong("42") -> 42).
+	// NaN and Infinity become null unless DisableNonFiniteNormalization is set.
+	UnwrapNumber
+	// UnwrapNull replaces the call with null.
+	UnwrapNull
+)
+
+// BracketKind selects which brackets a BracketAlias stands for. The values
+// match the C JsonRepairBracketKind enum.
+type BracketKind int
+
+const (
+	// BracketObject reads the words as { and }.
+	BracketObject BracketKind = iota
+	// BracketArray reads the words as [ and ].
+	BracketArray
+)
+
+// BracketAlias is a keyword pair that stands for brackets, such as BEGIN/END
+// in a legacy export.
+type BracketAlias struct {
+	Open, Close string
+	Kind        BracketKind
+}
+
+// Built-in delimiter pairs for RepairOptions.AltQuoteChars, opening then
+// closing character; concatenate them to accept both.
+const (
+	// GuillemetQuotes reads «...» and ‹...› as double-quoted strings.
+	GuillemetQuotes = "«»‹›"
+	// CJKCornerQuotes reads 「...」 and 『...』 as double-quoted strings.
+	CJKCornerQuotes = "「」『』"
+)
+
+// RepairOptions mirrors every jsonrepair_options_set_* setter of the C API.
+// The zero value matches the library defaults, so options that default to on
+// are exposed as Disable* fields.
+type RepairOptions struct {
+	// EnsureASCII escapes non-ASCII characters as \uXXXX.
+	EnsureASCII bool
+	// ASCIIScope limits escaping to keys or values; EnsureASCII overrides it.
+	ASCIIScope ASCIIScope
+	// DisablePythonKeywords stops mapping True/False/None to JSON literals.
+	DisablePythonKeywords bool
+	// DisableCaseInsensitiveKeywords keeps TRUE, FALSE, Null and NULL as
+	// strings instead of lowercasing them (True/False/None follow
+	// DisablePythonKeywords).
+	DisableCaseInsensitiveKeywords bool
+	// AbbreviatedKeywords reads a lone t, f or n value as true, false or null.
+	// Longer words such as tf, t-shirt and n/a stay strings.
+	AbbreviatedKeywords bool
+	// NullTokens lists bare values that mean "missing" in the data, such as
+	// "N/A", "-" or "nil"; each one standing alone as a value becomes null.
+	// Matching is exact and case-sensitive.
+	NullTokens []string
+	// DisableHashComments stops treating # as a line comment.
+	DisableHashComments bool
+	// SQLComments treats "-- " as a line comment.
+	SQLComments bool
+	// DisableUndefinedRepair keeps `undefined` instead of converting it to null.
+	DisableUndefinedRepair bool
+	// DisableFencedCodeBlocks stops stripping ```json fences around the input.
+	DisableFencedCodeBlocks bool
+	// DisableNonFiniteNormalization keeps NaN/Infinity instead of emitting null.
+	DisableNonFiniteNormalization bool
+	// DisableLeadingDotNumbers stops reading ".25" as 0.25.
+	DisableLeadingDotNumbers bool
+	// DisableTrailingDotNumbers stops reading "1." as 1.0.
+	DisableTrailingDotNumbers bool
+	// Logging enables repair logging in the library.
+	Logging bool
+	// PythonStyleSeparators formats output with ", " and ": " separators.
+	PythonStyleSeparators bool
+	// AggressiveTruncationFix closes heavily truncated containers early.
+	AggressiveTruncationFix bool
+	// RejectIfInvalid fails with ErrInvalidJSON instead of repairing non-JSON input.
+	RejectIfInvalid bool
+	// Timeout bounds the wall-clock time of a single repair (millisecond
+	// granularity, checked periodically). Zero means no limit.
+	Timeout time.Duration
+	// OnProgress is called with the input bytes parsed so far and the total
+	// during Repair, at most about 100 times plus once on completion.
+	// Streams do not report progress.
+	OnProgress func(done, total int64)
+	// FormatNumbers, when set, is called with each number of the output
+	// ("1.5", "-2e3") during Repair, RepairWithEnd and RepairToWriter and its
+	// result written instead, e.g. to give currency two decimals. Quoted digits
+	// and keys are not passed. A result that is not a JSON number fails the
+	// repair.
+	FormatNumbers func(raw string) string
+	// Trace, when set, receives a line per parser decision made during Repair
+	// ("@6 repair: inserted missing comma"), for finding out why an input
+	// repairs the way it does. Only the recursive engine records one.
+	Trace io.Writer
+	// MaxRepairs fails with ErrTooManyRepairs once more than this many fixes
+	// are needed. Zero means no limit.
+	MaxRepairs int
+	// MaxElements fails with ErrTooManyElements once an array or object has
+	// more than this many members. Zero means no limit.
+	MaxElements int
+	// MaxKeyLen fails with ErrKeyTooLong, or truncates per LongKeys, once an
+	// object key is longer than this many bytes. Zero means no limit.
+	MaxKeyLen int
+	// LongKeys selects whether a key over MaxKeyLen fails or is truncated.
+	LongKeys LongKeys
+	// MaxInputBytes fails with ErrInputTooLarge, before any work is done, when
+	// the input is longer than this many bytes. Zero means no limit.
+	MaxInputBytes int
+	// OutputBOM prefixes the output with a single UTF-8 BOM.
+	OutputBOM bool
+	// RawMessageSafe guarantees compact output with no surrounding whitespace,
+	// trailing newline or BOM, so it can be used as a json.RawMessage as is.
+	// It takes precedence over OutputBOM, IndentDetect and CompactSpacing.
+	RawMessageSafe bool
+	// TrimKeys trims whitespace around object keys.
+	TrimKeys bool
+	// UnwrapEscapedJSON unwraps a top-level string that contains escaped JSON,
+	// and string values holding JSON encoded two or more times.
+	UnwrapEscapedJSON bool
+	// MissingValues selects how keys without a value are repaired.
+	MissingValues MissingValues
+	// NormalizeNumbers emits numbers in one canonical spelling (1.50E+03 -> 1.5e3).
+	NormalizeNumbers bool
+	// Overflow selects how numbers that overflow a float64 (1e400) are emitted.
+	Overflow Overflow
+	// NumberSuffix selects how numbers with a unit suffix (30s, 10MB, 75%) are emitted.
+	NumberSuffix NumberSuffix
+	// NegativeZero selects whether the sign of -0, -0.0 and -0e5 is kept.
+	NegativeZero NegativeZero
+	// SafeIntegers selects how integers beyond +/-(2^53-1) are emitted.
+	SafeIntegers SafeIntegers
+	// UnwrapFunctions registers more Name(arg) wrappers to replace with their
+	// argument, on top of the built-in ObjectId, ISODate and Number* ones.
+	UnwrapFunctions map[string]UnwrapMode
+	// DisableBuiltinUnwrap drops the built-in wrappers, leaving only UnwrapFunctions.
+	DisableBuiltinUnwrap bool
+	// BracketAliases reads keyword pairs as brackets outside strings, so with
+	// {"BEGIN", "END", BracketObject} the input BEGIN a: 1 END becomes {"a":1}.
+	BracketAliases []BracketAlias
+	// ErrorAsJSON makes Repair return {"error":"...","offset":N} alongside the
+	// error for input that cannot be repaired, for callers that always want JSON.
+	ErrorAsJSON bool
+	// DisableStripEllipsis keeps bare ... placeholders ([1, 2, ...]) as the
+	// string "..." instead of dropping them.
+	DisableStripEllipsis bool
+	// EqualsSeparators accepts `=`, `=>` and `->` between keys and values.
+	EqualsSeparators bool
+	// DedupPosition collapses duplicate keys to their last value.
+	DedupPosition DedupPosition
+	// DecodeBase64 decodes an all-base64 input that holds an object or array.
+	DecodeBase64 bool
+	// AnnotateSource appends /* @src:OFFSET */ after each value; requires
+	// OutputFormat = FormatJSON5.
+	AnnotateSource bool
+	// StrayTokens selects how bare non-keyword array elements are repaired.
+	StrayTokens StrayTokens
+	// ForceContainer wraps a scalar top-level result in an array or object.
+	ForceContainer ForceContainer
+	// Envelope wraps the output in {"value":...,"repaired":...,"repairs":N};
+	// RepairEnvelope decodes it.
+	Envelope bool
+	// FixMojibake repairs Windows-1252 mojibake quotes and dashes (â€œkeyâ€).
+	FixMojibake bool
+	// AltQuoteChars lists extra string delimiters as opening/closing character
+	// pairs, such as GuillemetQuotes; they are kept inside ASCII-quoted strings.
+	AltQuoteChars string
+	// LineContinuations decides whether a backslash-newline inside a string
+	// joins the lines ("foo\<LF>bar" -> "foobar") or keeps the break.
+	LineContinuations LineContinuation
+	// FixBackslashes keeps a lone backslash that starts no valid escape, so
+	// "C:\Users" stays a Windows path instead of becoming "C:Users". Genuine
+	// escapes such as \n and \u00e9 are still decoded.
+	FixBackslashes bool
+	// Salvage replaces a nested value that fails to repair instead of failing.
+	Salvage Salvage
+	// DropPlaceholder, when not empty, is written in place of an element
+	// dropped by StrayDrop or a value nulled by SalvageNull; JSON text is kept
+	// as a value, anything else becomes a string.
+	DropPlaceholder string
+	// Strictness decides whether {a: b: c} fails or nests as {"a":{"b":"c"}}.
+	Strictness Strictness
+	// BracketsAsObjects reads ["a": 1, "b": 2] as {"a":1,"b":2} whatever the
+	// Strictness.
+	BracketsAsObjects bool
+	// UTF8Strictness decides whether invalid UTF-8 in the input fails or is
+	// replaced with U+FFFD.
+	UTF8Strictness UTF8Strictness
+	// OutputFormat selects strict JSON or JSON5 output.
+	OutputFormat OutputFormat
+	// CommaDecimal reads {"price": 3,14} as 3.14 where the comma is unambiguous.
+	CommaDecimal bool
+	// EvalFractions reads {"ratio": 1/2} as 0.5. Both sides must be bare
+	// integers, so paths and dates such as /usr/bin, 1/2/3 and 01/02 stay
+	// strings.
+	EvalFractions bool
+	// DedupArrays drops repeated scalar array elements, keeping the first.
+	DedupArrays bool
+	// SortKeys orders the members of every object by key, at every depth.
+	SortKeys bool
+	// EscapeSlashes emits "/" inside strings as "\/" for HTML embedding.
+	EscapeSlashes bool
+	// MinimalEscapes writes strings with only the escapes JSON requires
+	// (", \ and control characters), so \/ and \u0041 become / and A.
+	MinimalEscapes bool
+	// DisableShortEscapes writes control characters in strings as \u00XX
+	// instead of \t, \n, \r, \b and \f.
+	DisableShortEscapes bool
+	// StripTrailingLineWS removes spaces and tabs before each line break
+	// inside multi-line string values.
+	StripTrailingLineWS bool
+	// CollapseWS turns each run of whitespace inside string values into a
+	// single space. It changes the data, so use it for prose only.
+	CollapseWS bool
+	// CollapseWSNewlines also folds line breaks into the run under CollapseWS.
+	CollapseWSNewlines bool
+	// AddMissingBrackets wraps a bare body ("a": 1 or 1, 2) in {} or [].
+	AddMissingBrackets bool
+	// ParensAsArrays reads (1, 2, 3) in value position as [1,2,3].
+	ParensAsArrays bool
+	// CompactSpacing controls the whitespace between tokens of the output.
+	CompactSpacing CompactSpacing
+	// IndentDetect pretty-prints the output with the input's own indentation
+	// unit (tabs or N spaces); one-line input stays compact.
+	IndentDetect bool
+	// AlignValues pads keys so the values of each object share a column; it
+	// needs IndentDetect and indented input.
+	AlignValues bool
+	// CRLF ends the lines of IndentDetect output with \r\n instead of \n;
+	// newlines inside strings stay escaped.
+	CRLF bool
+	// ConcatAdjacentStrings joins strings separated only by whitespace
+	// ("line1"\n"line2" reads as "line1line2").
+	ConcatAdjacentStrings bool
+	// WrapFragments assembles newline-separated `key = value` lines into an object
+	// and a row of quoted CSV fields ("a","b","c") into an array.
+	WrapFragments bool
+	// ExtractEmbedded repairs only the first balanced {...} or [...] found in
+	// surrounding prose.
+	ExtractEmbedded bool
+	// StreamNDJSONAggregate collects streamed values into one array on Flush.
+	// Only used by NewStreamRepairerWithOptions.
+	StreamNDJSONAggregate bool
+	// LinesToArray repairs each non-blank line on its own and collects the
+	// values into one array; Salvage decides what an unrepairable line becomes.
+	LinesToArray bool
+	// StopAfterFirst repairs only the first root value and leaves the rest of
+	// the input unread, like RepairFirst but for the standard calls.
+	StopAfterFirst bool
+	// StreamValidateOnly makes streams validate values instead of repairing them.
+	// Only used by NewStreamRepairerWithOptions.
+	StreamValidateOnly bool
+}
+
+// StorageOptions returns the storage preset, the Go side of
+// jsonrepair_options_preset_storage: one canonical, compact form for
+// deduplicating and content-addressed storage, so inputs that differ only in
+// layout, key order, escapes or number spelling repair to the same bytes. It
+// sets RawMessageSafe, SortKeys, DedupLastPosition, NormalizeNumbers and
+// MinimalEscapes; the escape options it turns off are already off in the zero
+// value.
+func StorageOptions() RepairOptions {
+	return RepairOptions{
+		RawMessageSafe:   true,
+		SortKeys:         true,
+		DedupPosition:    DedupLastPosition,
+		NormalizeNumbers: true,
+		MinimalEscapes:   true,
+	}
+}
@@ -0,0 +1,38 @@
+//go:build cgo && !purego
+
+package main
+
+/*
+#include "../../include/jsonrepair.h"
+#include <stdint.h>
+#include <stdlib.h>
+
+extern void jsonrepairGoProgress(size_t done, size_t total, void *userdata);
+*/
+import "C"
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+// setProgress installs onProgress as the progress callback of cOpts and
+// returns the function that releases it. cOpts must not be used for a repair
+// after that. The handle lives in C memory so the C side never holds a Go
+// pointer.
+func setProgress(cOpts *C.Options, onProgress func(done, total int64)) (release func()) {
+	h := cgo.NewHandle(onProgress)
+	slot := (*C.uintptr_t)(C.malloc(C.sizeof_uintptr_t))
+	*slot = C.uintptr_t(h)
+	C.jsonrepair_options_set_progress_callback(cOpts, C.JsonRepairProgressFn(C.jsonrepairGoProgress), unsafe.Pointer(slot))
+	return func() {
+		C.jsonrepair_options_set_progress_callback(cOpts, nil, nil)
+		C.free(unsafe.Pointer(slot))
+		h.Delete()
+	}
+}
+
+//export jsonrepairGoProgress
+func jsonrepairGoProgress(done, total C.size_t, userdata unsafe.Pointer) {
+	h := cgo.Handle(*(*C.uintptr_t)(userdata))
+	h.Value().(func(done, total int64))(int64(done), int64(total))
+}
@@ -0,0 +1,66 @@
+//go:build cgo && !purego
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOnProgress(t *testing.T) {
+	input := "[" + strings.Repeat("{a: 1, b: 'x'},", 5000) + "]"
+	var calls [][2]int64
+	out, err := Repair(input, RepairOptions{OnProgress: func(done, total int64) {
+		calls = append(calls, [2]int64{done, total})
+	}})
+	if err != nil || !strings.HasPrefix(out, `[{"a":1,"b":"x"}`) {
+		t.Fatalf("Repair = %.40q, %v", out, err)
+	}
+	if len(calls) < 2 {
+		t.Fatalf("got %d progress calls, want several", len(calls))
+	}
+	for i, c := range calls {
+		if c[1] != int64(len(input)) {
+			t.Fatalf("call %d: total = %d, want %d", i, c[1], len(input))
+		}
+		if i > 0 && c[0] < calls[i-1][0] {
+			t.Fatalf("call %d: done went back from %d to %d", i, calls[i-1][0], c[0])
+		}
+	}
+	if last := calls[len(calls)-1]; last[0] != last[1] {
+		t.Errorf("last call: done = %d, want total %d", last[0], last[1])
+	}
+
+	// The callback is released with the repair: a later repair does not call it.
+	n := len(calls)
+	if _, err := Repair(input, RepairOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != n {
+		t.Errorf("callback called %d times after its repair returned", len(calls)-n)
+	}
+}
+
+func TestOnProgressConcurrent(t *testing.T) {
+	// Each repair reaches its own callback, also when several run at once.
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := "[" + strings.Repeat("1,", 1000*i) + "]"
+			var last [2]int64
+			if _, err := Repair(input, RepairOptions{OnProgress: func(done, total int64) {
+				last = [2]int64{done, total}
+			}}); err != nil {
+				t.Error(err)
+				return
+			}
+			if want := int64(len(input)); last != [2]int64{want, want} {
+				t.Errorf("input %d: last call = %v, want [%d %d]", i, last, want, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,580 @@
+//go:build !cgo || purego
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// This file is the pure-Go fallback, built instead of the cgo wrappers with
+// `-tags purego` or when cgo is off (CGO_ENABLED=0). It needs no Rust library
+// and provides Repair, RepairJSON, RepairJSONWithOptions and StreamRepairer
+// with the signatures of the cgo wrappers, so code using them compiles either
+// way. It covers the core repairs: unquoted and single-quoted keys and
+// strings, missing and trailing commas, missing colons, comments, Python and
+// JavaScript keywords, and containers left open at the end of the input. It is
+// slower than the library and handles fewer malformed inputs; any
+// RepairOptions field other than EnsureASCII fails with ErrUnsupportedOption.
+
+// codeInvalidUTF8 matches INVALID_UTF8 in the C JsonRepairErrorCode enum.
+const codeInvalidUTF8 = 13
+
+// Unwrap maps the error code to one of the sentinel errors, if any.
+func (e *RepairError) Unwrap() error {
+	if e.Code == codeInvalidUTF8 {
+		return ErrInvalidUTF8
+	}
+	return nil
+}
+
+// fallbackOptions are the RepairOptions fields the fallback implements.
+var fallbackOptions = map[string]bool{"EnsureASCII": true}
+
+// checkOptions returns ErrUnsupportedOption, naming the field, for the first
+// field of opts that is set but not implemented by the fallback.
+func checkOptions(opts RepairOptions) error {
+	v := reflect.ValueOf(opts)
+	for i := 0; i < v.NumField(); i++ {
+		if name := v.Type().Field(i).Name; !fallbackOptions[name] && !v.Field(i).IsZero() {
+			return fmt.Errorf("%w: %s", ErrUnsupportedOption, name)
+		}
+	}
+	return nil
+}
+
+// RepairJSON repairs a broken JSON string using default options.
+func RepairJSON(input string) (string, error) {
+	return Repair(input, RepairOptions{})
+}
+
+// RepairJSONWithOptions repairs JSON with custom options
+func RepairJSONWithOptions(input string, ensureASCII bool) (string, error) {
+	return Repair(input, RepairOptions{EnsureASCII: ensureASCII})
+}
+
+// Repair repairs input with opts. Only EnsureASCII is implemented; any other
+// field that is set returns ErrUnsupportedOption. Input that is not valid
+// UTF-8 fails with an *RepairError matching ErrInvalidUTF8.
+func Repair(input string, opts RepairOptions) (string, error) {
+	if err := checkOptions(opts); err != nil {
+		return "", err
+	}
+	return repairGo(input, opts.EnsureASCII)
+}
+
+// repairGo repairs input as the library does with default options: valid JSON
+// is returned as is, and several root values are wrapped in an array.
+func repairGo(input string, ascii bool) (string, error) {
+	if !utf8.ValidString(input) {
+		pos := 0
+		for pos < len(input) {
+			c, n := utf8.DecodeRuneInString(input[pos:])
+			if c == utf8.RuneError && n == 1 {
+				break
+			}
+			pos += n
+		}
+		e := &RepairError{Code: codeInvalidUTF8, Message: "Invalid UTF-8", Position: int64(pos)}
+		e.locate(input)
+		return "", e
+	}
+	if !ascii && json.Valid([]byte(input)) {
+		return input, nil
+	}
+	r := &repairer{s: unfence(input), ascii: ascii}
+	var values []string
+	for {
+		r.skip()
+		if r.i >= len(r.s) {
+			break
+		}
+		// A stray closer or a separator between root values.
+		if c := r.s[r.i]; c == '}' || c == ']' || c == ',' {
+			r.i++
+			continue
+		}
+		r.out.Reset()
+		r.value()
+		values = append(values, r.out.String())
+	}
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+		return values[0], nil
+	}
+	return "[" + strings.Join(values, ",") + "]", nil
+}
+
+// unfence drops a leading BOM and the lines of a Markdown code fence around
+// the document (```json ... ```).
+func unfence(s string) string {
+	s = strings.TrimPrefix(s, "\uFEFF")
+	body := strings.TrimLeft(s, " \t\r\n")
+	if !strings.HasPrefix(body, "```") {
+		return s
+	}
+	nl := strings.IndexByte(body, '\n')
+	if nl < 0 {
+		return ""
+	}
+	body = body[nl+1:]
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return body
+}
+
+// keywords maps bare words to the JSON literal they stand for, besides other
+// casings of true, false and null.
+var keywords = map[string]string{
+	"True":      "true",
+	"False":     "false",
+	"None":      "null",
+	"undefined": "null",
+	"NaN":       "null",
+	"Infinity":  "null",
+	"-Infinity": "null",
+}
+
+// repairer writes the repair of s[i:] to out, one root value at a time.
+type repairer struct {
+	s     string
+	i     int
+	out   strings.Builder
+	ascii bool
+}
+
+func (r *repairer) peek() byte {
+	if r.i < len(r.s) {
+		return r.s[r.i]
+	}
+	return 0
+}
+
+// skip skips whitespace and //, /* */ and # comments.
+func (r *repairer) skip() {
+	for r.i < len(r.s) {
+		rest := r.s[r.i:]
+		switch c := rest[0]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			r.i++
+		case c == '#' || strings.HasPrefix(rest, "//"):
+			if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+				r.i += nl + 1
+			} else {
+				r.i = len(r.s)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if end := strings.Index(rest[2:], "*/"); end >= 0 {
+				r.i += end + 4
+			} else {
+				r.i = len(r.s)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// delimAt reports whether a bare word or number ends at s[j]: at a line break,
+// punctuation, a quote or a comment.
+func (r *repairer) delimAt(j int) bool {
+	switch r.s[j] {
+	case '\n', '\r', ',', ':', '[', ']', '{', '}', '"', '\'', '#':
+		return true
+	case '/':
+		return strings.HasPrefix(r.s[j:], "//") || strings.HasPrefix(r.s[j:], "/*")
+	}
+	return false
+}
+
+func (r *repairer) value() {
+	switch c := r.peek(); {
+	case c == '{':
+		r.object()
+	case c == '[':
+		r.array()
+	case c == '"' || c == '\'':
+		r.writeString(r.quoted())
+	case c == '-' || c == '+' || c == '.' || c >= '0' && c <= '9':
+		r.number()
+	default:
+		r.word()
+	}
+}
+
+// object reads an object from its '{'. A missing colon or comma is inserted,
+// extra commas are dropped, a key without a value gets "", and an object
+// still open at a ']' or the end of the input is closed there.
+func (r *repairer) object() {
+	r.i++
+	r.out.WriteByte('{')
+	first := true
+	for {
+		r.skip()
+		c := r.peek()
+		if c == ',' {
+			r.i++
+			continue
+		}
+		if r.i >= len(r.s) || c == '}' || c == ']' {
+			if c == '}' {
+				r.i++
+			}
+			r.out.WriteByte('}')
+			return
+		}
+		if !first {
+			r.out.WriteByte(',')
+		}
+		first = false
+		r.writeString(r.key())
+		r.skip()
+		if r.peek() == ':' {
+			r.i++
+			r.skip()
+		}
+		r.out.WriteByte(':')
+		if c := r.peek(); r.i >= len(r.s) || c == ',' || c == '}' || c == ']' {
+			r.out.WriteString(`""`)
+			continue
+		}
+		r.value()
+	}
+}
+
+// key reads an object key: a quoted string, or bare text up to the colon.
+func (r *repairer) key() string {
+	if c := r.peek(); c == '"' || c == '\'' {
+		return r.quoted()
+	}
+	start := r.i
+	for r.i < len(r.s) && !r.delimAt(r.i) {
+		r.i++
+	}
+	return strings.TrimRight(r.s[start:r.i], " \t")
+}
+
+// array reads an array from its '['. Elements are separated by commas, colons
+// or nothing at all, and an array still open at a '}' or the end of the input
+// is closed there.
+func (r *repairer) array() {
+	r.i++
+	r.out.WriteByte('[')
+	first := true
+	for {
+		r.skip()
+		c := r.peek()
+		if c == ',' || c == ':' {
+			r.i++
+			continue
+		}
+		if r.i >= len(r.s) || c == ']' || c == '}' {
+			if c == ']' {
+				r.i++
+			}
+			r.out.WriteByte(']')
+			return
+		}
+		if !first {
+			r.out.WriteByte(',')
+		}
+		first = false
+		r.value()
+	}
+}
+
+// quoted reads a string opened by ' or " and returns its text. A string
+// still open at the end of the input is closed there.
+func (r *repairer) quoted() string {
+	q := r.s[r.i]
+	r.i++
+	var b strings.Builder
+	for r.i < len(r.s) {
+		switch c := r.s[r.i]; {
+		case c == q:
+			r.i++
+			return b.String()
+		case c == '\\' && r.i+1 < len(r.s):
+			r.i++
+			r.escape(&b)
+		default:
+			b.WriteByte(c)
+			r.i++
+		}
+	}
+	return b.String()
+}
+
+// escape decodes the escape after a backslash into b. An unknown escape keeps
+// the character after the backslash.
+func (r *repairer) escape(b *strings.Builder) {
+	c := r.s[r.i]
+	r.i++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		u, ok := r.hex4()
+		if !ok {
+			b.WriteByte('u')
+			return
+		}
+		if utf16.IsSurrogate(u) && strings.HasPrefix(r.s[r.i:], `\u`) {
+			save := r.i
+			r.i += 2
+			if low, ok := r.hex4(); ok {
+				if pair := utf16.DecodeRune(u, low); pair != utf8.RuneError {
+					b.WriteRune(pair)
+					return
+				}
+			}
+			r.i = save
+		}
+		b.WriteRune(u)
+	default:
+		b.WriteByte(c)
+	}
+}
+
+// hex4 reads the four hex digits of a \u escape.
+func (r *repairer) hex4() (rune, bool) {
+	if r.i+4 > len(r.s) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(r.s[r.i:r.i+4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	r.i += 4
+	return rune(n), true
+}
+
+// number reads a number, fixing the spellings JSON rejects (+1, .5, 1., 1e).
+// A run that is not a number even then, or that continues into a word (1st,
+// 2024-01-01T10), is written as a string.
+func (r *repairer) number() {
+	start := r.i
+	for r.i < len(r.s) && strings.IndexByte("0123456789+-.eE", r.s[r.i]) >= 0 {
+		r.i++
+	}
+	if r.i < len(r.s) && r.s[r.i] != ' ' && r.s[r.i] != '\t' && !r.delimAt(r.i) {
+		r.i = start
+		r.word()
+		return
+	}
+	raw := r.s[start:r.i]
+	n := strings.TrimPrefix(raw, "+")
+	n = strings.TrimRight(n, "eE+-")
+	if strings.HasSuffix(n, ".") {
+		n += "0"
+	}
+	if strings.HasPrefix(n, ".") || strings.HasPrefix(n, "-.") {
+		n = strings.Replace(n, ".", "0.", 1)
+	}
+	if n != "" && n[0] != '+' && json.Valid([]byte(n)) {
+		r.out.WriteString(n)
+		return
+	}
+	r.writeString(raw)
+}
+
+// word reads a bare word: a keyword, or text up to the next delimiter that is
+// written as a string. Words separated by spaces or tabs make one string, so
+// {a: hello world} gives {"a":"hello world"}.
+func (r *repairer) word() {
+	start, end := r.i, r.i
+	for j := r.i; j < len(r.s); {
+		if c := r.s[j]; c == ' ' || c == '\t' {
+			j++
+			continue
+		}
+		if r.delimAt(j) && !r.apostropheAt(j) {
+			break
+		}
+		_, n := utf8.DecodeRuneInString(r.s[j:])
+		j += n
+		end = j
+	}
+	if end == start {
+		// A delimiter where a value belongs, such as a stray ':'.
+		_, n := utf8.DecodeRuneInString(r.s[start:])
+		end = start + n
+	}
+	r.i = end
+	w := r.s[start:end]
+	if lower := strings.ToLower(w); lower == "true" || lower == "false" || lower == "null" {
+		r.out.WriteString(lower)
+	} else if k, ok := keywords[w]; ok {
+		r.out.WriteString(k)
+	} else {
+		r.writeString(w)
+	}
+}
+
+// apostropheAt reports whether the ' at s[j] is between two letters, as in
+// it's, and so part of a word rather than a quote.
+func (r *repairer) apostropheAt(j int) bool {
+	if r.s[j] != '\'' || j == 0 {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(r.s[:j])
+	after, _ := utf8.DecodeRuneInString(r.s[j+1:])
+	return unicode.IsLetter(before) && unicode.IsLetter(after)
+}
+
+// writeString writes s as a JSON string with the same escapes as the library:
+// short forms for control characters that have one, \u00XX for the others,
+// and with ascii, \uXXXX (a surrogate pair above U+FFFF) for non-ASCII.
+func (r *repairer) writeString(s string) {
+	out := &r.out
+	out.WriteByte('"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(c)
+		case c == '\b':
+			out.WriteString(`\b`)
+		case c == '\f':
+			out.WriteString(`\f`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c < 0x20:
+			fmt.Fprintf(out, `\u%04x`, c)
+		case c > 0x7f && r.ascii && c > 0xffff:
+			hi, lo := utf16.EncodeRune(c)
+			fmt.Fprintf(out, `\u%04X\u%04X`, hi, lo)
+		case c > 0x7f && r.ascii:
+			fmt.Fprintf(out, `\u%04X`, c)
+		default:
+			out.WriteRune(c)
+		}
+	}
+	out.WriteByte('"')
+}
+
+// StreamRepairer is the fallback's streaming repairer. Push buffers input and
+// returns the repair of each root object or array it completes; a root value
+// of any other kind waits for Flush, which repairs whatever is left.
+type StreamRepairer struct {
+	opts RepairOptions
+	buf  string
+	// err is the unsupported option error returned by every call.
+	err error
+}
+
+// NewStreamRepairer creates a new streaming repairer
+func NewStreamRepairer() *StreamRepairer {
+	return &StreamRepairer{}
+}
+
+// NewStreamRepairerWithOptions creates a streaming repairer configured by
+// opts. An option the fallback does not implement makes every Push and Flush
+// return ErrUnsupportedOption.
+func NewStreamRepairerWithOptions(opts RepairOptions) *StreamRepairer {
+	return &StreamRepairer{opts: opts, err: checkOptions(opts)}
+}
+
+// Push pushes a chunk and returns completed JSON if any
+func (s *StreamRepairer) Push(chunk string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.buf += chunk
+	var out strings.Builder
+	for {
+		start := len(s.buf) - len(strings.TrimLeft(s.buf, " \t\r\n"))
+		end := rootEnd(s.buf, start)
+		if end < 0 {
+			return out.String(), nil
+		}
+		value, err := repairGo(s.buf[start:end], s.opts.EnsureASCII)
+		s.buf = s.buf[end:]
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(value)
+	}
+}
+
+// Flush flushes remaining data
+func (s *StreamRepairer) Flush() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	rest := strings.TrimSpace(s.buf)
+	s.buf = ""
+	if rest == "" {
+		return "", nil
+	}
+	return repairGo(rest, s.opts.EnsureASCII)
+}
+
+// Close releases the stream. The fallback holds no C memory, so it only
+// drops the buffered input.
+func (s *StreamRepairer) Close() {
+	s.buf = ""
+}
+
+// rootEnd returns the offset just past the object or array that starts at
+// buf[start], or -1 when buf[start] opens neither or the container is not
+// closed yet. Brackets inside strings and comments are not counted.
+func rootEnd(buf string, start int) int {
+	if start >= len(buf) || buf[start] != '{' && buf[start] != '[' {
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(buf); i++ {
+		switch c := buf[i]; c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"', '\'':
+			for i++; i < len(buf) && buf[i] != c; i++ {
+				if buf[i] == '\\' {
+					i++
+				}
+			}
+		case '/':
+			if strings.HasPrefix(buf[i:], "//") {
+				nl := strings.IndexByte(buf[i:], '\n')
+				if nl < 0 {
+					return -1
+				}
+				i += nl
+			} else if strings.HasPrefix(buf[i:], "/*") {
+				end := strings.Index(buf[i+2:], "*/")
+				if end < 0 {
+					return -1
+				}
+				i += end + 3
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,152 @@
+//go:build !cgo || purego
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The wanted outputs are what the Rust library returns for the same input.
+func TestPureGoRepair(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{"{a:1, b:'hello'}", `{"a":1,"b":"hello"}`},
+		{"{'name': 'Alice'}", `{"name":"Alice"}`},
+		{`{"a":1,"b":2,}`, `{"a":1,"b":2}`},
+		{"[1 2 3]", "[1,2,3]"},
+		{`{"a":1 "b":2}`, `{"a":1,"b":2}`},
+		{`{"a" 1}`, `{"a":1}`},
+		{"{\"a\":1, // note\n\"b\":2}", `{"a":1,"b":2}`},
+		{"{/* c */ \"a\": # hash\n1}", `{"a":1}`},
+		{"[True, False, None]", "[true,false,null]"},
+		{"{a: null, b: TRUE}", `{"a":null,"b":true}`},
+		{"{a: undefined, b: NaN, c: Infinity}", `{"a":null,"b":null,"c":null}`},
+		{`{"a": [1, 2, {"b": 3`, `{"a":[1,2,{"b":3}]}`},
+		{"[1, 2,", "[1,2]"},
+		{"[1, 2]]", "[1,2]"},
+		{`"unterminated`, `"unterminated"`},
+		{"{a: hello world}", `{"a":"hello world"}`},
+		{"{a: 1st, d: 2024-01-01}", `{"a":"1st","d":"2024-01-01"}`},
+		{"[+1, .5, 1., 1e]", "[1,0.5,1.0,1]"},
+		{`["\u00e9", 'tab\there']`, `["é","tab\there"]`},
+		{"{a:1, b:'hello', // note\n tags: [True, None,], c: it's}", `{"a":1,"b":"hello","tags":[true,null],"c":"it's"}`},
+		{`{"a":1}{"b":2}`, `[{"a":1},{"b":2}]`},
+		{`{"a":1}`, `{"a":1}`},
+		{"", ""},
+	} {
+		got, err := RepairJSON(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("RepairJSON(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestPureGoEnsureASCII(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{"{'name': '统一码'}", `{"name":"\u7EDF\u4E00\u7801"}`},
+		{`{"e": "😀é"}`, `{"e":"\uD83D\uDE00\u00E9"}`},
+	} {
+		got, err := RepairJSONWithOptions(tt.input, true)
+		if err != nil || got != tt.want {
+			t.Errorf("RepairJSONWithOptions(%q, true) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestPureGoInvalidUTF8(t *testing.T) {
+	input := "{\n  \"é\": \"ü\xff\"\n}"
+	_, err := RepairJSON(input)
+	var e *RepairError
+	if !errors.Is(err, ErrInvalidUTF8) || !errors.As(err, &e) {
+		t.Fatalf("err = %v, want an *RepairError matching ErrInvalidUTF8", err)
+	}
+	if want := int64(strings.IndexByte(input, 0xff)); e.Position != want {
+		t.Errorf("Position = %d, want %d", e.Position, want)
+	}
+	if e.Line != 2 || e.Column != 10 {
+		t.Errorf("Line:Column = %d:%d, want 2:10", e.Line, e.Column)
+	}
+}
+
+func TestPureGoStream(t *testing.T) {
+	s := NewStreamRepairer()
+	defer s.Close()
+	var got []string
+	for _, chunk := range []string{"{a:", "1}", "{b:", "2} [1,", "2]", " 7"} {
+		out, err := s.Push(chunk)
+		if err != nil {
+			t.Fatalf("Push(%q): %v", chunk, err)
+		}
+		got = append(got, out)
+	}
+	out, err := s.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	got = append(got, out)
+	want := []string{"", `{"a":1}`, "", `{"b":2}`, "[1,2]", "", "7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs = %q, want %q", got, want)
+	}
+}
+
+// nonZero returns a value of typ that is not its zero value.
+func nonZero(t *testing.T, typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(1)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(typ, 1, 1))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+	case reflect.Func:
+		v.Set(reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, typ.NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(typ.Out(i))
+			}
+			return results
+		}))
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(&bytes.Buffer{}))
+	default:
+		t.Fatalf("no non-zero value for %v", typ)
+	}
+	return v
+}
+
+func TestPureGoUnsupportedOptions(t *testing.T) {
+	typ := reflect.TypeOf(RepairOptions{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		var opts RepairOptions
+		reflect.ValueOf(&opts).Elem().Field(i).Set(nonZero(t, field.Type))
+
+		_, err := Repair("{a: 1}", opts)
+		if field.Name == "EnsureASCII" {
+			if err != nil {
+				t.Errorf("EnsureASCII: %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrUnsupportedOption) || !strings.HasSuffix(err.Error(), ": "+field.Name) {
+			t.Errorf("%s: err = %v, want ErrUnsupportedOption naming the field", field.Name, err)
+		}
+		s := NewStreamRepairerWithOptions(opts)
+		if _, err := s.Push("{a: 1}"); !errors.Is(err, ErrUnsupportedOption) {
+			t.Errorf("%s: Push err = %v, want ErrUnsupportedOption", field.Name, err)
+		}
+		if _, err := s.Flush(); !errors.Is(err, ErrUnsupportedOption) {
+			t.Errorf("%s: Flush err = %v, want ErrUnsupportedOption", field.Name, err)
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import "strings"
+
+// SSEStreamRepairer feeds a Server-Sent Events stream into a StreamRepairer.
+//
+// The payload of every `data:` line is appended to the underlying stream
+// without a separator, so a JSON value may be split across any number of
+// events. Other SSE fields (`event:`, `id:`, `retry:`) and comment lines are
+// ignored, and a `[DONE]` payload ends the stream. Input may be pushed in
+// arbitrary chunks; partial lines are buffered until their terminator arrives.
+type SSEStreamRepairer struct {
+	stream  *StreamRepairer
+	partial string
+	done    bool
+}
+
+// NewSSEStreamRepairer creates an SSE adapter over a new StreamRepairer.
+func NewSSEStreamRepairer() *SSEStreamRepairer {
+	return &SSEStreamRepairer{stream: NewStreamRepairer()}
+}
+
+// Push consumes raw SSE text and returns any JSON completed by it.
+func (s *SSEStreamRepairer) Push(chunk string) (string, error) {
+	if s.done {
+		return "", nil
+	}
+	buf := s.partial + chunk
+	var out strings.Builder
+	for {
+		i := strings.IndexAny(buf, "\r\n")
+		// A trailing '\r' may be the first half of "\r\n": wait for more input.
+		if i < 0 || (buf[i] == '\r' && i == len(buf)-1) {
+			break
+		}
+		line := buf[:i]
+		if buf[i] == '\r' && buf[i+1] == '\n' {
+			i++
+		}
+		buf = buf[i+1:]
+		res, err := s.pushLine(line)
+		if err != nil {
+			return out.String(), err
+		}
+		out.WriteString(res)
+		if s.done {
+			buf = ""
+			break
+		}
+	}
+	s.partial = buf
+	return out.String(), nil
+}
+
+// Flush processes an unterminated last line and flushes the underlying stream.
+func (s *SSEStreamRepairer) Flush() (string, error) {
+	var out strings.Builder
+	if s.partial != "" && !s.done {
+		res, err := s.pushLine(strings.TrimSuffix(s.partial, "\r"))
+		if err != nil {
+			return res, err
+		}
+		out.WriteString(res)
+	}
+	s.partial = ""
+	tail, err := s.stream.Flush()
+	out.WriteString(tail)
+	return out.String(), err
+}
+
+// Done reports whether the `[DONE]` sentinel has been seen.
+func (s *SSEStreamRepairer) Done() bool {
+	return s.done
+}
+
+// Close frees the underlying stream.
+func (s *SSEStreamRepairer) Close() {
+	s.stream.Close()
+}
+
+func (s *SSEStreamRepairer) pushLine(line string) (string, error) {
+	data, ok := strings.CutPrefix(line, "data:")
+	if !ok {
+		return "", nil
+	}
+	data = strings.TrimPrefix(data, " ")
+	if strings.TrimSpace(data) == "[DONE]" {
+		s.done = true
+		return "", nil
+	}
+	if data == "" {
+		return "", nil
+	}
+	return s.stream.Push(data)
+}
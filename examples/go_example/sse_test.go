@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+// pushAll pushes chunks to s and returns the concatenated output.
+func pushAll(t *testing.T, s *SSEStreamRepairer, chunks ...string) string {
+	t.Helper()
+	var out string
+	for _, chunk := range chunks {
+		res, err := s.Push(chunk)
+		if err != nil {
+			t.Fatalf("Push(%q): %v", chunk, err)
+		}
+		out += res
+	}
+	return out
+}
+
+func TestSSEMultiLineData(t *testing.T) {
+	// One value split across the data: lines of an event and across events,
+	// pushed in chunks that split lines and a CRLF terminator.
+	s := NewSSEStreamRepairer()
+	defer s.Close()
+	out := pushAll(t, s,
+		"event: delta\nid: 1\ndata: {a:",
+		"1,\r",
+		"\ndata: b: [1,\n: keep-alive\n\ndata: 2]}\n\n",
+	)
+	if out != `{"a":1,"b":[1,2]}` {
+		t.Errorf("output = %q", out)
+	}
+	if s.Done() {
+		t.Error("Done() = true before [DONE]")
+	}
+}
+
+func TestSSEDone(t *testing.T) {
+	s := NewSSEStreamRepairer()
+	defer s.Close()
+	out := pushAll(t, s, "data: {a: 1}\n\ndata: [DONE]\n\ndata: {b: 2}\n\n")
+	if out != `{"a":1}` {
+		t.Errorf("output = %q", out)
+	}
+	if !s.Done() {
+		t.Fatal("Done() = false after [DONE]")
+	}
+	// Input after [DONE] is ignored, also when it is pushed later.
+	if out := pushAll(t, s, "data: {c: 3}\n"); out != "" {
+		t.Errorf("Push after [DONE] = %q", out)
+	}
+	if out, err := s.Flush(); err != nil || out != "" {
+		t.Errorf("Flush = %q, %v", out, err)
+	}
+}
+
+func TestSSEPartialTrailingEvent(t *testing.T) {
+	// A last line without its terminator, holding a value the stream cut off,
+	// is repaired by Flush.
+	s := NewSSEStreamRepairer()
+	defer s.Close()
+	if out := pushAll(t, s, "data: {a: 1}\n\ndata: {b: [2"); out != `{"a":1}` {
+		t.Errorf("output = %q", out)
+	}
+	out, err := s.Flush()
+	if err != nil || out != `{"b":[2]}` {
+		t.Errorf("Flush = %q, %v; want %q", out, err, `{"b":[2]}`)
+	}
+}
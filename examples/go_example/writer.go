@@ -0,0 +1,76 @@
+//go:build cgo && !purego
+
+package main
+
+/*
+#include "../../include/jsonrepair.h"
+#include <stdint.h>
+#include <stdlib.h>
+
+extern int jsonrepairGoWrite(char *data, size_t len, void *userdata);
+*/
+import "C"
+import (
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// outputSink is the Go side of a jsonrepair_repair_streaming_output call.
+type outputSink struct {
+	w   io.Writer
+	err error
+}
+
+// RepairToWriter repairs input with opts and writes the output to w in chunks
+// of at most 64 KiB while it is produced, so a huge value is never held as one
+// string. Each Write blocks the repair until it returns, which gives natural
+// backpressure. The first Write error stops the repair and is returned as-is;
+// after any error, what was already written is incomplete. The LLM engine and
+// options that rewrite the whole output deliver it at the end instead.
+func RepairToWriter(w io.Writer, input string, opts RepairOptions) error {
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	cOpts := newCOptions(opts)
+	defer C.jsonrepair_options_free(cOpts)
+	if opts.OnProgress != nil {
+		defer setProgress(cOpts, opts.OnProgress)()
+	}
+	if opts.FormatNumbers != nil {
+		defer setNumberFormat(cOpts, opts.FormatNumbers)()
+	}
+
+	sink := &outputSink{w: w}
+	h := cgo.NewHandle(sink)
+	defer h.Delete()
+	slot := (*C.uintptr_t)(C.malloc(C.sizeof_uintptr_t))
+	defer C.free(unsafe.Pointer(slot))
+	*slot = C.uintptr_t(h)
+
+	var cErr C.JsonRepairError
+	ok := C.jsonrepair_repair_streaming_output(cInput, cOpts, C.JsonRepairWriteFn(C.jsonrepairGoWrite), unsafe.Pointer(slot), &cErr)
+	if sink.err != nil {
+		if cErr.message != nil {
+			C.jsonrepair_free(cErr.message)
+		}
+		return sink.err
+	}
+	if !ok {
+		if err := takeInputError(&cErr, input); err != nil {
+			return err
+		}
+		return ErrRepairFailed
+	}
+	return nil
+}
+
+//export jsonrepairGoWrite
+func jsonrepairGoWrite(data *C.char, n C.size_t, userdata unsafe.Pointer) C.int {
+	sink := cgo.Handle(*(*C.uintptr_t)(userdata)).Value().(*outputSink)
+	if _, err := sink.w.Write(C.GoBytes(unsafe.Pointer(data), C.int(n))); err != nil {
+		sink.err = err
+		return 1
+	}
+	return 0
+}
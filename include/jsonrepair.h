@@ -7,6 +7,7 @@
 
 #include <stdarg.h>
 #include <stdbool.h>
+#include <stddef.h>
 #include <stdint.h>
 #include <stdlib.h>
 
@@ -25,8 +26,674 @@ typedef enum JsonRepairErrorCode {
   COLON_EXPECTED = 4,
   INVALID_UNICODE = 5,
   PARSE = 6,
+  INVALID_JSON = 7,
+  TIMEOUT = 8,
+  TOO_MANY_REPAIRS = 9,
+  POINTER_NOT_FOUND = 10,
+  BUFFER_OVERFLOW = 11,
+  TOO_MANY_ELEMENTS = 12,
+  /**
+   * An input string was not valid UTF-8; the position is the offset of the first
+   * invalid byte.
+   */
+  INVALID_UTF8 = 13,
+  /**
+   * An object key was longer than `max_key_len` bytes; the position is where the key
+   * starts.
+   */
+  KEY_TOO_LONG = 14,
+  /**
+   * The input was longer than `max_input_bytes`; no repair was attempted.
+   */
+  INPUT_TOO_LARGE = 15,
 } JsonRepairErrorCode;
 
+/**
+ * How an object key without a value is repaired (C API)
+ */
+typedef enum JsonRepairMissingValues {
+  /**
+   * Fill with `""` (default)
+   */
+  INSERT_EMPTY_STRING = 0,
+  /**
+   * Fill with `null`
+   */
+  INSERT_NULL = 1,
+  /**
+   * Drop the key
+   */
+  DROP_KEY = 2,
+} JsonRepairMissingValues;
+
+/**
+ * Which strings get non-ASCII characters escaped (C API)
+ */
+typedef enum JsonRepairAsciiScope {
+  /**
+   * Keep UTF-8 everywhere (default)
+   */
+  ASCII_NONE = 0,
+  /**
+   * Escape object keys only
+   */
+  ASCII_KEYS_ONLY = 1,
+  /**
+   * Escape values only
+   */
+  ASCII_VALUES_ONLY = 2,
+  /**
+   * Escape keys and values
+   */
+  ASCII_ALL = 3,
+} JsonRepairAsciiScope;
+
+/**
+ * Where the surviving member of a duplicated key goes (C API)
+ */
+typedef enum JsonRepairDedupPosition {
+  /**
+   * Keep all duplicates (default)
+   */
+  DEDUP_KEEP_ALL = 0,
+  /**
+   * Last value, at the first-seen position
+   */
+  DEDUP_FIRST_POSITION = 1,
+  /**
+   * Last value, at the last occurrence's position
+   */
+  DEDUP_LAST_POSITION = 2,
+} JsonRepairDedupPosition;
+
+/**
+ * How a bare non-keyword array element is repaired (C API)
+ */
+typedef enum JsonRepairStrayTokens {
+  /**
+   * Fail with a parse error at the token (default)
+   */
+  STRAY_ERROR = 0,
+  /**
+   * Quote it as a string
+   */
+  STRAY_QUOTE = 1,
+  /**
+   * Drop the element
+   */
+  STRAY_DROP = 2,
+} JsonRepairStrayTokens;
+
+/**
+ * What replaces a nested value that cannot be repaired (C API)
+ */
+typedef enum JsonRepairSalvage {
+  /**
+   * Fail the whole document (default)
+   */
+  SALVAGE_FAIL = 0,
+  /**
+   * Substitute `null`
+   */
+  SALVAGE_NULL = 1,
+  /**
+   * Substitute `{"$unrepairable": "<source text>"}`
+   */
+  SALVAGE_MARKER = 2,
+} JsonRepairSalvage;
+
+/**
+ * How far to go with repairs that guess at structure (C API)
+ */
+typedef enum JsonRepairStrictness {
+  /**
+   * Fail where the structure is ambiguous (default)
+   */
+  STRICTNESS_CONSERVATIVE = 0,
+  /**
+   * Read a value followed by a colon as a nested key, and `{1, 2}` as an array
+   */
+  STRICTNESS_AGGRESSIVE = 1,
+} JsonRepairStrictness;
+
+/**
+ * How input that is not valid UTF-8 is treated (C API)
+ */
+typedef enum JsonRepairUtf8Strictness {
+  /**
+   * Reject overlong encodings and other invalid sequences (default)
+   */
+  UTF8_STRICT = 0,
+  /**
+   * Replace each invalid sequence with U+FFFD
+   */
+  UTF8_LENIENT = 1,
+} JsonRepairUtf8Strictness;
+
+/**
+ * What happens to a key longer than max_key_len (C API)
+ */
+typedef enum JsonRepairLongKeys {
+  /**
+   * Fail with `KEY_TOO_LONG` (default)
+   */
+  LONG_KEYS_ERROR = 0,
+  /**
+   * Cut the key at the last UTF-8 character boundary within the limit
+   */
+  LONG_KEYS_TRUNCATE = 1,
+} JsonRepairLongKeys;
+
+/**
+ * Output syntax (C API)
+ */
+typedef enum JsonRepairOutputFormat {
+  /**
+   * Strict JSON (default)
+   */
+  FORMAT_JSON = 0,
+  /**
+   * JSON5: identifier keys unquoted, strings single-quoted
+   */
+  FORMAT_JSON5 = 1,
+} JsonRepairOutputFormat;
+
+/**
+ * Whitespace between tokens (C API)
+ */
+typedef enum JsonRepairCompactSpacing {
+  /**
+   * No whitespace (default)
+   */
+  SPACING_NONE = 0,
+  /**
+   * One space after every `:` and `,`
+   */
+  SPACING_MINIMAL = 1,
+} JsonRepairCompactSpacing;
+
+/**
+ * Numbers too large for a double (C API)
+ */
+typedef enum JsonRepairOverflow {
+  /**
+   * Keep the literal (default)
+   */
+  OVERFLOW_KEEP = 0,
+  /**
+   * Quote the literal as a string
+   */
+  OVERFLOW_QUOTE = 1,
+  /**
+   * Replace the number with `null`
+   */
+  OVERFLOW_NULL = 2,
+} JsonRepairOverflow;
+
+/**
+ * Numbers with a unit suffix such as `30s` (C API)
+ */
+typedef enum JsonRepairNumberSuffix {
+  /**
+   * Leave them to the general number rules (default)
+   */
+  SUFFIX_KEEP = 0,
+  /**
+   * Quote the whole token as a string
+   */
+  SUFFIX_QUOTE = 1,
+  /**
+   * Keep the number and drop the suffix
+   */
+  SUFFIX_STRIP = 2,
+} JsonRepairNumberSuffix;
+
+/**
+ * Sign handling for a zero such as `-0` (C API)
+ */
+typedef enum JsonRepairNegativeZero {
+  /**
+   * Keep the sign as written (default)
+   */
+  NEGATIVE_ZERO_PRESERVE = 0,
+  /**
+   * Drop the sign, keeping the rest of the spelling
+   */
+  NEGATIVE_ZERO_NORMALIZE = 1,
+} JsonRepairNegativeZero;
+
+/**
+ * Integers beyond the JavaScript safe range (C API)
+ */
+typedef enum JsonRepairSafeIntegers {
+  /**
+   * Keep them as written (default)
+   */
+  SAFE_INTEGERS_PASSTHROUGH = 0,
+  /**
+   * Clamp them to `±(2^53 - 1)`
+   */
+  SAFE_INTEGERS_CLAMP = 1,
+  /**
+   * Quote them as strings
+   */
+  SAFE_INTEGERS_QUOTE = 2,
+} JsonRepairSafeIntegers;
+
+/**
+ * How a typed wrapper is unwrapped (C API)
+ */
+typedef enum JsonRepairUnwrapMode {
+  /**
+   * Keep the argument as a string
+   */
+  UNWRAP_STRING = 0,
+  /**
+   * Make the argument a number
+   */
+  UNWRAP_NUMBER = 1,
+  /**
+   * Replace the call with `null`
+   */
+  UNWRAP_NULL = 2,
+} JsonRepairUnwrapMode;
+
+/**
+ * Which brackets a keyword alias stands for (C API)
+ */
+typedef enum JsonRepairBracketKind {
+  /**
+   * `{` and `}`
+   */
+  BRACKET_OBJECT = 0,
+  /**
+   * `[` and `]`
+   */
+  BRACKET_ARRAY = 1,
+} JsonRepairBracketKind;
+
+/**
+ * How a scalar top-level value is wrapped (C API)
+ */
+typedef enum JsonRepairForceContainer {
+  /**
+   * Leave scalars as they are (default)
+   */
+  FORCE_OFF = 0,
+  /**
+   * Wrap as `[value]`
+   */
+  FORCE_ARRAY = 1,
+  /**
+   * Wrap as `{"value":value}`
+   */
+  FORCE_OBJECT = 2,
+} JsonRepairForceContainer;
+
+/**
+ * What a backslash-newline line continuation inside a string becomes (C API)
+ */
+typedef enum JsonRepairLineContinuations {
+  /**
+   * Join the lines: `"foo\<LF>bar"` becomes `"foobar"` (default)
+   */
+  CONTINUATION_ELIDE = 0,
+  /**
+   * Keep the line break escaped: `"foo\<LF>bar"` becomes `"foo\nbar"`
+   */
+  CONTINUATION_KEEP = 1,
+} JsonRepairLineContinuations;
+
+/**
+ * JSON type of a repaired document's root value (C API)
+ */
+typedef enum JsonRepairValueKind {
+  VALUE_KIND_OBJECT = 0,
+  VALUE_KIND_ARRAY = 1,
+  VALUE_KIND_STRING = 2,
+  VALUE_KIND_NUMBER = 3,
+  VALUE_KIND_BOOLEAN = 4,
+  VALUE_KIND_NULL = 5,
+  /**
+   * The input held no value, so the output is empty
+   */
+  VALUE_KIND_EMPTY = 6,
+} JsonRepairValueKind;
+
+/**
+ * Byte order for BOM-less UTF-16 input (C API)
+ */
+typedef enum JsonRepairUtf16Endian {
+  UTF16_LE = 0,
+  UTF16_BE = 1,
+} JsonRepairUtf16Endian;
+
+/**
+ * Option ids for `jsonrepair_option_supported()` (C API), one per options setter
+ */
+typedef enum JsonRepairOption {
+  /**
+   * `jsonrepair_options_set_ensure_ascii()`
+   */
+  OPTION_ENSURE_ASCII = 0,
+  /**
+   * `jsonrepair_options_set_allow_python_keywords()`
+   */
+  OPTION_ALLOW_PYTHON_KEYWORDS = 1,
+  /**
+   * `jsonrepair_options_set_tolerate_hash_comments()`
+   */
+  OPTION_TOLERATE_HASH_COMMENTS = 2,
+  /**
+   * `jsonrepair_options_set_repair_undefined()`
+   */
+  OPTION_REPAIR_UNDEFINED = 3,
+  /**
+   * `jsonrepair_options_set_fenced_code_blocks()`
+   */
+  OPTION_FENCED_CODE_BLOCKS = 4,
+  /**
+   * `jsonrepair_options_set_normalize_js_nonfinite()`
+   */
+  OPTION_NORMALIZE_JS_NONFINITE = 5,
+  /**
+   * `jsonrepair_options_set_stream_ndjson_aggregate()`
+   */
+  OPTION_STREAM_NDJSON_AGGREGATE = 6,
+  /**
+   * `jsonrepair_options_set_logging()` (needs the `logging` feature)
+   */
+  OPTION_LOGGING = 7,
+  /**
+   * `jsonrepair_options_set_number_tolerance_leading_dot()`
+   */
+  OPTION_NUMBER_TOLERANCE_LEADING_DOT = 8,
+  /**
+   * `jsonrepair_options_set_number_tolerance_trailing_dot()`
+   */
+  OPTION_NUMBER_TOLERANCE_TRAILING_DOT = 9,
+  /**
+   * `jsonrepair_options_set_python_style_separators()`
+   */
+  OPTION_PYTHON_STYLE_SEPARATORS = 10,
+  /**
+   * `jsonrepair_options_set_aggressive_truncation_fix()`
+   */
+  OPTION_AGGRESSIVE_TRUNCATION_FIX = 11,
+  /**
+   * `jsonrepair_options_set_reject_if_invalid()`
+   */
+  OPTION_REJECT_IF_INVALID = 12,
+  /**
+   * `jsonrepair_options_set_timeout_ms()`
+   */
+  OPTION_TIMEOUT_MS = 13,
+  /**
+   * `jsonrepair_options_set_output_bom()`
+   */
+  OPTION_OUTPUT_BOM = 14,
+  /**
+   * `jsonrepair_options_set_stream_validate_only()`
+   */
+  OPTION_STREAM_VALIDATE_ONLY = 15,
+  /**
+   * `jsonrepair_options_set_trim_keys()`
+   */
+  OPTION_TRIM_KEYS = 16,
+  /**
+   * `jsonrepair_options_set_unwrap_escaped_json()`
+   */
+  OPTION_UNWRAP_ESCAPED_JSON = 17,
+  /**
+   * `jsonrepair_options_set_max_repairs()`
+   */
+  OPTION_MAX_REPAIRS = 18,
+  /**
+   * `jsonrepair_options_set_wrap_fragments()`
+   */
+  OPTION_WRAP_FRAGMENTS = 19,
+  /**
+   * `jsonrepair_options_set_missing_values()`
+   */
+  OPTION_MISSING_VALUES = 20,
+  /**
+   * `jsonrepair_options_set_ascii_scope()`
+   */
+  OPTION_ASCII_SCOPE = 21,
+  /**
+   * `jsonrepair_options_set_normalize_numbers()`
+   */
+  OPTION_NORMALIZE_NUMBERS = 22,
+  /**
+   * `jsonrepair_options_set_equals_separators()`
+   */
+  OPTION_EQUALS_SEPARATORS = 23,
+  /**
+   * `jsonrepair_options_set_dedup_position()`
+   */
+  OPTION_DEDUP_POSITION = 24,
+  /**
+   * `jsonrepair_options_set_decode_base64()`
+   */
+  OPTION_DECODE_BASE64 = 25,
+  /**
+   * `jsonrepair_options_set_annotate_source()`
+   */
+  OPTION_ANNOTATE_SOURCE = 26,
+  /**
+   * `jsonrepair_options_set_stray_tokens()`
+   */
+  OPTION_STRAY_TOKENS = 27,
+  /**
+   * `jsonrepair_options_set_force_container()`
+   */
+  OPTION_FORCE_CONTAINER = 28,
+  /**
+   * `jsonrepair_options_set_fix_mojibake()`
+   */
+  OPTION_FIX_MOJIBAKE = 29,
+  /**
+   * `jsonrepair_options_set_salvage()`
+   */
+  OPTION_SALVAGE = 30,
+  /**
+   * `jsonrepair_options_set_output_format()`
+   */
+  OPTION_OUTPUT_FORMAT = 31,
+  /**
+   * `jsonrepair_options_set_comma_decimal()`
+   */
+  OPTION_COMMA_DECIMAL = 32,
+  /**
+   * `jsonrepair_options_set_dedup_arrays()`
+   */
+  OPTION_DEDUP_ARRAYS = 33,
+  /**
+   * `jsonrepair_options_set_escape_slashes()`
+   */
+  OPTION_ESCAPE_SLASHES = 34,
+  /**
+   * `jsonrepair_options_set_add_missing_brackets()`
+   */
+  OPTION_ADD_MISSING_BRACKETS = 35,
+  /**
+   * `jsonrepair_options_set_compact_spacing()`
+   */
+  OPTION_COMPACT_SPACING = 36,
+  /**
+   * `jsonrepair_options_set_indent_detect()`
+   */
+  OPTION_INDENT_DETECT = 37,
+  /**
+   * `jsonrepair_options_set_align_values()`
+   */
+  OPTION_ALIGN_VALUES = 38,
+  /**
+   * `jsonrepair_options_set_concat_adjacent_strings()`
+   */
+  OPTION_CONCAT_ADJACENT_STRINGS = 39,
+  /**
+   * `jsonrepair_options_set_progress_callback()`
+   */
+  OPTION_PROGRESS_CALLBACK = 40,
+  /**
+   * `jsonrepair_options_set_max_elements()`
+   */
+  OPTION_MAX_ELEMENTS = 41,
+  /**
+   * `jsonrepair_options_set_tolerate_sql_comments()`
+   */
+  OPTION_TOLERATE_SQL_COMMENTS = 42,
+  /**
+   * `jsonrepair_options_set_overflow()`
+   */
+  OPTION_OVERFLOW = 43,
+  /**
+   * `jsonrepair_options_set_number_suffix()`, `jsonrepair_options_set_quote_suffixed_numbers()`
+   */
+  OPTION_NUMBER_SUFFIX = 44,
+  /**
+   * `jsonrepair_options_add_unwrap_function()`, `jsonrepair_options_clear_unwrap_functions()`
+   */
+  OPTION_UNWRAP_FUNCTIONS = 45,
+  /**
+   * `jsonrepair_options_set_error_as_json()`
+   */
+  OPTION_ERROR_AS_JSON = 46,
+  /**
+   * `jsonrepair_options_set_strip_ellipsis()`
+   */
+  OPTION_STRIP_ELLIPSIS = 47,
+  /**
+   * `jsonrepair_options_set_negative_zero()`
+   */
+  OPTION_NEGATIVE_ZERO = 48,
+  /**
+   * `jsonrepair_options_set_short_escapes()`
+   */
+  OPTION_SHORT_ESCAPES = 49,
+  /**
+   * `jsonrepair_options_set_strip_trailing_line_ws()`
+   */
+  OPTION_STRIP_TRAILING_LINE_WS = 50,
+  /**
+   * `jsonrepair_options_set_parens_as_arrays()`
+   */
+  OPTION_PARENS_AS_ARRAYS = 51,
+  /**
+   * `jsonrepair_options_set_safe_integers()`
+   */
+  OPTION_SAFE_INTEGERS = 52,
+  /**
+   * `jsonrepair_options_set_extract_embedded()`
+   */
+  OPTION_EXTRACT_EMBEDDED = 53,
+  /**
+   * `jsonrepair_options_set_minimal_escapes()`
+   */
+  OPTION_MINIMAL_ESCAPES = 54,
+  /**
+   * `jsonrepair_options_set_strictness()`
+   */
+  OPTION_STRICTNESS = 55,
+  /**
+   * `jsonrepair_options_set_max_key_len()`
+   */
+  OPTION_MAX_KEY_LEN = 56,
+  /**
+   * `jsonrepair_options_set_long_keys()`
+   */
+  OPTION_LONG_KEYS = 57,
+  /**
+   * `jsonrepair_options_set_case_insensitive_keywords()`
+   */
+  OPTION_CASE_INSENSITIVE_KEYWORDS = 58,
+  /**
+   * `jsonrepair_options_set_collapse_ws()`
+   */
+  OPTION_COLLAPSE_WS = 59,
+  /**
+   * `jsonrepair_options_set_collapse_ws_newlines()`
+   */
+  OPTION_COLLAPSE_WS_NEWLINES = 60,
+  /**
+   * `jsonrepair_options_set_trace()`
+   */
+  OPTION_TRACE = 61,
+  /**
+   * `jsonrepair_options_set_crlf()`
+   */
+  OPTION_CRLF = 62,
+  /**
+   * `jsonrepair_options_set_alt_quote_chars()`
+   */
+  OPTION_ALT_QUOTE_CHARS = 63,
+  /**
+   * `jsonrepair_options_set_lines_to_array()`
+   */
+  OPTION_LINES_TO_ARRAY = 64,
+  /**
+   * `jsonrepair_options_set_line_continuations()`
+   */
+  OPTION_LINE_CONTINUATIONS = 65,
+  /**
+   * `jsonrepair_options_set_drop_placeholder()`
+   */
+  OPTION_DROP_PLACEHOLDER = 66,
+  /**
+   * `jsonrepair_options_set_raw_message_safe()`
+   */
+  OPTION_RAW_MESSAGE_SAFE = 67,
+  /**
+   * `jsonrepair_options_set_utf8_strictness()`
+   */
+  OPTION_UTF8_STRICTNESS = 68,
+  /**
+   * `jsonrepair_options_add_bracket_alias()`, `jsonrepair_options_clear_bracket_aliases()`
+   */
+  OPTION_BRACKET_ALIASES = 69,
+  /**
+   * `jsonrepair_options_set_eval_fractions()`
+   */
+  OPTION_EVAL_FRACTIONS = 70,
+  /**
+   * `jsonrepair_options_set_fix_backslashes()`
+   */
+  OPTION_FIX_BACKSLASHES = 71,
+  /**
+   * `jsonrepair_options_set_stop_after_first()`
+   */
+  OPTION_STOP_AFTER_FIRST = 72,
+  /**
+   * `jsonrepair_options_set_max_input_bytes()`
+   */
+  OPTION_MAX_INPUT_BYTES = 73,
+  /**
+   * `jsonrepair_options_set_abbreviated_keywords()`
+   */
+  OPTION_ABBREVIATED_KEYWORDS = 74,
+  /**
+   * `jsonrepair_options_set_envelope()`
+   */
+  OPTION_ENVELOPE = 75,
+  /**
+   * `jsonrepair_options_set_number_format_callback()`
+   */
+  OPTION_NUMBER_FORMAT_CALLBACK = 76,
+  /**
+   * `jsonrepair_options_set_null_tokens()`
+   */
+  OPTION_NULL_TOKENS = 77,
+  /**
+   * `jsonrepair_options_set_sort_keys()`
+   */
+  OPTION_SORT_KEYS = 78,
+  /**
+   * `jsonrepair_options_set_brackets_as_objects()`
+   */
+  OPTION_BRACKETS_AS_OBJECTS = 79,
+} JsonRepairOption;
+
 typedef struct Options Options;
 
 typedef struct StreamRepairer StreamRepairer;
@@ -37,9 +704,133 @@ typedef struct StreamRepairer StreamRepairer;
 typedef struct JsonRepairError {
   enum JsonRepairErrorCode code;
   char *message;
-  uintptr_t position;
+  size_t position;
 } JsonRepairError;
 
+/**
+ * Validation status of one completed root value.
+ */
+typedef struct JsonRepairValueStatus {
+  bool valid;
+  /**
+   * The value's source text, unchanged
+   */
+  char *text;
+  /**
+   * Error details when `valid` is false (`code` is `OK` otherwise)
+   */
+  struct JsonRepairError error;
+} JsonRepairValueStatus;
+
+/**
+ * List of value statuses returned by the stream validation API.
+ */
+typedef struct JsonRepairValueStatusList {
+  struct JsonRepairValueStatus *items;
+  size_t len;
+} JsonRepairValueStatusList;
+
+/**
+ * List of strings returned by `jsonrepair_repair_split()` and
+ * `jsonrepair_list_repair_categories()`.
+ */
+typedef struct JsonRepairStringList {
+  char **items;
+  size_t len;
+} JsonRepairStringList;
+
+/**
+ * One repaired value and the input bytes it came from.
+ */
+typedef struct JsonRepairValueRange {
+  /**
+   * The repaired value
+   */
+  char *value;
+  /**
+   * Byte offset of the value's first source byte, counted from the start of the stream
+   */
+  size_t start;
+  /**
+   * Byte offset just past the value's last source byte
+   */
+  size_t end;
+} JsonRepairValueRange;
+
+/**
+ * List of value ranges returned by `jsonrepair_stream_take_ranges()`.
+ */
+typedef struct JsonRepairValueRangeList {
+  struct JsonRepairValueRange *items;
+  size_t len;
+} JsonRepairValueRangeList;
+
+/**
+ * Keep-alive status for a stretch of input in which no value completed.
+ */
+typedef struct JsonRepairHeartbeat {
+  /**
+   * Input bytes pushed so far, counted from the start of the stream
+   */
+  uint64_t bytes_in;
+  /**
+   * Bytes buffered for the value that has not completed yet
+   */
+  size_t buffered;
+} JsonRepairHeartbeat;
+
+/**
+ * List of heartbeats returned by `jsonrepair_stream_take_heartbeats()`.
+ */
+typedef struct JsonRepairHeartbeatList {
+  struct JsonRepairHeartbeat *items;
+  size_t len;
+} JsonRepairHeartbeatList;
+
+/**
+ * Totals for a streaming session, returned by `jsonrepair_stream_stats()`.
+ */
+typedef struct JsonRepairStreamStats {
+  /**
+   * Root values repaired and emitted
+   */
+  uint64_t values;
+  /**
+   * Repairs applied to those values, as counted for max_repairs
+   */
+  uint64_t repairs;
+  /**
+   * Input bytes pushed so far
+   */
+  uint64_t bytes_in;
+  /**
+   * Bytes of repaired values emitted, without the BOM or aggregation brackets
+   */
+  uint64_t bytes_out;
+} JsonRepairStreamStats;
+
+/**
+ * Progress callback (C API): called with the bytes parsed so far, the total input length
+* and the `userdata` pointer given to `jsonrepair_options_set_progress_callback()`.
+ */
+typedef void (*JsonRepairProgressFn)(size_t done, size_t total, void *userdata);
+
+/**
+ * Number format callback (C API): called with a number of the repaired output, such as
+* `1.5`, and the `userdata` pointer given to `jsonrepair_options_set_number_format_callback()`.
+ * Returns the text to write instead, or NULL to keep the number. The returned string is
+ * copied right away, so it only has to stay valid until the callback is called again or
+ * the repair returns.
+ */
+typedef const char *(*JsonRepairNumberFormatFn)(const char *raw, void *userdata);
+
+/**
+ * Write callback (C API): called with `len` bytes of output at `data` and the `userdata`
+* pointer given to `jsonrepair_repair_streaming_output()`. Return 0 to go on; any other
+ * value stops the repair.
+ */
+typedef int (*JsonRepairWriteFn)(const char *data, size_t len, void *userdata);
+
 #ifdef __cplusplus
 extern "C" {
 #endif // __cplusplus
@@ -95,6 +886,47 @@ void jsonrepair_options_set_ensure_ascii(struct Options *opts, bool value);
  */
 void jsonrepair_options_set_allow_python_keywords(struct Options *opts, bool value);
 
+/**
+ * Set the case_insensitive_keywords option.
+ *
+ * Reads `true`, `false` and `null` in other letter cases (`TRUE`, `FALSE`, `Null`, `NULL`)
+ * and writes them in lowercase. The Python spellings `True`, `False` and `None` follow
+ * `jsonrepair_options_set_allow_python_keywords()` instead. Default: true.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_case_insensitive_keywords(struct Options *opts, bool value);
+
+/**
+ * Set the abbreviated_keywords option.
+ *
+ * Reads a lone `t`, `f` or `n` value as `true`, `false` or `null` (`{ok: t}` →
+ * `{"ok":true}`). Only spaces or tabs may separate the letter from the next `,`, `}`, `]`,
+ * line break or the end, so `tf`, `t-shirt`, `n/a` and `t rex` stay strings, as do keys.
+ * Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_abbreviated_keywords(struct Options *opts, bool value);
+
+/**
+ * Set the null_tokens option from a list of bare tokens.
+ *
+ * Replaces the list with the `count` strings in `tokens`; each one standing alone in value
+ * position becomes `null`, so with `N/A` and `-` the input `{a: N/A, b: -}` gives
+ * `{"a":null,"b":null}`. Matching is exact and case-sensitive. NULL entries and non-UTF-8
+ * strings are skipped, and NULL or a count of 0 clears the list.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+* - `tokens` must point to `count` valid null-terminated strings or NULLs, or be NULL
+ */
+void jsonrepair_options_set_null_tokens(struct Options *opts,
+                                        const char *const *tokens,
+                                        size_t count);
+
 /**
  * Set the tolerate_hash_comments option.
  *
@@ -135,6 +967,31 @@ void jsonrepair_options_set_normalize_js_nonfinite(struct Options *opts, bool va
  */
 void jsonrepair_options_set_stream_ndjson_aggregate(struct Options *opts, bool value);
 
+/**
+ * Set the lines_to_array option.
+ *
+* Repairs each non-blank line of the input on its own and collects the values into one
+* JSON array, so a line left open does not run into the next. A line that cannot be
+ * repaired is handled per the salvage option (`SALVAGE_FAIL` returns its error). Off by
+ * default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_lines_to_array(struct Options *opts, bool value);
+
+/**
+ * Set the stop_after_first option.
+ *
+ * Stops after the first complete root value, so `{a: 1} {b: 2}` gives `{"a":1}`, and
+ * leaves the rest of the input unread: the cost of a large remainder is not paid. Like
+ * `jsonrepair_repair_first()`, but for the standard repair calls. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_stop_after_first(struct Options *opts, bool value);
+
 /**
  * Set the logging option.
  *
@@ -176,96 +1033,1494 @@ void jsonrepair_options_set_python_style_separators(struct Options *opts, bool v
 void jsonrepair_options_set_aggressive_truncation_fix(struct Options *opts, bool value);
 
 /**
- * Repair a JSON string with custom options.
+ * Set the reject_if_invalid option.
+ *
+ * When enabled, input that is not already valid JSON fails with
+ * `INVALID_JSON` (and the position of the first problem) instead of being repaired.
  *
  * # Safety
- * - `input` must be a valid null-terminated UTF-8 string
-* - `opts` must be a valid pointer to Options, or NULL for defaults
- * - The returned string must be freed with `jsonrepair_free()`
- * - Returns NULL on error
+* - `opts` must be a valid pointer to Options
  */
-char *jsonrepair_repair_with_options(const char *input, const struct Options *opts);
+void jsonrepair_options_set_reject_if_invalid(struct Options *opts, bool value);
 
 /**
- * Repair a JSON string with error details.
+ * Set the timeout_ms option.
+ *
+ * Repair aborts with `TIMEOUT` once a single call runs longer than `ms`
+ * milliseconds. The clock is checked every 256 parsed values, so the budget is
+ * approximate. Pass 0 to disable (default).
  *
  * # Safety
- * - `input` must be a valid null-terminated UTF-8 string
-* - `opts` must be a valid pointer to Options, or NULL for defaults
- * - `error` can be NULL to ignore error details
- * - If `error` is not NULL and an error occurs, `error.message` must be freed with `free()`
- * - The returned string must be freed with `jsonrepair_free()`
- * - Returns NULL on error
+* - `opts` must be a valid pointer to Options
  */
+void jsonrepair_options_set_timeout_ms(struct Options *opts, uint64_t ms);
 
-char *jsonrepair_repair_ex(const char *input,
-                           const struct Options *opts,
-                           struct JsonRepairError *error);
+/**
+ * Set the output_bom option.
+ *
+ * When enabled, the output is prefixed with a single UTF-8 BOM (`EF BB BF`).
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_output_bom(struct Options *opts, bool value);
 
 /**
- * Create a new streaming repairer.
+ * Set the raw_message_safe option.
+ *
+ * When enabled, the output is compact JSON with no surrounding whitespace, trailing newline
+ * or BOM, safe to embed as-is (e.g. as a Go `json.RawMessage`).
  *
  * # Safety
- * - `opts` can be NULL for default options
- * - Must be freed with `jsonrepair_stream_free()`
+* - `opts` must be a valid pointer to Options
  */
-struct StreamRepairer *jsonrepair_stream_new(const struct Options *opts);
+void jsonrepair_options_set_raw_message_safe(struct Options *opts, bool value);
 
 /**
- * Free a streaming repairer.
+ * Set the stream_validate_only option.
+ *
+ * Streams created with this option validate completed values instead of
+ * repairing them; use `jsonrepair_stream_push_validate()` to collect statuses.
  *
  * # Safety
-* - `stream` must be a pointer returned by `jsonrepair_stream_new()`, or NULL
+* - `opts` must be a valid pointer to Options
  */
-void jsonrepair_stream_free(struct StreamRepairer *stream);
+void jsonrepair_options_set_stream_validate_only(struct Options *opts, bool value);
 
 /**
- * Push a chunk to the streaming repairer.
+ * Set the trim_keys option.
+ *
+ * Whitespace around object keys is trimmed before any other key handling,
+ * so keys that differ only in surrounding whitespace become duplicates.
  *
  * # Safety
-* - `stream` must be a valid pointer to StreamRepairer
- * - `chunk` must be a valid null-terminated UTF-8 string
- * - Returns NULL if no complete value yet, or a string that must be freed with `jsonrepair_free()`
+* - `opts` must be a valid pointer to Options
  */
-char *jsonrepair_stream_push(struct StreamRepairer *stream, const char *chunk);
+void jsonrepair_options_set_trim_keys(struct Options *opts, bool value);
 
 /**
- * Flush the streaming repairer.
+ * Set the unwrap_escaped_json option.
+ *
+ * A top-level string whose content is strictly valid JSON is replaced by that
+ * content, up to 8 nested escaping layers. A string value holding an object or
+ * array encoded two or more times (`{"p": "{\\"k\\": 1}"}`) is decoded too.
  *
  * # Safety
-* - `stream` must be a valid pointer to StreamRepairer
- * - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
+* - `opts` must be a valid pointer to Options
  */
-char *jsonrepair_stream_flush(struct StreamRepairer *stream);
+void jsonrepair_options_set_unwrap_escaped_json(struct Options *opts, bool value);
 
 /**
- * Push a chunk with error handling.
+ * Set the max_repairs option.
+ *
+ * Repair aborts with `TOO_MANY_REPAIRS` once more than `n` fixes have been
+ * applied to the input. Pass 0 for no limit (default).
  *
  * # Safety
-* - `stream` must be a valid pointer to StreamRepairer
- * - `chunk` must be a valid null-terminated UTF-8 string
- * - `error` can be NULL to ignore error details
+* - `opts` must be a valid pointer to Options
  */
+void jsonrepair_options_set_max_repairs(struct Options *opts, size_t n);
 
-char *jsonrepair_stream_push_ex(struct StreamRepairer *stream,
-                                const char *chunk,
-                                struct JsonRepairError *error);
+/**
+ * Set the wrap_fragments option.
+ *
+ * Newline-separated `key = value` lines (`.env`/TOML style) are assembled
+* into a single JSON object, and a CSV-style row of quoted fields
+* (`"a","b","c"`) into an array.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_wrap_fragments(struct Options *opts, bool value);
 
 /**
- * Flush with error handling.
+ * Set the extract_embedded option.
+ *
+ * Only the JSON embedded in surrounding prose is repaired: the span runs from the first
+ * `{` or `[` to the closer that balances it, skipping brackets inside strings, so
+ * `Here is the result: {"a":1}. Thanks!` becomes `{"a":1}`. An opener that is never
+ * closed extends to the end of the input.
  *
  * # Safety
-* - `stream` must be a valid pointer to StreamRepairer
- * - `error` can be NULL to ignore error details
+* - `opts` must be a valid pointer to Options
  */
-char *jsonrepair_stream_flush_ex(struct StreamRepairer *stream, struct JsonRepairError *error);
+void jsonrepair_options_set_extract_embedded(struct Options *opts, bool value);
 
 /**
- * Get the library version string (C API).
+ * Set the missing_values option.
  *
-* Returns a process-static, read-only string pointer; callers must not free it.
+ * Controls keys with no value, with or without a colon (`{"a":}`, `{"a"}`):
+ * `INSERT_EMPTY_STRING` (default), `INSERT_NULL` or `DROP_KEY`. An unknown `mode` is
+ * ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
  */
-const char *jsonrepair_version(void);
+
+void jsonrepair_options_set_missing_values(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the ascii_scope option.
+ *
+ * Escapes non-ASCII characters as `\uXXXX` in keys only, values only, or both.
+ * `jsonrepair_options_set_ensure_ascii(opts, true)` still escapes everything.
+ * An unknown `scope` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_ascii_scope(struct Options *opts, uint32_t scope);
+
+/**
+ * Set the normalize_numbers option.
+ *
+ * Numbers are emitted in one canonical spelling (`1.50E+03` becomes `1.5e3`,
+ * `-0` becomes `0`) so equal values produce equal output.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_normalize_numbers(struct Options *opts, bool value);
+
+/**
+ * Set the equals_separators option.
+ *
+ * Object members may use `=`, `=>` or `->` instead of `:` (`{a => 1, b = 2, c -> 3}`);
+ * all separators are emitted as `:`.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_equals_separators(struct Options *opts, bool value);
+
+/**
+ * Set the dedup_position option.
+ *
+ * Collapses duplicate object keys so the last value wins. `DEDUP_FIRST_POSITION` keeps
+ * the survivor where the key first appeared (`{"a":1,"b":2,"a":3}` → `{"a":3,"b":2}`);
+ * `DEDUP_LAST_POSITION` moves it to the last occurrence (`{"b":2,"a":3}`).
+ * `DEDUP_KEEP_ALL` (default) keeps every duplicate.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_dedup_position(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the decode_base64 option.
+ *
+ * When the whole input is standard base64 that decodes to text opening an object or
+ * array, decode it first and repair the result. Any other input, including base64 that
+ * decodes to non-JSON, is repaired unchanged.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_decode_base64(struct Options *opts, bool value);
+
+/**
+ * Set the annotate_source option.
+ *
+ * Follows every parsed value with an `@src:OFFSET` block comment holding the byte offset
+ * in the input where it started. Requires `FORMAT_JSON5` output
+ * (`jsonrepair_options_set_output_format`); with strict JSON output the repair fails. Use
+ * it for debugging only.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_annotate_source(struct Options *opts, bool value);
+
+/**
+ * Set the stray_tokens option.
+ *
+ * Controls bare array elements that are not keywords, as in `[1, garbage, 2]`:
+ * `STRAY_ERROR` (default) fails with a `PARSE` error at the token's offset,
+ * `STRAY_QUOTE` emits `"garbage"` and `STRAY_DROP` removes the element.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_stray_tokens(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the force_container option.
+ *
+ * Guarantees an object or array at the top level: a scalar result such as `5` becomes
+ * `[5]` (`FORCE_ARRAY`) or `{"value":5}` (`FORCE_OBJECT`). Objects, arrays and empty
+ * output are unchanged. `FORCE_OFF` (default) keeps scalars.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_force_container(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the envelope option.
+ *
+ * Wraps the output in `{"value":...,"repaired":...,"repairs":N}`: `repaired` is whether the
+ * value differs from the input and `repairs` counts the fixes applied. An empty value is
+ * `null`. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_envelope(struct Options *opts, bool value);
+
+/**
+ * Set the fix_mojibake option.
+ *
+ * Repairs curly quotes, dashes and ellipses whose UTF-8 bytes were misdecoded as
+ * Windows-1252 (`â€œkeyâ€` → `"key"`) before repairing. Heuristic; off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_fix_mojibake(struct Options *opts, bool value);
+
+/**
+ * Set the alt_quotes option from a list of delimiter pairs.
+ *
+ * `pairs` is a UTF-8 string of characters taken two at a time as (opening, closing), so
+ * `"«»‹›"` reads guillemets as double quotes and `"「」『』"` does the same for CJK corner
+ * brackets. Outside a string, text between a pair is repaired as a double-quoted string;
+ * inside an ASCII-quoted string the characters are kept. A trailing unpaired character is
+ * ignored, and NULL or `""` clears the list. Non-UTF-8 input leaves the list unchanged.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `pairs` must be a valid null-terminated string, or NULL
+ */
+void jsonrepair_options_set_alt_quote_chars(struct Options *opts, const char *pairs);
+
+/**
+ * Set the line_continuations option.
+ *
+ * A backslash directly before a line break inside a string is a line continuation.
+ * `CONTINUATION_ELIDE` (default) removes both and joins the lines; `CONTINUATION_KEEP`
+ * removes the backslash and keeps the break as `\n` (`\r\n` for a CRLF break).
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_line_continuations(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the fix_backslashes option.
+ *
+ * Keeps a lone backslash inside a string that does not start a valid escape, so a Windows
+ * path such as `"C:\Users"` is written as `"C:\\Users"` instead of `"C:Users"`. Genuine
+ * escapes (`\n`, `\t`, `\uXXXX` and the rest) are still decoded; a `\u` without four hex
+ * digits is kept as text. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_fix_backslashes(struct Options *opts, bool value);
+
+/**
+ * Set the salvage option.
+ *
+ * When an array element or object value fails with a parse error (for example a stray
+ * token under `STRAY_ERROR`), `SALVAGE_NULL` and `SALVAGE_MARKER` replace it, up to the
+ * next `,` or closing bracket, and repair the rest of the document. `SALVAGE_FAIL`
+ * (default) fails the whole repair. Budget errors are never salvaged.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_salvage(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the drop_placeholder option.
+ *
+ * The placeholder is written in place of an array element dropped under `STRAY_DROP` and
+ * of a value replaced under `SALVAGE_NULL`. When it is one JSON value (`null`, `-1`,
+ * `{"dropped":true}`) it is written minified; any other text is written as a JSON
+ * string. NULL clears it (default). Non-UTF-8 input leaves it unchanged.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `placeholder` must be a valid null-terminated string, or NULL
+ */
+void jsonrepair_options_set_drop_placeholder(struct Options *opts, const char *placeholder);
+
+/**
+ * Set the strictness option.
+ *
+ * An object value that is itself followed by a colon (`{a: b: c}`) fails with a parse
+ * error under `STRICTNESS_CONSERVATIVE` (default). `STRICTNESS_AGGRESSIVE` reads it as the
+ * key of a nested object instead, giving `{"a":{"b":"c"}}`. An array element followed by
+ * a colon is handled the same way: `[a: 1, b: 2]` fails, or becomes `[{"a":1},{"b":2}]`
+ * (see `jsonrepair_options_set_brackets_as_objects()` to read it as one object).
+ * `STRICTNESS_AGGRESSIVE` also reads braces holding only values as an array (`{1, 2}` →
+ * `[1,2]`).
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_strictness(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the brackets_as_objects option.
+ *
+ * Brackets whose first element is followed by a colon are read as an object, whatever the
+ * strictness: `["a": 1, "b": 2]` becomes `{"a":1,"b":2}` instead of failing or becoming
+ * `[{"a":1},{"b":2}]`. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_brackets_as_objects(struct Options *opts, bool value);
+
+/**
+ * Set the utf8_strictness option.
+ *
+ * Under `UTF8_STRICT` (default) input that is not well-formed UTF-8, such as an overlong
+ * encoding (`C0 AF` for `/`), fails with `INVALID_UTF8` at the first bad byte.
+ * `UTF8_LENIENT` replaces each invalid sequence with U+FFFD and repairs the rest. Applies to
+ * the repair functions that take options; streaming chunks are always checked strictly.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_utf8_strictness(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the output_format option.
+ *
+ * `FORMAT_JSON5` renders the result as JSON5: object keys that are ASCII identifiers are
+ * unquoted and every other string is single-quoted. Nothing else changes; no trailing
+ * commas are added and input comments are not preserved.
+ * An unknown `format` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_output_format(struct Options *opts, uint32_t format);
+
+/**
+ * Set the comma_decimal option.
+ *
+ * Reads `{"price": 3,14}` as `{"price":3.14}`. Only object values are considered, and
+ * only when both sides of the comma are digits with no space around it and the fraction
+ * is followed by `}`, the end of input, or `,` and the next key. Arrays such as `[3,14]`
+ * are never changed. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_comma_decimal(struct Options *opts, bool value);
+
+/**
+ * Set the eval_fractions option.
+ *
+ * Reads `{"ratio": 1/2}` as `{"ratio":0.5}` in object values and array elements. Both
+* sides must be bare integers of at most 15 digits with no space around the slash (only
+ * the numerator may be negative), and the fraction must be followed by `,`, `}`, `]` or
+ * the end of input. Anything that could be a path or a date stays a string: `/usr/bin`,
+ * `1/usr`, `1 / 2`, `1/2/3`, `1.5/2`, `01/02`, `1/0`. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_eval_fractions(struct Options *opts, bool value);
+
+/**
+ * Set the dedup_arrays option.
+ *
+ * Drops repeated scalar array elements, keeping the first (`[1,1,2,"a","a"]` becomes
+ * `[1,2,"a"]`). Strings compare by decoded text, numbers by normalized spelling (`1` and
+ * `1.0` are equal) and `true`/`false`/`null` literally. Objects and arrays are never
+ * dropped. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_dedup_arrays(struct Options *opts, bool value);
+
+/**
+ * Set the sort_keys option.
+ *
+ * Orders the members of every object by key (`{"b":1,"a":2}` becomes `{"a":2,"b":1}`),
+* at every depth. Keys compare by decoded text in code point order; arrays keep their
+ * order. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_sort_keys(struct Options *opts, bool value);
+
+/**
+ * Set the storage preset: one canonical, compact form for dedup-friendly storage.
+ *
+ * Sets raw_message_safe (compact, no surrounding whitespace, trailing newline or BOM),
+ * sort_keys, `DEDUP_LAST_POSITION` (a repeated key keeps its last value), normalize_numbers
+ * and minimal_escapes, and for the escapes to be minimal turns off ensure_ascii, ascii_scope
+ * and escape_slashes and turns on short_escapes. Other options keep their values, and
+ * later setters may still change these.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_preset_storage(struct Options *opts);
+
+/**
+ * Set the escape_slashes option.
+ *
+ * Emits every `/` inside strings as `\/`, so `"</script>"` becomes `"<\/script>"`
+ * when JSON is embedded in HTML. Slashes outside strings are not touched. Off by
+ * default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_escape_slashes(struct Options *opts, bool value);
+
+/**
+ * Set the minimal_escapes option.
+ *
+ * Strings are written with the fewest escapes JSON allows: only `"`, `\\` and control
+ * characters stay escaped, so `"a\/b \u0041"` becomes `"a/b A"`. Non-ASCII characters
+ * stay escaped when `ensure_ascii` or `ascii_scope` asks for it.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_minimal_escapes(struct Options *opts, bool value);
+
+/**
+ * Set the short_escapes option.
+ *
+* Control characters in strings, such as a tab pasted into `"x<TAB>y"`, are escaped as
+ * `\b \f \n \r \t` where JSON has a short form. When off, every control character is
+ * written as `\u00XX` (`"x\u0009y"`), including short escapes already in the input. On
+ * by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_short_escapes(struct Options *opts, bool value);
+
+/**
+ * Set the strip_trailing_line_ws option.
+ *
+ * Spaces and tabs at the end of each line inside multi-line string values are removed:
+ * `"a = 1;  \n  b"` becomes `"a = 1;\n  b"`. Only whitespace directly before a `\n` or
+ * `\r` is dropped; keys are not touched.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_strip_trailing_line_ws(struct Options *opts, bool value);
+
+/**
+ * Set the collapse_ws option.
+ *
+ * Each run of whitespace inside string values becomes a single space: `"a \t  b"` becomes
+ * `"a b"`. This changes the data, so only turn it on for prose such as scraped text. The
+ * ends of a string are not trimmed and keys are not touched. Line breaks are kept unless
+ * `jsonrepair_options_set_collapse_ws_newlines()` is on. Default: false.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_collapse_ws(struct Options *opts, bool value);
+
+/**
+ * Set the collapse_ws_newlines option.
+ *
+ * With collapse_ws on, `\n` and `\r` join the whitespace run as well, so a multi-line value
+ * becomes one line. When off, each line break is kept and only the whitespace around it is
+ * collapsed. Default: false.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_collapse_ws_newlines(struct Options *opts, bool value);
+
+/**
+ * Set the add_missing_brackets option.
+ *
+ * Wraps an input that is only a container body: `"a": 1, "b": 2` becomes
+ * `{"a":1,"b":2}` when the first token is a key followed by `:`, and `1, 2, 3`
+ * becomes `[1,2,3]` when it starts with a scalar and has a top-level comma.
+ * Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_add_missing_brackets(struct Options *opts, bool value);
+
+/**
+ * Set the parens_as_arrays option.
+ *
+ * A parenthesized list in value position is read as an array: `(1, 2, 3)` becomes
+ * `[1,2,3]`. The argument list of a call such as `ObjectId("x")` is not converted.
+ * Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_parens_as_arrays(struct Options *opts, bool value);
+
+/**
+ * Set the compact_spacing option.
+ *
+ * `SPACING_MINIMAL` puts exactly one space after every `:` and `,` outside strings, so
+ * `{"a":[1,2]}` becomes `{"a": [1, 2]}`. Any other whitespace between tokens is removed,
+ * including the spacing of input that was already valid. `SPACING_NONE` keeps the
+ * current compact output.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+
+void jsonrepair_options_set_compact_spacing(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the indent_detect option.
+ *
+* Pretty-prints the output with the indentation unit of the input (a tab, four spaces,
+ * ...): the most common leading whitespace of the lines inside the top-level container.
+ * One-line input has no unit and stays compact. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_indent_detect(struct Options *opts, bool value);
+
+/**
+ * Set the align_values option.
+ *
+ * Together with `indent_detect`, pads the space after each `:` so the values of every
+ * object start in the same column, aligned per object on its longest key. Has no effect
+ * when the output is not indented or is JSON5. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_align_values(struct Options *opts, bool value);
+
+/**
+ * Set the crlf option.
+ *
+* Ends the lines of pretty-printed output (`indent_detect`, and the pretty form of
+ * `jsonrepair_repair_both()`) with `\r\n` instead of `\n`. Newlines inside string values
+ * stay escaped, and compact output is not changed. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_crlf(struct Options *opts, bool value);
+
+/**
+ * Set the concat_adjacent_strings option.
+ *
+ * Joins string literals separated only by whitespace, newlines or comments, as in
+ * `"line1"\n"line2"` (read as `"line1line2"`). A following string that is an object key
+ * is left alone. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_concat_adjacent_strings(struct Options *opts, bool value);
+
+/**
+ * Set the progress callback.
+ *
+ * During a repair `callback` is called at most about 100 times, with the number of input
+ * bytes parsed so far and the total, and once more with `done == total` when the repair
+ * succeeds. It runs on the thread that called the repair function. Pass NULL to remove
+ * it. Streams (`jsonrepair_stream_*`) do not report progress. Unset by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `userdata` must stay valid for as long as `opts` (or a copy made from it) is used
+ */
+
+void jsonrepair_options_set_progress_callback(struct Options *opts,
+                                              JsonRepairProgressFn callback,
+                                              void *userdata);
+
+/**
+ * Set the number format callback.
+ *
+ * Each number of the repaired output is passed to `callback` and replaced with what it
+ * returns, e.g. to write currency with two decimals. Quoted digits, keys and comments are
+ * not passed. A result that is not a JSON number fails the repair with a `PARSE` error.
+ * It runs on the thread that called the repair function. Pass NULL to remove it. Unset
+ * by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `userdata` must stay valid for as long as `opts` (or a copy made from it) is used
+ */
+
+void jsonrepair_options_set_number_format_callback(struct Options *opts,
+                                                   JsonRepairNumberFormatFn callback,
+                                                   void *userdata);
+
+/**
+ * Set the trace option.
+ *
+ * A developer aid: while on, each repair made with `opts` (or a copy made from it) appends
+ * a line per parser decision to a trace kept with the options, such as
+ * `@5 repair: inserted missing comma`; read it with `jsonrepair_options_take_trace()`.
+ * Only the recursive engine records a trace, and the output is the same either way.
+ * Turning it off drops the trace. Default: false.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_trace(struct Options *opts, bool value);
+
+/**
+ * Take the trace recorded with `opts` since the last call, one line per decision, and
+ * clear it.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL if `opts` is NULL or the trace option is off
+ */
+char *jsonrepair_options_take_trace(const struct Options *opts);
+
+/**
+ * Set the max_elements option.
+ *
+ * Repair aborts with `TOO_MANY_ELEMENTS` once a single array or object has more than `n`
+ * members, which bounds the work done on wide untrusted input. Pass 0 for no limit
+ * (default).
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_max_elements(struct Options *opts, size_t n);
+
+/**
+ * Set the max_key_len option.
+ *
+ * Repair aborts with `KEY_TOO_LONG` once an object key is longer than `n` bytes of UTF-8
+ * text, or cuts the key down when `jsonrepair_options_set_long_keys()` selects
+ * `LONG_KEYS_TRUNCATE`. Pass 0 for no limit (default).
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_max_key_len(struct Options *opts, size_t n);
+
+/**
+ * Set the max_input_bytes option.
+ *
+ * Repair fails with `INPUT_TOO_LARGE` before any work is done when the input is longer
+ * than `n` bytes, to protect services from oversized requests. This bounds what goes in,
+ * unlike max_elements and max_key_len, which are checked while parsing. Pass 0 for no
+ * limit (default).
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_max_input_bytes(struct Options *opts, size_t n);
+
+/**
+ * Set the long_keys option.
+ *
+ * Selects whether a key longer than `max_key_len` fails the repair (`LONG_KEYS_ERROR`,
+ * default) or is cut down to fit (`LONG_KEYS_TRUNCATE`), never splitting a UTF-8
+ * character.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_long_keys(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the tolerate_sql_comments option.
+ *
+ * Treats `--` followed by whitespace as a line comment, as in SQL-adjacent config files
+ * (`{a: 1 -- note}`). `--5` and `a--b` are left as values. Off by default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_tolerate_sql_comments(struct Options *opts, bool value);
+
+/**
+ * Set the overflow option.
+ *
+ * Controls numbers that overflow a double to infinity, such as `1e400`: `OVERFLOW_KEEP`
+ * leaves the literal as it is (default), `OVERFLOW_QUOTE` emits `"1e400"` so no digits
+ * are lost, and `OVERFLOW_NULL` emits `null`.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_overflow(struct Options *opts, uint32_t mode);
+
+/**
+ * Set the number_suffix option.
+ *
+ * Controls numbers with an attached unit suffix of letters or `%`, as in
+ * `{"timeout": 30s, "size": 10MB}`: `SUFFIX_QUOTE` emits `"30s"` and `"10MB"`,
+ * `SUFFIX_STRIP` emits `30` and `10`, and `SUFFIX_KEEP` (default) applies the general
+* number rules, which quote `30s` but split `100%` into `100` and a stray `%`.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_number_suffix(struct Options *opts, uint32_t mode);
+
+/**
+ * Quote numbers with a unit suffix (`30s` → `"30s"`, `100%` → `"100%"`).
+ *
+ * Shorthand for `jsonrepair_options_set_number_suffix()` with `SUFFIX_QUOTE` when `value`
+ * is true and `SUFFIX_KEEP` when it is false.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_quote_suffixed_numbers(struct Options *opts, bool value);
+
+/**
+ * Set the negative_zero option.
+ *
+ * `NEGATIVE_ZERO_NORMALIZE` drops the minus sign of any zero (`-0` → `0`, `-0.0` → `0.0`,
+ * `-0e5` → `0e5`) so equal values hash the same; `NEGATIVE_ZERO_PRESERVE` (default) keeps
+ * it. `normalize_numbers` always emits `0` for negative zero.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_negative_zero(struct Options *opts, uint32_t mode);
+
+/**
+* Set the safe_integers option.
+ *
+* Controls integer literals whose magnitude exceeds `2^53 - 1` (`9007199254740991`),
+ * which a JavaScript consumer would round: `SAFE_INTEGERS_CLAMP` replaces them with
+ * `9007199254740991` or `-9007199254740991`, `SAFE_INTEGERS_QUOTE` emits them as strings
+ * so every digit is kept, and `SAFE_INTEGERS_PASSTHROUGH` (default) leaves them alone.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_safe_integers(struct Options *opts, uint32_t mode);
+
+/**
+ * Register a typed wrapper `name(arg)` to unwrap.
+ *
+ * The call is replaced by its first argument: `UNWRAP_STRING` keeps it as a string
+ * (`UUID("ab")` → `"ab"`), `UNWRAP_NUMBER` makes a numeric string a number
+ * (`NumberLong("42")` → `42`, with `"NaN"` and `"Infinity"` handled per
+ * normalize_js_nonfinite) and `UNWRAP_NULL` emits `null`. Registering a name again
+ * replaces its mode. `ObjectId`, `ISODate`, `NumberInt`, `NumberLong`, `NumberDecimal` and
+ * `Decimal128` are registered by default. A NULL or non-UTF-8 `name` is ignored.
+ * An unknown `mode` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `name` must be a valid null-terminated string, or NULL
+ */
+void jsonrepair_options_add_unwrap_function(struct Options *opts, const char *name, uint32_t mode);
+
+/**
+ * Remove every registered typed wrapper, including the built-in ones.
+ *
+ * Calls such as `ObjectId("x")` are then repaired as bare text.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_clear_unwrap_functions(struct Options *opts);
+
+/**
+ * Register a keyword pair that stands for brackets.
+ *
+ * Outside strings and comments, the whole words `open_word` and `close_word` are read as
+ * `{`/`}` (`BRACKET_OBJECT`) or `[`/`]` (`BRACKET_ARRAY`), so with `BEGIN`/`END` as an
+ * object, `BEGIN a: 1 END` becomes `{"a":1}`. A word used as a key is left alone.
+ * Registering an opening word again replaces its pair. A NULL, empty or non-UTF-8 word is
+ * ignored.
+ * An unknown `kind` is ignored.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ * - `open_word` and `close_word` must be valid null-terminated strings, or NULL
+ */
+void jsonrepair_options_add_bracket_alias(struct Options *opts,
+                                          const char *open_word,
+                                          const char *close_word,
+                                          uint32_t kind);
+
+/**
+ * Remove every registered bracket alias.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_clear_bracket_aliases(struct Options *opts);
+
+/**
+ * Set the error_as_json option.
+ *
+ * When enabled, `jsonrepair_repair_with_options()` and `jsonrepair_repair_ex()` return
+ * `{"error":"...","offset":N}` instead of NULL for input that cannot be repaired, so the
+ * result is always JSON. `jsonrepair_repair_ex()` still reports the error in `error`.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_error_as_json(struct Options *opts, bool value);
+
+/**
+ * Set the strip_ellipsis option.
+ *
+ * Drops bare `...` placeholders that mark omitted content (`[1, 2, ...]` becomes `[1,2]`,
+ * `{"a": 1, ...}` becomes `{"a":1}`). Quoted `"..."` strings and spread syntax such as
+ * `...rest` are left alone. When off, a bare `...` is kept as the string `"..."`. On by
+ * default.
+ *
+ * # Safety
+* - `opts` must be a valid pointer to Options
+ */
+void jsonrepair_options_set_strip_ellipsis(struct Options *opts, bool value);
+
+/**
+ * Repair a JSON string with custom options.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error, or an error object with `error_as_json`
+ */
+char *jsonrepair_repair_with_options(const char *input, const struct Options *opts);
+
+/**
+ * Repair a JSON string with error details.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error, or an error object with `error_as_json`
+ */
+
+char *jsonrepair_repair_ex(const char *input,
+                           const struct Options *opts,
+                           struct JsonRepairError *error);
+
+/**
+ * Repair a single JSON input and hand the output to `write` in chunks as it is produced,
+ * so a huge value never has to be held in memory as one string.
+ *
+ * Chunks are at most 64 KiB, are not NUL-terminated and may split a UTF-8 sequence. The
+ * recursive engine passes them on while the input is parsed. The LLM engine, `salvage`,
+ * and options that rewrite the finished output (such as `dedup_position`, `indent_detect`
+ * or JSON5 output) need the whole result first and pass it on at the end.
+ *
+ * Backpressure: `write` runs on the calling thread and the repair waits for it to return,
+ * so a slow consumer slows the repair down rather than letting output pile up. Returning a
+ * non-zero value stops the repair with a `PARSE` error. After any failure the chunks
+ * already written are an incomplete document and should be discarded.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `write` must not be NULL; `data` is only valid during the call
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - Returns true on success, false on error
+ */
+
+bool jsonrepair_repair_streaming_output(const char *input,
+                                        const struct Options *opts,
+                                        JsonRepairWriteFn write,
+                                        void *userdata,
+                                        struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and compute the SHA-256 digest of the result.
+ *
+ * The digest covers the returned bytes exactly as emitted (excluding the
+ * terminating NUL).
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+* - `hash_out` must point to at least 32 writable bytes, or be NULL to skip the digest
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error; `hash_out` is left untouched
+ */
+
+char *jsonrepair_repair_hashed(const char *input,
+                               const struct Options *opts,
+                               uint8_t *hash_out,
+                               struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and report the type of its root value.
+ *
+* The kind describes the output as emitted: several root values wrapped into one array
+ * report `VALUE_KIND_ARRAY`.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `kind` can be NULL to ignore the type
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error; `kind` is left untouched
+ */
+
+char *jsonrepair_repair_with_kind(const char *input,
+                                  const struct Options *opts,
+                                  enum JsonRepairValueKind *kind,
+                                  struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and report whether the output differs from the input.
+ *
+ * `*changed` is set to false when the output is byte-for-byte the input (the default
+ * engine copies valid JSON through as written) and to true otherwise. Cheaper than
+ * computing the edits when only "was anything repaired?" matters.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `changed` can be NULL to ignore the flag
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error; `changed` is left untouched
+ */
+char *jsonrepair_repair_changed(const char *input,
+                                const struct Options *opts,
+                                bool *changed,
+                                struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and return its type skeleton.
+ *
+ * Every scalar is replaced by the name of its type (`"string"`, `"number"`, `"boolean"`,
+ * `"null"`), so `{a: 1, b: ['x']}` gives `{"a":"number","b":["string"]}`. An array lists
+ * the distinct skeletons of its elements once each, in order of first appearance.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error
+ */
+char *jsonrepair_repair_skeleton(const char *input,
+                                 const struct Options *opts,
+                                 struct JsonRepairError *error);
+
+/**
+* Repair a JSON string once and return it both minified and pretty-printed.
+ *
+ * The compact form is returned and the pretty form, indented by `indent` spaces per
+ * level, is stored in `*pretty`. The pretty form is laid out from the compact one
+ * without parsing again, so both hold the same values.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+* - `pretty` must be a valid pointer; on success `*pretty` must be freed with `jsonrepair_free()`
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error and sets `*pretty` to NULL
+ */
+char *jsonrepair_repair_both(const char *input,
+                             size_t indent,
+                             const struct Options *opts,
+                             char **pretty,
+                             struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string with its comments removed from the output and returned separately.
+ *
+* `*comments` receives a JSON object mapping the JSON Pointer of each commented value to
+ * the comment text (`{"/name":"who","/age":"years"}`), in document order. A comment
+ * belongs to the value that starts before it on the same line, otherwise to the next
+ * value; several comments of one value are joined with `\n`, and leading comments of the
+ * document use the key `""`. Always uses the recursive engine.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+* - `comments` must be a valid pointer; on success `*comments` must be freed with `jsonrepair_free()`
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error and sets `*comments` to NULL
+ */
+
+char *jsonrepair_repair_with_comments(const char *input,
+                                      const struct Options *opts,
+                                      char **comments,
+                                      struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and list the paths of the values it repaired.
+ *
+ * `*paths` receives one path per repaired value, written as in the repair log
+ * (`$["items"][2]["name"]`), listed once each in the order of their first repair. A
+ * repair to a container's own syntax (a key, comma or bracket) gives the container's
+ * path. Valid input gives an empty list, as does a build without the `logging` feature.
+ * Always uses the recursive engine.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+* - `paths` must be a valid pointer; on success `*paths` must be freed with `jsonrepair_string_list_free()`
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error and sets `*paths` to NULL
+ */
+char *jsonrepair_repair_affected_paths(const char *input,
+                                       const struct Options *opts,
+                                       struct JsonRepairStringList **paths,
+                                       struct JsonRepairError *error);
+
+/**
+* Repair a JSON string and return only the value at a JSON Pointer.
+ *
+* `pointer` follows RFC 6901 (`"/result/0"`; `""` selects the whole document).
+ * A missing target fails with `POINTER_NOT_FOUND`.
+ *
+ * # Safety
+* - `input` and `pointer` must be valid null-terminated UTF-8 strings
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error
+ */
+
+char *jsonrepair_repair_extract(const char *input,
+                                const char *pointer,
+                                const struct Options *opts,
+                                struct JsonRepairError *error);
+
+/**
+ * Repair a document of concatenated root values and return each value separately.
+ *
+ * `{a:1}{b:2}[1,2]` gives three strings: `{"a":1}`, `{"b":2}` and `[1,2]`. Values
+ * may be separated by whitespace, newlines, commas or nothing.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - Returns NULL on error; otherwise a list (possibly empty) that must be freed with
+ *   `jsonrepair_string_list_free()`
+ */
+
+struct JsonRepairStringList *jsonrepair_repair_split(const char *input,
+                                                     const struct Options *opts,
+                                                     struct JsonRepairError *error);
+
+/**
+ * Repair only the first root value of `input` and report how many input bytes it used.
+ *
+ * `{a: 1} junk` gives `{"a":1}` with `*consumed` set to 6. The count includes leading
+ * whitespace and comments; nothing after the value is parsed.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `consumed` can be NULL to ignore the byte count; it is set to 0 on error
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error, including input with no value
+ */
+
+char *jsonrepair_repair_first(const char *input,
+                              const struct Options *opts,
+                              size_t *consumed,
+                              struct JsonRepairError *error);
+
+/**
+ * Repair a JSON string and report the byte offset where its last root value ends.
+ *
+ * `{a: 1} {b: 2} <EOF>` gives `[{"a":1},{"b":2}]` with `*end_offset` set to 13, so a
+ * reader can advance past the values it got. Trailing data the repair ignores is not
+ * counted; with the stop_after_first option the offset is the end of the first value, as
+ * with `jsonrepair_repair_first()`. Input with no value gives 0.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `end_offset` can be NULL to ignore the offset; it is set to 0 on error
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error
+ */
+
+char *jsonrepair_repair_with_end(const char *input,
+                                 const struct Options *opts,
+                                 size_t *end_offset,
+                                 struct JsonRepairError *error);
+
+/**
+ * Free a string list, including all of its strings.
+ *
+ * # Safety
+* - `list` must be a pointer returned by `jsonrepair_repair_split()` or
+ *   `jsonrepair_list_repair_categories()`, or NULL
+ * - Do not use `list` after calling this function
+ */
+void jsonrepair_string_list_free(struct JsonRepairStringList *list);
+
+/**
+ * Repair UTF-16 input of `len` bytes and return UTF-8 output.
+ *
+ * A leading BOM selects the byte order; otherwise `endian` (a `JsonRepairUtf16Endian`)
+ * is used. An unknown `endian` fails with `PARSE`.
+ *
+ * # Safety
+* - `input` must point to at least `len` readable bytes (it need not be NUL-terminated)
+* - `opts` must be a valid pointer to Options, or NULL for defaults
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error, including malformed UTF-16
+ */
+
+char *jsonrepair_repair_utf16(const uint8_t *input,
+                              size_t len,
+                              uint32_t endian,
+                              const struct Options *opts,
+                              struct JsonRepairError *error);
+
+/**
+ * Minify a string that must already be valid JSON.
+ *
+ * Invalid input is rejected rather than repaired. Use `jsonrepair_minify_ex()`
+ * to get the error position.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error (including invalid JSON)
+ */
+char *jsonrepair_minify(const char *input);
+
+/**
+ * Minify a string that must already be valid JSON, with error details.
+ *
+ * # Safety
+ * - `input` must be a valid null-terminated UTF-8 string
+ * - `error` can be NULL to ignore error details
+ * - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+ * - The returned string must be freed with `jsonrepair_free()`
+ * - Returns NULL on error; invalid JSON is reported as `INVALID_JSON`
+ */
+char *jsonrepair_minify_ex(const char *input, struct JsonRepairError *error);
+
+/**
+ * Create a new streaming repairer.
+ *
+ * # Safety
+ * - `opts` can be NULL for default options
+ * - Must be freed with `jsonrepair_stream_free()`
+ */
+struct StreamRepairer *jsonrepair_stream_new(const struct Options *opts);
+
+/**
+ * Free a streaming repairer.
+ *
+ * # Safety
+* - `stream` must be a pointer returned by `jsonrepair_stream_new()`, or NULL
+ */
+void jsonrepair_stream_free(struct StreamRepairer *stream);
+
+/**
+ * Push a chunk to the streaming repairer.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `chunk` must be a valid null-terminated UTF-8 string
+ * - Returns NULL if no complete value yet, or a string that must be freed with `jsonrepair_free()`
+ */
+char *jsonrepair_stream_push(struct StreamRepairer *stream, const char *chunk);
+
+/**
+ * Flush the streaming repairer.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
+ */
+char *jsonrepair_stream_flush(struct StreamRepairer *stream);
+
+/**
+ * Push a chunk with error handling.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `chunk` must be a valid null-terminated UTF-8 string
+ * - `error` can be NULL to ignore error details
+ */
+
+char *jsonrepair_stream_push_ex(struct StreamRepairer *stream,
+                                const char *chunk,
+                                struct JsonRepairError *error);
+
+/**
+ * Flush with error handling.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `error` can be NULL to ignore error details
+ */
+char *jsonrepair_stream_flush_ex(struct StreamRepairer *stream, struct JsonRepairError *error);
+
+/**
+ * Flush the stream and reset it for the next document, in one call.
+ *
+ * Equivalent to `jsonrepair_stream_flush_ex` followed by replacing the stream with a new
+ * one built from the same options; the recover, max-buffer and range-tracking settings are
+ * kept. Skipped regions and ranges not taken yet survive the reset; stats start over. The
+ * stream is reset even when the flush fails.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `error` can be NULL to ignore error details
+ * - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
+ */
+char *jsonrepair_stream_finalize(struct StreamRepairer *stream, struct JsonRepairError *error);
+
+/**
+ * Push a chunk in validate-only mode and return the status of each value it completes.
+ *
+ * Values are checked against strict JSON and reported unchanged; nothing is repaired.
+ * The stream switches to validate-only mode on the first call.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `chunk` must be a valid null-terminated UTF-8 string
+ * - `error` can be NULL to ignore error details
+ * - Returns NULL on error; otherwise a list (possibly empty) that must be freed with
+ *   `jsonrepair_value_status_list_free()`
+ */
+
+struct JsonRepairValueStatusList *jsonrepair_stream_push_validate(struct StreamRepairer *stream,
+                                                                  const char *chunk,
+                                                                  struct JsonRepairError *error);
+
+/**
+ * Flush a stream in validate-only mode and return the remaining statuses.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - `error` can be NULL to ignore error details
+ * - Returns NULL on error; otherwise a list that must be freed with
+ *   `jsonrepair_value_status_list_free()`
+ */
+
+struct JsonRepairValueStatusList *jsonrepair_stream_flush_validate(struct StreamRepairer *stream,
+                                                                   struct JsonRepairError *error);
+
+/**
+ * Free a status list, including all of its strings.
+ *
+ * # Safety
+* - `list` must be a pointer returned by the stream validation API, or NULL
+ * - Do not use `list` after calling this function
+ */
+void jsonrepair_value_status_list_free(struct JsonRepairValueStatusList *list);
+
+/**
+ * Enable or disable error recovery on a stream.
+ *
+ * With recovery on, a root value that cannot be repaired is skipped, together
+ * with root-level input up to the next `{` or `[`, instead of failing the push.
+ * Collect the skipped regions with `jsonrepair_stream_take_skipped()`.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ */
+void jsonrepair_stream_set_recover(struct StreamRepairer *stream, bool value);
+
+/**
+ * Take the regions skipped in recovery mode since the last call.
+ *
+ * Each item has `valid == false`, the skipped source text, and the error of
+ * the value that could not be repaired.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+ *   freed with `jsonrepair_value_status_list_free()`
+ */
+struct JsonRepairValueStatusList *jsonrepair_stream_take_skipped(struct StreamRepairer *stream);
+
+/**
+ * Cap the bytes a stream may buffer for a value that has not completed yet.
+ *
+ * `bytes == 0` (the default) means unlimited. A push that leaves more than `bytes`
+ * buffered fails with `BUFFER_OVERFLOW` and discards the incomplete value; values
+ * completed earlier in the same chunk are returned by the next push or flush.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ */
+void jsonrepair_stream_set_max_buffer(struct StreamRepairer *stream, size_t bytes);
+
+/**
+ * Enable or disable recording the input byte range of each repaired value.
+ *
+ * Offsets count bytes from the start of the stream, across all pushed chunks,
+ * and leave out whitespace and comments around the value. Collect them with
+ * `jsonrepair_stream_take_ranges()`.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ */
+void jsonrepair_stream_set_track_ranges(struct StreamRepairer *stream, bool value);
+
+/**
+ * Take the value ranges recorded since the last call, in stream order.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+ *   freed with `jsonrepair_value_range_list_free()`
+ */
+struct JsonRepairValueRangeList *jsonrepair_stream_take_ranges(struct StreamRepairer *stream);
+
+/**
+ * Free a range list, including all of its strings.
+ *
+ * # Safety
+* - `list` must be a pointer returned by `jsonrepair_stream_take_ranges()`, or NULL
+ * - Do not use `list` after calling this function
+ */
+void jsonrepair_value_range_list_free(struct JsonRepairValueRangeList *list);
+
+/**
+* Report that the stream is alive every `interval_bytes` input bytes pushed
+ * without a value completing (0, the default, turns reports off).
+ *
+* A report is recorded at the end of a push once at least `interval_bytes` have
+ * arrived since the last completed value or report, at most one per push. Collect
+ * them with `jsonrepair_stream_take_heartbeats()`; the repaired output is unchanged.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ */
+void jsonrepair_stream_set_heartbeat(struct StreamRepairer *stream, size_t interval_bytes);
+
+/**
+ * Take the heartbeats recorded since the last call, in stream order.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+ *   freed with `jsonrepair_heartbeat_list_free()`
+ */
+struct JsonRepairHeartbeatList *jsonrepair_stream_take_heartbeats(struct StreamRepairer *stream);
+
+/**
+ * Free a heartbeat list.
+ *
+ * # Safety
+* - `list` must be a pointer returned by `jsonrepair_stream_take_heartbeats()`, or NULL
+ * - Do not use `list` after calling this function
+ */
+void jsonrepair_heartbeat_list_free(struct JsonRepairHeartbeatList *list);
+
+/**
+ * Get the totals of a streaming session so far.
+ *
+ * Counts cover every push and flush since the stream was created. Values dropped
+ * in recovery mode and validate-only statuses are not counted, and values repaired
+ * by the LLM-compatible engine add no repairs.
+ *
+ * # Safety
+* - `stream` must be a valid pointer to StreamRepairer
+ * - Returns all zeros if `stream` is NULL
+ */
+struct JsonRepairStreamStats jsonrepair_stream_stats(const struct StreamRepairer *stream);
+
+/**
+ * Get the library version string (C API).
+ *
+* Returns a process-static, read-only string pointer; callers must not free it.
+ */
+const char *jsonrepair_version(void);
+
+/**
+ * Describe this build as a JSON object, for bug reports (C API).
+ *
+ * ```json
+ * {"version":"0.1.0",
+ *  "features":{"serde":true,"logging":true,"llm_compat":false,"c_api":true},
+*  "target":{"arch":"x86_64","os":"linux","wasm":false,"pointer_width":64},
+ *  "max_depth":null,"unwrap_escaped_max_depth":8}
+ * ```
+ *
+ * `features` holds the Cargo features the library was compiled with. `max_depth` is the
+ * deepest nesting the parsers accept, `null` when there is no fixed limit (only the stack
+ * bounds it), and `unwrap_escaped_max_depth` the number of string-escaping layers
+ * `unwrap_escaped_json` removes. Keys may be added in later versions, never removed.
+ *
+ * The string is built on the first call. Returns a process-static, read-only string
+* pointer; callers must not free it.
+ */
+const char *jsonrepair_features(void);
+
+/**
+ * List the repair categories this build can log, the same names that appear in
+ * `RepairLogEntry::message`.
+ *
+ * Tools that explain repairs can use this instead of hardcoding the names. The list must be
+ * freed with `jsonrepair_string_list_free()`.
+ */
+struct JsonRepairStringList *jsonrepair_list_repair_categories(void);
+
+/**
+ * Whether an option, given as a `JsonRepairOption` id, takes effect in this build.
+ *
+ * Options behind a Cargo feature keep their setters in every build, but the setters do
+ * nothing when the feature is compiled out: `jsonrepair_options_set_logging()` needs
+ * `logging`. Ids this version of the library does not know return false, so a caller built
+ * against a newer header can tell that an option is missing rather than silently ignored.
+ */
+bool jsonrepair_option_supported(uint32_t option);
 
 #ifdef __cplusplus
 }  // extern "C"
@@ -0,0 +1,88 @@
+//! Alternative quote characters, for `Options::alt_quotes`: text that delimits strings with
+//! `«»`, `「」` or another configured pair is rewritten to use ASCII double quotes before
+//! repairing, so `{«name»: «Zoë»}` reads as `{"name": "Zoë"}`.
+//!
+//! Only pairs outside a string count as delimiters; inside an ASCII-quoted string the
+//! characters are content and are kept. Inside an alternative string, a raw `"` is escaped
+//! and a nested opening character of the same pair must be closed before the string ends.
+
+use crate::srcmap::{Rewrite, StepMap};
+
+enum Open {
+    // Not inside a string.
+    None,
+    // Inside a string delimited by this ASCII quote.
+    Ascii(char),
+    // Inside a string opened by `open`, with the number of nested `open`s still unclosed.
+    Alt {
+        open: char,
+        close: char,
+        depth: usize,
+    },
+}
+
+/// Return `input` with strings delimited by one of `pairs` (opening, closing) given ASCII
+/// double quotes, or `None` when no opening character of a pair occurs.
+pub(crate) fn normalize(input: &str, pairs: &[(char, char)]) -> Option<(String, StepMap)> {
+    if pairs.is_empty() || !input.contains(|c| pairs.iter().any(|&(o, _)| o == c)) {
+        return None;
+    }
+    let mut out = Rewrite::new(input);
+    let mut open = Open::None;
+    let mut escape = false;
+    for (i, c) in input.char_indices() {
+        let src = &input[i..i + c.len_utf8()];
+        match &mut open {
+            Open::Ascii(q) => {
+                out.push_str(src);
+                if escape {
+                    escape = false;
+                } else if c == '\\' {
+                    escape = true;
+                } else if c == *q {
+                    open = Open::None;
+                }
+            }
+            Open::Alt {
+                open: o,
+                close,
+                depth,
+            } => {
+                if escape {
+                    escape = false;
+                } else if c == '\\' {
+                    escape = true;
+                } else if c == *close && *depth == 0 {
+                    out.push('"');
+                    out.skip(src);
+                    open = Open::None;
+                    continue;
+                } else if c == *close {
+                    *depth -= 1;
+                } else if c == *o {
+                    *depth += 1;
+                } else if c == '"' {
+                    out.push('\\');
+                }
+                out.push_str(src);
+            }
+            Open::None => {
+                if let Some(&(o, close)) = pairs.iter().find(|&&(o, _)| o == c) {
+                    out.push('"');
+                    out.skip(src);
+                    open = Open::Alt {
+                        open: o,
+                        close,
+                        depth: 0,
+                    };
+                    continue;
+                }
+                out.push_str(src);
+                if c == '"' || c == '\'' {
+                    open = Open::Ascii(c);
+                }
+            }
+        }
+    }
+    Some(out.finish())
+}
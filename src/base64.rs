@@ -0,0 +1,41 @@
+//! Minimal standard base64 (RFC 4648) decoding for `Options::decode_base64`, without
+//! pulling in a codec dependency.
+
+/// Decode `input` when all of it is standard base64: the `A-Z a-z 0-9 + /` alphabet, at most
+/// two trailing `=`, and a length that is a multiple of 4 once ASCII whitespace (MIME line
+/// breaks) is removed. Returns `None` for anything else, including empty input.
+pub(crate) fn decode(input: &str) -> Option<Vec<u8>> {
+    let digits: Vec<u8> = input.bytes().filter(|b| !b.is_ascii_whitespace()).collect();
+    if digits.is_empty() || !digits.len().is_multiple_of(4) {
+        return None;
+    }
+    let body = digits
+        .strip_suffix(b"==")
+        .or_else(|| digits.strip_suffix(b"="));
+    let body = body.unwrap_or(&digits);
+    let mut out = Vec::with_capacity(body.len() / 4 * 3 + 2);
+    let mut acc = 0u32;
+    let mut bits = 0u32;
+    for &b in body {
+        acc = (acc << 6) | sextet(b)?;
+        bits += 6;
+        if bits >= 8 {
+            bits -= 8;
+            out.push((acc >> bits) as u8);
+            acc &= (1 << bits) - 1;
+        }
+    }
+    Some(out)
+}
+
+fn sextet(b: u8) -> Option<u32> {
+    let v = match b {
+        b'A'..=b'Z' => b - b'A',
+        b'a'..=b'z' => b - b'a' + 26,
+        b'0'..=b'9' => b - b'0' + 52,
+        b'+' => 62,
+        b'/' => 63,
+        _ => return None,
+    };
+    Some(u32::from(v))
+}
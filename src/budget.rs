@@ -0,0 +1,151 @@
+//! Runtime budgets shared by the repair engines.
+//!
+//! A `Budget` is created once per repair call from `Options` and polled by the
+//! engines as they make progress. Polling is cheap: the clock is only read once
+//! every `CLOCK_CHECK_INTERVAL` polls. Engines also charge each repair they apply,
+//! which enforces `Options::max_repairs`, count container members against
+//! `Options::max_elements` and measure object keys against `Options::max_key_len`.
+//! Polls also drive `Options::progress`.
+
+use crate::error::{RepairError, RepairErrorKind};
+use crate::options::{LongKeyPolicy, Options, Progress};
+use std::cell::Cell;
+use std::time::{Duration, Instant};
+
+/// Number of polls between two wall-clock reads. Engines poll once per parsed
+/// value and once per container member, so the deadline is noticed after at most
+/// this many values past the budget.
+pub(crate) const CLOCK_CHECK_INTERVAL: u32 = 256;
+
+/// Upper bound on progress reports per repair call (not counting the final one).
+pub(crate) const PROGRESS_REPORTS: usize = 100;
+
+thread_local! {
+    // Repairs charged on this thread across all calls, so callers that repair many values
+    // (streams) can total them without a log.
+    static CHARGED: Cell<u64> = const { Cell::new(0) };
+}
+
+/// Total number of repairs charged on the current thread so far.
+pub(crate) fn repairs_charged() -> u64 {
+    CHARGED.with(Cell::get)
+}
+
+#[derive(Debug)]
+struct ProgressState {
+    callback: Progress,
+    total: usize,
+    step: usize,
+    next: usize,
+}
+
+#[derive(Debug, Default)]
+pub(crate) struct Budget {
+    deadline: Option<(Instant, u64)>,
+    polls: u32,
+    max_repairs: usize,
+    repairs: usize,
+    max_elements: usize,
+    max_key_len: usize,
+    truncate_keys: bool,
+    progress: Option<ProgressState>,
+}
+
+impl Budget {
+    /// `len` is the byte length of the parsed input, the total reported to `progress`.
+    pub(crate) fn from_options(opts: &Options, len: usize) -> Self {
+        let deadline = if opts.timeout_ms > 0 {
+            Instant::now()
+                .checked_add(Duration::from_millis(opts.timeout_ms))
+                .map(|at| (at, opts.timeout_ms))
+        } else {
+            None
+        };
+        Self {
+            deadline,
+            polls: 0,
+            max_repairs: opts.max_repairs,
+            repairs: 0,
+            max_elements: opts.max_elements,
+            max_key_len: opts.max_key_len,
+            truncate_keys: opts.long_keys == LongKeyPolicy::Truncate,
+            progress: opts.progress.clone().map(|callback| {
+                let step = (len / PROGRESS_REPORTS).max(1);
+                ProgressState {
+                    callback,
+                    total: len,
+                    step,
+                    next: step,
+                }
+            }),
+        }
+    }
+
+    /// Record progress at byte offset `pos` and fail once the deadline has passed.
+    #[inline]
+    pub(crate) fn poll(&mut self, pos: usize) -> Result<(), RepairError> {
+        if let Some(p) = self.progress.as_mut().filter(|p| pos >= p.next) {
+            p.callback.report(pos, p.total);
+            p.next = pos + p.step;
+        }
+        if let Some((at, ms)) = self.deadline {
+            self.polls += 1;
+            if self.polls >= CLOCK_CHECK_INTERVAL {
+                self.polls = 0;
+                if Instant::now() >= at {
+                    return Err(RepairError::new(RepairErrorKind::Timeout(ms), pos));
+                }
+            }
+        }
+        Ok(())
+    }
+
+    /// Count one repair applied at byte offset `pos` and fail once more than
+    /// `max_repairs` have been applied.
+    #[inline]
+    pub(crate) fn charge_repair(&mut self, pos: usize) -> Result<(), RepairError> {
+        self.repairs += 1;
+        CHARGED.with(|c| c.set(c.get() + 1));
+        if self.max_repairs > 0 && self.repairs > self.max_repairs {
+            return Err(RepairError::new(
+                RepairErrorKind::TooManyRepairs(self.max_repairs),
+                pos,
+            ));
+        }
+        Ok(())
+    }
+
+    /// Fail when a container reaches `count` members, the last one starting at byte offset
+    /// `pos`, and that is more than `max_elements`.
+    #[inline]
+    pub(crate) fn check_elements(&self, count: usize, pos: usize) -> Result<(), RepairError> {
+        if self.max_elements > 0 && count > self.max_elements {
+            return Err(RepairError::new(
+                RepairErrorKind::TooManyElements(self.max_elements),
+                pos,
+            ));
+        }
+        Ok(())
+    }
+
+    /// Check an object key starting at byte offset `pos` against `max_key_len`. Returns the
+    /// length to cut it to under `LongKeyPolicy::Truncate`, the last character boundary
+    /// within the limit, or `None` when the key fits.
+    #[inline]
+    pub(crate) fn check_key(&self, key: &str, pos: usize) -> Result<Option<usize>, RepairError> {
+        if self.max_key_len == 0 || key.len() <= self.max_key_len {
+            return Ok(None);
+        }
+        if !self.truncate_keys {
+            return Err(RepairError::new(
+                RepairErrorKind::KeyTooLong(self.max_key_len),
+                pos,
+            ));
+        }
+        let mut len = self.max_key_len;
+        while !key.is_char_boundary(len) {
+            len -= 1;
+        }
+        Ok(Some(len))
+    }
+}
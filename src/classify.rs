@@ -1,9 +1,11 @@
 #[inline]
 pub fn is_whitespace(c: char) -> bool {
-    // Include U+FEFF (BOM) as whitespace-equivalent so it can be skipped at root in streaming.
+    // Include U+FEFF (BOM) and zero-width characters as whitespace-equivalent so they can be
+    // skipped between tokens in streaming (matches `lex::zero_width_len`), and the stray
+    // vertical tab and form feed control characters.
     matches!(
         c,
-        '\u{0009}' | '\u{000A}' | '\u{000D}' | '\u{0020}' | '\u{FEFF}'
+        '\u{0009}'..='\u{000D}' | '\u{0020}' | '\u{FEFF}' | '\u{200B}'..='\u{200D}' | '\u{2060}'
     )
 }
 
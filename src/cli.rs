@@ -1,5 +1,6 @@
 use crate::{
-    LeadingZeroPolicy, Options, StreamRepairer, repair_to_string, repair_to_writer_streaming,
+    LeadingZeroPolicy, NegativeZeroPolicy, NumberSuffixPolicy, Options, OverflowPolicy,
+    SafeIntegerPolicy, StreamRepairer, Strictness, repair_to_string, repair_to_writer_streaming,
 };
 use std::env;
 use std::fs::{self, File};
@@ -20,11 +21,19 @@ fn print_help(program: &str) {
                --pretty              Pretty-print output (non-streaming path)\n\
                --ensure-ascii        Escape non-ASCII as \\uXXXX\n\
                --no-python-keywords  Disable Python True/False/None normalization\n\
+               --no-case-insensitive-keywords  Keep TRUE, FALSE, Null, NULL as strings\n\
                --no-undefined-null   Disable undefined -> null repair\n\
                --no-fence            Disable fenced code block stripping\n\
                --no-hash-comments    Disable # line comment tolerance\n\
+               --sql-comments        Treat -- as a line comment\n\
+               --keep-ellipsis       Keep bare ... placeholders as strings\n\
                --no-nonfinite-null   Disable NaN/Infinity -> null normalization\n\
                --leading-zero POLICY Keep|Quote (default Keep)\n\
+               --overflow POLICY     Keep|Quote|Null for numbers like 1e400 (default Keep)\n\
+               --number-suffix POLICY Keep|Quote|Strip for numbers like 30s (default Keep)\n\
+               --negative-zero MODE  Preserve|Normalize the sign of -0 (default Preserve)\n\
+               --safe-integers MODE  Passthrough|Clamp|Quote integers past 2^53-1\n\
+               --strictness MODE     Conservative|Aggressive: fail or nest {{a: b: c}}\n\
            -h, --help                Show this help\n",
         prog = program
     );
@@ -87,6 +96,9 @@ fn parse_args() -> (Options, CliMode) {
             "--no-python-keywords" => {
                 opts.allow_python_keywords = false;
             }
+            "--no-case-insensitive-keywords" => {
+                opts.case_insensitive_keywords = false;
+            }
             "--no-undefined-null" => {
                 opts.repair_undefined = false;
             }
@@ -96,6 +108,12 @@ fn parse_args() -> (Options, CliMode) {
             "--no-hash-comments" => {
                 opts.tolerate_hash_comments = false;
             }
+            "--sql-comments" => {
+                opts.tolerate_sql_comments = true;
+            }
+            "--keep-ellipsis" => {
+                opts.strip_ellipsis = false;
+            }
             "--no-nonfinite-null" => {
                 opts.normalize_js_nonfinite = false;
             }
@@ -114,6 +132,84 @@ fn parse_args() -> (Options, CliMode) {
                     }
                 }
             }
+            "--overflow" => {
+                i += 1;
+                if i >= args.len() {
+                    eprintln!("Missing POLICY for --overflow");
+                    std::process::exit(2);
+                }
+                match args[i].to_lowercase().as_str() {
+                    "keep" => opts.overflow = OverflowPolicy::Keep,
+                    "quote" => opts.overflow = OverflowPolicy::Quote,
+                    "null" => opts.overflow = OverflowPolicy::Null,
+                    other => {
+                        eprintln!("Unknown overflow policy: {}", other);
+                        std::process::exit(2);
+                    }
+                }
+            }
+            "--number-suffix" => {
+                i += 1;
+                if i >= args.len() {
+                    eprintln!("Missing POLICY for --number-suffix");
+                    std::process::exit(2);
+                }
+                match args[i].to_lowercase().as_str() {
+                    "keep" => opts.number_suffix = NumberSuffixPolicy::Keep,
+                    "quote" => opts.number_suffix = NumberSuffixPolicy::Quote,
+                    "strip" => opts.number_suffix = NumberSuffixPolicy::Strip,
+                    other => {
+                        eprintln!("Unknown number suffix policy: {}", other);
+                        std::process::exit(2);
+                    }
+                }
+            }
+            "--negative-zero" => {
+                i += 1;
+                if i >= args.len() {
+                    eprintln!("Missing MODE for --negative-zero");
+                    std::process::exit(2);
+                }
+                match args[i].to_lowercase().as_str() {
+                    "preserve" => opts.negative_zero = NegativeZeroPolicy::Preserve,
+                    "normalize" => opts.negative_zero = NegativeZeroPolicy::Normalize,
+                    other => {
+                        eprintln!("Unknown negative zero mode: {}", other);
+                        std::process::exit(2);
+                    }
+                }
+            }
+            "--safe-integers" => {
+                i += 1;
+                if i >= args.len() {
+                    eprintln!("Missing MODE for --safe-integers");
+                    std::process::exit(2);
+                }
+                match args[i].to_lowercase().as_str() {
+                    "passthrough" => opts.safe_integers = SafeIntegerPolicy::Passthrough,
+                    "clamp" => opts.safe_integers = SafeIntegerPolicy::Clamp,
+                    "quote" => opts.safe_integers = SafeIntegerPolicy::Quote,
+                    other => {
+                        eprintln!("Unknown safe integers mode: {}", other);
+                        std::process::exit(2);
+                    }
+                }
+            }
+            "--strictness" => {
+                i += 1;
+                if i >= args.len() {
+                    eprintln!("Missing MODE for --strictness");
+                    std::process::exit(2);
+                }
+                match args[i].to_lowercase().as_str() {
+                    "conservative" => opts.strictness = Strictness::Conservative,
+                    "aggressive" => opts.strictness = Strictness::Aggressive,
+                    other => {
+                        eprintln!("Unknown strictness mode: {}", other);
+                        std::process::exit(2);
+                    }
+                }
+            }
             "--compat" => {
                 i += 1;
                 if i >= args.len() {
@@ -140,6 +236,7 @@ fn parse_args() -> (Options, CliMode) {
                 opts.number_tolerance_trailing_dot = false;
                 opts.number_tolerance_incomplete_exponent = false;
                 opts.allow_python_keywords = false;
+                opts.case_insensitive_keywords = false;
                 opts.repair_undefined = false;
                 opts.normalize_js_nonfinite = false;
             }
@@ -0,0 +1,183 @@
+//! Comment extraction for `repair_with_comments`.
+//!
+//! `scan` finds the `//`, `/* */` and (with `tolerate_hash_comments`) `#` comments of the
+//! input, skipping quoted strings. The document is repaired with `annotate_source`, and
+//! `attach` strips those annotations again while recording the JSON Pointer of every value
+//! and the input offset it started at. Each comment then belongs to the value that starts
+//! before it on the same line (`"a": 1, // one` is about `/a`) or, failing that, to the
+//! next value after it (a comment on its own line describes what follows). Comments with no
+//! value after them go to the last value before them, and comments of the same value are
+//! joined with a newline.
+
+use crate::options::Options;
+
+/// A comment of the input: its byte range and its text without markers or outer spaces.
+struct Comment {
+    start: usize,
+    end: usize,
+    text: String,
+}
+
+/// Return `annotated` (repaired with `annotate_source`) without its annotations, and the
+/// comments of `input` keyed by the JSON Pointer of the value they belong to, in document
+/// order.
+pub(crate) fn attach(
+    input: &str,
+    annotated: &str,
+    opts: &Options,
+) -> (String, Vec<(String, String)>) {
+    let (out, mut values) = strip_annotations(annotated);
+    values.sort_by_key(|&(start, _)| start);
+    let mut found: Vec<(String, String)> = Vec::new();
+    for comment in scan(input, opts) {
+        let before = values.partition_point(|&(start, _)| start < comment.start);
+        let after = values.partition_point(|&(start, _)| start < comment.end);
+        let same_line = before > 0 && !input[values[before - 1].0..comment.start].contains('\n');
+        let owner = if same_line || after == values.len() {
+            before.checked_sub(1)
+        } else {
+            Some(after)
+        };
+        let pointer = owner.map_or("", |i| values[i].1.as_str());
+        match found.iter_mut().find(|(p, _)| p == pointer) {
+            Some((_, text)) => {
+                text.push('\n');
+                text.push_str(&comment.text);
+            }
+            None => found.push((pointer.to_string(), comment.text)),
+        }
+    }
+    (out, found)
+}
+
+// Split off the `/* @src:OFFSET */` annotations, returning the plain output and, for every
+// annotated value, its input offset and JSON Pointer.
+fn strip_annotations(annotated: &str) -> (String, Vec<(usize, String)>) {
+    const MARK: &str = "/* @src:";
+    let mut out = String::with_capacity(annotated.len());
+    let mut values = Vec::new();
+    let mut stack: Vec<Frame> = Vec::new();
+    let mut rest = annotated;
+    while let Some(c) = rest.chars().next() {
+        let mut len = c.len_utf8();
+        match c {
+            '"' => {
+                len = crate::json5::string_end(rest);
+                let lit = &rest[..len];
+                if let Some(Frame::Object(key)) = stack.last_mut()
+                    && rest[len..].trim_start().starts_with(':')
+                {
+                    *key = crate::strict::decode_string(lit).unwrap_or_default();
+                }
+                out.push_str(lit);
+            }
+            '/' if rest.starts_with(MARK) => {
+                len = rest.find("*/").map_or(rest.len(), |i| i + 2);
+                let offset = rest[MARK.len()..len].trim_end_matches("*/").trim();
+                if let Ok(offset) = offset.parse() {
+                    values.push((offset, pointer(&stack)));
+                }
+            }
+            '{' => {
+                stack.push(Frame::Object(String::new()));
+                out.push(c);
+            }
+            '[' => {
+                stack.push(Frame::Array(0));
+                out.push(c);
+            }
+            '}' | ']' => {
+                stack.pop();
+                out.push(c);
+            }
+            ',' => {
+                if let Some(Frame::Array(i)) = stack.last_mut() {
+                    *i += 1;
+                }
+                out.push(c);
+            }
+            _ => out.push(c),
+        }
+        rest = &rest[len..];
+    }
+    (out, values)
+}
+
+// An open container while walking the output: the current key or index.
+enum Frame {
+    Object(String),
+    Array(usize),
+}
+
+// The JSON Pointer of the current value of the innermost frame.
+fn pointer(stack: &[Frame]) -> String {
+    let mut s = String::new();
+    for frame in stack {
+        s.push('/');
+        match frame {
+            Frame::Object(key) => s.push_str(&key.replace('~', "~0").replace('/', "~1")),
+            Frame::Array(i) => s.push_str(&i.to_string()),
+        }
+    }
+    s
+}
+
+// The comments of `input` outside quoted strings. `//` right after `:` is taken as part of
+// a URL (`http://x`), not as a comment, and `'` only opens a string where a value can start.
+fn scan(input: &str, opts: &Options) -> Vec<Comment> {
+    let bytes = input.as_bytes();
+    let mut comments = Vec::new();
+    let mut i = 0;
+    while i < bytes.len() {
+        let start = i;
+        let (end, text) = match bytes[i] {
+            q @ (b'"' | b'\'') if q == b'"' || opens_value(&bytes[..i]) => {
+                i += 1;
+                while i < bytes.len() && bytes[i] != q {
+                    i += if bytes[i] == b'\\' { 2 } else { 1 };
+                }
+                i += 1;
+                continue;
+            }
+            b'/' if bytes.get(i + 1) == Some(&b'/') && (i == 0 || bytes[i - 1] != b':') => {
+                let end = line_end(bytes, i);
+                (end, &input[i + 2..end])
+            }
+            b'/' if bytes.get(i + 1) == Some(&b'*') => {
+                let close = input[i + 2..].find("*/").map(|p| i + 2 + p);
+                let end = close.map_or(input.len(), |p| p + 2);
+                (end, &input[i + 2..close.unwrap_or(input.len())])
+            }
+            b'#' if opts.tolerate_hash_comments => {
+                let end = line_end(bytes, i);
+                (end, &input[i + 1..end])
+            }
+            _ => {
+                i += 1;
+                continue;
+            }
+        };
+        comments.push(Comment {
+            start,
+            end,
+            text: text.trim().to_string(),
+        });
+        i = end;
+    }
+    comments
+}
+
+// Whether a `'` after `before` starts a string rather than sitting inside a bare word.
+fn opens_value(before: &[u8]) -> bool {
+    matches!(
+        before.iter().rev().find(|b| !b.is_ascii_whitespace()),
+        None | Some(b':' | b'[' | b'{' | b',' | b'(')
+    )
+}
+
+fn line_end(bytes: &[u8], from: usize) -> usize {
+    bytes[from..]
+        .iter()
+        .position(|&b| b == b'\n' || b == b'\r')
+        .map_or(bytes.len(), |p| from + p)
+}
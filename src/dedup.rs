@@ -0,0 +1,295 @@
+//! Duplicate-key collapsing over repaired output (`Options::dedup_position`), plus
+//! duplicate scalar removal in arrays (`Options::dedup_arrays`) and key sorting
+//! (`Options::sort_keys`).
+//!
+//! The repaired text is valid JSON, so objects are walked with the same byte-wise skipping
+//! as JSON Pointer lookup. Only containers that hold a duplicate or an out-of-order key
+//! (directly or below) are rebuilt; everything else is copied through untouched.
+
+use crate::options::DedupPosition;
+use crate::pointer::{skip_value, skip_ws};
+use std::borrow::Cow;
+use std::collections::{HashMap, HashSet};
+
+/// Collapse duplicate keys in every object of `json`, keeping the last value at the
+/// position chosen by `position`, drop repeated scalar array elements when `arrays` is set,
+/// and order the members of every object by key when `sort` is set. Whitespace between root
+/// values is preserved.
+pub(crate) fn dedup(json: &str, position: DedupPosition, arrays: bool, sort: bool) -> String {
+    if position == DedupPosition::KeepAll && !arrays && !sort {
+        return json.to_string();
+    }
+    let mut d = Dedup {
+        json,
+        b: json.as_bytes(),
+        position,
+        arrays,
+        sort,
+        built: Vec::new(),
+    };
+    let mut out = String::with_capacity(json.len());
+    let mut i = 0;
+    while i < json.len() {
+        let start = skip_ws(d.b, i);
+        out.push_str(&json[i..start]);
+        if start >= json.len() {
+            break;
+        }
+        let (end, value) = d.value(start);
+        d.write(value, &mut out);
+        d.built.clear();
+        // Guard against a stray byte the scanner cannot consume.
+        i = end.max(start + 1);
+    }
+    out
+}
+
+struct Dedup<'a> {
+    json: &'a str,
+    b: &'a [u8],
+    position: DedupPosition,
+    arrays: bool,
+    sort: bool,
+    // Rebuilt containers of the current root value, referred to by `Piece::Built`.
+    built: Vec<Built<'a>>,
+}
+
+/// A walked value: copied through as written, or a rebuilt container.
+#[derive(Clone, Copy)]
+enum Piece<'a> {
+    Raw(&'a str),
+    Built(usize),
+}
+
+/// A container that changed, as its surviving members in output order. Array elements have
+/// an empty key; object keys are kept quoted as written.
+struct Built<'a> {
+    object: bool,
+    members: Vec<(&'a str, Piece<'a>)>,
+}
+
+/// A container being walked. Nested containers are kept on an explicit stack, so nesting
+/// depth is bounded by memory only.
+struct Frame<'a> {
+    open: usize,
+    object: bool,
+    // Where the member value being walked starts, and its quoted key in an object.
+    at: usize,
+    key: &'a str,
+    // Surviving members; `None` marks an object member moved later.
+    members: Vec<Option<(&'a str, Piece<'a>)>>,
+    keys: HashMap<Cow<'a, str>, usize>,
+    scalars: HashSet<(bool, Cow<'a, str>)>,
+    changed: bool,
+}
+
+impl<'a> Frame<'a> {
+    fn new(open: usize, object: bool) -> Self {
+        Frame {
+            open,
+            object,
+            at: open,
+            key: "",
+            members: Vec::new(),
+            keys: HashMap::new(),
+            scalars: HashSet::new(),
+            changed: false,
+        }
+    }
+
+    /// Start the member at `i`, returning where its value starts, or `None` when the
+    /// container ends at `i`.
+    fn next_value(&mut self, json: &'a str, i: usize) -> Option<usize> {
+        let b = json.as_bytes();
+        if self.object {
+            if b.get(i) != Some(&b'"') {
+                return None;
+            }
+            let key_end = skip_value(b, i);
+            self.key = &json[i..key_end];
+            let colon = skip_ws(b, key_end);
+            self.at = skip_ws(b, colon + 1);
+        } else {
+            if b.get(i).is_none_or(|&c| c == b']') {
+                return None;
+            }
+            self.at = i;
+        }
+        Some(self.at)
+    }
+}
+
+impl<'a> Dedup<'a> {
+    /// End offset of the value at `start`, and the value as walked.
+    fn value(&mut self, start: usize) -> (usize, Piece<'a>) {
+        let b = self.b;
+        let mut stack: Vec<Frame<'a>> = Vec::new();
+        let mut at = start;
+        loop {
+            let (mut end, mut value) = match b.get(at) {
+                Some(&c @ (b'{' | b'[')) => {
+                    let mut frame = Frame::new(at, c == b'{');
+                    let i = skip_ws(b, at + 1);
+                    if let Some(next) = frame.next_value(self.json, i) {
+                        stack.push(frame);
+                        at = next;
+                        continue;
+                    }
+                    self.close(frame, i)
+                }
+                _ => {
+                    let end = skip_value(b, at);
+                    (end, Piece::Raw(&self.json[at..end]))
+                }
+            };
+            // Hand the value to its container, closing each container it was the last of.
+            loop {
+                let Some(frame) = stack.last_mut() else {
+                    return (end, value);
+                };
+                self.add(frame, end, value);
+                let mut i = skip_ws(b, end);
+                let next = if b.get(i) == Some(&b',') {
+                    i = skip_ws(b, i + 1);
+                    frame.next_value(self.json, i)
+                } else {
+                    None
+                };
+                if let Some(next) = next {
+                    at = next;
+                    break;
+                }
+                let frame = stack.pop().expect("frame on the stack");
+                (end, value) = self.close(frame, i);
+            }
+        }
+    }
+
+    /// Add the member value that ends at `end` to `frame`.
+    fn add(&self, frame: &mut Frame<'a>, end: usize, value: Piece<'a>) {
+        frame.changed |= matches!(value, Piece::Built(_));
+        if !frame.object {
+            let repeated = self.arrays
+                && scalar_key(&self.json[frame.at..end])
+                    .is_some_and(|key| !frame.scalars.insert(key));
+            if repeated {
+                frame.changed = true;
+            } else {
+                frame.members.push(Some(("", value)));
+            }
+            return;
+        }
+        let (raw, key) = (frame.key, decode_key(frame.key));
+        let earlier = frame
+            .keys
+            .get(&key)
+            .copied()
+            .filter(|_| self.position != DedupPosition::KeepAll);
+        match earlier {
+            Some(at) => {
+                frame.changed = true;
+                match self.position {
+                    DedupPosition::First => {
+                        if let Some(m) = frame.members[at].as_mut() {
+                            m.1 = value;
+                        }
+                    }
+                    _ => {
+                        frame.members[at] = None;
+                        frame.keys.insert(key, frame.members.len());
+                        frame.members.push(Some((raw, value)));
+                    }
+                }
+            }
+            None => {
+                frame.keys.insert(key, frame.members.len());
+                frame.members.push(Some((raw, value)));
+            }
+        }
+    }
+
+    /// Close `frame` at its closer at `i`: end offset and the container as walked.
+    fn close(&mut self, frame: Frame<'a>, i: usize) -> (usize, Piece<'a>) {
+        let end = (i + 1).min(self.b.len());
+        let mut changed = frame.changed;
+        let mut members: Vec<_> = frame.members.into_iter().flatten().collect();
+        // Keys compare by decoded text, in code point order; equal keys keep their order.
+        if frame.object
+            && self.sort
+            && !members.is_sorted_by(|a, b| decode_key(a.0) <= decode_key(b.0))
+        {
+            members.sort_by(|a, b| decode_key(a.0).cmp(&decode_key(b.0)));
+            changed = true;
+        }
+        if !changed {
+            return (end, Piece::Raw(&self.json[frame.open..end]));
+        }
+        self.built.push(Built {
+            object: frame.object,
+            members,
+        });
+        (end, Piece::Built(self.built.len() - 1))
+    }
+
+    /// Append `value` to `out`, walking rebuilt containers with an explicit stack.
+    fn write(&self, value: Piece<'a>, out: &mut String) {
+        // Rebuilt containers being written, with the index of their next member.
+        let mut stack: Vec<(&Built<'a>, usize)> = Vec::new();
+        let mut next = Some(value);
+        loop {
+            match next.take() {
+                Some(Piece::Raw(s)) => out.push_str(s),
+                Some(Piece::Built(n)) => {
+                    let built = &self.built[n];
+                    out.push(if built.object { '{' } else { '[' });
+                    stack.push((built, 0));
+                }
+                None => {}
+            }
+            let Some((built, k)) = stack.last_mut() else {
+                return;
+            };
+            match built.members.get(*k) {
+                Some(&(key, value)) => {
+                    if *k > 0 {
+                        out.push(',');
+                    }
+                    if built.object {
+                        out.push_str(key);
+                        out.push(':');
+                    }
+                    *k += 1;
+                    next = Some(value);
+                }
+                None => {
+                    out.push(if built.object { '}' } else { ']' });
+                    stack.pop();
+                }
+            }
+        }
+    }
+}
+
+// Equality key of a scalar array element, tagged with whether it is a string: strings
+// compare by decoded text, numbers by their `normalize_numbers` spelling and `true`, `false`
+// and `null` literally. Objects and arrays have no key and are always kept.
+fn scalar_key(raw: &str) -> Option<(bool, Cow<'_, str>)> {
+    match raw.as_bytes().first()? {
+        b'{' | b'[' => None,
+        b'"' => Some((true, decode_key(raw))),
+        b'-' | b'0'..=b'9' => Some((false, Cow::Owned(crate::parser::normalize_number(raw)))),
+        _ => Some((false, Cow::Borrowed(raw))),
+    }
+}
+
+// Keys compare by their decoded text; the common escape-free key is borrowed as-is.
+fn decode_key(raw: &str) -> Cow<'_, str> {
+    let inner = raw.get(1..raw.len().saturating_sub(1)).unwrap_or("");
+    if !inner.contains('\\') {
+        return Cow::Borrowed(inner);
+    }
+    match crate::strict::decode_string(raw) {
+        Some(k) => Cow::Owned(k),
+        None => Cow::Borrowed(inner),
+    }
+}
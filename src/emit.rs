@@ -5,6 +5,10 @@ pub type JRResult<T> = Result<T, RepairError>;
 
 pub trait Emitter {
     fn emit_str(&mut self, s: &str) -> JRResult<()>;
+    /// Bytes emitted so far, as a mark to `rewind` to.
+    fn mark(&self) -> usize;
+    /// Drop everything emitted after `mark`.
+    fn rewind(&mut self, mark: usize);
     fn emit_char(&mut self, c: char) -> JRResult<()> {
         let mut buf = [0u8; 4];
         let s = c.encode_utf8(&mut buf);
@@ -27,11 +31,22 @@ impl<'a> Emitter for StringEmitter<'a> {
         self.out.push_str(s);
         Ok(())
     }
+    fn mark(&self) -> usize {
+        self.out.len()
+    }
+    fn rewind(&mut self, mark: usize) {
+        self.out.truncate(mark);
+    }
 }
 
 pub struct WriterEmitter<'a, W: Write> {
     pub w: &'a mut W,
     buf: Vec<u8>,
+    /// Write the buffer out once it holds this many bytes; 0 keeps everything until
+    /// `flush_all`. Output already written cannot be rewound over.
+    chunk: usize,
+    /// Bytes already written to `w`.
+    flushed: usize,
 }
 
 impl<'a, W: Write> WriterEmitter<'a, W> {
@@ -39,6 +54,17 @@ impl<'a, W: Write> WriterEmitter<'a, W> {
         Self {
             w,
             buf: Vec::with_capacity(cap),
+            chunk: 0,
+            flushed: 0,
+        }
+    }
+    /// An emitter that writes to `w` in pieces of about `chunk` bytes as output is produced.
+    pub fn chunked(w: &'a mut W, chunk: usize) -> Self {
+        Self {
+            w,
+            buf: Vec::with_capacity(chunk + 64),
+            chunk,
+            flushed: 0,
         }
     }
     pub fn flush_all(&mut self) -> JRResult<()> {
@@ -46,6 +72,7 @@ impl<'a, W: Write> WriterEmitter<'a, W> {
             self.w.write_all(&self.buf).map_err(|e| {
                 RepairError::new(RepairErrorKind::Parse(format!("io write error: {}", e)), 0)
             })?;
+            self.flushed += self.buf.len();
             self.buf.clear();
         }
         Ok(())
@@ -55,6 +82,15 @@ impl<'a, W: Write> WriterEmitter<'a, W> {
 impl<'a, W: Write> Emitter for WriterEmitter<'a, W> {
     fn emit_str(&mut self, s: &str) -> JRResult<()> {
         self.buf.extend_from_slice(s.as_bytes());
+        if self.chunk > 0 && self.buf.len() >= self.chunk {
+            self.flush_all()?;
+        }
         Ok(())
     }
+    fn mark(&self) -> usize {
+        self.flushed + self.buf.len()
+    }
+    fn rewind(&mut self, mark: usize) {
+        self.buf.truncate(mark.saturating_sub(self.flushed));
+    }
 }
@@ -1,5 +1,8 @@
+use crate::budget::Budget;
 use crate::error::{RepairError, RepairErrorKind};
-use crate::options::Options;
+use crate::options::{
+    NegativeZeroPolicy, NumberSuffixPolicy, Options, SafeIntegerPolicy, UnwrapMode,
+};
 mod scanner_bytes;
 use std::io::Write;
 
@@ -11,22 +14,23 @@ pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<Strin
     // Fast path: valid JSON as-is
     #[cfg(feature = "serde")]
     {
-        if !opts.ensure_ascii && opts.assume_valid_json_fastpath {
+        if !opts.ascii_keys() && !opts.ascii_values() && opts.assume_valid_json_fastpath {
             return Ok(input.to_string());
         }
-        if let Ok(val) = serde_json::from_str::<serde_json::Value>(input) {
-            if !opts.ensure_ascii {
+        if let Some(val) = serde_json::from_str::<serde_json::Value>(input)
+            .ok()
+            .filter(|_| {
+                !opts.trim_keys
+                    && opts.max_key_len == 0
+                    && opts.negative_zero == NegativeZeroPolicy::Preserve
+                    && opts.safe_integers == SafeIntegerPolicy::Passthrough
+            })
+        {
+            if !opts.ascii_keys() && !opts.ascii_values() {
                 return Ok(serde_json::to_string(&val)
                     .map_err(|e| RepairError::from_serde("serialize", e))?);
             } else {
-                use serde::Serialize;
-                let mut buf: Vec<u8> = Vec::with_capacity(input.len());
-                let mut ser = serde_json::Serializer::with_formatter(&mut buf, AsciiEscaper);
-                val.serialize(&mut ser)
-                    .map_err(|e| RepairError::from_serde("serialize", e))?;
-                return String::from_utf8(buf).map_err(|e| {
-                    RepairError::new(RepairErrorKind::Parse(format!("utf8 error: {}", e)), 0)
-                });
+                return Ok(crate::parser::escape_valid_json_ascii(input, opts));
             }
         }
     }
@@ -45,7 +49,7 @@ pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<Strin
                 }
                 let mut inner = *b;
                 // JSONP unwrap on body level (best-effort)
-                while let Some(inner2) = trim_jsonp(inner) {
+                while let Some(inner2) = trim_jsonp(inner, opts) {
                     inner = inner2;
                 }
                 let mut p = LlmCompatParser::new(inner, opts);
@@ -61,7 +65,7 @@ pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<Strin
             return Ok(out);
         } else if bodies.len() == 1 {
             let mut inner = bodies[0];
-            while let Some(inner2) = trim_jsonp(inner) {
+            while let Some(inner2) = trim_jsonp(inner, opts) {
                 inner = inner2;
             }
             let mut p = LlmCompatParser::new(inner, opts);
@@ -77,7 +81,7 @@ pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<Strin
 
     // Normal path: after BOM removal with optional JSONP wrapper
     let mut s = sfull;
-    while let Some(inner) = trim_jsonp(s) {
+    while let Some(inner) = trim_jsonp(s, opts) {
         s = inner;
     }
 
@@ -155,7 +159,9 @@ fn collect_fenced_bodies(s: &str) -> Vec<&str> {
     bodies
 }
 
-fn trim_jsonp(s: &str) -> Option<&str> {
+// A registered wrapper (`NumberLong("42")`, see `Options::unwrap_functions`) is not a
+// callback: it is left for the parser to unwrap as at any other position.
+fn trim_jsonp<'a>(s: &'a str, opts: &Options) -> Option<&'a str> {
     let rest = s.trim_start();
     // Detect `<ident>( ... )[;]`
     let mut chars = rest.chars();
@@ -172,6 +178,9 @@ fn trim_jsonp(s: &str) -> Option<&str> {
             break;
         }
     }
+    if opts.unwrap_mode(&rest[..i]).is_some() {
+        return None;
+    }
     let after_ident = rest[i..].trim_start();
     if !after_ident.starts_with('(') {
         return None;
@@ -193,6 +202,31 @@ struct LlmCompatParser<'a> {
     ensure_ascii: bool,
     _opts: &'a Options,
     char_to_byte: Vec<usize>,
+    budget: Budget,
+    // 正在解析的容器的闭合符（`]` 或 `}`），最内层在末尾
+    open: Vec<u8>,
+    // 正在展开的类型包装层数，每层占一层调用栈
+    calls: usize,
+}
+
+// `parse_containers` 栈上一个正在解析的容器
+enum Frame {
+    Object(ObjectFrame),
+    Array(ArrayFrame),
+}
+
+#[derive(Default)]
+struct ObjectFrame {
+    need_comma: bool,
+    members: usize,
+    // 键位置上多余的 `{`（`{{"a":1}}`）：成员并入当前对象，对应的 `}` 丢弃
+    doubled: usize,
+}
+
+#[derive(Default)]
+struct ArrayFrame {
+    need_comma: bool,
+    elements: usize,
 }
 
 #[derive(Copy, Clone, Eq, PartialEq)]
@@ -214,9 +248,12 @@ impl<'a> LlmCompatParser<'a> {
             input: s.chars().collect(),
             pos: 0,
             out: String::with_capacity(s.len().saturating_add(8)),
-            ensure_ascii: opts.ensure_ascii,
+            ensure_ascii: opts.ascii_values(),
             _opts: opts,
             char_to_byte,
+            budget: Budget::from_options(opts, s.len()),
+            open: Vec::new(),
+            calls: 0,
         }
     }
 
@@ -268,6 +305,19 @@ impl<'a> LlmCompatParser<'a> {
                     continue;
                 }
             }
+            // SQL 风格注释 `-- ...`（`tolerate_sql_comments`）
+            if self._opts.tolerate_sql_comments
+                && crate::parser::lex::starts_sql_comment(&self.orig[self.char_to_byte[self.pos]..])
+            {
+                self.pos += 2;
+                while let Some(ch) = self.current() {
+                    self.pos += 1;
+                    if ch == '\n' || ch == '\r' {
+                        break;
+                    }
+                }
+                continue;
+            }
             // optional hash comments
             if self.current() == Some('#') {
                 self.pos += 1;
@@ -283,6 +333,17 @@ impl<'a> LlmCompatParser<'a> {
         }
     }
 
+    // 跳过裸 `...` 占位符（`strip_ellipsis`），展开语法 `...rest` 不算
+    fn skip_ellipsis(&mut self) -> bool {
+        if self._opts.strip_ellipsis
+            && crate::parser::lex::is_bare_ellipsis(&self.orig[self.char_to_byte[self.pos]..])
+        {
+            self.pos += 3;
+            return true;
+        }
+        false
+    }
+
     fn parse(&mut self) -> Result<(), RepairError> {
         // Best-effort: skip non-JSON preface until likely start
         while let Some(ch) = self.current() {
@@ -300,15 +361,34 @@ impl<'a> LlmCompatParser<'a> {
     }
 
     fn parse_value(&mut self, ctx: Ctx) -> Result<(), RepairError> {
+        self.start_value()?;
+        if matches!(self.current(), Some('{' | '[')) {
+            return self.parse_containers();
+        }
+        self.parse_scalar(ctx)
+    }
+
+    // 值之前：检查预算并跳过空白与注释
+    fn start_value(&mut self) -> Result<(), RepairError> {
+        self.budget.poll(self.char_to_byte[self.pos])?;
         self.skip_ws();
         self.skip_comments();
         self.skip_ws();
+        Ok(())
+    }
+
+    // 容器以外的值，`pos` 已在其首字符上
+    fn parse_scalar(&mut self, ctx: Ctx) -> Result<(), RepairError> {
         match self.current() {
-            Some('{') => self.parse_object(),
-            Some('[') => self.parse_array(),
-            Some('"') | Some('\'') => self.parse_string_concat(),
+            Some('"') | Some('\'') => self.parse_string_concat(self._opts.concat_adjacent_strings),
             Some('/') => self.parse_regex_literal(),
             Some(c) if c == '-' || c.is_ascii_digit() => self.parse_number(),
+            Some('+')
+                if self._opts.normalize_js_nonfinite && self.take_nonfinite_word().is_some() =>
+            {
+                self.out.push_str("null");
+                Ok(())
+            }
             Some('.') if self._opts.number_tolerance_leading_dot => self.parse_number(),
             Some(c) if is_ident_start(c) => self.parse_ident_or_literal(ctx),
             Some(c) => {
@@ -322,23 +402,81 @@ impl<'a> LlmCompatParser<'a> {
                     self.parse_unquoted_string()
                 }
             }
+            None if ctx == Ctx::Object => {
+                // 截断在键之后（`{"a":`）：与缺失值一样补空字符串
+                self.out.push_str("\"\"");
+                Ok(())
+            }
             None => Ok(()),
         }
     }
 
-    fn parse_object(&mut self) -> Result<(), RepairError> {
-        self.out.push('{');
-        self.pos += 1; // skip '{'
+    // 解析 `pos` 处的对象或数组。嵌套容器放在显式的栈上而不是递归调用，
+    // 因此嵌套深度只受内存限制
+    fn parse_containers(&mut self) -> Result<(), RepairError> {
+        let depth = self.open.len();
+        let mut stack = vec![self.open_container()];
+        while let Some(frame) = stack.last_mut() {
+            let descend = match frame {
+                Frame::Object(frame) => self.object_step(frame),
+                Frame::Array(frame) => self.array_step(frame),
+            };
+            match descend {
+                Ok(true) => {
+                    let frame = self.open_container();
+                    stack.push(frame);
+                }
+                Ok(false) => {
+                    stack.pop();
+                    self.open.pop();
+                }
+                Err(err) => {
+                    self.open.truncate(depth);
+                    return Err(err);
+                }
+            }
+        }
+        Ok(())
+    }
+
+    // 消费 `pos` 处的 `{` 或 `[` 并返回其状态
+    fn open_container(&mut self) -> Frame {
+        let object = self.current() == Some('{');
+        self.pos += 1;
+        if object {
+            self.open.push(b'}');
+            self.out.push('{');
+            Frame::Object(ObjectFrame::default())
+        } else {
+            self.open.push(b']');
+            self.out.push('[');
+            Frame::Array(ArrayFrame::default())
+        }
+    }
+
+    // 错位的闭合符：属于外层容器时不消费（当前容器视为未闭合），
+    // 属于当前容器（交叉写错，如 `{"a": [1, 2}, "b": 3]`）时消费
+    fn skip_crossed_closer(&mut self) {
+        let rest = &self.orig[self.char_to_byte[self.pos]..];
+        if crate::parser::is_crossed_closer(&self.open, rest) {
+            self.pos += 1;
+        }
+    }
 
+    // 解析对象成员，直到对象闭合（返回 false）或某个值是嵌套容器（返回 true，
+    // `pos` 停在其开头）
+    fn object_step(&mut self, frame: &mut ObjectFrame) -> Result<bool, RepairError> {
         // Semantics: `expecting_key = true` means the next token should be a key
         let mut expecting_key = true;
-        let mut need_comma = false;
 
         loop {
             let checkpoint = self.pos;
             self.skip_ws();
             self.skip_comments();
             self.skip_ws();
+            if self.skip_ellipsis() {
+                continue;
+            }
 
             match self.current() {
                 None => {
@@ -346,11 +484,26 @@ impl<'a> LlmCompatParser<'a> {
                     self.out.push('}');
                     break;
                 }
+                Some('}') if frame.doubled > 0 => {
+                    self.pos += 1;
+                    frame.doubled -= 1;
+                    continue;
+                }
                 Some('}') => {
                     self.pos += 1;
                     self.out.push('}');
                     break;
                 }
+                Some(']') => {
+                    self.skip_crossed_closer();
+                    self.out.push('}');
+                    break;
+                }
+                Some('{') => {
+                    self.pos += 1;
+                    frame.doubled += 1;
+                    continue;
+                }
                 Some(',') => {
                     // Redundant comma: consume and continue; still expecting a key
                     self.pos += 1;
@@ -359,18 +512,31 @@ impl<'a> LlmCompatParser<'a> {
                 }
                 _ => {
                     // If a previous key:value has just finished but no comma present, synthesize one
-                    if need_comma {
+                    if frame.need_comma {
                         self.out.push(',');
                     }
+                    frame.members += 1;
+                    self.budget
+                        .check_elements(frame.members, self.char_to_byte[self.pos])?;
                     // Read key
                     if !expecting_key {
                         // 漏掉了逗号或状态错位，恢复到读取 key 的状态
                         expecting_key = true;
                     }
+                    let key_start = self.out.len();
+                    let key_at = self.char_to_byte[self.pos];
+                    self.ensure_ascii = self._opts.ascii_keys();
                     match self.current() {
-                        Some('"') | Some('\'') => self.parse_string_concat()?,
+                        Some('"') | Some('\'') => self.parse_string_concat(false)?,
                         _ => self.parse_unquoted_key()?,
                     }
+                    self.ensure_ascii = self._opts.ascii_values();
+                    if self._opts.trim_keys {
+                        self.trim_key_from(key_start);
+                    }
+                    if self._opts.max_key_len > 0 {
+                        self.check_key_from(key_start, key_at)?;
+                    }
                     // Colon: optional; synthesize when missing
                     self.skip_ws();
                     if self.current() == Some(':') {
@@ -379,11 +545,15 @@ impl<'a> LlmCompatParser<'a> {
                     } else {
                         self.out.push(':');
                     }
-                    // Read value
-                    self.parse_value(Ctx::Object)?;
-                    // Completed a key:value pair; next should be `,` or `}`. If a key starts directly, we add a comma.
+                    // Read value. Completed a key:value pair; next should be `,` or `}`. If a key
+                    // starts directly, we add a comma.
+                    self.start_value()?;
                     expecting_key = true;
-                    need_comma = true;
+                    frame.need_comma = true;
+                    if matches!(self.current(), Some('{' | '[')) {
+                        return Ok(true);
+                    }
+                    self.parse_scalar(Ctx::Object)?;
                 }
             }
 
@@ -393,12 +563,55 @@ impl<'a> LlmCompatParser<'a> {
             }
         }
 
+        Ok(false)
+    }
+
+    // Trim whitespace inside the key string emitted at `out[start..]`.
+    fn trim_key_from(&mut self, start: usize) {
+        let key = &self.out[start..];
+        if let Some(inner) = key.strip_prefix('"').and_then(|k| k.strip_suffix('"')) {
+            let trimmed = inner.trim();
+            if trimmed.len() != inner.len() {
+                let trimmed = format!("\"{}\"", trimmed);
+                self.out.truncate(start);
+                self.out.push_str(&trimmed);
+            }
+        }
+    }
+
+    // `max_key_len`: fail on, or cut down, the key string emitted at `out[start..]`, which
+    // started at byte offset `at` of the input.
+    fn check_key_from(&mut self, start: usize, at: usize) -> Result<(), RepairError> {
+        let Some(key) = crate::strict::decode_string(&self.out[start..]) else {
+            return Ok(());
+        };
+        if let Some(len) = self.budget.check_key(&key, at)? {
+            self.out.truncate(start);
+            let mut out = crate::emit::StringEmitter::new(&mut self.out);
+            crate::parser::emit_json_string_from_lit(
+                &mut out,
+                &key[..len],
+                self._opts.ascii_keys(),
+            )?;
+        }
         Ok(())
     }
 
     fn parse_unquoted_key(&mut self) -> Result<(), RepairError> {
         // For object keys: stop at whitespace or structural delimiters to avoid swallowing the value
         self.out.push('"');
+        // URL 形式的 key（`{http://x: 1}`）：分隔符取 `://` 之后最后一个冒号
+        if let Some(end) = self.url_key_end() {
+            while self.pos < end {
+                match self.input[self.pos] {
+                    '\\' => self.out.push_str("\\\\"),
+                    ch => self.append_char(ch),
+                }
+                self.pos += 1;
+            }
+            self.out.push('"');
+            return Ok(());
+        }
         // fast path: copy initial ascii run
         self.copy_ascii_key_run();
         while let Some(ch) = self.current() {
@@ -425,15 +638,44 @@ impl<'a> LlmCompatParser<'a> {
         Ok(())
     }
 
-    fn parse_array(&mut self) -> Result<(), RepairError> {
-        self.out.push('[');
-        self.pos += 1; // skip '['
+    // key 以 `ident://` 开头时返回其结束位置：到空白或 `, { } [ ] " '` 为止，
+    // 其中不属于 `//` 的最后一个冒号之前；没有这样的冒号则取整段。
+    fn url_key_end(&self) -> Option<usize> {
+        let mut i = self.pos;
+        while i < self.input.len()
+            && (self.input[i].is_ascii_alphanumeric() || self.input[i] == '_')
+        {
+            i += 1;
+        }
+        if i == self.pos
+            || !self.input[self.pos].is_ascii_alphabetic()
+            || self.input.get(i..i + 3) != Some(&[':', '/', '/'][..])
+        {
+            return None;
+        }
+        let start = i + 3;
+        let mut end = start;
+        while let Some(&ch) = self.input.get(end) {
+            if ch.is_whitespace() || matches!(ch, ',' | '{' | '}' | '[' | ']' | '"' | '\'') {
+                break;
+            }
+            end += 1;
+        }
+        let sep = (start..end).rev().find(|&j| {
+            self.input[j] == ':' && self.input.get(j + 1..j + 3) != Some(&['/', '/'][..])
+        });
+        Some(sep.unwrap_or(end))
+    }
 
-        let mut need_comma = false;
+    // 解析数组元素，直到数组闭合（返回 false）或某个元素是嵌套容器（返回 true）
+    fn array_step(&mut self, frame: &mut ArrayFrame) -> Result<bool, RepairError> {
         loop {
             self.skip_ws();
             self.skip_comments();
             self.skip_ws();
+            if self.skip_ellipsis() {
+                continue;
+            }
             match self.current() {
                 None => {
                     self.out.push(']');
@@ -444,6 +686,11 @@ impl<'a> LlmCompatParser<'a> {
                     self.out.push(']');
                     break;
                 }
+                Some('}') => {
+                    self.skip_crossed_closer();
+                    self.out.push(']');
+                    break;
+                }
                 Some(',') => {
                     // skip redundant commas
                     self.pos += 1;
@@ -452,36 +699,97 @@ impl<'a> LlmCompatParser<'a> {
                         continue;
                     }
                 }
+                Some(':') => {
+                    // `[a: 1]`：数组中没有键，丢弃多余的冒号
+                    self.pos += 1;
+                }
                 _ => {
-                    if need_comma {
+                    if frame.need_comma {
                         self.out.push(',');
                     }
-                    self.parse_value(Ctx::Array)?;
-                    need_comma = true;
+                    frame.elements += 1;
+                    self.budget
+                        .check_elements(frame.elements, self.char_to_byte[self.pos])?;
+                    self.start_value()?;
+                    frame.need_comma = true;
+                    if matches!(self.current(), Some('{' | '[')) {
+                        return Ok(true);
+                    }
+                    self.parse_scalar(Ctx::Array)?;
                 }
             }
         }
-        Ok(())
+        Ok(false)
     }
 
-    fn parse_string_concat(&mut self) -> Result<(), RepairError> {
-        // 解析一个或多个通过 `+` 相连的字符串字面量，合并成一个 JSON 字符串
+    fn parse_string_concat(&mut self, join_adjacent: bool) -> Result<(), RepairError> {
+        // 解析一个或多个通过 `+` 相连的字符串字面量，合并成一个 JSON 字符串；
+        // `join_adjacent` 时仅以空白分隔的相邻字符串也合并（`concat_adjacent_strings`）
         let mut buf = String::new();
 
         // 内部：读取单个字符串段，结果追加到 buf
         let mut read_segment = |this: &mut Self| {
+            let rest = &this.orig[this.char_to_byte[this.pos]..];
+            if let Some((body, _)) = crate::parser::doubled_quote_body(rest) {
+                // `""a""`: 只保留正文
+                for ch in body.chars() {
+                    this.append_char_to(&mut buf, ch);
+                }
+                this.pos += body.chars().count() + 4;
+                return;
+            }
             let quote = this.current().unwrap_or('"');
             if quote == '"' || quote == '\'' {
                 this.pos += 1; // skip opening
             }
             let mut esc = false;
+            let mut escaped_own_quote = false;
             while let Some(ch) = this.current() {
                 this.pos += 1;
                 if esc {
+                    esc = false;
+                    escaped_own_quote |= ch == quote;
+                    if ch == '\'' {
+                        // `\'` 在 JSON 中不是合法转义，只保留引号
+                        buf.push('\'');
+                        continue;
+                    }
+                    // ES6 `\u{1F600}`：改写为 `\uXXXX`，星际平面字符写成代理对
+                    // 超出范围或代理项（`\u{110000}`、`\u{D800}`）写成 U+FFFD；
+                    // 空的、未闭合的或非十六进制的（`\u{}`、`\u{1F600`、`\u{zz}`）原样保留
+                    if ch == 'u' {
+                        let rest = &this.orig[this.char_to_byte[this.pos]..];
+                        if let Some((c, len)) = crate::parser::brace_escape(rest) {
+                            this.pos += len;
+                            use std::fmt::Write as _;
+                            let mut units = [0u16; 2];
+                            for unit in c.encode_utf16(&mut units) {
+                                let _ = write!(buf, "\\u{:04X}", unit);
+                            }
+                            continue;
+                        }
+                        if rest.starts_with('{') {
+                            buf.push_str("\\\\u");
+                            continue;
+                        }
+                    }
+                    // 续行（反斜杠+换行）：合并两行，或按 `Keep` 保留为 `\n`（CRLF 为 `\r\n`）
+                    if ch == '\n' || ch == '\r' {
+                        let crlf = ch == '\r' && this.current() == Some('\n');
+                        if crlf {
+                            this.pos += 1;
+                        }
+                        if this._opts.line_continuations == crate::options::LineContinuation::Keep {
+                            buf.push_str(if ch == '\n' { "\\n" } else { "\\r" });
+                            if crlf {
+                                buf.push_str("\\n");
+                            }
+                        }
+                        continue;
+                    }
                     // 保留转义：以反斜杠+原样字符形式写出
                     buf.push('\\');
                     buf.push(ch);
-                    esc = false;
                     continue;
                 }
                 if ch == '\\' {
@@ -491,13 +799,52 @@ impl<'a> LlmCompatParser<'a> {
                 if ch == quote {
                     break;
                 }
+                // 混用引号（`"he said \"hi'}`）：见过同类转义引号后，另一种引号后接分隔符即结束
+                if escaped_own_quote
+                    && matches!(ch, '"' | '\'')
+                    && crate::parser::mixed_quote_closes(
+                        &this.orig[this.char_to_byte[this.pos]..],
+                        quote,
+                    )
+                {
+                    break;
+                }
                 if quote == '\'' && ch == '"' {
                     // 单引号字符串内的双引号需要 JSON 逃逸
                     buf.push_str("\\\"");
                     continue;
                 }
-                // fast path: ascii run until special/quote (use byte-level scanner)
-                if ch.is_ascii() && ch != '"' && ch != '\\' && ch != quote {
+                if ch == '\n'
+                    && crate::parser::starts_member(
+                        &this.orig[this.char_to_byte[this.pos]..],
+                        quote,
+                    )
+                {
+                    // 行尾缺少闭合引号，下一行是新的成员：在换行处结束字符串
+                    this.pos -= 1;
+                    if buf.ends_with("\\r") {
+                        buf.truncate(buf.len() - 2);
+                    }
+                    break;
+                }
+                if ch < ' ' {
+                    // 原始控制字符（换行等）必须转义
+                    match ch {
+                        '\n' => buf.push_str("\\n"),
+                        '\r' => buf.push_str("\\r"),
+                        '\t' => buf.push_str("\\t"),
+                        '\u{08}' => buf.push_str("\\b"),
+                        '\u{0C}' => buf.push_str("\\f"),
+                        _ => {
+                            use std::fmt::Write as _;
+                            let _ = write!(buf, "\\u{:04X}", ch as u32);
+                        }
+                    }
+                    continue;
+                }
+                // fast path: ascii run until special/quote (use byte-level scanner);
+                // 见过转义引号后逐字符处理，以便识别混用的结束引号
+                if ch.is_ascii() && ch != '"' && ch != '\\' && ch != quote && !escaped_own_quote {
                     buf.push(ch);
                     let bstart = this.char_to_byte[this.pos];
                     let bytes = this.orig.as_bytes();
@@ -524,6 +871,12 @@ impl<'a> LlmCompatParser<'a> {
             self.skip_ws();
             self.skip_comments();
             self.skip_ws();
+            if join_adjacent
+                && crate::parser::joins_previous_string(&self.orig[self.char_to_byte[self.pos]..])
+            {
+                read_segment(self);
+                continue;
+            }
             if self.current() != Some('+') {
                 break;
             }
@@ -555,6 +908,16 @@ impl<'a> LlmCompatParser<'a> {
         self.copy_ascii_symbol_run();
         while let Some(ch) = self.current() {
             match ch {
+                // 短语中的 URL（`see http://x.y/z now`）：`://` 及其后到空白或 `, ] } " '` 为止整段保留
+                ':' if self.input.get(self.pos + 1..self.pos + 3) == Some(&['/', '/'][..]) => {
+                    while let Some(c) = self.current() {
+                        if c.is_whitespace() || matches!(c, ',' | ']' | '}' | '"' | '\'' | '\\') {
+                            break;
+                        }
+                        self.append_char(c);
+                        self.pos += 1;
+                    }
+                }
                 ',' | '}' | ']' | ':' => break,
                 '"' => {
                     self.out.push_str("\\\"");
@@ -581,7 +944,15 @@ impl<'a> LlmCompatParser<'a> {
                     if found {
                         break;
                     }
-                    self.append_char(ch);
+                    // 词间的制表符、换行等控制字符需转义，否则输出不是合法 JSON
+                    match ch {
+                        '\t' => self.out.push_str("\\t"),
+                        '\n' => self.out.push_str("\\n"),
+                        '\r' => self.out.push_str("\\r"),
+                        '\u{0C}' => self.out.push_str("\\f"),
+                        '\u{0B}' => self.out.push_str("\\u000B"),
+                        _ => self.append_char(ch),
+                    }
                     self.pos += 1;
                 }
                 _ => {
@@ -672,6 +1043,15 @@ impl<'a> LlmCompatParser<'a> {
             if ch.is_whitespace() || matches!(ch, ',' | '}' | ']' | ')' | '(' | ':') {
                 break;
             }
+            // 完整数字后紧贴的 `"` 是缺逗号时下一个键或元素的开头（`{"a":1"b":2}`）；
+            // 其他情况（`1+"x"`）仍属于同一片段
+            if ch == '"'
+                && crate::parser::is_json_number(
+                    &self.input[start..end_seg].iter().collect::<String>(),
+                )
+            {
+                break;
+            }
             // 注释起始
             if ch == '/' {
                 if end_seg + 1 < self.input.len() {
@@ -683,6 +1063,40 @@ impl<'a> LlmCompatParser<'a> {
             }
             end_seg += 1;
         }
+        // 符号与指数之间没有尾数（`-E5`、`-.e3`）：值为 0；不容忍不完整指数时整体当作字符串
+        if self.input[start] == '-' {
+            let seg: String = self.input[start..end_seg].iter().collect();
+            if crate::parser::is_bare_exponent(&seg) {
+                self.pos = end_seg;
+                if opts.number_tolerance_incomplete_exponent {
+                    self.out.push('0');
+                } else {
+                    self.out.push('"');
+                    for ch in seg.chars() {
+                        self.append_char(ch);
+                    }
+                    self.out.push('"');
+                }
+                return Ok(());
+            }
+        }
+        // 带单位后缀的数字（`30s`、`10MB`、`100%`）：按 `number_suffix` 整体加引号或只保留数字
+        if opts.number_suffix != NumberSuffixPolicy::Keep {
+            let seg: String = self.input[start..end_seg].iter().collect();
+            if let Some((num, _)) = crate::parser::split_unit_suffix(&seg) {
+                if opts.number_suffix == NumberSuffixPolicy::Strip {
+                    self.push_number(num);
+                } else {
+                    self.out.push('"');
+                    for ch in seg.chars() {
+                        self.append_char(ch);
+                    }
+                    self.out.push('"');
+                }
+                self.pos = end_seg;
+                return Ok(());
+            }
+        }
         // 解析合法的数值前缀，按容错策略调整
         let mut i = start;
         let mut buf = String::new();
@@ -924,27 +1338,65 @@ impl<'a> LlmCompatParser<'a> {
         if started_with_dot && opts.number_tolerance_leading_dot {
             if buf.starts_with("-.") {
                 let mut fixed = String::from("-0");
-                fixed.push_str(&buf[2..]);
-                self.out.push_str(&fixed);
+                fixed.push_str(&buf[1..]);
+                self.push_number(&fixed);
                 return Ok(());
             } else if buf.starts_with('.') {
                 let mut fixed = String::from("0");
-                fixed.push_str(&buf[1..]);
-                self.out.push_str(&fixed);
+                fixed.push_str(&buf);
+                self.push_number(&fixed);
                 return Ok(());
             }
         }
 
-        // Trailing dot tolerance
+        // Trailing dot tolerance; before an exponent the dot is dropped instead (`1.E3` → `1e3`)
         if ends_with_dot && opts.number_tolerance_trailing_dot {
-            buf.push('0');
+            match buf.find('e') {
+                Some(e) => {
+                    buf.remove(e - 1);
+                }
+                None => buf.push('0'),
+            }
         }
 
         // 正常输出数字 token
-        self.out.push_str(&buf);
+        self.push_number(&buf);
         Ok(())
     }
 
+    // 输出数字 token；超出 JS 安全整数范围时按 `safe_integers` 截断或加引号，
+    // 超出 f64 范围（`1e400`）时按 `overflow` 策略加引号或替换为 null
+    fn push_number(&mut self, tok: &str) {
+        use crate::options::OverflowPolicy;
+        match self._opts.safe_integers {
+            SafeIntegerPolicy::Clamp if crate::parser::is_unsafe_integer(tok) => {
+                self.out.push_str(crate::parser::clamp_integer(tok));
+                return;
+            }
+            SafeIntegerPolicy::Quote if crate::parser::is_unsafe_integer(tok) => {
+                self.out.push('"');
+                self.out.push_str(tok);
+                self.out.push('"');
+                return;
+            }
+            _ => {}
+        }
+        match self._opts.overflow {
+            OverflowPolicy::Quote if crate::parser::overflows_f64(tok) => {
+                self.out.push('"');
+                self.out.push_str(tok);
+                self.out.push('"');
+            }
+            OverflowPolicy::Null if crate::parser::overflows_f64(tok) => self.out.push_str("null"),
+            _ if self._opts.negative_zero == NegativeZeroPolicy::Normalize
+                && crate::parser::is_negative_zero(tok) =>
+            {
+                self.out.push_str(&tok[1..])
+            }
+            _ => self.out.push_str(tok),
+        }
+    }
+
     fn parse_ident_or_literal(&mut self, _ctx: Ctx) -> Result<(), RepairError> {
         // Capture a run of identifier characters
         let start = self.pos;
@@ -958,6 +1410,34 @@ impl<'a> LlmCompatParser<'a> {
         let orig: String = self.input[start..self.pos].iter().collect();
         let ident = orig.to_lowercase();
 
+        // 已注册的类型包装 `Name(arg)`（见 `Options::unwrap_functions`）
+        if let Some(mode) = self._opts.unwrap_mode(&orig) {
+            let mut p = self.pos;
+            while matches!(self.input.get(p), Some(' ' | '\t')) {
+                p += 1;
+            }
+            if self.input.get(p) == Some(&'(') {
+                self.pos = p + 1;
+                return self.parse_unwrap_call(mode);
+            }
+        }
+
+        // Keywords only count as whole words: `true-ish` and `null.x` are quoted strings.
+        let whole = match self.current() {
+            None => true,
+            Some(ch) => {
+                ch.is_whitespace()
+                    || matches!(
+                        ch,
+                        ',' | ':' | '[' | ']' | '{' | '}' | '(' | ')' | '"' | '\'' | ';'
+                    )
+                    || ch == '/' && matches!(self.input.get(self.pos + 1), Some('/' | '*'))
+            }
+        };
+        if !whole {
+            self.pos = start;
+            return self.parse_unquoted_string();
+        }
         // JSON canonical keywords (must be lowercase)
         if orig == "true" {
             self.out.push_str("true");
@@ -972,18 +1452,18 @@ impl<'a> LlmCompatParser<'a> {
             return Ok(());
         }
 
-        // Python-style keywords (case-insensitive) gated by option
+        // Python-style keywords gated by option
         if self._opts.allow_python_keywords {
-            match ident.as_str() {
-                "true" => {
+            match orig.as_str() {
+                "True" => {
                     self.out.push_str("true");
                     return Ok(());
                 }
-                "false" => {
+                "False" => {
                     self.out.push_str("false");
                     return Ok(());
                 }
-                "none" => {
+                "None" => {
                     self.out.push_str("null");
                     return Ok(());
                 }
@@ -991,6 +1471,16 @@ impl<'a> LlmCompatParser<'a> {
             }
         }
 
+        // Other casings of the JSON keywords (`TRUE`, `Null`) gated by option; the Python
+        // spellings above stay with `allow_python_keywords`
+        if self._opts.case_insensitive_keywords
+            && !matches!(orig.as_str(), "True" | "False")
+            && matches!(ident.as_str(), "true" | "false" | "null")
+        {
+            self.out.push_str(&ident);
+            return Ok(());
+        }
+
         // JavaScript non-finite (NaN/Infinity) gated by option
         if self._opts.normalize_js_nonfinite {
             match ident.as_str() {
@@ -1013,6 +1503,84 @@ impl<'a> LlmCompatParser<'a> {
         self.parse_unquoted_string()
     }
 
+    // 用第一个参数替换整个调用并按 mode 转换；其余参数解析后丢弃，缺少 `)` 时也接受
+    fn parse_unwrap_call(&mut self, mode: UnwrapMode) -> Result<(), RepairError> {
+        if self.calls == crate::parser::MAX_UNWRAP_DEPTH {
+            return Err(RepairError::new(
+                RepairErrorKind::Parse("typed wrappers nested too deeply".into()),
+                self.char_to_byte[self.pos],
+            ));
+        }
+        self.calls += 1;
+        let unwrapped = self.unwrap_call_args(mode);
+        self.calls -= 1;
+        unwrapped
+    }
+
+    fn unwrap_call_args(&mut self, mode: UnwrapMode) -> Result<(), RepairError> {
+        let mark = self.out.len();
+        let mut arg: Option<String> = None;
+        loop {
+            self.skip_ws();
+            self.skip_comments();
+            self.skip_ws();
+            match self.current() {
+                None | Some(']' | '}') => break,
+                Some(')') => {
+                    self.pos += 1;
+                    break;
+                }
+                Some(',') => {
+                    self.pos += 1;
+                    continue;
+                }
+                _ => {}
+            }
+            if mode == UnwrapMode::Number
+                && arg.is_none()
+                && let Some(word) = self.take_nonfinite_word()
+            {
+                arg = Some(format!("\"{word}\""));
+                continue;
+            }
+            let before = self.pos;
+            self.parse_value(Ctx::Array)?;
+            let text = self.out.split_off(mark);
+            arg.get_or_insert(text);
+            if self.pos == before {
+                break;
+            }
+        }
+        let arg = arg.unwrap_or_default();
+        match mode {
+            UnwrapMode::Null => self.out.push_str("null"),
+            _ if arg.is_empty() => self.out.push_str("null"),
+            UnwrapMode::String if !arg.starts_with(['"', '{', '[']) => {
+                self.out.push('"');
+                self.out.push_str(&arg);
+                self.out.push('"');
+            }
+            UnwrapMode::Number => match arg.strip_prefix('"').and_then(|a| a.strip_suffix('"')) {
+                Some(num) if crate::parser::is_json_number(num) => self.push_number(num),
+                Some(k) if self._opts.normalize_js_nonfinite && crate::parser::is_non_finite(k) => {
+                    self.out.push_str("null")
+                }
+                _ => self.out.push_str(&arg),
+            },
+            UnwrapMode::String => self.out.push_str(&arg),
+        }
+        Ok(())
+    }
+
+    // 带可选符号的 `NaN`、`Infinity`（`+Infinity`、`-NaN`）：整词取出，免得单词解析把 `)` 也吞进去
+    fn take_nonfinite_word(&mut self) -> Option<&'a str> {
+        let rest = &self.orig[self.char_to_byte[self.pos]..];
+        let n = crate::parser::non_finite_len(rest)?;
+        // 全是 ASCII，字符数即字节数
+        self.pos += n;
+        Some(&rest[..n])
+    }
+
     fn append_char(&mut self, ch: char) {
         if !self.ensure_ascii || ch.is_ascii() {
             self.out.push(ch);
@@ -1108,43 +1676,6 @@ impl<'a> LlmCompatParser<'a> {
     }
 }
 
-// ASCII-only string formatter (serde serializer) for ensure_ascii=true
-#[cfg(feature = "serde")]
-struct AsciiEscaper;
-
-#[cfg(feature = "serde")]
-impl serde_json::ser::Formatter for AsciiEscaper {
-    fn write_string_fragment<W>(&mut self, writer: &mut W, fragment: &str) -> std::io::Result<()>
-    where
-        W: ?Sized + std::io::Write,
-    {
-        let mut start = 0usize;
-        let fragment_bytes = fragment.as_bytes();
-        for (i, ch) in fragment.char_indices() {
-            if ch <= '\u{7F}' {
-                continue;
-            }
-            if i > start {
-                writer.write_all(&fragment_bytes[start..i])?;
-            }
-            let cp = ch as u32;
-            if cp <= 0xFFFF {
-                write!(writer, "\\u{:04X}", cp)?;
-            } else {
-                let v = cp - 0x10000;
-                let high = 0xD800 + ((v >> 10) & 0x3FF);
-                let low = 0xDC00 + (v & 0x3FF);
-                write!(writer, "\\u{:04X}\\u{:04X}", high, low)?;
-            }
-            start = i + ch.len_utf8();
-        }
-        if start < fragment.len() {
-            writer.write_all(&fragment_bytes[start..])?;
-        }
-        Ok(())
-    }
-}
-
 fn apply_python_separators(s: &str) -> String {
     let mut out = String::with_capacity(s.len() + s.len() / 10);
     let mut in_str = false;
@@ -40,7 +40,7 @@ pub fn ascii_run_string(bytes: &[u8], quote: u8) -> usize {
         if b >= 0x80 {
             break;
         }
-        if b == quote || b == b'"' || b == b'\\' {
+        if b == quote || b == b'"' || b == b'\\' || b < 0x20 {
             break;
         }
         i += 1;
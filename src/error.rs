@@ -8,6 +8,26 @@ pub enum RepairErrorKind {
     ColonExpected,
     InvalidUnicodeEscape,
     Parse(String),
+    /// Input rejected because it is not valid JSON (see `Options::reject_if_invalid`).
+    InvalidJson(String),
+    /// Repair exceeded `Options::timeout_ms`; carries the configured budget in milliseconds.
+    Timeout(u64),
+    /// Repair needed more than `Options::max_repairs` fixes; carries the configured limit.
+    TooManyRepairs(usize),
+    /// An array or object had more than `Options::max_elements` members; carries the limit.
+    /// The position is where the first member over the limit starts.
+    TooManyElements(usize),
+    /// An object key was longer than `Options::max_key_len` bytes; carries the limit. The
+    /// position is where the key starts.
+    KeyTooLong(usize),
+    /// `repair_extract` found nothing at the given JSON Pointer; carries the pointer.
+    PointerNotFound(String),
+    /// A stream buffered more than `StreamRepairer::set_max_buffer` bytes for an incomplete
+    /// value; carries the configured cap. The position is the number of bytes buffered.
+    BufferOverflow(usize),
+    /// The input was longer than `Options::max_input_bytes`; carries the limit. The position
+    /// is the first byte over the limit.
+    InputTooLarge(usize),
 }
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -54,6 +74,50 @@ impl fmt::Display for RepairError {
                 write!(f, "Invalid unicode escape at position {}", self.position)
             }
             RepairErrorKind::Parse(msg) => write!(f, "{} at position {}", msg, self.position),
+            RepairErrorKind::InvalidJson(msg) => {
+                write!(f, "Invalid JSON: {} at position {}", msg, self.position)
+            }
+            RepairErrorKind::Timeout(ms) => {
+                write!(f, "Timed out after {} ms at position {}", ms, self.position)
+            }
+            RepairErrorKind::TooManyRepairs(n) => {
+                write!(
+                    f,
+                    "More than {} repairs needed at position {}",
+                    n, self.position
+                )
+            }
+            RepairErrorKind::TooManyElements(n) => {
+                write!(
+                    f,
+                    "Container has more than {} elements at position {}",
+                    n, self.position
+                )
+            }
+            RepairErrorKind::KeyTooLong(n) => {
+                write!(
+                    f,
+                    "Object key longer than {} bytes at position {}",
+                    n, self.position
+                )
+            }
+            RepairErrorKind::PointerNotFound(p) => {
+                write!(f, "JSON Pointer {:?} not found in repaired output", p)
+            }
+            RepairErrorKind::BufferOverflow(cap) => {
+                write!(
+                    f,
+                    "Stream buffer exceeded {} bytes ({} buffered) before a value completed",
+                    cap, self.position
+                )
+            }
+            RepairErrorKind::InputTooLarge(n) => {
+                write!(
+                    f,
+                    "Input longer than {} bytes at position {}",
+                    n, self.position
+                )
+            }
         }
     }
 }
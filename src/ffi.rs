@@ -4,10 +4,16 @@
 //! Enable with the `c-api` feature.
 
 use std::ffi::{CStr, CString};
-use std::os::raw::c_char;
+use std::os::raw::{c_char, c_int, c_void};
 use std::ptr;
 
-use crate::{Options, RepairError, RepairErrorKind, StreamRepairer};
+use crate::{
+    AsciiScope, BracketKind, CompactSpacing, DedupPosition, ForceContainer, LineContinuation,
+    LongKeyPolicy, MissingValuePolicy, NegativeZeroPolicy, NumberFormat, NumberSuffixPolicy,
+    Options, OutputFormat, OverflowPolicy, Progress, RepairError, RepairErrorKind,
+    SafeIntegerPolicy, SalvagePolicy, StrayTokenPolicy, StreamRepairer, StreamStats, Strictness,
+    Trace, UnwrapMode, Utf8Strictness, Utf16Endian, ValueKind, ValueRange, ValueStatus,
+};
 
 // ============================================================================
 // Error Handling
@@ -24,6 +30,20 @@ pub enum JsonRepairErrorCode {
     ColonExpected = 4,
     InvalidUnicode = 5,
     Parse = 6,
+    InvalidJson = 7,
+    Timeout = 8,
+    TooManyRepairs = 9,
+    PointerNotFound = 10,
+    BufferOverflow = 11,
+    TooManyElements = 12,
+    /// An input string was not valid UTF-8; the position is the offset of the first
+    /// invalid byte.
+    InvalidUtf8 = 13,
+    /// An object key was longer than `max_key_len` bytes; the position is where the key
+    /// starts.
+    KeyTooLong = 14,
+    /// The input was longer than `max_input_bytes`; no repair was attempted.
+    InputTooLarge = 15,
 }
 
 /// Error structure for C API
@@ -34,6 +54,10 @@ pub struct JsonRepairError {
     pub position: usize,
 }
 
+// Offsets and lengths cross the C boundary as `usize` (`size_t` in the header), which is as
+// wide as a pointer, so any position in an in-memory input is reported without truncation.
+const _: () = assert!(std::mem::size_of::<usize>() == std::mem::size_of::<*const c_char>());
+
 impl JsonRepairError {
     fn from_repair_error(err: RepairError) -> Self {
         let code = match err.kind {
@@ -43,6 +67,14 @@ impl JsonRepairError {
             RepairErrorKind::ColonExpected => JsonRepairErrorCode::ColonExpected,
             RepairErrorKind::InvalidUnicodeEscape => JsonRepairErrorCode::InvalidUnicode,
             RepairErrorKind::Parse(_) => JsonRepairErrorCode::Parse,
+            RepairErrorKind::InvalidJson(_) => JsonRepairErrorCode::InvalidJson,
+            RepairErrorKind::Timeout(_) => JsonRepairErrorCode::Timeout,
+            RepairErrorKind::TooManyRepairs(_) => JsonRepairErrorCode::TooManyRepairs,
+            RepairErrorKind::PointerNotFound(_) => JsonRepairErrorCode::PointerNotFound,
+            RepairErrorKind::BufferOverflow(_) => JsonRepairErrorCode::BufferOverflow,
+            RepairErrorKind::TooManyElements(_) => JsonRepairErrorCode::TooManyElements,
+            RepairErrorKind::KeyTooLong(_) => JsonRepairErrorCode::KeyTooLong,
+            RepairErrorKind::InputTooLarge(_) => JsonRepairErrorCode::InputTooLarge,
         };
 
         let message = CString::new(err.to_string())
@@ -56,6 +88,18 @@ impl JsonRepairError {
         }
     }
 
+    fn invalid_utf8(e: std::str::Utf8Error) -> Self {
+        let position = e.valid_up_to();
+        let message = CString::new(format!("Invalid UTF-8 at position {}", position))
+            .unwrap_or_else(|_| CString::new("Unknown error").unwrap())
+            .into_raw();
+        JsonRepairError {
+            code: JsonRepairErrorCode::InvalidUtf8,
+            message,
+            position,
+        }
+    }
+
     fn ok() -> Self {
         JsonRepairError {
             code: JsonRepairErrorCode::Ok,
@@ -65,6 +109,15 @@ impl JsonRepairError {
     }
 }
 
+// The C string `input` as text, checked according to `opts.utf8_strictness`.
+unsafe fn input_text<'a>(
+    input: *const c_char,
+    opts: &Options,
+) -> Result<std::borrow::Cow<'a, str>, std::str::Utf8Error> {
+    let bytes = unsafe { CStr::from_ptr(input) }.to_bytes();
+    crate::utf8::decode(bytes, opts.utf8_strictness)
+}
+
 // ============================================================================
 // Simple API
 // ============================================================================
@@ -165,6 +218,81 @@ pub unsafe extern "C" fn jsonrepair_options_set_allow_python_keywords(
     }
 }
 
+/// Set the case_insensitive_keywords option.
+///
+/// Reads `true`, `false` and `null` in other letter cases (`TRUE`, `FALSE`, `Null`, `NULL`)
+/// and writes them in lowercase. The Python spellings `True`, `False` and `None` follow
+/// `jsonrepair_options_set_allow_python_keywords()` instead. Default: true.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_case_insensitive_keywords(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.case_insensitive_keywords = value;
+        }
+    }
+}
+
+/// Set the abbreviated_keywords option.
+///
+/// Reads a lone `t`, `f` or `n` value as `true`, `false` or `null` (`{ok: t}` →
+/// `{"ok":true}`). Only spaces or tabs may separate the letter from the next `,`, `}`, `]`,
+/// line break or the end, so `tf`, `t-shirt`, `n/a` and `t rex` stay strings, as do keys.
+/// Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_abbreviated_keywords(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.abbreviated_keywords = value;
+        }
+    }
+}
+
+/// Set the null_tokens option from a list of bare tokens.
+///
+/// Replaces the list with the `count` strings in `tokens`; each one standing alone in value
+/// position becomes `null`, so with `N/A` and `-` the input `{a: N/A, b: -}` gives
+/// `{"a":null,"b":null}`. Matching is exact and case-sensitive. NULL entries and non-UTF-8
+/// strings are skipped, and NULL or a count of 0 clears the list.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `tokens` must point to `count` valid null-terminated strings or NULLs, or be NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_null_tokens(
+    opts: *mut Options,
+    tokens: *const *const c_char,
+    count: usize,
+) {
+    unsafe {
+        let Some(opts) = opts.as_mut() else {
+            return;
+        };
+        opts.null_tokens.clear();
+        if tokens.is_null() {
+            return;
+        }
+        for &token in std::slice::from_raw_parts(tokens, count) {
+            if !token.is_null()
+                && let Ok(token) = CStr::from_ptr(token).to_str()
+            {
+                opts.null_tokens.push(token.to_string());
+            }
+        }
+    }
+}
+
 /// Set the tolerate_hash_comments option.
 ///
 /// # Safety
@@ -242,6 +370,41 @@ pub unsafe extern "C" fn jsonrepair_options_set_stream_ndjson_aggregate(
     }
 }
 
+/// Set the lines_to_array option.
+///
+/// Repairs each non-blank line of the input on its own and collects the values into one
+/// JSON array, so a line left open does not run into the next. A line that cannot be
+/// repaired is handled per the salvage option (`SALVAGE_FAIL` returns its error). Off by
+/// default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_lines_to_array(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.lines_to_array = value;
+        }
+    }
+}
+
+/// Set the stop_after_first option.
+///
+/// Stops after the first complete root value, so `{a: 1} {b: 2}` gives `{"a":1}`, and
+/// leaves the rest of the input unread: the cost of a large remainder is not paid. Like
+/// `jsonrepair_repair_first()`, but for the standard repair calls. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_stop_after_first(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.stop_after_first = value;
+        }
+    }
+}
+
 /// Set the logging option.
 ///
 /// # Safety
@@ -319,215 +482,3062 @@ pub unsafe extern "C" fn jsonrepair_options_set_aggressive_truncation_fix(
     }
 }
 
-/// Repair a JSON string with custom options.
+/// Set the reject_if_invalid option.
+///
+/// When enabled, input that is not already valid JSON fails with
+/// `INVALID_JSON` (and the position of the first problem) instead of being repaired.
 ///
 /// # Safety
-/// - `input` must be a valid null-terminated UTF-8 string
-/// - `opts` must be a valid pointer to Options, or NULL for defaults
-/// - The returned string must be freed with `jsonrepair_free()`
-/// - Returns NULL on error
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_repair_with_options(
-    input: *const c_char,
-    opts: *const Options,
-) -> *mut c_char {
+pub unsafe extern "C" fn jsonrepair_options_set_reject_if_invalid(opts: *mut Options, value: bool) {
     unsafe {
-        if input.is_null() {
-            return ptr::null_mut();
-        }
-
-        let c_str = match CStr::from_ptr(input).to_str() {
-            Ok(s) => s,
-            Err(_) => return ptr::null_mut(),
-        };
-
-        let options = if opts.is_null() {
-            &Options::default()
-        } else {
-            &*opts
-        };
-
-        match crate::repair_json(c_str, options) {
-            Ok(result) => CString::new(result)
-                .unwrap_or_else(|_| CString::new("").unwrap())
-                .into_raw(),
-            Err(_) => ptr::null_mut(),
+        if let Some(opts) = opts.as_mut() {
+            opts.reject_if_invalid = value;
         }
     }
 }
 
-/// Repair a JSON string with error details.
+/// Set the timeout_ms option.
+///
+/// Repair aborts with `TIMEOUT` once a single call runs longer than `ms`
+/// milliseconds. The clock is checked every 256 parsed values, so the budget is
+/// approximate. Pass 0 to disable (default).
 ///
 /// # Safety
-/// - `input` must be a valid null-terminated UTF-8 string
-/// - `opts` must be a valid pointer to Options, or NULL for defaults
-/// - `error` can be NULL to ignore error details
-/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `free()`
-/// - The returned string must be freed with `jsonrepair_free()`
-/// - Returns NULL on error
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_repair_ex(
-    input: *const c_char,
-    opts: *const Options,
-    error: *mut JsonRepairError,
-) -> *mut c_char {
+pub unsafe extern "C" fn jsonrepair_options_set_timeout_ms(opts: *mut Options, ms: u64) {
     unsafe {
-        if input.is_null() {
-            if !error.is_null() {
-                *error = JsonRepairError::from_repair_error(RepairError::new(
-                    RepairErrorKind::Parse("Input is NULL".to_string()),
-                    0,
-                ));
-            }
-            return ptr::null_mut();
-        }
-
-        let c_str = match CStr::from_ptr(input).to_str() {
-            Ok(s) => s,
-            Err(e) => {
-                if !error.is_null() {
-                    *error = JsonRepairError::from_repair_error(RepairError::new(
-                        RepairErrorKind::Parse(format!("Invalid UTF-8: {}", e)),
-                        0,
-                    ));
-                }
-                return ptr::null_mut();
-            }
-        };
-
-        let options = if opts.is_null() {
-            &Options::default()
-        } else {
-            &*opts
-        };
-
-        match crate::repair_json(c_str, options) {
-            Ok(result) => {
-                if !error.is_null() {
-                    *error = JsonRepairError::ok();
-                }
-                CString::new(result)
-                    .unwrap_or_else(|_| CString::new("").unwrap())
-                    .into_raw()
-            }
-            Err(e) => {
-                if !error.is_null() {
-                    *error = JsonRepairError::from_repair_error(e);
-                }
-                ptr::null_mut()
-            }
+        if let Some(opts) = opts.as_mut() {
+            opts.timeout_ms = ms;
         }
     }
 }
 
-// ============================================================================
-// Streaming API
-// ============================================================================
-
-/// Create a new streaming repairer.
+/// Set the output_bom option.
+///
+/// When enabled, the output is prefixed with a single UTF-8 BOM (`EF BB BF`).
 ///
 /// # Safety
-/// - `opts` can be NULL for default options
-/// - Must be freed with `jsonrepair_stream_free()`
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_new(opts: *const Options) -> *mut StreamRepairer {
+pub unsafe extern "C" fn jsonrepair_options_set_output_bom(opts: *mut Options, value: bool) {
     unsafe {
-        let options = if opts.is_null() {
-            Options::default()
-        } else {
-            (*opts).clone()
-        };
-
-        Box::into_raw(Box::new(StreamRepairer::new(options)))
+        if let Some(opts) = opts.as_mut() {
+            opts.output_bom = value;
+        }
     }
 }
 
-/// Free a streaming repairer.
+/// Set the raw_message_safe option.
+///
+/// When enabled, the output is compact JSON with no surrounding whitespace, trailing newline
+/// or BOM, safe to embed as-is (e.g. as a Go `json.RawMessage`).
 ///
 /// # Safety
-/// - `stream` must be a pointer returned by `jsonrepair_stream_new()`, or NULL
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_free(stream: *mut StreamRepairer) {
+pub unsafe extern "C" fn jsonrepair_options_set_raw_message_safe(opts: *mut Options, value: bool) {
     unsafe {
-        if !stream.is_null() {
-            drop(Box::from_raw(stream));
+        if let Some(opts) = opts.as_mut() {
+            opts.raw_message_safe = value;
         }
     }
 }
 
-/// Push a chunk to the streaming repairer.
+/// Set the stream_validate_only option.
+///
+/// Streams created with this option validate completed values instead of
+/// repairing them; use `jsonrepair_stream_push_validate()` to collect statuses.
 ///
 /// # Safety
-/// - `stream` must be a valid pointer to StreamRepairer
-/// - `chunk` must be a valid null-terminated UTF-8 string
-/// - Returns NULL if no complete value yet, or a string that must be freed with `jsonrepair_free()`
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_push(
-    stream: *mut StreamRepairer,
-    chunk: *const c_char,
-) -> *mut c_char {
+pub unsafe extern "C" fn jsonrepair_options_set_stream_validate_only(
+    opts: *mut Options,
+    value: bool,
+) {
     unsafe {
-        if stream.is_null() || chunk.is_null() {
-            return ptr::null_mut();
-        }
-
-        let stream = match stream.as_mut() {
-            Some(s) => s,
-            None => return ptr::null_mut(),
-        };
-
-        let c_str = match CStr::from_ptr(chunk).to_str() {
-            Ok(s) => s,
-            Err(_) => return ptr::null_mut(),
-        };
-
-        match stream.push(c_str) {
-            Ok(Some(result)) => CString::new(result)
-                .unwrap_or_else(|_| CString::new("").unwrap())
-                .into_raw(),
-            Ok(None) => ptr::null_mut(),
-            Err(_) => ptr::null_mut(),
+        if let Some(opts) = opts.as_mut() {
+            opts.stream_validate_only = value;
         }
     }
 }
 
-/// Flush the streaming repairer.
+/// Set the trim_keys option.
+///
+/// Whitespace around object keys is trimmed before any other key handling,
+/// so keys that differ only in surrounding whitespace become duplicates.
 ///
 /// # Safety
-/// - `stream` must be a valid pointer to StreamRepairer
-/// - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_flush(stream: *mut StreamRepairer) -> *mut c_char {
+pub unsafe extern "C" fn jsonrepair_options_set_trim_keys(opts: *mut Options, value: bool) {
     unsafe {
-        if stream.is_null() {
-            return ptr::null_mut();
-        }
-
-        let stream = match stream.as_mut() {
-            Some(s) => s,
-            None => return ptr::null_mut(),
-        };
-
-        match stream.flush() {
-            Ok(Some(result)) => CString::new(result)
-                .unwrap_or_else(|_| CString::new("").unwrap())
-                .into_raw(),
-            Ok(None) => ptr::null_mut(),
-            Err(_) => ptr::null_mut(),
+        if let Some(opts) = opts.as_mut() {
+            opts.trim_keys = value;
         }
     }
 }
 
-/// Push a chunk with error handling.
+/// Set the unwrap_escaped_json option.
+///
+/// A top-level string whose content is strictly valid JSON is replaced by that
+/// content, up to 8 nested escaping layers. A string value holding an object or
+/// array encoded two or more times (`{"p": "{\\"k\\": 1}"}`) is decoded too.
 ///
 /// # Safety
-/// - `stream` must be a valid pointer to StreamRepairer
-/// - `chunk` must be a valid null-terminated UTF-8 string
-/// - `error` can be NULL to ignore error details
+/// - `opts` must be a valid pointer to Options
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_push_ex(
-    stream: *mut StreamRepairer,
-    chunk: *const c_char,
+pub unsafe extern "C" fn jsonrepair_options_set_unwrap_escaped_json(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.unwrap_escaped_json = value;
+        }
+    }
+}
+
+/// Set the max_repairs option.
+///
+/// Repair aborts with `TOO_MANY_REPAIRS` once more than `n` fixes have been
+/// applied to the input. Pass 0 for no limit (default).
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_max_repairs(opts: *mut Options, n: usize) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.max_repairs = n;
+        }
+    }
+}
+
+/// Set the wrap_fragments option.
+///
+/// Newline-separated `key = value` lines (`.env`/TOML style) are assembled
+/// into a single JSON object, and a CSV-style row of quoted fields
+/// (`"a","b","c"`) into an array.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_wrap_fragments(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.wrap_fragments = value;
+        }
+    }
+}
+
+/// Set the extract_embedded option.
+///
+/// Only the JSON embedded in surrounding prose is repaired: the span runs from the first
+/// `{` or `[` to the closer that balances it, skipping brackets inside strings, so
+/// `Here is the result: {"a":1}. Thanks!` becomes `{"a":1}`. An opener that is never
+/// closed extends to the end of the input.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_extract_embedded(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.extract_embedded = value;
+        }
+    }
+}
+
+// Enum setters take the mode as `u32`: a C caller can pass any integer, and an
+// out-of-range value in a `#[repr(C)]` enum parameter is undefined behavior.
+// `from_raw` maps the integer back to the enum, or `None` for an unknown value.
+macro_rules! c_enum_from_raw {
+    ($name:ident { $($variant:ident),+ $(,)? }) => {
+        impl $name {
+            fn from_raw(value: u32) -> Option<Self> {
+                [$(Self::$variant),+].into_iter().find(|v| *v as u32 == value)
+            }
+        }
+    };
+}
+
+/// How an object key without a value is repaired (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairMissingValues {
+    /// Fill with `""` (default)
+    InsertEmptyString = 0,
+    /// Fill with `null`
+    InsertNull = 1,
+    /// Drop the key
+    DropKey = 2,
+}
+
+c_enum_from_raw!(JsonRepairMissingValues {
+    InsertEmptyString,
+    InsertNull,
+    DropKey
+});
+
+/// Set the missing_values option.
+///
+/// Controls keys with no value, with or without a colon (`{"a":}`, `{"a"}`):
+/// `INSERT_EMPTY_STRING` (default), `INSERT_NULL` or `DROP_KEY`. An unknown `mode` is
+/// ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_missing_values(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairMissingValues::from_raw(mode)
+        {
+            opts.missing_value_policy = match mode {
+                JsonRepairMissingValues::InsertEmptyString => MissingValuePolicy::EmptyString,
+                JsonRepairMissingValues::InsertNull => MissingValuePolicy::Null,
+                JsonRepairMissingValues::DropKey => MissingValuePolicy::DropKey,
+            };
+        }
+    }
+}
+
+/// Which strings get non-ASCII characters escaped (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairAsciiScope {
+    /// Keep UTF-8 everywhere (default)
+    AsciiNone = 0,
+    /// Escape object keys only
+    AsciiKeysOnly = 1,
+    /// Escape values only
+    AsciiValuesOnly = 2,
+    /// Escape keys and values
+    AsciiAll = 3,
+}
+
+c_enum_from_raw!(JsonRepairAsciiScope {
+    AsciiNone,
+    AsciiKeysOnly,
+    AsciiValuesOnly,
+    AsciiAll
+});
+
+/// Set the ascii_scope option.
+///
+/// Escapes non-ASCII characters as `\uXXXX` in keys only, values only, or both.
+/// `jsonrepair_options_set_ensure_ascii(opts, true)` still escapes everything.
+/// An unknown `scope` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_ascii_scope(opts: *mut Options, scope: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(scope) = JsonRepairAsciiScope::from_raw(scope)
+        {
+            opts.ascii_scope = match scope {
+                JsonRepairAsciiScope::AsciiNone => AsciiScope::None,
+                JsonRepairAsciiScope::AsciiKeysOnly => AsciiScope::KeysOnly,
+                JsonRepairAsciiScope::AsciiValuesOnly => AsciiScope::ValuesOnly,
+                JsonRepairAsciiScope::AsciiAll => AsciiScope::All,
+            };
+        }
+    }
+}
+
+/// Set the normalize_numbers option.
+///
+/// Numbers are emitted in one canonical spelling (`1.50E+03` becomes `1.5e3`,
+/// `-0` becomes `0`) so equal values produce equal output.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_normalize_numbers(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.normalize_numbers = value;
+        }
+    }
+}
+
+/// Set the equals_separators option.
+///
+/// Object members may use `=`, `=>` or `->` instead of `:` (`{a => 1, b = 2, c -> 3}`);
+/// all separators are emitted as `:`.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_equals_separators(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.equals_separators = value;
+        }
+    }
+}
+
+/// Where the surviving member of a duplicated key goes (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairDedupPosition {
+    /// Keep all duplicates (default)
+    DedupKeepAll = 0,
+    /// Last value, at the first-seen position
+    DedupFirstPosition = 1,
+    /// Last value, at the last occurrence's position
+    DedupLastPosition = 2,
+}
+
+c_enum_from_raw!(JsonRepairDedupPosition {
+    DedupKeepAll,
+    DedupFirstPosition,
+    DedupLastPosition
+});
+
+/// Set the dedup_position option.
+///
+/// Collapses duplicate object keys so the last value wins. `DEDUP_FIRST_POSITION` keeps
+/// the survivor where the key first appeared (`{"a":1,"b":2,"a":3}` → `{"a":3,"b":2}`);
+/// `DEDUP_LAST_POSITION` moves it to the last occurrence (`{"b":2,"a":3}`).
+/// `DEDUP_KEEP_ALL` (default) keeps every duplicate.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_dedup_position(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairDedupPosition::from_raw(mode)
+        {
+            opts.dedup_position = match mode {
+                JsonRepairDedupPosition::DedupKeepAll => DedupPosition::KeepAll,
+                JsonRepairDedupPosition::DedupFirstPosition => DedupPosition::First,
+                JsonRepairDedupPosition::DedupLastPosition => DedupPosition::Last,
+            };
+        }
+    }
+}
+
+/// Set the decode_base64 option.
+///
+/// When the whole input is standard base64 that decodes to text opening an object or
+/// array, decode it first and repair the result. Any other input, including base64 that
+/// decodes to non-JSON, is repaired unchanged.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_decode_base64(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.decode_base64 = value;
+        }
+    }
+}
+
+/// Set the annotate_source option.
+///
+/// Follows every parsed value with an `@src:OFFSET` block comment holding the byte offset
+/// in the input where it started. Requires `FORMAT_JSON5` output
+/// (`jsonrepair_options_set_output_format`); with strict JSON output the repair fails. Use
+/// it for debugging only.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_annotate_source(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.annotate_source = value;
+        }
+    }
+}
+
+/// How a bare non-keyword array element is repaired (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairStrayTokens {
+    /// Fail with a parse error at the token (default)
+    StrayError = 0,
+    /// Quote it as a string
+    StrayQuote = 1,
+    /// Drop the element
+    StrayDrop = 2,
+}
+
+c_enum_from_raw!(JsonRepairStrayTokens {
+    StrayError,
+    StrayQuote,
+    StrayDrop
+});
+
+/// Set the stray_tokens option.
+///
+/// Controls bare array elements that are not keywords, as in `[1, garbage, 2]`:
+/// `STRAY_ERROR` (default) fails with a `PARSE` error at the token's offset,
+/// `STRAY_QUOTE` emits `"garbage"` and `STRAY_DROP` removes the element.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_stray_tokens(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairStrayTokens::from_raw(mode)
+        {
+            opts.stray_tokens = match mode {
+                JsonRepairStrayTokens::StrayQuote => StrayTokenPolicy::Quote,
+                JsonRepairStrayTokens::StrayDrop => StrayTokenPolicy::Drop,
+                JsonRepairStrayTokens::StrayError => StrayTokenPolicy::Error,
+            };
+        }
+    }
+}
+
+/// How a scalar top-level value is wrapped (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairForceContainer {
+    /// Leave scalars as they are (default)
+    ForceOff = 0,
+    /// Wrap as `[value]`
+    ForceArray = 1,
+    /// Wrap as `{"value":value}`
+    ForceObject = 2,
+}
+
+c_enum_from_raw!(JsonRepairForceContainer {
+    ForceOff,
+    ForceArray,
+    ForceObject
+});
+
+/// Set the force_container option.
+///
+/// Guarantees an object or array at the top level: a scalar result such as `5` becomes
+/// `[5]` (`FORCE_ARRAY`) or `{"value":5}` (`FORCE_OBJECT`). Objects, arrays and empty
+/// output are unchanged. `FORCE_OFF` (default) keeps scalars.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_force_container(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairForceContainer::from_raw(mode)
+        {
+            opts.force_container = match mode {
+                JsonRepairForceContainer::ForceOff => ForceContainer::Off,
+                JsonRepairForceContainer::ForceArray => ForceContainer::Array,
+                JsonRepairForceContainer::ForceObject => ForceContainer::Object,
+            };
+        }
+    }
+}
+
+/// Set the envelope option.
+///
+/// Wraps the output in `{"value":...,"repaired":...,"repairs":N}`: `repaired` is whether the
+/// value differs from the input and `repairs` counts the fixes applied. An empty value is
+/// `null`. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_envelope(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.envelope = value;
+        }
+    }
+}
+
+/// Set the fix_mojibake option.
+///
+/// Repairs curly quotes, dashes and ellipses whose UTF-8 bytes were misdecoded as
+/// Windows-1252 (`â€œkeyâ€` → `"key"`) before repairing. Heuristic; off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_fix_mojibake(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.fix_mojibake = value;
+        }
+    }
+}
+
+/// What replaces a nested value that cannot be repaired (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairSalvage {
+    /// Fail the whole document (default)
+    SalvageFail = 0,
+    /// Substitute `null`
+    SalvageNull = 1,
+    /// Substitute `{"$unrepairable": "<source text>"}`
+    SalvageMarker = 2,
+}
+
+c_enum_from_raw!(JsonRepairSalvage {
+    SalvageFail,
+    SalvageNull,
+    SalvageMarker
+});
+
+/// Set the alt_quotes option from a list of delimiter pairs.
+///
+/// `pairs` is a UTF-8 string of characters taken two at a time as (opening, closing), so
+/// `"«»‹›"` reads guillemets as double quotes and `"「」『』"` does the same for CJK corner
+/// brackets. Outside a string, text between a pair is repaired as a double-quoted string;
+/// inside an ASCII-quoted string the characters are kept. A trailing unpaired character is
+/// ignored, and NULL or `""` clears the list. Non-UTF-8 input leaves the list unchanged.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `pairs` must be a valid null-terminated string, or NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_alt_quote_chars(
+    opts: *mut Options,
+    pairs: *const c_char,
+) {
+    unsafe {
+        let Some(opts) = opts.as_mut() else {
+            return;
+        };
+        if pairs.is_null() {
+            opts.alt_quotes.clear();
+            return;
+        }
+        if let Ok(pairs) = CStr::from_ptr(pairs).to_str() {
+            let chars: Vec<char> = pairs.chars().collect();
+            opts.alt_quotes = chars.chunks_exact(2).map(|p| (p[0], p[1])).collect();
+        }
+    }
+}
+
+/// What a backslash-newline line continuation inside a string becomes (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairLineContinuations {
+    /// Join the lines: `"foo\<LF>bar"` becomes `"foobar"` (default)
+    ContinuationElide = 0,
+    /// Keep the line break escaped: `"foo\<LF>bar"` becomes `"foo\nbar"`
+    ContinuationKeep = 1,
+}
+
+c_enum_from_raw!(JsonRepairLineContinuations {
+    ContinuationElide,
+    ContinuationKeep
+});
+
+/// Set the line_continuations option.
+///
+/// A backslash directly before a line break inside a string is a line continuation.
+/// `CONTINUATION_ELIDE` (default) removes both and joins the lines; `CONTINUATION_KEEP`
+/// removes the backslash and keeps the break as `\n` (`\r\n` for a CRLF break).
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_line_continuations(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairLineContinuations::from_raw(mode)
+        {
+            opts.line_continuations = match mode {
+                JsonRepairLineContinuations::ContinuationElide => LineContinuation::Elide,
+                JsonRepairLineContinuations::ContinuationKeep => LineContinuation::Keep,
+            };
+        }
+    }
+}
+
+/// Set the fix_backslashes option.
+///
+/// Keeps a lone backslash inside a string that does not start a valid escape, so a Windows
+/// path such as `"C:\Users"` is written as `"C:\\Users"` instead of `"C:Users"`. Genuine
+/// escapes (`\n`, `\t`, `\uXXXX` and the rest) are still decoded; a `\u` without four hex
+/// digits is kept as text. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_fix_backslashes(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.fix_backslashes = value;
+        }
+    }
+}
+
+/// Set the salvage option.
+///
+/// When an array element or object value fails with a parse error (for example a stray
+/// token under `STRAY_ERROR`), `SALVAGE_NULL` and `SALVAGE_MARKER` replace it, up to the
+/// next `,` or closing bracket, and repair the rest of the document. `SALVAGE_FAIL`
+/// (default) fails the whole repair. Budget errors are never salvaged.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_salvage(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairSalvage::from_raw(mode)
+        {
+            opts.salvage = match mode {
+                JsonRepairSalvage::SalvageFail => SalvagePolicy::Fail,
+                JsonRepairSalvage::SalvageNull => SalvagePolicy::Null,
+                JsonRepairSalvage::SalvageMarker => SalvagePolicy::Marker,
+            };
+        }
+    }
+}
+
+/// Set the drop_placeholder option.
+///
+/// The placeholder is written in place of an array element dropped under `STRAY_DROP` and
+/// of a value replaced under `SALVAGE_NULL`. When it is one JSON value (`null`, `-1`,
+/// `{"dropped":true}`) it is written minified; any other text is written as a JSON
+/// string. NULL clears it (default). Non-UTF-8 input leaves it unchanged.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `placeholder` must be a valid null-terminated string, or NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_drop_placeholder(
+    opts: *mut Options,
+    placeholder: *const c_char,
+) {
+    unsafe {
+        let Some(opts) = opts.as_mut() else {
+            return;
+        };
+        if placeholder.is_null() {
+            opts.drop_placeholder = None;
+            return;
+        }
+        if let Ok(placeholder) = CStr::from_ptr(placeholder).to_str() {
+            opts.drop_placeholder = Some(placeholder.to_string());
+        }
+    }
+}
+
+/// How far to go with repairs that guess at structure (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairStrictness {
+    /// Fail where the structure is ambiguous (default)
+    StrictnessConservative = 0,
+    /// Read a value followed by a colon as a nested key, and `{1, 2}` as an array
+    StrictnessAggressive = 1,
+}
+
+c_enum_from_raw!(JsonRepairStrictness {
+    StrictnessConservative,
+    StrictnessAggressive
+});
+
+/// Set the strictness option.
+///
+/// An object value that is itself followed by a colon (`{a: b: c}`) fails with a parse
+/// error under `STRICTNESS_CONSERVATIVE` (default). `STRICTNESS_AGGRESSIVE` reads it as the
+/// key of a nested object instead, giving `{"a":{"b":"c"}}`. An array element followed by
+/// a colon is handled the same way: `[a: 1, b: 2]` fails, or becomes `[{"a":1},{"b":2}]`
+/// (see `jsonrepair_options_set_brackets_as_objects()` to read it as one object).
+/// `STRICTNESS_AGGRESSIVE` also reads braces holding only values as an array (`{1, 2}` →
+/// `[1,2]`).
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_strictness(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairStrictness::from_raw(mode)
+        {
+            opts.strictness = match mode {
+                JsonRepairStrictness::StrictnessConservative => Strictness::Conservative,
+                JsonRepairStrictness::StrictnessAggressive => Strictness::Aggressive,
+            };
+        }
+    }
+}
+
+/// Set the brackets_as_objects option.
+///
+/// Brackets whose first element is followed by a colon are read as an object, whatever the
+/// strictness: `["a": 1, "b": 2]` becomes `{"a":1,"b":2}` instead of failing or becoming
+/// `[{"a":1},{"b":2}]`. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_brackets_as_objects(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.brackets_as_objects = value;
+        }
+    }
+}
+
+/// How input that is not valid UTF-8 is treated (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairUtf8Strictness {
+    /// Reject overlong encodings and other invalid sequences (default)
+    Utf8Strict = 0,
+    /// Replace each invalid sequence with U+FFFD
+    Utf8Lenient = 1,
+}
+
+c_enum_from_raw!(JsonRepairUtf8Strictness {
+    Utf8Strict,
+    Utf8Lenient
+});
+
+/// Set the utf8_strictness option.
+///
+/// Under `UTF8_STRICT` (default) input that is not well-formed UTF-8, such as an overlong
+/// encoding (`C0 AF` for `/`), fails with `INVALID_UTF8` at the first bad byte.
+/// `UTF8_LENIENT` replaces each invalid sequence with U+FFFD and repairs the rest. Applies to
+/// the repair functions that take options; streaming chunks are always checked strictly.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_utf8_strictness(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairUtf8Strictness::from_raw(mode)
+        {
+            opts.utf8_strictness = match mode {
+                JsonRepairUtf8Strictness::Utf8Strict => Utf8Strictness::Strict,
+                JsonRepairUtf8Strictness::Utf8Lenient => Utf8Strictness::Lenient,
+            };
+        }
+    }
+}
+
+/// Output syntax (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairOutputFormat {
+    /// Strict JSON (default)
+    FormatJson = 0,
+    /// JSON5: identifier keys unquoted, strings single-quoted
+    FormatJson5 = 1,
+}
+
+c_enum_from_raw!(JsonRepairOutputFormat {
+    FormatJson,
+    FormatJson5
+});
+
+/// Set the output_format option.
+///
+/// `FORMAT_JSON5` renders the result as JSON5: object keys that are ASCII identifiers are
+/// unquoted and every other string is single-quoted. Nothing else changes; no trailing
+/// commas are added and input comments are not preserved.
+/// An unknown `format` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_output_format(opts: *mut Options, format: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(format) = JsonRepairOutputFormat::from_raw(format)
+        {
+            opts.output_format = match format {
+                JsonRepairOutputFormat::FormatJson => OutputFormat::Json,
+                JsonRepairOutputFormat::FormatJson5 => OutputFormat::Json5,
+            };
+        }
+    }
+}
+
+/// Set the comma_decimal option.
+///
+/// Reads `{"price": 3,14}` as `{"price":3.14}`. Only object values are considered, and
+/// only when both sides of the comma are digits with no space around it and the fraction
+/// is followed by `}`, the end of input, or `,` and the next key. Arrays such as `[3,14]`
+/// are never changed. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_comma_decimal(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.comma_decimal = value;
+        }
+    }
+}
+
+/// Set the eval_fractions option.
+///
+/// Reads `{"ratio": 1/2}` as `{"ratio":0.5}` in object values and array elements. Both
+/// sides must be bare integers of at most 15 digits with no space around the slash (only
+/// the numerator may be negative), and the fraction must be followed by `,`, `}`, `]` or
+/// the end of input. Anything that could be a path or a date stays a string: `/usr/bin`,
+/// `1/usr`, `1 / 2`, `1/2/3`, `1.5/2`, `01/02`, `1/0`. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_eval_fractions(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.eval_fractions = value;
+        }
+    }
+}
+
+/// Set the dedup_arrays option.
+///
+/// Drops repeated scalar array elements, keeping the first (`[1,1,2,"a","a"]` becomes
+/// `[1,2,"a"]`). Strings compare by decoded text, numbers by normalized spelling (`1` and
+/// `1.0` are equal) and `true`/`false`/`null` literally. Objects and arrays are never
+/// dropped. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_dedup_arrays(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.dedup_arrays = value;
+        }
+    }
+}
+
+/// Set the sort_keys option.
+///
+/// Orders the members of every object by key (`{"b":1,"a":2}` becomes `{"a":2,"b":1}`),
+/// at every depth. Keys compare by decoded text in code point order; arrays keep their
+/// order. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_sort_keys(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.sort_keys = value;
+        }
+    }
+}
+
+/// Set the storage preset: one canonical, compact form for dedup-friendly storage.
+///
+/// Sets raw_message_safe (compact, no surrounding whitespace, trailing newline or BOM),
+/// sort_keys, `DEDUP_LAST_POSITION` (a repeated key keeps its last value), normalize_numbers
+/// and minimal_escapes, and for the escapes to be minimal turns off ensure_ascii, ascii_scope
+/// and escape_slashes and turns on short_escapes. Other options keep their values, and
+/// later setters may still change these.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_preset_storage(opts: *mut Options) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.preset_storage();
+        }
+    }
+}
+
+/// Set the escape_slashes option.
+///
+/// Emits every `/` inside strings as `\/`, so `"</script>"` becomes `"<\/script>"`
+/// when JSON is embedded in HTML. Slashes outside strings are not touched. Off by
+/// default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_escape_slashes(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.escape_slashes = value;
+        }
+    }
+}
+
+/// Set the minimal_escapes option.
+///
+/// Strings are written with the fewest escapes JSON allows: only `"`, `\\` and control
+/// characters stay escaped, so `"a\/b \u0041"` becomes `"a/b A"`. Non-ASCII characters
+/// stay escaped when `ensure_ascii` or `ascii_scope` asks for it.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_minimal_escapes(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.minimal_escapes = value;
+        }
+    }
+}
+
+/// Set the short_escapes option.
+///
+/// Control characters in strings, such as a tab pasted into `"x<TAB>y"`, are escaped as
+/// `\b \f \n \r \t` where JSON has a short form. When off, every control character is
+/// written as `\u00XX` (`"x\u0009y"`), including short escapes already in the input. On
+/// by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_short_escapes(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.short_escapes = value;
+        }
+    }
+}
+
+/// Set the strip_trailing_line_ws option.
+///
+/// Spaces and tabs at the end of each line inside multi-line string values are removed:
+/// `"a = 1;  \n  b"` becomes `"a = 1;\n  b"`. Only whitespace directly before a `\n` or
+/// `\r` is dropped; keys are not touched.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_strip_trailing_line_ws(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.strip_trailing_line_ws = value;
+        }
+    }
+}
+
+/// Set the collapse_ws option.
+///
+/// Each run of whitespace inside string values becomes a single space: `"a \t  b"` becomes
+/// `"a b"`. This changes the data, so only turn it on for prose such as scraped text. The
+/// ends of a string are not trimmed and keys are not touched. Line breaks are kept unless
+/// `jsonrepair_options_set_collapse_ws_newlines()` is on. Default: false.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_collapse_ws(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.collapse_ws = value;
+        }
+    }
+}
+
+/// Set the collapse_ws_newlines option.
+///
+/// With collapse_ws on, `\n` and `\r` join the whitespace run as well, so a multi-line value
+/// becomes one line. When off, each line break is kept and only the whitespace around it is
+/// collapsed. Default: false.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_collapse_ws_newlines(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.collapse_ws_newlines = value;
+        }
+    }
+}
+
+/// Set the add_missing_brackets option.
+///
+/// Wraps an input that is only a container body: `"a": 1, "b": 2` becomes
+/// `{"a":1,"b":2}` when the first token is a key followed by `:`, and `1, 2, 3`
+/// becomes `[1,2,3]` when it starts with a scalar and has a top-level comma.
+/// Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_add_missing_brackets(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.add_missing_brackets = value;
+        }
+    }
+}
+
+/// Set the parens_as_arrays option.
+///
+/// A parenthesized list in value position is read as an array: `(1, 2, 3)` becomes
+/// `[1,2,3]`. The argument list of a call such as `ObjectId("x")` is not converted.
+/// Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_parens_as_arrays(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.parens_as_arrays = value;
+        }
+    }
+}
+
+/// Whitespace between tokens (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairCompactSpacing {
+    /// No whitespace (default)
+    SpacingNone = 0,
+    /// One space after every `:` and `,`
+    SpacingMinimal = 1,
+}
+
+c_enum_from_raw!(JsonRepairCompactSpacing {
+    SpacingNone,
+    SpacingMinimal
+});
+
+/// Set the compact_spacing option.
+///
+/// `SPACING_MINIMAL` puts exactly one space after every `:` and `,` outside strings, so
+/// `{"a":[1,2]}` becomes `{"a": [1, 2]}`. Any other whitespace between tokens is removed,
+/// including the spacing of input that was already valid. `SPACING_NONE` keeps the
+/// current compact output.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_compact_spacing(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairCompactSpacing::from_raw(mode)
+        {
+            opts.compact_spacing = match mode {
+                JsonRepairCompactSpacing::SpacingNone => CompactSpacing::None,
+                JsonRepairCompactSpacing::SpacingMinimal => CompactSpacing::Minimal,
+            };
+        }
+    }
+}
+
+/// Set the indent_detect option.
+///
+/// Pretty-prints the output with the indentation unit of the input (a tab, four spaces,
+/// ...): the most common leading whitespace of the lines inside the top-level container.
+/// One-line input has no unit and stays compact. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_indent_detect(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.indent_detect = value;
+        }
+    }
+}
+
+/// Set the align_values option.
+///
+/// Together with `indent_detect`, pads the space after each `:` so the values of every
+/// object start in the same column, aligned per object on its longest key. Has no effect
+/// when the output is not indented or is JSON5. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_align_values(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.align_values = value;
+        }
+    }
+}
+
+/// Set the crlf option.
+///
+/// Ends the lines of pretty-printed output (`indent_detect`, and the pretty form of
+/// `jsonrepair_repair_both()`) with `\r\n` instead of `\n`. Newlines inside string values
+/// stay escaped, and compact output is not changed. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_crlf(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.crlf = value;
+        }
+    }
+}
+
+/// Set the concat_adjacent_strings option.
+///
+/// Joins string literals separated only by whitespace, newlines or comments, as in
+/// `"line1"\n"line2"` (read as `"line1line2"`). A following string that is an object key
+/// is left alone. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_concat_adjacent_strings(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.concat_adjacent_strings = value;
+        }
+    }
+}
+
+/// Progress callback (C API): called with the bytes parsed so far, the total input length
+/// and the `userdata` pointer given to `jsonrepair_options_set_progress_callback()`.
+pub type JsonRepairProgressFn =
+    Option<unsafe extern "C" fn(done: usize, total: usize, userdata: *mut c_void)>;
+
+// The C caller vouches for `userdata` being usable on the thread that runs the repair.
+struct CallbackUserdata(*mut c_void);
+unsafe impl Send for CallbackUserdata {}
+unsafe impl Sync for CallbackUserdata {}
+
+/// Set the progress callback.
+///
+/// During a repair `callback` is called at most about 100 times, with the number of input
+/// bytes parsed so far and the total, and once more with `done == total` when the repair
+/// succeeds. It runs on the thread that called the repair function. Pass NULL to remove
+/// it. Streams (`jsonrepair_stream_*`) do not report progress. Unset by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `userdata` must stay valid for as long as `opts` (or a copy made from it) is used
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_progress_callback(
+    opts: *mut Options,
+    callback: JsonRepairProgressFn,
+    userdata: *mut c_void,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            let userdata = CallbackUserdata(userdata);
+            opts.progress = callback.map(|f| {
+                Progress::new(move |done, total| {
+                    let userdata = &userdata;
+                    f(done, total, userdata.0)
+                })
+            });
+        }
+    }
+}
+
+/// Number format callback (C API): called with a number of the repaired output, such as
+/// `1.5`, and the `userdata` pointer given to `jsonrepair_options_set_number_format_callback()`.
+/// Returns the text to write instead, or NULL to keep the number. The returned string is
+/// copied right away, so it only has to stay valid until the callback is called again or
+/// the repair returns.
+pub type JsonRepairNumberFormatFn =
+    Option<unsafe extern "C" fn(raw: *const c_char, userdata: *mut c_void) -> *const c_char>;
+
+/// Set the number format callback.
+///
+/// Each number of the repaired output is passed to `callback` and replaced with what it
+/// returns, e.g. to write currency with two decimals. Quoted digits, keys and comments are
+/// not passed. A result that is not a JSON number fails the repair with a `PARSE` error.
+/// It runs on the thread that called the repair function. Pass NULL to remove it. Unset
+/// by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `userdata` must stay valid for as long as `opts` (or a copy made from it) is used
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_number_format_callback(
+    opts: *mut Options,
+    callback: JsonRepairNumberFormatFn,
+    userdata: *mut c_void,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            let userdata = CallbackUserdata(userdata);
+            opts.number_format = callback.map(|f| {
+                NumberFormat::new(move |raw| {
+                    let userdata = &userdata;
+                    // A number never holds a NUL byte.
+                    let c_raw = CString::new(raw).unwrap_or_default();
+                    let out = f(c_raw.as_ptr(), userdata.0);
+                    if out.is_null() {
+                        raw.to_string()
+                    } else {
+                        CStr::from_ptr(out).to_string_lossy().into_owned()
+                    }
+                })
+            });
+        }
+    }
+}
+
+/// Set the trace option.
+///
+/// A developer aid: while on, each repair made with `opts` (or a copy made from it) appends
+/// a line per parser decision to a trace kept with the options, such as
+/// `@5 repair: inserted missing comma`; read it with `jsonrepair_options_take_trace()`.
+/// Only the recursive engine records a trace, and the output is the same either way.
+/// Turning it off drops the trace. Default: false.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_trace(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            if value {
+                opts.trace.get_or_insert_with(Trace::new);
+            } else {
+                opts.trace = None;
+            }
+        }
+    }
+}
+
+/// Take the trace recorded with `opts` since the last call, one line per decision, and
+/// clear it.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL if `opts` is NULL or the trace option is off
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_take_trace(opts: *const Options) -> *mut c_char {
+    unsafe {
+        match opts.as_ref().and_then(|opts| opts.trace.as_ref()) {
+            Some(trace) => CString::new(trace.take())
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            None => ptr::null_mut(),
+        }
+    }
+}
+
+/// Set the max_elements option.
+///
+/// Repair aborts with `TOO_MANY_ELEMENTS` once a single array or object has more than `n`
+/// members, which bounds the work done on wide untrusted input. Pass 0 for no limit
+/// (default).
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_max_elements(opts: *mut Options, n: usize) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.max_elements = n;
+        }
+    }
+}
+
+/// Set the max_key_len option.
+///
+/// Repair aborts with `KEY_TOO_LONG` once an object key is longer than `n` bytes of UTF-8
+/// text, or cuts the key down when `jsonrepair_options_set_long_keys()` selects
+/// `LONG_KEYS_TRUNCATE`. Pass 0 for no limit (default).
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_max_key_len(opts: *mut Options, n: usize) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.max_key_len = n;
+        }
+    }
+}
+
+/// Set the max_input_bytes option.
+///
+/// Repair fails with `INPUT_TOO_LARGE` before any work is done when the input is longer
+/// than `n` bytes, to protect services from oversized requests. This bounds what goes in,
+/// unlike max_elements and max_key_len, which are checked while parsing. Pass 0 for no
+/// limit (default).
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_max_input_bytes(opts: *mut Options, n: usize) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.max_input_bytes = n;
+        }
+    }
+}
+
+/// What happens to a key longer than max_key_len (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairLongKeys {
+    /// Fail with `KEY_TOO_LONG` (default)
+    LongKeysError = 0,
+    /// Cut the key at the last UTF-8 character boundary within the limit
+    LongKeysTruncate = 1,
+}
+
+c_enum_from_raw!(JsonRepairLongKeys {
+    LongKeysError,
+    LongKeysTruncate
+});
+
+/// Set the long_keys option.
+///
+/// Selects whether a key longer than `max_key_len` fails the repair (`LONG_KEYS_ERROR`,
+/// default) or is cut down to fit (`LONG_KEYS_TRUNCATE`), never splitting a UTF-8
+/// character.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_long_keys(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairLongKeys::from_raw(mode)
+        {
+            opts.long_keys = match mode {
+                JsonRepairLongKeys::LongKeysError => LongKeyPolicy::Error,
+                JsonRepairLongKeys::LongKeysTruncate => LongKeyPolicy::Truncate,
+            };
+        }
+    }
+}
+
+/// Set the tolerate_sql_comments option.
+///
+/// Treats `--` followed by whitespace as a line comment, as in SQL-adjacent config files
+/// (`{a: 1 -- note}`). `--5` and `a--b` are left as values. Off by default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_tolerate_sql_comments(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.tolerate_sql_comments = value;
+        }
+    }
+}
+
+/// Numbers too large for a double (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairOverflow {
+    /// Keep the literal (default)
+    OverflowKeep = 0,
+    /// Quote the literal as a string
+    OverflowQuote = 1,
+    /// Replace the number with `null`
+    OverflowNull = 2,
+}
+
+c_enum_from_raw!(JsonRepairOverflow {
+    OverflowKeep,
+    OverflowQuote,
+    OverflowNull
+});
+
+/// Set the overflow option.
+///
+/// Controls numbers that overflow a double to infinity, such as `1e400`: `OVERFLOW_KEEP`
+/// leaves the literal as it is (default), `OVERFLOW_QUOTE` emits `"1e400"` so no digits
+/// are lost, and `OVERFLOW_NULL` emits `null`.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_overflow(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairOverflow::from_raw(mode)
+        {
+            opts.overflow = match mode {
+                JsonRepairOverflow::OverflowKeep => OverflowPolicy::Keep,
+                JsonRepairOverflow::OverflowQuote => OverflowPolicy::Quote,
+                JsonRepairOverflow::OverflowNull => OverflowPolicy::Null,
+            };
+        }
+    }
+}
+
+/// Numbers with a unit suffix such as `30s` (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairNumberSuffix {
+    /// Leave them to the general number rules (default)
+    SuffixKeep = 0,
+    /// Quote the whole token as a string
+    SuffixQuote = 1,
+    /// Keep the number and drop the suffix
+    SuffixStrip = 2,
+}
+
+c_enum_from_raw!(JsonRepairNumberSuffix {
+    SuffixKeep,
+    SuffixQuote,
+    SuffixStrip
+});
+
+/// Set the number_suffix option.
+///
+/// Controls numbers with an attached unit suffix of letters or `%`, as in
+/// `{"timeout": 30s, "size": 10MB}`: `SUFFIX_QUOTE` emits `"30s"` and `"10MB"`,
+/// `SUFFIX_STRIP` emits `30` and `10`, and `SUFFIX_KEEP` (default) applies the general
+/// number rules, which quote `30s` but split `100%` into `100` and a stray `%`.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_number_suffix(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairNumberSuffix::from_raw(mode)
+        {
+            opts.number_suffix = match mode {
+                JsonRepairNumberSuffix::SuffixKeep => NumberSuffixPolicy::Keep,
+                JsonRepairNumberSuffix::SuffixQuote => NumberSuffixPolicy::Quote,
+                JsonRepairNumberSuffix::SuffixStrip => NumberSuffixPolicy::Strip,
+            };
+        }
+    }
+}
+
+/// Quote numbers with a unit suffix (`30s` → `"30s"`, `100%` → `"100%"`).
+///
+/// Shorthand for `jsonrepair_options_set_number_suffix()` with `SUFFIX_QUOTE` when `value`
+/// is true and `SUFFIX_KEEP` when it is false.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_quote_suffixed_numbers(
+    opts: *mut Options,
+    value: bool,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.number_suffix = if value {
+                NumberSuffixPolicy::Quote
+            } else {
+                NumberSuffixPolicy::Keep
+            };
+        }
+    }
+}
+
+/// Sign handling for a zero such as `-0` (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairNegativeZero {
+    /// Keep the sign as written (default)
+    NegativeZeroPreserve = 0,
+    /// Drop the sign, keeping the rest of the spelling
+    NegativeZeroNormalize = 1,
+}
+
+c_enum_from_raw!(JsonRepairNegativeZero {
+    NegativeZeroPreserve,
+    NegativeZeroNormalize
+});
+
+/// Set the negative_zero option.
+///
+/// `NEGATIVE_ZERO_NORMALIZE` drops the minus sign of any zero (`-0` → `0`, `-0.0` → `0.0`,
+/// `-0e5` → `0e5`) so equal values hash the same; `NEGATIVE_ZERO_PRESERVE` (default) keeps
+/// it. `normalize_numbers` always emits `0` for negative zero.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_negative_zero(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairNegativeZero::from_raw(mode)
+        {
+            opts.negative_zero = match mode {
+                JsonRepairNegativeZero::NegativeZeroPreserve => NegativeZeroPolicy::Preserve,
+                JsonRepairNegativeZero::NegativeZeroNormalize => NegativeZeroPolicy::Normalize,
+            };
+        }
+    }
+}
+
+/// Integers beyond the JavaScript safe range (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairSafeIntegers {
+    /// Keep them as written (default)
+    SafeIntegersPassthrough = 0,
+    /// Clamp them to `±(2^53 - 1)`
+    SafeIntegersClamp = 1,
+    /// Quote them as strings
+    SafeIntegersQuote = 2,
+}
+
+c_enum_from_raw!(JsonRepairSafeIntegers {
+    SafeIntegersPassthrough,
+    SafeIntegersClamp,
+    SafeIntegersQuote
+});
+
+/// Set the safe_integers option.
+///
+/// Controls integer literals whose magnitude exceeds `2^53 - 1` (`9007199254740991`),
+/// which a JavaScript consumer would round: `SAFE_INTEGERS_CLAMP` replaces them with
+/// `9007199254740991` or `-9007199254740991`, `SAFE_INTEGERS_QUOTE` emits them as strings
+/// so every digit is kept, and `SAFE_INTEGERS_PASSTHROUGH` (default) leaves them alone.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_safe_integers(opts: *mut Options, mode: u32) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairSafeIntegers::from_raw(mode)
+        {
+            opts.safe_integers = match mode {
+                JsonRepairSafeIntegers::SafeIntegersPassthrough => SafeIntegerPolicy::Passthrough,
+                JsonRepairSafeIntegers::SafeIntegersClamp => SafeIntegerPolicy::Clamp,
+                JsonRepairSafeIntegers::SafeIntegersQuote => SafeIntegerPolicy::Quote,
+            };
+        }
+    }
+}
+
+/// How a typed wrapper is unwrapped (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairUnwrapMode {
+    /// Keep the argument as a string
+    UnwrapString = 0,
+    /// Make the argument a number
+    UnwrapNumber = 1,
+    /// Replace the call with `null`
+    UnwrapNull = 2,
+}
+
+c_enum_from_raw!(JsonRepairUnwrapMode {
+    UnwrapString,
+    UnwrapNumber,
+    UnwrapNull
+});
+
+/// Register a typed wrapper `name(arg)` to unwrap.
+///
+/// The call is replaced by its first argument: `UNWRAP_STRING` keeps it as a string
+/// (`UUID("ab")` → `"ab"`), `UNWRAP_NUMBER` makes a numeric string a number
+/// (`NumberLong("42")` → `42`, with `"NaN"` and `"Infinity"` handled per
+/// normalize_js_nonfinite) and `UNWRAP_NULL` emits `null`. Registering a name again
+/// replaces its mode. `ObjectId`, `ISODate`, `NumberInt`, `NumberLong`, `NumberDecimal` and
+/// `Decimal128` are registered by default. A NULL or non-UTF-8 `name` is ignored.
+/// An unknown `mode` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `name` must be a valid null-terminated string, or NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_add_unwrap_function(
+    opts: *mut Options,
+    name: *const c_char,
+    mode: u32,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(mode) = JsonRepairUnwrapMode::from_raw(mode)
+            && !name.is_null()
+            && let Ok(name) = CStr::from_ptr(name).to_str()
+        {
+            let mode = match mode {
+                JsonRepairUnwrapMode::UnwrapString => UnwrapMode::String,
+                JsonRepairUnwrapMode::UnwrapNumber => UnwrapMode::Number,
+                JsonRepairUnwrapMode::UnwrapNull => UnwrapMode::Null,
+            };
+            opts.add_unwrap_function(name, mode);
+        }
+    }
+}
+
+/// Remove every registered typed wrapper, including the built-in ones.
+///
+/// Calls such as `ObjectId("x")` are then repaired as bare text.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_clear_unwrap_functions(opts: *mut Options) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.unwrap_functions.clear();
+        }
+    }
+}
+
+/// Which brackets a keyword alias stands for (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairBracketKind {
+    /// `{` and `}`
+    BracketObject = 0,
+    /// `[` and `]`
+    BracketArray = 1,
+}
+
+c_enum_from_raw!(JsonRepairBracketKind {
+    BracketObject,
+    BracketArray
+});
+
+/// Register a keyword pair that stands for brackets.
+///
+/// Outside strings and comments, the whole words `open_word` and `close_word` are read as
+/// `{`/`}` (`BRACKET_OBJECT`) or `[`/`]` (`BRACKET_ARRAY`), so with `BEGIN`/`END` as an
+/// object, `BEGIN a: 1 END` becomes `{"a":1}`. A word used as a key is left alone.
+/// Registering an opening word again replaces its pair. A NULL, empty or non-UTF-8 word is
+/// ignored.
+/// An unknown `kind` is ignored.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+/// - `open_word` and `close_word` must be valid null-terminated strings, or NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_add_bracket_alias(
+    opts: *mut Options,
+    open_word: *const c_char,
+    close_word: *const c_char,
+    kind: u32,
+) {
+    unsafe {
+        if let Some(opts) = opts.as_mut()
+            && let Some(kind) = JsonRepairBracketKind::from_raw(kind)
+            && !open_word.is_null()
+            && !close_word.is_null()
+            && let (Ok(open), Ok(close)) = (
+                CStr::from_ptr(open_word).to_str(),
+                CStr::from_ptr(close_word).to_str(),
+            )
+            && !open.is_empty()
+            && !close.is_empty()
+        {
+            let kind = match kind {
+                JsonRepairBracketKind::BracketObject => BracketKind::Object,
+                JsonRepairBracketKind::BracketArray => BracketKind::Array,
+            };
+            opts.add_bracket_alias(open, close, kind);
+        }
+    }
+}
+
+/// Remove every registered bracket alias.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_clear_bracket_aliases(opts: *mut Options) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.bracket_aliases.clear();
+        }
+    }
+}
+
+/// Set the error_as_json option.
+///
+/// When enabled, `jsonrepair_repair_with_options()` and `jsonrepair_repair_ex()` return
+/// `{"error":"...","offset":N}` instead of NULL for input that cannot be repaired, so the
+/// result is always JSON. `jsonrepair_repair_ex()` still reports the error in `error`.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_error_as_json(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.error_as_json = value;
+        }
+    }
+}
+
+/// Set the strip_ellipsis option.
+///
+/// Drops bare `...` placeholders that mark omitted content (`[1, 2, ...]` becomes `[1,2]`,
+/// `{"a": 1, ...}` becomes `{"a":1}`). Quoted `"..."` strings and spread syntax such as
+/// `...rest` are left alone. When off, a bare `...` is kept as the string `"..."`. On by
+/// default.
+///
+/// # Safety
+/// - `opts` must be a valid pointer to Options
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_options_set_strip_ellipsis(opts: *mut Options, value: bool) {
+    unsafe {
+        if let Some(opts) = opts.as_mut() {
+            opts.strip_ellipsis = value;
+        }
+    }
+}
+
+// The `error_as_json` result for a failed repair: `{"error":"<message>","offset":N}`.
+fn error_object(err: &RepairError) -> *mut c_char {
+    let mut out = String::from("{\"error\":");
+    let _ = crate::parser::emit_json_string_from_lit(
+        &mut crate::emit::StringEmitter::new(&mut out),
+        &err.to_string(),
+        false,
+    );
+    out.push_str(&format!(",\"offset\":{}}}", err.position));
+    CString::new(out)
+        .unwrap_or_else(|_| CString::new("").unwrap())
+        .into_raw()
+}
+
+/// Repair a JSON string with custom options.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error, or an error object with `error_as_json`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_with_options(
+    input: *const c_char,
+    opts: *const Options,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(_) => return ptr::null_mut(),
+        };
+
+        match crate::repair_json(&c_str, options) {
+            Ok(result) => CString::new(result)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            Err(e) if options.error_as_json => error_object(&e),
+            Err(_) => ptr::null_mut(),
+        }
+    }
+}
+
+/// Repair a JSON string with error details.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error, or an error object with `error_as_json`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_ex(
+    input: *const c_char,
+    opts: *const Options,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::repair_json(&c_str, options) {
+            Ok(result) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                let out = if options.error_as_json {
+                    error_object(&e)
+                } else {
+                    ptr::null_mut()
+                };
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                out
+            }
+        }
+    }
+}
+
+/// Write callback (C API): called with `len` bytes of output at `data` and the `userdata`
+/// pointer given to `jsonrepair_repair_streaming_output()`. Return 0 to go on; any other
+/// value stops the repair.
+pub type JsonRepairWriteFn =
+    Option<unsafe extern "C" fn(data: *const c_char, len: usize, userdata: *mut c_void) -> c_int>;
+
+// Largest chunk passed to a write callback.
+const WRITE_CHUNK: usize = 64 * 1024;
+
+// `Write` adapter over a C write callback; longer writes are split into `WRITE_CHUNK`s.
+struct CallbackWriter {
+    write: unsafe extern "C" fn(data: *const c_char, len: usize, userdata: *mut c_void) -> c_int,
+    userdata: *mut c_void,
+}
+
+impl std::io::Write for CallbackWriter {
+    fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+        if buf.is_empty() {
+            return Ok(0);
+        }
+        let len = buf.len().min(WRITE_CHUNK);
+        match unsafe { (self.write)(buf.as_ptr().cast(), len, self.userdata) } {
+            0 => Ok(len),
+            rc => Err(std::io::Error::other(format!(
+                "write callback returned {rc}"
+            ))),
+        }
+    }
+    fn flush(&mut self) -> std::io::Result<()> {
+        Ok(())
+    }
+}
+
+/// Repair a single JSON input and hand the output to `write` in chunks as it is produced,
+/// so a huge value never has to be held in memory as one string.
+///
+/// Chunks are at most 64 KiB, are not NUL-terminated and may split a UTF-8 sequence. The
+/// recursive engine passes them on while the input is parsed. The LLM engine, `salvage`,
+/// and options that rewrite the finished output (such as `dedup_position`, `indent_detect`
+/// or JSON5 output) need the whole result first and pass it on at the end.
+///
+/// Backpressure: `write` runs on the calling thread and the repair waits for it to return,
+/// so a slow consumer slows the repair down rather than letting output pile up. Returning a
+/// non-zero value stops the repair with a `PARSE` error. After any failure the chunks
+/// already written are an incomplete document and should be discarded.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `write` must not be NULL; `data` is only valid during the call
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - Returns true on success, false on error
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_streaming_output(
+    input: *const c_char,
+    opts: *const Options,
+    write: JsonRepairWriteFn,
+    userdata: *mut c_void,
+    error: *mut JsonRepairError,
+) -> bool {
+    unsafe {
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            false
+        };
+        let Some(write) = write else {
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse("write is NULL".to_string()), 0),
+            );
+        };
+        if input.is_null() {
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse("Input is NULL".to_string()), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return false;
+            }
+        };
+        let mut writer = CallbackWriter { write, userdata };
+        match crate::repair_to_writer_streaming(&c_str, options, &mut writer) {
+            Ok(()) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                true
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair a JSON string and compute the SHA-256 digest of the result.
+///
+/// The digest covers the returned bytes exactly as emitted (excluding the
+/// terminating NUL).
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `hash_out` must point to at least 32 writable bytes, or be NULL to skip the digest
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error; `hash_out` is left untouched
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_hashed(
+    input: *const c_char,
+    opts: *const Options,
+    hash_out: *mut u8,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::repair_to_string_hashed(&c_str, options) {
+            Ok((result, hash)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                if !hash_out.is_null() {
+                    ptr::copy_nonoverlapping(hash.as_ptr(), hash_out, hash.len());
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// JSON type of a repaired document's root value (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairValueKind {
+    ValueKindObject = 0,
+    ValueKindArray = 1,
+    ValueKindString = 2,
+    ValueKindNumber = 3,
+    ValueKindBoolean = 4,
+    ValueKindNull = 5,
+    /// The input held no value, so the output is empty
+    ValueKindEmpty = 6,
+}
+
+/// Repair a JSON string and report the type of its root value.
+///
+/// The kind describes the output as emitted: several root values wrapped into one array
+/// report `VALUE_KIND_ARRAY`.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `kind` can be NULL to ignore the type
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error; `kind` is left untouched
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_with_kind(
+    input: *const c_char,
+    opts: *const Options,
+    kind: *mut JsonRepairValueKind,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::repair_to_string_with_kind(&c_str, options) {
+            Ok((result, value_kind)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                if !kind.is_null() {
+                    *kind = match value_kind {
+                        ValueKind::Object => JsonRepairValueKind::ValueKindObject,
+                        ValueKind::Array => JsonRepairValueKind::ValueKindArray,
+                        ValueKind::String => JsonRepairValueKind::ValueKindString,
+                        ValueKind::Number => JsonRepairValueKind::ValueKindNumber,
+                        ValueKind::Boolean => JsonRepairValueKind::ValueKindBoolean,
+                        ValueKind::Null => JsonRepairValueKind::ValueKindNull,
+                        ValueKind::Empty => JsonRepairValueKind::ValueKindEmpty,
+                    };
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// Repair a JSON string and report whether the output differs from the input.
+///
+/// `*changed` is set to false when the output is byte-for-byte the input (the default
+/// engine copies valid JSON through as written) and to true otherwise. Cheaper than
+/// computing the edits when only "was anything repaired?" matters.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `changed` can be NULL to ignore the flag
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error; `changed` is left untouched
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_changed(
+    input: *const c_char,
+    opts: *const Options,
+    changed: *mut bool,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::repair_to_string_changed(&c_str, options) {
+            Ok((result, differs)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                if !changed.is_null() {
+                    *changed = differs;
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// Repair a JSON string and return its type skeleton.
+///
+/// Every scalar is replaced by the name of its type (`"string"`, `"number"`, `"boolean"`,
+/// `"null"`), so `{a: 1, b: ['x']}` gives `{"a":"number","b":["string"]}`. An array lists
+/// the distinct skeletons of its elements once each, in order of first appearance.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_skeleton(
+    input: *const c_char,
+    opts: *const Options,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::repair_to_skeleton(&c_str, options) {
+            Ok(result) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// Repair a JSON string once and return it both minified and pretty-printed.
+///
+/// The compact form is returned and the pretty form, indented by `indent` spaces per
+/// level, is stored in `*pretty`. The pretty form is laid out from the compact one
+/// without parsing again, so both hold the same values.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `pretty` must be a valid pointer; on success `*pretty` must be freed with `jsonrepair_free()`
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error and sets `*pretty` to NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_both(
+    input: *const c_char,
+    indent: usize,
+    opts: *const Options,
+    pretty: *mut *mut c_char,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if !pretty.is_null() {
+            *pretty = ptr::null_mut();
+        }
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() || pretty.is_null() {
+            let what = if input.is_null() { "Input" } else { "pretty" };
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse(format!("{what} is NULL")), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_to_string_both(&c_str, indent, options) {
+            Ok((compact, pretty_out)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                *pretty = CString::new(pretty_out)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw();
+                CString::new(compact)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair a JSON string with its comments removed from the output and returned separately.
+///
+/// `*comments` receives a JSON object mapping the JSON Pointer of each commented value to
+/// the comment text (`{"/name":"who","/age":"years"}`), in document order. A comment
+/// belongs to the value that starts before it on the same line, otherwise to the next
+/// value; several comments of one value are joined with `\n`, and leading comments of the
+/// document use the key `""`. Always uses the recursive engine.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `comments` must be a valid pointer; on success `*comments` must be freed with `jsonrepair_free()`
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error and sets `*comments` to NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_with_comments(
+    input: *const c_char,
+    opts: *const Options,
+    comments: *mut *mut c_char,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if !comments.is_null() {
+            *comments = ptr::null_mut();
+        }
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() || comments.is_null() {
+            let what = if input.is_null() { "Input" } else { "comments" };
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse(format!("{what} is NULL")), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_with_comments(&c_str, options) {
+            Ok((out, found)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                let push = |map: &mut String, s: &str| {
+                    let _ = crate::parser::emit_json_string_from_lit(
+                        &mut crate::emit::StringEmitter::new(map),
+                        s,
+                        false,
+                    );
+                };
+                let mut map = String::from("{");
+                for (i, (pointer, text)) in found.iter().enumerate() {
+                    if i > 0 {
+                        map.push(',');
+                    }
+                    push(&mut map, pointer);
+                    map.push(':');
+                    push(&mut map, text);
+                }
+                map.push('}');
+                *comments = CString::new(map)
+                    .unwrap_or_else(|_| CString::new("{}").unwrap())
+                    .into_raw();
+                CString::new(out)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair a JSON string and list the paths of the values it repaired.
+///
+/// `*paths` receives one path per repaired value, written as in the repair log
+/// (`$["items"][2]["name"]`), listed once each in the order of their first repair. A
+/// repair to a container's own syntax (a key, comma or bracket) gives the container's
+/// path. Valid input gives an empty list, as does a build without the `logging` feature.
+/// Always uses the recursive engine.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `paths` must be a valid pointer; on success `*paths` must be freed with `jsonrepair_string_list_free()`
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error and sets `*paths` to NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_affected_paths(
+    input: *const c_char,
+    opts: *const Options,
+    paths: *mut *mut JsonRepairStringList,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if !paths.is_null() {
+            *paths = ptr::null_mut();
+        }
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() || paths.is_null() {
+            let what = if input.is_null() { "Input" } else { "paths" };
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse(format!("{what} is NULL")), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_affected_paths(&c_str, options) {
+            Ok((out, found)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                *paths = string_list(found);
+                CString::new(out)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair a JSON string and return only the value at a JSON Pointer.
+///
+/// `pointer` follows RFC 6901 (`"/result/0"`; `""` selects the whole document).
+/// A missing target fails with `POINTER_NOT_FOUND`.
+///
+/// # Safety
+/// - `input` and `pointer` must be valid null-terminated UTF-8 strings
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_extract(
+    input: *const c_char,
+    pointer: *const c_char,
+    opts: *const Options,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() || pointer.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        let (c_str, c_pointer) =
+            match (input_text(input, options), CStr::from_ptr(pointer).to_str()) {
+                (Ok(s), Ok(p)) => (s, p),
+                (Err(e), _) | (_, Err(e)) => {
+                    if !error.is_null() {
+                        *error = JsonRepairError::invalid_utf8(e);
+                    }
+                    return ptr::null_mut();
+                }
+            };
+
+        match crate::repair_extract(&c_str, c_pointer, options) {
+            Ok(result) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// List of strings returned by `jsonrepair_repair_split()` and
+/// `jsonrepair_list_repair_categories()`.
+#[repr(C)]
+pub struct JsonRepairStringList {
+    pub items: *mut *mut c_char,
+    pub len: usize,
+}
+
+// Move `values` into a heap list owned by the caller (`jsonrepair_string_list_free`).
+fn string_list<S: Into<Vec<u8>>>(values: impl IntoIterator<Item = S>) -> *mut JsonRepairStringList {
+    let items: Box<[*mut c_char]> = values
+        .into_iter()
+        .map(|v| {
+            CString::new(v)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw()
+        })
+        .collect();
+    let len = items.len();
+    Box::into_raw(Box::new(JsonRepairStringList {
+        items: Box::into_raw(items) as *mut *mut c_char,
+        len,
+    }))
+}
+
+/// Repair a document of concatenated root values and return each value separately.
+///
+/// `{a:1}{b:2}[1,2]` gives three strings: `{"a":1}`, `{"b":2}` and `[1,2]`. Values
+/// may be separated by whitespace, newlines, commas or nothing.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - Returns NULL on error; otherwise a list (possibly empty) that must be freed with
+///   `jsonrepair_string_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_split(
+    input: *const c_char,
+    opts: *const Options,
+    error: *mut JsonRepairError,
+) -> *mut JsonRepairStringList {
+    unsafe {
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() {
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse("Input is NULL".to_string()), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_split(&c_str, options) {
+            Ok(values) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                string_list(values)
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair only the first root value of `input` and report how many input bytes it used.
+///
+/// `{a: 1} junk` gives `{"a":1}` with `*consumed` set to 6. The count includes leading
+/// whitespace and comments; nothing after the value is parsed.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `consumed` can be NULL to ignore the byte count; it is set to 0 on error
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error, including input with no value
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_first(
+    input: *const c_char,
+    opts: *const Options,
+    consumed: *mut usize,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if !consumed.is_null() {
+            *consumed = 0;
+        }
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() {
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse("Input is NULL".to_string()), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_first(&c_str, options) {
+            Ok((value, used)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                if !consumed.is_null() {
+                    *consumed = used;
+                }
+                CString::new(value)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Repair a JSON string and report the byte offset where its last root value ends.
+///
+/// `{a: 1} {b: 2} <EOF>` gives `[{"a":1},{"b":2}]` with `*end_offset` set to 13, so a
+/// reader can advance past the values it got. Trailing data the repair ignores is not
+/// counted; with the stop_after_first option the offset is the end of the first value, as
+/// with `jsonrepair_repair_first()`. Input with no value gives 0.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `end_offset` can be NULL to ignore the offset; it is set to 0 on error
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_with_end(
+    input: *const c_char,
+    opts: *const Options,
+    end_offset: *mut usize,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if !end_offset.is_null() {
+            *end_offset = 0;
+        }
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        if input.is_null() {
+            return fail(
+                error,
+                RepairError::new(RepairErrorKind::Parse("Input is NULL".to_string()), 0),
+            );
+        }
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+        let c_str = match input_text(input, options) {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match crate::repair_to_string_with_end(&c_str, options) {
+            Ok((value, end)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                if !end_offset.is_null() {
+                    *end_offset = end;
+                }
+                CString::new(value)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Free a string list, including all of its strings.
+///
+/// # Safety
+/// - `list` must be a pointer returned by `jsonrepair_repair_split()` or
+///   `jsonrepair_list_repair_categories()`, or NULL
+/// - Do not use `list` after calling this function
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_string_list_free(list: *mut JsonRepairStringList) {
+    unsafe {
+        if list.is_null() {
+            return;
+        }
+        let list = Box::from_raw(list);
+        let items = Box::from_raw(ptr::slice_from_raw_parts_mut(list.items, list.len));
+        for &item in items.iter() {
+            jsonrepair_free(item);
+        }
+    }
+}
+
+// ============================================================================
+// UTF-16 Input API
+// ============================================================================
+
+/// Byte order for BOM-less UTF-16 input (C API)
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairUtf16Endian {
+    Utf16Le = 0,
+    Utf16Be = 1,
+}
+
+c_enum_from_raw!(JsonRepairUtf16Endian { Utf16Le, Utf16Be });
+
+/// Repair UTF-16 input of `len` bytes and return UTF-8 output.
+///
+/// A leading BOM selects the byte order; otherwise `endian` (a `JsonRepairUtf16Endian`)
+/// is used. An unknown `endian` fails with `PARSE`.
+///
+/// # Safety
+/// - `input` must point to at least `len` readable bytes (it need not be NUL-terminated)
+/// - `opts` must be a valid pointer to Options, or NULL for defaults
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error, including malformed UTF-16
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_repair_utf16(
+    input: *const u8,
+    len: usize,
+    endian: u32,
+    opts: *const Options,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() && len > 0 {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let bytes = if len == 0 {
+            &[][..]
+        } else {
+            std::slice::from_raw_parts(input, len)
+        };
+        let endian = match JsonRepairUtf16Endian::from_raw(endian) {
+            Some(JsonRepairUtf16Endian::Utf16Le) => Utf16Endian::Little,
+            Some(JsonRepairUtf16Endian::Utf16Be) => Utf16Endian::Big,
+            None => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(RepairError::new(
+                        RepairErrorKind::Parse(format!("Unknown byte order {endian}")),
+                        0,
+                    ));
+                }
+                return ptr::null_mut();
+            }
+        };
+        let options = if opts.is_null() {
+            &Options::default()
+        } else {
+            &*opts
+        };
+
+        match crate::repair_utf16(bytes, endian, options) {
+            Ok(result) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+// ============================================================================
+// Minify API
+// ============================================================================
+
+/// Minify a string that must already be valid JSON.
+///
+/// Invalid input is rejected rather than repaired. Use `jsonrepair_minify_ex()`
+/// to get the error position.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error (including invalid JSON)
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_minify(input: *const c_char) -> *mut c_char {
+    unsafe { jsonrepair_minify_ex(input, ptr::null_mut()) }
+}
+
+/// Minify a string that must already be valid JSON, with error details.
+///
+/// # Safety
+/// - `input` must be a valid null-terminated UTF-8 string
+/// - `error` can be NULL to ignore error details
+/// - If `error` is not NULL and an error occurs, `error.message` must be freed with `jsonrepair_free()`
+/// - The returned string must be freed with `jsonrepair_free()`
+/// - Returns NULL on error; invalid JSON is reported as `INVALID_JSON`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_minify_ex(
+    input: *const c_char,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if input.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("Input is NULL".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let c_str = match CStr::from_ptr(input).to_str() {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match crate::minify(c_str) {
+            Ok(result) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+// ============================================================================
+// Streaming API
+// ============================================================================
+
+/// Create a new streaming repairer.
+///
+/// # Safety
+/// - `opts` can be NULL for default options
+/// - Must be freed with `jsonrepair_stream_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_new(opts: *const Options) -> *mut StreamRepairer {
+    unsafe {
+        let options = if opts.is_null() {
+            Options::default()
+        } else {
+            (*opts).clone()
+        };
+
+        Box::into_raw(Box::new(StreamRepairer::new(options)))
+    }
+}
+
+/// Free a streaming repairer.
+///
+/// # Safety
+/// - `stream` must be a pointer returned by `jsonrepair_stream_new()`, or NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_free(stream: *mut StreamRepairer) {
+    unsafe {
+        if !stream.is_null() {
+            drop(Box::from_raw(stream));
+        }
+    }
+}
+
+/// Push a chunk to the streaming repairer.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - `chunk` must be a valid null-terminated UTF-8 string
+/// - Returns NULL if no complete value yet, or a string that must be freed with `jsonrepair_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_push(
+    stream: *mut StreamRepairer,
+    chunk: *const c_char,
+) -> *mut c_char {
+    unsafe {
+        if stream.is_null() || chunk.is_null() {
+            return ptr::null_mut();
+        }
+
+        let stream = match stream.as_mut() {
+            Some(s) => s,
+            None => return ptr::null_mut(),
+        };
+
+        let c_str = match CStr::from_ptr(chunk).to_str() {
+            Ok(s) => s,
+            Err(_) => return ptr::null_mut(),
+        };
+
+        match stream.push(c_str) {
+            Ok(Some(result)) => CString::new(result)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            Ok(None) => ptr::null_mut(),
+            Err(_) => ptr::null_mut(),
+        }
+    }
+}
+
+/// Flush the streaming repairer.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_flush(stream: *mut StreamRepairer) -> *mut c_char {
+    unsafe {
+        if stream.is_null() {
+            return ptr::null_mut();
+        }
+
+        let stream = match stream.as_mut() {
+            Some(s) => s,
+            None => return ptr::null_mut(),
+        };
+
+        match stream.flush() {
+            Ok(Some(result)) => CString::new(result)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            Ok(None) => ptr::null_mut(),
+            Err(_) => ptr::null_mut(),
+        }
+    }
+}
+
+/// Push a chunk with error handling.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - `chunk` must be a valid null-terminated UTF-8 string
+/// - `error` can be NULL to ignore error details
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_push_ex(
+    stream: *mut StreamRepairer,
+    chunk: *const c_char,
     error: *mut JsonRepairError,
 ) -> *mut c_char {
     unsafe {
@@ -557,9 +3567,65 @@ pub unsafe extern "C" fn jsonrepair_stream_push_ex(
         let c_str = match CStr::from_ptr(chunk).to_str() {
             Ok(s) => s,
             Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+
+        match stream.push(c_str) {
+            Ok(Some(result)) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                CString::new(result)
+                    .unwrap_or_else(|_| CString::new("").unwrap())
+                    .into_raw()
+            }
+            Ok(None) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                ptr::null_mut()
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// Flush with error handling.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - `error` can be NULL to ignore error details
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_flush_ex(
+    stream: *mut StreamRepairer,
+    error: *mut JsonRepairError,
+) -> *mut c_char {
+    unsafe {
+        if stream.is_null() {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(RepairError::new(
+                    RepairErrorKind::Parse("NULL pointer".to_string()),
+                    0,
+                ));
+            }
+            return ptr::null_mut();
+        }
+
+        let stream = match stream.as_mut() {
+            Some(s) => s,
+            None => {
                 if !error.is_null() {
                     *error = JsonRepairError::from_repair_error(RepairError::new(
-                        RepairErrorKind::Parse(format!("Invalid UTF-8: {}", e)),
+                        RepairErrorKind::Parse("Invalid stream pointer".to_string()),
                         0,
                     ));
                 }
@@ -567,7 +3633,7 @@ pub unsafe extern "C" fn jsonrepair_stream_push_ex(
             }
         };
 
-        match stream.push(c_str) {
+        match stream.flush() {
             Ok(Some(result)) => {
                 if !error.is_null() {
                     *error = JsonRepairError::ok();
@@ -592,33 +3658,29 @@ pub unsafe extern "C" fn jsonrepair_stream_push_ex(
     }
 }
 
-/// Flush with error handling.
+/// Flush the stream and reset it for the next document, in one call.
+///
+/// Equivalent to `jsonrepair_stream_flush_ex` followed by replacing the stream with a new
+/// one built from the same options; the recover, max-buffer and range-tracking settings are
+/// kept. Skipped regions and ranges not taken yet survive the reset; stats start over. The
+/// stream is reset even when the flush fails.
 ///
 /// # Safety
 /// - `stream` must be a valid pointer to StreamRepairer
 /// - `error` can be NULL to ignore error details
+/// - Returns NULL if no data, or a string that must be freed with `jsonrepair_free()`
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn jsonrepair_stream_flush_ex(
+pub unsafe extern "C" fn jsonrepair_stream_finalize(
     stream: *mut StreamRepairer,
     error: *mut JsonRepairError,
 ) -> *mut c_char {
     unsafe {
-        if stream.is_null() {
-            if !error.is_null() {
-                *error = JsonRepairError::from_repair_error(RepairError::new(
-                    RepairErrorKind::Parse("NULL pointer".to_string()),
-                    0,
-                ));
-            }
-            return ptr::null_mut();
-        }
-
         let stream = match stream.as_mut() {
             Some(s) => s,
             None => {
                 if !error.is_null() {
                     *error = JsonRepairError::from_repair_error(RepairError::new(
-                        RepairErrorKind::Parse("Invalid stream pointer".to_string()),
+                        RepairErrorKind::Parse("NULL pointer".to_string()),
                         0,
                     ));
                 }
@@ -626,7 +3688,7 @@ pub unsafe extern "C" fn jsonrepair_stream_flush_ex(
             }
         };
 
-        match stream.flush() {
+        match stream.finalize() {
             Ok(Some(result)) => {
                 if !error.is_null() {
                     *error = JsonRepairError::ok();
@@ -651,6 +3713,458 @@ pub unsafe extern "C" fn jsonrepair_stream_flush_ex(
     }
 }
 
+// ============================================================================
+// Stream Validation API
+// ============================================================================
+
+/// Validation status of one completed root value.
+#[repr(C)]
+pub struct JsonRepairValueStatus {
+    pub valid: bool,
+    /// The value's source text, unchanged
+    pub text: *mut c_char,
+    /// Error details when `valid` is false (`code` is `OK` otherwise)
+    pub error: JsonRepairError,
+}
+
+/// List of value statuses returned by the stream validation API.
+#[repr(C)]
+pub struct JsonRepairValueStatusList {
+    pub items: *mut JsonRepairValueStatus,
+    pub len: usize,
+}
+
+fn status_list_into_raw(statuses: Vec<ValueStatus>) -> *mut JsonRepairValueStatusList {
+    let items: Box<[JsonRepairValueStatus]> = statuses
+        .into_iter()
+        .map(|st| JsonRepairValueStatus {
+            valid: st.valid,
+            text: CString::new(st.text)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            error: st
+                .error
+                .map(JsonRepairError::from_repair_error)
+                .unwrap_or_else(JsonRepairError::ok),
+        })
+        .collect();
+    let len = items.len();
+    Box::into_raw(Box::new(JsonRepairValueStatusList {
+        items: Box::into_raw(items) as *mut JsonRepairValueStatus,
+        len,
+    }))
+}
+
+/// Push a chunk in validate-only mode and return the status of each value it completes.
+///
+/// Values are checked against strict JSON and reported unchanged; nothing is repaired.
+/// The stream switches to validate-only mode on the first call.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - `chunk` must be a valid null-terminated UTF-8 string
+/// - `error` can be NULL to ignore error details
+/// - Returns NULL on error; otherwise a list (possibly empty) that must be freed with
+///   `jsonrepair_value_status_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_push_validate(
+    stream: *mut StreamRepairer,
+    chunk: *const c_char,
+    error: *mut JsonRepairError,
+) -> *mut JsonRepairValueStatusList {
+    unsafe {
+        let fail = |error: *mut JsonRepairError, e: RepairError| {
+            if !error.is_null() {
+                *error = JsonRepairError::from_repair_error(e);
+            }
+            ptr::null_mut()
+        };
+        let stream = match stream.as_mut() {
+            Some(s) if !chunk.is_null() => s,
+            _ => {
+                return fail(
+                    error,
+                    RepairError::new(RepairErrorKind::Parse("NULL pointer".to_string()), 0),
+                );
+            }
+        };
+        let c_str = match CStr::from_ptr(chunk).to_str() {
+            Ok(s) => s,
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::invalid_utf8(e);
+                }
+                return ptr::null_mut();
+            }
+        };
+        match stream.push_validate(c_str) {
+            Ok(statuses) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                status_list_into_raw(statuses)
+            }
+            Err(e) => fail(error, e),
+        }
+    }
+}
+
+/// Flush a stream in validate-only mode and return the remaining statuses.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - `error` can be NULL to ignore error details
+/// - Returns NULL on error; otherwise a list that must be freed with
+///   `jsonrepair_value_status_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_flush_validate(
+    stream: *mut StreamRepairer,
+    error: *mut JsonRepairError,
+) -> *mut JsonRepairValueStatusList {
+    unsafe {
+        let result = match stream.as_mut() {
+            Some(s) => s.flush_validate(),
+            None => Err(RepairError::new(
+                RepairErrorKind::Parse("NULL pointer".to_string()),
+                0,
+            )),
+        };
+        match result {
+            Ok(statuses) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::ok();
+                }
+                status_list_into_raw(statuses)
+            }
+            Err(e) => {
+                if !error.is_null() {
+                    *error = JsonRepairError::from_repair_error(e);
+                }
+                ptr::null_mut()
+            }
+        }
+    }
+}
+
+/// Free a status list, including all of its strings.
+///
+/// # Safety
+/// - `list` must be a pointer returned by the stream validation API, or NULL
+/// - Do not use `list` after calling this function
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_value_status_list_free(list: *mut JsonRepairValueStatusList) {
+    unsafe {
+        if list.is_null() {
+            return;
+        }
+        let list = Box::from_raw(list);
+        let items = Box::from_raw(ptr::slice_from_raw_parts_mut(list.items, list.len));
+        for st in items.iter() {
+            jsonrepair_free(st.text);
+            jsonrepair_free(st.error.message);
+        }
+    }
+}
+
+// ============================================================================
+// Stream Recovery API
+// ============================================================================
+
+/// Enable or disable error recovery on a stream.
+///
+/// With recovery on, a root value that cannot be repaired is skipped, together
+/// with root-level input up to the next `{` or `[`, instead of failing the push.
+/// Collect the skipped regions with `jsonrepair_stream_take_skipped()`.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_set_recover(stream: *mut StreamRepairer, value: bool) {
+    unsafe {
+        if let Some(stream) = stream.as_mut() {
+            stream.set_recover(value);
+        }
+    }
+}
+
+/// Take the regions skipped in recovery mode since the last call.
+///
+/// Each item has `valid == false`, the skipped source text, and the error of
+/// the value that could not be repaired.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+///   freed with `jsonrepair_value_status_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_take_skipped(
+    stream: *mut StreamRepairer,
+) -> *mut JsonRepairValueStatusList {
+    unsafe {
+        match stream.as_mut() {
+            Some(stream) => status_list_into_raw(stream.take_skipped()),
+            None => ptr::null_mut(),
+        }
+    }
+}
+
+/// Cap the bytes a stream may buffer for a value that has not completed yet.
+///
+/// `bytes == 0` (the default) means unlimited. A push that leaves more than `bytes`
+/// buffered fails with `BUFFER_OVERFLOW` and discards the incomplete value; values
+/// completed earlier in the same chunk are returned by the next push or flush.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_set_max_buffer(
+    stream: *mut StreamRepairer,
+    bytes: usize,
+) {
+    unsafe {
+        if let Some(stream) = stream.as_mut() {
+            stream.set_max_buffer(bytes);
+        }
+    }
+}
+
+// ============================================================================
+// Stream Value Ranges API
+// ============================================================================
+
+/// One repaired value and the input bytes it came from.
+#[repr(C)]
+pub struct JsonRepairValueRange {
+    /// The repaired value
+    pub value: *mut c_char,
+    /// Byte offset of the value's first source byte, counted from the start of the stream
+    pub start: usize,
+    /// Byte offset just past the value's last source byte
+    pub end: usize,
+}
+
+/// List of value ranges returned by `jsonrepair_stream_take_ranges()`.
+#[repr(C)]
+pub struct JsonRepairValueRangeList {
+    pub items: *mut JsonRepairValueRange,
+    pub len: usize,
+}
+
+fn range_list_into_raw(ranges: Vec<ValueRange>) -> *mut JsonRepairValueRangeList {
+    let items: Box<[JsonRepairValueRange]> = ranges
+        .into_iter()
+        .map(|r| JsonRepairValueRange {
+            value: CString::new(r.value)
+                .unwrap_or_else(|_| CString::new("").unwrap())
+                .into_raw(),
+            start: r.start,
+            end: r.end,
+        })
+        .collect();
+    let len = items.len();
+    Box::into_raw(Box::new(JsonRepairValueRangeList {
+        items: Box::into_raw(items) as *mut JsonRepairValueRange,
+        len,
+    }))
+}
+
+/// Enable or disable recording the input byte range of each repaired value.
+///
+/// Offsets count bytes from the start of the stream, across all pushed chunks,
+/// and leave out whitespace and comments around the value. Collect them with
+/// `jsonrepair_stream_take_ranges()`.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_set_track_ranges(
+    stream: *mut StreamRepairer,
+    value: bool,
+) {
+    unsafe {
+        if let Some(stream) = stream.as_mut() {
+            stream.set_track_ranges(value);
+        }
+    }
+}
+
+/// Take the value ranges recorded since the last call, in stream order.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+///   freed with `jsonrepair_value_range_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_take_ranges(
+    stream: *mut StreamRepairer,
+) -> *mut JsonRepairValueRangeList {
+    unsafe {
+        match stream.as_mut() {
+            Some(stream) => range_list_into_raw(stream.take_ranges()),
+            None => ptr::null_mut(),
+        }
+    }
+}
+
+/// Free a range list, including all of its strings.
+///
+/// # Safety
+/// - `list` must be a pointer returned by `jsonrepair_stream_take_ranges()`, or NULL
+/// - Do not use `list` after calling this function
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_value_range_list_free(list: *mut JsonRepairValueRangeList) {
+    unsafe {
+        if list.is_null() {
+            return;
+        }
+        let list = Box::from_raw(list);
+        let items = Box::from_raw(ptr::slice_from_raw_parts_mut(list.items, list.len));
+        for r in items.iter() {
+            jsonrepair_free(r.value);
+        }
+    }
+}
+
+// ============================================================================
+// Stream Heartbeat API
+// ============================================================================
+
+/// Keep-alive status for a stretch of input in which no value completed.
+#[repr(C)]
+#[derive(Debug, Clone, Copy, Default)]
+pub struct JsonRepairHeartbeat {
+    /// Input bytes pushed so far, counted from the start of the stream
+    pub bytes_in: u64,
+    /// Bytes buffered for the value that has not completed yet
+    pub buffered: usize,
+}
+
+/// List of heartbeats returned by `jsonrepair_stream_take_heartbeats()`.
+#[repr(C)]
+pub struct JsonRepairHeartbeatList {
+    pub items: *mut JsonRepairHeartbeat,
+    pub len: usize,
+}
+
+/// Report that the stream is alive every `interval_bytes` input bytes pushed
+/// without a value completing (0, the default, turns reports off).
+///
+/// A report is recorded at the end of a push once at least `interval_bytes` have
+/// arrived since the last completed value or report, at most one per push. Collect
+/// them with `jsonrepair_stream_take_heartbeats()`; the repaired output is unchanged.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_set_heartbeat(
+    stream: *mut StreamRepairer,
+    interval_bytes: usize,
+) {
+    unsafe {
+        if let Some(stream) = stream.as_mut() {
+            stream.set_heartbeat(interval_bytes);
+        }
+    }
+}
+
+/// Take the heartbeats recorded since the last call, in stream order.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - Returns NULL if `stream` is NULL; otherwise a list (possibly empty) that must be
+///   freed with `jsonrepair_heartbeat_list_free()`
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_take_heartbeats(
+    stream: *mut StreamRepairer,
+) -> *mut JsonRepairHeartbeatList {
+    unsafe {
+        let Some(stream) = stream.as_mut() else {
+            return ptr::null_mut();
+        };
+        let items: Box<[JsonRepairHeartbeat]> = stream
+            .take_heartbeats()
+            .into_iter()
+            .map(|h| JsonRepairHeartbeat {
+                bytes_in: h.bytes_in,
+                buffered: h.buffered,
+            })
+            .collect();
+        let len = items.len();
+        Box::into_raw(Box::new(JsonRepairHeartbeatList {
+            items: Box::into_raw(items) as *mut JsonRepairHeartbeat,
+            len,
+        }))
+    }
+}
+
+/// Free a heartbeat list.
+///
+/// # Safety
+/// - `list` must be a pointer returned by `jsonrepair_stream_take_heartbeats()`, or NULL
+/// - Do not use `list` after calling this function
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_heartbeat_list_free(list: *mut JsonRepairHeartbeatList) {
+    unsafe {
+        if list.is_null() {
+            return;
+        }
+        let list = Box::from_raw(list);
+        drop(Box::from_raw(ptr::slice_from_raw_parts_mut(
+            list.items, list.len,
+        )));
+    }
+}
+
+// ============================================================================
+// Stream Statistics API
+// ============================================================================
+
+/// Totals for a streaming session, returned by `jsonrepair_stream_stats()`.
+#[repr(C)]
+#[derive(Debug, Clone, Copy, Default)]
+pub struct JsonRepairStreamStats {
+    /// Root values repaired and emitted
+    pub values: u64,
+    /// Repairs applied to those values, as counted for max_repairs
+    pub repairs: u64,
+    /// Input bytes pushed so far
+    pub bytes_in: u64,
+    /// Bytes of repaired values emitted, without the BOM or aggregation brackets
+    pub bytes_out: u64,
+}
+
+impl From<StreamStats> for JsonRepairStreamStats {
+    fn from(s: StreamStats) -> Self {
+        Self {
+            values: s.values,
+            repairs: s.repairs,
+            bytes_in: s.bytes_in,
+            bytes_out: s.bytes_out,
+        }
+    }
+}
+
+/// Get the totals of a streaming session so far.
+///
+/// Counts cover every push and flush since the stream was created. Values dropped
+/// in recovery mode and validate-only statuses are not counted, and values repaired
+/// by the LLM-compatible engine add no repairs.
+///
+/// # Safety
+/// - `stream` must be a valid pointer to StreamRepairer
+/// - Returns all zeros if `stream` is NULL
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn jsonrepair_stream_stats(
+    stream: *const StreamRepairer,
+) -> JsonRepairStreamStats {
+    unsafe {
+        match stream.as_ref() {
+            Some(stream) => stream.stats().into(),
+            None => JsonRepairStreamStats::default(),
+        }
+    }
+}
+
 // ============================================================================
 // Version Info
 // ============================================================================
@@ -670,3 +4184,237 @@ pub extern "C" fn jsonrepair_version() -> *const c_char {
     };
     VERSION.as_ptr()
 }
+
+/// Describe this build as a JSON object, for bug reports (C API).
+///
+/// ```json
+/// {"version":"0.1.0",
+///  "features":{"serde":true,"logging":true,"llm_compat":false,"c_api":true},
+///  "target":{"arch":"x86_64","os":"linux","wasm":false,"pointer_width":64},
+///  "max_depth":null,"unwrap_escaped_max_depth":8}
+/// ```
+///
+/// `features` holds the Cargo features the library was compiled with. `max_depth` is the
+/// deepest nesting the parsers accept, `null` when there is no fixed limit (only the stack
+/// bounds it), and `unwrap_escaped_max_depth` the number of string-escaping layers
+/// `unwrap_escaped_json` removes. Keys may be added in later versions, never removed.
+///
+/// The string is built on the first call. Returns a process-static, read-only string
+/// pointer; callers must not free it.
+#[unsafe(no_mangle)]
+pub extern "C" fn jsonrepair_features() -> *const c_char {
+    static FEATURES: std::sync::OnceLock<CString> = std::sync::OnceLock::new();
+    FEATURES
+        .get_or_init(|| {
+            let json = format!(
+                concat!(
+                    r#"{{"version":"{}","#,
+                    r#""features":{{"serde":{},"logging":{},"llm_compat":{},"c_api":{}}},"#,
+                    r#""target":{{"arch":"{}","os":"{}","wasm":{},"pointer_width":{}}},"#,
+                    r#""max_depth":null,"unwrap_escaped_max_depth":{}}}"#
+                ),
+                env!("CARGO_PKG_VERSION"),
+                cfg!(feature = "serde"),
+                cfg!(feature = "logging"),
+                cfg!(feature = "llm-compat"),
+                cfg!(feature = "c-api"),
+                std::env::consts::ARCH,
+                std::env::consts::OS,
+                cfg!(target_family = "wasm"),
+                usize::BITS,
+                crate::repair::UNWRAP_ESCAPED_MAX_DEPTH,
+            );
+            CString::new(json).unwrap_or_default()
+        })
+        .as_ptr()
+}
+
+/// List the repair categories this build can log, the same names that appear in
+/// `RepairLogEntry::message`.
+///
+/// Tools that explain repairs can use this instead of hardcoding the names. The list must be
+/// freed with `jsonrepair_string_list_free()`.
+#[unsafe(no_mangle)]
+pub extern "C" fn jsonrepair_list_repair_categories() -> *mut JsonRepairStringList {
+    string_list(crate::repair_categories().iter().copied())
+}
+
+/// Option ids for `jsonrepair_option_supported()` (C API), one per options setter
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum JsonRepairOption {
+    /// `jsonrepair_options_set_ensure_ascii()`
+    OptionEnsureAscii = 0,
+    /// `jsonrepair_options_set_allow_python_keywords()`
+    OptionAllowPythonKeywords = 1,
+    /// `jsonrepair_options_set_tolerate_hash_comments()`
+    OptionTolerateHashComments = 2,
+    /// `jsonrepair_options_set_repair_undefined()`
+    OptionRepairUndefined = 3,
+    /// `jsonrepair_options_set_fenced_code_blocks()`
+    OptionFencedCodeBlocks = 4,
+    /// `jsonrepair_options_set_normalize_js_nonfinite()`
+    OptionNormalizeJsNonfinite = 5,
+    /// `jsonrepair_options_set_stream_ndjson_aggregate()`
+    OptionStreamNdjsonAggregate = 6,
+    /// `jsonrepair_options_set_logging()` (needs the `logging` feature)
+    OptionLogging = 7,
+    /// `jsonrepair_options_set_number_tolerance_leading_dot()`
+    OptionNumberToleranceLeadingDot = 8,
+    /// `jsonrepair_options_set_number_tolerance_trailing_dot()`
+    OptionNumberToleranceTrailingDot = 9,
+    /// `jsonrepair_options_set_python_style_separators()`
+    OptionPythonStyleSeparators = 10,
+    /// `jsonrepair_options_set_aggressive_truncation_fix()`
+    OptionAggressiveTruncationFix = 11,
+    /// `jsonrepair_options_set_reject_if_invalid()`
+    OptionRejectIfInvalid = 12,
+    /// `jsonrepair_options_set_timeout_ms()`
+    OptionTimeoutMs = 13,
+    /// `jsonrepair_options_set_output_bom()`
+    OptionOutputBom = 14,
+    /// `jsonrepair_options_set_stream_validate_only()`
+    OptionStreamValidateOnly = 15,
+    /// `jsonrepair_options_set_trim_keys()`
+    OptionTrimKeys = 16,
+    /// `jsonrepair_options_set_unwrap_escaped_json()`
+    OptionUnwrapEscapedJson = 17,
+    /// `jsonrepair_options_set_max_repairs()`
+    OptionMaxRepairs = 18,
+    /// `jsonrepair_options_set_wrap_fragments()`
+    OptionWrapFragments = 19,
+    /// `jsonrepair_options_set_missing_values()`
+    OptionMissingValues = 20,
+    /// `jsonrepair_options_set_ascii_scope()`
+    OptionAsciiScope = 21,
+    /// `jsonrepair_options_set_normalize_numbers()`
+    OptionNormalizeNumbers = 22,
+    /// `jsonrepair_options_set_equals_separators()`
+    OptionEqualsSeparators = 23,
+    /// `jsonrepair_options_set_dedup_position()`
+    OptionDedupPosition = 24,
+    /// `jsonrepair_options_set_decode_base64()`
+    OptionDecodeBase64 = 25,
+    /// `jsonrepair_options_set_annotate_source()`
+    OptionAnnotateSource = 26,
+    /// `jsonrepair_options_set_stray_tokens()`
+    OptionStrayTokens = 27,
+    /// `jsonrepair_options_set_force_container()`
+    OptionForceContainer = 28,
+    /// `jsonrepair_options_set_fix_mojibake()`
+    OptionFixMojibake = 29,
+    /// `jsonrepair_options_set_salvage()`
+    OptionSalvage = 30,
+    /// `jsonrepair_options_set_output_format()`
+    OptionOutputFormat = 31,
+    /// `jsonrepair_options_set_comma_decimal()`
+    OptionCommaDecimal = 32,
+    /// `jsonrepair_options_set_dedup_arrays()`
+    OptionDedupArrays = 33,
+    /// `jsonrepair_options_set_escape_slashes()`
+    OptionEscapeSlashes = 34,
+    /// `jsonrepair_options_set_add_missing_brackets()`
+    OptionAddMissingBrackets = 35,
+    /// `jsonrepair_options_set_compact_spacing()`
+    OptionCompactSpacing = 36,
+    /// `jsonrepair_options_set_indent_detect()`
+    OptionIndentDetect = 37,
+    /// `jsonrepair_options_set_align_values()`
+    OptionAlignValues = 38,
+    /// `jsonrepair_options_set_concat_adjacent_strings()`
+    OptionConcatAdjacentStrings = 39,
+    /// `jsonrepair_options_set_progress_callback()`
+    OptionProgressCallback = 40,
+    /// `jsonrepair_options_set_max_elements()`
+    OptionMaxElements = 41,
+    /// `jsonrepair_options_set_tolerate_sql_comments()`
+    OptionTolerateSqlComments = 42,
+    /// `jsonrepair_options_set_overflow()`
+    OptionOverflow = 43,
+    /// `jsonrepair_options_set_number_suffix()`, `jsonrepair_options_set_quote_suffixed_numbers()`
+    OptionNumberSuffix = 44,
+    /// `jsonrepair_options_add_unwrap_function()`, `jsonrepair_options_clear_unwrap_functions()`
+    OptionUnwrapFunctions = 45,
+    /// `jsonrepair_options_set_error_as_json()`
+    OptionErrorAsJson = 46,
+    /// `jsonrepair_options_set_strip_ellipsis()`
+    OptionStripEllipsis = 47,
+    /// `jsonrepair_options_set_negative_zero()`
+    OptionNegativeZero = 48,
+    /// `jsonrepair_options_set_short_escapes()`
+    OptionShortEscapes = 49,
+    /// `jsonrepair_options_set_strip_trailing_line_ws()`
+    OptionStripTrailingLineWs = 50,
+    /// `jsonrepair_options_set_parens_as_arrays()`
+    OptionParensAsArrays = 51,
+    /// `jsonrepair_options_set_safe_integers()`
+    OptionSafeIntegers = 52,
+    /// `jsonrepair_options_set_extract_embedded()`
+    OptionExtractEmbedded = 53,
+    /// `jsonrepair_options_set_minimal_escapes()`
+    OptionMinimalEscapes = 54,
+    /// `jsonrepair_options_set_strictness()`
+    OptionStrictness = 55,
+    /// `jsonrepair_options_set_max_key_len()`
+    OptionMaxKeyLen = 56,
+    /// `jsonrepair_options_set_long_keys()`
+    OptionLongKeys = 57,
+    /// `jsonrepair_options_set_case_insensitive_keywords()`
+    OptionCaseInsensitiveKeywords = 58,
+    /// `jsonrepair_options_set_collapse_ws()`
+    OptionCollapseWs = 59,
+    /// `jsonrepair_options_set_collapse_ws_newlines()`
+    OptionCollapseWsNewlines = 60,
+    /// `jsonrepair_options_set_trace()`
+    OptionTrace = 61,
+    /// `jsonrepair_options_set_crlf()`
+    OptionCrlf = 62,
+    /// `jsonrepair_options_set_alt_quote_chars()`
+    OptionAltQuoteChars = 63,
+    /// `jsonrepair_options_set_lines_to_array()`
+    OptionLinesToArray = 64,
+    /// `jsonrepair_options_set_line_continuations()`
+    OptionLineContinuations = 65,
+    /// `jsonrepair_options_set_drop_placeholder()`
+    OptionDropPlaceholder = 66,
+    /// `jsonrepair_options_set_raw_message_safe()`
+    OptionRawMessageSafe = 67,
+    /// `jsonrepair_options_set_utf8_strictness()`
+    OptionUtf8Strictness = 68,
+    /// `jsonrepair_options_add_bracket_alias()`, `jsonrepair_options_clear_bracket_aliases()`
+    OptionBracketAliases = 69,
+    /// `jsonrepair_options_set_eval_fractions()`
+    OptionEvalFractions = 70,
+    /// `jsonrepair_options_set_fix_backslashes()`
+    OptionFixBackslashes = 71,
+    /// `jsonrepair_options_set_stop_after_first()`
+    OptionStopAfterFirst = 72,
+    /// `jsonrepair_options_set_max_input_bytes()`
+    OptionMaxInputBytes = 73,
+    /// `jsonrepair_options_set_abbreviated_keywords()`
+    OptionAbbreviatedKeywords = 74,
+    /// `jsonrepair_options_set_envelope()`
+    OptionEnvelope = 75,
+    /// `jsonrepair_options_set_number_format_callback()`
+    OptionNumberFormatCallback = 76,
+    /// `jsonrepair_options_set_null_tokens()`
+    OptionNullTokens = 77,
+    /// `jsonrepair_options_set_sort_keys()`
+    OptionSortKeys = 78,
+    /// `jsonrepair_options_set_brackets_as_objects()`
+    OptionBracketsAsObjects = 79,
+}
+
+/// Whether an option, given as a `JsonRepairOption` id, takes effect in this build.
+///
+/// Options behind a Cargo feature keep their setters in every build, but the setters do
+/// nothing when the feature is compiled out: `jsonrepair_options_set_logging()` needs
+/// `logging`. Ids this version of the library does not know return false, so a caller built
+/// against a newer header can tell that an option is missing rather than silently ignored.
+#[unsafe(no_mangle)]
+pub extern "C" fn jsonrepair_option_supported(option: u32) -> bool {
+    if option == JsonRepairOption::OptionLogging as u32 {
+        return cfg!(feature = "logging");
+    }
+    option <= JsonRepairOption::OptionBracketsAsObjects as u32
+}
@@ -0,0 +1,176 @@
+//! Pretty-printing with the input's own indentation, for `Options::indent_detect`.
+//!
+//! `detect` finds the indentation unit of the input: the most common leading whitespace of
+//! the lines that start at nesting depth 1, inside the top-level container (ties go to the
+//! first one seen). Blank lines and lines that start with a closer are not counted, and
+//! brackets inside double-quoted strings do not change the depth. `reindent` then lays the
+//! repaired JSON out one member per line with that unit; empty containers stay `{}`/`[]`.
+//! With `align`, the values of each object are padded to start one column after its
+//! longest key. `compact` removes the layout again, for `repair_to_string_both`.
+
+/// Return the indentation unit of `input`, or None when no line inside the top-level
+/// container is indented.
+pub(crate) fn detect(input: &str) -> Option<&str> {
+    let bytes = input.as_bytes();
+    let mut counts: Vec<(&str, usize)> = Vec::new();
+    let mut depth = 0usize;
+    let mut in_string = false;
+    let mut line_start = true;
+    let mut i = 0;
+    while i < bytes.len() {
+        if line_start && depth == 1 && !in_string {
+            let ws = bytes[i..]
+                .iter()
+                .take_while(|&&b| b == b' ' || b == b'\t')
+                .count();
+            let content = bytes.get(i + ws).copied();
+            if ws > 0 && !matches!(content, None | Some(b'\n' | b'\r' | b'}' | b']')) {
+                let unit = &input[i..i + ws];
+                match counts.iter_mut().find(|(u, _)| *u == unit) {
+                    Some((_, n)) => *n += 1,
+                    None => counts.push((unit, 1)),
+                }
+            }
+        }
+        line_start = false;
+        match bytes[i] {
+            b'\\' if in_string => i += 1,
+            b'"' => in_string = !in_string,
+            b'{' | b'[' if !in_string => depth += 1,
+            b'}' | b']' if !in_string => depth = depth.saturating_sub(1),
+            b'\n' => line_start = true,
+            _ => {}
+        }
+        i += 1;
+    }
+    let mut best: Option<(&str, usize)> = None;
+    for (unit, n) in counts {
+        if best.is_none_or(|(_, m)| n > m) {
+            best = Some((unit, n));
+        }
+    }
+    best.map(|(unit, _)| unit)
+}
+
+/// Return `json` pretty-printed with `unit` as one level of indentation and `eol` ending
+/// each line, with the values of each object aligned when `align` is set.
+pub(crate) fn reindent(json: &str, unit: &str, eol: &str, align: bool) -> String {
+    let mut out = String::with_capacity(json.len() * 2);
+    let mut depth = 0usize;
+    // Longest key width of each open object (0 for arrays and without `align`).
+    let mut widths: Vec<usize> = Vec::new();
+    let mut last_width = 0usize;
+    let mut rest = json;
+    while let Some(c) = rest.chars().next() {
+        let mut len = c.len_utf8();
+        match c {
+            '"' => {
+                len = crate::json5::string_end(rest);
+                out.push_str(&rest[..len]);
+                last_width = rest[..len].chars().count();
+            }
+            '/' if rest.starts_with("/*") => {
+                len = rest.find("*/").map_or(rest.len(), |i| i + 2);
+                out.push_str(&rest[..len]);
+            }
+            '{' | '[' => {
+                let close = if c == '{' { '}' } else { ']' };
+                let after = rest[1..].trim_start();
+                out.push(c);
+                if after.starts_with(close) {
+                    out.push(close);
+                    len = rest.len() - after.len() + 1;
+                } else {
+                    depth += 1;
+                    widths.push(if align && c == '{' {
+                        longest_key(&rest[1..])
+                    } else {
+                        0
+                    });
+                    newline(&mut out, unit, eol, depth);
+                }
+            }
+            '}' | ']' => {
+                depth = depth.saturating_sub(1);
+                widths.pop();
+                newline(&mut out, unit, eol, depth);
+                out.push(c);
+            }
+            ',' => {
+                out.push(c);
+                if depth > 0 {
+                    newline(&mut out, unit, eol, depth);
+                }
+            }
+            ':' => {
+                out.push(':');
+                let width = widths.last().copied().unwrap_or(0);
+                for _ in last_width..width.max(last_width) + 1 {
+                    out.push(' ');
+                }
+            }
+            ' ' | '\t' | '\n' | '\r' if depth > 0 => {}
+            _ => out.push(c),
+        }
+        rest = &rest[len..];
+    }
+    out
+}
+
+/// Return `json` with all whitespace between tokens removed.
+pub(crate) fn compact(json: &str) -> String {
+    let mut out = String::with_capacity(json.len());
+    let mut rest = json;
+    while let Some(c) = rest.chars().next() {
+        let mut len = c.len_utf8();
+        match c {
+            '"' => {
+                len = crate::json5::string_end(rest);
+                out.push_str(&rest[..len]);
+            }
+            '/' if rest.starts_with("/*") => {
+                len = rest.find("*/").map_or(rest.len(), |i| i + 2);
+                out.push_str(&rest[..len]);
+            }
+            ' ' | '\t' | '\n' | '\r' => {}
+            _ => out.push(c),
+        }
+        rest = &rest[len..];
+    }
+    out
+}
+
+// Width in characters of the longest key of the object whose members start `body`, up to
+// its closing brace. Keys of nested objects are not counted.
+fn longest_key(body: &str) -> usize {
+    let mut longest = 0;
+    let mut depth = 0usize;
+    let mut rest = body;
+    while let Some(c) = rest.chars().next() {
+        let mut len = c.len_utf8();
+        match c {
+            '"' => {
+                len = crate::json5::string_end(rest);
+                if depth == 0 && rest[len..].trim_start().starts_with(':') {
+                    longest = longest.max(rest[..len].chars().count());
+                }
+            }
+            '/' if rest.starts_with("/*") => {
+                len = rest.find("*/").map_or(rest.len(), |i| i + 2);
+            }
+            '{' | '[' => depth += 1,
+            '}' | ']' if depth == 0 => break,
+            '}' | ']' => depth -= 1,
+            _ => {}
+        }
+        rest = &rest[len..];
+    }
+    longest
+}
+
+fn newline(out: &mut String, unit: &str, eol: &str, depth: usize) {
+    out.push_str(eol);
+    for _ in 0..depth {
+        out.push_str(unit);
+    }
+}
@@ -0,0 +1,87 @@
+//! JSON5 rendering of repaired output, for `Options::output_format`.
+//!
+//! The input is the strict JSON the repairer produced. Object keys that are ASCII
+//! identifiers (`[A-Za-z_$][A-Za-z0-9_$]*`) lose their quotes, and every other string is
+//! re-quoted with single quotes (`"it's"` → `'it\'s'`). Whitespace, numbers, keywords and
+//! `/* ... */` comments (from `annotate_source`) are copied unchanged.
+
+/// Return `json` rendered as JSON5.
+pub(crate) fn render(json: &str) -> String {
+    let mut out = String::with_capacity(json.len());
+    let mut rest = json;
+    while let Some(c) = rest.chars().next() {
+        if c == '"' {
+            let end = string_end(rest);
+            let (lit, after) = rest.split_at(end);
+            let body = lit[1..].strip_suffix('"').unwrap_or(&lit[1..]);
+            if is_identifier(body) && is_key(after) {
+                out.push_str(body);
+            } else {
+                push_single_quoted(&mut out, body);
+            }
+            rest = after;
+        } else if let Some(comment) = rest.strip_prefix("/*") {
+            let end = comment.find("*/").map_or(comment.len(), |i| i + 2);
+            out.push_str(&rest[..end + 2]);
+            rest = &comment[end..];
+        } else {
+            out.push(c);
+            rest = &rest[c.len_utf8()..];
+        }
+    }
+    out
+}
+
+// Byte length of the double-quoted string at the start of `s`, including both quotes.
+pub(crate) fn string_end(s: &str) -> usize {
+    let bytes = s.as_bytes();
+    let mut i = 1;
+    while i < bytes.len() {
+        match bytes[i] {
+            b'\\' => i += 2,
+            b'"' => return i + 1,
+            _ => i += 1,
+        }
+    }
+    bytes.len()
+}
+
+// Whether the string just closed is an object key: the next token is `:`.
+fn is_key(after: &str) -> bool {
+    let mut rest = after.trim_start();
+    while let Some(comment) = rest.strip_prefix("/*") {
+        rest = comment
+            .find("*/")
+            .map_or("", |i| &comment[i + 2..])
+            .trim_start();
+    }
+    rest.starts_with(':')
+}
+
+fn is_identifier(s: &str) -> bool {
+    let mut bytes = s.bytes();
+    matches!(bytes.next(), Some(b'A'..=b'Z' | b'a'..=b'z' | b'_' | b'$'))
+        && bytes.all(|b| b.is_ascii_alphanumeric() || b == b'_' || b == b'$')
+}
+
+// Re-quote the body of a JSON string with single quotes: `\"` needs no escape any more and
+// a bare `'` gains one. Other escapes are kept as they are.
+fn push_single_quoted(out: &mut String, body: &str) {
+    out.push('\'');
+    let mut chars = body.chars();
+    while let Some(c) = chars.next() {
+        match c {
+            '\\' => match chars.next() {
+                Some('"') => out.push('"'),
+                Some(e) => {
+                    out.push('\\');
+                    out.push(e);
+                }
+                None => out.push('\\'),
+            },
+            '\'' => out.push_str("\\'"),
+            _ => out.push(c),
+        }
+    }
+    out.push('\'');
+}
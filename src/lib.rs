@@ -1,21 +1,43 @@
+mod altquote;
+mod base64;
+mod budget;
 mod classify;
 pub mod cli;
+mod comments;
+mod dedup;
 mod emit;
 #[cfg(feature = "llm-compat")]
 mod engines;
 pub mod error;
+mod indent;
+mod json5;
+mod mojibake;
 pub mod options;
 mod parser;
+mod pointer;
 mod repair;
+mod sha256;
+mod skeleton;
+mod srcmap;
 pub mod stream;
+mod strict;
+mod utf16;
+mod utf8;
 
 #[cfg(feature = "c-api")]
 pub mod ffi;
 
 pub use error::{RepairError, RepairErrorKind};
-pub use options::{LeadingZeroPolicy, Options};
-pub use repair::RepairLogEntry;
-pub use stream::StreamRepairer;
+pub use options::{
+    AsciiScope, BUILTIN_UNWRAP_FUNCTIONS, BracketKind, CJK_CORNER_QUOTES, CompactSpacing,
+    DedupPosition, ForceContainer, GUILLEMET_QUOTES, LeadingZeroPolicy, LineContinuation,
+    LongKeyPolicy, MissingValuePolicy, NegativeZeroPolicy, NumberFormat, NumberSuffixPolicy,
+    Options, OutputFormat, OverflowPolicy, Progress, SafeIntegerPolicy, SalvagePolicy,
+    StrayTokenPolicy, Strictness, Trace, UnwrapMode, Utf8Strictness,
+};
+pub use repair::{RepairLogEntry, ValueKind};
+pub use stream::{Heartbeat, StreamRepairer, StreamStats, ValueRange, ValueStatus};
+pub use utf16::Utf16Endian;
 
 use std::io::Write;
 
@@ -113,6 +135,374 @@ pub fn repair_to_writer_streaming<W: Write>(
     repair::repair_to_writer_streaming(input, opts, writer)
 }
 
+/// Repair like [`repair_to_string`] and also return the SHA-256 digest of the output.
+///
+/// The digest covers the final bytes exactly as returned (including a BOM when
+/// `opts.output_bom` is set), which makes it a stable cache key for the repaired document.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_string_hashed, Options};
+///
+/// let (out, hash) = repair_to_string_hashed("{a: 1}", &Options::default())?;
+/// assert_eq!(out, r#"{"a":1}"#);
+/// let (_, again) = repair_to_string_hashed("{'a': 1,}", &Options::default())?;
+/// assert_eq!(hash, again);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_string_hashed(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, [u8; 32]), RepairError> {
+    let s = repair::repair_to_string(input, opts)?;
+    let hash = sha256::digest(s.as_bytes());
+    Ok((s, hash))
+}
+
+/// Repair `input` and also return the JSON type of its root value, for routing the
+/// result without parsing it again.
+///
+/// The kind describes the output as emitted, so input holding several root values that get
+/// wrapped into an array reports `Array`, and input with no value reports `Empty`.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_string_with_kind, Options, ValueKind};
+///
+/// let (out, kind) = repair_to_string_with_kind("[1, 2,", &Options::default())?;
+/// assert_eq!(out, "[1,2]");
+/// assert_eq!(kind, ValueKind::Array);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_string_with_kind(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, ValueKind), RepairError> {
+    let s = repair::repair_to_string(input, opts)?;
+    let kind = repair::value_kind(&s);
+    Ok((s, kind))
+}
+
+/// Repair `input` and also report whether the output differs from it in any byte, for
+/// "did we touch it?" checks that do not need the edits themselves.
+///
+/// The recursive engine copies valid JSON through as written, so input that needs no repair
+/// reports `false`; the LLM-compatible engine re-serializes it, so there only compact input
+/// does. Any repair, or an option that rewrites the output (`output_bom`, `indent_detect`,
+/// ...), reports `true`.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_string_changed, Options};
+///
+/// let (out, changed) = repair_to_string_changed("{a: 1}", &Options::default())?;
+/// assert_eq!((out.as_str(), changed), (r#"{"a":1}"#, true));
+/// let (_, changed) = repair_to_string_changed(r#"{"a": 1}"#, &Options::default())?;
+/// assert!(!changed);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_string_changed(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, bool), RepairError> {
+    let s = repair::repair_to_string(input, opts)?;
+    let changed = s != input;
+    Ok((s, changed))
+}
+
+/// Repair `input` and return its type skeleton: the same structure with every scalar
+/// replaced by the name of its type (`"string"`, `"number"`, `"boolean"` or `"null"`), for a
+/// quick look at unfamiliar data.
+///
+/// Objects keep their keys in order. An array lists the distinct skeletons of its elements
+/// once each, in order of first appearance, so `[1, "a", 2]` becomes `["number","string"]`
+/// and an array of same-shaped objects shows one object. Input with no value gives empty
+/// output.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_skeleton, Options};
+///
+/// let skeleton = repair_to_skeleton(
+///     "{a: 1, b: ['x', 'y'], c: [1, 'z', null]}",
+///     &Options::default(),
+/// )?;
+/// assert_eq!(
+///     skeleton,
+///     r#"{"a":"number","b":["string"],"c":["number","string","null"]}"#
+/// );
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_skeleton(input: &str, opts: &Options) -> Result<String, RepairError> {
+    repair::repair_skeleton(input, opts)
+}
+
+/// Repair `input` once and return it both minified and pretty-printed.
+///
+/// The pretty form puts one member per line, indented by `indent` spaces per level, and is
+/// laid out from the compact form rather than parsed again, so the two always hold the same
+/// values with the same spelling. Whitespace from `compact_spacing` or `indent_detect` is
+/// replaced by these two layouts.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_string_both, Options};
+///
+/// let (compact, pretty) = repair_to_string_both("{a: [1, 2]}", 2, &Options::default())?;
+/// assert_eq!(compact, r#"{"a":[1,2]}"#);
+/// assert_eq!(pretty, "{\n  \"a\": [\n    1,\n    2\n  ]\n}");
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_string_both(
+    input: &str,
+    indent: usize,
+    opts: &Options,
+) -> Result<(String, String), RepairError> {
+    repair::repair_both(input, indent, opts)
+}
+
+/// Repair `input` with its comments removed from the output but returned alongside it, as
+/// pairs of JSON Pointer and comment text in document order.
+///
+/// A comment belongs to the value that starts before it on the same line (`"a": 1, // one`
+/// is about `/a`), otherwise to the next value after it; several comments of one value are
+/// joined with newlines. Leading comments of the document map to `""`. `//`, `/* */` and,
+/// with `tolerate_hash_comments`, `#` comments are collected. This always uses the
+/// recursive engine. With `dedup_arrays`, array indices refer to the document before
+/// duplicates were dropped.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_with_comments, Options};
+///
+/// let input = "{\n  // who\n  name: 'John',\n  age: 30, /* years */\n}";
+/// let (out, comments) = repair_with_comments(input, &Options::default())?;
+/// assert_eq!(out, r#"{"name":"John","age":30}"#);
+/// assert_eq!(
+///     comments,
+///     [("/name".to_string(), "who".to_string()), ("/age".to_string(), "years".to_string())]
+/// );
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_with_comments(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, Vec<(String, String)>), RepairError> {
+    repair::repair_with_comments(input, opts)
+}
+
+/// Repair `input` and return only the value at JSON Pointer `pointer` (RFC 6901, e.g.
+/// `/result/items/0`; `""` is the whole document).
+///
+/// Output off the pointer path is dropped as the repair produces it, and the repair ends once
+/// the target is complete, so a value near the start of a large document costs little more
+/// than the text before it. Limits such as `max_repairs` and errors apply only to the part
+/// repaired. Options that transform the whole output (`sort_keys`, `compact_spacing`,
+/// `salvage`, ...) and several root values need the whole document: it is repaired in full
+/// and the target sliced out of the text. A malformed pointer is a `Parse` error at its byte
+/// offset in `pointer`; a missing target is `PointerNotFound`. With `envelope` the pointer is
+/// resolved against the repaired value, and the target is returned in the envelope of the
+/// whole repair: `{"value":TARGET,"repaired":...,"repairs":N}`.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_extract, Options};
+///
+/// let out = repair_extract("{status: ok, result: {a: [1, 2,]}", "/result/a", &Options::default())?;
+/// assert_eq!(out, "[1,2]");
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_extract(input: &str, pointer: &str, opts: &Options) -> Result<String, RepairError> {
+    let tokens = pointer::parse(pointer)?;
+    // The pointer walks strict JSON; a JSON5 `output_format` is applied to the slice.
+    let strict = Options {
+        output_format: OutputFormat::Json,
+        ..opts.clone()
+    };
+    let s = match repair::repair_extract_streaming(input, pointer, &tokens, &strict)? {
+        Some(out) => return Ok(render_extracted(out, opts)),
+        None => repair::repair_to_string(input, &strict)?,
+    };
+    let body = s.strip_prefix('\u{FEFF}').unwrap_or(&s);
+    let not_found = || RepairError::new(RepairErrorKind::PointerNotFound(pointer.to_string()), 0);
+    // An envelope keeps its fields around the target in place of the whole value.
+    let (value, found) = if opts.envelope {
+        let value = pointer::find(body, &["value".to_string()]).ok_or_else(not_found)?;
+        (value, pointer::find(value, &tokens).ok_or_else(not_found)?)
+    } else {
+        (body, pointer::find(body, &tokens).ok_or_else(not_found)?)
+    };
+    let start = value.as_ptr() as usize - s.as_ptr() as usize;
+    let out = [&s[..start], found, &s[start + value.len()..]].concat();
+    Ok(render_extracted(out, opts))
+}
+
+fn render_extracted(out: String, opts: &Options) -> String {
+    match opts.output_format {
+        OutputFormat::Json => out,
+        OutputFormat::Json5 => json5::render(&out),
+    }
+}
+
+/// Repair a document made of several concatenated root values and return each one
+/// separately (`{a:1}{b:2}[1,2]` → `{"a":1}`, `{"b":2}`, `[1,2]`).
+///
+/// Values may be separated by whitespace, newlines, commas or nothing at all. Each value is
+/// repaired on its own with `opts`, as [`StreamRepairer`] does per value, rather than being
+/// aggregated into one array the way [`repair_to_string`] does. Empty input gives an empty
+/// list.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_split, Options};
+///
+/// let values = repair_split("{a:1}{b:2}[1,2]", &Options::default())?;
+/// assert_eq!(values, [r#"{"a":1}"#, r#"{"b":2}"#, "[1,2]"]);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_split(input: &str, opts: &Options) -> Result<Vec<String>, RepairError> {
+    repair::repair_split(input, opts)
+}
+
+/// Repair only the first root value of `input` and return it together with the number of
+/// input bytes it used, for framing values in a custom protocol.
+///
+/// The count runs from the start of `input` to the end of the value, so it includes any
+/// leading whitespace or comments but not what follows the value; nothing after the value
+/// is parsed. Input with no value at all fails with `UnexpectedEnd`. Input rewrites such as
+/// `extract_embedded` or `add_missing_brackets` apply as in `repair_to_string`, and the count
+/// is still in bytes of `input` as given.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_first, Options};
+///
+/// let (value, consumed) = repair_first("{a: 1} trailing junk", &Options::default())?;
+/// assert_eq!(value, r#"{"a":1}"#);
+/// assert_eq!(consumed, 6);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_first(input: &str, opts: &Options) -> Result<(String, usize), RepairError> {
+    repair::repair_first(input, opts)
+}
+
+/// Repair `input` as `repair_to_string` does and also return the byte offset where its
+/// last root value ends, so a reader can advance past the values and keep what follows.
+///
+/// Trailing data the repair ignores, such as narrative after an object or a partial frame
+/// that does not start a value, is not counted. With `stop_after_first` the offset is the
+/// end of the first value, as in `repair_first`. Input with no value gives 0. The offset is
+/// in bytes of `input` as given, also when input rewrites such as `add_missing_brackets`
+/// changed the text that was repaired.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_to_string_with_end, Options};
+///
+/// let (out, end) = repair_to_string_with_end("{a: 1} {b: 2} <EOF>", &Options::default())?;
+/// assert_eq!(out, r#"[{"a":1},{"b":2}]"#);
+/// assert_eq!(end, 13);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_to_string_with_end(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, usize), RepairError> {
+    repair::repair_with_end(input, opts)
+}
+
+// ============================================================================
+// UTF-16 Input API
+// ============================================================================
+
+/// Repair UTF-16 encoded input and return the result as UTF-8.
+///
+/// A leading BOM (`FF FE` little-endian, `FE FF` big-endian) selects the byte order and is
+/// dropped; BOM-less input is read with `endian`. Input with an odd byte count or an unpaired
+/// surrogate fails with a `Parse` error at the offending byte offset.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_utf16, Options, Utf16Endian};
+///
+/// let le: Vec<u8> = "{a: 1}".encode_utf16().flat_map(|u| u.to_le_bytes()).collect();
+/// let out = repair_utf16(&le, Utf16Endian::Little, &Options::default())?;
+/// assert_eq!(out, r#"{"a":1}"#);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_utf16(
+    input: &[u8],
+    endian: Utf16Endian,
+    opts: &Options,
+) -> Result<String, RepairError> {
+    let s = utf16::decode(input, endian)?;
+    repair::repair_to_string(&s, opts)
+}
+
+/// Repair UTF-8 encoded bytes that may not be valid UTF-8.
+///
+/// `opts.utf8_strictness` decides what happens to invalid sequences: `Strict` (default)
+/// fails with a `Parse` error at the first bad byte, `Lenient` replaces each one with U+FFFD
+/// and repairs the rest.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_bytes, Options, Utf8Strictness};
+///
+/// // `C0 AF` is an overlong encoding of `/`.
+/// let input = b"{a: 'x\xC0\xAF'}";
+/// assert!(repair_bytes(input, &Options::default()).is_err());
+/// let opts = Options { utf8_strictness: Utf8Strictness::Lenient, ..Options::default() };
+/// assert_eq!(repair_bytes(input, &opts)?, "{\"a\":\"x\u{FFFD}\u{FFFD}\"}");
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_bytes(input: &[u8], opts: &Options) -> Result<String, RepairError> {
+    let s = utf8::decode(input, opts.utf8_strictness).map_err(|e| {
+        RepairError::new(
+            RepairErrorKind::Parse("invalid UTF-8".to_string()),
+            e.valid_up_to(),
+        )
+    })?;
+    repair::repair_to_string(&s, opts)
+}
+
+// ============================================================================
+// Minify API
+// ============================================================================
+
+/// Minify input that is already valid JSON.
+///
+/// Unlike the repair functions this never alters the document: invalid input fails with an
+/// `InvalidJson` error at the first problem's byte offset. Tokens are copied verbatim, so
+/// number spelling and string escapes are preserved; only insignificant whitespace (and a
+/// leading BOM) is removed.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::minify;
+///
+/// let compact = minify("{ \"a\": [1, 2.50],\n  \"b\": \"x y\" }")?;
+/// assert_eq!(compact, r#"{"a":[1,2.50],"b":"x y"}"#);
+/// assert!(minify("{a: 1}").is_err());
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn minify(input: &str) -> Result<String, RepairError> {
+    strict::minify(input)
+}
+
 // ============================================================================
 // Streaming Chunks API
 // ============================================================================
@@ -199,8 +589,17 @@ where
 /// # Ok::<(), jsonrepair::RepairError>(())
 /// ```
 pub fn repair_to_value(input: &str, opts: &Options) -> Result<serde_json::Value, RepairError> {
-    let s = repair_to_string(input, opts)?;
-    let v = serde_json::from_str(&s).map_err(|e| RepairError::from_serde("parse", e))?;
+    let s = if opts.output_format == OutputFormat::Json {
+        repair_to_string(input, opts)?
+    } else {
+        let strict = Options {
+            output_format: OutputFormat::Json,
+            ..opts.clone()
+        };
+        repair_to_string(input, &strict)?
+    };
+    let s = s.strip_prefix('\u{FEFF}').unwrap_or(&s);
+    let v = serde_json::from_str(s).map_err(|e| RepairError::from_serde("parse", e))?;
     Ok(v)
 }
 
@@ -305,5 +704,47 @@ pub fn repair_to_string_with_log(
     repair::repair_to_string_with_log(input, opts)
 }
 
+/// Repair a potentially invalid JSON string and list the paths of the values it repaired.
+///
+/// Each path is written as in [`Options::log_json_path`] (`$["items"][2]["name"]`) and
+/// names the innermost value being parsed when a repair was made: a repaired string or
+/// number gives its own path, and a repair to a container's own syntax (a key, comma or
+/// bracket) gives the container's. Paths are listed once each, in the order of their first
+/// repair; valid input gives none. Uses the recursive engine, and needs the `logging`
+/// feature: without it the list is always empty.
+///
+/// # Examples
+///
+/// ```
+/// use jsonrepair::{repair_affected_paths, Options};
+///
+/// let (repaired, paths) =
+///     repair_affected_paths(r#"{"a": 1, "items": [1, 2, {"name": 'x'}]}"#, &Options::default())?;
+/// assert_eq!(repaired, r#"{"a":1,"items":[1,2,{"name":"x"}]}"#);
+/// # #[cfg(feature = "logging")]
+/// assert_eq!(paths, [r#"$["items"][2]["name"]"#]);
+/// # Ok::<(), jsonrepair::RepairError>(())
+/// ```
+pub fn repair_affected_paths(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, Vec<String>), RepairError> {
+    repair::repair_affected_paths(input, opts)
+}
+
+/// List every repair category a [`RepairLogEntry::message`] can name.
+///
+/// Tools that explain repairs can use this instead of hardcoding the messages. The list
+/// does not depend on the `logging` feature.
+///
+/// # Examples
+///
+/// ```
+/// assert!(jsonrepair::repair_categories().contains(&"inserted missing comma"));
+/// ```
+pub fn repair_categories() -> &'static [&'static str] {
+    repair::REPAIR_CATEGORIES
+}
+
 #[cfg(test)]
 mod tests;
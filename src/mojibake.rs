@@ -0,0 +1,80 @@
+//! Repair of UTF-8 punctuation that was misdecoded as Windows-1252 (or Latin-1) before it
+//! reached us, for `Options::fix_mojibake`. Only the three-byte `E2 80 xx` sequences for
+//! curly quotes, dashes and the ellipsis are recognized: `â€œ` → `“`, `â€™` → `’`, `â€”` → `—`.
+//!
+//! Curly quotes outside a string become ASCII delimiters (`â€œkeyâ€` → `"key"`), since that
+//! is what they stood in for; inside an ASCII-quoted string they are restored as the curly
+//! characters so the string's content keeps its meaning.
+
+use crate::srcmap::{Rewrite, StepMap};
+
+/// Return `input` with mojibake sequences repaired, or `None` when it contains none.
+pub(crate) fn fix(input: &str) -> Option<(String, StepMap)> {
+    if !input.contains("â€") && !input.contains("â\u{80}") {
+        return None;
+    }
+    let mut out = Rewrite::new(input);
+    // The open string's ASCII delimiter, and whether a mojibake quote opened it.
+    let mut open: Option<(char, bool)> = None;
+    let mut escape = false;
+    let mut rest = input;
+    while let Some(c) = rest.chars().next() {
+        if let Some((fixed, len)) = sequence(rest) {
+            let seq = &rest[..len];
+            rest = &rest[len..];
+            match (fixed, open) {
+                ('“' | '”', None) => {
+                    out.push('"');
+                    open = Some(('"', true));
+                }
+                ('‘' | '’', None) => {
+                    out.push('\'');
+                    open = Some(('\'', true));
+                }
+                ('“' | '”', Some(('"', true))) => {
+                    out.push('"');
+                    open = None;
+                }
+                ('‘' | '’', Some(('\'', true))) => {
+                    out.push('\'');
+                    open = None;
+                }
+                _ => out.push(fixed),
+            }
+            out.skip(seq);
+            continue;
+        }
+        out.push_str(&rest[..c.len_utf8()]);
+        rest = &rest[c.len_utf8()..];
+        match open {
+            Some(_) if escape => escape = false,
+            Some(_) if c == '\\' => escape = true,
+            Some((q, _)) if c == q => open = None,
+            None if c == '"' || c == '\'' => open = Some((c, false)),
+            _ => {}
+        }
+    }
+    Some(out.finish())
+}
+
+// The intended character and byte length of a mojibake sequence at the start of `s`.
+fn sequence(s: &str) -> Option<(char, usize)> {
+    let tail = s.strip_prefix('â')?.strip_prefix(['€', '\u{80}'])?;
+    let head = s.len() - tail.len();
+    let Some(c) = tail.chars().next() else {
+        return Some(('”', head));
+    };
+    let fixed = match c {
+        'œ' | '\u{9C}' => '“',
+        '\u{9D}' => '”',
+        '˜' | '\u{98}' => '‘',
+        '™' | '\u{99}' => '’',
+        '“' | '\u{93}' => '–',
+        '”' | '\u{94}' => '—',
+        '¦' => '…',
+        // Windows-1252 has no character for 0x9D, so `”` usually loses its last byte.
+        c if c.is_ascii() => return Some(('”', head)),
+        _ => return None,
+    };
+    Some((fixed, head + c.len_utf8()))
+}
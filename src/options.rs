@@ -1,3 +1,6 @@
+use std::fmt;
+use std::sync::{Arc, Mutex, MutexGuard, PoisonError};
+
 #[derive(Clone, Debug, Copy, PartialEq, Eq)]
 pub enum LeadingZeroPolicy {
     /// Keep numbers with leading zeros as-is (may be non‑strict JSON, but pragmatic).
@@ -8,6 +11,223 @@ pub enum LeadingZeroPolicy {
     QuoteAsString,
 }
 
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum OverflowPolicy {
+    /// Keep a number too large for an `f64` (`1e400`) as its literal, which is valid JSON
+    /// but parses as infinity or fails in many consumers. Default.
+    Keep,
+    /// Quote the literal as a string: `[1e400]` becomes `["1e400"]`, keeping every digit
+    /// for arbitrary-precision consumers.
+    Quote,
+    /// Replace the number with `null`: `[1e400]` becomes `[null]`.
+    Null,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum NegativeZeroPolicy {
+    /// Keep the sign as written: `-0`, `-0.0` and `-0e5` stay negative. Default.
+    Preserve,
+    /// Drop the sign of any zero, keeping the rest of its spelling: `-0` becomes `0`,
+    /// `-0.0` becomes `0.0` and `-0e5` becomes `0e5`.
+    Normalize,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum SafeIntegerPolicy {
+    /// Keep integers of any size as written. Default.
+    Passthrough,
+    /// Clamp an integer beyond the JavaScript safe range to its edge: `9007199254740993`
+    /// becomes `9007199254740991` and `-9007199254740993` becomes `-9007199254740991`.
+    Clamp,
+    /// Quote an integer beyond the safe range as a string, so no digits are lost when a
+    /// JavaScript consumer parses it: `[9007199254740993]` becomes `["9007199254740993"]`.
+    Quote,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum NumberSuffixPolicy {
+    /// Leave unit suffixes to the general number rules: a token with letters is quoted
+    /// (`30s` → `"30s"`) but other characters split off (`100%` is read as `100` followed by
+    /// a stray `%`). Default.
+    Keep,
+    /// Quote the whole token as a string: `{"timeout": 30s, "load": 75%}` becomes
+    /// `{"timeout":"30s","load":"75%"}`.
+    Quote,
+    /// Keep the number and drop the suffix: `{"size": 10MB}` becomes `{"size":10}`.
+    Strip,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum BracketKind {
+    /// The words stand for `{` and `}`.
+    Object,
+    /// The words stand for `[` and `]`.
+    Array,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum UnwrapMode {
+    /// Keep the argument as a string: `ObjectId("5f1e")` becomes `"5f1e"`, and a
+    /// non-string argument is quoted (`Code(12)` → `"12"`).
+    String,
+    /// Make the argument a number: `NumberLong("42")` becomes `42`. A non-finite argument
+    /// (`NumberDecimal("NaN")`, `"Infinity"`, `"-Infinity"`) is handled like a bare `NaN`:
+    /// `null` under `normalize_js_nonfinite`, otherwise the string. Any other string that is
+    /// not a JSON number is kept as a string.
+    Number,
+    /// Replace the whole call with `null`.
+    Null,
+}
+
+/// Wrappers in `Options::unwrap_functions` by default: the MongoDB shell's extended JSON.
+pub const BUILTIN_UNWRAP_FUNCTIONS: &[(&str, UnwrapMode)] = &[
+    ("ObjectId", UnwrapMode::String),
+    ("ISODate", UnwrapMode::String),
+    ("NumberInt", UnwrapMode::Number),
+    ("NumberLong", UnwrapMode::Number),
+    ("NumberDecimal", UnwrapMode::Number),
+    ("Decimal128", UnwrapMode::Number),
+];
+
+/// Guillemet pairs for `Options::alt_quotes`: `«…»` and `‹…›`.
+pub const GUILLEMET_QUOTES: &[(char, char)] = &[('«', '»'), ('‹', '›')];
+
+/// CJK corner bracket pairs for `Options::alt_quotes`: `「…」` and `『…』`.
+pub const CJK_CORNER_QUOTES: &[(char, char)] = &[('「', '」'), ('『', '』')];
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum MissingValuePolicy {
+    /// Fill a key that has no value (`{"a":}`, `{"a"}`) with an empty string: `{"a":""}`.
+    /// Default, matching Python json_repair.
+    EmptyString,
+    /// Fill a key that has no value with `null`: `{"a":, "b":2}` becomes `{"a":null,"b":2}`.
+    Null,
+    /// Drop a key that has no value: `{"a":, "b":2}` becomes `{"b":2}`.
+    DropKey,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum StrayTokenPolicy {
+    /// Fail with a `Parse` error at the token's byte offset. Default.
+    Error,
+    /// Quote a bare non-keyword array element as a string: `[1, garbage, 2]` becomes
+    /// `[1,"garbage",2]`, as Python json_repair does.
+    Quote,
+    /// Drop the element: `[1, garbage, 2]` becomes `[1,2]`.
+    Drop,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum LongKeyPolicy {
+    /// Fail with a `KeyTooLong` error at the offset where the key starts. Default.
+    Error,
+    /// Cut the key down to at most `max_key_len` bytes, ending at a UTF-8 character
+    /// boundary, and carry on.
+    Truncate,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum SalvagePolicy {
+    /// Fail the whole document with the nested value's error. Default.
+    Fail,
+    /// Replace the bad value with `null`: `[1, bad, 2]` becomes `[1,null,2]`.
+    Null,
+    /// Replace the bad value with a marker object holding its source text:
+    /// `[1, bad, 2]` becomes `[1,{"$unrepairable":"bad"},2]`.
+    Marker,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum Strictness {
+    /// Only apply repairs with a single plausible reading. An object value or array element
+    /// that is itself followed by a colon (`{a: b: c}`, `[a: 1, b: 2]`) fails with a `Parse`
+    /// error at that value. Default.
+    Conservative,
+    /// Also apply guesses that may change the structure: an object value followed by a colon
+    /// is read as the key of a nested one-member object, so `{a: b: c}` becomes
+    /// `{"a":{"b":"c"}}` and `{a: b: c: d}` becomes `{"a":{"b":{"c":"d"}}}`. An array element
+    /// followed by a colon becomes a one-member object of its own, so the elements keep their
+    /// positions: `[a: 1, b: 2]` becomes `[{"a":1},{"b":2}]`, not one object (that reading is
+    /// `Options::brackets_as_objects`). Braces holding only values,
+    /// a `,` and no colon at their own depth, were written for an array: `{1, 2, 3}` becomes
+    /// `[1,2,3]`, while `{"a"}` stays a key without a value.
+    Aggressive,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum Utf8Strictness {
+    /// Reject input that is not well-formed UTF-8 with an error at the first bad byte:
+    /// overlong encodings (`C0 AF` for `/`), encoded surrogates (`ED A0 80`), code points
+    /// above U+10FFFF and stray or truncated sequences. Default.
+    Strict,
+    /// Decode what can be decoded and replace each invalid sequence with U+FFFD. An overlong
+    /// encoding is never read as the character it spells: `C0 AF` becomes two U+FFFD, not
+    /// `/`. Error positions and consumed lengths then refer to the decoded text.
+    Lenient,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum OutputFormat {
+    /// Strict JSON. Default.
+    Json,
+    /// JSON5 for human editing: identifier keys unquoted, strings single-quoted.
+    Json5,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum CompactSpacing {
+    /// No whitespace between tokens (`{"a":[1,2]}`). Default.
+    None,
+    /// Exactly one space after every `:` and `,` (`{"a": [1, 2]}`), no other whitespace.
+    Minimal,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum ForceContainer {
+    /// Leave a scalar top-level value as it is. Default.
+    Off,
+    /// Wrap a scalar top-level value in an array: `5` becomes `[5]`.
+    Array,
+    /// Wrap a scalar top-level value in an object under the key `"value"`: `5` becomes
+    /// `{"value":5}`.
+    Object,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum AsciiScope {
+    /// Keep non-ASCII characters as UTF-8 everywhere. Default.
+    None,
+    /// Escape non-ASCII characters in object keys only: `{"ключ":"знач"}` becomes
+    /// `{"\u043A\u043B\u044E\u0447":"знач"}`.
+    KeysOnly,
+    /// Escape non-ASCII characters in values only, leaving keys as UTF-8.
+    ValuesOnly,
+    /// Escape non-ASCII characters in keys and values; same as `ensure_ascii = true`.
+    All,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum DedupPosition {
+    /// Keep every occurrence of a duplicated key, in source order. Default.
+    KeepAll,
+    /// Keep one member per key holding the last value, at the key's first-seen position:
+    /// `{"a":1,"b":2,"a":3}` becomes `{"a":3,"b":2}`.
+    First,
+    /// Keep one member per key holding the last value, at the last occurrence's position:
+    /// `{"a":1,"b":2,"a":3}` becomes `{"b":2,"a":3}`.
+    Last,
+}
+
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum LineContinuation {
+    /// Remove the backslash and the line break, joining the lines: `"foo\<LF>bar"` becomes
+    /// `"foobar"`. Default.
+    Elide,
+    /// Remove the backslash and keep the line break as an escape: `"foo\<LF>bar"` becomes
+    /// `"foo\nbar"`.
+    Keep,
+}
+
 #[derive(Clone, Debug, Copy, PartialEq, Eq)]
 pub enum EngineKind {
     /// Auto selection (defaults to the recursive-descent engine for stability)
@@ -18,13 +238,96 @@ pub enum EngineKind {
     LlmCompat,
 }
 
+/// Progress callback for `Options::progress`, called as `f(bytes_done, bytes_total)`.
+///
+/// Cloning shares the same callback.
+#[derive(Clone)]
+pub struct Progress(Arc<dyn Fn(usize, usize) + Send + Sync>);
+
+impl Progress {
+    pub fn new(f: impl Fn(usize, usize) + Send + Sync + 'static) -> Self {
+        Self(Arc::new(f))
+    }
+
+    pub(crate) fn report(&self, done: usize, total: usize) {
+        (self.0)(done, total)
+    }
+}
+
+impl fmt::Debug for Progress {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str("Progress(..)")
+    }
+}
+
+/// Number formatter for `Options::number_format`, called with the text of each number in
+/// the output and returning the text to write instead.
+///
+/// Cloning shares the same callback.
+#[derive(Clone)]
+pub struct NumberFormat(Arc<dyn Fn(&str) -> String + Send + Sync>);
+
+impl NumberFormat {
+    pub fn new(f: impl Fn(&str) -> String + Send + Sync + 'static) -> Self {
+        Self(Arc::new(f))
+    }
+
+    pub(crate) fn format(&self, raw: &str) -> String {
+        (self.0)(raw)
+    }
+}
+
+impl fmt::Debug for NumberFormat {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str("NumberFormat(..)")
+    }
+}
+
+/// Parse trace for `Options::trace`: one line per parser decision, `@OFFSET what`, where
+/// `OFFSET` is the byte offset in the parsed input.
+///
+/// Cloning shares the same buffer, so keep a clone to read the trace after the repair.
+#[derive(Clone, Default)]
+pub struct Trace(Arc<Mutex<String>>);
+
+impl Trace {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// The lines recorded since the last call, clearing the buffer.
+    pub fn take(&self) -> String {
+        std::mem::take(&mut *self.lock())
+    }
+
+    pub(crate) fn line(&self, at: usize, what: fmt::Arguments<'_>) {
+        use fmt::Write;
+        let _ = writeln!(self.lock(), "@{at} {what}");
+    }
+
+    fn lock(&self) -> MutexGuard<'_, String> {
+        self.0.lock().unwrap_or_else(PoisonError::into_inner)
+    }
+}
+
+impl fmt::Debug for Trace {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str("Trace(..)")
+    }
+}
+
 #[derive(Clone, Debug)]
 pub struct Options {
     /// Treat `#` as a line comment (in addition to // and /* */) when not inside strings.
     /// Default: true. Disable for strict JSON.
     pub tolerate_hash_comments: bool,
+    /// Treat SQL-style `--` as a line comment between tokens (`{a: 1 -- note\n, b: 2}`).
+    /// The dashes must be followed by whitespace or the end of input, so `--5`, `a--b` and
+    /// `-5 - -3` are not comments. Default: false.
+    pub tolerate_sql_comments: bool,
     /// Convert the JavaScript value `undefined` into `null` when encountered as a value or symbol.
     /// Default: true. Matches common JSON‑like inputs from JS/LLMs.
+    /// Also unwraps JS computed keys: `{["a"]: 1}` and `{[a]: 1}` both become `{"a":1}`.
     pub repair_undefined: bool,
     /// Policy for numbers with leading zeros like 007.
     pub leading_zero_policy: LeadingZeroPolicy,
@@ -38,8 +341,26 @@ pub struct Options {
     /// Accept and normalize Python-style keywords True/False/None.
     /// Default: true to improve Python compatibility.
     pub allow_python_keywords: bool,
+    /// Accept `true`, `false` and `null` in other letter cases (`TRUE`, `FALSE`, `Null`,
+    /// `NULL`), as some serializers write them, and emit them in lowercase. The Python
+    /// spellings `True`, `False` and `None` are left to `allow_python_keywords`, so turning
+    /// that off still quotes them. Default: true.
+    pub case_insensitive_keywords: bool,
+    /// Read the single letters `t`, `f` and `n` as `true`, `false` and `null` in value
+    /// position, for terse formats that abbreviate them: `{ok: t, err: n}` becomes
+    /// `{"ok":true,"err":null}`. Only a letter standing alone counts: nothing but spaces or
+    /// tabs may separate it from the next `,`, `}`, `]`, line break or the end, so `tf`,
+    /// `t-shirt`, `n/a` and `t rex` stay strings, as do keys and uppercase `T`. Applies to
+    /// the recursive engine. Default: false.
+    pub abbreviated_keywords: bool,
+    /// Bare tokens that stand for a missing value in data exports, such as `N/A`, `-`, `NULL`
+    /// or `nil`: one in value position becomes `null`, so `{price: N/A}` gives
+    /// `{"price":null}`. Matching is exact and case-sensitive, and a token only counts
+    /// standing alone as `abbreviated_keywords` requires, so `N/A yet` and keys are repaired
+    /// as bare strings as usual. Applies to the recursive engine. Default: empty.
+    pub null_tokens: Vec<String>,
     /// When true, escape non-ASCII characters in strings as \uXXXX.
-    /// Default: false (preserve Unicode).
+    /// Default: false (preserve Unicode). Overrides `ascii_scope` when set.
     pub ensure_ascii: bool,
     /// Assume input is valid JSON and skip full serde roundtrip when possible.
     /// Applies only when `ensure_ascii == false`. Default: false (safer).
@@ -52,29 +373,80 @@ pub struct Options {
     /// When enabled, attach a JSON path to log entries (non-streaming only).
     /// Tracks array indices and object keys where available. Default: false.
     pub log_json_path: bool,
-    /// Normalize JavaScript non-finite numbers (NaN/Infinity/-Infinity) to null.
+    /// Normalize JavaScript non-finite numbers (NaN/Infinity/-Infinity) to null, including
+    /// the argument of a numeric typed wrapper such as `NumberDecimal("NaN")`.
     /// Default: true for pragmatic interoperability.
     pub normalize_js_nonfinite: bool,
     /// Aggregate streaming NDJSON outputs into a single JSON array.
     /// When enabled, `StreamRepairer::push` buffers values and emits on `flush()`.
     /// Default: false (emit per record).
     pub stream_ndjson_aggregate: bool,
+    /// Read each non-blank line of the input as a value of its own, repaired separately,
+    /// and collect the values into one array, for logs of one JSON value per line. Unlike
+    /// the array that wraps several root values, a line left open does not run into the
+    /// next one: `{a: 1\n{b: 2}` becomes `[{"a":1},{"b":2}]`, not `{"a":1,"b":2}`. Blank
+    /// lines and lines that repair to nothing (only a comment) are skipped. A line that
+    /// cannot be repaired (under `reject_if_invalid`, `max_repairs`, ...) is handled per
+    /// `salvage`: `Fail` returns its error, positioned in the whole input; `Null` and
+    /// `Marker` put a placeholder in its place. A timeout always fails the whole input.
+    /// Options that lay out the whole document (`indent_detect`, `compact_spacing`,
+    /// `output_format`, `output_bom`) apply to the array. Not applied by `StreamRepairer`,
+    /// `repair_to_string_with_log` or `repair_with_comments`. Default: false.
+    pub lines_to_array: bool,
+    /// Stop after the first complete root value and leave the rest of the input unread, for
+    /// protocols where only the first value matters: `{a: 1} {b: 2}` becomes `{"a":1}`
+    /// instead of `[{"a":1},{"b":2}]`. Unlike `repair_first`, the remainder is not looked at
+    /// at all, so its cost does not grow with it; the valid-JSON fast path, which would read
+    /// the whole input, is skipped. A body missing its opener gets it first, so `1, 2] 3`
+    /// becomes `[1,2]`. Applies to the recursive engine. Default: false.
+    pub stop_after_first: bool,
     /// Tolerance: treat a leading dot ".25" as "0.25". Default: true.
     pub number_tolerance_leading_dot: bool,
-    /// Tolerance: treat a trailing dot "1." as "1.0". Default: true.
+    /// Tolerance: treat a trailing dot "1." as "1.0". Before an exponent the dot is dropped
+    /// instead: "1.E3" becomes "1e3". Default: true.
     pub number_tolerance_trailing_dot: bool,
-    /// Tolerance: an incomplete exponent like "1e" falls back to the base number "1".
+    /// Tolerance: an incomplete exponent like "1e" or "1E+" falls back to the base number
+    /// "1"; when false the token is kept as a string ("\"1e\""). A sign and an exponent with no
+    /// mantissa digits ("-E5", "-.e3") is read as "0", or kept as a string ("\"-E5\"") when
+    /// false. Without the sign ("e5", "E1", ".e5") it is a bare word and is quoted either way.
     /// Default: true.
     pub number_tolerance_incomplete_exponent: bool,
     /// Quote suspicious number-like tokens containing non-number separators (e.g., 1/3, 10-20),
     /// multiple dots (e.g., 1.1.1), or mixed alphanumerics (e.g., 2notanumber). Default: true.
     pub number_quote_suspicious: bool,
-    /// Compatibility preset: enable Python-friendly tolerance behaviors.
-    /// Currently reserved for broader presets. Default: false.
+    /// Numbers written with a unit suffix of letters or `%` attached (`30s`, `10MB`,
+    /// `1.5GiB`, `100%`), as in config files: quote the whole token or keep only the
+    /// number, see [`NumberSuffixPolicy`]. A suffix after a space (`10 MB`), a hex literal
+    /// (`0xFF`) and a plain number (`30`) are never affected. Default: `Keep`.
+    pub number_suffix: NumberSuffixPolicy,
+    /// What to do with the sign of a zero (`-0`, `-0.0`, `-0e5`), for consumers that hash
+    /// or compare numbers by their text, see [`NegativeZeroPolicy`]. Input that is already
+    /// valid JSON is parsed rather than copied under `Normalize`. `normalize_numbers`
+    /// always emits `0` for negative zero. Default: `Preserve`.
+    pub negative_zero: NegativeZeroPolicy,
+    /// What to do with an integer whose magnitude exceeds the JavaScript safe range
+    /// (`2^53 - 1` = `9007199254740991`), where a consumer that reads numbers as doubles
+    /// would silently round it, see [`SafeIntegerPolicy`]. Only integer literals count;
+    /// numbers with a fraction or exponent are left alone. Input that is already valid
+    /// JSON is parsed rather than copied when this is not `Passthrough`.
+    /// Default: `Passthrough`.
+    pub safe_integers: SafeIntegerPolicy,
+    /// Compatibility preset: enable Python-friendly tolerance behaviors. Currently reads
+    /// the `repr` of a Python set as an array: braces holding a `,` and no `:` at their own
+    /// depth (`{1,2,3}` → `[1,2,3]`, `{'a','b'}` → `["a","b"]`). `{}` stays an empty object
+    /// and a brace with a colon anywhere at its depth (`{a, b: 1}`) stays an object, so a
+    /// one-element set `{1}` is read as an object key. Runs on the input, so it applies to
+    /// both engines. Default: false.
     pub compat_python_friendly: bool,
     /// Optional word comment markers like "COMMENT" that will be stripped when found in safe
     /// positions (e.g., immediately before an object key). Default: empty.
     pub word_comment_markers: Vec<String>,
+    /// Drop bare `...` placeholders, which LLMs write to mean "more omitted": `[1, 2, ...]`
+    /// becomes `[1,2]` and `{"a": 1, ...}` becomes `{"a":1}`, and a truncated container is
+    /// closed as usual. A quoted `"..."` is a normal string, and spread syntax (`...rest`,
+    /// `...{`) is not a placeholder. When off, a bare `...` is kept as the string `"..."`.
+    /// Default: true.
+    pub strip_ellipsis: bool,
     /// Aggressive truncation fix: when encountering extreme truncation inside an object/array,
     /// close the container early at a nearby safe boundary instead of failing or emitting null.
     /// Default: false (enable only when you need best‑effort recovery for highly truncated inputs).
@@ -88,33 +460,541 @@ pub struct Options {
     pub internal_no_stream_fallback: bool,
     /// Runtime engine selection: Auto/Recursive/LlmCompat. Default: Auto (stability first).
     pub engine: EngineKind,
+    /// Validation guard: when the input is not already valid JSON, return an
+    /// `InvalidJson` error pointing at the first problem instead of repairing it.
+    /// A leading BOM and surrounding whitespace are accepted. Default: false.
+    pub reject_if_invalid: bool,
+    /// Wall-clock budget for a single repair call, in milliseconds. When exceeded, repair
+    /// aborts with a `Timeout` error. The clock is read once every 256 parsed values,
+    /// container members or joined string parts (`"a" + "b"`), so a single huge token (e.g. one long string) finishes before the
+    /// deadline is noticed. Streaming applies the budget to each emitted segment.
+    /// Default: 0 (no limit).
+    pub timeout_ms: u64,
+    /// Progress reporting for large inputs: called with the bytes parsed so far and the
+    /// total input length, at most about 100 times per repair (checked as values are
+    /// parsed, like `timeout_ms`), then once more with `done == total` when the repair
+    /// succeeds. `StreamRepairer` does not report progress. Default: None.
+    pub progress: Option<Progress>,
+    /// Rewrite every number of the repaired output with a callback, for domain formats such
+    /// as two decimals for currency: `NumberFormat::new(|raw| format!("{:.2}", ..))`. It gets
+    /// the number as it would be written (`1.5`, `-2e3`) and only sees numbers: quoted
+    /// digits, keys and comments are not passed. A result that is not a JSON number fails the
+    /// repair with a `Parse` error at position 0, naming the number: the output is no longer
+    /// tied to input offsets. Runs on the output, so it applies to both engines. Default: None.
+    pub number_format: Option<NumberFormat>,
+    /// Developer aid for finding out why an input repairs the way it does: the recursive
+    /// engine appends a line to this trace for each value, key, container end and repair it
+    /// makes, and for the error that stopped it. Valid input that takes the serde fast path
+    /// gets a single line saying so. The LLM engine records nothing. Output is the same with
+    /// or without a trace, and when it is None nothing is formatted. Default: None.
+    pub trace: Option<Trace>,
+    /// Output formatting: prefix the repaired output with a UTF-8 BOM (`EF BB BF`) for consumers
+    /// that expect one. An input BOM is always stripped first, so the output carries exactly
+    /// one. Streaming writes it once, before the first emitted value. Default: false.
+    pub output_bom: bool,
+    /// Output formatting: guarantee output that can be embedded as-is, e.g. as a Go
+    /// `json.RawMessage`: compact strict JSON with no surrounding whitespace, trailing newline
+    /// or BOM. Takes precedence over `output_bom`, `indent_detect`, `compact_spacing` and
+    /// `output_format = Json5`. Output that is not JSON to begin with (`annotate_source`
+    /// comments, the empty output of an empty input) is only trimmed. Not applied by
+    /// `StreamRepairer`. Default: false.
+    pub raw_message_safe: bool,
+    /// Streaming: validate each completed root value against strict JSON instead of repairing
+    /// it. Statuses are collected with `StreamRepairer::push_validate` / `flush_validate`;
+    /// `push`/`flush` return no output in this mode. Default: false.
+    pub stream_validate_only: bool,
+    /// Trim leading/trailing whitespace from object keys, quoted or not (`{"name ": 1}` →
+    /// `{"name":1}`). Values are left untouched. Trimming happens while the key is parsed, so
+    /// any later key handling sees the trimmed key; two keys that differ only in surrounding
+    /// whitespace become duplicates and are both kept in source order. Default: false.
+    pub trim_keys: bool,
+    /// Unwrap JSON that was string-escaped an extra time: when the repaired output is a single
+    /// top-level string whose decoded content is itself strictly valid JSON (an object, an
+    /// array, or another such string), the content replaces it. Repeats for nested escaping up
+    /// to 8 levels; anything else (including strings that merely look like JSON) is kept as a
+    /// string. The unwrapped value is written out compactly. A whole document wrapped in single quotes (`'[1, 2]'`) is unwrapped the same way
+    /// when its content is a complete, strictly valid object or array. Inside a document, a
+    /// string value whose object or array was JSON-encoded two or more times (double-encoding,
+    /// often with too few backslashes: `{"p": "{\\"k\\": 1}"}`) is replaced by the decoded
+    /// container, up to the same 8 layers; a value escaped once is ordinary stringified JSON
+    /// and stays a string. Default: false.
+    pub unwrap_escaped_json: bool,
+    /// Give up with a `TooManyRepairs` error once more than this many repairs have been
+    /// applied: input that needs that many fixes is probably not JSON at all. Counted repairs
+    /// are quoting a bare key or value, converting a single-quoted string, normalizing a
+    /// keyword (`True`, `undefined`, ...), inserting a missing colon, comma or value, and
+    /// closing a truncated or mismatched container. Applies to the recursive engine; streaming
+    /// counts per emitted value. Default: 0 (unlimited).
+    pub max_repairs: usize,
+    /// Give up with a `TooManyElements` error once a single array or object has more than
+    /// this many members, to bound the work done on untrusted input that is wide rather
+    /// than deep. Members are counted as they are parsed, before any dedup, so input that
+    /// is already valid JSON goes through the parser instead of being copied as is.
+    /// Default: 0 (no limit).
+    pub max_elements: usize,
+    /// Give up with a `KeyTooLong` error once an object key is longer than this many bytes,
+    /// or cut it down under `LongKeyPolicy::Truncate`, to bound pathological keys in
+    /// untrusted input. The length is that of the key's UTF-8 text, before any escaping.
+    /// As with `max_elements`, input that is already valid JSON goes through the parser.
+    /// Default: 0 (no limit).
+    pub max_key_len: usize,
+    /// What to do with a key longer than `max_key_len`. Default: `Error`.
+    pub long_keys: LongKeyPolicy,
+    /// Fail with an `InputTooLarge` error, before any work is done, when the input is longer
+    /// than this many bytes, to protect services from oversized requests. Unlike
+    /// `max_elements` and `max_key_len` it is checked up front, so valid input may still
+    /// take the fast path. Not applied by `StreamRepairer`, which has `set_max_buffer`.
+    /// Default: 0 (no limit).
+    pub max_input_bytes: usize,
+    /// Wrap top-level fragments that are not a JSON document into one. Currently recognizes
+    /// newline-separated `key = value` lines (`.env`/TOML style, `#` comment lines and a
+    /// leading `export ` allowed) and assembles them into an object: `a = 1\nb = "x"` →
+    /// `{"a":1,"b":"x"}`. Values are repaired like any other value; an empty value becomes
+    /// `""`. A value whose bracket or quote is still open at the end of its line continues
+    /// on the following lines (`a = [1,\n2]`). Input with any other kind of line, such as a TOML `[section]`, is left to normal
+    /// repair. A single line of comma-separated quoted fields (a CSV row, `"a","b","c"`)
+    /// becomes an array, `["a","b","c"]`; one quoted string is left as a string.
+    /// Default: false.
+    pub wrap_fragments: bool,
+    /// Repair only the JSON embedded in surrounding prose: `Here is the result: {"a":1}.
+    /// Thanks!` → `{"a":1}`. The span starts at the first `{` or `[` whose closer is found
+    /// by bracket counting, with brackets inside strings skipped, and ends at that closer;
+    /// text before and after it is dropped. When that first opener is never closed (output
+    /// cut off mid-value), everything from it to the end is repaired. Input without any
+    /// `{` or `[` is repaired as a whole. Error positions and `annotate_source` offsets
+    /// still count from the start of the whole input. Default: false.
+    pub extract_embedded: bool,
+    /// What to do with an object key that has no value, with or without a colon (`{"a":}`,
+    /// `{"a", "b": 2}`, or a key cut off by truncation). Applies to the recursive engine.
+    /// Default: `EmptyString`.
+    pub missing_value_policy: MissingValuePolicy,
+    /// Which strings get non-ASCII characters escaped as \uXXXX: none, keys only, values
+    /// only, or all. A finer-grained `ensure_ascii`; `ensure_ascii = true` means `All`.
+    /// Default: `None`.
+    pub ascii_scope: AsciiScope,
+    /// Emit every number in one canonical spelling, for diffing and hashing: lowercase `e`,
+    /// no `+` or leading zeros in the exponent, no leading integer or trailing fraction
+    /// zeros, and `0` for negative zero (`1.50E+03` → `1.5e3`, `-0.0` → `0`). A zero
+    /// exponent is dropped (`1e0` → `1`); otherwise the value is never rounded or moved
+    /// between plain and exponent notation. Applies to the recursive engine. Default: false.
+    pub normalize_numbers: bool,
+    /// What to do with a number whose magnitude overflows an `f64` to infinity (`1e400`,
+    /// `-1e999`). Numbers that only lose precision or underflow to zero (`1e-400`) are not
+    /// affected. Input that is already valid JSON goes through the parser when this is not
+    /// `Keep`. Default: `Keep`.
+    pub overflow: OverflowPolicy,
+    /// Accept `=`, `=>` and `->` as key/value separators alongside `:` (`{a => 1, b = 2,
+    /// c -> 3, d: 4}`, as printed by Ruby, Perl, PHP array dumps and some ORMs) and emit `:`
+    /// for all of them. Separators inside strings are untouched, and a negative value after
+    /// `->` (`{a -> -5}`) stays a number. Applies to the recursive engine. Default: false.
+    pub equals_separators: bool,
+    /// Collapse duplicate object keys so the last value wins, placing the survivor at the
+    /// key's first-seen position (`First`) or at its last occurrence (`Last`). Keys compare
+    /// after unescaping, so `"a"` and `"\u0061"` are the same key. Runs on the repaired
+    /// output, so it applies to both engines; objects without duplicates are left as-is.
+    /// Default: `KeepAll` (duplicates are kept).
+    pub dedup_position: DedupPosition,
+    /// Drop repeated scalar elements from every array, keeping the first occurrence
+    /// (`[1,1,2,"a","a"]` → `[1,2,"a"]`). Strings compare by decoded text, numbers by their
+    /// `normalize_numbers` spelling (`1`, `1.0` and `1.00` are equal; `10` and `1e1` are
+    /// not), and `true`, `false` and `null` literally; a string never equals a number.
+    /// Objects and arrays are never dropped, though duplicates inside them are. Runs on the
+    /// repaired output like `dedup_position`. Default: false.
+    pub dedup_arrays: bool,
+    /// Order the members of every object by key, for output that compares equal whenever the
+    /// data does: `{"b":1,"a":{"d":2,"c":3}}` → `{"a":{"c":3,"d":2},"b":1}`. Keys compare by
+    /// decoded text in code point order; duplicate keys keep their relative order unless
+    /// `dedup_position` collapses them. Arrays keep their order. Runs on the repaired output
+    /// like `dedup_position`. Default: false.
+    pub sort_keys: bool,
+    /// Decode base64-wrapped input before repairing it. Only attempted when the whole input
+    /// (ignoring line breaks) is standard base64 with a length that is a multiple of 4, and
+    /// only used when it decodes to UTF-8 text starting with `{` or `[`; otherwise the input
+    /// is repaired as-is, so a bare word such as `true` is never decoded. Default: false.
+    pub decode_base64: bool,
+    /// Debugging aid: follow every parsed value with a `/* @src:OFFSET */` comment giving the
+    /// byte offset in the input where the value started (`{a: 1}` →
+    /// `{a:1/* @src:4 */}/* @src:0 */`), also when decoding or rewriting options changed the
+    /// text before it was parsed. Comments have no place in strict JSON, so this requires
+    /// `output_format = Json5` and fails with a `Parse` error otherwise; `dedup_position` is
+    /// skipped and valid input is re-parsed instead of copied. Values filled in for missing
+    /// ones carry no comment; streaming offsets are relative to each segment. Applies to the
+    /// recursive engine. Default: false.
+    pub annotate_source: bool,
+    /// What to do with a bare array element that is not a keyword (`true`, `null`, `None`,
+    /// `undefined`, ...): quote it, drop it, or fail. Used to control how aggressively
+    /// partial arrays are salvaged. Keywords, numbers and quoted strings are unaffected.
+    /// Applies to the recursive engine. Default: `Error`.
+    pub stray_tokens: StrayTokenPolicy,
+    /// What to do when an array element or object value fails with a `Parse` error (such
+    /// as a stray token under `StrayTokenPolicy::Error`) while the rest of the document is
+    /// repairable. The bad region runs from the start of that value to the next `,` or
+    /// closing bracket of its enclosing container; `Null` and `Marker` replace it and carry
+    /// on. Budget errors (`max_repairs`, `timeout_ms`) and top-level failures are never
+    /// salvaged. Applies to the recursive engine. Default: `Fail`.
+    pub salvage: SalvagePolicy,
+    /// Write this in place of content that would otherwise be dropped or nulled out: an
+    /// element removed by `StrayTokenPolicy::Drop` (`[1, junk, 2]` with `"?"` becomes
+    /// `[1,"?",2]`) and a value replaced under `SalvagePolicy::Null`, including unrepairable
+    /// lines of `lines_to_array`. A placeholder that is one JSON value (`null`, `-1`,
+    /// `{"dropped":true}`) is written minified; any other text is written as a string.
+    /// Applies to the recursive engine. Default: `None` (drop, or write `null`).
+    pub drop_placeholder: Option<String>,
+    /// How far to go with repairs that guess at structure (see `Strictness`). A value is
+    /// taken as a nested key only when it is a quoted string or an identifier directly
+    /// followed by `:` and another value; `http://x` and `C:\dir` are not. Applies to the
+    /// recursive engine; the LLM engine drops a colon between array elements
+    /// (`[a: 1]` → `["a",1]`). Default: `Conservative`.
+    pub strictness: Strictness,
+    /// Read brackets whose first element is followed by a colon as an object written with the
+    /// wrong brackets, in either `strictness`: `["a": 1, "b": 2]` becomes `{"a":1,"b":2}`. A
+    /// `]` or `}` closes it. Without it such an array follows `strictness`, failing or
+    /// wrapping each pair (`[{"a":1},{"b":2}]`). Applies to the recursive engine.
+    /// Default: false.
+    pub brackets_as_objects: bool,
+    /// How byte input that is not valid UTF-8 is treated (see `Utf8Strictness`). Applies to
+    /// `repair_bytes` and the C API functions that take options; `&str` input is valid by
+    /// construction, and streaming chunks are always checked strictly. Default: `Strict`.
+    pub utf8_strictness: Utf8Strictness,
+    /// Output syntax. `Json5` renders the repaired document as JSON5 with exactly two
+    /// changes: object keys that are ASCII identifiers (`[A-Za-z_$][A-Za-z0-9_$]*`) are
+    /// unquoted, and all other strings use single quotes (`{name: 'it\'s'}`). No trailing
+    /// commas are added and input comments are not carried over (the parser drops them);
+    /// `annotate_source` comments are kept. Applied last, after dedup and wrapping, and per
+    /// value when streaming. `repair_to_value`/`loads` always parse strict JSON. Default:
+    /// `Json`.
+    pub output_format: OutputFormat,
+    /// Read a European decimal comma in an object value: `{"price": 3,14}` becomes
+    /// `{"price":3.14}`. Only applied where the comma cannot be a separator: both sides are
+    /// plain digits with no space around the comma, and the fraction is followed by `}`, the
+    /// end of input, or `,` and the next key. Arrays are never touched (`[3,14]` stays two
+    /// elements), nor are `3, 14`, `3.5,1` or `{"a": 3,14: 1}`. A fraction of exactly three
+    /// digits is refused as a likely thousands separator: `{"a": 1,000}` is left as without
+    /// this option. Applies to the recursive engine. Default: false.
+    pub comma_decimal: bool,
+    /// Read a simple integer fraction in an object value or array element as its decimal
+    /// value: `{"ratio": 1/2}` becomes `{"ratio":0.5}`. Both sides must be bare integers of
+    /// at most 15 digits touching the slash, the numerator optionally negative, and the
+    /// fraction must be followed by `,`, `}`, `]` or the end of input. Anything that could be
+    /// a path or a date stays a string: `/usr/bin`, `1/usr`, `1 / 2`, `1/2/3`, `1.5/2`,
+    /// `01/02`, `1/0`. Applies to the recursive engine. Default: false.
+    pub eval_fractions: bool,
+    /// Escape every `/` inside strings as `\/` (`"</script>"` → `"<\/script>"`), for legacy
+    /// consumers that embed JSON in HTML `<script>` blocks. Slashes outside strings, such as
+    /// in the comments written by `annotate_source`, are not touched, and an existing `\/`
+    /// is kept as-is. Runs on the repaired output, so it applies to both engines.
+    /// Default: false.
+    pub escape_slashes: bool,
+    /// Write every string with the fewest escapes JSON allows: only `"`, `\\` and control
+    /// characters are escaped, so `\/` becomes `/` and `\u00e9` becomes `é`
+    /// (`"a\/b \u0041"` → `"a/b A"`). Non-ASCII characters stay escaped where
+    /// `ensure_ascii` or `ascii_scope` asks for it, and `escape_slashes` and
+    /// `short_escapes = false` still apply afterwards. A lone surrogate (`"x\ud800y"`) is
+    /// dropped while parsing, as without this option, so it gives `"xy"`. Runs on the
+    /// repaired output, so it applies to both engines. Default: false.
+    pub minimal_escapes: bool,
+    /// Escape control characters in strings with the short forms `\b \f \n \r \t` where
+    /// JSON has one, so a tab pasted into a string (`"x<TAB>y"`) becomes `"x\ty"`. When off,
+    /// every control character is written as `\u00XX` (`"x\u0009y"`), including short
+    /// escapes already in the input. Other control characters always use `\u00XX`. Runs on
+    /// the repaired output when off, so it applies to both engines. Default: true.
+    pub short_escapes: bool,
+    /// What a backslash directly before a line break inside a string (a shell- or C-style
+    /// line continuation, `"foo\<LF>bar"`) becomes: `Elide` joins the lines (`"foobar"`),
+    /// `Keep` keeps the break as `\n` (`"foo\nbar"`, or `\r\n` for a CRLF break). Applies
+    /// to both engines. Default: `Elide`.
+    pub line_continuations: LineContinuation,
+    /// Keep a lone backslash inside a string that does not start a valid escape, for Windows
+    /// paths written without doubled separators: `"C:\Users"` becomes `"C:\\Users"` instead
+    /// of losing the backslash (`"C:Users"`). Genuine escapes (`\"`, `\\`, `\/`, `\b`, `\f`,
+    /// `\n`, `\r`, `\t`, `\uXXXX`) are still decoded, so `"C:\new"` keeps its newline; a
+    /// `\u` without four hex digits is kept as text. Applies to the recursive engine.
+    /// Default: false.
+    pub fix_backslashes: bool,
+    /// Remove spaces and tabs at the end of each line inside multi-line string values, for
+    /// code snippets stored as JSON: `"a = 1;  \n  b"` becomes `"a = 1;\n  b"`. Only
+    /// whitespace directly before a `\n` or `\r` is dropped, so leading indentation and the
+    /// end of the last line are kept, and keys are not touched. Runs on the repaired output,
+    /// so it applies to both engines. Default: false.
+    pub strip_trailing_line_ws: bool,
+    /// Collapse each run of whitespace inside string values to a single space, for scraped
+    /// text stored as JSON: `"a \t  b"` becomes `"a b"`. Spaces, tabs, form feeds and vertical
+    /// tabs count as whitespace, raw or escaped. The ends of the string are not trimmed and
+    /// keys are not touched. This changes the data, so only turn it on when the strings are
+    /// prose. Line breaks follow `collapse_ws_newlines`. Runs on the repaired output, so it
+    /// applies to both engines. Default: false.
+    pub collapse_ws: bool,
+    /// With `collapse_ws`, also fold `\n` and `\r` into the run, so `"a \n\n b"` becomes
+    /// `"a b"`. When off, each line break is kept and only the whitespace around it is
+    /// collapsed: `"a  \n  b"` becomes `"a \n b"`. No effect without `collapse_ws`.
+    /// Default: false.
+    pub collapse_ws_newlines: bool,
+    /// Add the outermost brackets to an input that is only a container body. When the first
+    /// token is a quoted or bare key followed by `:` (not `://`), the input is wrapped as an
+    /// object (`"a": 1, "b": 2` → `{"a":1,"b":2}`). Otherwise, when it starts with a number,
+    /// quoted string or `true`/`false`/`null` and has a `,` outside strings and brackets, it is
+    /// wrapped as an array (`1, 2, 3` → `[1,2,3]`). Input starting with `{` or `[`, and a lone
+    /// scalar, are left as they are. Default: false.
+    pub add_missing_brackets: bool,
+    /// Read a parenthesized list in value position as an array, for tuple-style dumps:
+    /// `(1, 2, 3)` becomes `[1,2,3]` and `{"p": (0, (1, 2))}` becomes `{"p":[0,[1,2]]}`.
+    /// A `(` counts as an opener only at the start of the input or after `: , [ (`, so the
+    /// argument list of a call such as `ObjectId("x")` is left to `unwrap_functions`.
+    /// Parentheses inside strings and comments are kept. Runs on the input, so it applies
+    /// to both engines. Default: false.
+    pub parens_as_arrays: bool,
+    /// Whitespace between tokens of the compact output. `Minimal` puts exactly one space
+    /// after every `:` and `,` outside strings (`{"a": [1, 2], "b": {"c": null}}`) and
+    /// removes any other whitespace, so valid input copied through unchanged is re-spaced
+    /// too. Runs on the repaired output, unlike `python_style_separators`, so it applies to
+    /// both engines. Default: `None`.
+    pub compact_spacing: CompactSpacing,
+    /// Pretty-print the output with the input's own indentation unit, for reformatting an
+    /// existing file with minimal churn. The unit is the most common leading whitespace of
+    /// the lines that start inside the top-level container (a tab, four spaces, ...); lines
+    /// indented with a mix of tabs and spaces are re-indented with that unit throughout. When
+    /// the input has no indented lines (one-line input), the output stays compact. The unit
+    /// is read from the input as given, before compatibility rewrites such as
+    /// `compat_python_friendly` compact parts of it. Overrides `compact_spacing` when a unit
+    /// is found. Not applied by `StreamRepairer`. Default: false.
+    pub indent_detect: bool,
+    /// With `indent_detect`, pad the space after each `:` so that the values of an object
+    /// start in the same column, for generated config files. Each object is aligned on its
+    /// own longest key; nested objects do not affect their parent. Has no effect when the
+    /// output is not indented (no unit detected) or with `OutputFormat::Json5`, whose
+    /// unquoted keys would shift the columns. Default: false.
+    pub align_values: bool,
+    /// End the lines of pretty-printed output with `\r\n` instead of `\n`, for tools on
+    /// Windows. Only the line breaks added by the layout change (`indent_detect` and the
+    /// pretty form of `repair_to_string_both`); newlines inside strings stay escaped as
+    /// `\n`, and compact output has no line breaks to change. Default: false.
+    pub crlf: bool,
+    /// Join string literals separated only by whitespace, newlines or comments into one
+    /// string, as models emit multi-line text: `"line1"\n"line2"` becomes `"line1line2"`,
+    /// like `"line1" + "line2"`. A following string that is an object key (followed by
+    /// `:`) is not joined, so `{"a": "x"\n"b": 1}` keeps its two members. Without this,
+    /// adjacent strings are separate values (`["a" "b"]` is `["a","b"]`). Default: false.
+    pub concat_adjacent_strings: bool,
+    /// Make sure the top level is an object or array, for consumers whose schema expects a
+    /// container. A top-level scalar (string, number, `true`/`false`/`null`) is wrapped as
+    /// `[value]` or `{"value":value}`; a top-level object or array, including the array that
+    /// aggregates several root values, is left as-is, and empty output stays empty. Applied
+    /// after `unwrap_escaped_json`, so an unwrapped scalar is wrapped too. Streaming wraps
+    /// each emitted value. Default: `Off`.
+    pub force_container: ForceContainer,
+    /// Wrap the output in an object that carries repair metadata alongside the data, for
+    /// pipelines that only pass JSON: `{a: 1}` becomes
+    /// `{"value":{"a":1},"repaired":true,"repairs":1}`. `repaired` is whether the value differs
+    /// from the input, as in `repair_to_string_changed`; `repairs` counts the fixes the
+    /// recursive engine applied (the categories in `repair_categories`). An empty value is
+    /// `null`. `output_bom` goes before the envelope. Streaming and `repair_split` wrap each
+    /// value. Default: false.
+    pub envelope: bool,
+    /// Repair UTF-8 curly quotes, dashes and ellipses that were misdecoded as Windows-1252 or
+    /// Latin-1 (`â€œ`, `â€`, `â€˜`, `â€™`, `â€“`, `â€”`, `â€¦`) before repairing. A mojibake
+    /// quote outside a string becomes the ASCII delimiter it stood for (`{â€œkeyâ€: 1}` →
+    /// `{"key":1}`); inside a string, and for dashes, the intended character is restored.
+    /// Heuristic, so opt-in. Default: false.
+    pub fix_mojibake: bool,
+    /// Extra (opening, closing) character pairs that delimit strings like `"`, for text
+    /// quoted with guillemets or CJK brackets: with [`GUILLEMET_QUOTES`], `{«name»: «Zoë»}`
+    /// becomes `{"name":"Zoë"}`. Extend it with [`CJK_CORNER_QUOTES`] or pairs of your own.
+    /// The characters are only delimiters outside a string; inside an ASCII-quoted string
+    /// they are kept (`"«a»"` stays as written). Not applied by `StreamRepairer`.
+    /// Default: empty.
+    pub alt_quotes: Vec<(char, char)>,
+    /// Typed wrappers `Name(arg)` to replace with their argument, converted per
+    /// [`UnwrapMode`]: `{_id: ObjectId("5f1e"), n: NumberLong("42")}` becomes
+    /// `{"_id":"5f1e","n":42}`. Names are case-sensitive; only the first argument is kept and
+    /// `Name()` becomes `null`. A call nested more than 64 deep in the arguments of others is
+    /// a `Parse` error. Register more with [`Options::add_unwrap_function`], or clear the list
+    /// to quote such calls as bare text. Default: [`BUILTIN_UNWRAP_FUNCTIONS`].
+    pub unwrap_functions: Vec<(String, UnwrapMode)>,
+    /// Keyword pairs (opening, closing) that stand for brackets, for legacy exports that write
+    /// `BEGIN ... END` or `OBJECT ... ENDOBJECT` instead of braces: with `BEGIN`/`END` as
+    /// [`BracketKind::Object`], `BEGIN a: 1 END` becomes `{"a":1}`. Words match whole and
+    /// case-sensitively, and only outside strings and comments; a word used as a key
+    /// (`{END: 1}`) is left alone. Register with [`Options::add_bracket_alias`]. Not applied
+    /// by `StreamRepairer`. Default: empty.
+    pub bracket_aliases: Vec<(String, String, BracketKind)>,
+    /// C API only: when the input cannot be repaired, `jsonrepair_repair_with_options()` and
+    /// `jsonrepair_repair_ex()` return the JSON object `{"error":"...","offset":N}` instead of
+    /// NULL, for callers that always expect JSON back (`_ex` still fills in the error). Rust
+    /// functions keep returning `Err`. Default: false.
+    pub error_as_json: bool,
 }
 
 impl Default for Options {
     fn default() -> Self {
         Self {
             tolerate_hash_comments: true,
+            tolerate_sql_comments: false,
             repair_undefined: true,
             leading_zero_policy: LeadingZeroPolicy::KeepAsNumber,
             fenced_code_blocks: true,
             logging: false,
             allow_python_keywords: true,
+            case_insensitive_keywords: true,
+            abbreviated_keywords: false,
+            null_tokens: Vec::new(),
             ensure_ascii: false,
             assume_valid_json_fastpath: false,
             log_context_window: 10,
             log_json_path: false,
             normalize_js_nonfinite: true,
             stream_ndjson_aggregate: false,
+            lines_to_array: false,
+            stop_after_first: false,
             number_tolerance_leading_dot: true,
             number_tolerance_trailing_dot: true,
             number_tolerance_incomplete_exponent: true,
             number_quote_suspicious: true,
+            number_suffix: NumberSuffixPolicy::Keep,
+            negative_zero: NegativeZeroPolicy::Preserve,
+            safe_integers: SafeIntegerPolicy::Passthrough,
             compat_python_friendly: false,
             word_comment_markers: Vec::new(),
+            strip_ellipsis: true,
             aggressive_truncation_fix: false,
             python_style_separators: false,
             internal_no_stream_fallback: false,
             engine: EngineKind::Auto,
+            reject_if_invalid: false,
+            timeout_ms: 0,
+            progress: None,
+            number_format: None,
+            trace: None,
+            output_bom: false,
+            raw_message_safe: false,
+            stream_validate_only: false,
+            trim_keys: false,
+            unwrap_escaped_json: false,
+            max_repairs: 0,
+            max_elements: 0,
+            max_key_len: 0,
+            long_keys: LongKeyPolicy::Error,
+            max_input_bytes: 0,
+            wrap_fragments: false,
+            extract_embedded: false,
+            missing_value_policy: MissingValuePolicy::EmptyString,
+            ascii_scope: AsciiScope::None,
+            normalize_numbers: false,
+            overflow: OverflowPolicy::Keep,
+            equals_separators: false,
+            dedup_position: DedupPosition::KeepAll,
+            dedup_arrays: false,
+            sort_keys: false,
+            decode_base64: false,
+            annotate_source: false,
+            stray_tokens: StrayTokenPolicy::Error,
+            salvage: SalvagePolicy::Fail,
+            drop_placeholder: None,
+            strictness: Strictness::Conservative,
+            brackets_as_objects: false,
+            utf8_strictness: Utf8Strictness::Strict,
+            output_format: OutputFormat::Json,
+            comma_decimal: false,
+            eval_fractions: false,
+            escape_slashes: false,
+            minimal_escapes: false,
+            short_escapes: true,
+            line_continuations: LineContinuation::Elide,
+            fix_backslashes: false,
+            strip_trailing_line_ws: false,
+            collapse_ws: false,
+            collapse_ws_newlines: false,
+            add_missing_brackets: false,
+            parens_as_arrays: false,
+            compact_spacing: CompactSpacing::None,
+            indent_detect: false,
+            align_values: false,
+            crlf: false,
+            concat_adjacent_strings: false,
+            force_container: ForceContainer::Off,
+            envelope: false,
+            fix_mojibake: false,
+            alt_quotes: Vec::new(),
+            unwrap_functions: BUILTIN_UNWRAP_FUNCTIONS
+                .iter()
+                .map(|&(name, mode)| (name.to_string(), mode))
+                .collect(),
+            bracket_aliases: Vec::new(),
+            error_as_json: false,
         }
     }
 }
+
+impl Options {
+    /// Set the storage preset: one canonical, compact form of the data for deduplicating
+    /// and content-addressed storage, so inputs that differ only in layout, key order,
+    /// escapes or number spelling repair to the same bytes. Sets `raw_message_safe` (compact,
+    /// no surrounding whitespace, trailing newline or BOM), `sort_keys`,
+    /// `dedup_position = Last` (a repeated key keeps its last value), `normalize_numbers`
+    /// and `minimal_escapes`, and for the escapes to be minimal turns off `ensure_ascii`,
+    /// `ascii_scope` and `escape_slashes` and turns on `short_escapes`. Other options keep
+    /// their values; `normalize_numbers` applies to the recursive engine only.
+    pub fn preset_storage(&mut self) {
+        self.raw_message_safe = true;
+        self.sort_keys = true;
+        self.dedup_position = DedupPosition::Last;
+        self.normalize_numbers = true;
+        self.minimal_escapes = true;
+        self.ensure_ascii = false;
+        self.ascii_scope = AsciiScope::None;
+        self.escape_slashes = false;
+        self.short_escapes = true;
+    }
+
+    /// Unwrap `name(arg)` with `mode` (see `unwrap_functions`), replacing any earlier mode
+    /// registered for `name`.
+    pub fn add_unwrap_function(&mut self, name: impl Into<String>, mode: UnwrapMode) {
+        let name = name.into();
+        self.unwrap_functions.retain(|(n, _)| *n != name);
+        self.unwrap_functions.push((name, mode));
+    }
+
+    /// Read the words `open` and `close` as the brackets of `kind` (see `bracket_aliases`),
+    /// replacing any earlier alias with the same opening word.
+    pub fn add_bracket_alias(
+        &mut self,
+        open: impl Into<String>,
+        close: impl Into<String>,
+        kind: BracketKind,
+    ) {
+        let open = open.into();
+        self.bracket_aliases.retain(|(o, _, _)| *o != open);
+        self.bracket_aliases.push((open, close.into(), kind));
+    }
+
+    /// The mode registered for the wrapper `name`, if any.
+    pub(crate) fn unwrap_mode(&self, name: &str) -> Option<UnwrapMode> {
+        self.unwrap_functions
+            .iter()
+            .find(|(n, _)| n == name)
+            .map(|&(_, mode)| mode)
+    }
+
+    /// Whether non-ASCII characters in object keys are escaped.
+    pub(crate) fn ascii_keys(&self) -> bool {
+        self.ensure_ascii || matches!(self.ascii_scope, AsciiScope::KeysOnly | AsciiScope::All)
+    }
+
+    /// Whether non-ASCII characters in values are escaped.
+    pub(crate) fn ascii_values(&self) -> bool {
+        self.ensure_ascii || matches!(self.ascii_scope, AsciiScope::ValuesOnly | AsciiScope::All)
+    }
+
+    /// The JSON text written for `drop_placeholder`, if one is set.
+    pub(crate) fn placeholder_json(&self) -> Option<String> {
+        let p = self.drop_placeholder.as_deref()?;
+        Some(crate::strict::minify(p).unwrap_or_else(|_| {
+            let mut quoted = String::with_capacity(p.len() + 2);
+            let _ = crate::parser::emit_json_string_from_lit(
+                &mut crate::emit::StringEmitter::new(&mut quoted),
+                p,
+                self.ascii_values(),
+            );
+            quoted
+        }))
+    }
+}
@@ -1,43 +1,114 @@
 #![allow(clippy::needless_lifetimes)]
 
 use super::lex::{skip_ellipsis, skip_word_markers, skip_ws_and_comments};
-use super::number::parse_number_token;
+use super::number::{is_plus_signed_number, parse_number_token};
+use super::object::{nested_key_ahead, parse_fraction_or_number};
 use super::strings::parse_string_literal_concat_fast;
-use crate::emit::{Emitter, JRResult};
-use crate::options::Options;
+use crate::emit::{Emitter, JRResult, StringEmitter};
+use crate::options::{Options, SalvagePolicy, StrayTokenPolicy, Strictness};
 use crate::parser::parse_regex_literal;
 use crate::parser::parse_symbol_or_unquoted_string;
-use memchr::memchr2;
+use crate::parser::{Checkpoint, Step};
+use crate::parser::{null_token_len, parse_null_token};
 
-pub fn parse_array<'i, E: Emitter>(
+/// An array being parsed, kept on the explicit stack of `parse_container`.
+pub(super) struct ArrayFrame<'i> {
+    first: bool,
+    idx: usize,
+    // Opened at a `{` holding only values (`{1, 2}`), so a `}` closes it as written.
+    braced: bool,
+    // Where the nested container being parsed as an element started, for `salvage` and
+    // `annotate_source` once it is done.
+    element: Option<(Checkpoint<'i>, usize)>,
+}
+
+/// Consume the `[` at `input`, or the `{` of braced values when `braced`. Returns the frame
+/// for its elements, or `None` when the array is empty and already closed.
+pub(super) fn open_array<'i, E: Emitter>(
     input: &mut &'i str,
     opts: &Options,
     out: &mut E,
-    logger: &mut crate::parser::Logger,
-) -> JRResult<()> {
-    if !input.starts_with('[') {
-        return Ok(());
-    }
+    braced: bool,
+) -> JRResult<Option<ArrayFrame<'i>>> {
     *input = &input[1..];
     out.emit_char('[')?;
     // Enter-array fast path: if only ASCII whitespace before a closing ']', close immediately.
     if let Some(']') = fast_ws_to_only_rbracket(input) {
         out.emit_char(']')?;
-        return Ok(());
+        return Ok(None);
     }
     skip_ws_and_comments(input, opts);
-    let mut first = true;
-    let mut idx = 0usize;
+    Ok(Some(ArrayFrame {
+        first: true,
+        idx: 0,
+        braced,
+        element: None,
+    }))
+}
+
+/// Parse elements until the array closes or an element is a nested container or key chain,
+/// which is left at `input` for `parse_container`. `nested` is the result of the nested container
+/// that was left last time.
+pub(super) fn array_step<'i, E: Emitter>(
+    frame: &mut ArrayFrame<'i>,
+    nested: Option<JRResult<()>>,
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<Step> {
+    let mut parsed = nested.zip(frame.element.take());
     'outer: loop {
+        if let Some((parsed, (cp, start))) = parsed.take() {
+            if let Err(err) = parsed {
+                super::salvage(err, cp, input, opts, out, logger)?;
+            }
+            logger.annotate(out, start)?;
+            logger.pop_index();
+            frame.idx += 1;
+            // Fast path after element: ASCII ws -> next delimiter ',' or ']'
+            if let Some(delim) = fast_ws_to_comma_or_rbracket(input) {
+                match delim {
+                    ',' => {
+                        continue 'outer;
+                    }
+                    ']' => {
+                        out.emit_char(']')?;
+                        break 'outer;
+                    }
+                    _ => unreachable!(),
+                }
+            } else {
+                // Fallback: generic skipping and optional comma consumption
+                skip_ws_and_comments(input, opts);
+                if input.starts_with('}') {
+                    close_at_brace(frame.braced, input, out, logger)?;
+                    break;
+                }
+                if input.starts_with(',') {
+                    *input = &input[1..];
+                } else if input.starts_with(':') {
+                    // `[1: 2: 3]`: a colon after an element separates it from the next one.
+                    // Elements that can be keys never get here: a string or word followed
+                    // by a colon is taken by `nested_key_ahead` or a keyed array instead.
+                    logger.repair(input.len(), "replaced ':' separator with comma")?;
+                    *input = &input[1..];
+                } else if !input.is_empty() && !input.starts_with(']') {
+                    logger.repair(input.len(), "inserted missing comma")?;
+                }
+            }
+        }
         skip_ws_and_comments(input, opts);
         if input.is_empty() {
             // best-effort close
+            logger.repair(0, "closed unterminated array")?;
             out.emit_char(']')?;
             break;
         }
-        // If we see a closing '}' here, best-effort close the array and let outer object handle it
+        // A closing '}' here either closes an enclosing object, so close the array and let
+        // the object handle it, or was written for this array (`[1, 2}, "b": 3]`).
         if input.starts_with('}') {
-            out.emit_char(']')?;
+            close_at_brace(frame.braced, input, out, logger)?;
             break;
         }
         if input.starts_with(']') {
@@ -49,7 +120,7 @@ pub fn parse_array<'i, E: Emitter>(
             break;
         }
         skip_word_markers(input, &opts.word_comment_markers);
-        while skip_ellipsis(input) {
+        while skip_ellipsis(input, opts) {
             skip_ws_and_comments(input, opts);
         }
         // optional comma between elements (fast path: ASCII ws -> ',' or ']')
@@ -73,6 +144,55 @@ pub fn parse_array<'i, E: Emitter>(
                 break;
             }
         }
+        // A comma with no element after it (`[,]`, `[/* empty */,]`, `[1, ...]`) is a
+        // trailing comma; at the end of input the loop top closes the array.
+        skip_ws_and_comments(input, opts);
+        while skip_ellipsis(input, opts) {
+            skip_ws_and_comments(input, opts);
+        }
+        if let Some(rest) = input.strip_prefix(']') {
+            *input = rest;
+            out.emit_char(']')?;
+            break;
+        }
+        if input.is_empty() {
+            continue;
+        }
+        // `stray_tokens`: decide on a bare non-keyword element before its comma is emitted.
+        if opts.stray_tokens != StrayTokenPolicy::Quote
+            && !nested_key_ahead(input, opts)
+            && let Some(rest) = take_stray_token(input, opts)
+        {
+            if opts.stray_tokens == StrayTokenPolicy::Error {
+                let mut at = *input;
+                skip_ws_and_comments(&mut at, opts);
+                let err = super::to_err(logger.position(at.len()), "stray token in array");
+                if opts.salvage == SalvagePolicy::Fail {
+                    return Err(err);
+                }
+                if !frame.first {
+                    out.emit_char(',')?;
+                }
+                frame.first = false;
+                logger.count_member(frame.idx + 1, at.len())?;
+                let cp = logger.checkpoint(at, out);
+                super::salvage(err, cp, input, opts, out, logger)?;
+                frame.idx += 1;
+                continue 'outer;
+            }
+            logger.repair(input.len(), "dropped stray token")?;
+            if let Some(placeholder) = opts.placeholder_json() {
+                if !frame.first {
+                    out.emit_char(',')?;
+                }
+                frame.first = false;
+                logger.count_member(frame.idx + 1, input.len())?;
+                out.emit_str(&placeholder)?;
+                frame.idx += 1;
+            }
+            *input = rest;
+            continue 'outer;
+        }
         // Look-ahead: if we see an immediately truncated object like "{]",
         // drop the partial element and close the array (Python parity).
         if input.starts_with('{') {
@@ -85,107 +205,139 @@ pub fn parse_array<'i, E: Emitter>(
             }
         }
         // emit comma only when we are going to output an element
-        if !first {
+        if !frame.first {
             out.emit_char(',')?;
         }
-        first = false;
+        frame.first = false;
         // Pre-trim whitespace and ellipsis placeholders before parsing element
         skip_ws_and_comments(input, opts);
-        while skip_ellipsis(input) {
+        while skip_ellipsis(input, opts) {
             skip_ws_and_comments(input, opts);
         }
+        logger.count_member(frame.idx + 1, input.len())?;
+        logger.tick(input.len())?;
         // Track array index for value path
-        logger.push_index(idx);
+        logger.push_index(frame.idx);
         if input.is_empty() {
+            logger.repair(0, "closed unterminated array")?;
             out.emit_char(']')?;
             break;
         }
+        logger.trace_value(input);
+        let start = logger.source_offset(input);
+        let cp = logger.checkpoint(input, out);
         let c = input.chars().next().unwrap();
-        match c {
-            '{' => super::object::parse_object(input, opts, out, logger)?,
-            '[' => parse_array(input, opts, out, logger)?,
-            '"' | '\'' => parse_string_literal_concat_fast(input, opts, out)?,
-            '/' => parse_regex_literal(input, opts, out)?,
-            c if c == '-' || c == '.' || c.is_ascii_digit() => {
-                parse_number_token(input, opts, out)?
-            }
-            _ => parse_symbol_or_unquoted_string(input, opts, out, logger)?,
-        }
-        logger.pop_index();
-        idx += 1;
-        // Fast path after element: ASCII ws -> next delimiter ',' or ']'
-        if let Some(delim) = fast_ws_to_comma_or_rbracket(input) {
-            match delim {
-                ',' => {
-                    continue 'outer;
+        let element = match c {
+            // `[a: 1, b: 2]`: a key and value where an element belongs.
+            _ if nested_key_ahead(input, opts) => match opts.strictness {
+                Strictness::Conservative => Err(super::to_err(
+                    logger.position(input.len()),
+                    "array element followed by a colon",
+                )),
+                Strictness::Aggressive => {
+                    frame.element = Some((cp, start));
+                    return Ok(Step::Member);
                 }
-                ']' => {
-                    out.emit_char(']')?;
-                    break 'outer;
+            },
+            _ if null_token_len(input, opts).is_some() => {
+                parse_null_token(input, opts, out, logger)
+            }
+            '{' | '[' => {
+                frame.element = Some((cp, start));
+                return Ok(Step::Descend);
+            }
+            '"' | '\'' => {
+                if c == '\'' {
+                    logger.repair(input.len(), "converted single-quoted string")?;
                 }
-                _ => unreachable!(),
+                parse_string_literal_concat_fast(input, opts, out, logger)
             }
-        } else {
-            // Fallback: generic skipping and optional comma consumption
-            skip_ws_and_comments(input, opts);
-            if input.starts_with('}') {
-                out.emit_char(']')?;
-                break;
+            '/' => parse_regex_literal(input, opts, out),
+            c if c == '-' || c == '.' || c.is_ascii_digit() => {
+                parse_fraction_or_number(input, opts, out, logger)
             }
-            if input.starts_with(',') {
+            '+' if is_plus_signed_number(input) => {
                 *input = &input[1..];
+                parse_number_token(input, opts, out)
             }
-        }
+            _ => parse_symbol_or_unquoted_string(input, opts, out, logger),
+        };
+        parsed = Some((element, (cp, start)));
     }
-    Ok(())
+    Ok(Step::Done)
 }
 
-#[inline]
-fn fast_ws_to_only_rbracket(input: &mut &str) -> Option<char> {
-    let s = *input;
-    if s.is_empty() {
+// If the next element is a bare token the value parser would quote (not a keyword), return
+// the input after it. Parsed into a scratch buffer with a scratch logger, so nothing is
+// emitted or counted.
+fn take_stray_token<'i>(input: &'i str, opts: &Options) -> Option<&'i str> {
+    let mut look = input;
+    skip_ws_and_comments(&mut look, opts);
+    while skip_ellipsis(&mut look, opts) {
+        skip_ws_and_comments(&mut look, opts);
+    }
+    match look.chars().next()? {
+        '{' | '[' | ']' | '}' | ',' | '"' | '\'' | '/' | '-' | '.' => return None,
+        c if c.is_ascii_digit() => return None,
+        '+' if is_plus_signed_number(look) => return None,
+        _ => {}
+    }
+    if crate::parser::unwrap_call(look, opts).is_some() {
         return None;
     }
-    let bytes = s.as_bytes();
-    if let Some(pos) = memchr2(b',', b']', bytes) {
-        // If we found a comma before ']', this isn't an immediate close; ignore.
-        if bytes[pos] == b',' {
-            return None;
-        }
-        // Ensure all bytes before ']' are ASCII whitespace only.
-        for &b in &bytes[..pos] {
-            match b {
-                b' ' | b'\t' | b'\n' | b'\r' => {}
-                _ => return None,
-            }
+    let mut tmp = String::new();
+    let mut se = StringEmitter::new(&mut tmp);
+    let mut scratch = crate::parser::Logger::default();
+    parse_symbol_or_unquoted_string(&mut look, opts, &mut se, &mut scratch).ok()?;
+    tmp.starts_with('"').then_some(look)
+}
+
+#[inline]
+fn fast_ws_to_only_rbracket(input: &mut &str) -> Option<char> {
+    // Only the whitespace prefix is scanned: searching ahead for the delimiter would
+    // rescan the rest of the input at every level of deep nesting.
+    let bytes = input.as_bytes();
+    let pos = bytes
+        .iter()
+        .position(|b| !matches!(b, b' ' | b'\t' | b'\n' | b'\r'))?;
+    match bytes[pos] {
+        b']' => {
+            *input = &input[pos + 1..];
+            Some(']')
         }
-        *input = &s[pos + 1..];
-        Some(']')
-    } else {
-        None
+        _ => None,
     }
 }
 #[inline]
 fn fast_ws_to_comma_or_rbracket(input: &mut &str) -> Option<char> {
-    let s = *input;
-    if s.is_empty() {
-        return None;
-    }
-    let bytes = s.as_bytes();
-    // Find next ',' or ']' quickly
-    if let Some(pos) = memchr2(b',', b']', bytes) {
-        // Ensure the prefix is only ASCII whitespace
-        for &b in &bytes[..pos] {
-            match b {
-                b' ' | b'\t' | b'\n' | b'\r' => {}
-                _ => return None,
-            }
+    let bytes = input.as_bytes();
+    let pos = bytes
+        .iter()
+        .position(|b| !matches!(b, b' ' | b'\t' | b'\n' | b'\r'))?;
+    match bytes[pos] {
+        delim @ (b',' | b']') => {
+            *input = &input[pos + 1..];
+            Some(delim as char)
         }
-        let delim = bytes[pos] as char;
-        *input = &s[pos + 1..];
-        Some(delim)
+        _ => None,
+    }
+}
+
+// Close the array at a `}`, consuming it when the array was `braced` or it is a crossed
+// closer meant for the array.
+fn close_at_brace<E: Emitter>(
+    braced: bool,
+    input: &mut &str,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<()> {
+    if braced {
+        *input = &input[1..];
+    } else if logger.crossed_closer(input) {
+        logger.repair(input.len(), "closed array at crossed brace")?;
+        *input = &input[1..];
     } else {
-        // No delimiter ahead; nothing to do
-        None
+        logger.repair(input.len(), "closed array at mismatched brace")?;
     }
+    out.emit_char(']')
 }
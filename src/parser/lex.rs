@@ -7,6 +7,16 @@ pub fn skip_bom(input: &mut &str) {
     }
 }
 
+/// Byte length of a zero-width character (BOM/ZWNBSP U+FEFF, U+200B..U+200D, U+2060)
+/// at the start of `b`, or 0. These are insignificant between tokens.
+#[inline]
+pub fn zero_width_len(b: &[u8]) -> usize {
+    match b {
+        [0xEF, 0xBB, 0xBF, ..] | [0xE2, 0x80, 0x8B..=0x8D, ..] | [0xE2, 0x81, 0xA0, ..] => 3,
+        _ => 0,
+    }
+}
+
 /// Optimized combined whitespace and comment skipper.
 /// 🟢 Uses memchr for fast comment scanning while maintaining fast ASCII whitespace path.
 #[inline]
@@ -21,6 +31,10 @@ pub fn skip_ws_and_comments(input: &mut &str, opts: &Options) {
         while i < bytes.len() {
             match bytes[i] {
                 b' ' | b'\t' | b'\n' | b'\r' => i += 1,
+                // Stray vertical tab / form feed between tokens
+                0x0B | 0x0C => i += 1,
+                // Stray BOM / zero-width characters (e.g. from concatenated files)
+                0xE2 | 0xEF if zero_width_len(&bytes[i..]) > 0 => i += 3,
                 _ => break,
             }
         }
@@ -78,6 +92,17 @@ pub fn skip_ws_and_comments(input: &mut &str, opts: &Options) {
             continue;
         }
 
+        // SQL-style comment: -- (followed by whitespace)
+        if opts.tolerate_sql_comments && starts_sql_comment(input) {
+            let rest = &input[2..];
+            if let Some(pos) = memchr2(b'\n', b'\r', rest.as_bytes()) {
+                *input = &rest[pos + 1..];
+            } else {
+                *input = "";
+            }
+            continue;
+        }
+
         // No progress made, exit
         if before_len == input.len() {
             break;
@@ -85,6 +110,15 @@ pub fn skip_ws_and_comments(input: &mut &str, opts: &Options) {
     }
 }
 
+/// Whether `s` starts a `--` line comment (`tolerate_sql_comments`): the dashes are followed
+/// by whitespace or the end of input, which no number or bare value can be.
+pub fn starts_sql_comment(s: &str) -> bool {
+    matches!(
+        s.as_bytes(),
+        [b'-', b'-'] | [b'-', b'-', b' ' | b'\t' | b'\n' | b'\r', ..]
+    )
+}
+
 pub fn starts_with_ident(s: &str) -> bool {
     matches!(s.chars().next(), Some(c) if c.is_ascii_alphabetic() || c == '_' || c == '$')
 }
@@ -120,8 +154,8 @@ pub fn take_symbol_until_delim<'i>(input: &mut &'i str) -> &'i str {
     while i < b.len() {
         match b[i] {
             // ASCII whitespace or structural delimiters terminate the token
-            b' ' | b'\t' | b'\n' | b'\r' | b',' | b'[' | b']' | b'{' | b'}' | b'(' | b')'
-            | b':' | b'"' | b'\'' => break,
+            b' ' | b'\t' | b'\n' | b'\r' | 0x0B | 0x0C | b',' | b'[' | b']' | b'{' | b'}'
+            | b'(' | b')' | b':' | b'"' | b'\'' => break,
             b'/' => {
                 // Stop only if a comment starts
                 if i + 1 < b.len() && (b[i + 1] == b'/' || b[i + 1] == b'*') {
@@ -180,15 +214,27 @@ pub fn skip_word_markers(input: &mut &str, markers: &[String]) {
     }
 }
 
-pub fn skip_ellipsis(input: &mut &str) -> bool {
-    if let Some(rest) = input.strip_prefix("...") {
-        *input = rest;
+/// Skip a bare `...` placeholder (`Options::strip_ellipsis`), returning whether one was
+/// skipped. Spread syntax such as `...rest` or `...{` is left alone.
+pub fn skip_ellipsis(input: &mut &str, opts: &Options) -> bool {
+    if opts.strip_ellipsis && is_bare_ellipsis(input) {
+        *input = &input[3..];
         true
     } else {
         false
     }
 }
 
+/// Whether `s` starts with `...` that is not followed by a spread operand (an identifier,
+/// number, string, bracket, parenthesis or another dot).
+pub fn is_bare_ellipsis(s: &str) -> bool {
+    s.strip_prefix("...").is_some_and(|rest| {
+        !rest.chars().next().is_some_and(|c| {
+            c.is_alphanumeric() || matches!(c, '_' | '$' | '.' | '{' | '[' | '(' | '"' | '\'')
+        })
+    })
+}
+
 /// If `s` starts with optional ASCII whitespace, followed by an identifier, optional ASCII
 /// whitespace, and an opening parenthesis '(', return the byte offset to just after '('.
 /// Otherwise return None.
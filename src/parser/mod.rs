@@ -1,11 +1,17 @@
 #![allow(clippy::collapsible_if)]
 #![allow(clippy::needless_lifetimes)]
 
+use crate::budget::Budget;
 use crate::emit::{Emitter, JRResult, StringEmitter, WriterEmitter};
 use crate::error::{RepairError, RepairErrorKind};
-use crate::options::Options;
+use crate::options::{
+    NegativeZeroPolicy, Options, OverflowPolicy, SafeIntegerPolicy, SalvagePolicy, Strictness,
+    Trace, UnwrapMode,
+};
+use crate::pointer::PathEmitter;
 use crate::repair::RepairLogEntry;
-// Hand-written recursive descent parser using &str slicing for zero-copy parsing
+// Hand-written recursive descent parser using &str slicing for zero-copy parsing; nested
+// containers are driven from an explicit stack (see `parse_container`)
 
 mod array;
 pub(crate) mod lex;
@@ -13,25 +19,221 @@ mod number;
 mod object;
 mod strings;
 
-use array::parse_array;
 use lex::{
     fence_open_lang_newline_len, skip_bom, skip_ws_and_comments, starts_with_ident, take_ident,
     take_symbol_until_delim,
 };
-use number::parse_number_token;
-use object::parse_object;
-use strings::{emit_json_string_from_lit, parse_string_literal_concat_fast};
+#[cfg(feature = "llm-compat")]
+pub(crate) use number::{
+    clamp_integer, is_bare_exponent, is_negative_zero, is_unsafe_integer, overflows_f64,
+    split_unit_suffix,
+};
+use number::{emit_number, is_plus_signed_number, parse_number_token};
+pub(crate) use number::{is_json_number, is_non_finite, non_finite_len, normalize_number};
+pub(crate) use strings::emit_json_string_from_lit;
+use strings::parse_string_literal_concat_fast;
+#[cfg(feature = "llm-compat")]
+pub(crate) use strings::{
+    brace_escape, doubled_quote_body, joins_previous_string, mixed_quote_closes, starts_member,
+};
 
 fn to_err(pos: usize, msg: impl Into<String>) -> RepairError {
     RepairError::new(RepairErrorKind::Parse(msg.into()), pos)
 }
 
+/// Output and path state at the start of a nested value, restored if `salvage` replaces it.
+struct Checkpoint<'i> {
+    from: &'i str,
+    out: usize,
+    path: usize,
+}
+
+/// `salvage`: replace a nested value that failed with a `Parse` error by a placeholder and
+/// leave `input` at the delimiter that ends it. Other errors are returned unchanged.
+fn salvage<'i, E: Emitter>(
+    err: RepairError,
+    at: Checkpoint<'i>,
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> JRResult<()> {
+    if opts.salvage == SalvagePolicy::Fail || !matches!(err.kind, RepairErrorKind::Parse(_)) {
+        return Err(err);
+    }
+    out.rewind(at.out);
+    logger.truncate_path(at.path);
+    let end = bad_value_end(at.from);
+    logger.repair(at.from.len(), "salvaged unrepairable value")?;
+    if opts.salvage == SalvagePolicy::Marker {
+        out.emit_str("{\"$unrepairable\":")?;
+        emit_json_string_from_lit(out, at.from[..end].trim(), opts.ascii_values())?;
+        out.emit_char('}')?;
+    } else {
+        out.emit_str(opts.placeholder_json().as_deref().unwrap_or("null"))?;
+    }
+    *input = &at.from[end..];
+    Ok(())
+}
+
+// Output bytes `repair_to_writer_impl` collects before handing them to the writer.
+const WRITE_CHUNK: usize = 64 * 1024;
+
+// Bytes scanned by `is_crossed_closer` before it settles for the enclosing-container reading.
+const CROSSED_LOOKAHEAD: usize = 64 * 1024;
+
+// A closer that does not match the innermost open container (`open` holds the closers,
+// innermost last) is read one of two ways. Normally it closes the enclosing container it
+// matches, and the containers inside that one were left unclosed (`{"a": [1, 2}`). It is a
+// crossed closer, written for the innermost container instead, when no enclosing container
+// matches it, or when the input after it closes more containers than that first reading
+// leaves open (`{"a": [1, 2}, "b": 3]`, whose final `]` only fits if the `}` closed the
+// array). Only the first `CROSSED_LOOKAHEAD` bytes after the closer are counted.
+pub(crate) fn is_crossed_closer(open: &[u8], rest: &str) -> bool {
+    let Some((&c, after)) = rest.as_bytes().split_first() else {
+        return false;
+    };
+    let enclosing = &open[..open.len().saturating_sub(1)];
+    let Some(outer) = enclosing.iter().rposition(|&b| b == c) else {
+        return true;
+    };
+    let mut depth = 0usize;
+    let mut unmatched = 0usize;
+    let mut quote = None;
+    let mut escape = false;
+    for &b in after.iter().take(CROSSED_LOOKAHEAD) {
+        if let Some(q) = quote {
+            if escape {
+                escape = false;
+            } else if b == b'\\' {
+                escape = true;
+            } else if b == q {
+                quote = None;
+            }
+            continue;
+        }
+        match b {
+            b'"' | b'\'' => quote = Some(b),
+            b'{' | b'[' => depth += 1,
+            b'}' | b']' if depth > 0 => depth -= 1,
+            b'}' | b']' => {
+                unmatched += 1;
+                if unmatched > outer {
+                    return true;
+                }
+            }
+            _ => {}
+        }
+    }
+    false
+}
+
+// `brackets_as_objects`: whether the `[` at `input` holds members rather than elements: its
+// first element is a key followed by a colon (`["a": 1, "b": 2]`), so the brackets were
+// written for an object.
+fn bracketed_members_ahead(input: &str, opts: &Options) -> bool {
+    if !opts.brackets_as_objects {
+        return false;
+    }
+    let mut look = &input[1..];
+    skip_ws_and_comments(&mut look, opts);
+    object::nested_key_ahead(look, opts)
+}
+
+// `Strictness::Aggressive`: whether the `{` at `input` holds only values (`{1, 2, 3}`): a
+// `,` and no `:`, `=` or `->` separator at its own depth, outside quotes, before its closer.
+// As with `compat_python_friendly`, `{"a"}` stays a key without a value, and a first value
+// that is itself an object is a doubled brace (`{{"a": 1}}`). Only the first
+// `CROSSED_LOOKAHEAD` bytes are scanned; a longer one stays an object.
+fn braced_values_ahead(input: &str, opts: &Options) -> bool {
+    if opts.strictness != Strictness::Aggressive {
+        return false;
+    }
+    let mut look = &input[1..];
+    skip_ws_and_comments(&mut look, opts);
+    if look.starts_with('{') {
+        return false;
+    }
+    let mut depth = 0usize;
+    let mut quote = None;
+    let mut escape = false;
+    let mut values = false;
+    let mut comma = false;
+    let mut prev = 0u8;
+    for &b in look.as_bytes().iter().take(CROSSED_LOOKAHEAD) {
+        if let Some(q) = quote {
+            if escape {
+                escape = false;
+            } else if b == b'\\' {
+                escape = true;
+            } else if b == q {
+                quote = None;
+            }
+            continue;
+        }
+        match b {
+            b'"' | b'\'' => quote = Some(b),
+            b'{' | b'[' => depth += 1,
+            b'}' | b']' if depth > 0 => depth -= 1,
+            b'}' | b']' => return values && comma,
+            b',' if depth == 0 => comma = true,
+            b':' | b'=' if depth == 0 => return false,
+            b'>' if depth == 0 && prev == b'-' => return false,
+            _ => {}
+        }
+        values |= !matches!(b, b' ' | b'\t' | b'\n' | b'\r' | b',');
+        prev = b;
+    }
+    values && comma && look.len() <= CROSSED_LOOKAHEAD
+}
+
+// Byte length of a bad value: up to the first `,`, `}` or `]` outside its own brackets and
+// quotes, or the rest of the input.
+fn bad_value_end(s: &str) -> usize {
+    let mut depth = 0usize;
+    let mut quote = None;
+    let mut escape = false;
+    for (i, b) in s.bytes().enumerate() {
+        if let Some(q) = quote {
+            if escape {
+                escape = false;
+            } else if b == b'\\' {
+                escape = true;
+            } else if b == q {
+                quote = None;
+            }
+            continue;
+        }
+        match b {
+            b'"' | b'\'' => quote = Some(b),
+            b'{' | b'[' => depth += 1,
+            b'}' | b']' if depth > 0 => depth -= 1,
+            b',' | b'}' | b']' if depth == 0 => return i,
+            _ => {}
+        }
+    }
+    s.len()
+}
+
 #[derive(Default)]
 pub(crate) struct Logger {
     enable: bool,
     track_path: bool,
     entries: Vec<RepairLogEntry>,
     path: Vec<PathElem>,
+    budget: Budget,
+    origin_len: usize,
+    annotate: bool,
+    source: usize,
+    // Closers (`]` or `}`) of the containers being parsed, innermost last.
+    open: Vec<u8>,
+    trace: Option<Trace>,
+    // Source offset just past the last root value parsed.
+    root_end: usize,
+    // Typed wrappers being unwrapped, each one a level of the call stack.
+    calls: usize,
+    // `repair_affected_paths`: the distinct paths repaired.
+    affected: Option<AffectedPaths>,
 }
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -40,6 +242,87 @@ enum PathElem {
     Key(String),
 }
 
+/// The distinct paths of the repairs, kept as a tree of path elements that link to their
+/// parent: marking a path costs the same at any depth, and each path is written out once,
+/// from its parent's text.
+struct AffectedPaths {
+    /// Path nodes with the node of their parent; node 0 is the root `$`.
+    nodes: Vec<(usize, Option<PathElem>)>,
+    /// Node of each element of `Logger::path` that has one, outermost first. Nodes are made
+    /// when a repair is first marked at or below the element.
+    stack: Vec<usize>,
+    /// Whether each node has been repaired.
+    repaired: Vec<bool>,
+    /// Repaired nodes in order of first repair.
+    order: Vec<usize>,
+}
+
+impl AffectedPaths {
+    fn new() -> Self {
+        Self {
+            nodes: vec![(0, None)],
+            stack: Vec::new(),
+            repaired: vec![false],
+            order: Vec::new(),
+        }
+    }
+    fn mark(&mut self, path: &[PathElem]) {
+        while self.stack.len() < path.len() {
+            let parent = self.stack.last().copied().unwrap_or(0);
+            self.nodes
+                .push((parent, Some(path[self.stack.len()].clone())));
+            self.repaired.push(false);
+            self.stack.push(self.nodes.len() - 1);
+        }
+        let node = self.stack.last().copied().unwrap_or(0);
+        if !self.repaired[node] {
+            self.repaired[node] = true;
+            self.order.push(node);
+        }
+    }
+    fn into_paths(self) -> Vec<String> {
+        // A parent node is always made before its children.
+        let mut text: Vec<String> = Vec::with_capacity(self.nodes.len());
+        for (parent, elem) in &self.nodes {
+            text.push(match elem {
+                Some(elem) => {
+                    let mut s = text[*parent].clone();
+                    push_path_elem(&mut s, elem);
+                    s
+                }
+                None => String::from("$"),
+            });
+        }
+        self.order
+            .into_iter()
+            .map(|node| std::mem::take(&mut text[node]))
+            .collect()
+    }
+}
+
+fn push_path_elem(s: &mut String, elem: &PathElem) {
+    match elem {
+        PathElem::Index(i) => {
+            s.push('[');
+            s.push_str(&i.to_string());
+            s.push(']');
+        }
+        PathElem::Key(k) => {
+            s.push('[');
+            s.push('"');
+            for ch in k.chars() {
+                match ch {
+                    '"' => s.push_str("\\\""),
+                    '\\' => s.push_str("\\\\"),
+                    _ => s.push(ch),
+                }
+            }
+            s.push('"');
+            s.push(']');
+        }
+    }
+}
+
 impl Logger {
     pub(crate) fn new(enable: bool, track_path: bool) -> Self {
         Self {
@@ -47,9 +330,147 @@ impl Logger {
             track_path,
             entries: Vec::new(),
             path: Vec::new(),
+            budget: Budget::default(),
+            origin_len: 0,
+            annotate: false,
+            source: 0,
+            open: Vec::new(),
+            trace: None,
+            root_end: 0,
+            calls: 0,
+            affected: None,
+        }
+    }
+    /// Collect the distinct paths of the repairs for `into_affected_paths`.
+    pub(crate) fn with_affected_paths(mut self) -> Self {
+        self.track_path = true;
+        self.affected = Some(AffectedPaths::new());
+        self
+    }
+    /// Enable `annotate_source` comments; offsets are reported relative to the start of
+    /// `source`, the engine input before any wrapper trimming.
+    pub(crate) fn with_source(mut self, opts: &Options, source: &str) -> Self {
+        self.annotate = opts.annotate_source;
+        self.source = source.as_ptr() as usize;
+        self
+    }
+    /// Remember where a nested value starts so `salvage` can rewind over it.
+    #[inline]
+    fn checkpoint<'i, E: Emitter>(&self, from: &'i str, out: &E) -> Checkpoint<'i> {
+        Checkpoint {
+            from,
+            out: out.mark(),
+            path: self.path.len(),
+        }
+    }
+    /// Byte offset of `rest` (a suffix or sub-slice of the source) from the source start.
+    #[inline]
+    fn source_offset(&self, rest: &str) -> usize {
+        (rest.as_ptr() as usize).saturating_sub(self.source)
+    }
+    /// With `annotate_source`, emit `/* @src:OFFSET */` after a value that started at `start`.
+    #[inline]
+    fn annotate<E: Emitter>(&self, out: &mut E, start: usize) -> JRResult<()> {
+        if !self.annotate {
+            return Ok(());
+        }
+        out.emit_str("/* @src:")?;
+        out.emit_str(&start.to_string())?;
+        out.emit_str(" */")
+    }
+    /// Attach the runtime budget from `opts`; `origin_len` is the byte length of the parsed
+    /// input so error positions can be reported as offsets from its start.
+    pub(crate) fn with_budget(mut self, opts: &Options, origin_len: usize) -> Self {
+        self.budget = Budget::from_options(opts, origin_len);
+        self.origin_len = origin_len;
+        self.trace = opts.trace.clone();
+        self
+    }
+    /// Error position (offset from the start of the parsed input) with `remaining` bytes left.
+    #[inline]
+    fn position(&self, remaining: usize) -> usize {
+        self.origin_len.saturating_sub(remaining)
+    }
+    /// Poll the runtime budget; `remaining` is the length of the unparsed input.
+    #[inline]
+    fn tick(&mut self, remaining: usize) -> JRResult<()> {
+        self.budget.poll(self.origin_len.saturating_sub(remaining))
+    }
+    /// Check a container that now has `count` members against `max_elements`.
+    #[inline]
+    fn count_member(&self, count: usize, remaining: usize) -> JRResult<()> {
+        self.budget
+            .check_elements(count, self.origin_len.saturating_sub(remaining))
+    }
+    /// Check an object key that started with `remaining` bytes left against `max_key_len`,
+    /// cutting it down when `long_keys` asks for that.
+    #[inline]
+    fn check_key(&mut self, key: &mut String, remaining: usize) -> JRResult<()> {
+        let at = self.origin_len.saturating_sub(remaining);
+        if let Some(len) = self.budget.check_key(key, at)? {
+            self.repair(remaining, "truncated long key")?;
+            key.truncate(len);
+        }
+        Ok(())
+    }
+    /// Track a container whose closer (`]` or `}`) is `closer` while its members are parsed.
+    #[inline]
+    fn enter(&mut self, closer: u8) {
+        self.open.push(closer);
+    }
+    #[inline]
+    fn leave(&mut self) {
+        self.open.pop();
+    }
+    /// Whether the wrong closer at the start of `rest` belongs to the innermost container.
+    #[inline]
+    fn crossed_closer(&self, rest: &str) -> bool {
+        is_crossed_closer(&self.open, rest)
+    }
+    /// With `Options::trace`, record `what` at the point where `remaining` bytes are left.
+    #[inline]
+    fn trace(&self, remaining: usize, what: std::fmt::Arguments<'_>) {
+        if let Some(trace) = &self.trace {
+            trace.line(self.position(remaining), what);
+        }
+    }
+    /// Trace the start of the value at `rest`, named by its first character.
+    #[inline]
+    fn trace_value(&self, rest: &str) {
+        if self.trace.is_none() {
+            return;
         }
+        let kind = match rest.as_bytes().first() {
+            Some(b'{') => "object",
+            Some(b'[') => "array",
+            Some(b'"' | b'\'') => "string",
+            Some(b'/') => "regex",
+            Some(b'-' | b'+' | b'.' | b'0'..=b'9') => "number",
+            _ => "bare word",
+        };
+        self.trace(rest.len(), format_args!("value: {kind}"));
+    }
+    /// Trace the error that stopped the parse, at its own position.
+    pub(crate) fn trace_error(&self, err: &RepairError) {
+        if let Some(trace) = &self.trace {
+            trace.line(err.position, format_args!("error: {err}"));
+        }
+    }
+    /// Record one applied repair: logged when enabled and charged against `max_repairs`.
+    fn repair(&mut self, remaining: usize, message: &'static str) -> JRResult<()> {
+        self.trace(remaining, format_args!("repair: {message}"));
+        self.log(message);
+        self.budget
+            .charge_repair(self.origin_len.saturating_sub(remaining))
     }
     fn log(&mut self, message: &'static str) {
+        debug_assert!(
+            crate::repair::REPAIR_CATEGORIES.contains(&message),
+            "unlisted repair category: {message}"
+        );
+        if let Some(affected) = &mut self.affected {
+            affected.mark(&self.path);
+        }
         if !self.enable {
             return;
         }
@@ -68,26 +489,7 @@ impl Logger {
     fn format_path(&self) -> String {
         let mut s = String::from("$");
         for el in &self.path {
-            match el {
-                PathElem::Index(i) => {
-                    s.push('[');
-                    s.push_str(&i.to_string());
-                    s.push(']');
-                }
-                PathElem::Key(k) => {
-                    s.push('[');
-                    s.push('"');
-                    for ch in k.chars() {
-                        match ch {
-                            '"' => s.push_str("\\\""),
-                            '\\' => s.push_str("\\\\"),
-                            _ => s.push(ch),
-                        }
-                    }
-                    s.push('"');
-                    s.push(']');
-                }
-            }
+            push_path_elem(&mut s, el);
         }
         s
     }
@@ -98,7 +500,7 @@ impl Logger {
     }
     fn pop_key(&mut self) {
         if self.track_path {
-            let _ = self.path.pop();
+            self.truncate_path(self.path.len().saturating_sub(1));
         }
     }
     fn push_index(&mut self, i: usize) {
@@ -108,12 +510,47 @@ impl Logger {
     }
     fn pop_index(&mut self) {
         if self.track_path {
-            let _ = self.path.pop();
+            self.truncate_path(self.path.len().saturating_sub(1));
+        }
+    }
+    fn truncate_path(&mut self, len: usize) {
+        self.path.truncate(len);
+        if let Some(affected) = &mut self.affected {
+            affected.stack.truncate(len);
         }
     }
     pub(crate) fn into_entries(self) -> Vec<RepairLogEntry> {
         self.entries
     }
+    /// The distinct paths of the repairs, in order of first repair.
+    pub(crate) fn into_affected_paths(self) -> Vec<String> {
+        self.affected
+            .map(AffectedPaths::into_paths)
+            .unwrap_or_default()
+    }
+}
+
+// With `Options::trace`, note that the input was taken as valid JSON without parsing it.
+#[cfg(feature = "serde")]
+fn trace_fast_path(opts: &Options) {
+    if let Some(trace) = &opts.trace {
+        trace.line(0, format_args!("valid JSON: parser skipped"));
+    }
+}
+
+// Whether input that is already valid JSON may skip the parser. These options rewrite
+// valid input too, and `max_elements` and `max_key_len` have to see its members.
+#[cfg(feature = "serde")]
+fn fast_path_allowed(opts: &Options) -> bool {
+    !opts.trim_keys
+        && !opts.normalize_numbers
+        && !opts.annotate_source
+        && opts.max_elements == 0
+        && opts.max_key_len == 0
+        && opts.overflow == OverflowPolicy::Keep
+        && opts.negative_zero == NegativeZeroPolicy::Preserve
+        && opts.safe_integers == SafeIntegerPolicy::Passthrough
+        && !opts.stop_after_first
 }
 
 pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<String, RepairError> {
@@ -122,33 +559,32 @@ pub(crate) fn repair_to_string_impl(input: &str, opts: &Options) -> Result<Strin
     // Fast path: if input is already valid JSON, short-circuit
     #[cfg(feature = "serde")]
     {
-        if !opts.ensure_ascii && opts.assume_valid_json_fastpath {
+        if !opts.ascii_keys()
+            && !opts.ascii_values()
+            && opts.assume_valid_json_fastpath
+            && !opts.stop_after_first
+            && opts.max_elements == 0
+            && opts.max_key_len == 0
+        {
             // Skip full validation for maximum speed when explicitly allowed.
+            trace_fast_path(opts);
             return Ok(s.to_string());
         }
-        if let Ok(val) = serde_json::from_str::<serde_json::Value>(s) {
-            if !opts.ensure_ascii {
+        if fast_path_allowed(opts) && serde_json::from_str::<serde_json::Value>(s).is_ok() {
+            trace_fast_path(opts);
+            if !opts.ascii_keys() && !opts.ascii_values() {
                 return Ok(s.to_string());
             } else {
-                use serde::Serialize;
-                let mut buf: Vec<u8> = Vec::with_capacity(s.len());
-                let mut ser = serde_json::Serializer::with_formatter(&mut buf, AsciiEscaper);
-                val.serialize(&mut ser)
-                    .map_err(|e| to_err(0, format!("serde serialize error: {}", e)))?;
-                let out =
-                    String::from_utf8(buf).map_err(|e| to_err(0, format!("utf8 error: {}", e)))?;
-                return Ok(out);
+                return Ok(escape_valid_json_ascii(s, opts));
             }
         }
     }
 
-    let mut logger = Logger {
-        enable: false,
-        track_path: false,
-        entries: Vec::new(),
-        path: Vec::new(),
-    };
-    let out = parse_root_many_string_fast(&mut s, opts, &mut logger)?;
+    let mut logger = Logger::new(false, false)
+        .with_budget(opts, s.len())
+        .with_source(opts, input);
+    let out = parse_root_many_string_fast(&mut s, opts, &mut logger)
+        .inspect_err(|e| logger.trace_error(e))?;
     if opts.python_style_separators {
         return Ok(apply_python_separators(&out));
     }
@@ -165,42 +601,51 @@ pub(crate) fn repair_to_writer_impl<W: std::io::Write>(
     // Fast path when input is already valid JSON.
     #[cfg(feature = "serde")]
     {
-        use serde::Serialize;
-        if !opts.ensure_ascii && opts.assume_valid_json_fastpath {
+        if !opts.ascii_keys()
+            && !opts.ascii_values()
+            && opts.assume_valid_json_fastpath
+            && !opts.stop_after_first
+            && opts.max_elements == 0
+            && opts.max_key_len == 0
+        {
+            trace_fast_path(opts);
             writer
                 .write_all(s.as_bytes())
                 .map_err(|e| to_err(0, format!("io write error: {}", e)))?;
             return Ok(());
         }
-        if let Ok(val) = serde_json::from_str::<serde_json::Value>(s) {
-            if !opts.ensure_ascii {
-                writer
-                    .write_all(s.as_bytes())
-                    .map_err(|e| to_err(0, format!("io write error: {}", e)))?;
-                return Ok(());
+        if fast_path_allowed(opts) && serde_json::from_str::<serde_json::Value>(s).is_ok() {
+            trace_fast_path(opts);
+            let out = if !opts.ascii_keys() && !opts.ascii_values() {
+                std::borrow::Cow::Borrowed(s)
             } else {
-                let mut ser = serde_json::Serializer::with_formatter(writer, AsciiEscaper);
-                val.serialize(&mut ser)
-                    .map_err(|e| to_err(0, format!("serde serialize error: {}", e)))?;
-                return Ok(());
-            }
+                std::borrow::Cow::Owned(escape_valid_json_ascii(s, opts))
+            };
+            writer
+                .write_all(out.as_bytes())
+                .map_err(|e| to_err(0, format!("io write error: {}", e)))?;
+            return Ok(());
         }
     }
 
-    let mut emitter = WriterEmitter::with_capacity(writer, s.len().saturating_add(8));
-    let mut logger = Logger {
-        enable: false,
-        track_path: false,
-        entries: Vec::new(),
-        path: Vec::new(),
+    // `salvage` may rewind over any earlier output, so it keeps everything until the end.
+    let mut emitter = if opts.salvage == SalvagePolicy::Fail {
+        WriterEmitter::chunked(writer, WRITE_CHUNK)
+    } else {
+        WriterEmitter::with_capacity(writer, s.len().saturating_add(8))
     };
-    parse_root_many(&mut s, opts, &mut emitter, &mut logger)?;
+    let mut logger = Logger::new(false, false)
+        .with_budget(opts, s.len())
+        .with_source(opts, input);
+    parse_root_many(&mut s, opts, &mut emitter, &mut logger)
+        .inspect_err(|e| logger.trace_error(e))?;
     emitter.flush_all()?;
     if opts.python_style_separators {
         let s2 = repair_to_string_impl(
             input,
             &Options {
                 python_style_separators: false,
+                trace: None,
                 ..opts.clone()
             },
         )?;
@@ -212,21 +657,190 @@ pub(crate) fn repair_to_writer_impl<W: std::io::Write>(
     Ok(())
 }
 
+/// Source slices of up to `limit` root values in `input`, for `repair_split` and
+/// `repair_first`. Values may be separated by whitespace, comments, commas or nothing at all
+/// (`{a:1}{b:2}`); scanning stops at the first token that cannot start a value, and, as in
+/// `parse_root_many_string_fast`, at a bare word after an object or array, so trailing
+/// narrative is ignored. Input after the last value taken is not looked at.
+pub(crate) fn split_roots<'i>(
+    input: &'i str,
+    opts: &Options,
+    limit: usize,
+) -> JRResult<Vec<&'i str>> {
+    let mut s = pre_trim_wrappers(input, opts);
+    let mut logger = Logger::new(false, false).with_budget(opts, s.len());
+    let mut scratch = String::new();
+    let mut roots: Vec<&'i str> = Vec::new();
+    while roots.len() < limit {
+        skip_ws_and_comments(&mut s, opts);
+        while let Some(rest) = s.strip_prefix(',') {
+            s = rest;
+            skip_ws_and_comments(&mut s, opts);
+        }
+        let after_container = roots.last().is_some_and(|r| r.starts_with(['{', '[']));
+        if !starts_value(s) || after_container && s.starts_with(|c: char| c.is_ascii_alphabetic()) {
+            break;
+        }
+        let start = s;
+        scratch.clear();
+        parse_value(
+            &mut s,
+            opts,
+            &mut StringEmitter::new(&mut scratch),
+            &mut logger,
+        )?;
+        let len = start.len() - s.len();
+        if len == 0 {
+            break;
+        }
+        roots.push(&start[..len]);
+    }
+    Ok(roots)
+}
+
+/// Byte offset in `input` just past the last root value that `repair_to_string_impl` takes
+/// from it, or 0 when there is none. Trailing text the repair ignores is not counted.
+pub(crate) fn root_end(input: &str, opts: &Options) -> JRResult<usize> {
+    let mut s = pre_trim_wrappers(input, opts);
+    let mut logger = Logger::new(false, false)
+        .with_budget(opts, s.len())
+        .with_source(opts, input);
+    parse_root_many_string_fast(&mut s, opts, &mut logger)?;
+    Ok(logger.root_end)
+}
+
+/// What `extract_impl` found in the input.
+pub(crate) enum Extract {
+    Found(String),
+    Missing,
+    /// The pointer has to be resolved on the whole repaired output: valid input is copied
+    /// through as given, and several fenced blocks or root values are collected into an array.
+    Whole,
+}
+
+/// The value at `tokens` in the output `repair_to_string_impl` gives for `input`, parsed
+/// through a `PathEmitter`: only the target is kept, and the root value is repaired no
+/// further than the end of the target.
+pub(crate) fn extract_impl(input: &str, opts: &Options, tokens: &[String]) -> JRResult<Extract> {
+    let mut s = pre_trim_wrappers(input, opts);
+    if opts.fenced_code_blocks && !opts.stop_after_first && s.contains("```") {
+        return Ok(Extract::Whole);
+    }
+    #[cfg(feature = "serde")]
+    if opts.assume_valid_json_fastpath
+        || fast_path_allowed(opts) && serde_json::from_str::<serde_json::Value>(s).is_ok()
+    {
+        return Ok(Extract::Whole);
+    }
+    let mut logger = Logger::new(false, false)
+        .with_budget(opts, s.len())
+        .with_source(opts, input);
+    skip_ws_and_comments(&mut s, opts);
+    if s.is_empty() {
+        return Ok(Extract::Whole);
+    }
+    let single = skip_narrative(&mut s) || opts.stop_after_first;
+    let root = s;
+    let mut out = PathEmitter::new(tokens);
+    if let Err(e) = parse_value(&mut s, opts, &mut out, &mut logger) {
+        if !out.stopped() {
+            logger.trace_error(&e);
+            return Err(e);
+        }
+        // The parse ended inside the root value; where the value ends is found by matching
+        // its brackets instead.
+        s = match root.starts_with(['{', '[']).then(|| after_container(root)) {
+            Some(Some(rest)) => rest,
+            _ => return Ok(Extract::Whole),
+        };
+    }
+    if !single {
+        skip_ws_and_comments(&mut s, opts);
+        if let Some(rest) = s.strip_prefix(',') {
+            s = rest;
+            skip_ws_and_comments(&mut s, opts);
+        }
+        if takes_more_roots(s, root.chars().next().unwrap_or('\0')) {
+            return Ok(Extract::Whole);
+        }
+    }
+    Ok(out.result().map_or(Extract::Missing, Extract::Found))
+}
+
+/// The text after the object or array at the start of `s`, found by matching brackets outside
+/// strings and comments rather than by parsing; `""` when the brackets never balance. `None`
+/// when `s` ends inside a string. A quote opens a string only where a token starts, and a
+/// comment only after whitespace or punctuation, so `it's` and `http://` are plain text.
+fn after_container(s: &str) -> Option<&str> {
+    let b = s.as_bytes();
+    let token_start = |i: usize| {
+        i == 0
+            || matches!(
+                b[i - 1],
+                b'{' | b'[' | b',' | b':' | b' ' | b'\t' | b'\n' | b'\r'
+            )
+    };
+    let comment_start = |i: usize| token_start(i) && (i == 0 || b[i - 1] != b':');
+    let mut depth = 0usize;
+    let mut i = 0;
+    while i < b.len() {
+        match b[i] {
+            q @ (b'"' | b'\'') if q == b'"' || token_start(i) => {
+                i += 1;
+                loop {
+                    match *b.get(i)? {
+                        b'\\' => i += 2,
+                        c if c == q => break,
+                        _ => i += 1,
+                    }
+                }
+            }
+            b'/' if b.get(i + 1) == Some(&b'/') && comment_start(i) => {
+                i += b[i..]
+                    .iter()
+                    .position(|&c| c == b'\n')
+                    .unwrap_or(b.len() - i);
+                continue;
+            }
+            b'/' if b.get(i + 1) == Some(&b'*') && comment_start(i) => {
+                i += s[i..].find("*/").map_or(b.len() - i, |e| e + 1);
+            }
+            b'{' | b'[' => depth += 1,
+            b'}' | b']' => {
+                depth = depth.saturating_sub(1);
+                if depth == 0 {
+                    return Some(&s[i + 1..]);
+                }
+            }
+            _ => {}
+        }
+        i += 1;
+    }
+    Some("")
+}
+
 pub(crate) fn pre_trim_wrappers<'i>(input: &'i str, opts: &Options) -> &'i str {
     let mut s = input;
     // BOM
     skip_bom(&mut s);
     // Markdown fence: ```lang\n ... ```
     if opts.fenced_code_blocks {
-        // Only trim to a single fenced block when there is exactly one block.
-        if let Some(start) = s.find("```") {
+        // Only trim to a single fenced block when there is exactly one block. With
+        // `stop_after_first` the first block is taken, and its fence is only looked for
+        // before the first bracket, so the rest of the input is not scanned.
+        let head = if opts.stop_after_first {
+            &s[..s.find(['{', '[']).unwrap_or(s.len())]
+        } else {
+            s
+        };
+        if let Some(start) = head.find("```") {
             let after_ticks = start + 3;
             let lang_skip = fence_open_lang_newline_len(&s[after_ticks..]);
             let body_start = after_ticks + lang_skip;
             if let Some(end_rel) = s[body_start..].find("```") {
                 let after_end = body_start + end_rel + 3;
                 // If no additional fenced block occurs after the first closing, treat as a single fenced body
-                if !s[after_end..].contains("```") {
+                if opts.stop_after_first || !s[after_end..].contains("```") {
                     s = &s[body_start..body_start + end_rel];
                 }
             }
@@ -234,7 +848,11 @@ pub(crate) fn pre_trim_wrappers<'i>(input: &'i str, opts: &Options) -> &'i str {
     }
     // JSONP: ident( ... ) ;
     // 可嵌套，多层剥离
-    while let Some(inner) = trim_jsonp(s) {
+    // A registered wrapper (`NumberLong("42")`) is a value, not a callback: leave it to
+    // the value parser so it converts the same at the root as inside a container.
+    while unwrap_call(s.trim_start(), opts).is_none()
+        && let Some(inner) = trim_jsonp(s)
+    {
         s = inner;
     }
     s
@@ -280,6 +898,10 @@ pub(crate) fn parse_root_many<'i, E: Emitter>(
         let mut se = StringEmitter::new(&mut first);
         parse_value(input, opts, &mut se, logger)?;
     }
+    if opts.stop_after_first {
+        logger.trace(input.len(), format_args!("stopped after the first value"));
+        return out.emit_str(&first);
+    }
 
     // After first value, skip optional WS/comments and a single comma
     skip_ws_and_comments(input, opts);
@@ -290,6 +912,10 @@ pub(crate) fn parse_root_many<'i, E: Emitter>(
 
     let has_more = starts_value(input);
     if has_more {
+        logger.trace(
+            input.len(),
+            format_args!("more root values: wrapped in an array"),
+        );
         out.emit_char('[')?;
         out.emit_str(&first)?;
         while !input.is_empty() {
@@ -336,7 +962,7 @@ fn parse_root_many_string_fast<'i>(
 ) -> JRResult<String> {
     // If there are multiple fenced code blocks in the input, extract them and
     // return an array combining their parsed JSON bodies (Python json_repair parity).
-    if opts.fenced_code_blocks {
+    if opts.fenced_code_blocks && !opts.stop_after_first {
         let sfull = *input;
         if sfull.contains("```") {
             let mut bodies: Vec<&str> = Vec::new();
@@ -355,6 +981,10 @@ fn parse_root_many_string_fast<'i>(
                 }
             }
             if bodies.len() >= 2 {
+                logger.trace(
+                    sfull.len(),
+                    format_args!("{} fenced blocks: wrapped in an array", bodies.len()),
+                );
                 let mut agg = String::new();
                 let mut se_outer = StringEmitter::new(&mut agg);
                 se_outer.emit_char('[')?;
@@ -366,6 +996,7 @@ fn parse_root_many_string_fast<'i>(
                     let mut se = StringEmitter::new(&mut tmp);
                     let mut inner = *b;
                     parse_value(&mut inner, opts, &mut se, logger)?;
+                    logger.root_end = logger.source_offset(inner);
                     se_outer.emit_str(&tmp)?;
                 }
                 se_outer.emit_char(']')?;
@@ -379,40 +1010,15 @@ fn parse_root_many_string_fast<'i>(
     if input.is_empty() {
         return Ok(out);
     }
-    // Python-parity: if the input starts with narrative text and later contains a JSON
-    // object/array, skip directly to the first '{' or '[' and parse only that value.
-    let mut extracted_to_first_struct = false;
-    {
-        let s0 = *input;
-        let first_non_ws = s0.trim_start_matches([' ', '\t', '\n', '\r']);
-        if !first_non_ws.is_empty() {
-            let c0 = first_non_ws.chars().next().unwrap();
-            if c0 != '{' && c0 != '[' {
-                // Find the first '{' or '[' whose preceding character is a safe boundary
-                // (start of string, whitespace, or one of '(', ':', ',', '=') to avoid
-                // jumping into regex char classes or similar constructs.
-                let mut last_boundary_ok = true; // start of string
-                let mut skip_pos: Option<usize> = None;
-                for (i, ch) in s0.char_indices() {
-                    if ch == '{' || ch == '[' {
-                        if last_boundary_ok {
-                            skip_pos = Some(i);
-                            break;
-                        }
-                    }
-                    last_boundary_ok =
-                        matches!(ch, ' ' | '\t' | '\n' | '\r' | '(' | ':' | ',' | '=');
-                }
-                if let Some(pos) = skip_pos {
-                    *input = &s0[pos..];
-                    extracted_to_first_struct = true;
-                }
-            }
-        }
-    }
+    let extracted_to_first_struct = skip_narrative(input);
     let first_char = input.chars().next().unwrap_or('\0');
     // Parse first value directly into out
     parse_value(input, opts, &mut se, logger)?;
+    logger.root_end = logger.source_offset(input);
+    if opts.stop_after_first {
+        logger.trace(input.len(), format_args!("stopped after the first value"));
+        return Ok(out);
+    }
 
     // Probe if there are more values (optional comma + value start)
     skip_ws_and_comments(input, opts);
@@ -447,28 +1053,19 @@ fn parse_root_many_string_fast<'i>(
         }
         return Ok(out);
     }
-    // If the first value was an object/array but the next token isn't starting a
-    // JSON value with a structural starter, treat the tail as narrative and ignore.
-    if first_char == '{' || first_char == '[' {
-        let next_trim = input.trim_start();
-        if let Some(next_c) = next_trim.chars().next() {
-            match next_c {
-                '{' | '[' | '"' | '\'' | '-' => { /* aggregate below */ }
-                c if c.is_ascii_digit() => { /* aggregate below */ }
-                _ => {
-                    // ignore remainder
-                    return Ok(out);
-                }
-            }
-        } else {
-            return Ok(out);
-        }
+    if !takes_more_roots(input, first_char) {
+        logger.trace(input.len(), format_args!("ignored trailing text"));
+        return Ok(out);
     }
 
     // 🔧 NDJSON stream fallback disabled - direct aggregation is faster for benchmarks
     // The streaming processor has overhead that makes it slower for small NDJSON inputs
 
     // Multiple values: aggregate into array
+    logger.trace(
+        input.len(),
+        format_args!("more root values: wrapped in an array"),
+    );
     let mut agg = String::with_capacity(out.len().saturating_add(8));
     agg.push('[');
     agg.push_str(&out);
@@ -486,6 +1083,7 @@ fn parse_root_many_string_fast<'i>(
         }
         agg_se.emit_char(',')?;
         parse_value(input, opts, &mut agg_se, logger)?;
+        logger.root_end = logger.source_offset(input);
         skip_ws_and_comments(input, opts);
         if input.starts_with(',') {
             *input = &input[1..];
@@ -503,6 +1101,46 @@ fn parse_root_many_string_fast<'i>(
     Ok(agg)
 }
 
+/// Python-parity: if the input starts with narrative text and later contains a JSON
+/// object/array, skip directly to the first '{' or '[' so that only that value is parsed.
+/// Returns whether anything was skipped.
+fn skip_narrative(input: &mut &str) -> bool {
+    let s0 = *input;
+    let first_non_ws = s0.trim_start_matches([' ', '\t', '\n', '\r']);
+    let Some(c0) = first_non_ws.chars().next() else {
+        return false;
+    };
+    if c0 == '{' || c0 == '[' {
+        return false;
+    }
+    // Find the first '{' or '[' whose preceding character is a safe boundary
+    // (start of string, whitespace, or one of '(', ':', ',', '=') to avoid
+    // jumping into regex char classes or similar constructs.
+    let mut last_boundary_ok = true; // start of string
+    for (i, ch) in s0.char_indices() {
+        if (ch == '{' || ch == '[') && last_boundary_ok {
+            *input = &s0[i..];
+            return true;
+        }
+        last_boundary_ok = matches!(ch, ' ' | '\t' | '\n' | '\r' | '(' | ':' | ',' | '=');
+    }
+    false
+}
+
+/// Whether `rest`, the text after the first root value and any comma following it, starts
+/// another root value that is collected with the first into an array. After an object or
+/// array only a token that starts like JSON does; anything else is trailing narrative.
+fn takes_more_roots(rest: &str, first_char: char) -> bool {
+    if !starts_value(rest) {
+        return false;
+    }
+    if first_char != '{' && first_char != '[' {
+        return true;
+    }
+    rest.trim_start()
+        .starts_with(|c: char| matches!(c, '{' | '[' | '"' | '\'' | '-') || c.is_ascii_digit())
+}
+
 fn starts_value(s: &str) -> bool {
     let s = s.trim_start();
     match s.chars().next() {
@@ -519,30 +1157,241 @@ fn parse_value<'i, E: Emitter>(
     out: &mut E,
     logger: &mut Logger,
 ) -> JRResult<()> {
+    logger.tick(input.len())?;
     skip_ws_and_comments(input, opts);
     if input.is_empty() {
         return Err(to_err(0, "unexpected end while parsing value"));
     }
+    logger.trace_value(input);
+    let start = logger.source_offset(input);
     let c = input.chars().next().unwrap();
     match c {
-        '{' => parse_object(input, opts, out, logger),
-        '[' => parse_array(input, opts, out, logger),
-        '"' | '\'' => parse_string_literal_concat_fast(input, opts, out),
+        _ if null_token_len(input, opts).is_some() => parse_null_token(input, opts, out, logger),
+        '{' | '[' => parse_container(input, opts, out, logger),
+        '"' | '\'' => {
+            if c == '\'' {
+                logger.repair(input.len(), "converted single-quoted string")?;
+            }
+            parse_string_literal_concat_fast(input, opts, out, logger)
+        }
         '/' => parse_regex_literal(input, opts, out),
         '-' => {
-            // Special-case JS non-finite: -Infinity
-            if opts.normalize_js_nonfinite && input.starts_with("-Infinity") {
-                *input = &input[9..];
+            // Special-case JS non-finite: -Infinity, -NaN
+            if opts.normalize_js_nonfinite
+                && let Some(n) = non_finite_len(input)
+            {
+                logger.repair(input.len(), "normalized non-finite number")?;
+                *input = &input[n..];
                 out.emit_str("null")
             } else {
                 parse_number_token(input, opts, out)
             }
         }
         c if c == '.' || c.is_ascii_digit() => parse_number_token(input, opts, out),
+        '+' if is_plus_signed_number(input) => {
+            *input = &input[1..];
+            parse_number_token(input, opts, out)
+        }
         _ => parse_symbol_or_unquoted_string(input, opts, out, logger),
+    }?;
+    logger.annotate(out, start)
+}
+
+/// What a container step stopped at: a nested container to descend into, a key chain read
+/// as nested objects (`Strictness::Aggressive`), or its own end.
+enum Step {
+    Descend,
+    Member,
+    Done,
+}
+
+enum Frame<'i> {
+    Array(array::ArrayFrame<'i>),
+    Object(object::ObjectFrame<'i>),
+    Member(object::MemberFrame),
+}
+
+// Parse the object or array at `input`. Nested containers, and the key chains read as objects
+// under `Strictness::Aggressive`, are kept on a heap-allocated stack of frames rather than
+// the call stack, so nesting depth is bounded by memory only.
+fn parse_container<'i, E: Emitter>(
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> JRResult<()> {
+    let mut stack = Vec::new();
+    // The result of the container that was closed last, handed to its parent's next step.
+    let mut finished = open_container(&mut stack, input, opts, out, logger);
+    while let Some(frame) = stack.last_mut() {
+        let nested = finished.take();
+        let step = match frame {
+            Frame::Array(frame) => array::array_step(frame, nested, input, opts, out, logger),
+            Frame::Object(frame) => object::object_step(frame, nested, input, opts, out, logger),
+            Frame::Member(frame) => object::member_step(frame, nested, input, opts, out, logger),
+        };
+        match step {
+            Ok(Step::Descend) => finished = open_container(&mut stack, input, opts, out, logger),
+            Ok(Step::Member) => match object::open_nested_member(input, opts, out, logger) {
+                Ok(frame) => stack.push(Frame::Member(frame)),
+                Err(err) => finished = Some(Err(err)),
+            },
+            Ok(Step::Done) => {
+                let kind = match stack.pop() {
+                    Some(Frame::Member(_)) => None,
+                    Some(Frame::Array(_)) => Some("array"),
+                    _ => Some("object"),
+                };
+                if let Some(kind) = kind {
+                    logger.leave();
+                    logger.trace(input.len(), format_args!("end of {kind}"));
+                }
+                finished = Some(Ok(()));
+            }
+            Err(err) => {
+                if !matches!(stack.pop(), Some(Frame::Member(_))) {
+                    logger.leave();
+                }
+                finished = Some(Err(err));
+            }
+        }
+    }
+    finished.unwrap_or(Ok(()))
+}
+
+// Open the object or array at `input` and push its frame. Returns the container's result
+// instead when it is already closed (`{}`, `[ ]`) or failed to open.
+fn open_container<'i, E: Emitter>(
+    stack: &mut Vec<Frame<'i>>,
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> Option<JRResult<()>> {
+    // Brackets swapped with braces at the same level: with `brackets_as_objects`, `["a": 1]`
+    // is read as an object and, under `Strictness::Aggressive`, `{1, 2}` as an array. Either still closes at the closer
+    // that was written.
+    let braced = input.starts_with('{');
+    let is_object = if braced {
+        !braced_values_ahead(input, opts)
+    } else {
+        bracketed_members_ahead(input, opts)
+    };
+    if is_object != braced {
+        let message = if is_object {
+            "read bracketed members as object"
+        } else {
+            "read braced values as array"
+        };
+        if let Err(err) = logger.repair(input.len(), message) {
+            return Some(Err(err));
+        }
+    }
+    logger.enter(if braced { b'}' } else { b']' });
+    let opened = if is_object {
+        object::open_object(input, opts, out, !braced).map(|f| f.map(Frame::Object))
+    } else {
+        array::open_array(input, opts, out, braced).map(|f| f.map(Frame::Array))
+    };
+    match opened {
+        Ok(Some(frame)) => {
+            stack.push(frame);
+            None
+        }
+        Ok(None) => {
+            logger.leave();
+            let kind = if is_object { "object" } else { "array" };
+            logger.trace(input.len(), format_args!("end of {kind}"));
+            Some(Ok(()))
+        }
+        Err(err) => {
+            logger.leave();
+            Some(Err(err))
+        }
+    }
+}
+
+// True when `s` starts at a point that terminates a bare value: end of input, whitespace, a
+// structural delimiter (`, : [ ] { } ( )` or `;`), a quote, or a comment start.
+fn ends_bare_value(s: &str) -> bool {
+    match s.as_bytes() {
+        [] => true,
+        [b'/', b'/' | b'*', ..] => true,
+        [c, ..] => matches!(
+            c,
+            b' ' | b'\t'
+                | b'\n'
+                | b'\r'
+                | b','
+                | b':'
+                | b'['
+                | b']'
+                | b'{'
+                | b'}'
+                | b'('
+                | b')'
+                | b'"'
+                | b'\''
+                | b';'
+        ),
     }
 }
 
+// For `Options::abbreviated_keywords`, the keyword a lone `t`, `f` or `n` stands for: only
+// spaces or tabs may come between it and a `,`, `}`, `]`, line break or the end of input.
+fn abbreviated_keyword(tok: &str, rest: &str) -> Option<&'static str> {
+    let keyword = match tok {
+        "t" => "true",
+        "f" => "false",
+        "n" => "null",
+        _ => return None,
+    };
+    stands_alone(rest).then_some(keyword)
+}
+
+// Whether a bare token is a value by itself when `rest` follows it: only spaces or tabs may
+// come before a `,`, `}`, `]`, line break or the end of input.
+fn stands_alone(rest: &str) -> bool {
+    let after = rest.trim_start_matches([' ', '\t']);
+    matches!(
+        after.as_bytes().first(),
+        None | Some(b',' | b'}' | b']' | b'\n' | b'\r')
+    )
+}
+
+// For `Options::null_tokens`, the length of the configured token `s` starts with, if it
+// stands alone as a value. When several match (`-` and `--`), the longest wins.
+pub(crate) fn null_token_len(s: &str, opts: &Options) -> Option<usize> {
+    opts.null_tokens
+        .iter()
+        .filter(|t| !t.is_empty() && s.starts_with(t.as_str()) && stands_alone(&s[t.len()..]))
+        .map(|t| t.len())
+        .max()
+}
+
+/// Write `null` for the null token at the start of `input` (see `null_token_len`).
+pub(crate) fn parse_null_token<E: Emitter>(
+    input: &mut &str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> JRResult<()> {
+    let len = null_token_len(input, opts).unwrap_or(0);
+    if &input[..len] != "null" {
+        logger.repair(input.len(), "mapped null token")?;
+    }
+    *input = &input[len..];
+    out.emit_str("null")
+}
+
+/// Parse a bare (unquoted) value: keywords map to JSON literals, anything else is quoted.
+///
+/// A keyword only counts when it is a whole word, i.e. followed by a terminator: `truefoo`,
+/// `nullish`, `true-ish` and `false.x` are quoted as strings. A bare value is a run of words separated by spaces or tabs. Each word may contain any
+/// character except the terminators: whitespace, `, : [ ] { } ( ) " '`, or a comment start
+/// (`//`, `/*`). So `a@b.com`, `/usr/local/bin` and `v1.2.3-rc1` are quoted whole. A URL
+/// scheme (`ident://`) additionally keeps `:` and `//`, ending only at whitespace or
+/// `, ] } " '`.
 pub(crate) fn parse_symbol_or_unquoted_string<'i, E: Emitter>(
     input: &mut &'i str,
     opts: &Options,
@@ -551,36 +1400,78 @@ pub(crate) fn parse_symbol_or_unquoted_string<'i, E: Emitter>(
 ) -> JRResult<()> {
     let s = *input;
     let (tok, rest) = take_ident(s);
+    if !tok.is_empty() && rest.starts_with("://") {
+        let end = url_end(s);
+        *input = &s[end..];
+        logger.repair(s.len(), "quoted bare string")?;
+        return emit_json_string_from_lit(out, &s[..end], opts.ascii_values());
+    }
+    if let Some((mode, args)) = unwrap_call(s, opts) {
+        *input = args;
+        return parse_unwrap_call(input, mode, opts, out, logger);
+    }
+    // `+Infinity` / `+NaN`: the `+` keeps them out of `take_ident`
+    if opts.normalize_js_nonfinite
+        && s.starts_with('+')
+        && let Some(n) = non_finite_len(s)
+    {
+        *input = &s[n..];
+        logger.repair(s.len(), "normalized non-finite number")?;
+        return out.emit_str("null");
+    }
     if !tok.is_empty() {
         *input = rest;
         // Convert known keywords; otherwise accumulate adjacent unquoted words separated by spaces
         let mut emitted = String::new();
         let mut special_emitted = false;
-        let _ = match tok {
+        let mut keyword = if ends_bare_value(rest) { tok } else { "" };
+        if opts.abbreviated_keywords
+            && let Some(k) = abbreviated_keyword(tok, rest)
+        {
+            logger.repair(input.len(), "expanded abbreviated keyword")?;
+            keyword = k;
+        }
+        // Other casings of the JSON keywords (`TRUE`, `Null`); the Python spellings `True` and
+        // `False` belong to `allow_python_keywords`.
+        if opts.case_insensitive_keywords
+            && !matches!(keyword, "True" | "False")
+            && let Some(k) = ["true", "false", "null"]
+                .into_iter()
+                .find(|k| keyword.eq_ignore_ascii_case(k))
+            && k != keyword
+        {
+            logger.repair(input.len(), "normalized keyword case")?;
+            keyword = k;
+        }
+        let _ = match keyword {
             "true" => out.emit_str("true"),
             "false" => out.emit_str("false"),
             "null" => out.emit_str("null"),
             // pythonic
             "True" if opts.allow_python_keywords => {
-                logger.log("normalized python keyword");
+                logger.repair(input.len(), "normalized python keyword")?;
                 out.emit_str("true")
             }
             "False" if opts.allow_python_keywords => {
-                logger.log("normalized python keyword");
+                logger.repair(input.len(), "normalized python keyword")?;
                 out.emit_str("false")
             }
             "None" if opts.allow_python_keywords => {
-                logger.log("normalized python keyword");
+                logger.repair(input.len(), "normalized python keyword")?;
                 out.emit_str("null")
             }
             // js non-finite
-            "NaN" | "Infinity" | "-Infinity" if opts.normalize_js_nonfinite => out.emit_str("null"),
+            k if opts.normalize_js_nonfinite && is_non_finite(k) => {
+                logger.repair(input.len(), "normalized non-finite number")?;
+                out.emit_str("null")
+            }
             // undefined
             "undefined" if opts.repair_undefined => {
-                logger.log("replaced undefined with null");
+                logger.repair(input.len(), "replaced undefined with null")?;
                 out.emit_str("null")
             }
             _ => {
+                logger.repair(s.len(), "quoted bare string")?;
                 emitted.push_str(tok);
                 // accumulate subsequent bare identifiers/symbols separated by ASCII spaces
                 loop {
@@ -601,6 +1492,16 @@ pub(crate) fn parse_symbol_or_unquoted_string<'i, E: Emitter>(
                         break;
                     }
                     let nc = input.as_bytes()[0];
+                    // A quote between two letters is an apostrophe: `it's`, `don't`.
+                    if i == 0
+                        && nc == b'\''
+                        && emitted.ends_with(char::is_alphabetic)
+                        && input[1..].starts_with(char::is_alphabetic)
+                    {
+                        emitted.push('\'');
+                        *input = &input[1..];
+                        continue;
+                    }
                     if matches!(
                         nc,
                         b',' | b'}' | b']' | b':' | b'\n' | b'\r' | b'"' | b'\'' | b'[' | b'{'
@@ -614,16 +1515,35 @@ pub(crate) fn parse_symbol_or_unquoted_string<'i, E: Emitter>(
                             break;
                         }
                     }
-                    // Take next symbol chunk
-                    let part = take_symbol_until_delim(input);
-                    if part.is_empty() {
+                    if i > 0 && opts.tolerate_sql_comments && lex::starts_sql_comment(input) {
                         break;
                     }
-                    emitted.push(' ');
-                    emitted.push_str(part);
+                    // Take next symbol chunk; it belongs to the same word when no space
+                    // separated it (e.g. the `@b.com` in `a@b.com`). A URL and a
+                    // parenthesised aside (`Dr. Smith (retired)`) are taken whole.
+                    let (word, after) = take_ident(input);
+                    let len = if !word.is_empty() && after.starts_with("://") {
+                        url_end(input)
+                    } else if nc == b'(' {
+                        match input[1..]
+                            .find(['(', ')', ',', ':', '[', ']', '{', '}', '"', '\n', '\r'])
+                        {
+                            Some(j) if input.as_bytes()[j + 1] == b')' => j + 2,
+                            _ => break,
+                        }
+                    } else {
+                        let mut rest = *input;
+                        take_symbol_until_delim(&mut rest).len()
+                    };
+                    if len == 0 {
+                        break;
+                    }
+                    emitted.push_str(&r0[..i]);
+                    emitted.push_str(&input[..len]);
+                    *input = &input[len..];
                 }
                 special_emitted = true;
-                emit_json_string_from_lit(out, &emitted, opts.ensure_ascii)
+                emit_json_string_from_lit(out, &emitted, opts.ascii_values())
             }
         };
         if special_emitted {
@@ -640,14 +1560,126 @@ pub(crate) fn parse_symbol_or_unquoted_string<'i, E: Emitter>(
             // If we encounter a structural delimiter where a value is expected (like '}' or ',')
             // treat it as a missing value and emit an empty string without consuming the delimiter.
             if ch == '}' || ch == ',' || ch == ']' {
+                logger.repair(s.len(), "inserted missing value")?;
                 return out.emit_str("\"\"");
             }
+            logger.repair(s.len(), "quoted bare string")?;
             *input = &s[ch.len_utf8()..];
-            return emit_json_string_from_lit(out, ch.encode_utf8(&mut [0; 4]), opts.ensure_ascii);
+            return emit_json_string_from_lit(
+                out,
+                ch.encode_utf8(&mut [0; 4]),
+                opts.ascii_values(),
+            );
         }
         return Ok(());
     }
-    emit_json_string_from_lit(out, sym, opts.ensure_ascii)
+    logger.repair(s.len(), "quoted bare string")?;
+    emit_json_string_from_lit(out, sym, opts.ascii_values())
+}
+
+// Length of the URL (`scheme://...`) at the start of `s`: it runs to whitespace or one of
+// `, ] } " '`, so it keeps its own `:` and `//`.
+fn url_end(s: &str) -> usize {
+    s.find([' ', '\t', '\n', '\r', ',', ']', '}', '"', '\''])
+        .unwrap_or(s.len())
+}
+
+/// A registered wrapper `Name(` at the start of `s` (see `Options::unwrap_functions`):
+/// its mode and the input after the `(`.
+pub(crate) fn unwrap_call<'i>(s: &'i str, opts: &Options) -> Option<(UnwrapMode, &'i str)> {
+    if opts.unwrap_functions.is_empty() {
+        return None;
+    }
+    let (name, rest) = take_ident(s);
+    let mode = opts.unwrap_mode(name)?;
+    let args = rest.trim_start_matches([' ', '\t']).strip_prefix('(')?;
+    Some((mode, args))
+}
+
+/// Typed wrappers nested inside each other's arguments deeper than this are a `Parse` error.
+/// Each level is parsed on the call stack, unlike containers.
+pub(crate) const MAX_UNWRAP_DEPTH: usize = 64;
+
+// Replace the call whose arguments start at `input` with its first argument, converted per
+// `mode`. Later arguments are parsed and dropped; a missing `)` (truncated input) is accepted.
+// Under `Number`, a non-finite argument (`NumberDecimal("NaN")`) is treated like a bare
+// `NaN`: `null` with `normalize_js_nonfinite`, the string `"NaN"` without.
+fn parse_unwrap_call<'i, E: Emitter>(
+    input: &mut &'i str,
+    mode: UnwrapMode,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> JRResult<()> {
+    if logger.calls == MAX_UNWRAP_DEPTH {
+        return Err(to_err(
+            logger.position(input.len()),
+            "typed wrappers nested too deeply",
+        ));
+    }
+    logger.calls += 1;
+    let unwrapped = unwrap_call_args(input, mode, opts, out, logger);
+    logger.calls -= 1;
+    unwrapped
+}
+
+fn unwrap_call_args<'i, E: Emitter>(
+    input: &mut &'i str,
+    mode: UnwrapMode,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut Logger,
+) -> JRResult<()> {
+    let at = input.len();
+    logger.repair(at, "unwrapped typed wrapper")?;
+    let mut arg = String::new();
+    let mut first = true;
+    loop {
+        skip_ws_and_comments(input, opts);
+        if input.is_empty() {
+            break;
+        }
+        if let Some(rest) = input.strip_prefix(')') {
+            *input = rest;
+            break;
+        }
+        // A comma before the first argument (`ObjectId(, "x")`) has no value in front of it.
+        if first && let Some(rest) = input.strip_prefix(',') {
+            *input = rest;
+            continue;
+        }
+        if !first {
+            match input.strip_prefix(',') {
+                Some(rest) => *input = rest,
+                None if starts_value(input) => {}
+                None => break,
+            }
+            skip_ws_and_comments(input, opts);
+            if input.is_empty() || input.starts_with(')') {
+                continue;
+            }
+        }
+        let mut extra = String::new();
+        let buf = if first { &mut arg } else { &mut extra };
+        parse_value(input, opts, &mut StringEmitter::new(buf), logger)?;
+        first = false;
+    }
+    match mode {
+        UnwrapMode::Null => out.emit_str("null"),
+        _ if arg.is_empty() => out.emit_str("null"),
+        UnwrapMode::String if !arg.starts_with(['"', '{', '[']) => {
+            emit_json_string_from_lit(out, &arg, opts.ascii_values())
+        }
+        UnwrapMode::Number => match arg.strip_prefix('"').and_then(|a| a.strip_suffix('"')) {
+            Some(num) if is_json_number(num) => emit_number(out, num, opts),
+            Some(k) if opts.normalize_js_nonfinite && is_non_finite(k) => {
+                logger.repair(at, "normalized non-finite number")?;
+                out.emit_str("null")
+            }
+            _ => out.emit_str(&arg),
+        },
+        UnwrapMode::String => out.emit_str(&arg),
+    }
 }
 
 fn parse_regex_literal<'i, E: Emitter>(
@@ -685,6 +1717,12 @@ fn parse_regex_literal<'i, E: Emitter>(
                     break;
                 }
             }
+            // Not a regex if the token keeps going (e.g. a path like /usr/local/bin):
+            // quote the whole bare token instead.
+            if !ends_bare_value(&s[j..]) {
+                let sym = take_symbol_until_delim(input);
+                return emit_json_string_from_lit(out, sym, false);
+            }
             // Build a cleaned representation: remove escapes for forward slash in the body
             let lit = &s[..j]; // includes both slashes and flags
             let mut cleaned = String::with_capacity(lit.len());
@@ -723,44 +1761,45 @@ fn parse_regex_literal<'i, E: Emitter>(
     emit_json_string_from_lit(out, lit, false)
 }
 
-// ASCII-only string formatter for serde_json serializer
-// Ensures all non-ASCII characters are escaped as \uXXXX (and surrogate pairs when needed).
-#[cfg(feature = "serde")]
-struct AsciiEscaper;
-
+// Rewrite valid JSON `s` compactly, escaping non-ASCII characters as `\uXXXX` (a surrogate
+// pair past the BMP) in keys and/or values, following `ascii_keys()` / `ascii_values()`.
+// One pass over the text, so members keep their order and numbers their spelling.
 #[cfg(feature = "serde")]
-impl serde_json::ser::Formatter for AsciiEscaper {
-    fn write_string_fragment<W>(&mut self, writer: &mut W, fragment: &str) -> std::io::Result<()>
-    where
-        W: ?Sized + std::io::Write,
-    {
-        let mut start = 0usize;
-        let fragment_bytes = fragment.as_bytes();
-        for (i, ch) in fragment.char_indices() {
-            if ch <= '\u{7F}' {
-                continue;
+pub(crate) fn escape_valid_json_ascii(s: &str, opts: &Options) -> String {
+    use std::fmt::Write as _;
+    let mut out = String::with_capacity(s.len() + s.len() / 4);
+    let mut rest = s;
+    while let Some(c) = rest.chars().next() {
+        if c != '"' {
+            if !c.is_ascii_whitespace() {
+                out.push(c);
             }
-            if i > start {
-                writer.write_all(&fragment_bytes[start..i])?;
+            rest = &rest[c.len_utf8()..];
+            continue;
+        }
+        let (lit, after) = rest.split_at(crate::json5::string_end(rest));
+        rest = after;
+        let escape = if after.trim_start().starts_with(':') {
+            opts.ascii_keys()
+        } else {
+            opts.ascii_values()
+        };
+        if !escape {
+            out.push_str(lit);
+            continue;
+        }
+        for ch in lit.chars() {
+            if ch.is_ascii() {
+                out.push(ch);
+                continue;
             }
-            let cp = ch as u32;
-            if cp <= 0xFFFF {
-                // Safe: char in Rust is not a surrogate half
-                write!(writer, "\\u{:04X}", cp)?;
-            } else {
-                // Encode as surrogate pair
-                let v = cp - 0x10000;
-                let high = 0xD800 + ((v >> 10) & 0x3FF);
-                let low = 0xDC00 + (v & 0x3FF);
-                write!(writer, "\\u{:04X}\\u{:04X}", high, low)?;
+            let mut units = [0u16; 2];
+            for unit in ch.encode_utf16(&mut units) {
+                let _ = write!(out, "\\u{:04X}", unit);
             }
-            start = i + ch.len_utf8();
-        }
-        if start < fragment.len() {
-            writer.write_all(&fragment_bytes[start..])?;
         }
-        Ok(())
     }
+    out
 }
 
 fn apply_python_separators(s: &str) -> String {
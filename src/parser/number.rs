@@ -2,7 +2,113 @@
 #![allow(clippy::needless_borrow)]
 
 use crate::emit::{Emitter, JRResult};
-use crate::options::{LeadingZeroPolicy, Options};
+use crate::options::{
+    LeadingZeroPolicy, NegativeZeroPolicy, NumberSuffixPolicy, Options, OverflowPolicy,
+    SafeIntegerPolicy,
+};
+
+/// True when `s` starts with an explicit `+` sign before a number (`+5`, `+.5`).
+/// JSON only allows `-`, so callers drop the `+` and parse the rest as a number.
+#[inline]
+pub fn is_plus_signed_number(s: &str) -> bool {
+    match s.as_bytes() {
+        [b'+', d, ..] if d.is_ascii_digit() => true,
+        [b'+', b'.', d, ..] => d.is_ascii_digit(),
+        _ => false,
+    }
+}
+
+/// `comma_decimal`: for an object value like `3,14}`, return the byte offsets of the comma
+/// and of the end of the fraction when the comma can only be a decimal separator.
+///
+/// Both sides must be plain digits touching the comma (`-?\d+,\d+`), and the fraction must
+/// be followed, after whitespace, by `}`, the end of input, or `,` and then a quote or letter
+/// (the next key). Anything else — spaces around the comma, a fraction or exponent before
+/// it, a fraction followed by `:` or by more digits — keeps the comma as a separator. A
+/// fraction of exactly three digits (`1,000`) is refused too: it reads as well as a
+/// thousands separator.
+pub fn comma_decimal_split(s: &str) -> Option<(usize, usize)> {
+    let b = s.as_bytes();
+    let int_start = usize::from(b.first() == Some(&b'-'));
+    let comma = int_start
+        + b[int_start..]
+            .iter()
+            .take_while(|c| c.is_ascii_digit())
+            .count();
+    if comma == int_start || b.get(comma) != Some(&b',') {
+        return None;
+    }
+    let end = comma
+        + 1
+        + b[comma + 1..]
+            .iter()
+            .take_while(|c| c.is_ascii_digit())
+            .count();
+    if matches!(end - comma - 1, 0 | 3) {
+        return None;
+    }
+    let after = s[end..].trim_start();
+    let next_key = |rest: &str| {
+        rest.trim_start()
+            .starts_with(|c: char| c == '"' || c == '\'' || c.is_ascii_alphabetic())
+    };
+    match after.as_bytes().first() {
+        None | Some(b'}') => Some((comma, end)),
+        Some(b',') if next_key(&after[1..]) => Some((comma, end)),
+        _ => None,
+    }
+}
+
+/// `eval_fractions`: for a value like `1/2`, return its decimal spelling (`0.5`) and the byte
+/// length of the fraction.
+///
+/// Both sides must be integers of at most 15 digits, so the division is exact up to
+/// rounding, with no leading zero (`01/02` reads as a date) and a nonzero denominator;
+/// only the numerator may carry a `-`. The fraction must be followed, after whitespace, by
+/// `,`, `}`, `]` or the end of input, so `1/2/3`, `1/2px` and `1/usr` are left alone.
+pub fn integer_fraction(s: &str) -> Option<(String, usize)> {
+    let b = s.as_bytes();
+    let digits = |from: usize| {
+        let n = b[from..].iter().take_while(|c| c.is_ascii_digit()).count();
+        let plain = (1..=15).contains(&n) && (n == 1 || b[from] != b'0');
+        plain.then_some(from + n)
+    };
+    let slash = digits(usize::from(b.first() == Some(&b'-')))?;
+    if b.get(slash) != Some(&b'/') {
+        return None;
+    }
+    let end = digits(slash + 1)?;
+    if !matches!(
+        s[end..].trim_start().as_bytes().first(),
+        None | Some(b',' | b'}' | b']')
+    ) {
+        return None;
+    }
+    let den: f64 = s[slash + 1..end].parse().ok()?;
+    if den == 0.0 {
+        return None;
+    }
+    let num: f64 = s[..slash].parse().ok()?;
+    Some(((num / den).to_string(), end))
+}
+
+/// Length of a leading JS non-finite word with an optional sign (`NaN`, `+Infinity`,
+/// `-NaN`), if `s` starts with one.
+pub(crate) fn non_finite_len(s: &str) -> Option<usize> {
+    let sign = usize::from(s.starts_with(['+', '-']));
+    let rest = &s[sign..];
+    let word = ["NaN", "Infinity"]
+        .into_iter()
+        .find(|w| rest.starts_with(w))?;
+    let end = sign + word.len();
+    let boundary = !s[end..].starts_with(|c: char| c.is_alphanumeric() || c == '_');
+    boundary.then_some(end)
+}
+
+/// Whether `word` is exactly a JS non-finite number, signed or not.
+pub(crate) fn is_non_finite(word: &str) -> bool {
+    non_finite_len(word) == Some(word.len())
+}
 
 pub fn parse_number_token<E: Emitter>(
     input: &mut &str,
@@ -11,8 +117,10 @@ pub fn parse_number_token<E: Emitter>(
 ) -> JRResult<()> {
     let s = *input;
     // JS non-finite special-case handled here as a robust fallback
-    if opts.normalize_js_nonfinite && s.starts_with("-Infinity") {
-        *input = &s[9..];
+    if opts.normalize_js_nonfinite
+        && let Some(n) = non_finite_len(s)
+    {
+        *input = &s[n..];
         return out.emit_str("null");
     }
     // Work directly on &str; keep operations ASCII-focused where possible
@@ -25,6 +133,11 @@ pub fn parse_number_token<E: Emitter>(
         if ch.is_whitespace() || matches!(ch, ',' | '}' | ']' | ')' | '(' | ':') {
             break;
         }
+        // A `"` after a whole number starts the next key or element when the comma is
+        // missing (`{"a":1"b":2}`); after anything else (`1+"x"`) it stays in the token
+        if ch == '"' && is_json_number(&s[..end_seg]) {
+            break;
+        }
         // Stop if '/' starts a comment
         if ch == '/' {
             let p = end_seg + l;
@@ -42,6 +155,30 @@ pub fn parse_number_token<E: Emitter>(
     }
     let seg = &s[..end_seg];
 
+    // No mantissa digits between the sign and the exponent (`-E5`): the value is zero, or a
+    // string when incomplete exponents are not tolerated.
+    if is_bare_exponent(seg) {
+        *input = &s[end_seg..];
+        if !opts.number_tolerance_incomplete_exponent {
+            return crate::parser::strings::emit_json_string_from_lit(
+                out,
+                seg,
+                opts.ascii_values(),
+            );
+        }
+        return out.emit_str("0");
+    }
+
+    if opts.number_suffix != NumberSuffixPolicy::Keep
+        && let Some((num, _)) = split_unit_suffix(seg)
+    {
+        *input = &s[end_seg..];
+        return match opts.number_suffix {
+            NumberSuffixPolicy::Strip => emit_number(out, num, opts),
+            _ => crate::parser::strings::emit_json_string_from_lit(out, seg, opts.ascii_values()),
+        };
+    }
+
     // Quick suspicious checks on the entire segment
     let mut dot_count = 0usize;
     let mut has_alpha_non_e = false;
@@ -70,7 +207,7 @@ pub fn parse_number_token<E: Emitter>(
     }
     if has_alpha_non_e || has_slash || dot_count > 1 || hyphen_suspicious {
         *input = &s[end_seg..];
-        return crate::parser::strings::emit_json_string_from_lit(out, seg, opts.ensure_ascii);
+        return crate::parser::strings::emit_json_string_from_lit(out, seg, opts.ascii_values());
     }
 
     // Parse a valid JSON number prefix from the start with tolerances.
@@ -104,7 +241,7 @@ pub fn parse_number_token<E: Emitter>(
                 return crate::parser::strings::emit_json_string_from_lit(
                     out,
                     seg,
-                    opts.ensure_ascii,
+                    opts.ascii_values(),
                 );
             }
         } else {
@@ -167,6 +304,15 @@ pub fn parse_number_token<E: Emitter>(
                     break;
                 }
             }
+            if any == 0 && !opts.number_tolerance_incomplete_exponent {
+                // not tolerated: the whole segment is a string, as in the LLM engine
+                *input = &s[end_seg..];
+                return crate::parser::strings::emit_json_string_from_lit(
+                    out,
+                    seg,
+                    opts.ascii_values(),
+                );
+            }
             if any == 0 {
                 // invalid exponent -> drop exponent entirely (keep base)
                 advance_to = i; // advance past 'e' and optional sign
@@ -191,6 +337,11 @@ pub fn parse_number_token<E: Emitter>(
     if tok.is_empty() {
         return out.emit_str("0");
     }
+    // A sign with no digits (`[-]`, or cut off after `-`) holds no number: an empty string,
+    // like the missing value it stands for.
+    if tok == "-" {
+        return out.emit_str("\"\"");
+    }
 
     // Leading zeros policy (after optional '-')
     if let Some(first) = tok.chars().next() {
@@ -202,7 +353,7 @@ pub fn parse_number_token<E: Emitter>(
                     return crate::parser::strings::emit_json_string_from_lit(
                         out,
                         &tok,
-                        opts.ensure_ascii,
+                        opts.ascii_values(),
                     );
                 }
             }
@@ -214,19 +365,192 @@ pub fn parse_number_token<E: Emitter>(
         if let Some(stripped) = tok.strip_prefix('-') {
             let mut buf = String::from("-0");
             buf.push_str(stripped);
-            return out.emit_str(&buf);
+            return emit_number(out, &buf, opts);
         } else {
             let mut buf = String::from("0");
             buf.push_str(tok);
-            return out.emit_str(&buf);
+            return emit_number(out, &buf, opts);
         }
     }
     // Trailing dot tolerance (only if not a suspicious double-dot case which we handled earlier)
     if ends_with_dot && opts.number_tolerance_trailing_dot {
-        let mut buf = String::from(tok);
-        buf.push('0');
-        return out.emit_str(&buf);
+        // Before an exponent the dot is dropped instead: `1.E3` → `1e3`.
+        let buf = match tok.split_once(['e', 'E']) {
+            Some((base, exp)) => format!("{}e{exp}", &base[..base.len() - 1]),
+            None => format!("{tok}0"),
+        };
+        return emit_number(out, &buf, opts);
+    }
+
+    emit_number(out, tok, opts)
+}
+
+/// Whether `seg` is a sign and an exponent without mantissa digits (`-E5`, `-e3`, `-.e+1`),
+/// which both engines read as `0` under `number_tolerance_incomplete_exponent`. Without the
+/// sign (`e5`, `E1`, `.e5`) the token is a bare word, not a number, and is quoted.
+pub(crate) fn is_bare_exponent(seg: &str) -> bool {
+    let Some(s) = seg.strip_prefix('-') else {
+        return false;
+    };
+    let s = s.strip_prefix('.').unwrap_or(s);
+    let Some(exp) = s.strip_prefix(['e', 'E']) else {
+        return false;
+    };
+    let digits = exp.strip_prefix(['+', '-']).unwrap_or(exp);
+    !digits.is_empty() && digits.bytes().all(|b| b.is_ascii_digit())
+}
+
+pub(crate) fn emit_number<E: Emitter>(out: &mut E, tok: &str, opts: &Options) -> JRResult<()> {
+    match opts.safe_integers {
+        SafeIntegerPolicy::Clamp if is_unsafe_integer(tok) => {
+            return out.emit_str(clamp_integer(tok));
+        }
+        SafeIntegerPolicy::Quote if is_unsafe_integer(tok) => {
+            return crate::parser::strings::emit_json_string_from_lit(out, tok, false);
+        }
+        _ => {}
+    }
+    match opts.overflow {
+        OverflowPolicy::Quote if overflows_f64(tok) => {
+            return crate::parser::strings::emit_json_string_from_lit(out, tok, false);
+        }
+        OverflowPolicy::Null if overflows_f64(tok) => return out.emit_str("null"),
+        _ => {}
+    }
+    if opts.normalize_numbers {
+        out.emit_str(&normalize_number(tok))
+    } else if opts.negative_zero == NegativeZeroPolicy::Normalize && is_negative_zero(tok) {
+        out.emit_str(&tok[1..])
+    } else {
+        out.emit_str(tok)
+    }
+}
+
+/// Whether the number token `tok` is a zero with a minus sign (`-0`, `-0.0`, `-0e5`).
+pub(crate) fn is_negative_zero(tok: &str) -> bool {
+    tok.strip_prefix('-').is_some_and(|rest| {
+        let mantissa = rest.split(['e', 'E']).next().unwrap_or(rest);
+        mantissa.bytes().any(|b| b == b'0') && mantissa.bytes().all(|b| b == b'0' || b == b'.')
+    })
+}
+
+/// Whether `s` is exactly one strict JSON number: `-?(0|[1-9][0-9]*)(.[0-9]+)?([eE][+-]?[0-9]+)?`.
+pub(crate) fn is_json_number(s: &str) -> bool {
+    let digits = |b: &[u8]| b.iter().take_while(|c| c.is_ascii_digit()).count();
+    let mut b = s.as_bytes();
+    if let [b'-', rest @ ..] = b {
+        b = rest;
+    }
+    let n = digits(b);
+    if n == 0 || (n > 1 && b[0] == b'0') {
+        return false;
+    }
+    b = &b[n..];
+    if let [b'.', rest @ ..] = b {
+        let n = digits(rest);
+        if n == 0 {
+            return false;
+        }
+        b = &rest[n..];
+    }
+    if let [b'e' | b'E', rest @ ..] = b {
+        let rest = rest
+            .strip_prefix(b"+")
+            .or(rest.strip_prefix(b"-"))
+            .unwrap_or(rest);
+        let n = digits(rest);
+        if n == 0 {
+            return false;
+        }
+        b = &rest[n..];
+    }
+    b.is_empty()
+}
+
+/// Split a number with an attached unit suffix of letters or `%` (`30s`, `10MB`, `100%`)
+/// into the number and the suffix. A hex literal (`0xFF`) and an incomplete exponent
+/// (`1e`) have no suffix.
+pub(crate) fn split_unit_suffix(seg: &str) -> Option<(&str, &str)> {
+    let start = seg
+        .trim_end_matches(|c: char| c.is_alphabetic() || c == '%')
+        .len();
+    let (num, unit) = seg.split_at(start);
+    if unit.is_empty() || unit.eq_ignore_ascii_case("e") || !is_json_number(num) {
+        return None;
     }
+    if num.trim_start_matches('-') == "0" && unit.starts_with(['x', 'X']) {
+        return None;
+    }
+    Some((num, unit))
+}
+
+/// Largest integer a double holds exactly, `2^53 - 1`.
+const MAX_SAFE_INTEGER: &str = "9007199254740991";
+
+/// Whether the number token `tok` is an integer literal beyond `±(2^53 - 1)`.
+pub(crate) fn is_unsafe_integer(tok: &str) -> bool {
+    let digits = tok.strip_prefix('-').unwrap_or(tok);
+    if digits.is_empty() || !digits.bytes().all(|b| b.is_ascii_digit()) {
+        return false;
+    }
+    let digits = digits.trim_start_matches('0');
+    digits.len() > MAX_SAFE_INTEGER.len()
+        || (digits.len() == MAX_SAFE_INTEGER.len() && digits > MAX_SAFE_INTEGER)
+}
 
-    out.emit_str(tok)
+/// The safe-range edge on the side of the unsafe integer `tok`.
+pub(crate) fn clamp_integer(tok: &str) -> &'static str {
+    if tok.starts_with('-') {
+        "-9007199254740991"
+    } else {
+        MAX_SAFE_INTEGER
+    }
+}
+
+/// Whether the number token `tok` is finite in JSON but too large for an `f64` (`1e400`).
+pub(crate) fn overflows_f64(tok: &str) -> bool {
+    tok.parse::<f64>().is_ok_and(f64::is_infinite)
+}
+
+/// Canonical spelling of a number token for `normalize_numbers`: lowercase `e`, no `+` or
+/// leading zeros in the exponent, no leading integer or trailing fraction zeros, and `0`
+/// for any zero. `1.50E+03` → `1.5e3`, `-0.0` → `0`, `2.0e0` → `2`.
+pub(crate) fn normalize_number(tok: &str) -> String {
+    let (neg, rest) = match tok.strip_prefix('-') {
+        Some(r) => (true, r),
+        None => (false, tok),
+    };
+    let (mantissa, exp) = match rest.find(['e', 'E']) {
+        Some(i) => (&rest[..i], &rest[i + 1..]),
+        None => (rest, ""),
+    };
+    let (int, frac) = mantissa.split_once('.').unwrap_or((mantissa, ""));
+    let int = int.trim_start_matches('0');
+    let frac = frac.trim_end_matches('0');
+    if int.is_empty() && frac.is_empty() {
+        return "0".to_string();
+    }
+    let (exp_neg, exp_digits) = match exp.strip_prefix('-') {
+        Some(d) => (true, d),
+        None => (false, exp.strip_prefix('+').unwrap_or(exp)),
+    };
+    let exp_digits = exp_digits.trim_start_matches('0');
+
+    let mut out = String::with_capacity(tok.len());
+    if neg {
+        out.push('-');
+    }
+    out.push_str(if int.is_empty() { "0" } else { int });
+    if !frac.is_empty() {
+        out.push('.');
+        out.push_str(frac);
+    }
+    if !exp_digits.is_empty() {
+        out.push('e');
+        if exp_neg {
+            out.push('-');
+        }
+        out.push_str(exp_digits);
+    }
+    out
 }
@@ -1,16 +1,21 @@
 #![allow(clippy::needless_lifetimes)]
 
-use super::array::parse_array;
-use super::lex::{skip_ellipsis, skip_word_markers, skip_ws_and_comments};
-use super::number::parse_number_token;
+use super::lex::{
+    skip_ellipsis, skip_word_markers, skip_ws_and_comments, starts_sql_comment, take_ident,
+};
+use super::number::{
+    comma_decimal_split, integer_fraction, is_plus_signed_number, parse_number_token,
+};
 use super::strings::{
-    emit_json_string_from_lit, parse_one_string_key_strict, parse_string_literal_concat_fast,
+    doubled_quote_body, emit_json_string_from_lit, escaped_quote_closes, mixed_quote_closes,
+    parse_one_string_key_strict, parse_string_literal_concat_fast,
 };
 use crate::emit::{Emitter, JRResult};
-use crate::options::Options;
+use crate::options::{MissingValuePolicy, Options, Strictness};
 use crate::parser::parse_regex_literal;
 use crate::parser::parse_symbol_or_unquoted_string;
-use memchr::memchr2;
+use crate::parser::{Checkpoint, Step};
+use crate::parser::{null_token_len, parse_null_token};
 
 // Helper: if the upcoming content begins with a line comment (// or #),
 // cut the comment up to the earliest of newline (\n/\r) or a closing '}'.
@@ -25,8 +30,11 @@ fn skip_line_comment_preserving_rbrace(input: &mut &str, opts: &Options) {
     let after_ws = s.trim_start_matches([' ', '\t']);
     // If no comment marker immediately after spaces, return
     let bytes = after_ws.as_bytes();
-    if bytes.starts_with(b"//") || (opts.tolerate_hash_comments && bytes.first() == Some(&b'#')) {
-        let (_skip, rest) = if bytes.starts_with(b"//") {
+    if bytes.starts_with(b"//")
+        || (opts.tolerate_hash_comments && bytes.first() == Some(&b'#'))
+        || (opts.tolerate_sql_comments && starts_sql_comment(after_ws))
+    {
+        let (_skip, rest) = if bytes.starts_with(b"//") || bytes.starts_with(b"--") {
             (2usize, &after_ws[2..])
         } else {
             (1usize, &after_ws[1..])
@@ -55,46 +63,165 @@ fn skip_line_comment_preserving_rbrace(input: &mut &str, opts: &Options) {
     }
 }
 
-pub fn parse_object<'i, E: Emitter>(
-    input: &mut &'i str,
+// A number in value position, reading `3,14` as `3.14` under `comma_decimal`.
+fn parse_value_number<E: Emitter>(
+    input: &mut &str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<()> {
+    if opts.comma_decimal
+        && let Some((comma, end)) = comma_decimal_split(input)
+    {
+        logger.repair(input.len(), "read comma as decimal separator")?;
+        let joined = format!("{}.{}", &input[..comma], &input[comma + 1..end]);
+        *input = &input[end..];
+        return parse_number_token(&mut joined.as_str(), opts, out);
+    }
+    parse_fraction_or_number(input, opts, out, logger)
+}
+
+// A number in value or element position, reading `1/2` as `0.5` under `eval_fractions`.
+pub(super) fn parse_fraction_or_number<E: Emitter>(
+    input: &mut &str,
     opts: &Options,
     out: &mut E,
     logger: &mut crate::parser::Logger,
 ) -> JRResult<()> {
-    // assumes current starts with '{'
-    if !input.starts_with('{') {
-        return Ok(());
+    if opts.eval_fractions
+        && let Some((value, end)) = integer_fraction(input)
+    {
+        logger.repair(input.len(), "evaluated integer fraction")?;
+        *input = &input[end..];
+        return parse_number_token(&mut value.as_str(), opts, out);
     }
+    parse_number_token(input, opts, out)
+}
+
+/// An object being parsed, kept on the explicit stack of `parse_container`.
+pub(super) struct ObjectFrame<'i> {
+    first: bool,
+    members: usize,
+    doubled: usize,
+    // Opened at a `[` holding members (`["a": 1]`), so a `]` closes it as written.
+    bracketed: bool,
+    // Where the nested container being parsed as a value started, for `salvage` and
+    // `annotate_source` once it is done.
+    value: Option<(Checkpoint<'i>, usize)>,
+}
+
+/// Consume the `{` at `input`, or the `[` of bracketed members when `bracketed`. Returns the
+/// frame for its members, or `None` when the object is empty and already closed.
+pub(super) fn open_object<'i, E: Emitter>(
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    bracketed: bool,
+) -> JRResult<Option<ObjectFrame<'i>>> {
     *input = &input[1..];
     out.emit_char('{')?;
     // Enter-object fast path: if only ASCII whitespace before a closing '}', close immediately.
     if let Some('}') = fast_ws_to_only_rbrace(input) {
         out.emit_char('}')?;
-        return Ok(());
+        return Ok(None);
     }
     skip_ws_and_comments(input, opts);
-    let mut first = true;
+    Ok(Some(ObjectFrame {
+        first: true,
+        members: 0,
+        doubled: 0,
+        bracketed,
+        value: None,
+    }))
+}
+
+/// Parse members until the object closes or a value is a nested container or key chain,
+/// which is left at `input` for `parse_container`. `nested` is the result of the nested container that
+/// was left last time.
+pub(super) fn object_step<'i, E: Emitter>(
+    frame: &mut ObjectFrame<'i>,
+    nested: Option<JRResult<()>>,
+    input: &mut &'i str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<Step> {
+    let mut parsed = nested.zip(frame.value.take());
     loop {
+        if let Some((parsed, (cp, start))) = parsed.take() {
+            if let Err(err) = parsed {
+                super::salvage(err, cp, input, opts, out, logger)?;
+            }
+            logger.annotate(out, start)?;
+            logger.pop_key();
+
+            // Fast path after value: ASCII ws -> next delimiter ',' or '}'
+            if let Some(delim) = fast_ws_to_comma_or_rbrace(input) {
+                match delim {
+                    ',' => { /* continue loop to next member */ }
+                    '}' => {
+                        if close_object(out, &mut frame.doubled)? {
+                            break;
+                        }
+                    }
+                    _ => unreachable!(),
+                }
+            } else {
+                // Attempt to preserve trailing '}' when a line comment appears before it
+                skip_line_comment_preserving_rbrace(input, opts);
+                skip_ws_and_comments(input, opts);
+                if input.starts_with('}') {
+                    *input = &input[1..];
+                    if close_object(out, &mut frame.doubled)? {
+                        break;
+                    }
+                    continue;
+                }
+                if input.starts_with(',') {
+                    *input = &input[1..];
+                } else if !input.is_empty() && !input.starts_with(']') {
+                    logger.repair(input.len(), "inserted missing comma")?;
+                }
+            }
+        }
         skip_ws_and_comments(input, opts);
         if input.is_empty() {
             // 截断对象，补全闭合
+            logger.repair(0, "closed unterminated object")?;
+            out.emit_char('}')?;
+            break;
+        }
+        if frame.bracketed
+            && let Some(rest) = input.strip_prefix(']')
+        {
+            *input = rest;
             out.emit_char('}')?;
             break;
         }
         if input.starts_with(']') {
+            // Either an enclosing array's closer or a crossed one written for this object
+            // (`[{"a": 1], {"b": 2}]`), which is consumed.
+            if logger.crossed_closer(input) {
+                logger.repair(input.len(), "closed object at crossed bracket")?;
+                *input = &input[1..];
+            } else {
+                logger.repair(input.len(), "closed object at mismatched bracket")?;
+            }
             out.emit_char('}')?;
             break;
         }
         if input.starts_with('}') {
             *input = &input[1..];
-            out.emit_char('}')?;
-            break;
+            if close_object(out, &mut frame.doubled)? {
+                break;
+            }
+            continue;
         }
         // comma will be emitted later only when a member is actually produced
 
         // 可选：跳过词注释与省略号
         skip_word_markers(input, &opts.word_comment_markers);
-        while skip_ellipsis(input) {
+        while skip_ellipsis(input, opts) {
             skip_ws_and_comments(input, opts);
         }
         // Optional comma between members (fast path: ASCII ws -> ',' or '}')
@@ -102,8 +229,10 @@ pub fn parse_object<'i, E: Emitter>(
             match delim {
                 ',' => { /* consumed comma, proceed to next key */ }
                 '}' => {
-                    out.emit_char('}')?;
-                    break;
+                    if close_object(out, &mut frame.doubled)? {
+                        break;
+                    }
+                    continue;
                 }
                 _ => unreachable!(),
             }
@@ -113,60 +242,155 @@ pub fn parse_object<'i, E: Emitter>(
             }
             if input.starts_with('}') {
                 *input = &input[1..];
-                out.emit_char('}')?;
-                break;
+                if close_object(out, &mut frame.doubled)? {
+                    break;
+                }
+                continue;
             }
         }
-        if !first {
-            out.emit_char(',')?;
-        }
-        first = false;
-
-        // key: quoted or unquoted identifier/span until colon/comma/brace
+        // key: quoted or unquoted identifier/span until colon/comma/brace.
+        // The member is emitted only once its value is known to be present (or filled in per
+        // `missing_value_policy`), so a dangling key can still be dropped.
         skip_ws_and_comments(input, opts);
         if input.is_empty() {
+            logger.repair(0, "closed unterminated object")?;
             out.emit_char('}')?;
             break;
         }
-        let key_str = if input.starts_with('"') || input.starts_with('\'') {
+        // A comma with no member after it (`{,}`, `{/* todo */,}`) is a trailing comma.
+        if let Some(rest) = input.strip_prefix('}') {
+            *input = rest;
+            if close_object(out, &mut frame.doubled)? {
+                break;
+            }
+            continue;
+        }
+        // A `{` where a key is expected doubles the brace (`{{"a":1}}`): its members join this
+        // object and its `}` is dropped rather than read as an object under an empty key.
+        if let Some(rest) = input.strip_prefix('{') {
+            logger.repair(input.len(), "dropped doubled brace")?;
+            *input = rest;
+            frame.doubled += 1;
+            continue;
+        }
+        let key_at = input.len();
+        let computed = if opts.repair_undefined && input.starts_with('[') {
+            take_computed_key(input, opts)
+        } else {
+            None
+        };
+        let mut key_str = if let Some(k) = computed {
+            logger.repair(input.len(), "unwrapped computed key")?;
+            k
+        } else if let Some((body, len)) = doubled_quote_body(input) {
+            logger.repair(input.len(), "collapsed doubled quotes")?;
+            *input = &input[len..];
+            if opts.trim_keys {
+                body.trim().to_string()
+            } else {
+                body.to_string()
+            }
+        } else if input.starts_with('"') || input.starts_with('\'') {
+            if input.starts_with('\'') {
+                logger.repair(input.len(), "converted single-quoted key")?;
+            }
             // For keys, parse literal content for path, then emit as JSON string
-            let k = parse_one_string_key_strict(input)?;
-            emit_json_string_from_lit(out, &k, opts.ensure_ascii)?;
+            let mut k = parse_one_string_key_strict(input, opts)?;
+            if opts.trim_keys {
+                k = k.trim().to_string();
+            }
             k
         } else {
+            logger.repair(input.len(), "quoted unquoted key")?;
             // Fast path: take until one of ':', '}', ',' or newline via bytes scan
-            let key = take_key_until_delim_fast(input)
+            let key = take_url_key(input)
+                .or_else(|| take_key_until_delim_fast(input, opts.equals_separators))
                 .unwrap_or_else(|| take_until_delim(input, &[':', '}', ',']));
             let k = key.trim();
-            emit_json_string_from_lit(out, k, opts.ensure_ascii)?;
-            k.to_string()
+            if k.contains("\\\"") || k.contains("\\'") {
+                k.replace("\\\"", "\"").replace("\\'", "'")
+            } else {
+                k.to_string()
+            }
         };
+        logger.check_key(&mut key_str, key_at)?;
+        logger.trace(key_at, format_args!("key {key_str:?}"));
         skip_ws_and_comments(input, opts);
-        // colon
-        if input.starts_with(':') {
+        // colon (`=`, `=>` and `->` count as one under `equals_separators`)
+        let mut has_colon = input.starts_with(':');
+        if has_colon {
             *input = &input[1..];
-            out.emit_char(':')?;
-        } else {
-            out.emit_char(':')?; // insert missing colon
+        } else if opts.equals_separators && input.starts_with('=') {
+            let n = if input.starts_with("=>") { 2 } else { 1 };
+            *input = &input[n..];
+            logger.repair(input.len(), "replaced '=' separator with colon")?;
+            has_colon = true;
+        } else if opts.equals_separators && input.starts_with("->") {
+            *input = &input[2..];
+            logger.repair(input.len(), "replaced '->' separator with colon")?;
+            has_colon = true;
         }
         skip_ws_and_comments(input, opts);
 
         // value（可选：再次跳过词注释/省略号）
-        if input.is_empty() {
-            out.emit_char('}')?;
-            break;
-        }
         skip_word_markers(input, &opts.word_comment_markers);
-        while skip_ellipsis(input) {
+        while skip_ellipsis(input, opts) {
             skip_ws_and_comments(input, opts);
         }
+        let missing = input.is_empty() || input.starts_with([',', '}', ']']);
+        if missing && opts.missing_value_policy == MissingValuePolicy::DropKey {
+            logger.repair(input.len(), "dropped key without value")?;
+            continue;
+        }
+        if !frame.first {
+            out.emit_char(',')?;
+        }
+        frame.first = false;
+        frame.members += 1;
+        logger.count_member(frame.members, input.len())?;
+        emit_json_string_from_lit(out, &key_str, opts.ascii_keys())?;
+        if !has_colon {
+            logger.repair(input.len(), "inserted missing colon")?;
+        }
+        out.emit_char(':')?;
+        if missing {
+            logger.repair(input.len(), "inserted missing value")?;
+            match opts.missing_value_policy {
+                MissingValuePolicy::Null => out.emit_str("null")?,
+                _ => out.emit_str("\"\"")?,
+            }
+            continue;
+        }
+        logger.tick(input.len())?;
         // Track path for value
         logger.push_key(key_str);
+        logger.trace_value(input);
+        let start = logger.source_offset(input);
+        let cp = logger.checkpoint(input, out);
         let c = input.chars().next().unwrap();
-        match c {
-            '{' => super::object::parse_object(input, opts, out, logger)?,
-            '[' => parse_array(input, opts, out, logger)?,
+        let value = match c {
+            // `{a: b: c}`: the value is followed by a colon, so it reads as a key itself.
+            _ if nested_key_ahead(input, opts) => match opts.strictness {
+                Strictness::Conservative => Err(super::to_err(
+                    logger.position(input.len()),
+                    "object value followed by a colon",
+                )),
+                Strictness::Aggressive => {
+                    frame.value = Some((cp, start));
+                    return Ok(Step::Member);
+                }
+            },
+            _ if null_token_len(input, opts).is_some() => {
+                parse_null_token(input, opts, out, logger)
+            }
+            '{' | '[' => {
+                frame.value = Some((cp, start));
+                return Ok(Step::Descend);
+            }
             '"' | '\'' => {
+                if c == '\'' {
+                    logger.repair(input.len(), "converted single-quoted string")?;
+                }
                 // Heuristic: if a double-quoted value contains a comma before a suspicious close
                 // whose following token is not a valid terminator, prefer closing at the comma.
                 if c == '"' {
@@ -175,14 +399,23 @@ pub fn parse_object<'i, E: Emitter>(
                     let mut escape = false;
                     let mut first_comma: Option<usize> = None;
                     let mut close_pos: Option<usize> = None;
+                    let mut escaped_quote = false;
                     while i < s_val.len() {
                         let ch = s_val[i..].chars().next().unwrap();
                         let l = ch.len_utf8();
                         i += l;
                         if escape {
                             escape = false;
+                            if ch == '"' && escaped_quote_closes(&s_val[i..]) {
+                                break;
+                            }
+                            escaped_quote |= ch == '"';
                             continue;
                         }
+                        // `"he said \"hi'}`: the string parser closes it at the `'`.
+                        if ch == '\'' && escaped_quote && mixed_quote_closes(&s_val[i..], '"') {
+                            break;
+                        }
                         if ch == '\\' {
                             escape = true;
                             continue;
@@ -206,7 +439,8 @@ pub fn parse_object<'i, E: Emitter>(
                         if !after_ok {
                             if let Some(comma_i) = first_comma {
                                 let content = &s_val[1..comma_i];
-                                emit_json_string_from_lit(out, content, opts.ensure_ascii)?;
+                                emit_json_string_from_lit(out, content, opts.ascii_values())?;
+                                logger.annotate(out, start)?;
                                 // leave input at comma for the outer loop to consume
                                 *input = &s_val[comma_i..];
                                 logger.pop_key();
@@ -217,8 +451,9 @@ pub fn parse_object<'i, E: Emitter>(
                                     match delim {
                                         ',' => { /* next member */ }
                                         '}' => {
-                                            out.emit_char('}')?;
-                                            return Ok(());
+                                            if close_object(out, &mut frame.doubled)? {
+                                                return Ok(Step::Done);
+                                            }
                                         }
                                         _ => {}
                                     }
@@ -228,41 +463,136 @@ pub fn parse_object<'i, E: Emitter>(
                         }
                     }
                 }
-                parse_string_literal_concat_fast(input, opts, out)?
+                parse_string_literal_concat_fast(input, opts, out, logger)
             }
-            '/' => parse_regex_literal(input, opts, out)?,
+            '/' => parse_regex_literal(input, opts, out),
             c if c == '-' || c == '.' || c.is_ascii_digit() => {
-                parse_number_token(input, opts, out)?
+                parse_value_number(input, opts, out, logger)
+            }
+            '+' if is_plus_signed_number(input) => {
+                *input = &input[1..];
+                parse_number_token(input, opts, out)
             }
-            _ => parse_symbol_or_unquoted_string(input, opts, out, logger)?,
+            _ => parse_symbol_or_unquoted_string(input, opts, out, logger),
+        };
+        parsed = Some((value, (cp, start)));
+    }
+    Ok(Step::Done)
+}
+
+// Whether the object value or array element at `input` is itself a key: a double-quoted
+// string or an identifier directly followed by `:` and another value (`b: c` in `{a: b: c}`,
+// `a: 1` in `[a: 1]`). A colon
+// followed by `/` or `\` belongs to a URL or path (`http://x`, `C:\dir`) and does not count.
+pub(super) fn nested_key_ahead(input: &str, opts: &Options) -> bool {
+    let rest = if input.starts_with('"') {
+        &input[crate::json5::string_end(input)..]
+    } else {
+        let (ident, rest) = take_ident(input);
+        if ident.is_empty() {
+            return false;
         }
-        logger.pop_key();
+        rest
+    };
+    let Some(mut rest) = rest.trim_start_matches([' ', '\t']).strip_prefix(':') else {
+        return false;
+    };
+    if rest.starts_with(['/', '\\']) {
+        return false;
+    }
+    skip_ws_and_comments(&mut rest, opts);
+    !(rest.is_empty() || rest.starts_with([',', '}', ']', ':']))
+}
 
-        // Fast path after value: ASCII ws -> next delimiter ',' or '}'
-        if let Some(delim) = fast_ws_to_comma_or_rbrace(input) {
-            match delim {
-                ',' => { /* continue loop to next member */ }
-                '}' => {
-                    out.emit_char('}')?;
-                    break;
-                }
-                _ => unreachable!(),
-            }
+/// `Strictness::Aggressive`: a key at value position (see `nested_key_ahead`) read with the
+/// value after its colon as a one-member object, nesting again for a chain (`b: c: d`). Kept
+/// on the explicit stack of `parse_container` while its value is a nested container.
+pub(super) struct MemberFrame {
+    // Where each link of the chain after the first starts, innermost last.
+    links: Vec<usize>,
+    // Where the value after the last colon starts, for `annotate_source`.
+    value: usize,
+}
+
+/// Open the one-member objects of the key chain at `input`, leaving it at the value.
+pub(super) fn open_nested_member<E: Emitter>(
+    input: &mut &str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<MemberFrame> {
+    let mut links = Vec::new();
+    loop {
+        logger.repair(input.len(), "wrapped nested key in object")?;
+        let key_at = input.len();
+        let mut key = if input.starts_with('"') {
+            parse_one_string_key_strict(input, opts)?
         } else {
-            // Attempt to preserve trailing '}' when a line comment appears before it
-            skip_line_comment_preserving_rbrace(input, opts);
-            skip_ws_and_comments(input, opts);
-            if input.starts_with('}') {
-                *input = &input[1..];
-                out.emit_char('}')?;
-                break;
-            }
-            if input.starts_with(',') {
-                *input = &input[1..];
-            }
+            let (ident, rest) = take_ident(input);
+            *input = rest;
+            ident.to_string()
+        };
+        if opts.trim_keys {
+            key = key.trim().to_string();
+        }
+        logger.check_key(&mut key, key_at)?;
+        skip_ws_and_comments(input, opts);
+        *input = &input[1..];
+        skip_ws_and_comments(input, opts);
+        out.emit_char('{')?;
+        emit_json_string_from_lit(out, &key, opts.ascii_keys())?;
+        out.emit_char(':')?;
+        logger.push_key(key);
+        if !nested_key_ahead(input, opts) {
+            break;
+        }
+        links.push(logger.source_offset(input));
+    }
+    Ok(MemberFrame { links, value: 0 })
+}
+
+/// Parse the value of the key chain and close its objects. A nested container is left at
+/// `input` for `parse_container`, which hands back its result as `nested`.
+pub(super) fn member_step<E: Emitter>(
+    frame: &mut MemberFrame,
+    nested: Option<JRResult<()>>,
+    input: &mut &str,
+    opts: &Options,
+    out: &mut E,
+    logger: &mut crate::parser::Logger,
+) -> JRResult<Step> {
+    match nested {
+        None if input.starts_with(['{', '[']) && null_token_len(input, opts).is_none() => {
+            logger.tick(input.len())?;
+            logger.trace_value(input);
+            frame.value = logger.source_offset(input);
+            return Ok(Step::Descend);
         }
+        None => super::parse_value(input, opts, out, logger)?,
+        Some(parsed) => {
+            parsed?;
+            logger.annotate(out, frame.value)?;
+        }
+    }
+    while let Some(start) = frame.links.pop() {
+        logger.pop_key();
+        out.emit_char('}')?;
+        logger.annotate(out, start)?;
+    }
+    logger.pop_key();
+    out.emit_char('}')?;
+    Ok(Step::Done)
+}
+
+// Handle a consumed `}`: it closes one doubled brace while any are open, otherwise the object
+// itself. Returns whether the object is now closed.
+fn close_object<E: Emitter>(out: &mut E, doubled: &mut usize) -> JRResult<bool> {
+    if *doubled > 0 {
+        *doubled -= 1;
+        return Ok(false);
     }
-    Ok(())
+    out.emit_char('}')?;
+    Ok(true)
 }
 
 fn take_until_delim<'i>(input: &mut &'i str, delims: &[char]) -> &'i str {
@@ -278,8 +608,60 @@ fn take_until_delim<'i>(input: &mut &'i str, delims: &[char]) -> &'i str {
     &s[..end]
 }
 
+// JS computed key: `["a"]` or `[name]` followed by a colon. Returns the unwrapped key and
+// consumes through the `]`; anything else (e.g. an array in key position) is left untouched.
+fn take_computed_key(input: &mut &str, opts: &Options) -> Option<String> {
+    let inner = input.strip_prefix('[')?.trim_start();
+    let (key, rest) = if inner.starts_with(['"', '\'']) {
+        let mut cur = inner;
+        let k = parse_one_string_key_strict(&mut cur, opts).ok()?;
+        (k, cur.trim_start())
+    } else {
+        let end = inner.find(|c: char| {
+            matches!(
+                c,
+                ']' | '[' | '{' | '}' | ',' | ':' | '"' | '\'' | '\n' | '\r'
+            )
+        })?;
+        let k = inner[..end].trim();
+        if k.is_empty() {
+            return None;
+        }
+        (k.to_string(), &inner[end..])
+    };
+    let after = rest.strip_prefix(']')?;
+    if !after.trim_start().starts_with(':') {
+        return None;
+    }
+    *input = after;
+    Some(key)
+}
+
+// A bare key that starts with a URL scheme (`{http://x: 1}`) keeps its `://` and any later
+// colons: the key runs to whitespace or `, { } [ ] " '`, and the last colon in that run that
+// is not part of a `//` separates it from the value (`{http://x:8080/a:1}` has key
+// `http://x:8080/a`). With no such colon the whole run is the key.
+fn take_url_key<'i>(input: &mut &'i str) -> Option<&'i str> {
+    let s = *input;
+    let (scheme, rest) = take_ident(s);
+    if scheme.is_empty() || !rest.starts_with("://") {
+        return None;
+    }
+    let end = s
+        .find([' ', '\t', '\n', '\r', ',', '{', '}', '[', ']', '"', '\''])
+        .unwrap_or(s.len());
+    let start = scheme.len() + 3;
+    let key_end = s[start..end]
+        .rmatch_indices(':')
+        .map(|(i, _)| start + i)
+        .find(|&i| !s[i + 1..].starts_with("//"))
+        .unwrap_or(end);
+    *input = &s[key_end..];
+    Some(&s[..key_end])
+}
+
 #[inline]
-fn take_key_until_delim_fast<'i>(input: &mut &'i str) -> Option<&'i str> {
+fn take_key_until_delim_fast<'i>(input: &mut &'i str, stop_at_equals: bool) -> Option<&'i str> {
     let s = *input;
     if s.is_empty() {
         return Some("");
@@ -290,6 +672,10 @@ fn take_key_until_delim_fast<'i>(input: &mut &'i str) -> Option<&'i str> {
         match b[i] {
             b' ' | b'\t' | b'\n' | b'\r' | b',' | b'{' | b'}' | b'[' | b']' | b'(' | b')'
             | b':' | b'"' | b'\'' => break,
+            b'=' if stop_at_equals => break,
+            b'-' if stop_at_equals && b.get(i + 1) == Some(&b'>') => break,
+            // Corner case: an escaped quote inside a bare key (`{a\"b: 1}`) is part of the key.
+            b'\\' if matches!(b.get(i + 1), Some(b'"' | b'\'')) => i += 2,
             b'/' => {
                 if i + 1 < b.len() && (b[i + 1] == b'/' || b[i + 1] == b'*') {
                     break;
@@ -308,27 +694,16 @@ fn take_key_until_delim_fast<'i>(input: &mut &'i str) -> Option<&'i str> {
 // consume through '}' and return it. Otherwise, return None without consuming.
 #[inline]
 fn fast_ws_to_only_rbrace(input: &mut &str) -> Option<char> {
-    let s = *input;
-    if s.is_empty() {
-        return None;
-    }
-    let bytes = s.as_bytes();
-    if let Some(pos) = memchr2(b',', b'}', bytes) {
-        // If we found a comma before '}', not an immediate close
-        if bytes[pos] == b',' {
-            return None;
+    let bytes = input.as_bytes();
+    let pos = bytes
+        .iter()
+        .position(|b| !matches!(b, b' ' | b'\t' | b'\n' | b'\r'))?;
+    match bytes[pos] {
+        b'}' => {
+            *input = &input[pos + 1..];
+            Some('}')
         }
-        // Ensure all bytes before '}' are ASCII whitespace only
-        for &b in &bytes[..pos] {
-            match b {
-                b' ' | b'\t' | b'\n' | b'\r' => {}
-                _ => return None,
-            }
-        }
-        *input = &s[pos + 1..];
-        Some('}')
-    } else {
-        None
+        _ => None,
     }
 }
 
@@ -336,23 +711,15 @@ fn fast_ws_to_only_rbrace(input: &mut &str) -> Option<char> {
 // consume through the delimiter and return it. Otherwise, return None.
 #[inline]
 fn fast_ws_to_comma_or_rbrace(input: &mut &str) -> Option<char> {
-    let s = *input;
-    if s.is_empty() {
-        return None;
-    }
-    let bytes = s.as_bytes();
-    if let Some(pos) = memchr2(b',', b'}', bytes) {
-        // Ensure the prefix is only ASCII whitespace
-        for &b in &bytes[..pos] {
-            match b {
-                b' ' | b'\t' | b'\n' | b'\r' => {}
-                _ => return None,
-            }
+    let bytes = input.as_bytes();
+    let pos = bytes
+        .iter()
+        .position(|b| !matches!(b, b' ' | b'\t' | b'\n' | b'\r'))?;
+    match bytes[pos] {
+        delim @ (b',' | b'}') => {
+            *input = &input[pos + 1..];
+            Some(delim as char)
         }
-        let delim = bytes[pos] as char;
-        *input = &s[pos + 1..];
-        Some(delim)
-    } else {
-        None
+        _ => None,
     }
 }
@@ -3,6 +3,7 @@
 
 use super::lex::skip_ws_and_comments;
 use crate::emit::{Emitter, JRResult};
+use crate::options::{LineContinuation, Options};
 
 /// Parse string literal with optional concatenations or embedded ident-quote form.
 /// 🚀 Optimized: use fast byte-level scanning to check for concatenation.
@@ -12,6 +13,7 @@ pub fn parse_string_literal_concat_fast<E: Emitter>(
     input: &mut &str,
     opts: &crate::options::Options,
     out: &mut E,
+    logger: &mut crate::parser::Logger,
 ) -> JRResult<()> {
     let s = *input;
 
@@ -22,17 +24,35 @@ pub fn parse_string_literal_concat_fast<E: Emitter>(
         _ => return Ok(()),
     };
 
+    if let Some((body, len)) = doubled_quote_body(s) {
+        *input = &s[len..];
+        return emit_json_string_from_lit(out, body, opts.ascii_values());
+    }
+
     // 🚀 Fast scan to find the end of the string (byte-level)
     let bytes = s.as_bytes();
     let mut i = 1usize; // skip opening quote
     let mut escape = false;
+    let mut escaped_own_quote = false;
     while i < bytes.len() {
         let b = bytes[i];
         if escape {
             escape = false;
-            i += 1;
+            // Step over the whole escaped character: `\É` escapes a multi-byte one.
+            i += s[i..].chars().next().map_or(1, char::len_utf8);
+            if b == quote && escaped_quote_closes(&s[i..]) {
+                break;
+            }
+            escaped_own_quote |= b == quote;
             continue;
         }
+        if escaped_own_quote
+            && matches!(b, b'"' | b'\'')
+            && mixed_quote_closes(&s[i + 1..], quote as char)
+        {
+            i += 1;
+            break;
+        }
         if b == b'\\' {
             escape = true;
             i += 1;
@@ -42,6 +62,9 @@ pub fn parse_string_literal_concat_fast<E: Emitter>(
             i += 1; // include closing quote
             break;
         }
+        if b == b'\n' && starts_member(&s[i + 1..], quote as char) {
+            break;
+        }
         // Handle multi-byte UTF-8 characters
         if b >= 0x80 {
             // Multi-byte character, skip it
@@ -58,7 +81,8 @@ pub fn parse_string_literal_concat_fast<E: Emitter>(
     skip_ws_and_comments(&mut look, opts);
 
     // Quick byte-level check for '+' concatenation
-    let has_concat = look.as_bytes().first() == Some(&b'+');
+    let has_concat = look.as_bytes().first() == Some(&b'+')
+        || (opts.concat_adjacent_strings && joins_previous_string(look));
 
     // Check for embedded pattern: <ident><quote> (only if no '+')
     let has_embed = if !has_concat {
@@ -98,16 +122,16 @@ pub fn parse_string_literal_concat_fast<E: Emitter>(
 
     // 🚀 Fast path - no concatenation, parse once and emit
     if !has_concat && !has_embed && !has_punct {
-        let lit = parse_one_string_literal(input)?;
-        return emit_json_string_from_lit(out, &lit, opts.ensure_ascii);
+        let lit = parse_one_string_literal(input, opts)?;
+        return emit_json_string_from_lit(out, &lit, opts.ascii_values());
     }
 
     // 🔴 Slow path - has concatenation, use temporary buffer
-    let lit = parse_one_string_literal(input)?;
+    let lit = parse_one_string_literal(input, opts)?;
     let mut acc = String::new();
     acc.push_str(&lit);
     *input = after_string;
-    finish_string_concat(input, opts, out, acc)
+    finish_string_concat(input, opts, out, logger, acc)
 }
 
 /// Helper to finish string concatenation after the first string is already parsed
@@ -115,14 +139,21 @@ fn finish_string_concat<E: Emitter>(
     input: &mut &str,
     opts: &crate::options::Options,
     out: &mut E,
+    logger: &mut crate::parser::Logger,
     mut acc: String,
 ) -> JRResult<()> {
     loop {
+        logger.tick(input.len())?;
         skip_ws_and_comments(input, opts);
         if let Some(r) = input.strip_prefix('+') {
             *input = r;
             skip_ws_and_comments(input, opts);
-            let lit2 = parse_one_string_literal(input)?;
+            let lit2 = parse_one_string_literal(input, opts)?;
+            acc.push_str(&lit2);
+            continue;
+        }
+        if opts.concat_adjacent_strings && joins_previous_string(input) {
+            let lit2 = parse_one_string_literal(input, opts)?;
             acc.push_str(&lit2);
             continue;
         }
@@ -169,31 +200,129 @@ fn finish_string_concat<E: Emitter>(
         }
         // Heuristic: punctuation-start continuation until the next quote.
         // Handles cases like: "<h3>text"?</h3>" by interpreting the stray '"' as content.
-        if let Some(first) = sref.chars().next() {
-            if matches!(first, '?' | '!' | '<' | '>' | '/' | '.') {
+        // With no quote left (`"a".`) the punctuation is not part of the string.
+        if sref.starts_with(['?', '!', '<', '>', '/', '.']) {
+            if let Some(idx) = sref.find(['"', '\'']) {
                 // Reinsert a double-quote as content (will be escaped on emit)
                 acc.push('"');
-                let s2 = sref;
-                let mut idx = 0usize;
-                while idx < s2.len() {
-                    let ch = s2[idx..].chars().next().unwrap();
-                    let l = ch.len_utf8();
-                    if ch == '"' || ch == '\'' {
-                        *input = &s2[idx + l..];
-                        break;
-                    }
-                    acc.push(ch);
-                    idx += l;
-                }
+                acc.push_str(&sref[..idx]);
+                *input = &sref[idx + 1..];
                 continue;
             }
         }
         break;
     }
-    emit_json_string_from_lit(out, &acc, opts.ensure_ascii)
+    emit_json_string_from_lit(out, &acc, opts.ascii_values())
+}
+
+/// The character and byte length of the `{1F600}` part of an ES6 `\u{1F600}` escape at
+/// the start of `s`: hex digits in braces. A value that is no character, past U+10FFFF or
+/// a surrogate (`\u{110000}`, `\u{D800}`), reads as U+FFFD.
+pub(crate) fn brace_escape(s: &str) -> Option<(char, usize)> {
+    let digits = s.strip_prefix('{')?;
+    let close = digits.find('}')?;
+    let hex = &digits[..close];
+    if hex.is_empty() || !hex.bytes().all(|b| b.is_ascii_hexdigit()) {
+        return None;
+    }
+    let c = u32::from_str_radix(hex, 16)
+        .ok()
+        .and_then(char::from_u32)
+        .unwrap_or(char::REPLACEMENT_CHARACTER);
+    Some((c, close + 2))
 }
 
-pub fn parse_one_string_literal(input: &mut &str) -> JRResult<String> {
+// A backslash before a line break continues the line: join the lines, or keep the break
+// (a CRLF break as a whole) when `cont` is `Keep`.
+fn push_line_continuation(
+    s: &str,
+    i: &mut usize,
+    ch: char,
+    cont: LineContinuation,
+    out: &mut String,
+) {
+    let crlf = ch == '\r' && s[*i..].starts_with('\n');
+    if crlf {
+        *i += 1;
+    }
+    if cont == LineContinuation::Keep {
+        out.push(ch);
+        if crlf {
+            out.push('\n');
+        }
+    }
+}
+
+// Whether `rest` (just past a `\u`) holds the hex digits of a unicode escape, for
+// `fix_backslashes`.
+fn unicode_escape_ahead(rest: &str) -> bool {
+    brace_escape(rest).is_some()
+        || rest.len() >= 4 && rest.as_bytes()[..4].iter().all(u8::is_ascii_hexdigit)
+}
+
+// Decode the `XXXX` of a `\uXXXX` escape (or the `{XXXXX}` of an ES6 one) starting at byte
+// `i` of `s` (just past the `u`) into `out`, joining a surrogate pair and dropping a lone
+// surrogate or invalid hex. A brace escape that is empty, unterminated or not hex (`\u{}`,
+// `\u{1F600`, `\u{zz}`) is kept as written.
+fn push_unicode_escape(s: &str, i: &mut usize, out: &mut String) {
+    if let Some((c, len)) = brace_escape(&s[*i..]) {
+        out.push(c);
+        *i += len;
+        return;
+    }
+    if s[*i..].starts_with('{') {
+        out.push_str("\\u");
+        return;
+    }
+    if *i + 4 <= s.len() {
+        let hex = &s[*i..*i + 4];
+        if let Ok(v) = u16::from_str_radix(hex, 16) {
+            let is_high = (0xD800..=0xDBFF).contains(&v);
+            let is_low = (0xDC00..=0xDFFF).contains(&v);
+            if !is_high && !is_low {
+                if let Some(c) = char::from_u32(v as u32) {
+                    out.push(c);
+                }
+                *i += 4;
+            } else if is_high {
+                // Try to consume a following low surrogate
+                if *i + 6 <= s.len() && s[*i + 4..].starts_with("\\u") && *i + 10 <= s.len() {
+                    let lo_hex = &s[*i + 6..*i + 10];
+                    if let Ok(lo) = u16::from_str_radix(lo_hex, 16) {
+                        if (0xDC00..=0xDFFF).contains(&lo) {
+                            let hi = v as u32 - 0xD800;
+                            let lo10 = lo as u32 - 0xDC00;
+                            let code = 0x1_0000 + ((hi << 10) | lo10);
+                            if let Some(c) = char::from_u32(code) {
+                                out.push(c);
+                            }
+                            *i += 6; // "\\u" and the low XXXX; the high XXXX is skipped below
+                        }
+                    }
+                }
+                // Fallback: skip high surrogate and emit nothing
+                *i += 4;
+            } else {
+                // Isolated low surrogate: skip
+                *i += 4;
+            }
+        } else {
+            *i += 4; // skip invalid hex
+        }
+    }
+}
+
+/// Parse one quoted string literal and return its decoded content.
+///
+/// The string ends at the first of, in order of precedence:
+/// 1. an unescaped quote of its own kind;
+/// 2. an escaped quote of its own kind followed by `,`, `}`, `]` or the end (`"C:"}`),
+///    keeping the backslash;
+/// 3. once the string has held an escaped quote of its own kind, a quote of the other kind
+///    followed by `,`, `}`, `]` or the end, when no quote of its own kind closes it later
+///    (`{"a": "he said \"hi'}` reads as `he said "hi`), as left by concatenated templates;
+/// 4. a raw newline before the next member, or a delimiter when the string never closes.
+pub fn parse_one_string_literal(input: &mut &str, opts: &Options) -> JRResult<String> {
     let s = *input;
     let mut it = s.char_indices();
     let (start_i, quote) = match it.next() {
@@ -205,6 +334,8 @@ pub fn parse_one_string_literal(input: &mut &str) -> JRResult<String> {
     let mut out = String::new();
     let _bytes = s.as_bytes();
     let mut escape = false;
+    let mut closes = None;
+    let mut escaped_own_quote = false;
     while i < s.len() {
         let ch = s[i..].chars().next().unwrap();
         let l = ch.len_utf8();
@@ -213,54 +344,32 @@ pub fn parse_one_string_literal(input: &mut &str) -> JRResult<String> {
             escape = false;
             match ch {
                 '\\' => out.push('\\'),
-                '"' => out.push('"'),
-                '\'' => out.push('\''),
+                c if c == quote && escaped_quote_closes(&s[i..]) => {
+                    // Lone trailing backslash (`"C:\"}`): keep it and close the string.
+                    out.push('\\');
+                    *input = &s[i..];
+                    return Ok(out);
+                }
+                '"' | '\'' => {
+                    escaped_own_quote |= ch == quote;
+                    out.push(ch);
+                }
+                '/' => out.push('/'),
                 'n' => out.push('\n'),
                 'r' => out.push('\r'),
                 't' => out.push('\t'),
                 'b' => out.push('\u{0008}'),
                 'f' => out.push('\u{000C}'),
-                'u' => {
-                    // \uXXXX (handle surrogate pairs)
-                    if i + 4 <= s.len() {
-                        let hex = &s[i..i + 4];
-                        if let Ok(v) = u16::from_str_radix(hex, 16) {
-                            let is_high = (0xD800..=0xDBFF).contains(&v);
-                            let is_low = (0xDC00..=0xDFFF).contains(&v);
-                            if !is_high && !is_low {
-                                if let Some(c) = char::from_u32(v as u32) {
-                                    out.push(c);
-                                }
-                                i += 4;
-                            } else if is_high {
-                                // Try to consume a following low surrogate
-                                if i + 6 <= s.len()
-                                    && s[i + 4..].starts_with("\\u")
-                                    && i + 10 <= s.len()
-                                {
-                                    let lo_hex = &s[i + 6..i + 10];
-                                    if let Ok(lo) = u16::from_str_radix(lo_hex, 16) {
-                                        if (0xDC00..=0xDFFF).contains(&lo) {
-                                            let hi = v as u32 - 0xD800;
-                                            let lo10 = lo as u32 - 0xDC00;
-                                            let code = 0x1_0000 + ((hi << 10) | lo10);
-                                            if let Some(c) = char::from_u32(code) {
-                                                out.push(c);
-                                            }
-                                            i += 10; // consumed XXXX, "\\u", and next XXXX
-                                        }
-                                    }
-                                }
-                                // Fallback: skip high surrogate and emit nothing
-                                i += 4;
-                            } else {
-                                // Isolated low surrogate: skip
-                                i += 4;
-                            }
-                        } else {
-                            i += 4; // skip invalid hex
-                        }
-                    }
+                'u' if opts.fix_backslashes && !unicode_escape_ahead(&s[i..]) => {
+                    out.push_str("\\u")
+                }
+                'u' => push_unicode_escape(s, &mut i, &mut out),
+                '\n' | '\r' => {
+                    push_line_continuation(s, &mut i, ch, opts.line_continuations, &mut out)
+                }
+                _ if opts.fix_backslashes => {
+                    out.push('\\');
+                    out.push(ch);
                 }
                 _ => out.push(ch),
             }
@@ -287,8 +396,24 @@ pub fn parse_one_string_literal(input: &mut &str) -> JRResult<String> {
             *input = &s[i..];
             return Ok(out);
         }
-        // Heuristic: best-effort close on delimiters for unclosed strings inside containers
-        if ch == ']' || ch == '}' || ch == ',' {
+        if escaped_own_quote && matches!(ch, '"' | '\'') && mixed_quote_closes(&s[i..], quote) {
+            *input = &s[i..];
+            return Ok(out);
+        }
+        if ch == '\n' && starts_member(&s[i..], quote) {
+            // the closing quote is missing at the end of the line; leave the newline
+            if out.ends_with('\r') {
+                out.pop();
+            }
+            *input = &s[i - l..];
+            return Ok(out);
+        }
+        // Heuristic: best-effort close on delimiters for unclosed strings inside containers.
+        // A delimiter is content when the string plainly closes later (`'b,c', 'd'`).
+        // The answer is the same for every delimiter before that quote, so it is computed once.
+        if (ch == ']' || ch == '}' || ch == ',')
+            && !*closes.get_or_insert_with(|| closes_later(&s[i..], quote))
+        {
             // step back to let the container parser handle the delimiter
             *input = &s[i - l..];
             return Ok(out);
@@ -300,8 +425,118 @@ pub fn parse_one_string_literal(input: &mut &str) -> JRResult<String> {
     Ok(out)
 }
 
+/// Bytes `closes_later` scans for the quote that would close a string before giving up.
+const STRING_CLOSE_LOOKAHEAD: usize = 64 * 1024;
+
+// Whether the next unescaped `quote` in `rest` looks like this string's closing quote: it is
+// followed (after whitespace) by `,`, `}`, `]` or the end of input. In `"b, "c": 1}` the next
+// quote opens a key instead, so the string is unclosed and ends at the comma. Only the first
+// `STRING_CLOSE_LOOKAHEAD` bytes are searched; a quote further away does not count.
+fn closes_later(rest: &str, quote: char) -> bool {
+    let mut chars = rest.char_indices();
+    while let Some((i, ch)) = chars.next() {
+        if i >= STRING_CLOSE_LOOKAHEAD {
+            return false;
+        }
+        if ch == '\\' {
+            chars.next();
+        } else if ch == quote {
+            return matches!(
+                rest[i + 1..].trim_start().as_bytes().first(),
+                None | Some(b',') | Some(b'}') | Some(b']')
+            );
+        }
+    }
+    false
+}
+
+// Whether a quote of the other kind, before `rest`, closes a string opened with `quote`
+// (`"he said \"hi'}`): it is followed by a delimiter and no `quote` closes the string later.
+pub(crate) fn mixed_quote_closes(rest: &str, quote: char) -> bool {
+    escaped_quote_closes(rest) && !closes_later(rest, quote)
+}
+
+// Whether the line after a raw newline inside a string opens the next object member
+// (`"b": 1`). The string then lacked its closing quote at the end of the previous line, as in
+// `{"a": "foo\n  "b": 1}`; a quote on the next line that closes the string (`"foo\n"}`) is
+// left alone.
+pub(crate) fn starts_member(rest: &str, quote: char) -> bool {
+    let Some(key) = rest.trim_start().strip_prefix(quote) else {
+        return false;
+    };
+    match key.find([quote, '\n']) {
+        Some(end) if end > 0 && key[end..].starts_with(quote) => key[end + quote.len_utf8()..]
+            .trim_start_matches([' ', '\t'])
+            .starts_with(':'),
+        _ => false,
+    }
+}
+
+/// For `concat_adjacent_strings`: whether `rest` starts with a string literal that joins the
+/// string before it. It must not be the next object key, i.e. its closing quote is not
+/// followed (after whitespace) by `:`.
+pub(crate) fn joins_previous_string(rest: &str) -> bool {
+    let bytes = rest.as_bytes();
+    let quote = match bytes.first() {
+        Some(&q @ (b'"' | b'\'')) => q,
+        _ => return false,
+    };
+    let mut i = 1;
+    while i < bytes.len() && bytes[i] != quote {
+        i += if bytes[i] == b'\\' { 2 } else { 1 };
+    }
+    !rest
+        .get(i + 1..)
+        .unwrap_or("")
+        .trim_start()
+        .starts_with(':')
+}
+
+/// A string whose quotes were all doubled (`""a""`, a copy-paste artifact) reads as `"a"`.
+/// Returns the body and the byte length of the whole literal.
+///
+/// A real empty string is never followed directly by content, so this only fires when the
+/// opening pair is followed by a character that is not a quote, whitespace or delimiter,
+/// the body runs to the next pair of quotes without another quote, backslash or line
+/// break, and that pair is followed (after whitespace) by `:`, `,`, `}`, `]` or the end of
+/// input. `{"": 1}`, `["",""]` and `"" "x"` keep their empty strings.
+pub(crate) fn doubled_quote_body(s: &str) -> Option<(&str, usize)> {
+    let quote = match s.as_bytes() {
+        [q @ (b'"' | b'\''), q2, ..] if q == q2 => *q,
+        _ => return None,
+    };
+    let rest = &s[2..];
+    let end = rest.bytes().position(|b| b == quote)?;
+    let body = &rest[..end];
+    let first = body.chars().next()?;
+    if first.is_whitespace()
+        || matches!(first, ',' | ':' | '}' | ']')
+        || body.contains(['\\', '\n', '\r'])
+        || rest.as_bytes().get(end + 1) != Some(&quote)
+    {
+        return None;
+    }
+    let after = &rest[end + 2..];
+    matches!(
+        after.trim_start().as_bytes().first(),
+        None | Some(b':' | b',' | b'}' | b']')
+    )
+    .then_some((body, end + 4))
+}
+
+/// Whether a quote escaped by a backslash really closes the string: it is followed (after
+/// whitespace) by `,`, `}`, `]` or the end of input. A value such as `"C:\"` ends in a
+/// lone backslash that would otherwise swallow its closing quote; the backslash is kept as a
+/// literal (`"C:\\"`) so Windows paths keep their trailing separator.
+pub(crate) fn escaped_quote_closes(rest: &str) -> bool {
+    matches!(
+        rest.trim_start().as_bytes().first(),
+        None | Some(b',') | Some(b'}') | Some(b']')
+    )
+}
+
 // Strict variant for object keys: stop at the first matching closing quote.
-pub fn parse_one_string_key_strict(input: &mut &str) -> JRResult<String> {
+pub fn parse_one_string_key_strict(input: &mut &str, opts: &Options) -> JRResult<String> {
     let s = *input;
     let mut it = s.char_indices();
     let (start_i, quote) = match it.next() {
@@ -318,7 +553,26 @@ pub fn parse_one_string_key_strict(input: &mut &str) -> JRResult<String> {
         i += l;
         if escape {
             escape = false;
-            out.push(ch);
+            match ch {
+                '\\' | '"' | '\'' | '/' => out.push(ch),
+                'n' => out.push('\n'),
+                'r' => out.push('\r'),
+                't' => out.push('\t'),
+                'b' => out.push('\u{0008}'),
+                'f' => out.push('\u{000C}'),
+                'u' if opts.fix_backslashes && !unicode_escape_ahead(&s[i..]) => {
+                    out.push_str("\\u")
+                }
+                'u' => push_unicode_escape(s, &mut i, &mut out),
+                '\n' | '\r' => {
+                    push_line_continuation(s, &mut i, ch, opts.line_continuations, &mut out)
+                }
+                _ if opts.fix_backslashes => {
+                    out.push('\\');
+                    out.push(ch);
+                }
+                _ => out.push(ch),
+            }
             continue;
         }
         if ch == '\\' {
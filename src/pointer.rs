@@ -0,0 +1,468 @@
+//! JSON Pointer (RFC 6901) lookup over repaired output.
+//!
+//! The repaired text is already valid JSON, so the target is located by scanning it and
+//! skipping sibling values byte-wise; nothing is deserialized or serialized again.
+//! `PathEmitter` does the same scan on the output as the parser produces it, so output off
+//! the path is never stored and the parse can end once the target is complete.
+
+use crate::emit::{Emitter, JRResult};
+use crate::error::{RepairError, RepairErrorKind};
+
+/// Split `pointer` into unescaped reference tokens (`~1` → `/`, `~0` → `~`).
+/// The empty pointer refers to the whole document.
+pub(crate) fn parse(pointer: &str) -> Result<Vec<String>, RepairError> {
+    if pointer.is_empty() {
+        return Ok(Vec::new());
+    }
+    let Some(rest) = pointer.strip_prefix('/') else {
+        return Err(invalid("JSON Pointer must be empty or start with '/'", 0));
+    };
+    let mut tokens = Vec::new();
+    let mut offset = 1;
+    for raw in rest.split('/') {
+        let mut token = String::with_capacity(raw.len());
+        let mut chars = raw.char_indices();
+        while let Some((i, c)) = chars.next() {
+            if c != '~' {
+                token.push(c);
+                continue;
+            }
+            match chars.next() {
+                Some((_, '0')) => token.push('~'),
+                Some((_, '1')) => token.push('/'),
+                _ => return Err(invalid("invalid '~' escape in JSON Pointer", offset + i)),
+            }
+        }
+        tokens.push(token);
+        offset += raw.len() + 1;
+    }
+    Ok(tokens)
+}
+
+/// Return the slice of `json` (valid JSON) that `tokens` refers to, or `None` if a token
+/// names a missing member or index.
+pub(crate) fn find<'a>(json: &'a str, tokens: &[String]) -> Option<&'a str> {
+    let b = json.as_bytes();
+    let mut i = skip_ws(b, 0);
+    for token in tokens {
+        i = match b.get(i)? {
+            b'{' => find_member(json, i, token)?,
+            b'[' => find_element(b, i, token)?,
+            _ => return None,
+        };
+    }
+    let end = skip_value(b, i);
+    Some(&json[i..end])
+}
+
+fn find_member(json: &str, open: usize, token: &str) -> Option<usize> {
+    let b = json.as_bytes();
+    let mut i = skip_ws(b, open + 1);
+    while b.get(i) == Some(&b'"') {
+        let key_end = skip_value(b, i);
+        let key = &json[i + 1..key_end - 1];
+        let matches = if key.contains('\\') {
+            crate::strict::decode_string(&json[i..key_end]).is_some_and(|k| k == token)
+        } else {
+            key == token
+        };
+        // Skip ':' to the value.
+        let value = skip_ws(b, skip_ws(b, key_end) + 1);
+        if matches {
+            return Some(value);
+        }
+        i = skip_ws(b, skip_value(b, value));
+        if b.get(i) != Some(&b',') {
+            return None;
+        }
+        i = skip_ws(b, i + 1);
+    }
+    None
+}
+
+fn find_element(b: &[u8], open: usize, token: &str) -> Option<usize> {
+    // RFC 6901 array indices: decimal digits without leading zeros.
+    if token.is_empty()
+        || !token.bytes().all(|c| c.is_ascii_digit())
+        || (token.len() > 1 && token.starts_with('0'))
+    {
+        return None;
+    }
+    let index: usize = token.parse().ok()?;
+    let mut i = skip_ws(b, open + 1);
+    if b.get(i) == Some(&b']') {
+        return None;
+    }
+    for _ in 0..index {
+        i = skip_ws(b, skip_value(b, i));
+        if b.get(i) != Some(&b',') {
+            return None;
+        }
+        i = skip_ws(b, i + 1);
+    }
+    Some(i)
+}
+
+/// End offset (exclusive) of the value starting at `i`.
+pub(crate) fn skip_value(b: &[u8], mut i: usize) -> usize {
+    let mut depth = 0usize;
+    let mut in_str = false;
+    let mut esc = false;
+    while i < b.len() {
+        let c = b[i];
+        i += 1;
+        if in_str {
+            if esc {
+                esc = false;
+            } else if c == b'\\' {
+                esc = true;
+            } else if c == b'"' {
+                in_str = false;
+                if depth == 0 {
+                    return i;
+                }
+            }
+            continue;
+        }
+        match c {
+            b'"' => in_str = true,
+            b'{' | b'[' => depth += 1,
+            b'}' | b']' => {
+                if depth == 0 {
+                    return i - 1;
+                }
+                depth -= 1;
+                if depth == 0 {
+                    return i;
+                }
+            }
+            b',' | b' ' | b'\t' | b'\n' | b'\r' if depth == 0 => return i - 1,
+            _ => {}
+        }
+    }
+    i
+}
+
+pub(crate) fn skip_ws(b: &[u8], mut i: usize) -> usize {
+    while matches!(b.get(i), Some(b' ' | b'\t' | b'\n' | b'\r')) {
+        i += 1;
+    }
+    i
+}
+
+/// An `Emitter` that keeps only the value a pointer refers to. Output off the pointer path is
+/// scanned for its structure and dropped. Once the target is complete, or a container on the
+/// path closes without it, `emit_str` fails so that the parse ends there; `result` tells that
+/// stop from a real error. Dropped output cannot be rewound over, so `salvage` must be off.
+pub(crate) struct PathEmitter<'t> {
+    tokens: &'t [String],
+    /// Containers open in the output.
+    depth: usize,
+    /// The open containers that lie on the path, outermost first.
+    path: Vec<Level>,
+    in_string: bool,
+    escaped: bool,
+    /// Raw text of an object key on the path, while it is being read.
+    key: Option<String>,
+    /// `depth` where the target starts, once it has been reached.
+    target: Option<usize>,
+    /// The target is a number or literal, which the next delimiter ends.
+    scalar: bool,
+    value: String,
+    state: State,
+    emitted: usize,
+}
+
+// A container on the pointer path.
+struct Level {
+    object: bool,
+    /// Object: a key comes next.
+    key: bool,
+    /// Array: index of the current element.
+    index: usize,
+    /// Array: the index the pointer names, if the token is one.
+    want: Option<usize>,
+    /// The current member or element is the next one on the path.
+    hit: bool,
+}
+
+#[derive(Clone, Copy, PartialEq)]
+enum State {
+    Open,
+    Found,
+    Missing,
+}
+
+impl<'t> PathEmitter<'t> {
+    pub(crate) fn new(tokens: &'t [String]) -> Self {
+        Self {
+            tokens,
+            depth: 0,
+            path: Vec::new(),
+            in_string: false,
+            escaped: false,
+            key: None,
+            target: None,
+            scalar: false,
+            value: String::new(),
+            state: State::Open,
+            emitted: 0,
+        }
+    }
+
+    /// Whether the emitter stopped the parse: the target is complete or known to be missing.
+    pub(crate) fn stopped(&self) -> bool {
+        self.state != State::Open
+    }
+
+    /// The target, or `None` if the output did not have it.
+    pub(crate) fn result(self) -> Option<String> {
+        match self.state {
+            State::Found => Some(self.value),
+            // A number or literal at the very end of the output.
+            State::Open if self.scalar => Some(self.value),
+            _ => None,
+        }
+    }
+
+    // The next value starts at the level of the innermost path container and is on the path.
+    fn on_path(&self) -> bool {
+        self.depth == self.path.len() && self.path.last().is_none_or(|l| l.hit)
+    }
+
+    fn stop(&mut self, state: State) -> RepairError {
+        self.state = state;
+        RepairError::new(
+            RepairErrorKind::Parse("pointer target complete".to_string()),
+            0,
+        )
+    }
+
+    fn found(&mut self, tail: &str) -> JRResult<()> {
+        self.value.push_str(tail);
+        Err(self.stop(State::Found))
+    }
+
+    // A string, number or literal on the path: the target, or a dead end when the path goes
+    // on past it.
+    fn reach(&mut self) -> JRResult<()> {
+        if self.path.len() < self.tokens.len() {
+            return Err(self.stop(State::Missing));
+        }
+        self.target = Some(self.depth);
+        Ok(())
+    }
+
+    // A path container opens; its members are matched against the next token.
+    fn descend(&mut self, object: bool) {
+        let token = &self.tokens[self.path.len()];
+        let want = if token.bytes().all(|c| c.is_ascii_digit())
+            && (token.len() == 1 || !token.starts_with('0'))
+        {
+            token.parse().ok()
+        } else {
+            None
+        };
+        self.path.push(Level {
+            object,
+            key: object,
+            index: 0,
+            want,
+            hit: !object && want == Some(0),
+        });
+    }
+
+    fn read_key(&mut self, raw: &str) {
+        let token = &self.tokens[self.path.len() - 1];
+        let hit = if raw.contains('\\') {
+            crate::strict::decode_string(raw).is_some_and(|k| k == *token)
+        } else {
+            raw[1..raw.len() - 1] == **token
+        };
+        if let Some(level) = self.path.last_mut() {
+            level.hit = hit;
+        }
+    }
+}
+
+impl PathEmitter<'_> {
+    // Most output lies inside values off the path, where only strings and brackets count.
+    // Returns where the value ended, if it did within `b`.
+    #[inline]
+    fn skip(&mut self, b: &[u8]) -> Option<usize> {
+        let mut i = 0;
+        if self.in_string {
+            i = self.string_end(b, 0)?;
+        }
+        while i < b.len() {
+            match b[i] {
+                b'"' => {
+                    self.in_string = true;
+                    i = self.string_end(b, i + 1)?;
+                    continue;
+                }
+                b'{' | b'[' => self.depth += 1,
+                b'}' | b']' => {
+                    self.depth -= 1;
+                    if self.depth == self.path.len() {
+                        return Some(i + 1);
+                    }
+                }
+                _ => {}
+            }
+            i += 1;
+        }
+        None
+    }
+
+    // Past the closing quote of the string `b[i..]` is inside, or `None` if `b` ends first.
+    #[inline]
+    fn string_end(&mut self, b: &[u8], mut i: usize) -> Option<usize> {
+        if self.escaped {
+            self.escaped = false;
+            i += 1;
+        }
+        while i < b.len() {
+            match b[i] {
+                b'\\' => i += 2,
+                b'"' => {
+                    self.in_string = false;
+                    return Some(i + 1);
+                }
+                _ => i += 1,
+            }
+        }
+        self.escaped = i > b.len();
+        None
+    }
+
+    fn scan(&mut self, s: &str) -> JRResult<()> {
+        if self.stopped() {
+            // The parser may try another reading after a failed one; it has to stop as well.
+            return Err(self.stop(self.state));
+        }
+        // Where the part of `s` that belongs to the target, or to a key, starts.
+        let mut copy_from = self.target.map(|_| 0);
+        let mut key_from = self.key.as_ref().map(|_| 0);
+        for (i, c) in s.bytes().enumerate() {
+            if self.in_string {
+                if self.escaped {
+                    self.escaped = false;
+                } else if c == b'\\' {
+                    self.escaped = true;
+                } else if c == b'"' {
+                    self.in_string = false;
+                    if let Some(from) = key_from.take() {
+                        let mut key = self.key.take().unwrap_or_default();
+                        key.push_str(&s[from..=i]);
+                        self.read_key(&key);
+                    } else if self.target == Some(self.depth) {
+                        return self.found(&s[copy_from.unwrap_or(0)..=i]);
+                    }
+                }
+                continue;
+            }
+            if self.scalar && matches!(c, b',' | b'}' | b']' | b' ' | b'\t' | b'\n' | b'\r') {
+                return self.found(&s[copy_from.unwrap_or(0)..i]);
+            }
+            if self.target.is_some() {
+                match c {
+                    b'"' => self.in_string = true,
+                    b'{' | b'[' => self.depth += 1,
+                    b'}' | b']' => {
+                        self.depth -= 1;
+                        if self.target == Some(self.depth) {
+                            return self.found(&s[copy_from.unwrap_or(0)..=i]);
+                        }
+                    }
+                    _ => {}
+                }
+                continue;
+            }
+            let at_path = self.depth == self.path.len();
+            match c {
+                b'"' => {
+                    self.in_string = true;
+                    if at_path && self.path.last().is_some_and(|l| l.object && l.key) {
+                        self.key = Some(String::new());
+                        key_from = Some(i);
+                    } else if self.on_path() {
+                        self.reach()?;
+                        copy_from = Some(i);
+                    }
+                }
+                b'{' | b'[' => {
+                    let on_path = self.on_path();
+                    if on_path && self.path.len() == self.tokens.len() {
+                        self.target = Some(self.depth);
+                        copy_from = Some(i);
+                    }
+                    self.depth += 1;
+                    if on_path && self.target.is_none() {
+                        self.descend(c == b'{');
+                    }
+                }
+                b'}' | b']' => {
+                    self.depth = self.depth.saturating_sub(1);
+                    if self.depth < self.path.len() {
+                        return Err(self.stop(State::Missing));
+                    }
+                }
+                b',' if at_path => {
+                    if let Some(level) = self.path.last_mut() {
+                        if level.object {
+                            level.key = true;
+                            level.hit = false;
+                        } else {
+                            level.index += 1;
+                            level.hit = level.want == Some(level.index);
+                        }
+                    }
+                }
+                b':' if at_path => {
+                    if let Some(level) = self.path.last_mut() {
+                        level.key = false;
+                    }
+                }
+                b',' | b':' | b' ' | b'\t' | b'\n' | b'\r' => {}
+                _ if self.on_path() => {
+                    self.reach()?;
+                    self.scalar = true;
+                    copy_from = Some(i);
+                }
+                _ => {}
+            }
+        }
+        if let Some(from) = copy_from {
+            self.value.push_str(&s[from..]);
+        }
+        if let (Some(from), Some(key)) = (key_from, self.key.as_mut()) {
+            key.push_str(&s[from..]);
+        }
+        Ok(())
+    }
+}
+
+impl Emitter for PathEmitter<'_> {
+    #[inline]
+    fn emit_str(&mut self, s: &str) -> JRResult<()> {
+        self.emitted += s.len();
+        if self.depth > self.path.len() && self.target.is_none() {
+            return match self.skip(s.as_bytes()) {
+                Some(end) => self.scan(&s[end..]),
+                None => Ok(()),
+            };
+        }
+        self.scan(s)
+    }
+    fn mark(&self) -> usize {
+        self.emitted
+    }
+    fn rewind(&mut self, _mark: usize) {
+        unreachable!("PathEmitter is not used with salvage")
+    }
+}
+
+fn invalid(msg: &str, position: usize) -> RepairError {
+    RepairError::new(RepairErrorKind::Parse(msg.to_string()), position)
+}
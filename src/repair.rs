@@ -1,7 +1,11 @@
-#[cfg(feature = "logging")]
 use crate::emit::StringEmitter;
-use crate::error::RepairError;
-use crate::options::{EngineKind, Options};
+use crate::error::{RepairError, RepairErrorKind};
+use crate::options::{
+    BracketKind, CompactSpacing, DedupPosition, EngineKind, ForceContainer, NumberFormat, Options,
+    OutputFormat, SalvagePolicy,
+};
+use crate::srcmap::{Rewrite, SourceMap, StepMap};
+use std::borrow::Cow;
 use std::io::Write;
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -12,6 +16,74 @@ pub struct RepairLogEntry {
     pub path: Option<String>,
 }
 
+/// JSON type of the root value of a repaired document.
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum ValueKind {
+    Object,
+    Array,
+    String,
+    Number,
+    Boolean,
+    Null,
+    /// The output is empty: the input held no value, only whitespace or comments.
+    Empty,
+}
+
+/// The kind of the root value of repaired output `out`, read from its first byte after any
+/// BOM or indentation (values are always complete by then).
+pub(crate) fn value_kind(out: &str) -> ValueKind {
+    let body = out.trim_start_matches(|c: char| c == '\u{FEFF}' || c.is_ascii_whitespace());
+    match body.bytes().next() {
+        None => ValueKind::Empty,
+        Some(b'{') => ValueKind::Object,
+        Some(b'[') => ValueKind::Array,
+        Some(b'"' | b'\'') => ValueKind::String,
+        Some(b't' | b'f') => ValueKind::Boolean,
+        Some(b'n') => ValueKind::Null,
+        Some(_) => ValueKind::Number,
+    }
+}
+
+// Every message a `RepairLogEntry` can carry, grouped by the part of the parser that logs it.
+// `Logger::log` asserts membership in debug builds so the list cannot drift.
+pub(crate) const REPAIR_CATEGORIES: &[&str] = &[
+    "closed unterminated object",
+    "closed unterminated array",
+    "closed object at mismatched bracket",
+    "closed array at mismatched brace",
+    "closed object at crossed bracket",
+    "closed array at crossed brace",
+    "dropped doubled brace",
+    "read bracketed members as object",
+    "read braced values as array",
+    "wrapped nested key in object",
+    "inserted missing comma",
+    "inserted missing colon",
+    "inserted missing value",
+    "dropped key without value",
+    "dropped stray token",
+    "quoted unquoted key",
+    "converted single-quoted key",
+    "unwrapped computed key",
+    "truncated long key",
+    "replaced '=' separator with colon",
+    "replaced '->' separator with colon",
+    "replaced ':' separator with comma",
+    "read comma as decimal separator",
+    "evaluated integer fraction",
+    "quoted bare string",
+    "converted single-quoted string",
+    "collapsed doubled quotes",
+    "normalized python keyword",
+    "normalized keyword case",
+    "expanded abbreviated keyword",
+    "mapped null token",
+    "normalized non-finite number",
+    "replaced undefined with null",
+    "unwrapped typed wrapper",
+    "salvaged unrepairable value",
+];
+
 // Route to the selected engine at runtime (default: recursive-descent).
 // When the `llm-compat` feature is not compiled, always fall back to recursive-descent.
 #[inline]
@@ -54,8 +126,1520 @@ fn engine_repair_to_writer<W: Write>(
     }
 }
 
+// Input guards that run before any repair work is done.
+#[inline]
+fn guard_input(input: &str, opts: &Options) -> Result<(), RepairError> {
+    guard_size(input, opts)?;
+    if opts.reject_if_invalid {
+        crate::strict::validate(input)?;
+    }
+    Ok(())
+}
+
+// Option combinations with no output to give, checked by the entry points before anything
+// else. Source annotations are comments, which strict JSON output has no room for.
+fn guard_options(opts: &Options) -> Result<(), RepairError> {
+    if opts.annotate_source && opts.output_format == OutputFormat::Json {
+        return Err(RepairError::new(
+            RepairErrorKind::Parse("annotate_source requires output_format = Json5".to_string()),
+            0,
+        ));
+    }
+    Ok(())
+}
+
+// `Options::max_input_bytes`: part of `guard_input`, and checked on its own by the entry
+// points that split the input before repairing the pieces.
+#[inline]
+fn guard_size(input: &str, opts: &Options) -> Result<(), RepairError> {
+    let max = opts.max_input_bytes;
+    if max > 0 && input.len() > max {
+        return Err(RepairError::new(RepairErrorKind::InputTooLarge(max), max));
+    }
+    Ok(())
+}
+
+/// Maximum number of string-escaping layers removed by `Options::unwrap_escaped_json`.
+pub(crate) const UNWRAP_ESCAPED_MAX_DEPTH: usize = 8;
+
+// Replace a top-level JSON string whose content is itself JSON with that content, repeatedly.
+// Only strictly valid objects, arrays or strings are unwrapped, so plain text stays a string.
+fn unwrap_escaped(mut out: String, opts: &Options) -> Result<String, RepairError> {
+    for _ in 0..UNWRAP_ESCAPED_MAX_DEPTH {
+        let Some(inner) = crate::strict::decode_string(&out) else {
+            break;
+        };
+        let body = inner.trim_matches([' ', '\t', '\n', '\r']);
+        if !body.starts_with(['{', '[', '"']) || crate::strict::validate(body).is_err() {
+            break;
+        }
+        let repaired = engine_repair_to_string(body, opts)?;
+        // Valid JSON is copied through with its spacing; the unwrapped value is written out
+        // compactly, as a single-quoted document is.
+        out = crate::strict::minify(&repaired).unwrap_or(repaired);
+    }
+    Ok(out)
+}
+
+// `unwrap_escaped_json` for values: a string value holding an object or array that was
+// JSON-encoded more than once (`{"payload": "{\\"k\\": 1}"}`, often with too few
+// backslashes to even be a valid string) is replaced by the decoded container. A value
+// escaped only once is ordinary stringified JSON and is kept. `None` when nothing changed.
+fn unescape_nested_values(input: &str) -> Option<(String, StepMap)> {
+    let bytes = input.as_bytes();
+    let mut out = Rewrite::new(input);
+    let mut copied = 0usize;
+    let mut prev = 0u8;
+    let mut i = 0usize;
+    while i < bytes.len() {
+        let b = bytes[i];
+        if b != b'"' {
+            if !b.is_ascii_whitespace() {
+                prev = b;
+            }
+            i += 1;
+            continue;
+        }
+        if matches!(prev, b':' | b'[' | b',')
+            && let Some((json, len)) = escaped_container(&input[i + 1..])
+        {
+            out.push_str(&input[copied..i]);
+            out.push_str(&json);
+            out.skip(&input[i..i + len + 2]);
+            i += len + 2;
+            copied = i;
+        } else {
+            i += 1;
+            while i < bytes.len() && bytes[i] != b'"' {
+                i += if bytes[i] == b'\\' { 2 } else { 1 };
+            }
+            i += 1;
+        }
+        prev = b'"';
+    }
+    if copied == 0 {
+        return None;
+    }
+    out.push_str(&input[copied..]);
+    Some(out.finish())
+}
+
+// The container encoded in the string body at the start of `s` when it takes two or more
+// layers of unescaping, up to the depth cap, to become valid JSON; with the body length,
+// which runs to the first quote not preceded by a backslash.
+fn escaped_container(s: &str) -> Option<(String, usize)> {
+    let b = s.as_bytes();
+    if !matches!(b.first(), Some(b'{' | b'[')) || !s[1..].trim_start().starts_with('\\') {
+        return None;
+    }
+    let len = (1..b.len()).find(|&j| b[j] == b'"' && b[j - 1] != b'\\')?;
+    let mut text = crate::strict::unescape(&s[..len])?;
+    if crate::strict::validate(&text).is_ok() {
+        return None;
+    }
+    for _ in 1..UNWRAP_ESCAPED_MAX_DEPTH {
+        text = crate::strict::unescape(&text)?;
+        if crate::strict::validate(&text).is_ok() {
+            return text.starts_with(['{', '[']).then_some((text, len));
+        }
+    }
+    None
+}
+
+// A whole document wrapped in single quotes (`'[1, 2]'`) carries no inner escaping, so the
+// string parser's heuristics would split it; return the content when it is a complete,
+// strictly valid object or array.
+fn single_quoted_document(input: &str) -> Option<&str> {
+    let s = input.trim_start_matches('\u{FEFF}');
+    let s = s.trim_matches([' ', '\t', '\n', '\r']);
+    let inner = s.strip_prefix('\'')?.strip_suffix('\'')?;
+    let body = inner.trim_matches([' ', '\t', '\n', '\r']);
+    if body.starts_with(['{', '[']) && crate::strict::validate(body).is_ok() {
+        Some(body)
+    } else {
+        None
+    }
+}
+
+// Newline-separated `key = value` lines (a `.env`/TOML fragment) assembled into object text for
+// the engine. Every non-blank line must be a bare or quoted key, `=`, and an optional value;
+// `#` lines are comments and a leading `export ` is dropped. A value with a bracket or quote
+// still open at the end of its line runs on over the lines that close it (see `value_end`).
+// Anything else (including TOML `[section]` headers) returns `None` and the input is
+// repaired as-is.
+fn key_value_lines(input: &str) -> Option<(String, StepMap)> {
+    let s = input.trim_start_matches('\u{FEFF}');
+    let mut obj = Rewrite::new(input);
+    obj.push('{');
+    let mut members = 0usize;
+    let mut at = 0;
+    while at < s.len() {
+        let line_end = s[at..].find('\n').map_or(s.len(), |i| at + i);
+        let line = s[at..line_end].trim();
+        at = line_end + 1;
+        if line.is_empty() || line.starts_with('#') {
+            continue;
+        }
+        let line = line.strip_prefix("export ").unwrap_or(line);
+        let (key, value) = line.split_once('=')?;
+        let (key, value) = (key.trim_end(), value.trim_start());
+        let quoted = key.len() >= 2
+            && (key.starts_with('"') && key.ends_with('"')
+                || key.starts_with('\'') && key.ends_with('\''));
+        let bare = !key.is_empty()
+            && key
+                .bytes()
+                .all(|b| b.is_ascii_alphanumeric() || matches!(b, b'_' | b'-' | b'.'));
+        if !(quoted || bare) || value.starts_with('=') {
+            return None;
+        }
+        let start = value.as_ptr() as usize - s.as_ptr() as usize;
+        let end = value_end(s, start);
+        let value = if end > line_end {
+            at = end + 1;
+            s[start..end].trim_end()
+        } else {
+            strip_inline_comment(value)
+        };
+        if members > 0 {
+            obj.push(',');
+        }
+        if bare {
+            obj.push('"');
+            obj.push_str(key);
+            obj.push('"');
+        } else {
+            obj.push_str(key);
+        }
+        obj.push(':');
+        obj.push_str(if value.is_empty() { "\"\"" } else { value });
+        members += 1;
+    }
+    if members == 0 {
+        return None;
+    }
+    obj.push('}');
+    Some(obj.finish())
+}
+
+// Where the fragment value starting at `start` in `s` ends: the newline that ends its line,
+// or a later one when a `[` or `{` is still open, or a string is, at the end of the line.
+// Quotes count at the start of the value and inside brackets, so an apostrophe in a bare
+// value does not open a string. A ` # comment` runs to the end of its line. A value still
+// open at the end of input runs to it.
+fn value_end(s: &str, start: usize) -> usize {
+    let b = s.as_bytes();
+    let mut depth = 0usize;
+    let mut quote = None;
+    let mut i = start;
+    while i < b.len() {
+        match (quote, b[i]) {
+            (Some(_), b'\\') => i += 1,
+            (Some(q), c) if c == q => quote = None,
+            (Some(_), _) => {}
+            (None, q @ (b'"' | b'\'')) if i == start || depth > 0 => quote = Some(q),
+            (None, b'[' | b'{') => depth += 1,
+            (None, b']' | b'}') => depth = depth.saturating_sub(1),
+            (None, b'#') if matches!(b[i - 1], b' ' | b'\t') => {
+                i = s[i..].find('\n').map_or(b.len(), |n| i + n);
+                continue;
+            }
+            (None, b'\n') if depth == 0 => return i,
+            _ => {}
+        }
+        i += 1;
+    }
+    b.len()
+}
+
+// Cut a ` # comment` that follows a fragment value, ignoring `#` inside quotes.
+fn strip_inline_comment(value: &str) -> &str {
+    let mut quote = None;
+    let mut prev = b' ';
+    for (i, b) in value.bytes().enumerate() {
+        match (quote, b) {
+            (None, b'"' | b'\'') => quote = Some(b),
+            (Some(q), _) if b == q && prev != b'\\' => quote = None,
+            (None, b'#') if prev == b' ' || prev == b'\t' => return value[..i].trim_end(),
+            _ => {}
+        }
+        prev = b;
+    }
+    value
+}
+
+// The input with each `(`...`)` in value position turned into `[`...`]`, for
+// `parens_as_arrays`, or None when there is none. A `(` after anything but the start of
+// input or `: , [ (` opens a call, and its `)` is kept.
+fn parens_to_brackets(input: &str, opts: &Options) -> Option<String> {
+    if !input.contains('(') {
+        return None;
+    }
+    let mut out = String::with_capacity(input.len());
+    let mut converted = false;
+    // One entry per open `(`: whether it became a `[`.
+    let mut open: Vec<bool> = Vec::new();
+    let mut prev = None;
+    let mut rest = input;
+    while let Some(c) = rest.chars().next() {
+        // A comment is copied as is and leaves `prev` alone, so `[1, /* c */ (2, 3)]` still
+        // has the `(` in value position.
+        if let Some(len) = comment_len(rest, opts) {
+            out.push_str(&rest[..len]);
+            rest = &rest[len..];
+            continue;
+        }
+        let mut len = c.len_utf8();
+        let at_value = matches!(prev, None | Some(':' | ',' | '[' | '('));
+        match c {
+            '"' | '\'' if c == '"' || at_value => {
+                len = quoted_len(rest, c as u8);
+                out.push_str(&rest[..len]);
+            }
+            '(' => {
+                open.push(at_value);
+                converted |= at_value;
+                out.push(if at_value { '[' } else { '(' });
+            }
+            ')' => out.push(if open.pop() == Some(true) { ']' } else { ')' }),
+            _ => out.push(c),
+        }
+        if !c.is_whitespace() {
+            prev = Some(c);
+        }
+        rest = &rest[len..];
+    }
+    converted.then_some(out)
+}
+
+// The input with the words of `bracket_aliases` turned into the brackets they stand for,
+// or None when there is none. Strings, comments and words used as keys are copied as they
+// are; as in `parens_to_brackets`, a `'` only opens a string in value position, so
+// `BEGIN note: it's fine END` still closes. A closing word closes the innermost open alias
+// that uses it, so two aliases can share `END`.
+fn keyword_brackets(input: &str, opts: &Options) -> Option<(String, StepMap)> {
+    let aliases = &opts.bracket_aliases;
+    if !aliases.iter().any(|(o, _, _)| input.contains(o.as_str())) {
+        return None;
+    }
+    let is_word = |c: char| c.is_alphanumeric() || c == '_' || c == '$';
+    let brackets = |i: usize| match aliases[i].2 {
+        BracketKind::Object => ('{', '}'),
+        BracketKind::Array => ('[', ']'),
+    };
+    let mut out = Rewrite::new(input);
+    let mut converted = false;
+    // One entry per open alias: the index of its pair.
+    let mut open: Vec<usize> = Vec::new();
+    let mut prev = None;
+    let mut rest = input;
+    while let Some(c) = rest.chars().next() {
+        if let Some(len) = comment_len(rest, opts) {
+            out.push_str(&rest[..len]);
+            rest = &rest[len..];
+            continue;
+        }
+        let mut len = c.len_utf8();
+        let mut last = c;
+        let at_value = matches!(prev, None | Some(':' | ',' | '[' | '{'));
+        match c {
+            '"' | '\'' if c == '"' || at_value => {
+                len = quoted_len(rest, c as u8);
+                out.push_str(&rest[..len]);
+            }
+            c if is_word(c) => {
+                len = rest.find(|c| !is_word(c)).unwrap_or(rest.len());
+                let word = &rest[..len];
+                let bracket = if rest[len..].trim_start().starts_with(':') {
+                    None
+                } else if let Some(i) = aliases.iter().position(|(o, _, _)| o == word) {
+                    open.push(i);
+                    Some(brackets(i).0)
+                } else if let Some(at) = open.iter().rposition(|&i| aliases[i].1 == word) {
+                    let i = open[at];
+                    open.truncate(at);
+                    Some(brackets(i).1)
+                } else {
+                    // A closing word with nothing of its own open, left to the parser to drop.
+                    aliases
+                        .iter()
+                        .position(|(_, c, _)| c == word)
+                        .map(|i| brackets(i).1)
+                };
+                match bracket {
+                    Some(b) => {
+                        converted = true;
+                        last = b;
+                        out.push(b);
+                        out.skip(word);
+                    }
+                    None => out.push_str(word),
+                }
+            }
+            _ => out.push_str(&rest[..len]),
+        }
+        if !c.is_whitespace() {
+            prev = Some(last);
+        }
+        rest = &rest[len..];
+    }
+    converted.then(|| out.finish())
+}
+
+// Length of the comment at the start of `rest` (`/* */`, `//`, or `#` under
+// `tolerate_hash_comments`), up to but not including a line comment's newline.
+fn comment_len(rest: &str, opts: &Options) -> Option<usize> {
+    if rest.starts_with("/*") {
+        Some(rest.find("*/").map_or(rest.len(), |i| i + 2))
+    } else if rest.starts_with("//") || opts.tolerate_hash_comments && rest.starts_with('#') {
+        Some(rest.find('\n').unwrap_or(rest.len()))
+    } else {
+        None
+    }
+}
+
+// The input with each Python set literal turned into an array, for
+// `compat_python_friendly`, or None when there is none. A `{`...`}` is a set when it holds
+// a `,` and no `:` at its own depth, so `{1, 2}` becomes `[1, 2]` while `{}`, `{a: 1}` and
+// `{a, b: 1}` (first colon later) stay objects. Strings, comments and nested brackets are
+// skipped when looking for the separators.
+fn sets_to_arrays(input: &str, opts: &Options) -> Option<String> {
+    if !input.contains('{') || !input.contains(',') {
+        return None;
+    }
+    let mut out = String::with_capacity(input.len());
+    let mut converted = false;
+    // One entry per open bracket: its closer, where a `{` was written, and whether a `,`
+    // and a `:` were seen directly inside it.
+    let mut open: Vec<(char, usize, bool, bool)> = Vec::new();
+    let mut prev = None;
+    let mut rest = input;
+    while let Some(c) = rest.chars().next() {
+        if let Some(len) = comment_len(rest, opts) {
+            out.push_str(&rest[..len]);
+            rest = &rest[len..];
+            continue;
+        }
+        let mut len = c.len_utf8();
+        let at_value = matches!(prev, None | Some(':' | ',' | '[' | '(' | '{'));
+        match c {
+            '"' | '\'' if c == '"' || at_value => {
+                len = quoted_len(rest, c as u8);
+                out.push_str(&rest[..len]);
+            }
+            '{' | '[' | '(' => {
+                let close = match c {
+                    '{' => '}',
+                    '[' => ']',
+                    _ => ')',
+                };
+                open.push((close, out.len(), false, false));
+                out.push(c);
+            }
+            ',' | ':' => {
+                if let Some(top) = open.last_mut() {
+                    if c == ',' {
+                        top.2 = true;
+                    } else {
+                        top.3 = true;
+                    }
+                }
+                out.push(c);
+            }
+            '}' | ']' | ')' => {
+                if let Some(&(close, at, comma, colon)) = open.last()
+                    && close == c
+                {
+                    open.pop();
+                    if c == '}' && comma && !colon {
+                        out.replace_range(at..at + 1, "[");
+                        out.push(']');
+                        converted = true;
+                    } else {
+                        out.push(c);
+                    }
+                } else {
+                    out.push(c);
+                }
+            }
+            _ => out.push(c),
+        }
+        if !c.is_whitespace() {
+            prev = Some(c);
+        }
+        rest = &rest[len..];
+    }
+    converted.then_some(out)
+}
+
+// The first `{...}` or `[...]` in `input`, for `extract_embedded`: from the first opener to
+// the closer that brings the bracket depth back to zero, or to the end when there is none.
+// A `'` opens a string only where a value or key can start, so prose apostrophes inside
+// the span do not.
+fn embedded_span(input: &str) -> &str {
+    let Some(start) = input.find(['{', '[']) else {
+        return input;
+    };
+    let s = &input[start..];
+    let mut depth = 0usize;
+    let mut prev = ' ';
+    let mut i = 0;
+    while let Some(c) = s[i..].chars().next() {
+        let mut len = c.len_utf8();
+        match c {
+            '"' => len = quoted_len(&s[i..], b'"'),
+            '\'' if matches!(prev, '{' | '[' | ':' | ',') => len = quoted_len(&s[i..], b'\''),
+            '{' | '[' => depth += 1,
+            '}' | ']' => {
+                depth -= 1;
+                if depth == 0 {
+                    return &s[..i + 1];
+                }
+            }
+            _ => {}
+        }
+        if !c.is_whitespace() {
+            prev = c;
+        }
+        i += len;
+    }
+    s
+}
+
+// Length of the string literal opening `s` with `quote`, through its closing quote.
+fn quoted_len(s: &str, quote: u8) -> usize {
+    let bytes = s.as_bytes();
+    let mut i = 1;
+    while i < bytes.len() {
+        match bytes[i] {
+            b'\\' => i += 2,
+            b if b == quote => return i + 1,
+            _ => i += 1,
+        }
+    }
+    bytes.len()
+}
+
+// The input wrapped in `{}` or `[]` when it is a bare container body, for
+// `add_missing_brackets`: a leading key and `:` means an object, a leading scalar with a
+// top-level `,` an array.
+fn naked_body(input: &str) -> Option<(String, StepMap)> {
+    let s = input.trim_start_matches('\u{FEFF}');
+    let body = s.trim_matches([' ', '\t', '\n', '\r']);
+    let (first, rest) = leading_token(body)?;
+    let after = rest.trim_start();
+    if after.starts_with(':') && !after.starts_with("://") {
+        return Some(enclose(input, body, '{', Some('}')));
+    }
+    let scalar = first.starts_with(['"', '\'', '-', '.'])
+        || first.starts_with(|c: char| c.is_ascii_digit())
+        || matches!(first, "true" | "false" | "null");
+    (scalar && has_top_level_comma(rest)).then(|| enclose(input, body, '[', Some(']')))
+}
+
+// `body`, a slice of `input`, after `open` and before `close`.
+fn enclose(input: &str, body: &str, open: char, close: Option<char>) -> (String, StepMap) {
+    let mut out = Rewrite::new(input);
+    out.push(open);
+    out.push_str(body);
+    if let Some(close) = close {
+        out.push(close);
+    }
+    out.finish()
+}
+
+// The input with the opener of a closing `}` or `]` that ends it but matches no bracket:
+// `"a": 1, "b": 2}` gets its `{` when it starts with a key and `:`, `1, 2, 3]` its `[` when
+// it starts with a scalar. Everything before the closer must be balanced, so narrative
+// that merely ends in a bracket is left alone. With `stop_after_first` only the first value
+// is read, so the closer may be the first unmatched one with more text after it
+// (`1, 2] 3`).
+fn unopened_body(input: &str, stop_after_first: bool) -> Option<(String, StepMap)> {
+    let s = input.trim_start_matches('\u{FEFF}');
+    let body = s.trim_matches([' ', '\t', '\n', '\r']);
+    let end = if stop_after_first {
+        unmatched_closer(body)? + 1
+    } else {
+        body.len()
+    };
+    let inner = body[..end].strip_suffix(['}', ']'])?;
+    let (first, rest) = leading_token(inner)?;
+    let after = rest.trim_start();
+    let keyed = after.starts_with(':') && !after.starts_with("://");
+    let scalar = first.starts_with(['"', '\'', '-', '.'])
+        || first.starts_with(|c: char| c.is_ascii_digit())
+        || matches!(first, "true" | "false" | "null");
+    let opener = match body.as_bytes()[end - 1] {
+        b'}' if keyed => '{',
+        b']' if scalar && !keyed => '[',
+        _ => return None,
+    };
+    is_balanced(inner).then(|| enclose(input, body, opener, None))
+}
+
+// A rewritten document that is already valid JSON would be copied through as-is, spacing
+// and all; compact it to match what the parser writes for any other repair.
+fn compact_rewrite(doc: String, map: &mut SourceMap) -> String {
+    match crate::strict::minify(&doc) {
+        Ok(out) => {
+            map.push(StepMap::deletions(&doc, &out));
+            out
+        }
+        Err(_) => doc,
+    }
+}
+
+// Offset of the first `}` or `]` in `s` outside strings that closes no bracket opened in
+// `s`.
+fn unmatched_closer(s: &str) -> Option<usize> {
+    let mut depth = 0usize;
+    let mut quote = None;
+    let mut escaped = false;
+    for (i, c) in s.char_indices() {
+        match quote {
+            Some(q) => {
+                if escaped {
+                    escaped = false;
+                } else if c == '\\' {
+                    escaped = true;
+                } else if c == q {
+                    quote = None;
+                }
+            }
+            None => match c {
+                '"' | '\'' => quote = Some(c),
+                '{' | '[' => depth += 1,
+                '}' | ']' if depth == 0 => return Some(i),
+                '}' | ']' => depth -= 1,
+                _ => {}
+            },
+        }
+    }
+    None
+}
+
+// True when every bracket in `s` outside strings is closed in order and no string is left
+// open.
+fn is_balanced(s: &str) -> bool {
+    let mut open = Vec::new();
+    let mut quote = None;
+    let mut escaped = false;
+    for c in s.chars() {
+        match quote {
+            Some(q) => {
+                if escaped {
+                    escaped = false;
+                } else if c == '\\' {
+                    escaped = true;
+                } else if c == q {
+                    quote = None;
+                }
+            }
+            None => match c {
+                '"' | '\'' => quote = Some(c),
+                '{' => open.push('}'),
+                '[' => open.push(']'),
+                '}' | ']' if open.pop() != Some(c) => return false,
+                _ => {}
+            },
+        }
+    }
+    open.is_empty() && quote.is_none()
+}
+
+// The input as an array when it is a CSV-style row of two or more comma-separated quoted
+// fields (`"a","b","c"`), for `wrap_fragments`. Each field must be a closed string; a single
+// quoted string is not a row.
+fn quoted_fields(input: &str) -> Option<(String, StepMap)> {
+    let mut rest = input
+        .trim_start_matches('\u{FEFF}')
+        .trim_matches([' ', '\t', '\n', '\r']);
+    let mut fields = Vec::new();
+    loop {
+        let (field, after) = leading_token(rest)?;
+        let q = field.chars().next()?;
+        if !matches!(q, '"' | '\'') || field.len() < 2 || !field.ends_with(q) {
+            return None;
+        }
+        fields.push(field);
+        rest = after.trim_start();
+        if rest.is_empty() {
+            break;
+        }
+        rest = rest.strip_prefix(',')?.trim_start();
+    }
+    if fields.len() < 2 {
+        return None;
+    }
+    let mut out = Rewrite::new(input);
+    out.push('[');
+    for (i, field) in fields.into_iter().enumerate() {
+        if i > 0 {
+            out.push(',');
+        }
+        out.push_str(field);
+    }
+    out.push(']');
+    Some(out.finish())
+}
+
+// Split off the first token of `s`: a quoted string (escapes honoured) or a run of
+// characters up to whitespace or a delimiter. `None` for empty input or a container.
+fn leading_token(s: &str) -> Option<(&str, &str)> {
+    let q = s.chars().next()?;
+    if q == '{' || q == '[' {
+        return None;
+    }
+    let end = if q == '"' || q == '\'' {
+        let mut escaped = false;
+        s.char_indices()
+            .skip(1)
+            .find(|&(_, c)| {
+                let close = c == q && !escaped;
+                escaped = c == '\\' && !escaped;
+                close
+            })
+            .map_or(s.len(), |(i, _)| i + 1)
+    } else {
+        s.find(|c: char| c.is_whitespace() || matches!(c, ',' | ':' | '[' | ']' | '{' | '}'))
+            .unwrap_or(s.len())
+    };
+    (end > 0).then(|| s.split_at(end))
+}
+
+// True when `s` has a `,` outside strings and brackets.
+fn has_top_level_comma(s: &str) -> bool {
+    let mut depth = 0usize;
+    let mut quote = None;
+    let mut escaped = false;
+    for c in s.chars() {
+        match quote {
+            Some(q) => {
+                if escaped {
+                    escaped = false;
+                } else if c == '\\' {
+                    escaped = true;
+                } else if c == q {
+                    quote = None;
+                }
+            }
+            None => match c {
+                '"' | '\'' => quote = Some(c),
+                '{' | '[' => depth += 1,
+                '}' | ']' => depth = depth.saturating_sub(1),
+                ',' if depth == 0 => return true,
+                _ => {}
+            },
+        }
+    }
+    false
+}
+
+// A whole input that is base64 (e.g. an email-pipeline payload) and decodes to UTF-8 text
+// opening an object or array. Decoded text that looks like anything else is not used; the
+// decoded text has no offsets in common with the input, so all of it maps to the start.
+fn base64_document(input: &str) -> Option<(String, StepMap)> {
+    let s = input.trim_start_matches('\u{FEFF}');
+    let text = String::from_utf8(crate::base64::decode(s)?).ok()?;
+    let body = text.trim_start_matches('\u{FEFF}');
+    if !body.trim_start().starts_with(['{', '[']) {
+        return None;
+    }
+    let mut out = Rewrite::new(input);
+    out.push_str(body);
+    out.skip(input);
+    Some(out.finish())
+}
+
+// Text decoding selected by options (base64 payloads, mojibake, alternative quotes),
+// applied before the document-level rewrites below.
+fn decode_input<'a>(input: &'a str, opts: &Options, map: &mut SourceMap) -> Cow<'a, str> {
+    let mut text = Cow::Borrowed(input);
+    if opts.decode_base64
+        && let Some((json, step)) = base64_document(&text)
+    {
+        map.push(step);
+        text = Cow::Owned(compact_rewrite(json, map));
+    }
+    if opts.fix_mojibake
+        && let Some((fixed, step)) = crate::mojibake::fix(&text)
+    {
+        map.push(step);
+        text = Cow::Owned(compact_rewrite(fixed, map));
+    }
+    if let Some((quoted, step)) = crate::altquote::normalize(&text, &opts.alt_quotes) {
+        map.push(step);
+        text = Cow::Owned(compact_rewrite(quoted, map));
+    }
+    if !opts.bracket_aliases.is_empty()
+        && let Some((doc, step)) = keyword_brackets(&text, opts)
+    {
+        map.push(step);
+        text = Cow::Owned(doc);
+    }
+    text
+}
+
+// Input rewrites selected by options, applied after the guards and before the engine runs;
+// with the map of offsets in the result back to `input`.
+fn prepare_input<'a>(input: &'a str, opts: &Options) -> (Cow<'a, str>, SourceMap) {
+    let mut map = SourceMap::default();
+    let text = match decode_input(input, opts, &mut map) {
+        Cow::Borrowed(s) => rewrite_input(s, opts, &mut map),
+        Cow::Owned(s) => Cow::Owned(rewrite_input(&s, opts, &mut map).into_owned()),
+    };
+    if opts.unwrap_escaped_json
+        && let Some((doc, step)) = unescape_nested_values(&text)
+    {
+        map.push(step);
+        let doc = compact_rewrite(doc, &mut map);
+        return (Cow::Owned(doc), map);
+    }
+    (text, map)
+}
+
+fn rewrite_input<'a>(input: &'a str, opts: &Options, map: &mut SourceMap) -> Cow<'a, str> {
+    let input = if opts.extract_embedded {
+        let span = embedded_span(input);
+        map.push(StepMap::slice(input, span));
+        span
+    } else {
+        input
+    };
+    if opts.unwrap_escaped_json
+        && let Some(body) = single_quoted_document(input)
+    {
+        map.push(StepMap::slice(input, body));
+        return Cow::Owned(compact_rewrite(body.to_string(), map));
+    }
+    if opts.wrap_fragments
+        && let Some((doc, step)) = key_value_lines(input).or_else(|| quoted_fields(input))
+    {
+        map.push(step);
+        return Cow::Owned(compact_rewrite(doc, map));
+    }
+    let input = match unopened_body(input, opts.stop_after_first).or_else(|| {
+        opts.add_missing_brackets
+            .then(|| naked_body(input))
+            .flatten()
+    }) {
+        Some((doc, step)) => {
+            map.push(step);
+            Cow::Owned(compact_rewrite(doc, map))
+        }
+        None => Cow::Borrowed(input),
+    };
+    // Sets first, so a tuple in a set (`{(1, 2), 3}`) is in value position for the parens.
+    // Both only swap one bracket for another, so offsets don't move.
+    let input = match opts
+        .compat_python_friendly
+        .then(|| sets_to_arrays(&input, opts))
+        .flatten()
+    {
+        Some(doc) => Cow::Owned(compact_rewrite(doc, map)),
+        None => input,
+    };
+    if opts.parens_as_arrays
+        && let Some(doc) = parens_to_brackets(&input, opts)
+    {
+        return Cow::Owned(compact_rewrite(doc, map));
+    }
+    input
+}
+
+// Wrap a scalar top-level value for `force_container`: `5` → `[5]` or `{"value":5}`.
+// Objects, arrays and empty output are returned unchanged.
+fn wrap_scalar(out: String, mode: ForceContainer) -> String {
+    let head = out.trim_start_matches([' ', '\t', '\n', '\r']);
+    if head.is_empty() || head.starts_with(['{', '[']) {
+        return out;
+    }
+    match mode {
+        ForceContainer::Off => out,
+        ForceContainer::Array => format!("[{out}]"),
+        ForceContainer::Object => format!("{{\"value\":{out}}}"),
+    }
+}
+
+// Escape `/` inside strings for `escape_slashes`. Escapes are copied as pairs, so an
+// existing `\/` is not escaped twice.
+fn escape_slashes(out: String) -> String {
+    if !out.contains('/') {
+        return out;
+    }
+    let mut s = String::with_capacity(out.len() + 8);
+    let mut in_string = false;
+    let mut chars = out.chars();
+    while let Some(c) = chars.next() {
+        match c {
+            '"' => in_string = !in_string,
+            '\\' if in_string => {
+                s.push(c);
+                if let Some(e) = chars.next() {
+                    s.push(e);
+                }
+                continue;
+            }
+            '/' if in_string => s.push('\\'),
+            _ => {}
+        }
+        s.push(c);
+    }
+    s
+}
+
+// Pass each number outside strings and comments through `Options::number_format`. A bad
+// result is reported at position 0: offsets into the output do not map back to the input.
+fn format_numbers(out: String, format: &NumberFormat) -> Result<String, RepairError> {
+    let b = out.as_bytes();
+    let mut s = String::with_capacity(out.len());
+    let mut copied = 0;
+    let mut i = 0;
+    while i < b.len() {
+        match b[i] {
+            b'"' => i += crate::json5::string_end(&out[i..]),
+            b'/' if b.get(i + 1) == Some(&b'*') => {
+                i = out[i + 2..].find("*/").map_or(b.len(), |e| i + 2 + e + 2);
+            }
+            b'-' | b'0'..=b'9' => {
+                let len = out[i..]
+                    .find(|c: char| !matches!(c, '0'..='9' | '-' | '+' | '.' | 'e' | 'E'))
+                    .unwrap_or(out.len() - i);
+                let raw = &out[i..i + len];
+                if crate::parser::is_json_number(raw) {
+                    let number = format.format(raw);
+                    if !crate::parser::is_json_number(&number) {
+                        return Err(RepairError::new(
+                            RepairErrorKind::Parse(format!(
+                                "number format gave {number:?} for {raw}, not a JSON number"
+                            )),
+                            0,
+                        ));
+                    }
+                    s.push_str(&out[copied..i]);
+                    s.push_str(&number);
+                    copied = i + len;
+                }
+                i += len;
+            }
+            _ => i += 1,
+        }
+    }
+    s.push_str(&out[copied..]);
+    Ok(s)
+}
+
+// Spell the short escapes `\b \f \n \r \t` in strings as `\u00XX` for
+// `short_escapes = false`.
+fn long_escapes(out: String) -> String {
+    if !out.contains('\\') {
+        return out;
+    }
+    let mut s = String::with_capacity(out.len() + 16);
+    let mut in_string = false;
+    let mut chars = out.chars();
+    while let Some(c) = chars.next() {
+        match c {
+            '"' => in_string = !in_string,
+            '\\' if in_string => {
+                match chars.next() {
+                    Some('b') => s.push_str("\\u0008"),
+                    Some('f') => s.push_str("\\u000C"),
+                    Some('n') => s.push_str("\\u000A"),
+                    Some('r') => s.push_str("\\u000D"),
+                    Some('t') => s.push_str("\\u0009"),
+                    Some(e) => {
+                        s.push(c);
+                        s.push(e);
+                    }
+                    None => s.push(c),
+                }
+                continue;
+            }
+            _ => {}
+        }
+        s.push(c);
+    }
+    s
+}
+
+// Rewrite every string with the fewest escapes JSON allows, for `minimal_escapes`: `\/`
+// and `\uXXXX` of printable characters become the characters themselves, `\u0022` and
+// `\u005C` become `\"` and `\\`, and control characters use their short escape where one
+// exists. Non-ASCII escapes are kept where `ascii_keys`/`ascii_values` asks for them. A lone
+// surrogate never gets here: both engines drop one while parsing.
+fn minimal_escapes(out: String, opts: &Options) -> String {
+    if !out.contains('\\') {
+        return out;
+    }
+    let mut s = String::with_capacity(out.len());
+    let mut rest = out.as_str();
+    while let Some(c) = rest.chars().next() {
+        if c != '"' {
+            s.push(c);
+            rest = &rest[c.len_utf8()..];
+            continue;
+        }
+        let len = crate::json5::string_end(rest);
+        let (lit, after) = rest.split_at(len);
+        rest = after;
+        let keep_ascii = if after.trim_start().starts_with(':') {
+            opts.ascii_keys()
+        } else {
+            opts.ascii_values()
+        };
+        let mut body = lit;
+        while let Some(c) = body.chars().next() {
+            if c != '\\' || body.len() < 2 {
+                s.push(c);
+                body = &body[c.len_utf8()..];
+                continue;
+            }
+            let (decoded, len) = match body.as_bytes()[1] {
+                b'/' => (Some('/'), 2),
+                b'u' => unicode_escape(body),
+                _ => (None, 1 + body[1..].chars().next().map_or(0, char::len_utf8)),
+            };
+            match decoded {
+                Some('"') => s.push_str("\\\""),
+                Some('\\') => s.push_str("\\\\"),
+                Some('\u{08}') => s.push_str("\\b"),
+                Some('\u{0C}') => s.push_str("\\f"),
+                Some('\n') => s.push_str("\\n"),
+                Some('\r') => s.push_str("\\r"),
+                Some('\t') => s.push_str("\\t"),
+                Some(d) if !(d < ' ' || keep_ascii && !d.is_ascii()) => s.push(d),
+                _ => s.push_str(&body[..len]),
+            }
+            body = &body[len..];
+        }
+    }
+    s
+}
+
+// Decode the `\uXXXX` escape (or surrogate pair of them) that starts `body`: the character,
+// if it is a valid one, and the length of the escape text.
+fn unicode_escape(body: &str) -> (Option<char>, usize) {
+    let hex = |at: usize| {
+        body.get(at..at + 4)
+            .filter(|h| h.bytes().all(|b| b.is_ascii_hexdigit()))
+            .and_then(|h| u32::from_str_radix(h, 16).ok())
+    };
+    let Some(hi) = hex(2) else {
+        return (None, 2);
+    };
+    if (0xD800..0xDC00).contains(&hi)
+        && body[6..].starts_with("\\u")
+        && let Some(lo) = hex(8).filter(|lo| (0xDC00..0xE000).contains(lo))
+    {
+        let code = 0x10000 + ((hi - 0xD800) << 10) + (lo - 0xDC00);
+        return (char::from_u32(code), 12);
+    }
+    (char::from_u32(hi), 6)
+}
+
+// Drop spaces and tabs (raw or `\t`) before each `\n` or `\r` escape in string values, for
+// `strip_trailing_line_ws`. A string followed by `:` is a key and is copied as-is.
+fn strip_line_ws(out: String) -> String {
+    if !out.contains("\\n") && !out.contains("\\r") {
+        return out;
+    }
+    let mut s = String::with_capacity(out.len());
+    let mut rest = out.as_str();
+    while let Some(c) = rest.chars().next() {
+        if c != '"' {
+            s.push(c);
+            rest = &rest[c.len_utf8()..];
+            continue;
+        }
+        let len = crate::json5::string_end(rest);
+        let (lit, after) = rest.split_at(len);
+        rest = after;
+        if after.trim_start().starts_with(':') {
+            s.push_str(lit);
+            continue;
+        }
+        // Trailing whitespace of the current line, held back until the line goes on.
+        let mut pending = 0;
+        let mut body = lit;
+        while let Some(c) = body.chars().next() {
+            let len = if c == '\\' && body.len() > 1 {
+                2
+            } else {
+                c.len_utf8()
+            };
+            let token = &body[..len];
+            match token {
+                " " | "\t" | "\\t" => pending += len,
+                "\\n" | "\\r" => {
+                    s.truncate(s.len() - pending);
+                    pending = 0;
+                }
+                _ => pending = 0,
+            }
+            s.push_str(token);
+            body = &body[len..];
+        }
+    }
+    s
+}
+
+// Collapse each run of whitespace in string values to one space, for `collapse_ws`. Escapes
+// count by what they decode to, so `\t` and `\u0020` are whitespace too. Line breaks join
+// the run under `collapse_ws_newlines` and are copied as written otherwise.
+fn collapse_ws(out: String, newlines: bool) -> String {
+    let mut s = String::with_capacity(out.len());
+    let mut rest = out.as_str();
+    while let Some(c) = rest.chars().next() {
+        if c != '"' {
+            s.push(c);
+            rest = &rest[c.len_utf8()..];
+            continue;
+        }
+        let len = crate::json5::string_end(rest);
+        let (lit, after) = rest.split_at(len);
+        rest = after;
+        if after.trim_start().starts_with(':') {
+            s.push_str(lit);
+            continue;
+        }
+        let mut in_run = false;
+        let mut body = lit;
+        while let Some(c) = body.chars().next() {
+            let (decoded, len) = if c == '\\' && body.len() > 1 {
+                match body.as_bytes()[1] {
+                    b't' => (Some('\t'), 2),
+                    b'n' => (Some('\n'), 2),
+                    b'r' => (Some('\r'), 2),
+                    b'f' => (Some('\u{0C}'), 2),
+                    b'u' => unicode_escape(body),
+                    _ => (None, 1 + body[1..].chars().next().map_or(0, char::len_utf8)),
+                }
+            } else {
+                (Some(c), c.len_utf8())
+            };
+            let ws = match decoded {
+                Some(' ' | '\t' | '\u{0B}' | '\u{0C}') => true,
+                Some('\n' | '\r') => newlines,
+                _ => false,
+            };
+            if !ws {
+                s.push_str(&body[..len]);
+            } else if !in_run {
+                s.push(' ');
+            }
+            in_run = ws;
+            body = &body[len..];
+        }
+    }
+    s
+}
+
+// Re-space output for `CompactSpacing::Minimal`: whitespace outside strings and comments is
+// dropped and exactly one space follows each `:` and `,`.
+fn minimal_spacing(out: &str) -> String {
+    let mut s = String::with_capacity(out.len() + out.len() / 8);
+    let mut in_string = false;
+    let mut rest = out;
+    while let Some(c) = rest.chars().next() {
+        let mut len = c.len_utf8();
+        if in_string {
+            if c == '\\' {
+                len += rest[1..].chars().next().map_or(0, char::len_utf8);
+            } else if c == '"' {
+                in_string = false;
+            }
+            s.push_str(&rest[..len]);
+        } else if rest.starts_with("/*") {
+            len = rest.find("*/").map_or(rest.len(), |i| i + 2);
+            s.push_str(&rest[..len]);
+        } else {
+            match c {
+                ' ' | '\t' | '\n' | '\r' => {}
+                ':' | ',' => {
+                    s.push(c);
+                    s.push(' ');
+                }
+                '"' => {
+                    in_string = true;
+                    s.push(c);
+                }
+                _ => s.push(c),
+            }
+        }
+        rest = &rest[len..];
+    }
+    s
+}
+
+// True when an output transform needs the complete repaired text.
+#[inline]
+fn buffers_output(opts: &Options) -> bool {
+    opts.unwrap_escaped_json
+        || opts.dedup_position != DedupPosition::KeepAll
+        || opts.dedup_arrays
+        || opts.sort_keys
+        || opts.minimal_escapes
+        || opts.escape_slashes
+        || !opts.short_escapes
+        || opts.strip_trailing_line_ws
+        || opts.collapse_ws
+        || opts.lines_to_array
+        || opts.compact_spacing != CompactSpacing::None
+        || opts.indent_detect
+        || opts.force_container != ForceContainer::Off
+        || opts.output_format != OutputFormat::Json
+        || opts.raw_message_safe
+        || opts.envelope
+        || opts.number_format.is_some()
+}
+
+// Line ending of pretty-printed output.
+#[inline]
+fn line_end(opts: &Options) -> &'static str {
+    if opts.crlf { "\r\n" } else { "\n" }
+}
+
+// Output transforms applied to the final repaired text. `input` is the input as given, before
+// any rewrite: `indent_detect` takes its unit from there.
+#[inline]
+fn finish_output(mut out: String, opts: &Options, input: &str) -> Result<String, RepairError> {
+    if let Some(format) = &opts.number_format {
+        out = format_numbers(out, format)?;
+    }
+    if (opts.dedup_position != DedupPosition::KeepAll || opts.dedup_arrays || opts.sort_keys)
+        && !opts.annotate_source
+    {
+        out = crate::dedup::dedup(&out, opts.dedup_position, opts.dedup_arrays, opts.sort_keys);
+    }
+    if opts.force_container != ForceContainer::Off {
+        out = wrap_scalar(out, opts.force_container);
+    }
+    if opts.minimal_escapes {
+        out = minimal_escapes(out, opts);
+    }
+    if opts.escape_slashes {
+        out = escape_slashes(out);
+    }
+    if opts.collapse_ws {
+        out = collapse_ws(out, opts.collapse_ws_newlines);
+    }
+    if opts.strip_trailing_line_ws {
+        out = strip_line_ws(out);
+    }
+    if !opts.short_escapes {
+        out = long_escapes(out);
+    }
+    if opts.compact_spacing == CompactSpacing::Minimal {
+        out = minimal_spacing(&out);
+    }
+    if opts.indent_detect
+        && let Some(unit) = crate::indent::detect(input)
+    {
+        let align = opts.align_values && opts.output_format == OutputFormat::Json;
+        out = crate::indent::reindent(&out, unit, line_end(opts), align);
+    }
+    if opts.raw_message_safe {
+        // Layout and the BOM are dropped again: the output is embedded byte for byte.
+        return Ok(crate::strict::minify(&out).unwrap_or_else(|_| out.trim().to_string()));
+    }
+    if opts.output_format == OutputFormat::Json5 {
+        out = crate::json5::render(&out);
+    }
+    if opts.output_bom {
+        let mut s = String::with_capacity(out.len() + 3);
+        s.push('\u{FEFF}');
+        s.push_str(&out);
+        return Ok(s);
+    }
+    Ok(out)
+}
+
+// The final `Options::progress` report, once the whole input has been repaired.
+fn report_done(opts: &Options, len: usize) {
+    if let Some(progress) = &opts.progress {
+        progress.report(len, len);
+    }
+}
+
+// Options for repairing the values of a split input one by one: per-value progress
+// would restart at zero for each value, so it is not reported.
+fn without_progress(opts: &Options) -> Cow<'_, Options> {
+    if opts.progress.is_none() {
+        return Cow::Borrowed(opts);
+    }
+    Cow::Owned(Options {
+        progress: None,
+        ..opts.clone()
+    })
+}
+
 pub(crate) fn repair_to_string(input: &str, opts: &Options) -> Result<String, RepairError> {
-    engine_repair_to_string(input, opts)
+    guard_options(opts)?;
+    repair_document(input, opts)
+}
+
+// `repair_to_string` without the options check, for the callers that repair to JSON first
+// and apply the output format to the result.
+fn repair_document(input: &str, opts: &Options) -> Result<String, RepairError> {
+    if opts.envelope {
+        return repair_enveloped(input, opts);
+    }
+    if opts.lines_to_array {
+        return repair_lines(input, opts);
+    }
+    guard_input(input, opts)?;
+    let (text, map) = prepare_input(input, opts);
+    // Positions are reported in the input as given, not in the rewritten text, and the
+    // indent unit is detected there too: rewrites may have compacted it.
+    let mut out = engine_repair_to_string(&text, opts).map_err(|e| map.error(e))?;
+    if opts.annotate_source {
+        out = map.annotations(out);
+    }
+    if opts.unwrap_escaped_json {
+        out = unwrap_escaped(out, opts)?;
+    }
+    report_done(opts, text.len());
+    finish_output(out, opts, input)
+}
+
+// `Options::envelope`: the repaired value with whether it changed and how many repairs it
+// took, counted from the repairs charged on this thread during the call.
+fn repair_enveloped(input: &str, opts: &Options) -> Result<String, RepairError> {
+    let value_opts = Options {
+        envelope: false,
+        output_bom: false,
+        ..opts.clone()
+    };
+    let charged = crate::budget::repairs_charged();
+    let value = repair_to_string(input, &value_opts)?;
+    let repairs = crate::budget::repairs_charged() - charged;
+    // Valid input is copied through with its surrounding whitespace; keep that out.
+    let trimmed = value.trim_matches([' ', '\t', '\n', '\r']);
+    let value_json = if trimmed.is_empty() { "null" } else { trimmed };
+    Ok(format!(
+        "{}{{\"value\":{},\"repaired\":{},\"repairs\":{}}}",
+        if opts.output_bom { "\u{FEFF}" } else { "" },
+        value_json,
+        value != input,
+        repairs
+    ))
+}
+
+// `Options::lines_to_array`: repair each non-blank line on its own and join the values into
+// one array, which then gets the document layout options. Any error but a timeout is
+// confined to its line, so `salvage` can replace the line.
+fn repair_lines(input: &str, opts: &Options) -> Result<String, RepairError> {
+    guard_size(input, opts)?;
+    let line_opts = Options {
+        lines_to_array: false,
+        output_bom: false,
+        output_format: OutputFormat::Json,
+        compact_spacing: CompactSpacing::None,
+        indent_detect: false,
+        progress: None,
+        ..opts.clone()
+    };
+    let mut out = String::from("[");
+    let mut at = 0;
+    for line in input.split_inclusive('\n') {
+        let start = at;
+        at += line.len();
+        let line = line.trim_end_matches(['\n', '\r']);
+        if line.trim().is_empty() {
+            continue;
+        }
+        let value = match repair_document(line, &line_opts) {
+            // Annotations count from the start of the line; move them to the input.
+            Ok(value) if opts.annotate_source => {
+                let mut map = SourceMap::default();
+                map.push(StepMap::slice(input, line));
+                map.annotations(value)
+            }
+            Ok(value) => value,
+            Err(e)
+                if opts.salvage == SalvagePolicy::Fail
+                    || matches!(e.kind, RepairErrorKind::Timeout(_)) =>
+            {
+                return Err(RepairError::new(e.kind, start + e.position));
+            }
+            Err(_) if opts.salvage == SalvagePolicy::Null => opts
+                .placeholder_json()
+                .unwrap_or_else(|| "null".to_string()),
+            Err(_) => {
+                let mut marker = String::from("{\"$unrepairable\":");
+                crate::parser::emit_json_string_from_lit(
+                    &mut StringEmitter::new(&mut marker),
+                    line.trim(),
+                    opts.ascii_values(),
+                )?;
+                marker.push('}');
+                marker
+            }
+        };
+        if value.is_empty() {
+            continue;
+        }
+        if out.len() > 1 {
+            out.push(',');
+        }
+        out.push_str(&value);
+    }
+    out.push(']');
+    report_done(opts, input.len());
+    let layout = Options {
+        output_bom: opts.output_bom,
+        raw_message_safe: opts.raw_message_safe,
+        output_format: opts.output_format,
+        compact_spacing: opts.compact_spacing,
+        indent_detect: opts.indent_detect,
+        align_values: opts.align_values,
+        crlf: opts.crlf,
+        ..Options::default()
+    };
+    finish_output(out, &layout, input)
+}
+
+pub(crate) fn repair_with_comments(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, Vec<(String, String)>), RepairError> {
+    guard_input(input, opts)?;
+    let (text, map) = prepare_input(input, opts);
+    // Source annotations map each output value back to the input, where the comments are.
+    let annotated = crate::parser::repair_to_string_impl(
+        &text,
+        &Options {
+            annotate_source: true,
+            ..opts.clone()
+        },
+    )
+    .map_err(|e| map.error(e))?;
+    let (mut out, comments) = crate::comments::attach(&text, &annotated, opts);
+    if opts.unwrap_escaped_json {
+        out = unwrap_escaped(out, opts)?;
+    }
+    report_done(opts, text.len());
+    Ok((finish_output(out, opts, input)?, comments))
+}
+
+pub(crate) fn repair_skeleton(input: &str, opts: &Options) -> Result<String, RepairError> {
+    // The skeleton is read from strict JSON, without source annotations.
+    let json_opts = if opts.output_format == OutputFormat::Json && !opts.annotate_source {
+        Cow::Borrowed(opts)
+    } else {
+        Cow::Owned(Options {
+            output_format: OutputFormat::Json,
+            annotate_source: false,
+            ..opts.clone()
+        })
+    };
+    Ok(crate::skeleton::skeleton(&repair_to_string(
+        input, &json_opts,
+    )?))
+}
+
+pub(crate) fn repair_both(
+    input: &str,
+    indent: usize,
+    opts: &Options,
+) -> Result<(String, String), RepairError> {
+    // Both layouts are made from the JSON output; JSON5 quoting is applied to each after.
+    let json_opts = if opts.output_format == OutputFormat::Json {
+        Cow::Borrowed(opts)
+    } else {
+        Cow::Owned(Options {
+            output_format: OutputFormat::Json,
+            ..opts.clone()
+        })
+    };
+    guard_options(opts)?;
+    let compact = crate::indent::compact(&repair_document(input, &json_opts)?);
+    let pretty = crate::indent::reindent(&compact, &" ".repeat(indent), line_end(opts), false);
+    Ok(match opts.output_format {
+        OutputFormat::Json => (compact, pretty),
+        OutputFormat::Json5 => (
+            crate::json5::render(&compact),
+            crate::json5::render(&pretty),
+        ),
+    })
+}
+
+pub(crate) fn repair_split(input: &str, opts: &Options) -> Result<Vec<String>, RepairError> {
+    guard_size(input, opts)?;
+    let opts = &*without_progress(opts);
+    let (input, _) = prepare_input(input, opts);
+    crate::parser::split_roots(&input, opts, usize::MAX)?
+        .into_iter()
+        .map(|value| repair_to_string(value, opts))
+        .collect()
+}
+
+pub(crate) fn repair_first(input: &str, opts: &Options) -> Result<(String, usize), RepairError> {
+    guard_size(input, opts)?;
+    let opts = &*without_progress(opts);
+    // The value is found in the rewritten text, and its end mapped back to the input.
+    let (text, map) = prepare_input(input, opts);
+    let Some(&value) = crate::parser::split_roots(&text, opts, 1)?.first() else {
+        return Err(RepairError::new(
+            RepairErrorKind::UnexpectedEnd,
+            input.len(),
+        ));
+    };
+    let end = value.as_ptr() as usize - text.as_ptr() as usize + value.len();
+    Ok((repair_to_string(value, opts)?, map.to_source(end)))
+}
+
+pub(crate) fn repair_with_end(input: &str, opts: &Options) -> Result<(String, usize), RepairError> {
+    let out = repair_to_string(input, opts)?;
+    // The values are found again on the text the output was repaired from, and their end
+    // mapped back through the rewrites to the input as given.
+    let scan = Options {
+        progress: None,
+        trace: None,
+        ..opts.clone()
+    };
+    let (text, map) = prepare_input(input, &scan);
+    Ok((out, map.to_source(crate::parser::root_end(&text, &scan)?)))
+}
+
+// `repair_extract` without keeping the output off the pointer path, when the engine output is
+// the result as it stands: no transform needs all of it and `salvage` never rewinds over
+// it. `None` when the whole document has to be repaired and the target sliced out.
+pub(crate) fn repair_extract_streaming(
+    input: &str,
+    pointer: &str,
+    tokens: &[String],
+    opts: &Options,
+) -> Result<Option<String>, RepairError> {
+    guard_options(opts)?;
+    if buffers_output(opts)
+        || opts.annotate_source
+        || opts.python_style_separators
+        || opts.salvage != SalvagePolicy::Fail
+        || cfg!(feature = "llm-compat") && opts.engine == EngineKind::LlmCompat
+    {
+        return Ok(None);
+    }
+    guard_input(input, opts)?;
+    let (input, map) = prepare_input(input, opts);
+    let found = crate::parser::extract_impl(&input, opts, tokens).map_err(|e| map.error(e))?;
+    let value = match found {
+        crate::parser::Extract::Found(value) => value,
+        crate::parser::Extract::Missing => {
+            return Err(RepairError::new(
+                RepairErrorKind::PointerNotFound(pointer.to_string()),
+                0,
+            ));
+        }
+        crate::parser::Extract::Whole => return Ok(None),
+    };
+    report_done(opts, input.len());
+    Ok(Some(if opts.output_bom {
+        format!("\u{FEFF}{value}")
+    } else {
+        value
+    }))
 }
 
 pub(crate) fn repair_to_writer_streaming<W: Write>(
@@ -63,7 +1647,24 @@ pub(crate) fn repair_to_writer_streaming<W: Write>(
     opts: &Options,
     writer: &mut W,
 ) -> Result<(), RepairError> {
-    engine_repair_to_writer(input, opts, writer)
+    guard_options(opts)?;
+    if buffers_output(opts) {
+        // Unwrapping, dedup and wrapping need the whole output; buffer it.
+        let s = repair_to_string(input, opts)?;
+        return writer.write_all(s.as_bytes()).map_err(|e| {
+            RepairError::new(RepairErrorKind::Parse(format!("write error: {}", e)), 0)
+        });
+    }
+    guard_input(input, opts)?;
+    let (input, map) = prepare_input(input, opts);
+    if opts.output_bom {
+        writer.write_all("\u{FEFF}".as_bytes()).map_err(|e| {
+            RepairError::new(RepairErrorKind::Parse(format!("write error: {}", e)), 0)
+        })?;
+    }
+    engine_repair_to_writer(&input, opts, writer).map_err(|e| map.error(e))?;
+    report_done(opts, input.len());
+    Ok(())
 }
 
 #[cfg(feature = "logging")]
@@ -72,12 +1673,65 @@ pub(crate) fn repair_to_string_with_log(
     opts: &Options,
 ) -> Result<(String, Vec<RepairLogEntry>), RepairError> {
     // Force-enable logging for this call and return captured log entries
+    let (out, logger, map) = repair_with_logger(
+        input,
+        opts,
+        crate::parser::Logger::new(true, opts.log_json_path),
+    )?;
+    let mut log = logger.into_entries();
+    for entry in &mut log {
+        entry.position = map.to_source(entry.position);
+    }
+    Ok((out, log))
+}
+
+/// Repair `input` with `logger` and hand the logger back with the source map of the
+/// prepared text, for the caller to read what it recorded.
+#[cfg(feature = "logging")]
+fn repair_with_logger(
+    input: &str,
+    opts: &Options,
+    logger: crate::parser::Logger,
+) -> Result<(String, crate::parser::Logger, SourceMap), RepairError> {
+    guard_options(opts)?;
+    guard_input(input, opts)?;
+    let (text, map) = prepare_input(input, opts);
     let mut out = String::new();
     let mut emitter = StringEmitter::new(&mut out);
-    let mut s = crate::parser::pre_trim_wrappers(input, opts);
-    let mut logger = crate::parser::Logger::new(true, opts.log_json_path);
-    crate::parser::parse_root_many(&mut s, opts, &mut emitter, &mut logger)?;
-    Ok((out, logger.into_entries()))
+    let mut s = crate::parser::pre_trim_wrappers(&text, opts);
+    let mut logger = logger.with_budget(opts, s.len()).with_source(opts, &text);
+    crate::parser::parse_root_many(&mut s, opts, &mut emitter, &mut logger)
+        .inspect_err(|e| logger.trace_error(e))
+        .map_err(|e| map.error(e))?;
+    if opts.annotate_source {
+        out = map.annotations(out);
+    }
+    report_done(opts, text.len());
+    Ok((finish_output(out, opts, input)?, logger, map))
+}
+
+/// Repair `input` and list the distinct paths of its repairs, in order of first repair.
+///
+/// The paths are collected as the parser goes, each written out once, rather than read off
+/// a full log whose every entry spells out its path.
+#[cfg(feature = "logging")]
+pub(crate) fn repair_affected_paths(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, Vec<String>), RepairError> {
+    let logger = crate::parser::Logger::new(false, false).with_affected_paths();
+    let (out, logger, _) = repair_with_logger(input, opts, logger)?;
+    Ok((out, logger.into_affected_paths()))
+}
+
+#[cfg(not(feature = "logging"))]
+pub(crate) fn repair_affected_paths(
+    input: &str,
+    opts: &Options,
+) -> Result<(String, Vec<String>), RepairError> {
+    // Logging disabled at compile time: no repairs are recorded
+    let (out, _) = repair_to_string_with_log(input, opts)?;
+    Ok((out, Vec::new()))
 }
 
 #[cfg(not(feature = "logging"))]
@@ -85,7 +1739,14 @@ pub(crate) fn repair_to_string_with_log(
     input: &str,
     opts: &Options,
 ) -> Result<(String, Vec<RepairLogEntry>), RepairError> {
+    guard_options(opts)?;
+    guard_input(input, opts)?;
+    let (text, map) = prepare_input(input, opts);
     // Logging disabled at compile time: return repaired string with empty log
-    let s = crate::parser::repair_to_string_impl(input, opts)?;
-    Ok((s, Vec::new()))
+    let mut s = crate::parser::repair_to_string_impl(&text, opts).map_err(|e| map.error(e))?;
+    if opts.annotate_source {
+        s = map.annotations(s);
+    }
+    report_done(opts, text.len());
+    Ok((finish_output(s, opts, input)?, Vec::new()))
 }
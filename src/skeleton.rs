@@ -0,0 +1,102 @@
+//! Type skeleton of repaired output, for exploring unfamiliar data: every scalar is replaced
+//! by the name of its type, so `{"a":1,"b":["x"]}` becomes `{"a":"number","b":["string"]}`.
+//!
+//! Objects keep their keys in order. An array is summarized by the distinct skeletons of
+//! its elements in order of first appearance: `[1, 2, "x", 3]` becomes
+//! `["number","string"]`, and objects of different shapes are listed once each. Like the
+//! parser, the walk keeps open containers on an explicit stack, so depth is not limited by
+//! the call stack.
+
+use crate::pointer::{skip_value, skip_ws};
+use std::collections::HashSet;
+
+enum Frame {
+    // The skeleton written so far, up to and including the current key and `:`.
+    Object(String),
+    // Distinct element skeletons in order of first appearance, and a set of them.
+    Array(Vec<String>, HashSet<String>),
+}
+
+/// The type skeleton of `json`, which must be valid JSON. Empty input gives empty output.
+pub(crate) fn skeleton(json: &str) -> String {
+    let b = json.as_bytes();
+    let mut stack: Vec<Frame> = Vec::new();
+    let mut i = skip_ws(b, json.len() - json.trim_start_matches('\u{FEFF}').len());
+    loop {
+        let mut value = match b.get(i) {
+            None => return String::new(),
+            Some(b'{') => {
+                i = skip_ws(b, i + 1);
+                if b.get(i) == Some(&b'}') {
+                    i += 1;
+                    "{}".to_string()
+                } else {
+                    let mut s = String::from("{");
+                    i = push_key(json, i, &mut s);
+                    stack.push(Frame::Object(s));
+                    continue;
+                }
+            }
+            Some(b'[') => {
+                i = skip_ws(b, i + 1);
+                if b.get(i) == Some(&b']') {
+                    i += 1;
+                    "[]".to_string()
+                } else {
+                    stack.push(Frame::Array(Vec::new(), HashSet::new()));
+                    continue;
+                }
+            }
+            Some(&c) => {
+                i = skip_value(b, i);
+                match c {
+                    b'"' => "\"string\"",
+                    b't' | b'f' => "\"boolean\"",
+                    b'n' => "\"null\"",
+                    _ => "\"number\"",
+                }
+                .to_string()
+            }
+        };
+        // Hand the finished value to its container, closing every container it completes.
+        loop {
+            i = skip_ws(b, i);
+            let more = b.get(i) == Some(&b',');
+            match stack.last_mut() {
+                None => return value,
+                Some(Frame::Object(s)) => {
+                    s.push_str(&value);
+                    if more {
+                        s.push(',');
+                        i = push_key(json, skip_ws(b, i + 1), s);
+                        break;
+                    }
+                    s.push('}');
+                    value = std::mem::take(s);
+                }
+                Some(Frame::Array(items, seen)) => {
+                    if seen.insert(value.clone()) {
+                        items.push(value);
+                    }
+                    if more {
+                        i = skip_ws(b, i + 1);
+                        break;
+                    }
+                    value = format!("[{}]", items.join(","));
+                }
+            }
+            // The closing bracket.
+            i += 1;
+            stack.pop();
+        }
+    }
+}
+
+// Copy the key at `i` and its `:` to `s`; returns the offset of the member's value.
+fn push_key(json: &str, i: usize, s: &mut String) -> usize {
+    let b = json.as_bytes();
+    let end = skip_value(b, i);
+    s.push_str(&json[i..end]);
+    s.push(':');
+    skip_ws(b, skip_ws(b, end) + 1)
+}
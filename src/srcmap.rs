@@ -0,0 +1,204 @@
+//! Byte offsets through the input rewrites that run before the engine (`extract_embedded`,
+//! `add_missing_brackets`, `fix_mojibake`, ...).
+//!
+//! The engine reports positions in the text it was given, so every rewrite records how its
+//! output relates to its input, and `SourceMap::to_source` maps a position back through the
+//! whole chain to the caller's input. Text copied from the input maps byte for byte; text a
+//! rewrite put in (a `{` it added, a `"` standing in for `«`) maps to the input position
+//! where it was put in.
+
+/// One run of a rewrite's output: from output byte `at` on, either copied from input byte
+/// `src` on, or inserted at input byte `src`.
+#[derive(Clone, Copy, Debug)]
+struct Seg {
+    at: usize,
+    src: usize,
+    copied: bool,
+}
+
+/// How the output of one rewrite relates to its input. Empty means unchanged offsets.
+#[derive(Clone, Debug, Default)]
+pub(crate) struct StepMap(Vec<Seg>);
+
+impl StepMap {
+    /// The map of `part`, a sub-slice of `src`, taken as the rewritten text.
+    pub(crate) fn slice(src: &str, part: &str) -> Self {
+        let mut r = Rewrite::new(src);
+        r.push_str(part);
+        r.finish().1
+    }
+
+    /// The map of `out`, made from `src` by only deleting characters (such as whitespace).
+    pub(crate) fn deletions(src: &str, out: &str) -> Self {
+        let mut r = Rewrite::new(src);
+        let mut rest = out;
+        let mut at = 0;
+        while let Some(c) = rest.chars().next() {
+            let Some(i) = src[at..].find(c) else {
+                break;
+            };
+            let len = c.len_utf8();
+            r.push_str(&src[at + i..at + i + len]);
+            at += i + len;
+            rest = &rest[len..];
+        }
+        r.push_str(rest);
+        r.finish().1
+    }
+
+    fn to_source(&self, pos: usize) -> usize {
+        let i = self.0.partition_point(|s| s.at <= pos);
+        match i.checked_sub(1).map(|i| self.0[i]) {
+            Some(seg) if seg.copied => seg.src + (pos - seg.at),
+            Some(seg) => seg.src,
+            None => pos,
+        }
+    }
+}
+
+/// Offsets of text produced by a chain of rewrites, mapped back to the text the first one
+/// was given.
+#[derive(Clone, Debug, Default)]
+pub(crate) struct SourceMap(Vec<StepMap>);
+
+impl SourceMap {
+    /// Add the map of a rewrite of the text this map currently ends at.
+    pub(crate) fn push(&mut self, step: StepMap) {
+        if !step.0.is_empty() {
+            self.0.push(step);
+        }
+    }
+
+    /// The input offset of byte `pos` of the rewritten text.
+    pub(crate) fn to_source(&self, pos: usize) -> usize {
+        self.0
+            .iter()
+            .rev()
+            .fold(pos, |pos, step| step.to_source(pos))
+    }
+
+    /// `err` with its position moved from the rewritten text to the input.
+    pub(crate) fn error(&self, mut err: crate::RepairError) -> crate::RepairError {
+        err.position = self.to_source(err.position);
+        err
+    }
+
+    /// `out`, repaired from the rewritten text with `annotate_source`, with the offsets of
+    /// its `/* @src:OFFSET */` annotations moved to the input.
+    pub(crate) fn annotations(&self, out: String) -> String {
+        const MARK: &str = "/* @src:";
+        if self.0.is_empty() {
+            return out;
+        }
+        let mut fixed = String::with_capacity(out.len());
+        let mut rest = out.as_str();
+        while let Some(c) = rest.chars().next() {
+            let mut len = c.len_utf8();
+            if c == '"' {
+                len = crate::json5::string_end(rest);
+                fixed.push_str(&rest[..len]);
+            } else if let Some(body) = rest.strip_prefix(MARK)
+                && let Some(end) = body.find("*/")
+                && let Ok(offset) = body[..end].trim().parse()
+            {
+                len = MARK.len() + end + 2;
+                fixed.push_str(MARK);
+                fixed.push_str(&self.to_source(offset).to_string());
+                fixed.push_str(" */");
+            } else {
+                fixed.push_str(&rest[..len]);
+            }
+            rest = &rest[len..];
+        }
+        fixed
+    }
+}
+
+/// Output of a rewrite under construction. `push_str` of a sub-slice of the input counts as
+/// copied; anything else (a `char`, a literal, decoded text) counts as inserted at the end
+/// of the last copied slice, so text standing in for skipped input maps to where that
+/// input starts.
+pub(crate) struct Rewrite<'a> {
+    src: &'a str,
+    out: String,
+    segs: Vec<Seg>,
+    cursor: usize,
+}
+
+impl<'a> Rewrite<'a> {
+    pub(crate) fn new(src: &'a str) -> Self {
+        Self {
+            src,
+            out: String::with_capacity(src.len() + 8),
+            segs: Vec::new(),
+            cursor: 0,
+        }
+    }
+
+    /// Byte offset of `s` in the input, if it is a sub-slice of it.
+    fn offset_of(&self, s: &str) -> Option<usize> {
+        let start = self.src.as_ptr() as usize;
+        let at = (s.as_ptr() as usize).checked_sub(start)?;
+        (at + s.len() <= self.src.len()).then_some(at)
+    }
+
+    pub(crate) fn push_str(&mut self, s: &str) {
+        if s.is_empty() {
+            return;
+        }
+        let at = self.out.len();
+        match self.offset_of(s) {
+            Some(src) => {
+                let contiguous = self
+                    .segs
+                    .last()
+                    .is_some_and(|last| last.copied && last.src + (at - last.at) == src);
+                if !contiguous {
+                    self.segs.push(Seg {
+                        at,
+                        src,
+                        copied: true,
+                    });
+                }
+                self.cursor = src + s.len();
+            }
+            None => {
+                let same = self
+                    .segs
+                    .last()
+                    .is_some_and(|last| !last.copied && last.src == self.cursor);
+                if !same {
+                    self.segs.push(Seg {
+                        at,
+                        src: self.cursor,
+                        copied: false,
+                    });
+                }
+            }
+        }
+        self.out.push_str(s);
+    }
+
+    pub(crate) fn push(&mut self, c: char) {
+        self.push_str(c.encode_utf8(&mut [0; 4]));
+    }
+
+    /// Mark `s`, a sub-slice of the input, as used up without copying it, so text after it
+    /// maps past it.
+    pub(crate) fn skip(&mut self, s: &str) {
+        if let Some(src) = self.offset_of(s) {
+            self.cursor = src + s.len();
+        }
+    }
+
+    /// The rewritten text and its map. The end of the output maps to the end of the last
+    /// copied or skipped input.
+    pub(crate) fn finish(mut self) -> (String, StepMap) {
+        self.segs.push(Seg {
+            at: self.out.len(),
+            src: self.cursor,
+            copied: false,
+        });
+        (self.out, StepMap(self.segs))
+    }
+}
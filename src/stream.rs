@@ -14,12 +14,62 @@ enum QuoteKind {
     Double,
 }
 
+/// Validation result for one completed root value (see [`StreamRepairer::push_validate`]).
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ValueStatus {
+    /// Whether the value is valid JSON as-is.
+    pub valid: bool,
+    /// The value's source text, unchanged (surrounding whitespace trimmed).
+    pub text: String,
+    /// First problem found for an invalid value; positions are relative to `text`.
+    pub error: Option<RepairError>,
+}
+
+/// One repaired root value and the input bytes it came from (see
+/// [`StreamRepairer::set_track_ranges`]).
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ValueRange {
+    /// The repaired value, as returned by `push`/`flush`.
+    pub value: String,
+    /// Byte offset of the value's first source byte, counted from the start of the stream.
+    pub start: usize,
+    /// Byte offset just past the value's last source byte.
+    pub end: usize,
+}
+
+/// Keep-alive status for a stretch of input in which no value completed (see
+/// [`StreamRepairer::set_heartbeat`]).
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Heartbeat {
+    /// Input bytes pushed so far, counted from the start of the stream.
+    pub bytes_in: u64,
+    /// Bytes buffered for the value that has not completed yet.
+    pub buffered: usize,
+}
+
+/// Totals for a streaming session (see [`StreamRepairer::stats`]).
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct StreamStats {
+    /// Root values repaired and emitted.
+    pub values: u64,
+    /// Repairs applied to those values, as counted for `Options::max_repairs` (the recursive
+    /// engine's count; values repaired by the LLM-compatible engine add none).
+    pub repairs: u64,
+    /// Input bytes pushed so far, whether or not they are part of a completed value.
+    pub bytes_in: u64,
+    /// Bytes of repaired values emitted, not counting the BOM or NDJSON aggregation brackets
+    /// and separators.
+    pub bytes_out: u64,
+}
+
 pub struct StreamRepairer {
     opts: Options,
     buf: String,
+    // Stream offset of `buf[0]`: the number of input bytes already dropped from the buffer.
+    base: usize,
     seg_start: usize,
     scan_pos: usize,
-    depth: i32,
+    depth: i64,
     in_string: bool,
     quote_kind: QuoteKind,
     escape: bool,
@@ -31,13 +81,44 @@ pub struct StreamRepairer {
     // NDJSON aggregation (optional)
     agg_open: bool,
     agg_buf: String,
+    // `Options::output_bom`: write the BOM once, before the first output of the stream.
+    output_bom: bool,
+    bom_pending: bool,
+    // Validate-only mode: completed values are checked, not repaired.
+    validate_only: bool,
+    statuses: Vec<ValueStatus>,
+    // Recovery mode: values that fail to repair are skipped and reported instead of failing
+    // the stream; `resync` holds the region being skipped up to the next `{` or `[`.
+    recover: bool,
+    resync: Option<ValueStatus>,
+    skipped: Vec<ValueStatus>,
+    // Cap on bytes buffered for an incomplete value (0 = unlimited); output completed by a
+    // chunk that then overflows is kept in `carry` for the next `push`/`flush`.
+    max_buffer: usize,
+    carry: String,
+    // Source ranges of repaired values, recorded when `track_ranges` is set.
+    track_ranges: bool,
+    ranges: Vec<ValueRange>,
+    // Keep-alive reports: one per `heartbeat` bytes pushed with no value completing, counted
+    // from `quiet_since`, the stream offset of the last completion or report (0 = off).
+    heartbeat: usize,
+    quiet_since: usize,
+    heartbeats: Vec<Heartbeat>,
+    // Cumulative counts of emitted values; `bytes_in` is derived from `base` and `buf`.
+    stats: StreamStats,
 }
 
 impl StreamRepairer {
-    pub fn new(opts: Options) -> Self {
-        Self {
+    pub fn new(mut opts: Options) -> Self {
+        // Segments are repaired independently; the stream adds the BOM itself.
+        let bom_pending = std::mem::take(&mut opts.output_bom);
+        // Progress would restart for every segment, so streams do not report it.
+        opts.progress = None;
+        let validate_only = opts.stream_validate_only;
+        let mut s = Self {
             opts,
             buf: String::new(),
+            base: 0,
             seg_start: 0,
             scan_pos: 0,
             depth: 0,
@@ -51,11 +132,282 @@ impl StreamRepairer {
             last_sig_end: 0,
             agg_open: false,
             agg_buf: String::new(),
+            output_bom: bom_pending,
+            bom_pending,
+            validate_only,
+            statuses: Vec::new(),
+            recover: false,
+            resync: None,
+            skipped: Vec::new(),
+            max_buffer: 0,
+            carry: String::new(),
+            track_ranges: false,
+            ranges: Vec::new(),
+            heartbeat: 0,
+            quiet_since: 0,
+            heartbeats: Vec::new(),
+            stats: StreamStats::default(),
+        };
+        if validate_only {
+            s.enter_validate_mode();
+        }
+        s
+    }
+
+    // Values are reported as statuses, so no text output (aggregation, BOM) is produced.
+    fn enter_validate_mode(&mut self) {
+        self.validate_only = true;
+        self.opts.stream_ndjson_aggregate = false;
+        self.bom_pending = false;
+    }
+
+    /// Enable or disable error recovery.
+    ///
+    /// With recovery on, a root value that cannot be repaired (for example one that exceeds
+    /// `Options::max_repairs` or `Options::timeout_ms`) no longer fails `push`/`flush`.
+    /// It is dropped together with any following root-level input up to the next `{` or
+    /// `[`, and the skipped region is reported by [`StreamRepairer::take_skipped`].
+    pub fn set_recover(&mut self, recover: bool) {
+        self.recover = recover;
+    }
+
+    /// Take the regions skipped in recovery mode since the last call.
+    ///
+    /// Each entry has `valid == false`, the skipped source text, and the error of the value
+    /// that failed (positions relative to `text`). A region is reported once the stream has
+    /// resynchronized at the next `{` or `[`, or on flush.
+    pub fn take_skipped(&mut self) -> Vec<ValueStatus> {
+        std::mem::take(&mut self.skipped)
+    }
+
+    /// Record the input byte range of every repaired root value.
+    ///
+    /// Offsets count bytes from the start of the stream, across all pushed chunks, and
+    /// exclude whitespace and comments around the value; [`StreamRepairer::take_ranges`] returns them.
+    /// Values dropped in recovery mode and validate-only statuses are not recorded.
+    pub fn set_track_ranges(&mut self, track: bool) {
+        self.track_ranges = track;
+    }
+
+    /// Take the value ranges recorded since the last call, in stream order.
+    pub fn take_ranges(&mut self) -> Vec<ValueRange> {
+        std::mem::take(&mut self.ranges)
+    }
+
+    /// Report that the stream is alive every `interval_bytes` input bytes pushed without a
+    /// value completing (0, the default, turns reports off).
+    ///
+    /// A report is recorded at the end of a push once at least `interval_bytes` have arrived
+    /// since the last completed value or report, at most one per push, and is taken with
+    /// [`StreamRepairer::take_heartbeats`]. It tells a slow stream, whose value is still
+    /// growing, from a stalled one; the repaired output is unchanged.
+    pub fn set_heartbeat(&mut self, interval_bytes: usize) {
+        self.heartbeat = interval_bytes;
+    }
+
+    /// Take the heartbeats recorded since the last call, in stream order.
+    pub fn take_heartbeats(&mut self) -> Vec<Heartbeat> {
+        std::mem::take(&mut self.heartbeats)
+    }
+
+    // Record a heartbeat when `heartbeat` bytes have arrived since the last completed value.
+    fn note_heartbeat(&mut self) {
+        let bytes_in = self.base + self.buf.len();
+        if self.heartbeat > 0 && bytes_in - self.quiet_since >= self.heartbeat {
+            self.heartbeats.push(Heartbeat {
+                bytes_in: bytes_in as u64,
+                buffered: self.buf.len() - self.seg_start,
+            });
+            self.quiet_since = bytes_in;
+        }
+    }
+
+    /// Return the totals of the session so far: values emitted, repairs applied to them
+    /// and bytes pushed in and emitted out. Values dropped in recovery mode and
+    /// validate-only statuses are not counted.
+    pub fn stats(&self) -> StreamStats {
+        StreamStats {
+            bytes_in: (self.base + self.buf.len()) as u64,
+            ..self.stats
+        }
+    }
+
+    /// Cap the input buffered for a value that has not completed yet, in bytes (0, the
+    /// default, means unlimited).
+    ///
+    /// When a push leaves more than `bytes` buffered, it fails with
+    /// `RepairErrorKind::BufferOverflow` and the incomplete value is discarded, so a stream
+    /// whose value never ends cannot grow memory without bound. Values completed earlier in
+    /// the same chunk are not lost: `push` returns them with its next successful call (or
+    /// `flush`), and the writer variants have already written them.
+    pub fn set_max_buffer(&mut self, bytes: usize) {
+        self.max_buffer = bytes;
+    }
+
+    // Enforce `max_buffer` after a chunk has been scanned: drop the incomplete value and
+    // reset the scanner to the root level.
+    fn check_buffer(&mut self) -> Result<(), RepairError> {
+        let buffered = self.buf.len() - self.seg_start;
+        if self.max_buffer == 0 || buffered <= self.max_buffer {
+            return Ok(());
+        }
+        self.base += self.buf.len();
+        self.buf.clear();
+        self.seg_start = 0;
+        self.scan_pos = 0;
+        self.depth = 0;
+        self.in_string = false;
+        self.escape = false;
+        self.in_line_comment = false;
+        self.in_block_comment = false;
+        self.in_fence = false;
+        self.value_started = false;
+        self.last_sig_end = 0;
+        Err(RepairError::new(
+            RepairErrorKind::BufferOverflow(self.max_buffer),
+            buffered,
+        ))
+    }
+
+    // A `--` comment at byte `i` under `tolerate_sql_comments`. The character after the dashes
+    // must already be buffered, so `--5` split across chunks is not mistaken for one.
+    fn starts_sql_comment(&self, i: usize) -> bool {
+        self.opts.tolerate_sql_comments
+            && i + 2 < self.buf.len()
+            && crate::parser::lex::starts_sql_comment(&self.buf[i..])
+    }
+
+    // Repair one completed root segment, or record its status in validate-only mode.
+    // `start` is the stream offset of the segment's first byte.
+    fn repair_segment(&mut self, segment: &str, start: usize) -> Result<String, RepairError> {
+        self.quiet_since = start + segment.len();
+        if !self.validate_only {
+            let charged = crate::budget::repairs_charged();
+            let res = repair_to_string(segment, &self.opts);
+            if let Ok(fixed) = &res
+                && !fixed.is_empty()
+            {
+                self.stats.values += 1;
+                self.stats.repairs += crate::budget::repairs_charged() - charged;
+                self.stats.bytes_out += fixed.len() as u64;
+            }
+            return match res {
+                Ok(fixed) if self.track_ranges && !fixed.is_empty() => {
+                    let mut text = segment;
+                    crate::parser::lex::skip_ws_and_comments(&mut text, &self.opts);
+                    if let Some(n) = crate::parser::lex::jsonp_prefix_len(text) {
+                        text = text[n..].trim_start_matches(is_whitespace);
+                    }
+                    let start = start + segment.len() - text.len();
+                    self.ranges.push(ValueRange {
+                        value: fixed.clone(),
+                        start,
+                        end: start + text.trim_end_matches(is_whitespace).len(),
+                    });
+                    Ok(fixed)
+                }
+                Err(mut e) if self.recover => {
+                    self.finish_resync();
+                    let lead = segment.len() - segment.trim_start_matches(is_whitespace).len();
+                    e.position = e.position.saturating_sub(lead);
+                    self.resync = Some(ValueStatus {
+                        valid: false,
+                        text: segment.trim_matches(is_whitespace).to_string(),
+                        error: Some(e),
+                    });
+                    Ok(String::new())
+                }
+                res => res,
+            };
+        }
+        let text = segment.trim_matches(is_whitespace);
+        if !text.is_empty() {
+            let error = crate::strict::validate(text).err();
+            self.statuses.push(ValueStatus {
+                valid: error.is_none(),
+                text: text.to_string(),
+                error,
+            });
+        }
+        Ok(String::new())
+    }
+
+    /// Push a chunk and return the validation status of each root value it completes.
+    ///
+    /// Values are checked against strict JSON and reported unchanged; no repair is done.
+    /// Calling this switches the repairer into validate-only mode (as if
+    /// `Options::stream_validate_only` were set), after which `push`/`flush` produce no output.
+    pub fn push_validate(&mut self, chunk: &str) -> Result<Vec<ValueStatus>, RepairError> {
+        self.enter_validate_mode();
+        self.push_inner(chunk)?;
+        Ok(std::mem::take(&mut self.statuses))
+    }
+
+    /// Flush buffered input in validate-only mode and return the remaining statuses.
+    pub fn flush_validate(&mut self) -> Result<Vec<ValueStatus>, RepairError> {
+        self.enter_validate_mode();
+        self.flush_inner()?;
+        Ok(std::mem::take(&mut self.statuses))
+    }
+
+    // Recovery: while resynchronizing at root level, move input up to the next `{` / `[`
+    // into the skipped region. Returns true when input at `i` was consumed.
+    fn resync_consume(&mut self, i: usize) -> bool {
+        if self.depth != 0 || self.value_started || self.in_line_comment || self.in_block_comment {
+            return false;
+        }
+        let Some(region) = self.resync.as_mut() else {
+            return false;
+        };
+        let rest = &self.buf[i..];
+        let end = memchr2(b'{', b'[', rest.as_bytes()).unwrap_or(rest.len());
+        region.text.push_str(&rest[..end]);
+        if end < rest.len() {
+            self.finish_resync();
+        }
+        self.scan_pos = i + end;
+        self.drop_prefix(self.scan_pos);
+        end > 0
+    }
+
+    fn finish_resync(&mut self) {
+        if let Some(mut region) = self.resync.take() {
+            region
+                .text
+                .truncate(region.text.trim_end_matches(is_whitespace).len());
+            self.skipped.push(region);
+        }
+    }
+
+    fn with_bom(&mut self, out: Option<String>) -> Option<String> {
+        match out {
+            Some(s) if self.bom_pending && !s.is_empty() => {
+                self.bom_pending = false;
+                Some(format!("\u{FEFF}{}", s))
+            }
+            other => other,
         }
     }
 
+    fn with_bom_writer<W: Write, T>(
+        &mut self,
+        writer: &mut W,
+        f: impl FnOnce(&mut Self, &mut BomWriter<'_, W>) -> Result<T, RepairError>,
+    ) -> Result<T, RepairError> {
+        let mut w = BomWriter {
+            inner: writer,
+            pending: self.bom_pending,
+        };
+        let res = f(self, &mut w);
+        self.bom_pending = w.pending;
+        res
+    }
+
     // Add a value to string aggregation buffer
     fn agg_add_val_str(&mut self, val: &str) {
+        if val.is_empty() {
+            return;
+        }
         if !self.agg_open {
             self.agg_open = true;
             self.agg_buf.clear();
@@ -92,6 +444,9 @@ impl StreamRepairer {
         writer: &mut W,
         val: &str,
     ) -> Result<(), RepairError> {
+        if val.is_empty() {
+            return Ok(());
+        }
         if !self.agg_open {
             self.agg_open = true;
             writer.write_all(b"[").map_err(|e| {
@@ -124,10 +479,19 @@ impl StreamRepairer {
     /// Returns `Some(String)` when this call produces a complete root-level JSON value;
     /// otherwise returns `None` (no output yet).
     pub fn push(&mut self, chunk: &str) -> Result<Option<String>, RepairError> {
+        let out = self.push_inner(chunk)?;
+        Ok(self.with_bom(out))
+    }
+
+    fn push_inner(&mut self, chunk: &str) -> Result<Option<String>, RepairError> {
         self.buf.push_str(chunk);
-        let mut out = String::new();
+        let mut out = std::mem::take(&mut self.carry);
         let mut i = self.scan_pos;
         while i < self.buf.len() {
+            if self.resync.is_some() && self.resync_consume(i) {
+                i = self.scan_pos;
+                continue;
+            }
             // Root-level helper: drop JSONP prefix like ident '(' (allow spaces)
             if self.depth == 0
                 && !self.in_string
@@ -283,6 +647,10 @@ impl StreamRepairer {
                 self.in_line_comment = true;
                 i += len;
                 continue;
+            } else if ch == '-' && self.starts_sql_comment(i) {
+                self.in_line_comment = true;
+                i += 2;
+                continue;
             } else if ch == '`' && self.depth == 0 {
                 // fenced code block markers ``` at root-level: drop the markers themselves
                 let (c2, l2) = next_char(&self.buf, i + len);
@@ -451,6 +819,11 @@ impl StreamRepairer {
             }
         }
         self.scan_pos = i;
+        self.note_heartbeat();
+        if let Err(e) = self.check_buffer() {
+            self.carry = out;
+            return Err(e);
+        }
         if out.is_empty() {
             Ok(None)
         } else {
@@ -466,10 +839,22 @@ impl StreamRepairer {
         &mut self,
         chunk: &str,
         writer: &mut W,
+    ) -> Result<(), RepairError> {
+        self.with_bom_writer(writer, |this, w| this.push_to_writer_inner(chunk, w))
+    }
+
+    fn push_to_writer_inner<W: Write>(
+        &mut self,
+        chunk: &str,
+        writer: &mut W,
     ) -> Result<(), RepairError> {
         self.buf.push_str(chunk);
         let mut i = self.scan_pos;
         while i < self.buf.len() {
+            if self.resync.is_some() && self.resync_consume(i) {
+                i = self.scan_pos;
+                continue;
+            }
             // Grammar fast-path at root for writer: drop JSONP prefix via lex helper
             if self.depth == 0
                 && !self.in_string
@@ -610,6 +995,10 @@ impl StreamRepairer {
                 self.in_line_comment = true;
                 i += len;
                 continue;
+            } else if ch == '-' && self.starts_sql_comment(i) {
+                self.in_line_comment = true;
+                i += 2;
+                continue;
             } else if ch == '`' && self.depth == 0 {
                 let (c2, l2) = next_char(&self.buf, i + len);
                 let (c3, l3) = next_char(&self.buf, i + len + l2);
@@ -806,12 +1195,19 @@ impl StreamRepairer {
             }
         }
         self.scan_pos = i;
-        Ok(())
+        self.note_heartbeat();
+        self.check_buffer()
     }
 
     /// Flush and write any remaining data into `writer`. If NDJSON aggregation is enabled,
     /// this closes the array.
     pub fn flush_to_writer<W: Write>(&mut self, writer: &mut W) -> Result<(), RepairError> {
+        self.with_bom_writer(writer, |this, w| this.flush_to_writer_inner(w))?;
+        self.finish_resync();
+        Ok(())
+    }
+
+    fn flush_to_writer_inner<W: Write>(&mut self, writer: &mut W) -> Result<(), RepairError> {
         if self.seg_start < self.buf.len() {
             if self.depth == 0
                 && !self.value_started
@@ -820,6 +1216,7 @@ impl StreamRepairer {
                 && !self.in_line_comment
                 && self.last_sig_end <= self.seg_start
             {
+                self.base += self.buf.len();
                 self.buf.clear();
                 self.seg_start = 0;
                 self.scan_pos = 0;
@@ -829,7 +1226,7 @@ impl StreamRepairer {
                 return Ok(());
             }
             let s = self.buf[self.seg_start..].to_string();
-            let fixed = repair_to_string(&s, &self.opts)?;
+            let fixed = self.repair_segment(&s, self.base + self.seg_start)?;
             if self.opts.stream_ndjson_aggregate {
                 self.agg_add_val_writer(writer, &fixed)?;
             } else {
@@ -838,6 +1235,7 @@ impl StreamRepairer {
                 })?;
             }
         }
+        self.base += self.buf.len();
         self.buf.clear();
         self.seg_start = 0;
         self.scan_pos = 0;
@@ -858,6 +1256,52 @@ impl StreamRepairer {
     ///
     /// Returns `Some(String)` when there is final output to emit; otherwise `None`.
     pub fn flush(&mut self) -> Result<Option<String>, RepairError> {
+        let carry = std::mem::take(&mut self.carry);
+        let out = self.flush_inner()?;
+        self.finish_resync();
+        let out = match out {
+            _ if carry.is_empty() => out,
+            Some(s) => Some(carry + &s),
+            None => Some(carry),
+        };
+        Ok(self.with_bom(out))
+    }
+
+    /// Flush the remaining output and reset the stream for the next document.
+    ///
+    /// Equivalent to `flush` followed by replacing the repairer with a new one built from
+    /// the same options, in one call. The `set_recover`, `set_track_ranges`, `set_heartbeat`
+    /// and `set_max_buffer` settings and validate-only mode are kept, as are skipped regions,
+    /// ranges and heartbeats not taken yet, so the ones the flush completed can still be taken. Stats start
+    /// over and the BOM is written again before the next output. The stream is reset even
+    /// when the flush fails.
+    pub fn finalize(&mut self) -> Result<Option<String>, RepairError> {
+        let out = self.flush();
+        self.reset();
+        out
+    }
+
+    fn reset(&mut self) {
+        let mut opts = self.opts.clone();
+        opts.output_bom = self.output_bom;
+        let validate_only = self.validate_only;
+        *self = Self {
+            recover: self.recover,
+            max_buffer: self.max_buffer,
+            track_ranges: self.track_ranges,
+            heartbeat: self.heartbeat,
+            heartbeats: std::mem::take(&mut self.heartbeats),
+            statuses: std::mem::take(&mut self.statuses),
+            skipped: std::mem::take(&mut self.skipped),
+            ranges: std::mem::take(&mut self.ranges),
+            ..Self::new(opts)
+        };
+        if validate_only {
+            self.enter_validate_mode();
+        }
+    }
+
+    fn flush_inner(&mut self) -> Result<Option<String>, RepairError> {
         let mut out = String::new();
         if self.seg_start < self.buf.len() {
             // If nothing meaningful collected at root, skip repairing
@@ -868,6 +1312,7 @@ impl StreamRepairer {
                 && !self.in_line_comment
                 && self.last_sig_end <= self.seg_start
             {
+                self.base += self.buf.len();
                 self.buf.clear();
                 self.seg_start = 0;
                 self.scan_pos = 0;
@@ -879,7 +1324,7 @@ impl StreamRepairer {
                 }
             }
             let s = self.buf[self.seg_start..].to_string();
-            let fixed = repair_to_string(&s, &self.opts)?;
+            let fixed = self.repair_segment(&s, self.base + self.seg_start)?;
             if self.opts.stream_ndjson_aggregate {
                 self.agg_add_val_str(&fixed);
             } else {
@@ -887,6 +1332,7 @@ impl StreamRepairer {
             }
         }
         // reset
+        self.base += self.buf.len();
         self.buf.clear();
         self.seg_start = 0;
         self.scan_pos = 0;
@@ -910,9 +1356,10 @@ impl StreamRepairer {
             return Ok(String::new());
         }
         let segment = self.buf[self.seg_start..end].to_string();
-        let fixed = repair_to_string(&segment, &self.opts)?;
+        let fixed = self.repair_segment(&segment, self.base + self.seg_start)?;
         // drop processed part from buffer to keep memory bounded
         self.buf.drain(..end);
+        self.base += end;
         // adjust indices
         if self.scan_pos >= end {
             self.scan_pos -= end;
@@ -934,6 +1381,7 @@ impl StreamRepairer {
             return;
         }
         self.buf.drain(..end);
+        self.base += end;
         if self.scan_pos >= end {
             self.scan_pos -= end;
         } else {
@@ -948,6 +1396,26 @@ impl StreamRepairer {
     }
 }
 
+// Writer adapter that emits the UTF-8 BOM before the first non-empty write.
+struct BomWriter<'w, W: Write> {
+    inner: &'w mut W,
+    pending: bool,
+}
+
+impl<W: Write> Write for BomWriter<'_, W> {
+    fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+        if self.pending && !buf.is_empty() {
+            self.inner.write_all(b"\xEF\xBB\xBF")?;
+            self.pending = false;
+        }
+        self.inner.write(buf)
+    }
+
+    fn flush(&mut self) -> std::io::Result<()> {
+        self.inner.flush()
+    }
+}
+
 #[inline]
 fn next_char(s: &str, i: usize) -> (char, usize) {
     if i >= s.len() {
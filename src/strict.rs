@@ -0,0 +1,321 @@
+//! Strict RFC 8259 checks for input that is expected to already be valid JSON.
+//!
+//! Used by guard options such as `Options::reject_if_invalid` and by `minify`.
+//! Positions in the returned errors are byte offsets into the checked input.
+
+use crate::error::{RepairError, RepairErrorKind};
+
+/// Validate that `input` is exactly one JSON value surrounded by optional whitespace.
+/// A single leading BOM is tolerated.
+pub(crate) fn validate(input: &str) -> Result<(), RepairError> {
+    let mut v = Validator {
+        b: input.as_bytes(),
+        pos: 0,
+    };
+    if v.b.starts_with(b"\xEF\xBB\xBF") {
+        v.pos = 3;
+    }
+    v.document()
+}
+
+/// Validate `input` and return it with all insignificant whitespace removed.
+/// Tokens (including number spelling and string escapes) are copied verbatim.
+pub(crate) fn minify(input: &str) -> Result<String, RepairError> {
+    validate(input)?;
+    let s = input.strip_prefix('\u{FEFF}').unwrap_or(input);
+    let b = s.as_bytes();
+    let mut out = String::with_capacity(s.len());
+    let mut run = 0usize; // start of the pending run to copy
+    let mut i = 0usize;
+    while i < b.len() {
+        match b[i] {
+            b'"' => {
+                // Validated input: the string ends at the first unescaped quote.
+                i += 1;
+                while b[i] != b'"' {
+                    i += if b[i] == b'\\' { 2 } else { 1 };
+                }
+                i += 1;
+            }
+            b' ' | b'\t' | b'\n' | b'\r' => {
+                out.push_str(&s[run..i]);
+                i += 1;
+                run = i;
+            }
+            _ => i += 1,
+        }
+    }
+    out.push_str(&s[run..]);
+    Ok(out)
+}
+
+/// If `input` is exactly one valid JSON string (surrounding whitespace allowed), return its
+/// decoded content. Returns `None` for any other value or invalid input.
+pub(crate) fn decode_string(input: &str) -> Option<String> {
+    let s = input.trim_matches([' ', '\t', '\n', '\r']);
+    if !s.starts_with('"') || validate(s).is_err() {
+        return None;
+    }
+    unescape(&s[1..s.len() - 1])
+}
+
+/// Decode the JSON escapes of a string body (`\"`, `\\`, `\n`, `\uXXXX`, ...). Bare quotes
+/// are kept as they are; `None` for a malformed escape.
+pub(crate) fn unescape(body: &str) -> Option<String> {
+    let mut out = String::with_capacity(body.len());
+    let mut chars = body.chars();
+    while let Some(c) = chars.next() {
+        if c != '\\' {
+            out.push(c);
+            continue;
+        }
+        match chars.next()? {
+            'n' => out.push('\n'),
+            't' => out.push('\t'),
+            'r' => out.push('\r'),
+            'b' => out.push('\u{8}'),
+            'f' => out.push('\u{c}'),
+            'u' => {
+                let hi = hex4(&mut chars)?;
+                let code = if (0xD800..0xDC00).contains(&hi) {
+                    // Validated input may still carry a lone surrogate; only pair well-formed ones.
+                    let rest = chars.as_str();
+                    match rest
+                        .strip_prefix("\\u")
+                        .and_then(|r| u32::from_str_radix(r.get(..4)?, 16).ok())
+                    {
+                        Some(lo) if (0xDC00..0xE000).contains(&lo) => {
+                            chars = rest[6..].chars();
+                            0x10000 + ((hi - 0xD800) << 10) + (lo - 0xDC00)
+                        }
+                        _ => 0xFFFD,
+                    }
+                } else {
+                    hi
+                };
+                out.push(char::from_u32(code).unwrap_or('\u{FFFD}'));
+            }
+            other => out.push(other), // `"`, `\\` and `/`
+        }
+    }
+    Some(out)
+}
+
+fn hex4(chars: &mut std::str::Chars<'_>) -> Option<u32> {
+    let mut v = 0u32;
+    for _ in 0..4 {
+        v = v * 16 + chars.next()?.to_digit(16)?;
+    }
+    Some(v)
+}
+
+struct Validator<'a> {
+    b: &'a [u8],
+    pos: usize,
+}
+
+impl<'a> Validator<'a> {
+    fn err(&self, msg: &str) -> RepairError {
+        RepairError::new(RepairErrorKind::InvalidJson(msg.to_string()), self.pos)
+    }
+
+    #[inline]
+    fn peek(&self) -> Option<u8> {
+        self.b.get(self.pos).copied()
+    }
+
+    #[inline]
+    fn skip_ws(&mut self) {
+        while let Some(b' ' | b'\t' | b'\n' | b'\r') = self.peek() {
+            self.pos += 1;
+        }
+    }
+
+    fn expect(&mut self, want: u8, msg: &str) -> Result<(), RepairError> {
+        self.skip_ws();
+        if self.peek() == Some(want) {
+            self.pos += 1;
+            Ok(())
+        } else if self.peek().is_none() {
+            Err(self.err("unexpected end of input"))
+        } else {
+            Err(self.err(msg))
+        }
+    }
+
+    fn document(&mut self) -> Result<(), RepairError> {
+        self.value()?;
+        self.skip_ws();
+        if self.pos < self.b.len() {
+            return Err(self.err("trailing characters after value"));
+        }
+        Ok(())
+    }
+
+    // Iterative descent with an explicit container stack so arbitrarily deep
+    // input cannot overflow the native stack.
+    fn value(&mut self) -> Result<(), RepairError> {
+        let mut stack: Vec<u8> = Vec::new();
+        'value: loop {
+            self.skip_ws();
+            match self.peek() {
+                Some(b'{') => {
+                    self.pos += 1;
+                    self.skip_ws();
+                    if self.peek() == Some(b'}') {
+                        self.pos += 1;
+                    } else {
+                        stack.push(b'{');
+                        self.key()?;
+                        continue 'value;
+                    }
+                }
+                Some(b'[') => {
+                    self.pos += 1;
+                    self.skip_ws();
+                    if self.peek() == Some(b']') {
+                        self.pos += 1;
+                    } else {
+                        stack.push(b'[');
+                        continue 'value;
+                    }
+                }
+                Some(b'"') => self.string()?,
+                Some(b'-' | b'0'..=b'9') => self.number()?,
+                Some(b't') => self.literal(b"true")?,
+                Some(b'f') => self.literal(b"false")?,
+                Some(b'n') => self.literal(b"null")?,
+                Some(_) => return Err(self.err("expected a value")),
+                None => return Err(self.err("unexpected end of input")),
+            }
+            // After a complete value: close containers or move to the next member.
+            loop {
+                self.skip_ws();
+                match stack.last() {
+                    None => return Ok(()),
+                    Some(b'{') => match self.peek() {
+                        Some(b',') => {
+                            self.pos += 1;
+                            self.key()?;
+                            continue 'value;
+                        }
+                        Some(b'}') => {
+                            self.pos += 1;
+                            stack.pop();
+                        }
+                        Some(_) => return Err(self.err("expected ',' or '}' after object member")),
+                        None => return Err(self.err("unexpected end of input")),
+                    },
+                    Some(_) => match self.peek() {
+                        Some(b',') => {
+                            self.pos += 1;
+                            continue 'value;
+                        }
+                        Some(b']') => {
+                            self.pos += 1;
+                            stack.pop();
+                        }
+                        Some(_) => return Err(self.err("expected ',' or ']' after array element")),
+                        None => return Err(self.err("unexpected end of input")),
+                    },
+                }
+            }
+        }
+    }
+
+    fn key(&mut self) -> Result<(), RepairError> {
+        self.skip_ws();
+        match self.peek() {
+            Some(b'"') => self.string()?,
+            Some(_) => return Err(self.err("expected a double-quoted object key")),
+            None => return Err(self.err("unexpected end of input")),
+        }
+        self.expect(b':', "expected ':' after object key")
+    }
+
+    fn string(&mut self) -> Result<(), RepairError> {
+        self.pos += 1; // opening quote
+        loop {
+            match self.peek() {
+                None => return Err(self.err("unterminated string")),
+                Some(b'"') => {
+                    self.pos += 1;
+                    return Ok(());
+                }
+                Some(b'\\') => {
+                    self.pos += 1;
+                    match self.peek() {
+                        Some(b'"' | b'\\' | b'/' | b'b' | b'f' | b'n' | b'r' | b't') => {
+                            self.pos += 1
+                        }
+                        Some(b'u') => {
+                            self.pos += 1;
+                            for _ in 0..4 {
+                                match self.peek() {
+                                    Some(h) if h.is_ascii_hexdigit() => self.pos += 1,
+                                    _ => return Err(self.err("invalid unicode escape")),
+                                }
+                            }
+                        }
+                        _ => return Err(self.err("invalid escape sequence")),
+                    }
+                }
+                Some(c) if c < 0x20 => {
+                    return Err(self.err("unescaped control character in string"));
+                }
+                Some(_) => self.pos += 1,
+            }
+        }
+    }
+
+    fn digits(&mut self) -> usize {
+        let start = self.pos;
+        while let Some(b'0'..=b'9') = self.peek() {
+            self.pos += 1;
+        }
+        self.pos - start
+    }
+
+    fn number(&mut self) -> Result<(), RepairError> {
+        if self.peek() == Some(b'-') {
+            self.pos += 1;
+        }
+        match self.peek() {
+            Some(b'0') => {
+                self.pos += 1;
+                if let Some(b'0'..=b'9') = self.peek() {
+                    return Err(self.err("leading zeros are not allowed"));
+                }
+            }
+            Some(b'1'..=b'9') => {
+                self.digits();
+            }
+            _ => return Err(self.err("invalid number")),
+        }
+        if self.peek() == Some(b'.') {
+            self.pos += 1;
+            if self.digits() == 0 {
+                return Err(self.err("expected digits after decimal point"));
+            }
+        }
+        if let Some(b'e' | b'E') = self.peek() {
+            self.pos += 1;
+            if let Some(b'+' | b'-') = self.peek() {
+                self.pos += 1;
+            }
+            if self.digits() == 0 {
+                return Err(self.err("expected digits in exponent"));
+            }
+        }
+        Ok(())
+    }
+
+    fn literal(&mut self, word: &[u8]) -> Result<(), RepairError> {
+        if self.b[self.pos..].starts_with(word) {
+            self.pos += word.len();
+            Ok(())
+        } else {
+            Err(self.err("invalid literal"))
+        }
+    }
+}
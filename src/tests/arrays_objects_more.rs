@@ -106,3 +106,41 @@ fn obj_many_members_with_newlines_and_comments() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v.as_object().unwrap().len(), 20);
 }
+
+#[test]
+fn missing_comma_before_an_abutting_quote() {
+    let cases = [
+        (r#"{"a":1"b":2}"#, r#"{"a":1,"b":2}"#),
+        (r#"{"a":"x""b":"y"}"#, r#"{"a":"x","b":"y"}"#),
+        (r#"["a""b"]"#, r#"["a","b"]"#),
+        (r#"[1"b", -2.5"c"]"#, r#"[1,"b",-2.5,"c"]"#),
+        (
+            r#"{"a":true"b":null"c":[1]"d":1e3"e":2}"#,
+            r#"{"a":true,"b":null,"c":[1],"d":1e3,"e":2}"#,
+        ),
+    ];
+    // A quote after text that is not a whole number is not split off.
+    let not_numbers = [
+        (r#"{"a": 0+"x", "b": 1}"#, r#"{"a":"0+\"x\"","b":1}"#),
+        (r#"{"a":-"x"}"#, r#"{"a":"-\"x\""}"#),
+        (r#"[1+"abc"]"#, r#"["1+\"abc\""]"#),
+        (r#"1+"abc""#, r#""1+\"abc\"""#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+        for (input, want) in not_numbers {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+    }
+}
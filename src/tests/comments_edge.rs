@@ -35,3 +35,184 @@ fn unicode_in_comments_adjacent_to_colon() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v, serde_json::json!({"键":"值"}));
 }
+
+#[test]
+fn trailing_line_comment_after_root_value_keeps_structure() {
+    for s in [
+        "{\"a\":1} // done",
+        "{\"a\":1} // done }",
+        "{\"a\":1 // done\n}",
+        "{\"a\":1} // done\n",
+    ] {
+        let out = crate::repair_to_string(s, &opts()).unwrap();
+        assert_eq!(out, r#"{"a":1}"#, "input={:?}", s);
+    }
+}
+
+#[test]
+fn trailing_line_comment_inside_nested_containers() {
+    let s = "{\"a\": {\"b\": [1, 2] // list\n} // inner\n} // outer";
+    let out = crate::repair_to_string(s, &opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!({"a":{"b":[1,2]}}));
+}
+
+#[test]
+fn trailing_line_comment_between_root_values() {
+    let s = "{\"a\":1} // first\n{\"b\":2} // second";
+    let out = crate::repair_to_string(s, &opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!([{"a":1},{"b":2}]));
+}
+
+#[test]
+fn trailing_comma_hidden_by_comment_before_closer() {
+    for s in [
+        "[1, 2, // last\n]",
+        "[1, 2, /* last */ ]",
+        "[1, 2, # last\n]",
+        "[1, 2, // one\n// two\n]",
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &opts()).unwrap(),
+            "[1,2]",
+            "{s:?}"
+        );
+        let mut buf = Vec::new();
+        crate::repair_to_writer(s, &opts(), &mut buf).unwrap();
+        assert_eq!(String::from_utf8(buf).unwrap(), "[1,2]", "{s:?}");
+    }
+    let s = "{\"a\": [1, 2, // x\n], // y\n\"b\": 2, // z\n}";
+    assert_eq!(
+        crate::repair_to_string(s, &opts()).unwrap(),
+        r#"{"a":[1,2],"b":2}"#
+    );
+}
+
+#[test]
+fn trailing_comma_hidden_by_comment_streaming() {
+    let mut r = crate::StreamRepairer::new(opts());
+    let mut out = String::new();
+    for chunk in ["[1, 2,", " // last", "\n]"] {
+        if let Some(s) = r.push(chunk).unwrap() {
+            out.push_str(&s);
+        }
+    }
+    if let Some(s) = r.flush().unwrap() {
+        out.push_str(&s);
+    }
+    assert_eq!(out, "[1,2]");
+}
+
+#[test]
+fn comment_only_containers_collapse_to_empty() {
+    for (s, want) in [
+        ("{/* todo */}", "{}"),
+        ("[ /* empty */ ]", "[]"),
+        ("{ // todo\n}", "{}"),
+        ("[ // nothing yet\n]", "[]"),
+        ("[ /* a */ // b\n /* c */ ]", "[]"),
+        ("{\"a\": {/* x */}, \"b\": [ // y\n]}", r#"{"a":{},"b":[]}"#),
+        ("{/* todo */", "{}"),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &opts()).unwrap(), want, "{s:?}");
+    }
+}
+
+#[test]
+fn comma_only_containers_collapse_to_empty() {
+    for (s, want) in [
+        ("[,]", "[]"),
+        ("{,}", "{}"),
+        ("[ /* empty */ , ]", "[]"),
+        ("{ // todo\n , }", "{}"),
+        ("[,1]", "[1]"),
+        ("[1,,]", "[1]"),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &opts()).unwrap(), want, "{s:?}");
+    }
+}
+
+fn sql_comments() -> Options {
+    Options {
+        tolerate_sql_comments: true,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn sql_comments_between_entries() {
+    let s = "{\n  -- connection settings\n  host: 'db', -- primary\n  port: 5432 -- it's fixed\n  , user: 'app'\n}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..sql_comments()
+        };
+        let out = crate::repair_to_string(s, &o).unwrap();
+        assert_eq!(
+            out, r#"{"host":"db","port":5432,"user":"app"}"#,
+            "{engine:?}"
+        );
+        let out = crate::repair_to_string("[1, -- one\n2] --", &o).unwrap();
+        assert_eq!(out, "[1,2]", "{engine:?}");
+    }
+    // Off by default: the word after the dashes is a stray token.
+    let err = crate::repair_to_string("[1, -- x\n2]", &opts()).unwrap_err();
+    assert_eq!(err.position, 7);
+    let o = Options {
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..opts()
+    };
+    let out = crate::repair_to_string("[1, -- x\n2]", &o).unwrap();
+    assert_eq!(out, r#"[1,"--","x",2]"#);
+}
+
+#[test]
+fn sql_comments_leave_dashes_in_values() {
+    let o = sql_comments();
+    let out = crate::repair_to_string("{a: --5, b: a--b, c: [-5, -3],}", &o).unwrap();
+    assert_eq!(out, r#"{"a":"--5","b":"a--b","c":[-5,-3]}"#);
+    // A bare value ends where a comment starts after a space.
+    let out = crate::repair_to_string("{a: foo bar -- note\n}", &o).unwrap();
+    assert_eq!(out, r#"{"a":"foo bar"}"#);
+    // Streams treat the comment like a `#` one when splitting values.
+    let mut st = crate::StreamRepairer::new(o);
+    let first = st.push("{a: 1 -- one\n}\n").unwrap();
+    assert_eq!(first.as_deref(), Some(r#"{"a":1}"#));
+    let second = st.push("{b: 2 --").unwrap();
+    assert_eq!(second, None);
+    let rest = st.flush().unwrap();
+    assert_eq!(rest.as_deref(), Some(r#"{"b":2}"#));
+}
+
+#[test]
+fn repair_with_comments_maps_comments_to_pointers() {
+    let s = "// config\n{\n  # who\n  name: 'John', // the name\n  tags: [\n    'a', /* first */\n    // second\n    'b'\n  ],\n  url: http://x.io,\n  \"a/b\": 1 /* slash */\n}";
+    let (out, comments) = crate::repair_with_comments(s, &opts()).unwrap();
+    assert_eq!(
+        out,
+        r#"{"name":"John","tags":["a","b"],"url":"http://x.io","a/b":1}"#
+    );
+    let want = [
+        ("", "config"),
+        ("/name", "who\nthe name"),
+        ("/tags/0", "first"),
+        ("/tags/1", "second"),
+        ("/a~1b", "slash"),
+    ];
+    let want: Vec<_> = want
+        .iter()
+        .map(|&(p, t)| (p.to_string(), t.to_string()))
+        .collect();
+    assert_eq!(comments, want);
+    // Without comments the output matches a plain repair.
+    let (out, comments) = crate::repair_with_comments("{a: [1, 2,]}", &opts()).unwrap();
+    assert_eq!(
+        out,
+        crate::repair_to_string("{a: [1, 2,]}", &opts()).unwrap()
+    );
+    assert!(comments.is_empty());
+}
@@ -80,3 +80,47 @@ fn ns_word_comment_markers_skip_before_key() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v, serde_json::json!({"value_1": true, "value_2": "data"}));
 }
+
+#[test]
+fn ns_stray_bom_between_array_elements() {
+    let s = "[1,\u{FEFF}2\u{FEFF}, 3]";
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!([1, 2, 3]));
+}
+
+#[test]
+fn ns_zero_width_chars_between_tokens() {
+    let s = "{\u{200B}\"a\"\u{2060}: 1,\u{FEFF}\n\"b\": [\u{200D}true]}";
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!({"a": 1, "b": [true]}));
+}
+
+#[test]
+fn ns_zero_width_chars_preserved_inside_strings() {
+    let s = "['a\u{FEFF}b', \"c\u{200B}d\"]";
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!(["a\u{FEFF}b", "c\u{200B}d"]));
+}
+
+#[test]
+fn ns_form_feed_and_vertical_tab_between_tokens() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        let out = crate::repair_to_string("[1,\u{0C}2 \u{0C} 3]", &o).unwrap();
+        assert_eq!(out, "[1,2,3]");
+        let out = crate::repair_to_string("{\u{0B}a\u{0C}:\u{0B}1}", &o).unwrap();
+        assert_eq!(out, r#"{"a":1}"#);
+        // Inside strings they are kept, escaped.
+        let out = crate::repair_to_string("['a\u{0C}b', \"c\u{0B}d\"]", &o).unwrap();
+        assert_eq!(out, r#"["a\fb","c\u000Bd"]"#);
+    }
+}
@@ -50,6 +50,85 @@ fn python_keywords_and_undefined() {
     assert_eq!(v["nope"], false);
 }
 
+#[test]
+fn keywords_in_any_case() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let mut o = Options {
+            engine,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..opts()
+        };
+        for (s, want) in [
+            ("[TRUE, FALSE, NULL]", "[true,false,null]"),
+            ("[tRuE, fAlSe, Null]", "[true,false,null]"),
+            (
+                "{a: True, b: False, c: None}",
+                r#"{"a":true,"b":false,"c":null}"#,
+            ),
+            ("{a: TRUEISH, b: NULLS}", r#"{"a":"TRUEISH","b":"NULLS"}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+        // Python spellings follow allow_python_keywords, other casings this option.
+        o.allow_python_keywords = false;
+        assert_eq!(
+            crate::repair_to_string("[True, TRUE, Null, None]", &o).unwrap(),
+            r#"["True",true,null,"None"]"#,
+            "{engine:?}"
+        );
+        o.allow_python_keywords = true;
+        o.case_insensitive_keywords = false;
+        assert_eq!(
+            crate::repair_to_string("[True, TRUE, Null, None]", &o).unwrap(),
+            r#"[true,"TRUE","Null",null]"#,
+            "{engine:?}"
+        );
+    }
+}
+
+#[test]
+fn abbreviated_keywords_expand_lone_letters() {
+    let o = Options {
+        abbreviated_keywords: true,
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..opts()
+    };
+    for (s, want) in [
+        (
+            "{ok: t, err: f, data: n}",
+            r#"{"ok":true,"err":false,"data":null}"#,
+        ),
+        ("[t, f , n\t]", "[true,false,null]"),
+        ("{a: t\n b: 1}", r#"{"a":true,"b":1}"#),
+        ("f", "false"),
+        // Part of a longer bare word, or a key: left alone.
+        (
+            "[tf, t-shirt, n/a, f.x, t rex]",
+            r#"["tf","t-shirt","n/a","f.x","t rex"]"#,
+        ),
+        ("{t: 1, n: f}", r#"{"t":1,"n":false}"#),
+        ("[T, F, N, 't']", r#"["T","F","N","t"]"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+    // Off by default.
+    let o = Options {
+        abbreviated_keywords: false,
+        ..o
+    };
+    assert_eq!(
+        crate::repair_to_string("[t, f, n]", &o).unwrap(),
+        r#"["t","f","n"]"#
+    );
+}
+
 #[test]
 fn repair_unclosed_braces_and_brackets() {
     let s1 = "{ 'a': 1"; // missing right curly
@@ -117,3 +196,38 @@ fn repair_log_reports_changes() {
     assert_eq!(v["ok"], true);
     assert!(v["bad"].is_null());
 }
+
+#[test]
+fn null_tokens_map_sentinels_to_null() {
+    let o = Options {
+        null_tokens: ["N/A", "-", "--", "NULL", "nil"].map(String::from).to_vec(),
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..opts()
+    };
+    for (s, want) in [
+        (
+            "{price: N/A, qty: -, note: nil}",
+            r#"{"price":null,"qty":null,"note":null}"#,
+        ),
+        ("[NULL, --, 1, -1]", "[null,null,1,-1]"),
+        ("{a: N/A\n b: 2}", r#"{"a":null,"b":2}"#),
+        ("-", "null"),
+        // Not standing alone, quoted, a key, or another casing: left alone.
+        (
+            "[N/A yet, 'N/A', n/a, nils]",
+            r#"["N/A yet","N/A","n/a","nils"]"#,
+        ),
+        ("{N/A: 1}", r#"{"N/A":1}"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+    // Empty by default: the tokens are repaired as bare strings.
+    let o = Options {
+        null_tokens: Vec::new(),
+        ..o
+    };
+    assert_eq!(
+        crate::repair_to_string("[N/A, nil]", &o).unwrap(),
+        r#"["N/A","nil"]"#
+    );
+}
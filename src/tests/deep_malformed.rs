@@ -75,3 +75,99 @@ fn deep_string_concat_with_unicode_and_comments() {
     assert_eq!(v["name"], "你好");
     assert_eq!(v["arr"], serde_json::json!(["ab", "xy"]));
 }
+
+#[test]
+fn deep_nesting_does_not_overflow_the_stack() {
+    let n = 100_000;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options { engine, ..opts() };
+        let closed = format!("{}{}", "[".repeat(n), "]".repeat(n));
+        assert_eq!(crate::repair_to_string(&"[".repeat(n), &o).unwrap(), closed);
+        assert_eq!(crate::repair_to_string(&closed, &o).unwrap(), closed);
+        let out = crate::repair_to_string(&"{a:".repeat(n), &o).unwrap();
+        assert_eq!(out, format!("{}\"\"{}", "{\"a\":".repeat(n), "}".repeat(n)));
+        let mixed = format!("{}1{}", "{\"a\":[".repeat(n), "]}".repeat(n));
+        assert_eq!(crate::repair_to_string(&mixed, &o).unwrap(), mixed);
+        // Typed wrappers recurse, so their nesting is capped.
+        let err = crate::repair_to_string(&"ObjectId(".repeat(n), &o).unwrap_err();
+        assert!(matches!(err.kind, RepairErrorKind::Parse(_)));
+        let depth = crate::parser::MAX_UNWRAP_DEPTH;
+        let wrapped = format!("{}\"x\"", "ObjectId([".repeat(depth));
+        let want = format!("{}\"x\"{}", "[".repeat(depth), "]".repeat(depth));
+        assert_eq!(crate::repair_to_string(&wrapped, &o).unwrap(), want);
+    }
+    // Key chains read as nested objects are kept on the same stack.
+    let o = Options {
+        strictness: crate::options::Strictness::Aggressive,
+        ..opts()
+    };
+    let out = crate::repair_to_string(&"{a: b: ".repeat(n), &o).unwrap();
+    let inner = r#"{"a":"b","":""}"#;
+    let want = format!(
+        "{}{inner}{}",
+        "{\"a\":{\"b\":".repeat(n - 1),
+        "}}".repeat(n - 1)
+    );
+    assert_eq!(out, want);
+}
+
+#[test]
+fn deep_nesting_with_trailing_and_missing_commas() {
+    let n = 50_000;
+    let mut trailing = String::new();
+    let mut missing = String::new();
+    let mut want = String::new();
+    for i in 0..n {
+        trailing.push_str(if i % 2 == 0 { "[1, " } else { "{k: " });
+        missing.push_str(if i % 2 == 0 { "[1 " } else { "{\"k\": " });
+        want.push_str(if i % 2 == 0 { "[1," } else { "{\"k\":" });
+    }
+    trailing.push('0');
+    missing.push('0');
+    want.push('0');
+    for i in (0..n).rev() {
+        trailing.push_str(if i % 2 == 0 { ", 2,]" } else { ", b: 3,}" });
+        missing.push_str(if i % 2 == 0 { " 2]" } else { " \"b\": 3}" });
+        want.push_str(if i % 2 == 0 { ",2]" } else { ",\"b\":3}" });
+    }
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options { engine, ..opts() };
+        assert_eq!(crate::repair_to_string(&trailing, &o).unwrap(), want);
+        assert_eq!(crate::repair_to_string(&missing, &o).unwrap(), want);
+    }
+}
+
+#[test]
+fn deep_nesting_with_dedup_and_sort_keys() {
+    let n = 100_000;
+    let nest = |inner: &str| format!("{}{inner}{}", "{a:".repeat(n), "}".repeat(n));
+    let want = |inner: &str| format!("{}{inner}{}", "{\"a\":".repeat(n), "}".repeat(n));
+    for position in [DedupPosition::First, DedupPosition::Last] {
+        let o = Options {
+            dedup_position: position,
+            ..opts()
+        };
+        let out = crate::repair_to_string(&nest("{b: 1, b: 2}"), &o).unwrap();
+        assert_eq!(out, want(r#"{"b":2}"#));
+        let out = crate::repair_to_string(&"{a:".repeat(n), &o).unwrap();
+        assert_eq!(out, want("\"\""));
+    }
+    let o = Options {
+        dedup_arrays: true,
+        ..opts()
+    };
+    let out = crate::repair_to_string(&nest("[1, 1, [2, 2]]"), &o).unwrap();
+    assert_eq!(out, want("[1,[2]]"));
+    let o = Options {
+        sort_keys: true,
+        ..opts()
+    };
+    let out = crate::repair_to_string(&nest("{c: 1, b: {z: 1, y: 2}}"), &o).unwrap();
+    assert_eq!(out, want(r#"{"b":{"y":2,"z":1},"c":1}"#));
+}
@@ -176,3 +176,360 @@ fn st_fence_plain_no_language_is_ignored() {
     let v: serde_json::Value = serde_json::from_str(&outs[0]).unwrap();
     assert_eq!(v, serde_json::json!({"a":1}));
 }
+
+fn fragments() -> Options {
+    Options {
+        wrap_fragments: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn wrap_fragments_key_value_lines_become_object() {
+    let s = "a = 1\nb = \"x\"\nc = true\n";
+    let out = crate::repair_to_string(s, &fragments()).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":"x","c":true}"#);
+}
+
+#[test]
+fn wrap_fragments_env_style_lines() {
+    let s = "# settings\nexport DB_URL=postgres://u:p@h:5432/db\nNAME = hello world # note\nEMPTY=\nQ=\"a # b\"\n";
+    let out = crate::repair_to_string(s, &fragments()).unwrap();
+    assert_eq!(
+        out,
+        r#"{"DB_URL":"postgres://u:p@h:5432/db","NAME":"hello world","EMPTY":"","Q":"a # b"}"#
+    );
+}
+
+#[test]
+fn wrap_fragments_values_spanning_lines() {
+    for (s, want) in [
+        ("a = [1,\n2]\nb = 2", r#"{"a":[1,2],"b":2}"#),
+        (
+            "a = {\"x\": 1,\n\"y\": 2}\nb = 3",
+            r#"{"a":{"x":1,"y":2},"b":3}"#,
+        ),
+        (
+            "a = [\n  \"it's\",\n  'x'\n]\nb = 1\n",
+            r#"{"a":["it's","x"],"b":1}"#,
+        ),
+        ("q = \"one\ntwo\"\nn = 1", r#"{"q":"one\ntwo","n":1}"#),
+        // A bracket in a comment or an apostrophe in a bare value opens nothing.
+        (
+            "a = 1 # see [x\nb = it's\nc = 2",
+            r#"{"a":1,"b":"it's","c":2}"#,
+        ),
+    ] {
+        let out = crate::repair_to_string(s, &fragments()).unwrap();
+        assert_eq!(out, want, "input {s:?}");
+    }
+}
+
+#[test]
+fn wrap_fragments_quoted_csv_row_becomes_array() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..fragments()
+        };
+        for (s, want) in [
+            (r#""a","b","c""#, r#"["a","b","c"]"#),
+            ("\"a\" , 'b', \"c, d\"\n", r#"["a","b","c, d"]"#),
+            (r#""hello""#, r#""hello""#),
+            (r#""a,b""#, r#""a,b""#),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {s:?}");
+        }
+    }
+}
+
+#[test]
+fn wrap_fragments_leaves_other_input_alone() {
+    for s in ["{\"a\": 1}", "[server]\nport = 80", "x == 1", "hello"] {
+        let wrapped = crate::repair_to_string(s, &fragments());
+        let plain = crate::repair_to_string(s, &Options::default());
+        assert_eq!(wrapped, plain, "input {:?}", s);
+    }
+    // Off by default.
+    let out = crate::repair_to_string("a = 1\nb = 2", &Options::default()).unwrap();
+    assert_ne!(out, r#"{"a":1,"b":2}"#);
+}
+
+fn base64_opts() -> Options {
+    Options {
+        decode_base64: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn decode_base64_then_repair() {
+    let o = base64_opts();
+    // {name: 'John', age: 30,}
+    let out = crate::repair_to_string("e25hbWU6ICdKb2huJywgYWdlOiAzMCx9", &o).unwrap();
+    assert_eq!(out, r#"{"name":"John","age":30}"#);
+    // Padded, truncated array.
+    let out = crate::repair_to_string("WzEsIDIsIDM=\n", &o).unwrap();
+    assert_eq!(out, "[1,2,3]");
+    // Decoded text that is valid JSON is compacted like any other repair.
+    let out = crate::repair_to_string("WzEsIDIsIDNd", &o).unwrap();
+    assert_eq!(out, "[1,2,3]");
+    // MIME-style line breaks inside the payload.
+    let s = "eyJpdGVtcyI6IFsxLCAyLCAzXSwgIm5hbWUiOiAi\r\nbWFpbCBwYXlsb2FkIiwgIm9rIjogdHJ1ZSx9\r\n";
+    let out = crate::repair_to_string(s, &o).unwrap();
+    assert_eq!(out, r#"{"items":[1,2,3],"name":"mail payload","ok":true}"#);
+}
+
+#[test]
+fn decode_base64_falls_back_to_plain_repair() {
+    let o = base64_opts();
+    // Not base64 at all, base64 of a non-container ("just text"), bad length, bare words.
+    for s in [
+        "{a: 1}",
+        "Imp1c3QgdGV4dCI=",
+        "e25hbWU6ICdKb2huJywgYWdlOiAzMCx",
+        "true",
+        "null",
+    ] {
+        let decoded = crate::repair_to_string(s, &o).unwrap();
+        let plain = crate::repair_to_string(s, &Options::default()).unwrap();
+        assert_eq!(decoded, plain, "input {:?}", s);
+    }
+    // Off by default.
+    let out = crate::repair_to_string("WzEsIDIsIDM=", &Options::default()).unwrap();
+    assert_ne!(out, "[1,2,3]");
+}
+
+fn brackets() -> Options {
+    Options {
+        add_missing_brackets: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn add_missing_brackets_object_body() {
+    let o = brackets();
+    let out = crate::repair_to_string(r#""a": 1, "b": 2"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":2}"#);
+    let out = crate::repair_to_string("a: 1, b: [1, 2],\n'c': {d: 'x, y'}", &o).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":[1,2],"c":{"d":"x, y"}}"#);
+    // Off by default: only the leading key survives.
+    let out = crate::repair_to_string(r#""a": 1, "b": 2"#, &Options::default()).unwrap();
+    assert_eq!(out, r#""a""#);
+}
+
+#[test]
+fn add_missing_brackets_array_body() {
+    let o = brackets();
+    let out = crate::repair_to_string("1, 2, 3", &o).unwrap();
+    assert_eq!(out, "[1,2,3]");
+    let out = crate::repair_to_string("'a', \"b,c\", true, {d: 1},", &o).unwrap();
+    assert_eq!(out, r#"["a","b,c",true,{"d":1}]"#);
+}
+
+#[test]
+fn add_missing_brackets_leaves_documents_and_scalars() {
+    let o = brackets();
+    for s in [
+        "{a: 1}, {b: 2}",
+        "[1, 2",
+        "\"a,b\"",
+        "42",
+        "http://x.com/a",
+        "Here is the data: {\"a\": 1}",
+    ] {
+        let wrapped = crate::repair_to_string(s, &o).unwrap();
+        let plain = crate::repair_to_string(s, &Options::default()).unwrap();
+        assert_eq!(wrapped, plain, "input {:?}", s);
+    }
+}
+
+#[test]
+fn unmatched_closer_gets_its_opener() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (input, want) in [
+            (r#""a":1, "b":2}"#, r#"{"a":1,"b":2}"#),
+            ("a: 1, b: {c: [2]}}\n", r#"{"a":1,"b":{"c":[2]}}"#),
+            (r#""a": "}"}"#, r#"{"a":"}"}"#),
+            ("1,2,3]", "[1,2,3]"),
+            ("'x', [1, 2]]", r#"["x",[1,2]]"#),
+            // already valid once opened: compacted like any other repair
+            ("\"a\": [1, 2],\n \"b\": true }", r#"{"a":[1,2],"b":true}"#),
+        ] {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+        // A closer that does not fit the body is not given an opener.
+        for (input, opener) in [(r#""a":1]"#, '['), ("1, 2}", '{')] {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert!(!out.starts_with(opener), "{engine:?}: {input:?} -> {out:?}");
+        }
+    }
+}
+
+#[test]
+fn builtin_typed_wrappers_are_unwrapped() {
+    let s = r#"{_id: ObjectId("507f1f77"), at: ISODate("2020-01-01T00:00:00Z"), n: NumberLong("42"), d: Decimal128('1.50'), i: NumberInt(7)}"#;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Options::default()
+        };
+        let out = crate::repair_to_string(s, &o).unwrap();
+        assert_eq!(
+            out, r#"{"_id":"507f1f77","at":"2020-01-01T00:00:00Z","n":42,"d":1.50,"i":7}"#,
+            "{engine:?}"
+        );
+        // A non-numeric string stays a string; later arguments and an empty call are dropped.
+        let out =
+            crate::repair_to_string(r#"[NumberLong("x"), ObjectId("a", "b"), ObjectId()]"#, &o);
+        assert_eq!(out.unwrap(), r#"["x","a",null]"#, "{engine:?}");
+        // At the root a wrapper is unwrapped, not stripped like a JSONP callback.
+        for (s, want) in [
+            (r#"NumberLong("42")"#, "42"),
+            (r#" ObjectId("x");"#, r#""x""#),
+            (r#"callback(NumberLong("42"))"#, "42"),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {s:?}");
+        }
+    }
+    // Clearing the list leaves such calls to the bare-value repairs.
+    let o = Options {
+        unwrap_functions: Vec::new(),
+        ..Options::default()
+    };
+    let out = crate::repair_to_string(r#"[ObjectId("a")]"#, &o);
+    assert_ne!(out.as_deref(), Ok(r#"["a"]"#));
+}
+
+#[test]
+fn numeric_wrappers_follow_non_finite_handling() {
+    let s = r#"{a: NumberDecimal("NaN"), b: NumberDecimal("Infinity"), c: Decimal128("-Infinity"), d: NumberDecimal(NaN), e: ObjectId("NaN")}"#;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (normalize, want) in [
+            (true, r#"{"a":null,"b":null,"c":null,"d":null,"e":"NaN"}"#),
+            (
+                false,
+                r#"{"a":"NaN","b":"Infinity","c":"-Infinity","d":"NaN","e":"NaN"}"#,
+            ),
+        ] {
+            let o = Options {
+                engine,
+                normalize_js_nonfinite: normalize,
+                ..Options::default()
+            };
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} normalize={normalize}");
+            // The same holds for a wrapper that is the whole document.
+            for (top, raw) in [
+                (r#"NumberDecimal("Infinity")"#, r#""Infinity""#),
+                (r#"NumberDecimal("NaN")"#, r#""NaN""#),
+            ] {
+                let out = crate::repair_to_string(top, &o).unwrap();
+                let want = if normalize { "null" } else { raw };
+                assert_eq!(out, want, "{engine:?} normalize={normalize} {top:?}");
+            }
+        }
+    }
+}
+
+#[test]
+fn custom_typed_wrappers_are_unwrapped() {
+    let mut o = Options::default();
+    o.add_unwrap_function("UUID", UnwrapMode::String);
+    o.add_unwrap_function("Secret", UnwrapMode::Null);
+    o.add_unwrap_function("NumberLong", UnwrapMode::String);
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..o.clone()
+        };
+        let s = r#"{id: UUID("3b241101-e2bb-4255-8caf-4136c566a962"), key: Secret("hunter2"), n: NumberLong(5), u: uuid("x")}"#;
+        let out = crate::repair_to_string(s, &o).unwrap();
+        let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+        assert_eq!(
+            v["id"], "3b241101-e2bb-4255-8caf-4136c566a962",
+            "{engine:?}"
+        );
+        assert_eq!(v["key"], serde_json::Value::Null, "{engine:?}");
+        assert_eq!(v["n"], "5", "{engine:?}");
+        // Names are case-sensitive.
+        assert_ne!(v["u"], "x", "{engine:?}");
+        // A truncated call still yields its argument.
+        let out = crate::repair_to_string(r#"[UUID("ab-cd""#, &o).unwrap();
+        assert_eq!(out, r#"["ab-cd"]"#, "{engine:?}");
+    }
+}
+
+#[test]
+fn extract_embedded_takes_the_first_balanced_span() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            extract_embedded: true,
+            ..Default::default()
+        };
+        for (s, want) in [
+            ("Here is the result: {\"a\":1}. Thanks!", r#"{"a":1}"#),
+            ("Sure! [1,2] and also [3]. Hope that helps.", "[1,2]"),
+            // Brackets inside strings do not end the span.
+            (
+                "Output: {\"s\":\"a } b\",k: 2} (done)",
+                r#"{"s":"a } b","k":2}"#,
+            ),
+            // An opener that is never closed runs to the end.
+            ("Partial: {\"a\": [1, 2], \"b\": 3", r#"{"a":[1,2],"b":3}"#),
+            ("no json here", r#""no json here""#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+}
+
+#[test]
+fn extract_embedded_reports_positions_in_the_input() {
+    let o = Options {
+        extract_embedded: true,
+        stray_tokens: crate::options::StrayTokenPolicy::Error,
+        ..Default::default()
+    };
+    let err = crate::repair_to_string("Here it is: [1, x, 2]", &o).unwrap_err();
+    assert_eq!(err.position, 16);
+    let o = Options {
+        extract_embedded: true,
+        annotate_source: true,
+        output_format: crate::options::OutputFormat::Json5,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string("Here it is: {a: 1}", &o).unwrap();
+    assert_eq!(out, "{a:1/* @src:16 */}/* @src:12 */");
+}
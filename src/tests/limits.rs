@@ -0,0 +1,339 @@
+use super::*;
+use crate::RepairErrorKind;
+
+// Large enough that repairing it takes far longer than 1 ms in any build profile.
+fn slow_input() -> String {
+    let mut s = String::from("[");
+    for i in 0..300_000 {
+        s.push_str(&format!("{{k{}: 'v', n: {}}},", i, i));
+    }
+    s
+}
+
+#[test]
+fn timeout_aborts_long_repair() {
+    let opts = Options {
+        timeout_ms: 1,
+        ..Options::default()
+    };
+    let input = slow_input();
+    let err = crate::repair_to_string(&input, &opts).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::Timeout(1));
+    assert!(err.position > 0 && err.position < input.len());
+    assert!(err.to_string().starts_with("Timed out after 1 ms"));
+}
+
+#[test]
+fn timeout_applies_to_writer_and_log_paths() {
+    let opts = Options {
+        timeout_ms: 1,
+        ..Options::default()
+    };
+    let input = slow_input();
+    let mut sink = Vec::new();
+    let err = crate::repair_to_writer_streaming(&input, &opts, &mut sink).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Timeout(_)));
+    let err = crate::repair_to_string_with_log(&input, &opts).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Timeout(_)));
+}
+
+#[test]
+fn generous_timeout_does_not_change_output() {
+    let opts = Options {
+        timeout_ms: 60_000,
+        ..Options::default()
+    };
+    let s = "{a:1, b:[1,2,{c:'x'}], d:True}";
+    let out = crate::repair_to_string(s, &opts).unwrap();
+    assert_eq!(
+        out,
+        crate::repair_to_string(s, &Options::default()).unwrap()
+    );
+}
+
+#[test]
+fn timeout_bounds_string_punctuation_continuations() {
+    // Punctuation after a string with no quote left used to loop without consuming input.
+    let opts = Options {
+        timeout_ms: 200,
+        ..Options::default()
+    };
+    for (s, want) in [
+        ("\"a\".", r#""a""#),
+        ("\"a\"?", r#""a""#),
+        ("\"\".", r#""""#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &opts).unwrap(), want, "{s}");
+    }
+}
+
+// Options whose progress reports are collected into the returned vector.
+fn recording_progress() -> (
+    Options,
+    std::sync::Arc<std::sync::Mutex<Vec<(usize, usize)>>>,
+) {
+    let reports = std::sync::Arc::new(std::sync::Mutex::new(Vec::new()));
+    let sink = reports.clone();
+    let opts = Options {
+        progress: Some(crate::Progress::new(move |done, total| {
+            sink.lock().unwrap().push((done, total))
+        })),
+        ..Options::default()
+    };
+    (opts, reports)
+}
+
+#[test]
+fn progress_is_bounded_monotonic_and_completes() {
+    let input: String = std::iter::once("[")
+        .chain(std::iter::repeat_n("{a: 1, b: 'x'},", 20_000))
+        .collect();
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let (opts, reports) = recording_progress();
+        let opts = Options { engine, ..opts };
+        crate::repair_to_string(&input, &opts).unwrap();
+        let reports = reports.lock().unwrap();
+        assert!(reports.len() > 10, "{engine:?}: {}", reports.len());
+        assert!(reports.len() <= crate::budget::PROGRESS_REPORTS + 1);
+        assert!(reports.windows(2).all(|w| w[0].0 < w[1].0));
+        assert!(reports.iter().all(|&(_, total)| total == input.len()));
+        assert_eq!(reports.last(), Some(&(input.len(), input.len())));
+    }
+}
+
+#[test]
+fn progress_reports_completion_for_valid_input_and_skips_streams() {
+    let (opts, reports) = recording_progress();
+    crate::repair_to_string(r#"{"a": [1, 2]}"#, &opts).unwrap();
+    assert_eq!(*reports.lock().unwrap(), [(13, 13)]);
+    let mut sink = Vec::new();
+    crate::repair_to_writer_streaming("{a: 1}", &opts, &mut sink).unwrap();
+    assert_eq!(reports.lock().unwrap().last(), Some(&(6, 6)));
+
+    reports.lock().unwrap().clear();
+    let mut stream = crate::StreamRepairer::new(opts);
+    stream.push("{a: 1}\n{b: 2}").unwrap();
+    stream.flush().unwrap();
+    assert!(reports.lock().unwrap().is_empty());
+}
+
+fn max_elements(n: usize) -> Options {
+    Options {
+        max_elements: n,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn max_elements_rejects_a_million_element_array() {
+    let input = format!("[{}]", "0,".repeat(1_000_000));
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let opts = Options {
+            engine,
+            ..max_elements(1000)
+        };
+        let err = crate::repair_to_string(&input, &opts).unwrap_err();
+        assert_eq!(
+            err.kind,
+            RepairErrorKind::TooManyElements(1000),
+            "{engine:?}"
+        );
+        // The 1001st element starts right after 1000 `0,` pairs and the bracket.
+        assert_eq!(err.position, 2001, "{engine:?}");
+    }
+    let opts = max_elements(1_000_000);
+    let out = crate::repair_to_string(&input, &opts).unwrap();
+    assert_eq!(out.len(), input.len() - 1);
+}
+
+#[test]
+fn max_elements_applies_to_each_container() {
+    let opts = max_elements(2);
+    // Two members per container pass, however many containers there are.
+    let out = crate::repair_to_string("{a: [1, 2], b: {c: 1, d: [3, 4]}}", &opts).unwrap();
+    assert_eq!(out, r#"{"a":[1,2],"b":{"c":1,"d":[3,4]}}"#);
+    let err = crate::repair_to_string("{a: 1, b: 2, c: 3}", &opts).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::TooManyElements(2));
+    assert!(
+        err.to_string()
+            .starts_with("Container has more than 2 elements")
+    );
+    let err = crate::repair_to_string("[[1, 2, 3]]", &opts).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::TooManyElements(2));
+    let err = crate::repair_to_string_with_log("[1, 2, 3]", &opts).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::TooManyElements(2));
+    let mut sink = Vec::new();
+    let err = crate::repair_to_writer_streaming("[1, 2, 3]", &opts, &mut sink).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::TooManyElements(2));
+}
+
+fn max_key_len(n: usize) -> Options {
+    Options {
+        max_key_len: n,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn max_key_len_rejects_a_100kb_key() {
+    let key = "k".repeat(100 * 1024);
+    let input = format!(r#"{{"a": 1, "{key}": 2}}"#);
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let opts = Options {
+            engine,
+            ..max_key_len(256)
+        };
+        let err = crate::repair_to_string(&input, &opts).unwrap_err();
+        assert_eq!(err.kind, RepairErrorKind::KeyTooLong(256), "{engine:?}");
+        assert_eq!(err.position, 9, "{engine:?}");
+        assert!(
+            err.to_string()
+                .starts_with("Object key longer than 256 bytes")
+        );
+        let opts = Options {
+            long_keys: crate::options::LongKeyPolicy::Truncate,
+            ..opts
+        };
+        let out = crate::repair_to_string(&input, &opts).unwrap();
+        assert_eq!(
+            out,
+            format!(r#"{{"a":1,"{}":2}}"#, &key[..256]),
+            "{engine:?}"
+        );
+    }
+    // The key fits exactly; valid input is still parsed, so the output is compact.
+    let out = crate::repair_to_string(&input, &max_key_len(key.len())).unwrap();
+    assert_eq!(out, format!(r#"{{"a":1,"{key}":2}}"#));
+}
+
+#[test]
+fn max_key_len_truncates_at_a_char_boundary() {
+    let opts = Options {
+        long_keys: crate::options::LongKeyPolicy::Truncate,
+        ..max_key_len(4)
+    };
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let opts = Options {
+            engine,
+            ..opts.clone()
+        };
+        // "aéé" is five bytes; cutting at four would split the second "é".
+        for (s, want) in [
+            ("{\"a\u{e9}\u{e9}\": 1}", "{\"a\u{e9}\":1}"),
+            (
+                "{abcdef: {ab: 1, abcde: [2]}}",
+                r#"{"abcd":{"ab":1,"abcd":[2]}}"#,
+            ),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &opts).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+    // The limit counts the decoded key, not its escapes.
+    let out = crate::repair_to_string(r#"{"\u0041\u0042": 1}"#, &max_key_len(2)).unwrap();
+    assert_eq!(out, r#"{"AB":1}"#);
+    let mut stream = crate::StreamRepairer::new(max_key_len(2));
+    let err = stream.push("{abc: 1}\n").unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::KeyTooLong(2));
+}
+
+fn max_repairs(n: usize) -> Options {
+    Options {
+        max_repairs: n,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn max_repairs_allows_input_within_limit() {
+    // Two unquoted keys and one single-quoted value: three repairs.
+    let input = "{a: 1, b: 'x'}";
+    let out = crate::repair_to_string(input, &max_repairs(3)).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":"x"}"#);
+    // Valid JSON needs no repairs at all.
+    assert!(crate::repair_to_string(r#"{"a": [1, 2]}"#, &max_repairs(1)).is_ok());
+}
+
+#[test]
+fn max_repairs_rejects_input_over_limit() {
+    let input = "{a: 1, b: 'x'}";
+    let err = crate::repair_to_string(input, &max_repairs(2)).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::TooManyRepairs(2));
+    assert!(err.position < input.len());
+    assert!(err.to_string().starts_with("More than 2 repairs needed"));
+}
+
+#[test]
+fn max_repairs_flags_mostly_unquoted_input() {
+    // Every key and value needs quoting: six repairs.
+    let prose = "{name: bob, age: unknown, city: paris}";
+    let err = crate::repair_to_string(prose, &max_repairs(3)).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::TooManyRepairs(3)));
+    // Unlimited by default.
+    assert!(crate::repair_to_string(prose, &Options::default()).is_ok());
+}
+
+#[test]
+fn max_repairs_counts_structural_fixes() {
+    // Missing colon, two missing commas, and an unterminated array and object.
+    let input = r#"{"a" 1 "b": [1 2"#;
+    assert!(crate::repair_to_string(input, &max_repairs(5)).is_ok());
+    let err = crate::repair_to_string(input, &max_repairs(4)).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::TooManyRepairs(4)));
+}
+
+#[test]
+fn max_input_bytes_rejects_input_over_the_cap_up_front() {
+    let input = "{a: 1, b: [1, 2]}";
+    let capped = |n| Options {
+        max_input_bytes: n,
+        ..Options::default()
+    };
+    // Exactly at the cap, and just under it, the input is repaired.
+    let want = r#"{"a":1,"b":[1,2]}"#;
+    assert_eq!(
+        crate::repair_to_string(input, &capped(input.len())).unwrap(),
+        want
+    );
+    assert_eq!(
+        crate::repair_to_string(input, &capped(input.len() + 1)).unwrap(),
+        want
+    );
+    // One byte over fails before any work, even when the input would not repair.
+    let n = input.len() - 1;
+    let err = crate::repair_to_string(input, &capped(n)).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::InputTooLarge(n));
+    assert_eq!(err.position, n);
+    assert!(err.to_string().starts_with("Input longer than 16 bytes"));
+    let err = crate::repair_to_string("\u{0}\u{1}[[[[", &capped(3)).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::InputTooLarge(3));
+    // Entry points that split the input check the whole of it.
+    let o = capped(10);
+    for err in [
+        crate::repair_split("{a: 1} {b: 2}", &o).unwrap_err(),
+        crate::repair_first("{a: 1} {b: 2}", &o).unwrap_err(),
+    ] {
+        assert_eq!(err.kind, RepairErrorKind::InputTooLarge(10));
+    }
+    let o = Options {
+        lines_to_array: true,
+        ..capped(10)
+    };
+    let err = crate::repair_to_string("{a: 1}\n{b: 2}", &o).unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::InputTooLarge(10));
+}
@@ -17,3 +17,91 @@ fn logging_path_key_with_space() {
     }
     assert!(ok);
 }
+
+#[test]
+fn logged_messages_are_listed_categories() {
+    let cats = crate::repair_categories();
+    let mut sorted = cats.to_vec();
+    sorted.sort_unstable();
+    sorted.dedup();
+    assert_eq!(sorted.len(), cats.len());
+    let opts = Options {
+        logging: true,
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Default::default()
+    };
+    for input in [
+        "{a 1 'b': [1 2, None",
+        "{\"a\": undefined, b, c: NaN]",
+        "[x y]}",
+    ] {
+        let (_out, log) = crate::repair_to_string_with_log(input, &opts).unwrap();
+        assert!(!log.is_empty(), "{input}");
+        for e in log {
+            assert!(cats.contains(&e.message), "{}", e.message);
+        }
+    }
+}
+
+#[test]
+fn trace_records_parser_decisions() {
+    let trace = crate::Trace::new();
+    let opts = Options {
+        trace: Some(trace.clone()),
+        ..Default::default()
+    };
+    let input = "{a: 1 b: [1, 'x'], c}";
+    let out = crate::repair_to_string(input, &opts).unwrap();
+    assert_eq!(
+        out,
+        crate::repair_to_string(input, &Options::default()).unwrap()
+    );
+    assert_eq!(
+        trace.take(),
+        "@0 value: object\n\
+         @1 repair: quoted unquoted key\n\
+         @1 key \"a\"\n\
+         @4 value: number\n\
+         @6 repair: inserted missing comma\n\
+         @6 repair: quoted unquoted key\n\
+         @6 key \"b\"\n\
+         @9 value: array\n\
+         @10 value: number\n\
+         @13 value: string\n\
+         @13 repair: converted single-quoted string\n\
+         @17 end of array\n\
+         @19 repair: quoted unquoted key\n\
+         @19 key \"c\"\n\
+         @20 repair: inserted missing colon\n\
+         @20 repair: inserted missing value\n\
+         @21 end of object\n"
+    );
+    // `take` clears the buffer.
+    assert_eq!(trace.take(), "");
+
+    crate::repair_to_string("[1, 2]", &opts).unwrap();
+    assert_eq!(trace.take(), "@0 valid JSON: parser skipped\n");
+
+    assert!(crate::repair_to_string("{a: b: c}", &opts).is_err());
+    assert!(
+        trace
+            .take()
+            .ends_with("@4 error: object value followed by a colon at position 4\n")
+    );
+}
+
+#[cfg(feature = "llm-compat")]
+#[test]
+fn trace_is_empty_for_llm_engine() {
+    let trace = crate::Trace::new();
+    let opts = Options {
+        engine: crate::options::EngineKind::LlmCompat,
+        trace: Some(trace.clone()),
+        ..Default::default()
+    };
+    assert_eq!(
+        crate::repair_to_string("{a: 1}", &opts).unwrap(),
+        r#"{"a":1}"#
+    );
+    assert_eq!(trace.take(), "");
+}
@@ -115,3 +115,48 @@ fn ns_logging_path_array_of_arrays() {
     }
     assert!(saw);
 }
+
+#[test]
+fn ns_affected_paths_list_each_repaired_value_once() {
+    let input = r#"{"user": {"name": 'Ann', "nick": 'A'}, "tags": ["a", tru, 'b'], "n": 1"#;
+    let o = Options {
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Options::default()
+    };
+    let (out, paths) = crate::repair_affected_paths(input, &o).unwrap();
+    assert_eq!(
+        out,
+        r#"{"user":{"name":"Ann","nick":"A"},"tags":["a","tru","b"],"n":1}"#
+    );
+    assert_eq!(
+        paths,
+        [
+            r#"$["user"]["name"]"#,
+            r#"$["user"]["nick"]"#,
+            r#"$["tags"][1]"#,
+            r#"$["tags"][2]"#,
+            // Closing the object is a repair of the root's own syntax.
+            "$",
+        ]
+    );
+    // Keys and commas are repairs of the container holding them, reported once.
+    let (_, paths) =
+        crate::repair_affected_paths("[{a: 1, b: 2 c: 3}]", &Options::default()).unwrap();
+    assert_eq!(paths, ["$[0]"]);
+    // Valid input has nothing to review.
+    let (out, paths) = crate::repair_affected_paths(r#"{"a": [1]}"#, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":[1]}"#);
+    assert!(paths.is_empty());
+}
+
+#[test]
+fn ns_affected_paths_of_deep_nesting_are_listed_once_each() {
+    // Every level repairs its key and its missing closer: one path per level.
+    let depth = 2000;
+    let input = "{a: ".repeat(depth);
+    let (_, paths) = crate::repair_affected_paths(&input, &Options::default()).unwrap();
+    assert_eq!(paths.len(), depth);
+    assert_eq!(paths[0], "$");
+    assert_eq!(paths[2], r#"$["a"]["a"]"#);
+    assert_eq!(paths[depth - 1].len(), 1 + 5 * (depth - 1));
+}
@@ -59,6 +59,7 @@ mod core_non_streaming;
 mod deep_malformed;
 mod file_operations;
 mod jsonp_fence;
+mod limits;
 mod logging_more;
 mod logging_path;
 mod ndjson;
@@ -66,6 +67,7 @@ mod non_streaming_misc;
 mod numbers;
 mod numbers_more;
 mod objects_arrays;
+mod output_format;
 mod python_compat;
 mod python_parity;
 mod python_parity_deep;
@@ -76,4 +78,5 @@ mod stream_fuzz_large;
 mod streaming;
 mod strings_escapes_more;
 mod strings_regex_concat;
+mod validation;
 mod writer_streaming_more;
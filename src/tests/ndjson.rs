@@ -117,3 +117,220 @@ fn st_ndjson_numbers_only_many_small_chunks() {
     let arr = v.as_array().expect("array");
     assert!(!arr.is_empty());
 }
+
+#[test]
+fn repair_split_returns_each_root_value() {
+    let o = Options::default();
+    let values = crate::repair_split("{a:1}{b:2}[1,2]", &o).unwrap();
+    assert_eq!(values, [r#"{"a":1}"#, r#"{"b":2}"#, "[1,2]"]);
+    // Whitespace, newlines, commas and comments between values are tolerated.
+    let values = crate::repair_split("{a:1},\n// next\n{b:2} , [1, 2,]\n\n'x', 3,", &o).unwrap();
+    assert_eq!(values, [r#"{"a":1}"#, r#"{"b":2}"#, "[1,2]", r#""x""#, "3"]);
+    // A bare word after a container is trailing narrative, as in repair_to_string.
+    let values = crate::repair_split("{a:1} hope this helps", &o).unwrap();
+    assert_eq!(values, [r#"{"a":1}"#]);
+    assert!(crate::repair_split("  \n", &o).unwrap().is_empty());
+}
+
+#[test]
+fn repair_split_applies_options_per_value() {
+    let o = Options {
+        force_container: crate::ForceContainer::Array,
+        dedup_position: crate::DedupPosition::First,
+        ..Default::default()
+    };
+    let values = crate::repair_split("{a: 1, a: 2} 5 [3", &o).unwrap();
+    assert_eq!(values, [r#"{"a":2}"#, "[5]", "[3]"]);
+}
+
+#[test]
+fn repair_first_reports_bytes_consumed() {
+    let o = Options::default();
+    let input = "  {id: 1, op: 'ping'}{id: 2";
+    let (value, consumed) = crate::repair_first(input, &o).unwrap();
+    assert_eq!(value, r#"{"id":1,"op":"ping"}"#);
+    assert_eq!(&input[consumed..], "{id: 2");
+    // Junk after the value is never parsed, even when it could not be repaired.
+    let (value, consumed) = crate::repair_first("[1, 2,]\x00\x01 {{{", &o).unwrap();
+    assert_eq!((value.as_str(), consumed), ("[1,2]", 7));
+    let (value, consumed) = crate::repair_first("/* c */ 42, 43", &o).unwrap();
+    assert_eq!((value.as_str(), consumed), ("42", 10));
+    let err = crate::repair_first(" \n", &o).unwrap_err();
+    assert_eq!(err.kind, crate::RepairErrorKind::UnexpectedEnd);
+}
+
+#[test]
+fn repair_first_counts_bytes_of_the_input_before_rewrites() {
+    let first = |input: &str, o: &Options| crate::repair_first(input, o).unwrap();
+    let o = Options {
+        extract_embedded: true,
+        ..Default::default()
+    };
+    let input = "Here it is: {a: 1} more {b: 2}";
+    assert_eq!(first(input, &o), (r#"{"a":1}"#.to_string(), 18));
+    let o = Options {
+        add_missing_brackets: true,
+        ..Default::default()
+    };
+    assert_eq!(
+        first("a: 1, b: 2", &o),
+        (r#"{"a":1,"b":2}"#.to_string(), 10)
+    );
+    // An unopened body gets its opener first, and the count includes its closer.
+    let o = Options::default();
+    assert_eq!(first(r#""a": 1}"#, &o), (r#"{"a":1}"#.to_string(), 7));
+    let o = Options {
+        parens_as_arrays: true,
+        ..Default::default()
+    };
+    // The value found is valid JSON by then, so it is copied through as written.
+    assert_eq!(first("(1, 2) (3)", &o), ("[1, 2]".to_string(), 6));
+}
+
+#[test]
+fn repair_with_end_stops_before_trailing_junk() {
+    let o = Options::default();
+    let end = |input: &str, o: &Options| crate::repair_to_string_with_end(input, o).unwrap();
+    // Narrative after an object is ignored by the repair and not counted.
+    let input = "{a: 1} trailing junk";
+    assert_eq!(end(input, &o), (r#"{"a":1}"#.to_string(), 6));
+    let input = "{\"a\": 1}\n{b: 2,}\n\x00\x01 {{{";
+    let (out, at) = end(input, &o);
+    assert_eq!(out, r#"[{"a":1},{"b":2}]"#);
+    assert_eq!(&input[at..], "\n\x00\x01 {{{");
+    // The offset is in the input as given: narrative, fences and BOM before the value count.
+    assert_eq!(end("Here: {a: 1} thanks", &o).1, 12);
+    assert_eq!(end("```json\n[1, 2]\n``` done", &o).1, 14);
+    assert_eq!(end("\u{FEFF}[1]", &o).1, 6);
+    assert_eq!(end(" \n", &o), (String::new(), 0));
+    // With stop_after_first the offset is the end of the first value, as in repair_first.
+    let first = Options {
+        stop_after_first: true,
+        ..Default::default()
+    };
+    let input = "  {id: 1}{id: 2";
+    assert_eq!(
+        end(input, &first).1,
+        crate::repair_first(input, &o).unwrap().1
+    );
+}
+
+#[test]
+fn repair_with_end_counts_bytes_of_the_input_before_rewrites() {
+    let end = |input: &str, o: &Options| crate::repair_to_string_with_end(input, o).unwrap();
+    let o = Options {
+        add_missing_brackets: true,
+        ..Default::default()
+    };
+    assert_eq!(end("a: 1, b: 2", &o), (r#"{"a":1,"b":2}"#.to_string(), 10));
+    let o = Options {
+        parens_as_arrays: true,
+        ..Default::default()
+    };
+    assert_eq!(end("(1, 2), (3, 4)", &o), ("[[1,2],[3,4]]".to_string(), 14));
+    assert_eq!(end("(1, 2)\n", &o), ("[1,2]".to_string(), 6));
+    // An unopened body: its closer is the last byte of the input that was used.
+    let o = Options::default();
+    assert_eq!(end("  1, 2, 3]\n", &o), ("[1,2,3]".to_string(), 10));
+    assert_eq!(end(r#""a": 1}"#, &o), (r#"{"a":1}"#.to_string(), 7));
+}
+
+#[test]
+fn lines_to_array_collects_one_value_per_line() {
+    let input = "{\"a\":1}\n{b: 2}\n\n  \n[1, 2,\r\n'text'\n";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            lines_to_array: true,
+            ..Default::default()
+        };
+        let out = crate::repair_to_string(input, &o).unwrap();
+        assert_eq!(out, r#"[{"a":1},{"b":2},[1,2],"text"]"#, "{engine:?}");
+        assert_eq!(crate::repair_to_string("\n\n", &o).unwrap(), "[]");
+    }
+    // A line left open does not run into the next one, as it does without the option.
+    let o = Options {
+        lines_to_array: true,
+        ..Default::default()
+    };
+    for (input, joined, lines) in [
+        ("{a: 1\n{b: 2}", r#"{"a":1,"b":2}"#, r#"[{"a":1},{"b":2}]"#),
+        ("[1, 2\n[3]", "[1,2,[3]]", "[[1,2],[3]]"),
+        (
+            "{a: 'x\n{b: 2}",
+            r#"{"a":"x\n{b: 2"}"#,
+            r#"[{"a":"x"},{"b":2}]"#,
+        ),
+    ] {
+        let out = crate::repair_to_string(input, &Options::default()).unwrap();
+        assert_eq!(out, joined);
+        assert_eq!(crate::repair_to_string(input, &o).unwrap(), lines);
+    }
+}
+
+#[test]
+fn lines_to_array_salvages_unrepairable_lines() {
+    // The third line needs more than `max_repairs` fixes; a comment-only line is skipped.
+    let input = "{\"a\":1}\n{b: 2}\n{x y z w v}\n// note\n[1, 2,\n";
+    let opts = |salvage| Options {
+        lines_to_array: true,
+        max_repairs: 2,
+        salvage,
+        ..Default::default()
+    };
+    let err = crate::repair_to_string(input, &opts(crate::SalvagePolicy::Fail)).unwrap_err();
+    assert!(matches!(
+        err.kind,
+        crate::RepairErrorKind::TooManyRepairs(2)
+    ));
+    assert!((15..26).contains(&err.position), "{}", err.position);
+    let out = crate::repair_to_string(input, &opts(crate::SalvagePolicy::Null)).unwrap();
+    assert_eq!(out, r#"[{"a":1},{"b":2},null,[1,2]]"#);
+    let out = crate::repair_to_string(input, &opts(crate::SalvagePolicy::Marker)).unwrap();
+    assert_eq!(
+        out,
+        r#"[{"a":1},{"b":2},{"$unrepairable":"{x y z w v}"},[1,2]]"#
+    );
+    // Layout options apply to the whole array.
+    let o = Options {
+        lines_to_array: true,
+        compact_spacing: crate::CompactSpacing::Minimal,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string("{a:1}\n[1,2]", &o).unwrap();
+    assert_eq!(out, r#"[{"a": 1}, [1, 2]]"#);
+}
+
+#[test]
+fn stop_after_first_ignores_the_remainder() {
+    let o = Options {
+        stop_after_first: true,
+        ..Default::default()
+    };
+    for (input, want) in [
+        ("{a: 1} {b: 2}", r#"{"a":1}"#),
+        (r#"{"a": 1}, {"b": 2}"#, r#"{"a":1}"#),
+        ("1 2 3", "1"),
+        ("[1, 2,] \x00\x01 {{{", "[1,2]"),
+        ("```json\n{a: 1}\n```\n```json\n{b: 2}\n```", r#"{"a":1}"#),
+        ("{a: 1", r#"{"a":1}"#),
+        // An unopened body gets its opener first, so it is the first value.
+        ("1, 2] 3", "[1,2]"),
+        (r#""a": 1} {"b": 2}"#, r#"{"a":1}"#),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(input, &o).unwrap(),
+            want,
+            "{input:?}"
+        );
+        let mut out = Vec::new();
+        crate::repair_to_writer_streaming(input, &o, &mut out).unwrap();
+        assert_eq!(String::from_utf8(out).unwrap(), want, "writer: {input:?}");
+    }
+    // Without the option the remainder is parsed and the values are wrapped in an array.
+    let out = crate::repair_to_string("{a: 1} {b: 2}", &Options::default()).unwrap();
+    assert_eq!(out, r#"[{"a":1},{"b":2}]"#);
+}
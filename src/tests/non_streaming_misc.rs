@@ -16,6 +16,41 @@ fn ns_js_nonfinite_to_null() {
     assert_eq!(v, serde_json::json!({"x":null, "y":null, "z":null}));
 }
 
+#[test]
+fn ns_keywords_only_match_whole_words() {
+    let cases = [
+        (r#"{"x": true,}"#, r#"{"x":true}"#),
+        (r#"{"x": truefoo,}"#, r#"{"x":"truefoo"}"#),
+        (
+            r#"{"x": nullish, "y": falsey}"#,
+            r#"{"x":"nullish","y":"falsey"}"#,
+        ),
+        (
+            r#"{"x": true-ish, "y": null.x, "z": NaNx}"#,
+            r#"{"x":"true-ish","y":"null.x","z":"NaNx"}"#,
+        ),
+        (
+            "[true, truefoo, nullish, falsey, null]",
+            r#"[true,"truefoo","nullish","falsey",null]"#,
+        ),
+        ("[false/*c*/, True, None]", "[false,true,null]"),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input}");
+        }
+    }
+}
+
 #[test]
 fn test_empty_input() {
     let opts = Options::default();
@@ -76,3 +111,102 @@ fn ns_writer_roundtrip() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v, serde_json::json!({"a":1, "b":"x", "arr":[1,2,3]}));
 }
+
+fn utf16_bytes(s: &str, endian: Utf16Endian) -> Vec<u8> {
+    s.encode_utf16()
+        .flat_map(|u| match endian {
+            Utf16Endian::Little => u.to_le_bytes(),
+            Utf16Endian::Big => u.to_be_bytes(),
+        })
+        .collect()
+}
+
+#[test]
+fn ns_utf16_with_bom_overrides_endian() {
+    let opts = Options::default();
+    let mut le = vec![0xFF, 0xFE];
+    le.extend(utf16_bytes("{a:'统一码 😀'}", Utf16Endian::Little));
+    let mut be = vec![0xFE, 0xFF];
+    be.extend(utf16_bytes("{a:'统一码 😀'}", Utf16Endian::Big));
+    let want = "{\"a\":\"统一码 😀\"}";
+    assert_eq!(
+        crate::repair_utf16(&le, Utf16Endian::Big, &opts).unwrap(),
+        want
+    );
+    assert_eq!(
+        crate::repair_utf16(&be, Utf16Endian::Little, &opts).unwrap(),
+        want
+    );
+}
+
+#[test]
+fn ns_utf16_without_bom_uses_explicit_endian() {
+    let opts = Options::default();
+    let be = utf16_bytes("[1, 2,", Utf16Endian::Big);
+    assert_eq!(
+        crate::repair_utf16(&be, Utf16Endian::Big, &opts).unwrap(),
+        "[1,2]"
+    );
+    assert_eq!(
+        crate::repair_utf16(&[], Utf16Endian::Little, &opts).unwrap(),
+        ""
+    );
+}
+
+#[test]
+fn ns_utf16_malformed_input_errors() {
+    let opts = Options::default();
+    let err = crate::repair_utf16(&[b'{', 0, b'}'], Utf16Endian::Little, &opts).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Parse(_)));
+    assert_eq!(err.position, 2);
+
+    // `[` followed by a lone high surrogate.
+    let err = crate::repair_utf16(&[b'[', 0, 0x3D, 0xD8], Utf16Endian::Little, &opts).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Parse(_)));
+    assert_eq!(err.position, 2);
+}
+
+#[test]
+fn ns_utf8_strictness_rejects_or_replaces_overlong_encodings() {
+    use crate::options::Utf8Strictness;
+    // Overlong `/` in two, three and four bytes, an encoded surrogate, a code point above
+    // U+10FFFF, an overlong `"` that would end the string early, and a truncated sequence.
+    let bad: [&[u8]; 7] = [
+        b"\xC0\xAF",
+        b"\xE0\x80\xAF",
+        b"\xF0\x80\x80\xAF",
+        b"\xED\xA0\x80",
+        b"\xF4\x90\x80\x80",
+        b"\xC0\xA2",
+        b"\xE2\x82",
+    ];
+    let lenient = Options {
+        utf8_strictness: Utf8Strictness::Lenient,
+        ..Options::default()
+    };
+    for seq in bad {
+        let mut input = b"{a: 'x".to_vec();
+        input.extend_from_slice(seq);
+        input.extend_from_slice(b"'}");
+        let err = crate::repair_bytes(&input, &Options::default()).unwrap_err();
+        assert!(matches!(err.kind, RepairErrorKind::Parse(_)), "{seq:?}");
+        assert_eq!(err.position, 6, "{seq:?}");
+        let out = crate::repair_bytes(&input, &lenient).unwrap();
+        let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+        let a = v["a"].as_str().unwrap();
+        assert!(
+            a.starts_with('x') && a[1..].chars().all(|c| c == '\u{FFFD}'),
+            "{seq:?}: {a:?}"
+        );
+    }
+    let out = crate::repair_bytes(b"['\xC0\xAF']", &lenient).unwrap();
+    assert_eq!(out, "[\"\u{FFFD}\u{FFFD}\"]");
+    // Valid input is the same either way.
+    let input = "{a: '统一码 😀'}".as_bytes();
+    for opts in [Options::default(), lenient] {
+        assert_eq!(
+            crate::repair_bytes(input, &opts).unwrap(),
+            "{\"a\":\"统一码 😀\"}"
+        );
+    }
+}
@@ -46,6 +46,95 @@ fn malformed_exponent_signs_parsed_base() {
     assert_eq!(v["n"], 2);
 }
 
+#[test]
+fn malformed_exponent_forms_repair_to_valid_numbers() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options { engine, ..opts() };
+        for (s, want) in [
+            // a sign and no mantissa digits: zero
+            ("[-E5]", "[0]"),
+            ("[-.e+1]", "[0]"),
+            // no fraction digits before the exponent: the dot is dropped
+            ("[1.E3]", "[1e3]"),
+            ("[-1.e-3]", "[-1e-3]"),
+            // no exponent digits: the base number
+            ("[1e]", "[1]"),
+            ("[1E+]", "[1]"),
+            ("[1.e]", "[1.0]"),
+            // only a missing leading zero
+            ("[.5E2]", "[0.5E2]"),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert!(crate::minify(&out).is_ok(), "{engine:?} {s}: {out}");
+            // The LLM engine writes every exponent with a lowercase `e`.
+            assert_eq!(out.to_lowercase(), want.to_lowercase(), "{engine:?} {s}");
+        }
+    }
+    let out = crate::repair_to_string("{a: -E5, b: 1.E3, c: 1e}", &opts()).unwrap();
+    assert_eq!(out, r#"{"a":0,"b":1e3,"c":1}"#);
+}
+
+#[test]
+fn exponent_shaped_bare_words_stay_strings() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..opts()
+        };
+        for (s, want) in [
+            (r#"{"move": e5}"#, r#"{"move":"e5"}"#),
+            (r#"{"grid": E1, "n": 1}"#, r#"{"grid":"E1","n":1}"#),
+            (r#"{"note": e4 e5}"#, r#"{"note":"e4 e5"}"#),
+            ("[e1, E2, .e5]", r#"["e1","E2",".e5"]"#),
+            ("e5", r#""e5""#),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {s}");
+        }
+    }
+}
+
+#[test]
+fn incomplete_exponent_kept_as_string_when_not_tolerated() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            number_tolerance_incomplete_exponent: false,
+            ..opts()
+        };
+        let out = crate::repair_to_string("{a: 1e, b: 1E+, c: 2e5}", &o).unwrap();
+        assert_eq!(out, r#"{"a":"1e","b":"1E+","c":2e5}"#, "{engine:?}");
+    }
+}
+
+#[test]
+fn bare_exponent_kept_as_string_when_not_tolerated() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options { engine, ..opts() };
+        let out = crate::repair_to_string("[-E5, -.e+1]", &o).unwrap();
+        assert_eq!(out, "[0,0]", "{engine:?}");
+        let o = Options {
+            number_tolerance_incomplete_exponent: false,
+            ..o
+        };
+        let out = crate::repair_to_string("[-E5, -.e+1]", &o).unwrap();
+        assert_eq!(out, r#"["-E5","-.e+1"]"#, "{engine:?}");
+    }
+}
+
 #[test]
 fn tolerate_leading_trailing_dot_with_unicode_adjacent() {
     let s = "{'名':.5, '值':1.}";
@@ -54,3 +143,352 @@ fn tolerate_leading_trailing_dot_with_unicode_adjacent() {
     assert_eq!(v["名"], 0.5);
     assert_eq!(v["值"], 1.0);
 }
+
+#[test]
+fn leading_plus_sign_is_stripped() {
+    let out = crate::repair_to_string(r#"{"a": +5, "b": +1.2e3}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"a":5,"b":1.2e3}"#);
+    let out = crate::repair_to_string("[+1, +.5, -2]", &opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!([1, 0.5, -2]));
+    assert_eq!(crate::repair_to_string("+7", &opts()).unwrap(), "7");
+}
+
+#[test]
+fn signed_non_finite_numbers_are_normalized() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options { engine, ..opts() };
+        let s = "{a: +Infinity, b: -Infinity, c: +NaN, d: -NaN, e: [+Infinity]}";
+        let out = crate::repair_to_string(s, &o).unwrap();
+        assert_eq!(
+            out, r#"{"a":null,"b":null,"c":null,"d":null,"e":[null]}"#,
+            "{engine:?}"
+        );
+        assert_eq!(
+            crate::repair_to_string("+Infinity", &o).unwrap(),
+            "null",
+            "{engine:?}"
+        );
+        let o = Options {
+            normalize_js_nonfinite: false,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..o
+        };
+        let out = crate::repair_to_string("[+Infinity, -NaN]", &o).unwrap();
+        assert_eq!(out, r#"["+Infinity","-NaN"]"#, "{engine:?}");
+    }
+}
+
+#[test]
+fn plus_inside_strings_and_exponents_is_untouched() {
+    let out = crate::repair_to_string(r#"{"s": "+5", t: '+1', "e": 1e+3}"#, &opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v["s"], "+5");
+    assert_eq!(v["t"], "+1");
+    assert!(out.contains("1e+3"));
+}
+
+#[test]
+fn normalize_numbers_table() {
+    let o = Options {
+        normalize_numbers: true,
+        ..Default::default()
+    };
+    let table = [
+        ("1E5", "1e5"),
+        ("1e+5", "1e5"),
+        ("1.50E+03", "1.5e3"),
+        ("2.5e-007", "2.5e-7"),
+        ("1.0", "1"),
+        ("1.500", "1.5"),
+        ("100", "100"),
+        ("10.010", "10.01"),
+        ("-0", "0"),
+        ("-0.0", "0"),
+        ("0e10", "0"),
+        ("2.0e0", "2"),
+        ("1e0", "1"),
+        ("1E+000", "1"),
+        ("1.25e-0", "1.25"),
+        ("12e1", "12e1"),
+        ("-3.10E-00", "-3.1"),
+        ("007", "7"),
+        (".50", "0.5"),
+        ("5.", "5"),
+        ("+1.0E+2", "1e2"),
+    ];
+    for (before, after) in table {
+        let out = crate::repair_to_string(&format!("[{before}]"), &o).unwrap();
+        assert_eq!(out, format!("[{after}]"), "{before}");
+    }
+}
+
+#[test]
+fn normalize_numbers_valid_json_and_strings() {
+    let o = Options {
+        normalize_numbers: true,
+        ..Default::default()
+    };
+    // Valid JSON goes through the repair path too, and strings are untouched.
+    let out = crate::repair_to_string(r#"{"a": 1.50, "b": "1.50", "c": -0}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":1.5,"b":"1.50","c":0}"#);
+    let out = crate::repair_to_string(r#"{"a": 1.50}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"a": 1.50}"#);
+}
+
+#[test]
+fn negative_zero_preserve_and_normalize() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let normalize = Options {
+            engine,
+            negative_zero: crate::NegativeZeroPolicy::Normalize,
+            ..Default::default()
+        };
+        let preserve = Options {
+            engine,
+            ..Default::default()
+        };
+        for (before, after) in [
+            ("-0", "0"),
+            ("-0.0", "0.0"),
+            ("-0e5", "0e5"),
+            ("-0.000e-2", "0.000e-2"),
+            ("-.0", "0.0"),
+            ("-0.5", "-0.5"),
+            ("-10", "-10"),
+        ] {
+            let out = crate::repair_to_string(&format!("[{before},]"), &normalize).unwrap();
+            assert_eq!(out, format!("[{after}]"), "{engine:?} {before}");
+        }
+        // Valid JSON is parsed too under Normalize.
+        let out = crate::repair_to_string(r#"{"a": -0, "b": "-0"}"#, &normalize).unwrap();
+        assert_eq!(out, r#"{"a":0,"b":"-0"}"#, "{engine:?}");
+        for zero in ["-0", "-0.0", "-0e5"] {
+            let out = crate::repair_to_string(&format!("[{zero},]"), &preserve).unwrap();
+            assert_eq!(out, format!("[{zero}]"), "{engine:?}");
+        }
+    }
+}
+
+fn comma_decimal() -> Options {
+    Options {
+        comma_decimal: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn comma_decimal_reads_unambiguous_object_values() {
+    for (s, want) in [
+        (r#"{"price": 3,14}"#, r#"{"price":3.14}"#),
+        (r#"{"a": 3,14, "b": -0,5}"#, r#"{"a":3.14,"b":-0.5}"#),
+        ("{price: 3,14\n, qty: 2}", r#"{"price":3.14,"qty":2}"#),
+        (r#"{"a": 1,5"#, r#"{"a":1.5}"#),
+        (r#"{"a": 1,0005}"#, r#"{"a":1.0005}"#),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &comma_decimal()).unwrap(),
+            want,
+            "{s:?}"
+        );
+    }
+    // Off by default: the fraction becomes a key.
+    let out = crate::repair_to_string(r#"{"price": 3,14}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"price":3,"14":""}"#);
+}
+
+fn eval_fractions() -> Options {
+    Options {
+        eval_fractions: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn eval_fractions_reads_integer_fractions() {
+    for (s, want) in [
+        (r#"{"ratio": 1/2}"#, r#"{"ratio":0.5}"#),
+        ("[1/2, 3/4, -1/4, 4/2]", "[0.5,0.75,-0.25,2]"),
+        ("{a: 1/3, b: 10/4}", r#"{"a":0.3333333333333333,"b":2.5}"#),
+        ("{a: [0/5 ]}", r#"{"a":[0]}"#),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &eval_fractions()).unwrap(),
+            want,
+            "{s:?}"
+        );
+    }
+    let log = crate::repair_to_string_with_log("[3/4]", &eval_fractions())
+        .unwrap()
+        .1;
+    assert_eq!(log.len(), 1);
+    assert_eq!(log[0].message, "evaluated integer fraction");
+    // Off by default.
+    let out = crate::repair_to_string(r#"{"ratio": 1/2}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"ratio":"1/2"}"#);
+}
+
+#[test]
+fn eval_fractions_refuses_paths_and_dates() {
+    for (s, want) in [
+        ("{p: /usr/bin}", r#"{"p":"/usr/bin"}"#),
+        ("{p: 1/usr}", r#"{"p":"1/usr"}"#),
+        ("{p: 1/2/3}", r#"{"p":"1/2/3"}"#),
+        ("{p: 1/2px}", r#"{"p":"1/2px"}"#),
+        ("{p: 1.5/2}", r#"{"p":"1.5/2"}"#),
+        ("{p: 1/-2}", r#"{"p":"1/-2"}"#),
+        ("{d: 01/02}", r#"{"d":"01/02"}"#),
+        ("{z: 1/0}", r#"{"z":"1/0"}"#),
+        ("[1234567890123456/2]", r#"["1234567890123456/2"]"#),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &eval_fractions()).unwrap(),
+            want,
+            "{s:?}"
+        );
+    }
+}
+
+#[test]
+fn comma_decimal_refuses_ambiguous_commas() {
+    for (s, want) in [
+        ("[3,14]", "[3,14]"),
+        (r#"{"a": [3,14],}"#, r#"{"a":[3,14]}"#),
+        (r#"{"a": 3, 14}"#, r#"{"a":3,"14":""}"#),
+        (r#"{"a": 3.5,1}"#, r#"{"a":3.5,"1":""}"#),
+        (r#"{"a": 3,14: 1}"#, r#"{"a":3,"14":1}"#),
+        (r#"{"a": 3,14,15}"#, r#"{"a":3,"14":"","15":""}"#),
+        // Three digits may be a thousands separator.
+        (r#"{"a": 1,000}"#, r#"{"a":1,"000":""}"#),
+        (r#"{"a": -2,500, "b": 1}"#, r#"{"a":-2,"500":"","b":1}"#),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &comma_decimal()).unwrap(),
+            want,
+            "{s:?}"
+        );
+    }
+}
+
+#[test]
+fn overflow_policy_handles_infinite_literals() {
+    let v: Result<serde_json::Value, _> = serde_json::from_str("[1e400]");
+    assert!(v.is_err(), "serde rejects 1e400 as out of range");
+    let s = "{a: 1e400, b: [-1e999, 1e-400, 1.5]}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (overflow, want, valid) in [
+            (
+                OverflowPolicy::Keep,
+                r#"{"a":1e400,"b":[-1e999,1e-400,1.5]}"#,
+                "[1e400]",
+            ),
+            (
+                OverflowPolicy::Quote,
+                r#"{"a":"1e400","b":["-1e999",1e-400,1.5]}"#,
+                r#"["1e400"]"#,
+            ),
+            (
+                OverflowPolicy::Null,
+                r#"{"a":null,"b":[null,1e-400,1.5]}"#,
+                "[null]",
+            ),
+        ] {
+            let o = Options {
+                engine,
+                overflow,
+                ..opts()
+            };
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {overflow:?}");
+            // Input that is already valid JSON is rewritten too.
+            let out = crate::repair_to_string("[1e400]", &o).unwrap();
+            assert_eq!(out, valid, "{engine:?} {overflow:?}");
+        }
+    }
+    let o = Options {
+        overflow: OverflowPolicy::Null,
+        ..opts()
+    };
+    let v = crate::repair_to_value("[1e400, 2]", &o).unwrap();
+    assert_eq!(v, serde_json::json!([null, 2]));
+}
+
+#[test]
+fn number_suffix_policy_quotes_or_strips_units() {
+    let s = "{timeout: 30s, size: 10MB, load: 75%, disk: 1.5GiB, t: -5ms, n: 30, hex: 0xFF}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (number_suffix, want) in [
+            (
+                NumberSuffixPolicy::Quote,
+                r#"{"timeout":"30s","size":"10MB","load":"75%","disk":"1.5GiB","t":"-5ms","n":30,"hex":"0xFF"}"#,
+            ),
+            (
+                NumberSuffixPolicy::Strip,
+                r#"{"timeout":30,"size":10,"load":75,"disk":1.5,"t":-5,"n":30,"hex":"0xFF"}"#,
+            ),
+        ] {
+            let o = Options {
+                engine,
+                number_suffix,
+                stray_tokens: StrayTokenPolicy::Quote,
+                ..opts()
+            };
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {number_suffix:?}");
+            // A unit after a space is a separate token.
+            let out = crate::repair_to_string("[10 MB]", &o).unwrap();
+            assert_eq!(out, r#"[10,"MB"]"#, "{engine:?} {number_suffix:?}");
+        }
+    }
+    // By default a letter suffix is quoted but `%` is split off.
+    let o = Options {
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..opts()
+    };
+    let out = crate::repair_to_string("[30s, 75%]", &o).unwrap();
+    assert_eq!(out, r#"["30s",75,"%"]"#);
+}
+
+#[test]
+fn safe_integers_at_the_2_53_boundary() {
+    use crate::options::SafeIntegerPolicy;
+    let s = "[9007199254740991, 9007199254740992, -9007199254740991, -9007199254740993, 12345678901234567890, 9007199254740993.0, 1e20]";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (safe_integers, want) in [
+            (
+                SafeIntegerPolicy::Clamp,
+                "[9007199254740991,9007199254740991,-9007199254740991,-9007199254740991,9007199254740991,9007199254740993.0,1e20]",
+            ),
+            (
+                SafeIntegerPolicy::Quote,
+                r#"[9007199254740991,"9007199254740992",-9007199254740991,"-9007199254740993","12345678901234567890",9007199254740993.0,1e20]"#,
+            ),
+        ] {
+            let o = Options {
+                engine,
+                safe_integers,
+                ..opts()
+            };
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {safe_integers:?}");
+        }
+    }
+    // Passed through by default.
+    let out = crate::repair_to_string("[9007199254740993]", &opts()).unwrap();
+    assert_eq!(out, "[9007199254740993]");
+}
@@ -116,6 +116,35 @@ fn ns_ellipsis_variants() {
     assert_eq!(arr[1], serde_json::json!([1, 2]));
 }
 
+#[test]
+fn strip_ellipsis_drops_bare_placeholders() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Options::default()
+        };
+        for (s, want) in [
+            ("[1,2,...]", "[1,2]"),
+            (r#"{"a":1, ...}"#, r#"{"a":1}"#),
+            (r#"{"items": [1, 2, ...]}"#, r#"{"items":[1,2]}"#),
+            (r#"["...", 1, ..."#, r#"["...",1]"#),
+            ("[1, ...rest]", r#"[1,"...rest"]"#),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "{engine:?} {s:?}");
+        }
+        let o = Options {
+            strip_ellipsis: false,
+            ..o
+        };
+        let out = crate::repair_to_string("[1,2,...]", &o).unwrap();
+        assert_eq!(out, r#"[1,2,"..."]"#, "{engine:?}");
+    }
+}
+
 #[test]
 fn ns_object_missing_colon_and_comma_with_comments() {
     let s = "{'a' /*x*/ 1 /*y*/ 'b' /*z*/ 2}";
@@ -123,3 +152,943 @@ fn ns_object_missing_colon_and_comma_with_comments() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v, serde_json::json!({"a":1,"b":2}));
 }
+
+#[test]
+fn ns_unquoted_key_with_escaped_quote() {
+    let out = crate::repair_to_string(r#"{a\"b: 1}"#, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a\"b":1}"#);
+    let out = crate::repair_to_string(r#"{it\'s: 1, c\"d\"e: 2}"#, &Options::default()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!({"it's":1, "c\"d\"e":2}));
+}
+
+#[test]
+fn trim_keys_strips_key_whitespace_only() {
+    let o = Options {
+        trim_keys: true,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(r#"{"name ": " v ", ' id':2}"#, &o).unwrap();
+    assert_eq!(out, r#"{"name":" v ","id":2}"#);
+    // Off by default: valid input passes through unchanged.
+    let out = crate::repair_to_string(r#"{"name ": 1}"#, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"name ": 1}"#);
+}
+
+#[test]
+fn trim_keys_can_create_duplicate_keys() {
+    let o = Options {
+        trim_keys: true,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(r#"{"a": 1, "a ": 2}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":1,"a":2}"#);
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v["a"], 2);
+}
+
+#[test]
+fn trim_keys_llm_engine() {
+    let o = Options {
+        trim_keys: true,
+        engine: crate::options::EngineKind::LlmCompat,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(r#"{"a ": 1, " b": [2]}"#, &o).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!({"a": 1, "b": [2]}));
+}
+
+fn missing(policy: crate::MissingValuePolicy) -> Options {
+    Options {
+        missing_value_policy: policy,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn missing_value_with_colon() {
+    use crate::MissingValuePolicy::*;
+    let s = r#"{"a":, "b":2}"#;
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":"","b":2}"#);
+    assert_eq!(
+        crate::repair_to_string(s, &missing(Null)).unwrap(),
+        r#"{"a":null,"b":2}"#
+    );
+    assert_eq!(
+        crate::repair_to_string(s, &missing(DropKey)).unwrap(),
+        r#"{"b":2}"#
+    );
+}
+
+#[test]
+fn missing_value_without_colon() {
+    use crate::MissingValuePolicy::*;
+    assert_eq!(
+        crate::repair_to_string(r#"{"a"}"#, &missing(Null)).unwrap(),
+        r#"{"a":null}"#
+    );
+    assert_eq!(
+        crate::repair_to_string(r#"{"a"}"#, &missing(DropKey)).unwrap(),
+        "{}"
+    );
+    assert_eq!(
+        crate::repair_to_string(r#"{"x": 1, "a", "b": 2}"#, &missing(DropKey)).unwrap(),
+        r#"{"x":1,"b":2}"#
+    );
+    assert_eq!(
+        crate::repair_to_string(r#"[{"a"}, 1]"#, &missing(Null)).unwrap(),
+        r#"[{"a":null},1]"#
+    );
+}
+
+#[test]
+fn missing_value_at_truncation_is_valid_json() {
+    use crate::MissingValuePolicy::*;
+    for (policy, want) in [
+        (EmptyString, r#"{"a":1,"b":""}"#),
+        (Null, r#"{"a":1,"b":null}"#),
+        (DropKey, r#"{"a":1}"#),
+    ] {
+        for s in [
+            r#"{"a": 1, "b""#,
+            r#"{"a": 1, "b":"#,
+            r#"{"a": 1, "b": ..."#,
+        ] {
+            assert_eq!(crate::repair_to_string(s, &missing(policy)).unwrap(), want);
+        }
+    }
+}
+
+fn equals_opts() -> Options {
+    Options {
+        equals_separators: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn equals_separators_mixed_in_one_object() {
+    let o = equals_opts();
+    for s in [
+        "{a => 1, b: 2, c = 3}",
+        "{a=>1, b:2, c=3}",
+        "{'a' => 1, \"b\" : 2, c =3}",
+        "{\n  a => 1,\n  b: 2,\n  c = 3\n}",
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"a":1,"b":2,"c":3}"#,
+            "{s:?}"
+        );
+    }
+    let out = crate::repair_to_string("{a => {b => [1, {c = 'x'}]}}", &o).unwrap();
+    assert_eq!(out, r#"{"a":{"b":[1,{"c":"x"}]}}"#);
+}
+
+#[test]
+fn equals_separators_leave_strings_alone() {
+    let o = equals_opts();
+    let out = crate::repair_to_string(r#"{"a" => "x => y", "b": "k = v"}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":"x => y","b":"k = v"}"#);
+    let out = crate::repair_to_string(r#"['a => b', "c = d",]"#, &o).unwrap();
+    assert_eq!(out, r#"["a => b","c = d"]"#);
+}
+
+#[test]
+fn arrow_separators_in_php_style_dumps() {
+    let o = equals_opts();
+    for s in [
+        r#"{"a" -> 1, "b" -> 2}"#,
+        "{a -> 1, b->2}",
+        "{'a'->1, b => 2}",
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"a":1,"b":2}"#,
+            "{s:?}"
+        );
+    }
+    // A negative value right after the arrow stays a number.
+    let out = crate::repair_to_string("{a -> -5, b->-1.5, c-d -> {e -> [1]}}", &o).unwrap();
+    assert_eq!(out, r#"{"a":-5,"b":-1.5,"c-d":{"e":[1]}}"#);
+    let out = crate::repair_to_string(r#"{"s" -> "x -> y"}"#, &o).unwrap();
+    assert_eq!(out, r#"{"s":"x -> y"}"#);
+}
+
+#[test]
+fn computed_keys_with_string_literal() {
+    let o = Options::default();
+    for s in [r#"{["a"]: 1}"#, "{['a']: 1}", r#"{ [ "a" ] : 1 }"#] {
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"a":1}"#,
+            "{s:?}"
+        );
+    }
+    let out = crate::repair_to_string(r#"{["a b"]: 1, ["c]"]: {["d"]: 2}}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a b":1,"c]":{"d":2}}"#);
+}
+
+#[test]
+fn computed_keys_bare_are_quoted() {
+    let o = Options::default();
+    let out = crate::repair_to_string("{[dynamic]: 2, b: 3}", &o).unwrap();
+    assert_eq!(out, r#"{"dynamic":2,"b":3}"#);
+    let out = crate::repair_to_string("{[Symbol.iterator]: true}", &o).unwrap();
+    assert_eq!(out, r#"{"Symbol.iterator":true}"#);
+    let strict = Options {
+        repair_undefined: false,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string("{[dynamic]: 2}", &strict);
+    assert_ne!(out.as_deref(), Ok(r#"{"dynamic":2}"#));
+}
+
+fn dedup(position: DedupPosition) -> Options {
+    Options {
+        dedup_position: position,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn dedup_keep_last_value_at_first_position() {
+    let o = dedup(DedupPosition::First);
+    let out = crate::repair_to_string("{a: 1, b: 2, a: 3, c: 4, b: 5}", &o).unwrap();
+    assert_eq!(out, r#"{"a":3,"b":5,"c":4}"#);
+    // Keys compare decoded; the first spelling is kept.
+    let out = crate::repair_to_string(r#"{"a": 1, "\u0061": 2}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":2}"#);
+    // Nested objects, inside arrays too, are collapsed independently.
+    let out = crate::repair_to_string("[{x: {k: 1, k: 2}, x: {k: 3}}, {y: 1}]", &o).unwrap();
+    assert_eq!(out, r#"[{"x":{"k":3}},{"y":1}]"#);
+}
+
+#[test]
+fn dedup_keep_last_value_at_last_position() {
+    let o = dedup(DedupPosition::Last);
+    let out = crate::repair_to_string("{a: 1, b: 2, a: 3, c: 4, b: 5}", &o).unwrap();
+    assert_eq!(out, r#"{"a":3,"c":4,"b":5}"#);
+    let out = crate::repair_to_string("{a: {k: 1, j: 2, k: 3}, a: [{k: 1, k: 2}]}", &o).unwrap();
+    assert_eq!(out, r#"{"a":[{"k":2}]}"#);
+}
+
+#[test]
+fn dedup_leaves_objects_without_duplicates_untouched() {
+    for position in [
+        DedupPosition::KeepAll,
+        DedupPosition::First,
+        DedupPosition::Last,
+    ] {
+        let o = dedup(position);
+        let valid = r#"{ "a": [1, 2], "b": { "c": "a" } }"#;
+        assert_eq!(crate::repair_to_string(valid, &o).unwrap(), valid);
+    }
+    let out = crate::repair_to_string("{a: 1, a: 2}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":1,"a":2}"#);
+    // Valid input with duplicates is rebuilt only where needed.
+    let o = dedup(DedupPosition::First);
+    let out = crate::repair_to_string(r#"{"a": 1, "b": [1, 2], "a": 2}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":2,"b":[1, 2]}"#);
+}
+
+#[test]
+fn dedup_applies_to_writer_and_stream() {
+    let o = dedup(DedupPosition::First);
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming("{a: 1, b: 2, a: 3}", &o, &mut buf).unwrap();
+    assert_eq!(String::from_utf8(buf).unwrap(), r#"{"a":3,"b":2}"#);
+    let mut r = crate::StreamRepairer::new(o);
+    let out = r.push("{a: 1, a: 2}\n").unwrap();
+    assert_eq!(out.as_deref(), Some(r#"{"a":2}"#));
+}
+
+fn dedup_arrays() -> Options {
+    Options {
+        dedup_arrays: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn dedup_arrays_keeps_first_scalar() {
+    let o = dedup_arrays();
+    let out = crate::repair_to_string(r#"[1,1,2,"a","a"]"#, &o).unwrap();
+    assert_eq!(out, r#"[1,2,"a"]"#);
+    // Numbers compare by normalized spelling, strings by decoded text, and a string never
+    // equals the number or keyword it spells.
+    let out = crate::repair_to_string(
+        r#"[1, 1.0, 1e1, 10, "a", "\u0061", "1", true, true, null, null]"#,
+        &o,
+    )
+    .unwrap();
+    assert_eq!(out, r#"[1,1e1,10,"a","1",true,null]"#);
+    // Off by default.
+    let out = crate::repair_to_string("[1, 1,]", &Options::default()).unwrap();
+    assert_eq!(out, "[1,1]");
+}
+
+#[test]
+fn dedup_arrays_keeps_containers() {
+    let o = dedup_arrays();
+    let out = crate::repair_to_string("[{a: 1}, {a: 1}, [2, 2], [2, 2]]", &o).unwrap();
+    assert_eq!(out, r#"[{"a":1},{"a":1},[2],[2]]"#);
+    // Object keys keep their duplicates unless `dedup_position` is also set.
+    let out = crate::repair_to_string("{a: [1, 1], a: 2}", &o).unwrap();
+    assert_eq!(out, r#"{"a":[1],"a":2}"#);
+    let o = Options {
+        dedup_position: DedupPosition::First,
+        ..dedup_arrays()
+    };
+    let out = crate::repair_to_string("{a: [1, 1], a: [3, 3]}", &o).unwrap();
+    assert_eq!(out, r#"{"a":[3]}"#);
+}
+
+fn stray(policy: StrayTokenPolicy) -> Options {
+    Options {
+        stray_tokens: policy,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn stray_tokens_error_is_default() {
+    let err = crate::repair_to_string("[1, garbage, 2]", &Options::default()).unwrap_err();
+    assert_eq!(
+        err.kind,
+        RepairErrorKind::Parse("stray token in array".into())
+    );
+    assert_eq!(err.position, 4);
+    let out = crate::repair_to_string("[1, garbage, 2]", &stray(StrayTokenPolicy::Quote)).unwrap();
+    assert_eq!(out, r#"[1,"garbage",2]"#);
+}
+
+#[test]
+fn stray_tokens_drop_removes_element() {
+    let o = stray(StrayTokenPolicy::Drop);
+    for (s, want) in [
+        ("[1, garbage, 2]", "[1,2]"),
+        ("[garbage, 1]", "[1]"),
+        ("[1, 2, garbage]", "[1,2]"),
+        ("[true, None, undefined, NaN, x]", "[true,null,null,null]"),
+        ("{a: [1, foo bar, {b: zz}]}", r#"{"a":[1,{"b":"zz"}]}"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+}
+
+#[test]
+fn drop_placeholder_stands_in_for_dropped_element() {
+    let with = |p: &str| Options {
+        drop_placeholder: Some(p.to_string()),
+        ..stray(StrayTokenPolicy::Drop)
+    };
+    for (p, s, want) in [
+        ("null", "[1, garbage, 2]", "[1,null,2]"),
+        ("?", "[garbage, 1, x]", r#"["?",1,"?"]"#),
+        (
+            "{ \"dropped\": true }",
+            "{a: [1, foo bar]}",
+            r#"{"a":[1,{"dropped":true}]}"#,
+        ),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &with(p)).unwrap(),
+            want,
+            "{p:?} {s:?}"
+        );
+    }
+    // Salvaged values get it instead of null; keywords are never stray.
+    let o = Options {
+        drop_placeholder: Some("-1".to_string()),
+        ..salvaging(SalvagePolicy::Null)
+    };
+    let out = crate::repair_to_string("[None, garbage, 2]", &o).unwrap();
+    assert_eq!(out, "[null,-1,2]");
+}
+
+#[test]
+fn stray_tokens_error_reports_offset() {
+    let o = stray(StrayTokenPolicy::Error);
+    let err = crate::repair_to_string("[1, garbage, 2]", &o).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Parse(_)), "{err:?}");
+    assert_eq!(err.position, 4);
+    let err = crate::repair_to_string("{a: [1, 'x', oops]}", &o).unwrap_err();
+    assert_eq!(err.position, 13);
+    // Keywords, numbers and strings are not stray.
+    let out = crate::repair_to_string("[True, 'x', -1, undefined,]", &o).unwrap();
+    assert_eq!(out, r#"[true,"x",-1,null]"#);
+}
+
+fn salvaging(policy: SalvagePolicy) -> Options {
+    Options {
+        salvage: policy,
+        ..stray(StrayTokenPolicy::Error)
+    }
+}
+
+#[test]
+fn salvage_replaces_bad_element_in_good_array() {
+    let o = salvaging(SalvagePolicy::Null);
+    for (s, want) in [
+        ("[1, garbage, 2]", "[1,null,2]"),
+        ("[garbage]", "[null]"),
+        ("[1, 2, garbage", "[1,2,null]"),
+        (
+            r#"{"a": [1, {"b": [x y, 3]}], "c": 2}"#,
+            r#"{"a":[1,{"b":[null,3]}],"c":2}"#,
+        ),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+}
+
+#[test]
+fn salvage_marker_keeps_source_text() {
+    let o = salvaging(SalvagePolicy::Marker);
+    let out = crate::repair_to_string(r#"["ok", stray "q", 3]"#, &o).unwrap();
+    assert_eq!(out, r#"["ok",{"$unrepairable":"stray \"q\""},3]"#);
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v[2], 3);
+}
+
+#[test]
+fn salvage_does_not_hide_budget_errors() {
+    let o = Options {
+        max_repairs: 1,
+        ..salvaging(SalvagePolicy::Null)
+    };
+    let err = crate::repair_to_string("[a, b, c]", &o).unwrap_err();
+    assert!(
+        matches!(err.kind, RepairErrorKind::TooManyRepairs(1)),
+        "{err:?}"
+    );
+    // Default: the stray token still fails the document.
+    let err = crate::repair_to_string("[1, garbage]", &salvaging(SalvagePolicy::Fail));
+    assert!(err.is_err());
+}
+
+#[test]
+fn doubled_braces_collapse_into_one_object() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (s, want) in [
+            (r#"{{"a":1}}"#, r#"{"a":1}"#),
+            ("{{{a: 1}}}", r#"{"a":1}"#),
+            ("{ {} }", "{}"),
+            (r#"{"x": {{"a":1}}, "y": 2}"#, r#"{"x":{"a":1},"y":2}"#),
+            (r#"{{"a":1}, {"b":2}}"#, r#"{"a":1,"b":2}"#),
+            (r#"{{"a":1}"#, r#"{"a":1}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+        // Nested arrays are valid JSON and stay as they are.
+        let s = r#"[[{"a":1}],[[]]]"#;
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), s, "{engine:?}");
+    }
+    let o = Options {
+        logging: true,
+        ..Default::default()
+    };
+    let (_, log) = crate::repair_to_string_with_log(r#"{{"a":1}}"#, &o).unwrap();
+    let messages: Vec<_> = log.iter().map(|e| e.message).collect();
+    assert_eq!(messages, ["dropped doubled brace"]);
+}
+
+#[test]
+fn crossed_closers_are_reordered() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (s, want) in [
+            (r#"{"a": [1, 2}, "b": 3]"#, r#"{"a":[1,2],"b":3}"#),
+            (r#"[{"a": 1], {"b": 2}]"#, r#"[{"a":1},{"b":2}]"#),
+            (
+                r#"{"a": [1, {"b": 2]}, "c": 3}"#,
+                r#"{"a":[1,{"b":2}],"c":3}"#,
+            ),
+            ("[[1}, 2]", "[[1],2]"),
+            // A closer inside a string does not count.
+            (r#"{"a": [1, 2}, "b": "]"]"#, r#"{"a":[1,2],"b":"]"}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+        // A closer for an enclosing container still closes it when nothing after it needs
+        // the inner container to stay open.
+        for (s, want) in [
+            (r#"{"a": [1, 2}"#, r#"{"a":[1,2]}"#),
+            (r#"[{"a": 1]"#, r#"[{"a":1}]"#),
+            (r#"{"a": {"b": [1}, "c": 2}"#, r#"{"a":{"b":[1]},"c":2}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+    let o = Options {
+        logging: true,
+        ..Default::default()
+    };
+    let (_, log) = crate::repair_to_string_with_log(r#"[{"a": 1], {"b": 2}]"#, &o).unwrap();
+    let messages: Vec<_> = log.iter().map(|e| e.message).collect();
+    assert_eq!(messages, ["closed object at crossed bracket"]);
+}
+
+#[test]
+fn newline_separated_objects_in_an_array() {
+    let want = r#"[{"a":1},{"b":2},{"c":3},{"d":4},{"e":5}]"#;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for s in [
+            "[{\"a\":1}\n{\"b\":2}\n{\"c\":3}\n{\"d\":4}\n{\"e\":5}]",
+            "[\n  {\"a\": 1}\n  {\"b\": 2}\n  {\"c\": 3}\n  {\"d\": 4}\n  {\"e\": 5}\n]",
+            "[{a:1}\r\n{b:2}\r\n\r\n{c:3}\n// d\n{d:4}\n{e:5}]",
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+    // Split across stream chunks, the elements still get their commas.
+    let mut stream = crate::StreamRepairer::new(Options::default());
+    let mut out = String::new();
+    for chunk in [
+        "[{\"a\":1}\n",
+        "{\"b\":2}\n{\"c\"",
+        ":3}\n{\"d\":4}\n{\"e\":5}]",
+    ] {
+        out.extend(stream.push(chunk).unwrap());
+    }
+    out.extend(stream.flush().unwrap());
+    assert_eq!(out, want);
+}
+
+#[test]
+fn url_keys_split_at_the_last_colon() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (s, want) in [
+            ("{http://x: 1}", r#"{"http://x":1}"#),
+            (
+                "{http://x:8080/a:1, b: 2}",
+                r#"{"http://x:8080/a":1,"b":2}"#,
+            ),
+            ("{https://a.b/c : 'v'}", r#"{"https://a.b/c":"v"}"#),
+            ("{file:///tmp/a: [true]}", r#"{"file:///tmp/a":[true]}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+}
+
+#[test]
+fn parens_as_arrays_converts_tuples_not_calls() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            parens_as_arrays: true,
+            ..Default::default()
+        };
+        for (s, want) in [
+            ("(1,2,3)", "[1,2,3]"),
+            ("(1, 2, 3)", "[1,2,3]"),
+            (
+                "{\"p\": (1, 2),\n \"q\": [3, 4]}",
+                r#"{"p":[1,2],"q":[3,4]}"#,
+            ),
+            ("(1,)", "[1]"),
+            ("()", "[]"),
+            (
+                r#"{"p":(0,(1,2)),"q":"(x)"}"#,
+                r#"{"p":[0,[1,2]],"q":"(x)"}"#,
+            ),
+            ("[(1,2),(3)]", "[[1,2],[3]]"),
+            (
+                r#"{"id": ObjectId("5f1e"), "n": (1)}"#,
+                r#"{"id":"5f1e","n":[1]}"#,
+            ),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+    // Off by default.
+    let out = crate::repair_to_string("(1,2,3)", &Options::default()).unwrap();
+    assert_ne!(out, "[1,2,3]");
+}
+
+#[test]
+fn typed_wrappers_trailing_commas_and_comments_compose() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (parens_as_arrays, compat_python_friendly) in
+            [(false, false), (true, false), (false, true), (true, true)]
+        {
+            let o = Options {
+                engine,
+                parens_as_arrays,
+                compat_python_friendly,
+                ..Default::default()
+            };
+            for (s, want) in [
+                (r#"[ObjectId("x"), /* c */ ,]"#, r#"["x"]"#),
+                (r#"[ObjectId(, "x" /* ) */ ,), 1,]"#, r#"["x",1]"#),
+                ("{a: NumberLong(\"42\", // n\n), /* c */ ,}", r#"{"a":42}"#),
+            ] {
+                assert_eq!(
+                    crate::repair_to_string(s, &o).unwrap(),
+                    want,
+                    "{engine:?} {s:?}"
+                );
+            }
+            // A comment before a tuple or set leaves it in value position.
+            let out =
+                crate::repair_to_string(r#"{a: /* c */ (1, 2), b: ObjectId("x"), /* d */ ,}"#, &o)
+                    .unwrap();
+            if parens_as_arrays {
+                assert_eq!(out, r#"{"a":[1,2],"b":"x"}"#, "{engine:?}");
+            }
+            let out =
+                crate::repair_to_string(r#"[/* c */ {'a(b', 2}, ObjectId("y"),]"#, &o).unwrap();
+            if compat_python_friendly {
+                assert_eq!(out, r#"[["a(b",2],"y"]"#, "{engine:?}");
+            }
+        }
+    }
+}
+
+#[test]
+fn value_followed_by_colon_fails_or_nests_per_strictness() {
+    use crate::error::RepairErrorKind;
+    use crate::options::{SalvagePolicy, Strictness};
+    // Conservative (default): the value is not guessed at.
+    for (s, at) in [
+        ("{a: b: c}", 4),
+        (r#"{"a": "b": "c"}"#, 6),
+        ("{a: b: c, d: 1}", 4),
+    ] {
+        let err = crate::repair_to_string(s, &Options::default()).unwrap_err();
+        assert!(matches!(err.kind, RepairErrorKind::Parse(_)), "{s:?}");
+        assert_eq!(err.position, at, "{s:?}");
+    }
+    let o = Options {
+        salvage: SalvagePolicy::Null,
+        ..Default::default()
+    };
+    assert_eq!(
+        crate::repair_to_string("{a: b: c, d: 1}", &o).unwrap(),
+        r#"{"a":null,"d":1}"#
+    );
+    // Aggressive: read as the key of a nested object.
+    let o = Options {
+        strictness: Strictness::Aggressive,
+        ..Default::default()
+    };
+    for (s, want) in [
+        ("{a: b: c}", r#"{"a":{"b":"c"}}"#),
+        (r#"{"a": "b": "c"}"#, r#"{"a":{"b":"c"}}"#),
+        ("{a: b: c, d: 1}", r#"{"a":{"b":"c"},"d":1}"#),
+        ("{a: b: c: d}", r#"{"a":{"b":{"c":"d"}}}"#),
+        ("[{a: b: [1, 2]}]", r#"[{"a":{"b":[1,2]}}]"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+    // URLs and paths are values in either mode.
+    for o in [Options::default(), o] {
+        assert_eq!(
+            crate::repair_to_string("{u: http://x.io, p: 1}", &o).unwrap(),
+            r#"{"u":"http://x.io","p":1}"#
+        );
+    }
+}
+
+#[test]
+fn array_element_followed_by_colon_fails_or_wraps_per_strictness() {
+    use crate::error::RepairErrorKind;
+    use crate::options::{EngineKind, SalvagePolicy, Strictness};
+    let recursive = |strictness| Options {
+        engine: EngineKind::Recursive,
+        strictness,
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Default::default()
+    };
+    // Conservative (default): no guess at what the pairs belong to.
+    for (s, at) in [
+        ("[a:1, b:2]", 1),
+        (r#"["a": 1]"#, 1),
+        ("[1, a: 2]", 4),
+        (r#"[1, "a": 1, "b": 2]"#, 4),
+    ] {
+        let err = crate::repair_to_string(s, &recursive(Strictness::Conservative)).unwrap_err();
+        assert!(matches!(err.kind, RepairErrorKind::Parse(_)), "{s:?}");
+        assert_eq!(err.position, at, "{s:?}");
+    }
+    let o = Options {
+        salvage: SalvagePolicy::Null,
+        ..recursive(Strictness::Conservative)
+    };
+    assert_eq!(
+        crate::repair_to_string("[1, a: 2, 3]", &o).unwrap(),
+        "[1,null,3]"
+    );
+    // Aggressive: each pair becomes a one-member object in its place.
+    for (s, want) in [
+        ("[a:1, b:2]", r#"[{"a":1},{"b":2}]"#),
+        (r#"["a": 1, "b": 'x']"#, r#"[{"a":1},{"b":"x"}]"#),
+        ("[a: b: 1]", r#"[{"a":{"b":1}}]"#),
+        ("[a: {x: 1}, b: [2]]", r#"[{"a":{"x":1}},{"b":[2]}]"#),
+        ("{k: [a: 1]}", r#"{"k":[{"a":1}]}"#),
+        ("[1, a: 2, 3]", r#"[1,{"a":2},3]"#),
+        (r#"[1, "a": 1, "b": 'x']"#, r#"[1,{"a":1},{"b":"x"}]"#),
+        ("[1, a: b: 1]", r#"[1,{"a":{"b":1}}]"#),
+        ("[{}, a: {x: 1}, b: [2]]", r#"[{},{"a":{"x":1}},{"b":[2]}]"#),
+        ("{k: [0, a: 1]}", r#"{"k":[0,{"a":1}]}"#),
+    ] {
+        let out = crate::repair_to_string(s, &recursive(Strictness::Aggressive)).unwrap();
+        assert_eq!(out, want, "{s:?}");
+    }
+    // A URL stays an element in either mode.
+    for strictness in [Strictness::Conservative, Strictness::Aggressive] {
+        assert_eq!(
+            crate::repair_to_string("[http://x.io, 1]", &recursive(strictness)).unwrap(),
+            r#"["http://x.io",1]"#
+        );
+    }
+}
+
+#[test]
+fn swapped_brackets_and_braces_read_as_written_kind() {
+    use crate::options::{EngineKind, Strictness};
+    let recursive = |strictness| Options {
+        engine: EngineKind::Recursive,
+        strictness,
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Default::default()
+    };
+    let bracketed = |strictness| Options {
+        brackets_as_objects: true,
+        ..recursive(strictness)
+    };
+    // With `brackets_as_objects`, brackets whose first element is followed by a colon hold
+    // an object, in either mode.
+    for strictness in [Strictness::Conservative, Strictness::Aggressive] {
+        for (s, want) in [
+            (r#"["a": 1, "b": 2]"#, r#"{"a":1,"b":2}"#),
+            ("[a:1, b:'x']", r#"{"a":1,"b":"x"}"#),
+            ("[a: [b: 1], c: [1, 2]]", r#"{"a":{"b":1},"c":[1,2]}"#),
+            ("{k: [a: 1]}", r#"{"k":{"a":1}}"#),
+            (r#"[["a": 1], ["b": 2]]"#, r#"[{"a":1},{"b":2}]"#),
+            ("[a: 1, b: 2", r#"{"a":1,"b":2}"#),
+            // So does a `}`.
+            ("[a: 1}", r#"{"a":1}"#),
+            // Not a key: a URL, an element before the colon, an empty or nested container.
+            ("[http://x.io]", r#"["http://x.io"]"#),
+            ("[[a], b]", r#"[["a"],"b"]"#),
+        ] {
+            let out = crate::repair_to_string(s, &bracketed(strictness)).unwrap();
+            assert_eq!(out, want, "{strictness:?} {s:?}");
+        }
+    }
+    let log = crate::repair_to_string_with_log(r#"["a": 1]"#, &bracketed(Strictness::Conservative))
+        .unwrap()
+        .1;
+    assert_eq!(log.len(), 1);
+    assert_eq!(log[0].message, "read bracketed members as object");
+    assert_eq!(log[0].position, 0);
+
+    // Braces holding only values hold an array under aggressive strictness.
+    for (s, aggressive) in [
+        ("{1, 2, 3}", "[1,2,3]"),
+        (r#"{"a", "b"}"#, r#"["a","b"]"#),
+        ("{k: {1, [2, 3], {x: 4}}}", r#"{"k":[1,[2,3],{"x":4}]}"#),
+        ("[{1, 2}, {3, 4}]", "[[1,2],[3,4]]"),
+        ("{1, 2", "[1,2]"),
+        // Any separator at its own depth keeps it an object.
+        ("{a, b: 1}", r#"{"a":"","b":1}"#),
+        // One value, even with a quoted comma, or a doubled brace, is not an array.
+        (r#"{"a"}"#, r#"{"a":""}"#),
+        (r#"{"a,b"}"#, r#"{"a,b":""}"#),
+        (r#"{{"a": 1}, "b": 2}"#, r#"{"a":1,"b":2}"#),
+    ] {
+        let out = crate::repair_to_string(s, &recursive(Strictness::Aggressive)).unwrap();
+        assert_eq!(out, aggressive, "{s:?}");
+    }
+    assert_eq!(
+        crate::repair_to_string("{1, 2, 3}", &recursive(Strictness::Conservative)).unwrap(),
+        r#"{"1":"","2":"","3":""}"#
+    );
+}
+
+#[cfg(feature = "llm-compat")]
+#[test]
+fn llm_engine_drops_colon_between_array_elements() {
+    let o = Options {
+        engine: crate::options::EngineKind::LlmCompat,
+        ..Default::default()
+    };
+    assert_eq!(
+        crate::repair_to_string("[a:1, b:2]", &o).unwrap(),
+        r#"["a",1,"b",2]"#
+    );
+}
+
+#[test]
+fn bracket_aliases_read_keywords_as_brackets() {
+    use crate::options::BracketKind;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let mut o = Options {
+            engine,
+            ..Default::default()
+        };
+        o.add_bracket_alias("BEGIN", "END", BracketKind::Object);
+        o.add_bracket_alias("LIST", "END", BracketKind::Array);
+        o.add_bracket_alias("OBJECT", "ENDOBJECT", BracketKind::Object);
+        for (s, want) in [
+            ("BEGIN a: 1, b: 2 END", r#"{"a":1,"b":2}"#),
+            // The words inside strings and comments are content.
+            (
+                r#"BEGIN a: "BEGIN x END", b: 'END' END"#,
+                r#"{"a":"BEGIN x END","b":"END"}"#,
+            ),
+            ("BEGIN // END\n a: 1 END", r#"{"a":1}"#),
+            // `END` closes the innermost alias that uses it.
+            (
+                "BEGIN tags: LIST 1, 2 END, n: 3 END",
+                r#"{"tags":[1,2],"n":3}"#,
+            ),
+            (
+                "OBJECT a: OBJECT b: 1 ENDOBJECT ENDOBJECT",
+                r#"{"a":{"b":1}}"#,
+            ),
+            ("[BEGIN a: 1 END, BEGIN a: 2 END]", r#"[{"a":1},{"a":2}]"#),
+            ("BEGIN note: it's fine END", r#"{"note":"it's fine"}"#),
+            // Whole words only, and not in key position.
+            (
+                "{END: BEGINNER, x: LISTS}",
+                r#"{"END":"BEGINNER","x":"LISTS"}"#,
+            ),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, want, "engine={engine:?} input={s:?}");
+        }
+        let o = Options {
+            engine,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..Default::default()
+        };
+        assert_eq!(
+            crate::repair_to_string("[BEGIN]", &o).unwrap(),
+            r#"["BEGIN"]"#
+        );
+    }
+}
+
+#[test]
+fn sort_keys_orders_members_at_every_depth() {
+    let o = Options {
+        sort_keys: true,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(r#"{"b": 1, "a": {"d": [{"z": 0, "y": 1}], "c": 3}}"#, &o);
+    assert_eq!(out.unwrap(), r#"{"a":{"c":3,"d":[{"y":1,"z":0}]},"b":1}"#);
+    // Keys compare decoded, and duplicates keep their order.
+    let out = crate::repair_to_string(r#"{"b": 1, "a": 2, "b": 3, "B": 4}"#, &o).unwrap();
+    assert_eq!(out, r#"{"B":4,"a":2,"b":1,"b":3}"#);
+}
+
+#[test]
+fn storage_preset_gives_equal_data_equal_bytes() {
+    let mut o = Options::default();
+    o.preset_storage();
+    let a = crate::repair_to_string(
+        "{\n  \"name\": \"caf\\u00e9\",\n  \"tags\": [\"a\\/b\"],\n  \"price\": 1.50E+03,\n  \"id\": 7\n}\n",
+        &o,
+    )
+    .unwrap();
+    let b = crate::repair_to_string(
+        "{id: 7, price: 1.5e3, tags: ['a/b'], name: 'café', id: 7}",
+        &o,
+    )
+    .unwrap();
+    assert_eq!(a, r#"{"id":7,"name":"café","price":1.5e3,"tags":["a/b"]}"#);
+    assert_eq!(a, b);
+}
+
+#[test]
+fn colons_between_array_elements_separate_them() {
+    for (s, want) in [
+        ("[1:2:3]", "[1,2,3]"),
+        ("[1: 2: 3]", "[1,2,3]"),
+        ("[1, 2 : 3]", "[1,2,3]"),
+        ("[[1]: {a: 2}: 3]", r#"[[1],{"a":2},3]"#),
+        ("{a: [1:2]}", r#"{"a":[1,2]}"#),
+        ("[1:]", "[1]"),
+    ] {
+        assert_eq!(
+            crate::repair_to_string(s, &Options::default()).unwrap(),
+            want,
+            "{s:?}"
+        );
+    }
+    let log = crate::repair_to_string_with_log("[1:2]", &Options::default())
+        .unwrap()
+        .1;
+    assert_eq!(log.len(), 1);
+    assert_eq!(log[0].message, "replaced ':' separator with comma");
+    // A string or word before the colon is still a key, so it fails per strictness.
+    assert!(crate::repair_to_string(r#"["a": 1]"#, &Options::default()).is_err());
+    // Objects are unaffected: a colon after a value there is a nested key.
+    assert!(crate::repair_to_string("{a: b: c}", &Options::default()).is_err());
+}
@@ -0,0 +1,780 @@
+use super::*;
+
+fn bom_opts() -> Options {
+    Options {
+        output_bom: true,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn output_bom_prefixes_exactly_one_bom() {
+    for inp in ["{a:1}", "\u{FEFF}{a:1}", "{\"a\":1}", "\u{FEFF}{\"a\":1}"] {
+        let out = crate::repair_to_string(inp, &bom_opts()).unwrap();
+        assert_eq!(out, "\u{FEFF}{\"a\":1}", "input={:?}", inp);
+    }
+}
+
+#[test]
+fn output_bom_writer_paths() {
+    let mut buf = Vec::new();
+    crate::repair_to_writer("{a:1}", &bom_opts(), &mut buf).unwrap();
+    assert_eq!(buf, b"\xEF\xBB\xBF{\"a\":1}");
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming("\u{FEFF}[1,2", &bom_opts(), &mut buf).unwrap();
+    assert_eq!(buf, b"\xEF\xBB\xBF[1,2]");
+}
+
+#[test]
+fn output_bom_streaming_emits_once() {
+    let out = crate::repair_chunks_to_string(["\u{FEFF}{a:", "1}{b:", "2}"], &bom_opts()).unwrap();
+    assert_eq!(out, "\u{FEFF}{\"a\":1}{\"b\":2}");
+    let mut buf = Vec::new();
+    crate::repair_chunks_to_writer(["{a:1}", "[2]"], &bom_opts(), &mut buf).unwrap();
+    assert_eq!(buf, b"\xEF\xBB\xBF{\"a\":1}[2]");
+}
+
+#[test]
+fn output_bom_is_ignored_when_parsing_to_value() {
+    let v = crate::loads("{a:1}", &bom_opts()).unwrap();
+    assert_eq!(v, serde_json::json!({"a":1}));
+}
+
+#[test]
+fn sha256_known_vectors() {
+    fn hex(b: [u8; 32]) -> String {
+        b.iter().map(|x| format!("{:02x}", x)).collect()
+    }
+    assert_eq!(
+        hex(crate::sha256::digest(b"")),
+        "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+    );
+    assert_eq!(
+        hex(crate::sha256::digest(b"abc")),
+        "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+    );
+    // Two-block padding (56..64 bytes in the final block).
+    assert_eq!(
+        hex(crate::sha256::digest(
+            b"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq"
+        )),
+        "248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1"
+    );
+    assert_eq!(
+        hex(crate::sha256::digest(&[b'a'; 1000])),
+        "41edece42d63e8d9bf515a9ba6932e1c20cbc9f5a5d134645adb5db1b9737ea3"
+    );
+}
+
+#[test]
+fn repair_hashed_covers_emitted_bytes() {
+    let (out, hash) = crate::repair_to_string_hashed("{a: 1}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":1}"#);
+    assert_eq!(hash, crate::sha256::digest(out.as_bytes()));
+    let o = Options {
+        output_bom: true,
+        ..Default::default()
+    };
+    let (bom_out, bom_hash) = crate::repair_to_string_hashed("{a: 1}", &o).unwrap();
+    assert!(bom_out.starts_with('\u{FEFF}'));
+    assert_eq!(bom_hash, crate::sha256::digest(bom_out.as_bytes()));
+    assert_ne!(bom_hash, hash);
+}
+
+#[test]
+fn repair_with_kind_reports_the_root_type() {
+    use crate::ValueKind;
+    let cases = [
+        ("{a: 1", ValueKind::Object),
+        ("[1, 2,", ValueKind::Array),
+        ("{a:1}{b:2}", ValueKind::Array),
+        ("'hi'", ValueKind::String),
+        ("hello world", ValueKind::String),
+        (".5", ValueKind::Number),
+        ("-3", ValueKind::Number),
+        ("True", ValueKind::Boolean),
+        ("false", ValueKind::Boolean),
+        ("None", ValueKind::Null),
+        ("// nothing", ValueKind::Empty),
+    ];
+    for (input, want) in cases {
+        let (out, kind) = crate::repair_to_string_with_kind(input, &Options::default()).unwrap();
+        assert_eq!(kind, want, "{input} -> {out}");
+    }
+    // The kind is read past a BOM and indentation, and from JSON5 output.
+    let o = Options {
+        output_bom: true,
+        output_format: crate::OutputFormat::Json5,
+        ..Default::default()
+    };
+    let (_, kind) = crate::repair_to_string_with_kind("  'x'", &o).unwrap();
+    assert_eq!(kind, ValueKind::String);
+}
+
+#[test]
+fn repair_changed_is_false_only_for_identical_output() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for input in [r#"{"a":[1,2],"b":"x"}"#, "[]", "\"s\""] {
+            let (out, changed) = crate::repair_to_string_changed(input, &o).unwrap();
+            assert_eq!((out.as_str(), changed), (input, false), "{engine:?}");
+        }
+        for input in ["{a: 1}", "[1, 2,", "{\"a\": 1,}", "'s'"] {
+            let (out, changed) = crate::repair_to_string_changed(input, &o).unwrap();
+            assert!(changed, "{engine:?}: {input:?} -> {out:?}");
+        }
+    }
+    // The recursive engine copies valid JSON through with its spacing.
+    for input in [r#"{"a": [1, 2], "b": "x"}"#, "  {\"a\":1}\n"] {
+        let (out, changed) = crate::repair_to_string_changed(input, &Options::default()).unwrap();
+        assert_eq!((out.as_str(), changed), (input, false));
+    }
+    // An option that rewrites valid output counts as a change.
+    let o = Options {
+        output_bom: true,
+        ..Default::default()
+    };
+    let (_, changed) = crate::repair_to_string_changed("[]", &o).unwrap();
+    assert!(changed);
+}
+
+#[test]
+fn extract_returns_only_the_pointed_value() {
+    let o = Options::default();
+    let s = "```json\n{status: 'ok', result: {items: [1, {'a/b': 2, 'm~n': [3]},], note: 'x'}\n```";
+    let cases = [
+        (
+            "",
+            r#"{"status":"ok","result":{"items":[1,{"a/b":2,"m~n":[3]}],"note":"x"}}"#,
+        ),
+        ("/status", r#""ok""#),
+        ("/result/items", r#"[1,{"a/b":2,"m~n":[3]}]"#),
+        ("/result/items/0", "1"),
+        ("/result/items/1/a~1b", "2"),
+        ("/result/items/1/m~0n/0", "3"),
+        ("/result/note", r#""x""#),
+    ];
+    for (ptr, want) in cases {
+        assert_eq!(crate::repair_extract(s, ptr, &o).unwrap(), want, "{ptr}");
+    }
+}
+
+#[test]
+fn extract_escaped_keys_and_separators() {
+    let o = Options {
+        ensure_ascii: true,
+        python_style_separators: true,
+        ..Default::default()
+    };
+    let out = crate::repair_extract("{'ключ': [1, 2], b: 3}", "/ключ", &o).unwrap();
+    assert_eq!(out, "[1, 2]");
+    let out = crate::repair_extract("{a: {b: 'é'}, c: 1}", "/a", &o).unwrap();
+    assert_eq!(out, r#"{"b": "\u00E9"}"#);
+}
+
+#[test]
+fn extract_from_the_value_of_an_envelope() {
+    let o = Options {
+        envelope: true,
+        ..Default::default()
+    };
+    let out = crate::repair_extract("{a: 1}", "/a", &o).unwrap();
+    assert_eq!(out, r#"{"value":1,"repaired":true,"repairs":1}"#);
+    let out = crate::repair_extract(r#"{"a": [1, 2]}"#, "/a/1", &o).unwrap();
+    assert_eq!(out, r#"{"value":2,"repaired":false,"repairs":0}"#);
+    let err = crate::repair_extract("{a: 1}", "/value", &o).unwrap_err();
+    assert_eq!(
+        err.kind,
+        RepairErrorKind::PointerNotFound("/value".to_string())
+    );
+}
+
+#[test]
+fn extract_agrees_with_slicing_the_whole_repair() {
+    let o = Options::default();
+    let inputs = [
+        "{a: {b: [1, 'x', {c: true}], d: null}, e: 'y'}",
+        "{a: {b: [1, 'x', {c: true}], d: null}, e: 'y'} and some trailing words",
+        "Here it is: {a: {b: [1, 2]}, e: 3}",
+        "{a: {b: [1, 2]}, e: 3}{a: 4}",
+        "{a: {b: [1, 2]}, e: 3}, [5, 6]",
+        "[{a: 1}, {a: 2}] [3]",
+        "{a: {b: [1, 2",
+        "{'k\\'s': {\"x\\u0041\": 1}, a: \"}{\"}",
+        "{a: it's [1}, b: 2}",
+        "{a: 'http://x/{'} {a: 2}",
+        "{a: [1, 2, 3], a: 4}",
+        "[]",
+        "1 2 3",
+    ];
+    let pointers = [
+        "", "/a", "/a/b", "/a/b/1", "/a/b/2/c", "/a/d", "/e", "/0", "/1/a", "/k's/xA",
+    ];
+    for input in inputs {
+        let whole = crate::repair_to_string(input, &o).unwrap();
+        for ptr in pointers {
+            let tokens = crate::pointer::parse(ptr).unwrap();
+            let want = crate::pointer::find(&whole, &tokens).map(str::to_string);
+            let got = crate::repair_extract(input, ptr, &o);
+            match want {
+                Some(want) => assert_eq!(got.unwrap(), want, "{input} {ptr}"),
+                None => assert_eq!(
+                    got.unwrap_err().kind,
+                    RepairErrorKind::PointerNotFound(ptr.to_string()),
+                    "{input} {ptr}"
+                ),
+            }
+        }
+    }
+}
+
+#[test]
+fn extract_stops_repairing_once_the_target_is_complete() {
+    // The repairs after `/a` would go over the limit, but they are never made.
+    let o = Options {
+        max_repairs: 2,
+        ..Default::default()
+    };
+    let input = "{a: [1, 2], b: [x, y, z, w]}";
+    assert!(crate::repair_to_string(input, &o).is_err());
+    assert_eq!(crate::repair_extract(input, "/a", &o).unwrap(), "[1,2]");
+    assert!(crate::repair_extract(input, "/b", &o).is_err());
+}
+
+#[test]
+fn extract_missing_or_malformed_pointer() {
+    let o = Options::default();
+    for ptr in ["/b", "/a/1", "/a/01", "/a/-", "/a/0/x", "/n/0"] {
+        let err = crate::repair_extract("{a: [1], n: 5}", ptr, &o).unwrap_err();
+        assert_eq!(err.kind, RepairErrorKind::PointerNotFound(ptr.to_string()));
+    }
+    let err = crate::repair_extract("{}", "a", &o).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Parse(_)));
+    let err = crate::repair_extract("{}", "/a~2", &o).unwrap_err();
+    assert_eq!(err.position, 2);
+}
+
+#[test]
+fn annotate_source_offsets_after_each_value() {
+    let o = Options {
+        annotate_source: true,
+        output_format: OutputFormat::Json5,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string("[1, 'x', {b: [true]}]", &o).unwrap();
+    assert_eq!(
+        out,
+        "[1/* @src:1 */,'x'/* @src:4 */,{b:[true/* @src:14 */]/* @src:13 */}/* @src:9 */]/* @src:0 */"
+    );
+    // Valid input is parsed too; offsets count from the input start, past any fence.
+    let out = crate::repair_to_string(r#"{"a": 1}"#, &o).unwrap();
+    assert_eq!(out, "{a:1/* @src:6 */}/* @src:0 */");
+    let out = crate::repair_to_string("```json\n{a: null}\n```", &o).unwrap();
+    assert_eq!(out, "{a:null/* @src:12 */}/* @src:8 */");
+    // A filled-in missing value has no source.
+    let out = crate::repair_to_string("{a:}", &o).unwrap();
+    assert_eq!(out, "{a:''}/* @src:0 */");
+    // The annotated output still repairs to the plain JSON.
+    let plain = crate::repair_to_string(&out, &Options::default()).unwrap();
+    assert_eq!(plain, r#"{"a":""}"#);
+    // Offsets are in the input as given, before mojibake or alternative quotes were fixed.
+    let fixed = Options {
+        fix_mojibake: true,
+        alt_quotes: crate::options::GUILLEMET_QUOTES.to_vec(),
+        ..o.clone()
+    };
+    let out = crate::repair_to_string("{\"x\": \"â€œâ€\u{9D}\", b: 1}", &fixed).unwrap();
+    assert_eq!(out, "{x:'“”'/* @src:6 */,b:1/* @src:27 */}/* @src:0 */");
+    let out = crate::repair_to_string("{«a»: 1}", &fixed).unwrap();
+    assert_eq!(out, "{a:1/* @src:8 */}/* @src:0 */");
+    // Strict JSON output has no room for the comments.
+    let json = Options {
+        annotate_source: true,
+        ..Default::default()
+    };
+    let err = crate::repair_to_string("[1]", &json).unwrap_err();
+    assert!(
+        matches!(err.kind, crate::RepairErrorKind::Parse(_)),
+        "{err:?}"
+    );
+    let mut buf = Vec::new();
+    assert!(crate::repair_to_writer_streaming("[1]", &json, &mut buf).is_err());
+}
+
+#[test]
+fn force_container_wraps_scalars_only() {
+    let arr = Options {
+        force_container: ForceContainer::Array,
+        ..Default::default()
+    };
+    let obj = Options {
+        force_container: ForceContainer::Object,
+        ..Default::default()
+    };
+    for (s, want_arr, want_obj) in [
+        ("5", "[5]", r#"{"value":5}"#),
+        ("'hi'", r#"["hi"]"#, r#"{"value":"hi"}"#),
+        ("True", "[true]", r#"{"value":true}"#),
+        (" null ", "[ null ]", r#"{"value": null }"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &arr).unwrap(), want_arr, "{s:?}");
+        assert_eq!(crate::repair_to_string(s, &obj).unwrap(), want_obj, "{s:?}");
+    }
+    // Containers, aggregated root values and empty input are unchanged.
+    for (s, want) in [
+        ("{a: 1}", r#"{"a":1}"#),
+        (" [1]", " [1]"),
+        ("1 2", "[1,2]"),
+        ("", ""),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &obj).unwrap(), want, "{s:?}");
+    }
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming("'x'", &arr, &mut buf).unwrap();
+    assert_eq!(buf, br#"["x"]"#);
+}
+
+fn json5() -> Options {
+    Options {
+        output_format: OutputFormat::Json5,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn json5_unquotes_identifier_keys_and_single_quotes_strings() {
+    for (s, want) in [
+        (
+            r#"{"name": "it's", "say": "a \"b\"", $id_2: [true, null, -1.5]}"#,
+            r#"{name:'it\'s',say:'a "b"',$id_2:[true,null,-1.5]}"#,
+        ),
+        (
+            r#"{"two words": 1, "9lives": 2, "é": "\n",}"#,
+            r#"{'two words':1,'9lives':2,'é':'\n'}"#,
+        ),
+        ("'plain'", "'plain'"),
+        (r#"{"k": "key:"}"#, "{k: 'key:'}"),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &json5()).unwrap(), want, "{s:?}");
+    }
+}
+
+#[test]
+fn json5_round_trips_through_repair() {
+    for s in [
+        r#"{"a": [1, "x'y", {"b c": "d\"e"}], "f": null, "g": "\\"}"#,
+        r#"[{"id": 1, "tags": ["a", "b"]}, {"id": 2, "note": "it's \"ok\""}]"#,
+        r#"{"unicode": "é😀", "$": {"_": -0.5e-3}}"#,
+    ] {
+        let strict = crate::repair_to_string(s, &Options::default()).unwrap();
+        let out = crate::repair_to_string(s, &json5()).unwrap();
+        assert_ne!(out, strict);
+        let back = crate::repair_to_string(&out, &Options::default()).unwrap();
+        let back: serde_json::Value = serde_json::from_str(&back).unwrap();
+        let orig: serde_json::Value = serde_json::from_str(&strict).unwrap();
+        assert_eq!(back, orig, "{out}");
+    }
+}
+
+#[test]
+fn json5_applies_to_writer_stream_and_extract() {
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming(r#"{"a": "x"}"#, &json5(), &mut buf).unwrap();
+    assert_eq!(buf, b"{a: 'x'}");
+    let mut r = crate::StreamRepairer::new(json5());
+    assert_eq!(
+        r.push("{\"a\": 'x'}\n").unwrap().as_deref(),
+        Some("{a:'x'}")
+    );
+    let out = crate::repair_extract("{a: {b: 'c'}}", "/a", &json5()).unwrap();
+    assert_eq!(out, "{b:'c'}");
+    // Parsing to a value always goes through strict JSON.
+    let v = crate::loads("{a: 'x'}", &json5()).unwrap();
+    assert_eq!(v["a"], "x");
+}
+
+fn escaping_slashes() -> Options {
+    Options {
+        escape_slashes: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn escape_slashes_only_inside_strings() {
+    let o = escaping_slashes();
+    let out =
+        crate::repair_to_string("{'a/b': '</script>', c: [1, 2,], // x\n d: 1/2}", &o).unwrap();
+    assert_eq!(out, r#"{"a\/b":"<\/script>","c":[1,2],"d":"1\/2"}"#);
+    // Valid input is escaped too, keeping its spacing; an existing `\/` and `\\` before `/` stay well-formed.
+    let out = crate::repair_to_string(r#"["a\/b", "c\\/d", "e/f"]"#, &o).unwrap();
+    assert_eq!(out, r#"["a\/b", "c\\\/d", "e\/f"]"#);
+    // Off by default.
+    let out = crate::repair_to_string("['</p>']", &Options::default()).unwrap();
+    assert_eq!(out, r#"["</p>"]"#);
+}
+
+#[test]
+fn escape_slashes_leaves_annotations_and_applies_to_writer() {
+    let o = Options {
+        annotate_source: true,
+        output_format: OutputFormat::Json5,
+        ..escaping_slashes()
+    };
+    let out = crate::repair_to_string("['/']", &o).unwrap();
+    assert_eq!(out, r#"['\/'/* @src:1 */]/* @src:0 */"#);
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming("{a: '/'}", &escaping_slashes(), &mut buf).unwrap();
+    assert_eq!(buf, br#"{"a":"\/"}"#);
+    let j5 = Options {
+        output_format: OutputFormat::Json5,
+        ..escaping_slashes()
+    };
+    assert_eq!(
+        crate::repair_to_string("{a: '/'}", &j5).unwrap(),
+        r"{a:'\/'}"
+    );
+}
+
+fn minimal_spacing() -> Options {
+    Options {
+        compact_spacing: CompactSpacing::Minimal,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn minimal_spacing_puts_one_space_after_separators() {
+    let o = minimal_spacing();
+    let out =
+        crate::repair_to_string("{a: [1, [2,3], {b: {c: null,}}], 'd:e': 'x, y'", &o).unwrap();
+    assert_eq!(
+        out,
+        r#"{"a": [1, [2, 3], {"b": {"c": null}}], "d:e": "x, y"}"#
+    );
+    // Valid input is re-spaced: existing newlines and indentation collapse to the same form.
+    let out = crate::repair_to_string("{\n  \"a\" :  [1 ,\n 2],\n  \"b\": {}\n}", &o).unwrap();
+    assert_eq!(out, r#"{"a": [1, 2], "b": {}}"#);
+    // Escaped quotes do not end the string early.
+    let out = crate::repair_to_string(r#"["a\" :b, \"c",]"#, &o).unwrap();
+    assert_eq!(out, r#"["a\" :b, \"c"]"#);
+    // Off by default.
+    let out = crate::repair_to_string("{a: [1, 2]}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":[1,2]}"#);
+}
+
+#[test]
+fn minimal_spacing_keeps_annotations_and_applies_to_writer() {
+    let o = Options {
+        annotate_source: true,
+        output_format: OutputFormat::Json5,
+        ..minimal_spacing()
+    };
+    let out = crate::repair_to_string("[1,2]", &o).unwrap();
+    assert_eq!(out, "[1/* @src:1 */, 2/* @src:3 */]/* @src:0 */");
+    let mut buf = Vec::new();
+    crate::repair_to_writer_streaming("{a:1,b:2}", &minimal_spacing(), &mut buf).unwrap();
+    assert_eq!(buf, br#"{"a": 1, "b": 2}"#);
+}
+
+fn indent_detect() -> Options {
+    Options {
+        indent_detect: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn indent_detect_matches_tab_and_four_space_input() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..indent_detect()
+        };
+        let out = crate::repair_to_string("{\n\ta: 1,\n\tb: [1, 2,],\n\tc: {}\n}\n", &o).unwrap();
+        assert_eq!(
+            out, "{\n\t\"a\": 1,\n\t\"b\": [\n\t\t1,\n\t\t2\n\t],\n\t\"c\": {}\n}",
+            "{engine:?}"
+        );
+        let out = crate::repair_to_string("[\n    {id: 1,\n        tags: ['x']}\n]", &o).unwrap();
+        assert_eq!(
+            out,
+            "[\n    {\n        \"id\": 1,\n        \"tags\": [\n            \"x\"\n        ]\n    }\n]",
+            "{engine:?}"
+        );
+    }
+}
+
+#[test]
+fn indent_detect_uses_the_most_common_unit() {
+    let o = indent_detect();
+    // Mixed tabs and spaces: four spaces win two to one, and the tab line is re-indented.
+    let s = "{\n    \"a\": 1,\n\t\"b\": \"{ not [ nested\",\n    \"c\": 3\n}";
+    let out = crate::repair_to_string(s, &o).unwrap();
+    assert_eq!(
+        out,
+        "{\n    \"a\": 1,\n    \"b\": \"{ not [ nested\",\n    \"c\": 3\n}"
+    );
+    // One-line input has no unit, so the output stays compact.
+    let out = crate::repair_to_string("{a: 1, b: [2]}", &o).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":[2]}"#);
+    // Off by default.
+    let out = crate::repair_to_string("{\n\ta: 1\n}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":1}"#);
+}
+
+#[test]
+fn indent_detect_reads_the_input_before_rewrites() {
+    // The set literal is rewritten to an array on a compacted copy of the input; the indent
+    // unit still comes from the input as given.
+    let o = Options {
+        compat_python_friendly: true,
+        ..indent_detect()
+    };
+    let out = crate::repair_to_string("{\n    \"a\": {1, 2},\n    \"b\": 1\n}", &o).unwrap();
+    assert_eq!(
+        out,
+        "{\n    \"a\": [\n        1,\n        2\n    ],\n    \"b\": 1\n}"
+    );
+}
+
+#[test]
+fn align_values_pads_keys_per_object() {
+    let o = Options {
+        align_values: true,
+        ..indent_detect()
+    };
+    let s = "{\n  name: 'app',\n  version: 2,\n  db: {host: 'x', port: 5432},\n  tags: []\n}";
+    let out = crate::repair_to_string(s, &o).unwrap();
+    assert_eq!(
+        out,
+        concat!(
+            "{\n",
+            "  \"name\":    \"app\",\n",
+            "  \"version\": 2,\n",
+            "  \"db\":      {\n",
+            "    \"host\": \"x\",\n",
+            "    \"port\": 5432\n",
+            "  },\n",
+            "  \"tags\":    []\n",
+            "}"
+        )
+    );
+    // Every value of the outer object starts in the same column.
+    let value_column = |l: &str| l.len() - l[l.find(':').unwrap() + 1..].trim_start().len();
+    for line in out.lines().filter(|l| l.starts_with("  \"")) {
+        assert_eq!(value_column(line), 13, "{line:?}");
+    }
+    // One-line input is not indented, so there is nothing to align.
+    let out = crate::repair_to_string("{a: 1, long: 2}", &o).unwrap();
+    assert_eq!(out, r#"{"a":1,"long":2}"#);
+}
+
+#[test]
+fn crlf_changes_only_layout_line_breaks() {
+    let o = Options {
+        crlf: true,
+        ..indent_detect()
+    };
+    // The escaped newline inside the string value is left as `\n`.
+    let out = crate::repair_to_string("{\n  a: 'x\\ny',\n  b: [1]\n}", &o).unwrap();
+    assert_eq!(
+        out,
+        "{\r\n  \"a\": \"x\\ny\",\r\n  \"b\": [\r\n    1\r\n  ]\r\n}"
+    );
+    // Compact output has no line breaks to change.
+    let out = crate::repair_to_string("{a: 'x\\ny', b: [1]}", &o).unwrap();
+    assert_eq!(out, r#"{"a":"x\ny","b":[1]}"#);
+    let (compact, pretty) = crate::repair_to_string_both("{a: [1]}", 2, &o).unwrap();
+    assert_eq!(compact, r#"{"a":[1]}"#);
+    assert_eq!(pretty, "{\r\n  \"a\": [\r\n    1\r\n  ]\r\n}");
+}
+
+#[test]
+fn repair_both_returns_matching_compact_and_pretty() {
+    let (compact, pretty) =
+        crate::repair_to_string_both("{a: [1, 2], b: {}, c: 'x y'}", 2, &Options::default())
+            .unwrap();
+    assert_eq!(compact, r#"{"a":[1,2],"b":{},"c":"x y"}"#);
+    assert_eq!(
+        pretty,
+        "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {},\n  \"c\": \"x y\"\n}"
+    );
+    // Valid input keeps its values but not its layout.
+    let (compact, pretty) =
+        crate::repair_to_string_both("[ 1 ,\n 2 ]", 4, &Options::default()).unwrap();
+    assert_eq!(compact, "[1,2]");
+    assert_eq!(pretty, "[\n    1,\n    2\n]");
+    let a: serde_json::Value = serde_json::from_str(&compact).unwrap();
+    let b: serde_json::Value = serde_json::from_str(&pretty).unwrap();
+    assert_eq!(a, b);
+}
+
+#[test]
+fn raw_message_safe_output_embeds_as_is() {
+    let opts = |engine| Options {
+        engine,
+        raw_message_safe: true,
+        output_bom: true,
+        indent_detect: true,
+        compact_spacing: CompactSpacing::Minimal,
+        output_format: OutputFormat::Json5,
+        ..Options::default()
+    };
+    let cases = [
+        (
+            "  {\"a\": [1, 2], \"b\": \"x y\"}\n",
+            "{\"a\":[1,2],\"b\":\"x y\"}",
+        ),
+        (
+            "\u{FEFF}{\n    a: 1,\n    b: 'x y'\n}\n",
+            "{\"a\":1,\"b\":\"x y\"}",
+        ),
+        ("```json\n[1, 2]\n```\n", "[1,2]"),
+        (" \"hi\" ", "\"hi\""),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (inp, want) in cases {
+            let out = crate::repair_to_string(inp, &opts(engine)).unwrap();
+            assert_eq!(out, want, "engine={:?} input={:?}", engine, inp);
+            assert!(crate::minify(&out).is_ok_and(|m| m == out));
+            let mut buf = Vec::new();
+            crate::repair_to_writer(inp, &opts(engine), &mut buf).unwrap();
+            assert_eq!(buf, want.as_bytes(), "engine={:?} input={:?}", engine, inp);
+        }
+    }
+}
+
+#[test]
+fn skeleton_replaces_scalars_with_type_names() {
+    let cases = [
+        (
+            "{a: 1, b: 'x', c: true, d: null, e: {f: 2.5}}",
+            r#"{"a":"number","b":"string","c":"boolean","d":"null","e":{"f":"number"}}"#,
+        ),
+        // Arrays list each distinct element skeleton once, in order of first appearance.
+        (
+            "[1, 2, 'x', 3, false, 'y']",
+            r#"["number","string","boolean"]"#,
+        ),
+        (
+            "[{n: 1}, {n: 2}, {n: 3, m: 'x'}, null]",
+            r#"[{"n":"number"},{"n":"number","m":"string"},"null"]"#,
+        ),
+        ("[[1, 2], [3], ['a']]", r#"[["number"],["string"]]"#),
+        ("{a: [], b: {}}", r#"{"a":[],"b":{}}"#),
+        ("\"hi\"", r#""string""#),
+        ("", ""),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let opts = Options {
+            engine,
+            ..Options::default()
+        };
+        for (inp, want) in cases {
+            let out = crate::repair_to_skeleton(inp, &opts).unwrap();
+            assert_eq!(out, want, "engine={:?} input={:?}", engine, inp);
+        }
+    }
+    // Layout and annotation options do not leak into the skeleton.
+    let opts = Options {
+        output_format: OutputFormat::Json5,
+        annotate_source: true,
+        output_bom: true,
+        ..Options::default()
+    };
+    assert_eq!(
+        crate::repair_to_skeleton("{'a b': [1]}", &opts).unwrap(),
+        r#"{"a b":["number"]}"#
+    );
+    let deep = "[".repeat(100_000);
+    let out = crate::repair_to_skeleton(&deep, &Options::default()).unwrap();
+    assert_eq!(out, "[".repeat(99_999) + "[]" + &"]".repeat(99_999));
+}
+
+#[test]
+fn envelope_reports_whether_and_how_much_was_repaired() {
+    let o = Options {
+        envelope: true,
+        ..Default::default()
+    };
+    let env = |input: &str| crate::repair_to_string(input, &o).unwrap();
+    assert_eq!(
+        env(r#"{"a": [1, 2]}"#),
+        r#"{"value":{"a": [1, 2]},"repaired":false,"repairs":0}"#
+    );
+    assert_eq!(
+        env("  \"s\"\n"),
+        r#"{"value":"s","repaired":false,"repairs":0}"#
+    );
+    assert_eq!(
+        env("{a: 1, b: 'x'}"),
+        r#"{"value":{"a":1,"b":"x"},"repaired":true,"repairs":3}"#
+    );
+    assert_eq!(env(""), r#"{"value":null,"repaired":false,"repairs":0}"#);
+    // Each value of a split input gets its own envelope and count.
+    let parts = crate::repair_split(r#"{"a":1} [1,"#, &o).unwrap();
+    assert_eq!(
+        parts,
+        [
+            r#"{"value":{"a":1},"repaired":false,"repairs":0}"#,
+            r#"{"value":[1],"repaired":true,"repairs":1}"#,
+        ]
+    );
+    // The BOM goes in front of the envelope, not inside it.
+    let o = Options {
+        output_bom: true,
+        ..o
+    };
+    assert_eq!(
+        crate::repair_to_string("[1]", &o).unwrap(),
+        "\u{FEFF}{\"value\":[1],\"repaired\":false,\"repairs\":0}"
+    );
+}
+
+#[test]
+fn number_format_rewrites_numbers_only() {
+    let two_decimals =
+        crate::NumberFormat::new(|raw: &str| format!("{:.2}", raw.parse::<f64>().unwrap()));
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            number_format: Some(two_decimals.clone()),
+            ..Default::default()
+        };
+        let out = crate::repair_to_string("{price: 12.5, qty: 3, sku: '100', '7': [-1e1]}", &o);
+        assert_eq!(
+            out.unwrap(),
+            r#"{"price":12.50,"qty":3.00,"sku":"100","7":[-10.00]}"#,
+            "{engine:?}"
+        );
+    }
+    // A result that is not a JSON number fails the repair.
+    let o = Options {
+        number_format: Some(crate::NumberFormat::new(|raw: &str| format!("${raw}"))),
+        ..Default::default()
+    };
+    let err = crate::repair_to_string("[1]", &o).unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::Parse(_)));
+    // The output has no input offsets to report.
+    let err = crate::repair_to_string("// n\n{a: 1}", &o).unwrap_err();
+    assert_eq!(err.position, 0);
+}
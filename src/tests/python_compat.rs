@@ -273,3 +273,36 @@ fn test_html_in_json() {
     let input = r#"{"html": "<h3>Passie voor techniek"?</h3>"}"#;
     assert_repair_eq(input, r#"{"html": "<h3>Passie voor techniek\"?</h3>"}"#);
 }
+
+#[test]
+fn test_python_set_literals_become_arrays() {
+    let cases = [
+        ("{1,2,3}", "[1,2,3]"),
+        ("{1, 2, 3}", "[1,2,3]"),
+        ("{\"x\": {1, 2},\n \"y\": 3}", r#"{"x":[1,2],"y":3}"#),
+        ("{'a','b'}", r#"["a","b"]"#),
+        (r#"{"x":{1,2},"y":{}}"#, r#"{"x":[1,2],"y":{}}"#),
+        ("{(1,2),'x'}", r#"[[1,2],"x"]"#),
+        // A colon anywhere at the brace's own depth keeps it an object.
+        ("{a, b: 1}", r#"{"a":"","b":1}"#),
+        (r#"{"k,":1,"s":"{1, 2}"}"#, r#"{"k,":1,"s":"{1, 2}"}"#),
+        ("{}", "{}"),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            compat_python_friendly: true,
+            parens_as_arrays: true,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+    }
+    // Off by default: the elements are read as keys.
+    assert_repair_eq("{1, 2}", r#"{"1": "", "2": ""}"#);
+}
@@ -287,3 +287,434 @@ fn st_chunks_pure_fn_basic() {
     // In aggregate mode, all root-level values (including JSONP's inner value) are aggregated
     assert_eq!(arr.len(), 3);
 }
+
+#[test]
+fn stream_stray_bom_between_values() {
+    let mut r = StreamRepairer::new(Options::default());
+    let mut out = String::new();
+    for c in ["{a:1}\u{FEFF}", "\u{200B}{b:2}"] {
+        if let Some(s) = r.push(c).unwrap() {
+            out.push_str(&s);
+        }
+    }
+    if let Some(s) = r.flush().unwrap() {
+        out.push_str(&s);
+    }
+    assert_eq!(out, r#"{"a":1}{"b":2}"#);
+}
+
+#[test]
+fn stream_validate_only_reports_status_per_value() {
+    let mut r = StreamRepairer::new(Options::default());
+    let mut statuses = Vec::new();
+    for c in ["{\"a\":1}\n{b:", "2}\n[1, 2", "]\n"] {
+        statuses.extend(r.push_validate(c).unwrap());
+    }
+    statuses.extend(r.flush_validate().unwrap());
+    let summary: Vec<(bool, &str)> = statuses
+        .iter()
+        .map(|s| (s.valid, s.text.as_str()))
+        .collect();
+    assert_eq!(
+        summary,
+        vec![(true, "{\"a\":1}"), (false, "{b:2}"), (true, "[1, 2]")]
+    );
+    let err = statuses[1].error.as_ref().unwrap();
+    assert_eq!(err.position, 1);
+    // No repaired output is produced in validate-only mode.
+    assert_eq!(r.push("{c:3}").unwrap(), None);
+}
+
+#[test]
+fn stream_validate_only_option_and_unterminated_tail() {
+    let opts = Options {
+        stream_validate_only: true,
+        stream_ndjson_aggregate: true,
+        ..Options::default()
+    };
+    let mut r = StreamRepairer::new(opts);
+    assert_eq!(r.push("{\"a\":1}\n{\"b\":").unwrap(), None);
+    // Statuses completed by `push` are kept until the next validate call drains them.
+    let first = r.push_validate("").unwrap();
+    assert_eq!(first.len(), 1);
+    assert!(first[0].valid);
+    let tail = r.flush_validate().unwrap();
+    assert_eq!(tail.len(), 1);
+    assert!(!tail[0].valid);
+    assert_eq!(tail[0].text, "{\"b\":");
+}
+
+#[test]
+fn stream_trailing_line_comment_split_across_chunks() {
+    let out = crate::repair_chunks_to_string(
+        ["{\"a\":1} // do", "ne\n{\"b\":2} // x"],
+        &Options::default(),
+    )
+    .unwrap();
+    assert_eq!(out, r#"{"a":1}{"b":2}"#);
+    let out =
+        crate::repair_chunks_to_string(["{\"a\":1 // done }", "\n}"], &Options::default()).unwrap();
+    assert_eq!(out, r#"{"a":1}"#);
+}
+
+fn recovering(opts: Options) -> crate::StreamRepairer {
+    let mut r = crate::StreamRepairer::new(opts);
+    r.set_recover(true);
+    r
+}
+
+#[test]
+fn st_recover_skips_to_next_value() {
+    let opts = Options {
+        max_repairs: 2,
+        ..Default::default()
+    };
+    let mut r = recovering(opts.clone());
+    let mut out = String::new();
+    for chunk in [
+        "{\"a\":1}\n{a b c d e}\n",
+        "junk ",
+        "here {\"b\":",
+        "2}\n[1]\n",
+    ] {
+        if let Some(s) = r.push(chunk).unwrap() {
+            out.push_str(&s);
+        }
+    }
+    assert!(r.flush().unwrap().is_none());
+    assert_eq!(out, r#"{"a":1}{"b":2}[1]"#);
+    let skipped = r.take_skipped();
+    assert_eq!(skipped.len(), 1);
+    assert!(!skipped[0].valid);
+    assert_eq!(skipped[0].text, "{a b c d e}\njunk here");
+    assert!(matches!(
+        skipped[0].error.as_ref().unwrap().kind,
+        RepairErrorKind::TooManyRepairs(2)
+    ));
+    assert!(r.take_skipped().is_empty());
+
+    // Without recovery the same input fails the push.
+    let mut r = crate::StreamRepairer::new(opts);
+    assert!(r.push("{\"a\":1}\n{a b c d e}\n").is_err());
+}
+
+#[test]
+fn st_recover_reports_failed_tail_on_flush() {
+    let opts = Options {
+        reject_if_invalid: true,
+        ..Default::default()
+    };
+    let mut r = recovering(opts);
+    assert_eq!(r.push("[1]\n").unwrap().as_deref(), Some("[1]"));
+    assert!(r.push("{broken").unwrap().is_none());
+    assert!(r.take_skipped().is_empty());
+    assert!(r.flush().unwrap().is_none());
+    let skipped = r.take_skipped();
+    assert_eq!(skipped.len(), 1);
+    assert_eq!(skipped[0].text, "{broken");
+    assert!(matches!(
+        skipped[0].error.as_ref().unwrap().kind,
+        RepairErrorKind::InvalidJson(_)
+    ));
+}
+
+#[test]
+fn st_recover_writer_and_aggregate() {
+    let opts = Options {
+        max_repairs: 2,
+        stream_ndjson_aggregate: true,
+        ..Default::default()
+    };
+    let mut r = recovering(opts);
+    let mut w = Vec::new();
+    r.push_to_writer("{\"a\":1}\n{a b c d e}\nxx [3]\n", &mut w)
+        .unwrap();
+    r.flush_to_writer(&mut w).unwrap();
+    assert_eq!(String::from_utf8(w).unwrap(), r#"[{"a":1}, [3]]"#);
+    assert_eq!(r.take_skipped()[0].text, "{a b c d e}\nxx");
+}
+
+#[test]
+fn st_max_buffer_overflow_errors_and_resets() {
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_max_buffer(8);
+    assert!(r.push("{\"a\":").unwrap().is_none());
+    let err = r.push("\"long value").unwrap_err();
+    assert_eq!(err.kind, RepairErrorKind::BufferOverflow(8));
+    assert_eq!(err.position, 16);
+    // The incomplete value is gone; the stream carries on at the root level.
+    assert_eq!(r.push("[1]\n").unwrap().as_deref(), Some("[1]"));
+    assert!(r.flush().unwrap().is_none());
+}
+
+#[test]
+fn st_max_buffer_keeps_completed_output() {
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_max_buffer(4);
+    let err = r.push("{\"a\":1}\n[1, 2, 3").unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::BufferOverflow(4)));
+    assert_eq!(r.flush().unwrap().as_deref(), Some(r#"{"a":1}"#));
+
+    let mut w = Vec::new();
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_max_buffer(4);
+    assert!(r.push_to_writer("[0]\n{\"b\": 22", &mut w).is_err());
+    r.flush_to_writer(&mut w).unwrap();
+    assert_eq!(String::from_utf8(w).unwrap(), "[0]");
+}
+
+#[test]
+fn st_max_buffer_under_cap_is_unaffected() {
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_max_buffer(16);
+    assert!(r.push("{\"a\":").unwrap().is_none());
+    assert_eq!(r.push(" 1}\n").unwrap().as_deref(), Some(r#"{"a": 1}"#));
+    assert_eq!(r.push("{b: 2}\n").unwrap().as_deref(), Some(r#"{"b":2}"#));
+}
+
+#[test]
+fn st_escape_split_across_pushes() {
+    let mut r = StreamRepairer::new(Options::default());
+    assert!(r.push("\"abc\\").unwrap().is_none());
+    assert!(r.push("n\"").unwrap().is_none());
+    assert_eq!(r.flush().unwrap().as_deref(), Some("\"abc\\n\""));
+
+    // An escaped quote or backslash split at the boundary must not end the string, and a
+    // delimiter inside it must not close the container.
+    for (parts, want) in [
+        (["[\"abc\\", "n\"]\n"], "[\"abc\\n\"]"),
+        (["{\"a\": \"x\\", "\"]\"}\n"], "{\"a\": \"x\\\"]\"}"),
+        (["{\"a\": \"x\\", "\\\"}\n"], "{\"a\": \"x\\\\\"}"),
+        (["['it\\", "'s']\n"], "[\"it's\"]"),
+    ] {
+        let mut r = StreamRepairer::new(Options::default());
+        assert!(r.push(parts[0]).unwrap().is_none(), "{parts:?}");
+        assert_eq!(
+            r.push(parts[1]).unwrap().as_deref(),
+            Some(want),
+            "{parts:?}"
+        );
+        let mut w = Vec::new();
+        let mut r = StreamRepairer::new(Options::default());
+        r.push_to_writer(parts[0], &mut w).unwrap();
+        assert!(w.is_empty(), "{parts:?}");
+        r.push_to_writer(parts[1], &mut w).unwrap();
+        assert_eq!(String::from_utf8(w).unwrap(), want, "{parts:?}");
+    }
+}
+
+#[test]
+fn st_number_split_across_pushes() {
+    // A segment is only repaired once its root value closes, so a number or exponent cut at
+    // the boundary is read whole, never as two tokens.
+    for (parts, want) in [
+        (vec!["{\"n\":123", "45}"], r#"{"n":12345}"#),
+        (vec!["{\"n\":1e", "3}"], r#"{"n":1e3}"#),
+        (vec!["{\"n\":1e", "-3}"], r#"{"n":1e-3}"#),
+        (vec!["{n: -0.", "5E+2}"], r#"{"n":-0.5E+2}"#),
+        (vec!["[1", "2, 3", "4]"], "[12, 34]"),
+    ] {
+        let mut r = StreamRepairer::new(Options::default());
+        let mut got = Vec::new();
+        for p in &parts[..parts.len() - 1] {
+            got.push(r.push(p).unwrap());
+        }
+        assert!(got.iter().all(Option::is_none), "{parts:?}");
+        assert_eq!(
+            r.push(parts[parts.len() - 1]).unwrap().as_deref(),
+            Some(want),
+            "{parts:?}"
+        );
+        let mut w = Vec::new();
+        let mut r = StreamRepairer::new(Options::default());
+        for p in &parts {
+            r.push_to_writer(p, &mut w).unwrap();
+        }
+        assert_eq!(String::from_utf8(w).unwrap(), want, "{parts:?}");
+    }
+    // A root number waits for the flush.
+    let mut r = StreamRepairer::new(Options::default());
+    assert!(r.push("123").unwrap().is_none());
+    assert!(r.push("45").unwrap().is_none());
+    assert_eq!(r.flush().unwrap().as_deref(), Some("12345"));
+}
+
+#[test]
+fn st_flush_closes_every_open_container() {
+    // A value truncated several levels deep is closed innermost first on flush.
+    let cases = [
+        (r#"[{"a":[{"b":"#, r#"[{"a":[{"b":""}]}]"#),
+        (r#"{"x":{"y":[[{"z":"str"#, r#"{"x":{"y":[[{"z":"str"}]]}}"#),
+        (r#"[{"a":[{"b":-"#, r#"[{"a":[{"b":""}]}]"#),
+        (r#"{"a":[[[[1,"#, r#"{"a":[[[[1]]]]}"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let mut r = StreamRepairer::new(o.clone());
+            assert!(r.push(input).unwrap().is_none(), "{engine:?}: {input}");
+            assert_eq!(
+                r.flush().unwrap().as_deref(),
+                Some(want),
+                "{engine:?}: {input}"
+            );
+            // The same input pushed a byte at a time.
+            let mut r = StreamRepairer::new(o.clone());
+            for i in 0..input.len() {
+                assert!(r.push(&input[i..i + 1]).unwrap().is_none());
+            }
+            assert_eq!(
+                r.flush().unwrap().as_deref(),
+                Some(want),
+                "{engine:?}: {input}"
+            );
+        }
+    }
+}
+
+#[test]
+fn st_value_ranges_map_back_to_input() {
+    let input = "// head\n{a: 1}\n  [1, 2,] // two\ncallback({\"b\": 'x'});\n{c: [true";
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_track_ranges(true);
+    let mut ranges = Vec::new();
+    for chunk in [&input[..10], &input[10..20], &input[20..33], &input[33..]] {
+        r.push(chunk).unwrap();
+        ranges.extend(r.take_ranges());
+    }
+    r.flush().unwrap();
+    ranges.extend(r.take_ranges());
+    let got: Vec<(&str, &str)> = ranges
+        .iter()
+        .map(|v| (v.value.as_str(), &input[v.start..v.end]))
+        .collect();
+    assert_eq!(
+        got,
+        [
+            (r#"{"a":1}"#, "{a: 1}"),
+            ("[1,2]", "[1, 2,]"),
+            (r#"{"b":"x"}"#, "{\"b\": 'x'}"),
+            (r#"{"c":[true]}"#, "{c: [true"),
+        ]
+    );
+
+    // Off by default.
+    let mut r = StreamRepairer::new(Options::default());
+    r.push("{a: 1}\n").unwrap();
+    assert!(r.take_ranges().is_empty());
+}
+
+#[test]
+fn st_stats_total_the_session() {
+    let mut r = StreamRepairer::new(Options {
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Options::default()
+    });
+    assert_eq!(r.stats(), crate::StreamStats::default());
+    let mut out = String::new();
+    for chunk in ["{a: 1}\n[1, 2,]", "\n{\"ok\": true}\n{b: 'x'", "}\n[tr"] {
+        out.extend(r.push(chunk).unwrap());
+    }
+    let before_flush = r.stats();
+    assert_eq!(before_flush.values, 4);
+    assert_eq!(before_flush.repairs, 3);
+    assert_eq!(before_flush.bytes_in, 40);
+    out.extend(r.flush().unwrap());
+    let st = r.stats();
+    assert_eq!(st.values, 5);
+    assert_eq!(st.bytes_in, 40);
+    assert_eq!(st.bytes_out, out.len() as u64);
+    assert_eq!(st.repairs, before_flush.repairs + 2);
+
+    // Valid values are emitted without repairs.
+    let mut r = StreamRepairer::new(Options::default());
+    r.push("{\"a\": 1}\n[2]\n").unwrap();
+    assert_eq!(r.stats().values, 2);
+    assert_eq!(r.stats().repairs, 0);
+}
+
+#[test]
+fn st_finalize_flushes_and_resets() {
+    let mut r = StreamRepairer::new(Options {
+        output_bom: true,
+        ..Options::default()
+    });
+    r.set_recover(true);
+    assert_eq!(
+        r.push("{a: 1}\n{b: 2").unwrap().as_deref(),
+        Some("\u{feff}{\"a\":1}")
+    );
+    assert_eq!(r.finalize().unwrap().as_deref(), Some("{\"b\":2}"));
+    assert_eq!(r.stats(), crate::StreamStats::default());
+
+    // The next document starts from scratch, BOM included.
+    assert_eq!(r.push("[1, 2").unwrap(), None);
+    assert_eq!(r.finalize().unwrap().as_deref(), Some("\u{feff}[1,2]"));
+    assert_eq!(r.finalize().unwrap(), None);
+
+    // A failed flush still leaves the stream ready for reuse.
+    let mut r = StreamRepairer::new(Options {
+        max_repairs: 1,
+        ..Options::default()
+    });
+    r.push("{a: 'x', b: 'y'").unwrap();
+    assert!(r.finalize().is_err());
+    assert_eq!(r.push("[1]\n").unwrap().as_deref(), Some("[1]"));
+}
+
+#[test]
+fn st_heartbeat_reports_quiet_stretches_without_changing_output() {
+    let chunks = [
+        "{a: 'xxxx",
+        "xxxx",
+        "xx",
+        "xxxxxxxxxx",
+        "'}\n",
+        "{b: [1, 2, 3",
+        &"4".repeat(100),
+        "]}\n",
+    ];
+    let mut plain = StreamRepairer::new(Options::default());
+    let mut r = StreamRepairer::new(Options::default());
+    r.set_heartbeat(10);
+    let mut beats = Vec::new();
+    for chunk in chunks {
+        assert_eq!(r.push(chunk).unwrap(), plain.push(chunk).unwrap());
+        beats.push(r.take_heartbeats());
+    }
+    assert_eq!(r.flush().unwrap(), plain.flush().unwrap());
+    let at = |bytes_in, buffered| vec![Heartbeat { bytes_in, buffered }];
+    assert_eq!(
+        beats,
+        [
+            vec![],
+            at(13, 13),
+            vec![],
+            at(25, 25),
+            // The value completes: the count starts over.
+            vec![],
+            at(40, 12),
+            // At most one report per push.
+            at(140, 112),
+            vec![],
+        ]
+    );
+
+    // Off by default and with an interval of 0; kept by `finalize`.
+    let mut r = StreamRepairer::new(Options::default());
+    r.push(&"x".repeat(1000)).unwrap();
+    assert!(r.take_heartbeats().is_empty());
+    r.set_heartbeat(5);
+    r.finalize().unwrap();
+    r.push("[1, 2, 3").unwrap();
+    assert_eq!(r.take_heartbeats(), at(8, 8));
+    r.set_heartbeat(0);
+    r.push(&"4".repeat(100)).unwrap();
+    assert!(r.take_heartbeats().is_empty());
+}
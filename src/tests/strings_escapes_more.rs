@@ -44,6 +44,29 @@ fn mixed_backslashes_and_quotes() {
     );
 }
 
+#[test]
+fn trailing_backslash_before_closing_quote() {
+    // `\"` followed by a delimiter is a lone backslash plus the closing quote.
+    let out = crate::repair_to_string(r#"{"path": "C:\"}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"path":"C:\\"}"#);
+    let s = r#"{"dir": "C:\\Users\\Me\", "drive": 'D:\'}"#;
+    let out = crate::repair_to_string(s, &opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v["dir"], "C:\\Users\\Me\\");
+    assert_eq!(v["drive"], "D:\\");
+    let out = crate::repair_to_string(r#"["C:\", "x"]"#, &opts()).unwrap();
+    assert_eq!(out, r#"["C:\\","x"]"#);
+}
+
+#[test]
+fn escaped_quote_inside_string_is_kept() {
+    let out = crate::repair_to_string(r#"{"a": "say \"hi\" now",}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"a":"say \"hi\" now"}"#);
+    // At the end of input the final `\"` counts as a lone backslash too.
+    let out = crate::repair_to_string(r#"["\"quoted\""#, &opts()).unwrap();
+    assert_eq!(out, r#"["\"quoted\\"]"#);
+}
+
 #[test]
 fn control_chars_escaped() {
     let s = "{t:'a\\tb\\nc\\rd\\fe'}"; // \t \n \r \f
@@ -70,3 +93,617 @@ fn ensure_ascii_on_surrogate_pair() {
     let v: serde_json::Value = serde_json::from_str(&out).unwrap();
     assert_eq!(v["e"], "😀");
 }
+
+fn scoped(scope: crate::AsciiScope) -> Options {
+    Options {
+        ascii_scope: scope,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn ascii_scope_each_scope() {
+    use crate::AsciiScope::*;
+    let s = "{ключ: 'знач', 'k': ['é', {ü: 1}]}";
+    let cases = [
+        (None, r#"{"ключ":"знач","k":["é",{"ü":1}]}"#),
+        (
+            KeysOnly,
+            r#"{"\u043A\u043B\u044E\u0447":"знач","k":["é",{"\u00FC":1}]}"#,
+        ),
+        (
+            ValuesOnly,
+            r#"{"ключ":"\u0437\u043D\u0430\u0447","k":["\u00E9",{"ü":1}]}"#,
+        ),
+        (
+            All,
+            r#"{"\u043A\u043B\u044E\u0447":"\u0437\u043D\u0430\u0447","k":["\u00E9",{"\u00FC":1}]}"#,
+        ),
+    ];
+    for (scope, want) in cases {
+        assert_eq!(
+            crate::repair_to_string(s, &scoped(scope)).unwrap(),
+            want,
+            "{scope:?}"
+        );
+    }
+}
+
+#[test]
+fn ascii_scope_valid_json_fast_path() {
+    use crate::AsciiScope::*;
+    let s = r#"{"ключ": ["знач", {"😀": "😀"}]}"#;
+    let out = crate::repair_to_string(s, &scoped(KeysOnly)).unwrap();
+    assert_eq!(
+        out,
+        r#"{"\u043A\u043B\u044E\u0447":["знач",{"\uD83D\uDE00":"😀"}]}"#
+    );
+    let out = crate::repair_to_string(s, &scoped(ValuesOnly)).unwrap();
+    assert_eq!(
+        out,
+        r#"{"ключ":["\u0437\u043D\u0430\u0447",{"😀":"\uD83D\uDE00"}]}"#
+    );
+    // Members keep their order, and numbers their spelling.
+    let s = r#"{"b": "é", "a": 1, "é": {"z": 1e5, "y": "ü:"}}"#;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (scope, want) in [
+            (KeysOnly, r#"{"b":"é","a":1,"\u00E9":{"z":1e5,"y":"ü:"}}"#),
+            (
+                ValuesOnly,
+                r#"{"b":"\u00E9","a":1,"é":{"z":1e5,"y":"\u00FC:"}}"#,
+            ),
+            (
+                All,
+                r#"{"b":"\u00E9","a":1,"\u00E9":{"z":1e5,"y":"\u00FC:"}}"#,
+            ),
+        ] {
+            let o = Options {
+                engine,
+                ..scoped(scope)
+            };
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {scope:?}"
+            );
+            let mut buf = Vec::new();
+            crate::repair_to_writer(s, &o, &mut buf).unwrap();
+            assert_eq!(
+                String::from_utf8(buf).unwrap(),
+                want,
+                "{engine:?} {scope:?}"
+            );
+        }
+    }
+}
+
+#[test]
+fn ascii_scope_ensure_ascii_takes_precedence() {
+    let mut o = scoped(crate::AsciiScope::KeysOnly);
+    o.ensure_ascii = true;
+    let out = crate::repair_to_string("{ключ: 'знач'}", &o).unwrap();
+    assert!(out.is_ascii());
+}
+
+#[test]
+fn ascii_scope_llm_engine() {
+    let o = Options {
+        ascii_scope: crate::AsciiScope::KeysOnly,
+        engine: crate::options::EngineKind::LlmCompat,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string("{ключ: 'знач'}", &o).unwrap();
+    assert_eq!(out, r#"{"\u043A\u043B\u044E\u0447":"знач"}"#);
+}
+
+fn mojibake() -> Options {
+    Options {
+        fix_mojibake: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn fix_mojibake_quotes_become_delimiters() {
+    let o = mojibake();
+    let out = crate::repair_to_string("{â€œkeyâ€: â€œvalueâ€,}", &o).unwrap();
+    assert_eq!(out, r#"{"key":"value"}"#);
+    // Latin-1 decoding keeps the C1 control characters instead.
+    let out = crate::repair_to_string("{â\u{80}\u{9C}keyâ\u{80}\u{9D}: [â€˜aâ€™]}", &o).unwrap();
+    assert_eq!(out, r#"{"key":["a"]}"#);
+    // Quotes that were all that kept the input from being valid JSON.
+    let out = crate::repair_to_string("{â€œaâ€: â€œbâ€}", &o).unwrap();
+    assert_eq!(out, r#"{"a":"b"}"#);
+}
+
+#[test]
+fn fix_mojibake_inside_strings_restores_characters() {
+    let o = mojibake();
+    let s = "{quote: \"He said â€œhiâ€ â€” ok\", it: 'donâ€™t', range: \"1â€“2â€¦\"}";
+    let out = crate::repair_to_string(s, &o).unwrap();
+    assert_eq!(
+        out,
+        r#"{"quote":"He said “hi” — ok","it":"don’t","range":"1–2…"}"#
+    );
+    // Off by default: the sequences are ordinary text.
+    let out = crate::repair_to_string("[\"â€”\"]", &Options::default()).unwrap();
+    assert_eq!(out, "[\"â€”\"]");
+}
+
+#[test]
+fn alt_quotes_delimit_strings_outside_ascii_strings() {
+    let mut alt_quotes = crate::GUILLEMET_QUOTES.to_vec();
+    alt_quotes.extend_from_slice(crate::CJK_CORNER_QUOTES);
+    let cases = [
+        ("{«name»: «Zoë», n: 1}", r#"{"name":"Zoë","n":1}"#),
+        (
+            "{「名前」: 「山田」, list: [『x』, ‹y›]}",
+            r#"{"名前":"山田","list":["x","y"]}"#,
+        ),
+        // Kept inside an ASCII-quoted string.
+        (
+            "{a: \"«keep» 「this」\", b: '‹too›'}",
+            r#"{"a":"«keep» 「this」","b":"‹too›"}"#,
+        ),
+        // A raw quote inside the pair is escaped; a nested pair stays as content.
+        ("{a: «say \"hi\" «twice»»}", r#"{"a":"say \"hi\" «twice»"}"#),
+        // Quotes that were all that kept the input from being valid JSON.
+        ("{«a»: «b»}", r#"{"a":"b"}"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            alt_quotes: alt_quotes.clone(),
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+    }
+    // Off by default: the characters are ordinary text.
+    let o = Options {
+        stray_tokens: StrayTokenPolicy::Quote,
+        ..Options::default()
+    };
+    let out = crate::repair_to_string("[«a»]", &o).unwrap();
+    assert_eq!(out, r#"["«a»"]"#);
+}
+
+#[test]
+fn mixed_quote_styles_normalize_to_double() {
+    let cases = [
+        (r#"{'a': "b", "c": 'd'}"#, r#"{"a":"b","c":"d"}"#),
+        (
+            r#"{'a':"b","c":'d','e':"f","g":'h','i':'j',"k":"l",}"#,
+            r#"{"a":"b","c":"d","e":"f","g":"h","i":"j","k":"l"}"#,
+        ),
+        (
+            r#"{'a': {"b": 'c', 'd': ["e", 'f', "g'h", 'i"j']}, "k": 'l'}"#,
+            r#"{"a":{"b":"c","d":["e","f","g'h","i\"j"]},"k":"l"}"#,
+        ),
+        (
+            r#"[{'x': "it's"}, {"y": 'say "hi"'}]"#,
+            r#"[{"x":"it's"},{"y":"say \"hi\""}]"#,
+        ),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input}");
+        }
+    }
+}
+
+#[test]
+fn delimiters_inside_closed_strings_are_content() {
+    let o = Options::default();
+    let out = crate::repair_to_string(r#"{"a": 'b,c', 'd': "e}f", "g": 'h]i'}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":"b,c","d":"e}f","g":"h]i"}"#);
+    let out = crate::repair_to_string(r#"['1,2', "3]4",]"#, &o).unwrap();
+    assert_eq!(out, r#"["1,2","3]4"]"#);
+    // A quote that opens the next key does not close the string: it ends at the comma.
+    let out = crate::repair_to_string(r#"{"a": "b, "c": 1}"#, &o).unwrap();
+    assert_eq!(out, r#"{"a":"b","c":1}"#);
+}
+
+#[test]
+fn doubled_quotes_collapse_but_empty_strings_stay() {
+    let cases = [
+        (r#"{""a"": 1}"#, r#"{"a":1}"#),
+        (
+            r#"{""a b"": ""c"", ""d"": [""e"", 2],}"#,
+            r#"{"a b":"c","d":["e",2]}"#,
+        ),
+        ("{''k'': ''v''}", r#"{"k":"v"}"#),
+        // Legitimate empty strings are followed by a delimiter, never by content.
+        (r#"{"": 1,}"#, r#"{"":1}"#),
+        (r#"{"": "", "x": ["", ""],}"#, r#"{"":"","x":["",""]}"#),
+        (r#"[""x"", ""]"#, r#"["x",""]"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input}");
+        }
+    }
+    // Valid input with an empty key is returned as is.
+    let out = crate::repair_to_string(r#"{"": 1}"#, &opts()).unwrap();
+    assert_eq!(out, r#"{"": 1}"#);
+}
+
+#[test]
+fn multiline_string_keeps_structural_characters() {
+    let s = "{\"note\": \"first line\nsecond } line, ]\n\", \"n\": 1}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Options::default()
+        };
+        let out = crate::repair_to_string(s, &o).unwrap();
+        assert_eq!(
+            out, r#"{"note":"first line\nsecond } line, ]\n","n":1}"#,
+            "{engine:?}"
+        );
+        // A quote missing at the end of a line ends the string there when the next line
+        // opens a member.
+        let out = crate::repair_to_string("{\"a\": \"foo\r\n  \"b\": 1}", &o).unwrap();
+        assert_eq!(out, r#"{"a":"foo","b":1}"#, "{engine:?}");
+    }
+}
+
+#[test]
+fn closing_quote_search_is_bounded() {
+    let o = Options::default();
+    let near = format!("{{a: \"x }} {}\", b: 1}}", "y".repeat(1000));
+    let v: serde_json::Value =
+        serde_json::from_str(&crate::repair_to_string(&near, &o).unwrap()).unwrap();
+    assert_eq!(v["a"], format!("x }} {}", "y".repeat(1000)));
+    // Past the lookahead the `}` is taken as the object's closer.
+    let far = format!("{{a: \"x }} {}\", b: 1}}", "y".repeat(70 * 1024));
+    let out = crate::repair_to_string(&far, &o).unwrap();
+    assert!(out.starts_with(r#"{"a":"x "}"#), "{}", &out[..20]);
+}
+
+#[test]
+fn pasted_tab_in_string_uses_short_escape() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Options::default()
+        };
+        let out = crate::repair_to_string("{\"a\": \"x\ty\"}", &o).unwrap();
+        assert_eq!(out, r#"{"a":"x\ty"}"#, "{engine:?}");
+        let out = crate::repair_to_string("['a\u{8}\u{c}\u{1}\r\n']", &o).unwrap();
+        assert_eq!(out, r#"["a\b\f\u0001\r\n"]"#, "{engine:?}");
+
+        let o = Options {
+            short_escapes: false,
+            ..o
+        };
+        let out = crate::repair_to_string("{\"a\": \"x\ty\"}", &o).unwrap();
+        assert_eq!(out, r#"{"a":"x\u0009y"}"#, "{engine:?}");
+        let out = crate::repair_to_string("['a\u{8}\u{c}\r\n', 'b\\\\n']", &o).unwrap();
+        assert_eq!(out, r#"["a\u0008\u000C\u000D\u000A","b\\n"]"#, "{engine:?}");
+        // Short escapes already in valid input are spelled out too.
+        let out = crate::repair_to_string(r#"{"a":"x\ty"}"#, &o).unwrap();
+        assert_eq!(out, r#"{"a":"x\u0009y"}"#, "{engine:?}");
+    }
+}
+
+#[test]
+fn strip_trailing_line_ws_in_code_values() {
+    let s = "{\"code\":\"fn main() {  \\n    let x = 1;\\t\\n    x  \\r\\n}  \",\"k  \\n\":\"a \\\\n\"}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            strip_trailing_line_ws: true,
+            ..Default::default()
+        };
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"code":"fn main() {\n    let x = 1;\n    x\r\n}  ","k  \n":"a \\n"}"#,
+            "{engine:?}"
+        );
+    }
+    // Off by default.
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    assert!(out.contains(r#"{  \n"#), "{out}");
+}
+
+#[test]
+fn collapse_ws_in_string_values() {
+    let s = "{\"k  ey\": \"a\\t\\t b\", \"b\": \"x   y\", \"c\": \"p \\t \\f q\\n\\n  r\", 'd': 'raw\ttab', \"e\": [\"  both  ends  \", \"\\u0020\\u0020z\"]}";
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let mut o = Options {
+            engine,
+            collapse_ws: true,
+            ..Default::default()
+        };
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"k  ey":"a b","b":"x y","c":"p q\n\n r","d":"raw tab","e":[" both ends "," z"]}"#,
+            "{engine:?}"
+        );
+        o.collapse_ws_newlines = true;
+        assert_eq!(
+            crate::repair_to_string(s, &o).unwrap(),
+            r#"{"k  ey":"a b","b":"x y","c":"p q r","d":"raw tab","e":[" both ends "," z"]}"#,
+            "{engine:?}"
+        );
+    }
+    // Off by default, and the newline sub-option does nothing on its own.
+    let o = Options {
+        collapse_ws_newlines: true,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(s, &o).unwrap();
+    assert!(out.contains(r#""x   y""#), "{out}");
+}
+
+#[test]
+fn mixed_escaped_and_closing_quotes() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        for (s, want) in [
+            (r#"{"a": "he said \"hi'}"#, r#"{"a":"he said \"hi"}"#),
+            (
+                r#"{"a": "he said \"hi', "b": 2}"#,
+                r#"{"a":"he said \"hi","b":2}"#,
+            ),
+            (r#"["a \"b', "c"]"#, r#"["a \"b","c"]"#),
+            (r#"{'a': 'it\'s"}"#, r#"{"a":"it's"}"#),
+            // The string's own quote still wins when it closes later.
+            (
+                r#"{"a": "say \"x', then \"y\"", b: 1}"#,
+                r#"{"a":"say \"x', then \"y\"","b":1}"#,
+            ),
+            // Without an escaped quote, the other kind is content.
+            (r#"{"a":"x', y"}"#, r#"{"a":"x', y"}"#),
+        ] {
+            assert_eq!(
+                crate::repair_to_string(s, &o).unwrap(),
+                want,
+                "{engine:?} {s:?}"
+            );
+        }
+    }
+}
+
+#[test]
+fn minimal_escapes_keeps_only_required_escapes() {
+    let input = r#"{"key": "\/a\/b \u0041\u00e9\uD83D\uDE00 \u0022q\u005C \u0009\u0001", x: 1}"#;
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let mut o = Options {
+            engine,
+            minimal_escapes: true,
+            ..Default::default()
+        };
+        assert_eq!(
+            crate::repair_to_string(input, &o).unwrap(),
+            r#"{"key":"/a/b Aé😀 \"q\\ \t\u0001","x":1}"#,
+            "{engine:?}"
+        );
+        // Non-ASCII stays escaped where ascii_scope asks for it.
+        o.ascii_scope = crate::options::AsciiScope::ValuesOnly;
+        assert_eq!(
+            crate::repair_to_string(r#"{"é": "\u00E9\/"}"#, &o).unwrap(),
+            r#"{"é":"\u00E9/"}"#,
+            "{engine:?}"
+        );
+    }
+    // A lone surrogate escape is dropped while parsing, so it never reaches the rewrite.
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            minimal_escapes: true,
+            ..Default::default()
+        };
+        for s in [r#"["x\ud800y"]"#, r#"["x\uDC00y"]"#] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            assert_eq!(out, r#"["xy"]"#, "{engine:?} {s:?}");
+        }
+    }
+}
+
+#[test]
+fn escapes_in_quoted_keys_are_decoded() {
+    // Keys used to lose their escapes (`"a\nb"` became `"anb"`).
+    let o = Options {
+        engine: crate::options::EngineKind::Recursive,
+        ..Default::default()
+    };
+    for (s, want) in [
+        (r#"{"a\nb": 1,}"#, r#"{"a\nb":1}"#),
+        (r#"{"\u0041\uD83D\uDE00": 1,}"#, r#"{"A😀":1}"#),
+        (r#"{'a\/b': 1}"#, r#"{"a/b":1}"#),
+    ] {
+        assert_eq!(crate::repair_to_string(s, &o).unwrap(), want, "{s:?}");
+    }
+}
+
+#[test]
+fn es6_brace_unicode_escapes_in_strings() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        // BMP and astral code points; the latter become a surrogate pair when escaped.
+        let out =
+            crate::repair_to_string(r#"{"city": "Caf\u{e9}", "face": '\u{1F600}!'}"#, &o).unwrap();
+        let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+        assert_eq!(
+            v,
+            serde_json::json!({"city": "Caf\u{e9}", "face": "\u{1F600}!"})
+        );
+        // A value past U+10FFFF or a surrogate reads as U+FFFD; an empty, unterminated or
+        // non-hex brace escape is kept as written.
+        for (s, want) in [
+            (r#"["a\u{110000}b"]"#, "a\u{FFFD}b"),
+            (r#"["a\u{D800}b"]"#, "a\u{FFFD}b"),
+            (r#"["a\u{DFFF}b"]"#, "a\u{FFFD}b"),
+            (r#"["a\u{FFFFFFFFF}b"]"#, "a\u{FFFD}b"),
+            (r#"["x\u{0000041}"]"#, "xA"),
+            (r#"["a\u{}b"]"#, "a\\u{}b"),
+            (r#"{k: 'a\u{}b', n: 1}"#, "a\\u{}b"),
+            (r#"["x\u{1F600"]"#, "x\\u{1F600"),
+            (r#"["x\u{1F600", "y"]"#, "x\\u{1F600"),
+            (r#"["\u{zz}"]"#, "\\u{zz}"),
+            (r#"["\u{é}"]"#, "\\u{é}"),
+            (r#"["a\u{12 4}b"]"#, "a\\u{12 4}b"),
+        ] {
+            let out = crate::repair_to_string(s, &o).unwrap();
+            let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+            let got = v.get(0).or_else(|| v.get("k")).unwrap();
+            assert_eq!(got, want, "{engine:?} {s:?} -> {out}");
+        }
+        let out = crate::repair_to_string(r#"{k: 'a\u{}b', n: 1}"#, &o).unwrap();
+        assert_eq!(out, r#"{"k":"a\\u{}b","n":1}"#, "{engine:?}");
+    }
+    let o = Options {
+        ensure_ascii: true,
+        ..Default::default()
+    };
+    let out = crate::repair_to_string(r#"["\u{1F600}", "\u{41}"]"#, &o).unwrap();
+    assert_eq!(out, r#"["\uD83D\uDE00","A"]"#);
+}
+
+#[test]
+fn line_continuations_elide_or_keep_the_break() {
+    use crate::options::LineContinuation;
+    let cases = [
+        // input, Elide, Keep
+        (
+            "{\"a\": \"foo\\\nbar\"}",
+            r#"{"a":"foobar"}"#,
+            r#"{"a":"foo\nbar"}"#,
+        ),
+        ("['x\\\r\ny', 1]", r#"["xy",1]"#, r#"["x\r\ny",1]"#),
+        ("{\"k\\\ney\": 1}", r#"{"key":1}"#, r#"{"k\ney":1}"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        for (input, elide, keep) in cases {
+            for (mode, want) in [
+                (LineContinuation::Elide, elide),
+                (LineContinuation::Keep, keep),
+            ] {
+                let o = Options {
+                    engine,
+                    line_continuations: mode,
+                    ..Default::default()
+                };
+                let out = crate::repair_to_string(input, &o).unwrap();
+                assert_eq!(out, want, "{engine:?} {mode:?}: {input:?}");
+            }
+        }
+    }
+}
+
+#[test]
+fn fix_backslashes_keeps_windows_path_separators() {
+    let o = Options {
+        fix_backslashes: true,
+        ..Default::default()
+    };
+    let cases = [
+        (
+            r#"{"path": "C:\Users\Ann"}"#,
+            r#"{"path":"C:\\Users\\Ann"}"#,
+        ),
+        (
+            r#"{"exe": "C:\Program Files\App\bin\x.exe"}"#,
+            r#"{"exe":"C:\\Program Files\\App\bin\\x.exe"}"#,
+        ),
+        (r#"['D:\data\logs', 'E:\']"#, r#"["D:\\data\\logs","E:\\"]"#),
+        (
+            r#"{"share": "\\server\share"}"#,
+            r#"{"share":"\\server\\share"}"#,
+        ),
+        (r#"{"C:\Temp": 1}"#, r#"{"C:\\Temp":1}"#),
+        // Genuine escapes are still decoded; `\u` without hex digits is text.
+        (
+            r#"["C:\new\table\Ann", "\u00e9\/"]"#,
+            r#"["C:\new\table\\Ann","é/"]"#,
+        ),
+        (r#"["C:\users\u", "\u12"]"#, r#"["C:\\users\\u","\\u12"]"#),
+        // A backslash before a non-ASCII character.
+        (
+            r#"{"p": "C:\Users\Élodie"}"#,
+            r#"{"p":"C:\\Users\\Élodie"}"#,
+        ),
+        (r#"["\é", "D:\日本\ß"]"#, r#"["\\é","D:\\日本\\ß"]"#),
+    ];
+    for (input, want) in cases {
+        assert_eq!(crate::repair_to_string(input, &o).unwrap(), want, "{input}");
+    }
+    // Off by default: the backslash of an unknown escape is dropped.
+    let out = crate::repair_to_string(r#"{"path": "C:\Users"}"#, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"path":"C:Users"}"#);
+    // The same with a backslash before a non-ASCII character, which used to panic.
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..Default::default()
+        };
+        let out = crate::repair_to_string(r#"{"p": "C:\Users\Élodie", "q": "\é"}"#, &o);
+        assert_eq!(
+            out.as_deref(),
+            Ok(r#"{"p":"C:UsersÉlodie","q":"é"}"#),
+            "{engine:?}"
+        );
+    }
+}
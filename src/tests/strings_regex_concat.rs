@@ -118,3 +118,113 @@ fn ns_concat_unicode_ensure_ascii() {
     let out = crate::repair_to_string(s, &o).unwrap();
     assert!(out.contains("\\u"));
 }
+
+#[test]
+fn ns_bare_email_value_quoted_whole() {
+    let out = crate::repair_to_string("{email: a@b.com, n: 1}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"email":"a@b.com","n":1}"#);
+}
+
+#[test]
+fn ns_bare_absolute_path_value_quoted_whole() {
+    let out = crate::repair_to_string("{p: /usr/local/bin, q: 2}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"p":"/usr/local/bin","q":2}"#);
+    let out = crate::repair_to_string("[/tmp/x, ./rel/path]", &Options::default()).unwrap();
+    assert_eq!(out, r#"["/tmp/x","./rel/path"]"#);
+}
+
+#[test]
+fn ns_bare_version_values_quoted_whole() {
+    let out = crate::repair_to_string("{v: 1.2.3-rc1, w: v1.2.3}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"v":"1.2.3-rc1","w":"v1.2.3"}"#);
+}
+
+#[test]
+fn ns_bare_url_value_keeps_scheme_and_port() {
+    let s = "{url: http://x.com:80/a?b=1, n: 2}";
+    let out = crate::repair_to_string(s, &Options::default()).unwrap();
+    assert_eq!(out, r#"{"url":"http://x.com:80/a?b=1","n":2}"#);
+}
+
+#[test]
+fn ns_bare_multi_word_phrase_values() {
+    let cases = [
+        (
+            r#"{"note": hello world, "x": 1}"#,
+            r#"{"note":"hello world","x":1}"#,
+        ),
+        // Surrounding whitespace is trimmed; the comma still separates members.
+        (
+            r#"{"note":   hello world   , "x": 1}"#,
+            r#"{"note":"hello world","x":1}"#,
+        ),
+        (
+            "{a: it's a test. ok? yes!}",
+            r#"{"a":"it's a test. ok? yes!"}"#,
+        ),
+        (
+            "{a: Dr. Smith (retired), b: 2}",
+            r#"{"a":"Dr. Smith (retired)","b":2}"#,
+        ),
+        ("{a: a-b c_d e/f @g #h}", r#"{"a":"a-b c_d e/f @g #h"}"#),
+        (
+            "{a: see http://x.y/z now, b: 1}",
+            r#"{"a":"see http://x.y/z now","b":1}"#,
+        ),
+        ("{a: wait; what}", r#"{"a":"wait; what"}"#),
+        ("[foo bar baz, 2]", r#"["foo bar baz",2]"#),
+        // Internal spacing is kept as written.
+        ("{a: x\t\ty}", r#"{"a":"x\t\ty"}"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            stray_tokens: StrayTokenPolicy::Quote,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input:?}");
+        }
+    }
+}
+
+#[test]
+fn ns_regex_literal_still_recognized_before_delimiter() {
+    let out = crate::repair_to_string("{r: /ab+c/gi, n: 1}", &Options::default()).unwrap();
+    assert_eq!(out, r#"{"r":"/ab+c/gi","n":1}"#);
+}
+
+#[test]
+fn ns_adjacent_strings_join_when_enabled() {
+    let cases = [
+        // Three newline-separated fragments of one multi-line value.
+        (
+            "{\"text\": \"line1\\n\"\n  \"line2\\n\"\n  \"line3\"}",
+            r#"{"text":"line1\nline2\nline3"}"#,
+        ),
+        ("[\"a\" 'b'\n\"c\", \"d\"]", r#"["abc","d"]"#),
+        // The next key is not joined into the value before it.
+        ("{\"a\": \"x\"\n\"b\": 1}", r#"{"a":"x","b":1}"#),
+    ];
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            concat_adjacent_strings: true,
+            ..Default::default()
+        };
+        for (input, want) in cases {
+            let out = crate::repair_to_string(input, &o).unwrap();
+            assert_eq!(out, want, "{engine:?}: {input}");
+        }
+    }
+    // Off by default: adjacent strings stay separate values.
+    let out = crate::repair_to_string("[\"a\"\n\"b\"]", &Options::default()).unwrap();
+    assert_eq!(out, r#"["a","b"]"#);
+}
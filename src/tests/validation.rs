@@ -0,0 +1,210 @@
+use super::*;
+use crate::RepairErrorKind;
+
+fn reject_opts() -> Options {
+    Options {
+        reject_if_invalid: true,
+        ..Options::default()
+    }
+}
+
+#[test]
+fn reject_if_invalid_passes_valid_json_through() {
+    let s = r#"{"a":[1,2.5e3,"x"],"b":null}"#;
+    let out = crate::repair_to_string(s, &reject_opts()).unwrap();
+    assert_eq!(out, s);
+}
+
+#[test]
+fn reject_if_invalid_reports_first_problem_offset() {
+    let cases = [
+        ("{a:1}", 1),
+        (r#"{"a":1,}"#, 7),
+        ("[1, 2", 5),
+        (r#"{"a": 'x'}"#, 6),
+        ("[01]", 2),
+        (r#"{"a":1} trailing"#, 8),
+    ];
+    for (inp, pos) in cases {
+        let err = crate::repair_to_string(inp, &reject_opts()).unwrap_err();
+        assert!(
+            matches!(err.kind, RepairErrorKind::InvalidJson(_)),
+            "input={} err={}",
+            inp,
+            err
+        );
+        assert_eq!(err.position, pos, "input={} err={}", inp, err);
+    }
+}
+
+#[test]
+fn reject_if_invalid_applies_to_writer_and_log_paths() {
+    let mut out = Vec::new();
+    assert!(crate::repair_to_writer_streaming("{a:1}", &reject_opts(), &mut out).is_err());
+    assert!(out.is_empty());
+    assert!(crate::repair_to_string_with_log("[1,2,]", &reject_opts()).is_err());
+}
+
+#[test]
+fn reject_if_invalid_accepts_leading_bom_and_whitespace() {
+    let out = crate::repair_to_string("\u{FEFF} [1, 2] \n", &reject_opts()).unwrap();
+    let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert_eq!(v, serde_json::json!([1, 2]));
+}
+
+#[test]
+fn minify_strips_whitespace_and_keeps_tokens_verbatim() {
+    let s =
+        "\u{FEFF} {\n  \"a\" : [ 1 , 2.50, -0e+1 ],\n\t\"b\\\" c\": \"x  y\\n\", \"d\": null }\r\n";
+    let out = crate::minify(s).unwrap();
+    assert_eq!(out, r#"{"a":[1,2.50,-0e+1],"b\" c":"x  y\n","d":null}"#);
+    assert_eq!(crate::minify("  42 ").unwrap(), "42");
+}
+
+#[test]
+fn minify_rejects_invalid_json() {
+    let err = crate::minify("{\"a\": 1, b: 2}").unwrap_err();
+    assert!(matches!(err.kind, RepairErrorKind::InvalidJson(_)));
+    assert_eq!(err.position, 9);
+    assert!(crate::minify("").is_err());
+}
+
+fn unwrap_opts() -> Options {
+    Options {
+        unwrap_escaped_json: true,
+        ..Default::default()
+    }
+}
+
+#[test]
+fn unwrap_escaped_json_single_layer() {
+    let out = crate::repair_to_string(r#""{\"a\": 1, \"b\": [true]}""#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":[true]}"#);
+    // Written out compactly, the same as the single-quoted form of the document.
+    let out = crate::repair_to_string(r#""{\"a\": 1, \"b\": [1, 2]}""#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":[1,2]}"#);
+    let out = crate::repair_to_string(r#"'{"a": 1, "b": [1, 2]}'"#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"a":1,"b":[1,2]}"#);
+    // Off by default: the string is kept.
+    let out = crate::repair_to_string(r#""{\"a\": 1}""#, &Options::default()).unwrap();
+    assert_eq!(out, r#""{\"a\": 1}""#);
+}
+
+#[test]
+fn unwrap_escaped_json_nested_layers_and_unicode() {
+    let s = r#""\"[1, \\\"\\u00e9\\ud83d\\ude00\\\"]\"""#;
+    let out = crate::repair_to_string(s, &unwrap_opts()).unwrap();
+    assert_eq!(out, "[1,\"é😀\"]");
+}
+
+#[test]
+fn unwrap_escaped_json_keeps_non_json_strings() {
+    for s in [
+        r#""hello""#,
+        r#""[citation needed]""#,
+        r#""{a: 1}""#,
+        r#""42""#,
+    ] {
+        let out = crate::repair_to_string(s, &unwrap_opts()).unwrap();
+        assert_eq!(out, s);
+    }
+    // Only top-level strings are unwrapped.
+    let out = crate::repair_to_string(r#"{"x": "{\"a\": 1}"}"#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"x": "{\"a\": 1}"}"#);
+}
+
+#[test]
+fn unwrap_escaped_json_depth_cap() {
+    // Wrap a value in more layers than the cap; the outermost cap layers are removed.
+    let mut s = String::from("[1]");
+    for _ in 0..crate::repair::UNWRAP_ESCAPED_MAX_DEPTH + 2 {
+        s = serde_json::to_string(&s).unwrap();
+    }
+    let out = crate::repair_to_string(&s, &unwrap_opts()).unwrap();
+    let mut expected = String::from("[1]");
+    for _ in 0..2 {
+        expected = serde_json::to_string(&expected).unwrap();
+    }
+    assert_eq!(out, expected);
+}
+
+#[test]
+fn unwrap_escaped_json_over_escaped_values() {
+    for engine in [
+        crate::options::EngineKind::Recursive,
+        crate::options::EngineKind::LlmCompat,
+    ] {
+        let o = Options {
+            engine,
+            ..unwrap_opts()
+        };
+        // Two layers with too few backslashes: not even a valid string.
+        let out = crate::repair_to_string(r#"{"payload": "{\\"k\\": 1}"}"#, &o).unwrap();
+        let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+        assert_eq!(v, serde_json::json!({"payload": {"k": 1}}), "{engine:?}");
+        // Two layers, properly double-encoded, inside an array.
+        let out = crate::repair_to_string(r#"[1, "[\\\"a\\\", 2]"]"#, &o).unwrap();
+        let v: serde_json::Value = serde_json::from_str(&out).unwrap();
+        assert_eq!(v, serde_json::json!([1, ["a", 2]]), "{engine:?}");
+    }
+    // The decoded value is written out compactly, as any repair is.
+    let out = crate::repair_to_string(r#"{"p": "{\\"k\\": [1, 2]}"}"#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"p":{"k":[1,2]}}"#);
+    // One layer is ordinary stringified JSON and stays a string.
+    let out = crate::repair_to_string(r#"{"x": "{\"a\": 1}", y: 2}"#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"x":"{\"a\": 1}","y":2}"#);
+    // Off by default.
+    let plain = crate::repair_to_string(r#"{"payload": "{\\"k\\": 1}"}"#, &Options::default());
+    assert_ne!(plain.unwrap(), r#"{"payload": {"k": 1}}"#);
+}
+
+#[test]
+fn unwrap_escaped_json_value_depth_cap() {
+    // Escape the body of a string value `layers` times.
+    let value = |layers: usize| {
+        let mut body = String::from("{\"k\":1}");
+        for _ in 0..layers {
+            let quoted = serde_json::to_string(&body).unwrap();
+            body = quoted[1..quoted.len() - 1].to_string();
+        }
+        format!("{{\"p\": \"{body}\", q: 1}}")
+    };
+    let max = crate::repair::UNWRAP_ESCAPED_MAX_DEPTH;
+    let out = crate::repair_to_string(&value(max), &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"p":{"k":1},"q":1}"#);
+    // One layer more than the cap is left to normal string repair.
+    let out = crate::repair_to_string(&value(max + 1), &unwrap_opts()).unwrap();
+    let got: serde_json::Value = serde_json::from_str(&out).unwrap();
+    assert!(got["p"].is_string(), "{out}");
+    // Runs of backslashes that never decode to JSON terminate too.
+    let s = format!("{{\"p\": \"{{{}\"}}", "\\".repeat(64));
+    assert!(crate::repair_to_string(&s, &unwrap_opts()).is_ok());
+}
+
+#[test]
+fn unwrap_single_quoted_document() {
+    let out = crate::repair_to_string("'[1, 2, 3]'", &unwrap_opts()).unwrap();
+    assert_eq!(out, "[1,2,3]");
+    let out = crate::repair_to_string(" '{\"a\": [1]}'\n", &unwrap_opts()).unwrap();
+    assert_eq!(out, r#"{"a":[1]}"#);
+}
+
+#[test]
+fn unwrap_distinguishes_plain_string_from_wrapped_array() {
+    let out = crate::repair_to_string(r#""hello""#, &unwrap_opts()).unwrap();
+    assert_eq!(out, r#""hello""#);
+    let out = crate::repair_to_string("'hello'", &unwrap_opts()).unwrap();
+    assert_eq!(out, r#""hello""#);
+    let out = crate::repair_to_string(r#""[1,2]""#, &unwrap_opts()).unwrap();
+    assert_eq!(out, "[1,2]");
+    let out = crate::repair_to_string("'[1,2]'", &unwrap_opts()).unwrap();
+    assert_eq!(out, "[1,2]");
+}
+
+#[test]
+fn unwrap_single_quoted_requires_complete_value() {
+    // Truncated content is not unwrapped; it goes through normal string repair.
+    let plain = crate::repair_to_string("'[1, 2'", &Options::default()).unwrap();
+    let out = crate::repair_to_string("'[1, 2'", &unwrap_opts()).unwrap();
+    assert_eq!(out, plain);
+}
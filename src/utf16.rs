@@ -0,0 +1,48 @@
+//! UTF-16 input transcoding. Repair itself always works on (and emits) UTF-8.
+
+use crate::error::{RepairError, RepairErrorKind};
+
+/// Byte order of UTF-16 input that carries no BOM.
+#[derive(Clone, Debug, Copy, PartialEq, Eq)]
+pub enum Utf16Endian {
+    Little,
+    Big,
+}
+
+/// Transcode UTF-16 `bytes` to UTF-8. A leading BOM (`FF FE` or `FE FF`) selects the byte
+/// order and is dropped; otherwise `endian` is used. Errors carry the byte offset of the
+/// odd trailing byte or of the unpaired surrogate.
+pub(crate) fn decode(bytes: &[u8], endian: Utf16Endian) -> Result<String, RepairError> {
+    let (endian, body, offset) = match bytes {
+        [0xFF, 0xFE, rest @ ..] => (Utf16Endian::Little, rest, 2),
+        [0xFE, 0xFF, rest @ ..] => (Utf16Endian::Big, rest, 2),
+        _ => (endian, bytes, 0),
+    };
+    if !body.len().is_multiple_of(2) {
+        return Err(RepairError::new(
+            RepairErrorKind::Parse("UTF-16 input has an odd number of bytes".to_string()),
+            bytes.len() - 1,
+        ));
+    }
+    let units = body.chunks_exact(2).map(|p| match endian {
+        Utf16Endian::Little => u16::from_le_bytes([p[0], p[1]]),
+        Utf16Endian::Big => u16::from_be_bytes([p[0], p[1]]),
+    });
+    let mut out = String::with_capacity(body.len() / 2);
+    let mut pos = offset;
+    for ch in char::decode_utf16(units) {
+        match ch {
+            Ok(c) => {
+                out.push(c);
+                pos += c.len_utf16() * 2;
+            }
+            Err(_) => {
+                return Err(RepairError::new(
+                    RepairErrorKind::Parse("invalid UTF-16: unpaired surrogate".to_string()),
+                    pos,
+                ));
+            }
+        }
+    }
+    Ok(out)
+}
@@ -0,0 +1,16 @@
+//! UTF-8 byte input, for `Options::utf8_strictness`. Repair itself works on `&str`.
+
+use crate::options::Utf8Strictness;
+use std::borrow::Cow;
+
+/// Read `bytes` as UTF-8. Under `Strict` any ill-formed sequence, overlong encodings
+/// included, is an error; under `Lenient` each one is replaced with U+FFFD.
+pub(crate) fn decode(
+    bytes: &[u8],
+    strictness: Utf8Strictness,
+) -> Result<Cow<'_, str>, std::str::Utf8Error> {
+    match strictness {
+        Utf8Strictness::Strict => std::str::from_utf8(bytes).map(Cow::Borrowed),
+        Utf8Strictness::Lenient => Ok(String::from_utf8_lossy(bytes)),
+    }
+}
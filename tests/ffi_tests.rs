@@ -5,7 +5,7 @@
 #![cfg(feature = "c-api")]
 
 use std::ffi::{CStr, CString};
-use std::os::raw::c_char;
+use std::os::raw::{c_char, c_int, c_void};
 use std::ptr;
 
 // Import the FFI functions
@@ -291,3 +291,2136 @@ fn test_new_options() {
         jsonrepair_options_free(opts);
     }
 }
+
+#[test]
+fn test_reject_if_invalid() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_reject_if_invalid(opts, true);
+
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let input = CString::new("{\"a\": 1, b: 2}").unwrap();
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::InvalidJson);
+        assert_eq!(error.position, 9);
+        if !error.message.is_null() {
+            let _ = CString::from_raw(error.message);
+        }
+
+        let valid = CString::new("{\"a\":1}").unwrap();
+        let result = jsonrepair_repair_with_options(valid.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"a\":1}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_timeout_ms() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_timeout_ms(opts, 1);
+
+        let mut body = String::from("[");
+        for i in 0..300_000 {
+            body.push_str(&format!("{{k{}: 'v'}},", i));
+        }
+        let input = CString::new(body).unwrap();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Timeout);
+        if !error.message.is_null() {
+            let _ = CString::from_raw(error.message);
+        }
+
+        jsonrepair_options_set_timeout_ms(opts, 0);
+        let small = CString::new("{a:1}").unwrap();
+        let result = jsonrepair_repair_with_options(small.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_output_bom() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_output_bom(opts, true);
+
+        let input = CString::new("\u{FEFF}{a:1}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "\u{FEFF}{\"a\":1}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_trim_keys() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_trim_keys(opts, true);
+
+        let input = CString::new("{\"name \": 1, ' id':2}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"name\":1,\"id\":2}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_unwrap_escaped_json() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_unwrap_escaped_json(opts, true);
+
+        let input = CString::new(r#""{\"a\": 1}""#).unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_max_repairs() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_repairs(opts, 1);
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let input = CString::new("{a: 1, b: 2}").unwrap();
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::TooManyRepairs);
+        if !error.message.is_null() {
+            let _ = CString::from_raw(error.message);
+        }
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_error_as_json() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_repairs(opts, 1);
+        jsonrepair_options_set_error_as_json(opts, true);
+        let input = CString::new("{a: 1, b: 2}").unwrap();
+
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert!(!result.is_null());
+        let v: serde_json::Value = serde_json::from_str(&c_str_to_string(result)).unwrap();
+        assert!(
+            v["error"]
+                .as_str()
+                .unwrap()
+                .starts_with("More than 1 repairs needed")
+        );
+        assert!(v["offset"].is_u64());
+        jsonrepair_free(result);
+
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::TooManyRepairs);
+        let v: serde_json::Value = serde_json::from_str(&c_str_to_string(result)).unwrap();
+        assert_eq!(v["offset"], error.position as u64);
+        jsonrepair_free(result);
+        jsonrepair_free(error.message);
+
+        // Repairable input is unaffected.
+        let input = CString::new("{a: 1}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_tolerate_sql_comments() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_tolerate_sql_comments(opts, true);
+        let input = CString::new("{a: 1, -- note\nb: --2}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1,"b":"--2"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_overflow() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("[1e400, -1e999, 1.5]").unwrap();
+        for (mode, want) in [
+            (
+                JsonRepairOverflow::OverflowKeep as u32,
+                "[1e400,-1e999,1.5]",
+            ),
+            (
+                JsonRepairOverflow::OverflowQuote as u32,
+                r#"["1e400","-1e999",1.5]"#,
+            ),
+            (JsonRepairOverflow::OverflowNull as u32, "[null,null,1.5]"),
+        ] {
+            jsonrepair_options_set_overflow(opts, mode);
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want, "{mode:?}");
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_number_suffix() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{timeout: 30s, load: 75%, n: 30}").unwrap();
+        jsonrepair_options_set_quote_suffixed_numbers(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"timeout":"30s","load":"75%","n":30}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_set_number_suffix(opts, JsonRepairNumberSuffix::SuffixStrip as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"timeout":30,"load":75,"n":30}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_unwrap_functions() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayQuote as u32);
+        let name = CString::new("UUID").unwrap();
+        jsonrepair_options_add_unwrap_function(
+            opts,
+            name.as_ptr(),
+            JsonRepairUnwrapMode::UnwrapString as u32,
+        );
+        jsonrepair_options_add_unwrap_function(
+            opts,
+            ptr::null(),
+            JsonRepairUnwrapMode::UnwrapNull as u32,
+        );
+        let input = CString::new(r#"[UUID("ab-cd"), NumberLong("42")]"#).unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"["ab-cd",42]"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_clear_unwrap_functions(opts);
+        jsonrepair_options_add_unwrap_function(
+            opts,
+            name.as_ptr(),
+            JsonRepairUnwrapMode::UnwrapNull as u32,
+        );
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"[null,"NumberLong","(","42",")"]"#
+        );
+        jsonrepair_free(result);
+
+        // An unknown mode registers nothing.
+        let long = CString::new("NumberLong").unwrap();
+        jsonrepair_options_add_unwrap_function(opts, long.as_ptr(), 5);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"[null,"NumberLong","(","42",")"]"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_max_elements() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_elements(opts, 2);
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let input = CString::new("[1, 2, 3]").unwrap();
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::TooManyElements);
+        assert_eq!(error.position, 7);
+        if !error.message.is_null() {
+            let _ = CString::from_raw(error.message);
+        }
+
+        jsonrepair_options_set_max_elements(opts, 0);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1, 2, 3]");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_wrap_fragments() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_wrap_fragments(opts, true);
+
+        let input = CString::new("a = 1\nb = 'x'\n").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1,"b":"x"}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_hashed() {
+    unsafe {
+        let mut hash = [0u8; 32];
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let input = CString::new("{a: 1}").unwrap();
+        let result =
+            jsonrepair_repair_hashed(input.as_ptr(), ptr::null(), hash.as_mut_ptr(), &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), "{\"a\":1}");
+        jsonrepair_free(result);
+
+        let input = CString::new("{'a': 1,}").unwrap();
+        let mut again = [0u8; 32];
+        let result =
+            jsonrepair_repair_hashed(input.as_ptr(), ptr::null(), again.as_mut_ptr(), &mut error);
+        jsonrepair_free(result);
+        assert_eq!(hash, again);
+        assert_ne!(hash, [0u8; 32]);
+    }
+}
+
+#[test]
+fn test_repair_with_kind() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let mut kind = JsonRepairValueKind::ValueKindEmpty;
+        for (input, want) in [
+            ("{a: 1", JsonRepairValueKind::ValueKindObject),
+            ("[1", JsonRepairValueKind::ValueKindArray),
+            ("'x'", JsonRepairValueKind::ValueKindString),
+            ("1.5", JsonRepairValueKind::ValueKindNumber),
+            ("True", JsonRepairValueKind::ValueKindBoolean),
+            ("null", JsonRepairValueKind::ValueKindNull),
+            ("", JsonRepairValueKind::ValueKindEmpty),
+        ] {
+            let input = CString::new(input).unwrap();
+            let result =
+                jsonrepair_repair_with_kind(input.as_ptr(), ptr::null(), &mut kind, &mut error);
+            assert_eq!(error.code, JsonRepairErrorCode::Ok);
+            assert_eq!(kind, want);
+            jsonrepair_free(result);
+        }
+
+        let result =
+            jsonrepair_repair_with_kind(ptr::null(), ptr::null(), ptr::null_mut(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_missing_values() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{\"a\":, \"b\"}").unwrap();
+
+        jsonrepair_options_set_missing_values(opts, JsonRepairMissingValues::InsertNull as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"a\":null,\"b\":null}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_missing_values(opts, JsonRepairMissingValues::DropKey as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{}");
+        jsonrepair_free(result);
+
+        // An unknown value leaves the option as it was.
+        jsonrepair_options_set_missing_values(opts, 7);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_utf16() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let be: Vec<u8> = "{a: 1}".encode_utf16().flat_map(u16::to_be_bytes).collect();
+        let result = jsonrepair_repair_utf16(
+            be.as_ptr(),
+            be.len(),
+            JsonRepairUtf16Endian::Utf16Be as u32,
+            ptr::null(),
+            &mut error,
+        );
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), "{\"a\":1}");
+        jsonrepair_free(result);
+
+        let odd = [0xFFu8, 0xFE, b'{'];
+        let result = jsonrepair_repair_utf16(
+            odd.as_ptr(),
+            odd.len(),
+            JsonRepairUtf16Endian::Utf16Le as u32,
+            ptr::null(),
+            &mut error,
+        );
+        assert!(result.is_null());
+        assert_ne!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(error.position, 2);
+        drop(CString::from_raw(error.message));
+
+        // An unknown byte order is an error rather than a guess.
+        let result = jsonrepair_repair_utf16(be.as_ptr(), be.len(), 2, ptr::null(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        drop(CString::from_raw(error.message));
+    }
+}
+
+#[test]
+fn test_ascii_scope() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{ключ: 'é'}").unwrap();
+
+        jsonrepair_options_set_ascii_scope(opts, JsonRepairAsciiScope::AsciiKeysOnly as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            "{\"\\u043A\\u043B\\u044E\\u0447\":\"é\"}"
+        );
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_ascii_scope(opts, JsonRepairAsciiScope::AsciiValuesOnly as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"ключ\":\"\\u00E9\"}");
+        jsonrepair_free(result);
+
+        // An unknown value leaves the option as it was.
+        jsonrepair_options_set_ascii_scope(opts, 9);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"ключ\":\"\\u00E9\"}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_normalize_numbers() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_normalize_numbers(opts, true);
+        let input = CString::new("[1.50E+03, -0.0]").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1.5e3,0]");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_extract() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let input = CString::new("{status: ok, result: {a: [1, 2,]}").unwrap();
+
+        let pointer = CString::new("/result/a").unwrap();
+        let result =
+            jsonrepair_repair_extract(input.as_ptr(), pointer.as_ptr(), ptr::null(), &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), "[1,2]");
+        jsonrepair_free(result);
+
+        let pointer = CString::new("/missing").unwrap();
+        let result =
+            jsonrepair_repair_extract(input.as_ptr(), pointer.as_ptr(), ptr::null(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::PointerNotFound);
+        drop(CString::from_raw(error.message));
+    }
+}
+
+#[test]
+fn test_equals_separators() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_equals_separators(opts, true);
+        let input = CString::new("{a => 1, b: 2, c = 3}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"a\":1,\"b\":2,\"c\":3}");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_stream_recover() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_repairs(opts, 2);
+        let stream = jsonrepair_stream_new(opts);
+        jsonrepair_options_free(opts);
+        jsonrepair_stream_set_recover(stream, true);
+
+        let chunk = CString::new("{a b c d e}\nnoise {\"b\": 2}\n").unwrap();
+        let result = jsonrepair_stream_push(stream, chunk.as_ptr());
+        assert_eq!(c_str_to_string(result), "{\"b\": 2}");
+        jsonrepair_free(result);
+
+        let list = jsonrepair_stream_take_skipped(stream);
+        assert_eq!((*list).len, 1);
+        let item = &*(*list).items;
+        assert!(!item.valid);
+        assert_eq!(
+            CStr::from_ptr(item.text).to_str().unwrap(),
+            "{a b c d e}\nnoise"
+        );
+        assert_eq!(item.error.code, JsonRepairErrorCode::TooManyRepairs);
+        jsonrepair_value_status_list_free(list);
+
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_stream_value_ranges() {
+    unsafe {
+        let stream = jsonrepair_stream_new(ptr::null());
+        jsonrepair_stream_set_track_ranges(stream, true);
+
+        for part in ["{a: 1}\n  {b:", " 2}\n"] {
+            let chunk = CString::new(part).unwrap();
+            jsonrepair_free(jsonrepair_stream_push(stream, chunk.as_ptr()));
+        }
+        let list = jsonrepair_stream_take_ranges(stream);
+        let items = std::slice::from_raw_parts((*list).items, (*list).len);
+        let got: Vec<(String, usize, usize)> = items
+            .iter()
+            .map(|r| (c_str_to_string(r.value), r.start, r.end))
+            .collect();
+        assert_eq!(
+            got,
+            [
+                ("{\"a\":1}".to_string(), 0, 6),
+                ("{\"b\":2}".to_string(), 9, 15),
+            ]
+        );
+        jsonrepair_value_range_list_free(list);
+
+        let list = jsonrepair_stream_take_ranges(stream);
+        assert_eq!((*list).len, 0);
+        jsonrepair_value_range_list_free(list);
+        assert!(jsonrepair_stream_take_ranges(ptr::null_mut()).is_null());
+
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_dedup_position() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{a: 1, b: 2, a: 3}").unwrap();
+        for (mode, want) in [
+            (
+                JsonRepairDedupPosition::DedupKeepAll as u32,
+                "{\"a\":1,\"b\":2,\"a\":3}",
+            ),
+            (
+                JsonRepairDedupPosition::DedupFirstPosition as u32,
+                "{\"a\":3,\"b\":2}",
+            ),
+            (
+                JsonRepairDedupPosition::DedupLastPosition as u32,
+                "{\"b\":2,\"a\":3}",
+            ),
+        ] {
+            jsonrepair_options_set_dedup_position(opts, mode);
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want, "{mode:?}");
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_dedup_arrays() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_dedup_arrays(opts, true);
+        let input = CString::new("[1, 1, 2, 'a', 'a']").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[1,2,"a"]"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_escape_slashes() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_escape_slashes(opts, true);
+        let input = CString::new("{html: '</script>'}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"html":"<\/script>"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_add_missing_brackets() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_add_missing_brackets(opts, true);
+        for (input, want) in [("a: 1, b: 2,", r#"{"a":1,"b":2}"#), ("1, 2, 3,", "[1,2,3]")] {
+            let input = CString::new(input).unwrap();
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_compact_spacing() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_compact_spacing(
+            opts,
+            JsonRepairCompactSpacing::SpacingMinimal as u32,
+        );
+        let input = CString::new("{a: [1,2], b: {c: 'x,y'},}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"a": [1, 2], "b": {"c": "x,y"}}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_set_compact_spacing(opts, JsonRepairCompactSpacing::SpacingNone as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":[1,2],"b":{"c":"x,y"}}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_indent_detect() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_indent_detect(opts, true);
+        let input = CString::new("{\n\ta: 1,\n\tb: [2]\n}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            "{\n\t\"a\": 1,\n\t\"b\": [\n\t\t2\n\t]\n}"
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_align_values() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_indent_detect(opts, true);
+        jsonrepair_options_set_align_values(opts, true);
+        let input = CString::new("{\n  a: 1,\n  long: 2\n}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            "{\n  \"a\":    1,\n  \"long\": 2\n}"
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_crlf() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_indent_detect(opts, true);
+        jsonrepair_options_set_crlf(opts, true);
+        let input = CString::new("{\n\ta: \"x\\ny\"\n}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\r\n\t\"a\": \"x\\ny\"\r\n}");
+        jsonrepair_free(result);
+        // Without indent_detect the output is compact and has no line breaks.
+        jsonrepair_options_set_indent_detect(opts, false);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"x\ny"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_concat_adjacent_strings() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_concat_adjacent_strings(opts, true);
+        let input = CString::new("{\"msg\": \"a \"\n\"b \"\n\"c\"}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"msg":"a b c"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+unsafe extern "C" fn count_progress(done: usize, total: usize, userdata: *mut c_void) {
+    let seen = unsafe { &mut *(userdata as *mut Vec<(usize, usize)>) };
+    seen.push((done, total));
+}
+
+#[test]
+fn test_progress_callback() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let mut seen: Vec<(usize, usize)> = Vec::new();
+        jsonrepair_options_set_progress_callback(
+            opts,
+            Some(count_progress),
+            &mut seen as *mut Vec<(usize, usize)> as *mut c_void,
+        );
+        let body = format!("[{}]", "{k: 'v'},".repeat(10_000));
+        let input = CString::new(body.as_str()).unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert!(!result.is_null());
+        jsonrepair_free(result);
+        assert!(seen.len() > 1 && seen.len() <= 101);
+        assert_eq!(seen.last(), Some(&(body.len(), body.len())));
+
+        // NULL removes the callback.
+        seen.clear();
+        jsonrepair_options_set_progress_callback(opts, None, ptr::null_mut());
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        jsonrepair_free(result);
+        assert!(seen.is_empty());
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_decode_base64() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_decode_base64(opts, true);
+        // {name: 'John', age: 30,}
+        let input = CString::new("e25hbWU6ICdKb2huJywgYWdlOiAzMCx9").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"name":"John","age":30}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_annotate_source() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_annotate_source(opts, true);
+        jsonrepair_options_set_output_format(opts, JsonRepairOutputFormat::FormatJson5 as u32);
+        let input = CString::new("{a: [1, 2]}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            "{a:[1/* @src:5 */,2/* @src:8 */]/* @src:4 */}/* @src:0 */"
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_stray_tokens() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("[1, garbage, 2]").unwrap();
+
+        // STRAY_ERROR by default.
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        assert_eq!(error.position, 4);
+        drop(CString::from_raw(error.message));
+
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayQuote as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[1,"garbage",2]"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayDrop as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1,2]");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_force_container() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("42").unwrap();
+        for (mode, want) in [
+            (JsonRepairForceContainer::ForceOff as u32, "42"),
+            (JsonRepairForceContainer::ForceArray as u32, "[42]"),
+            (
+                JsonRepairForceContainer::ForceObject as u32,
+                "{\"value\":42}",
+            ),
+        ] {
+            jsonrepair_options_set_force_container(opts, mode);
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want, "{mode:?}");
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_fix_mojibake() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_fix_mojibake(opts, true);
+        let input = CString::new("{â€œkeyâ€: â€œa â€” bâ€}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"key":"a — b"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_stream_max_buffer() {
+    unsafe {
+        let stream = jsonrepair_stream_new(ptr::null());
+        jsonrepair_stream_set_max_buffer(stream, 4);
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let chunk = CString::new("{\"a\": [1, 2, 3").unwrap();
+        let result = jsonrepair_stream_push_ex(stream, chunk.as_ptr(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::BufferOverflow);
+        assert_eq!(error.position, 14);
+        drop(CString::from_raw(error.message));
+
+        let chunk = CString::new("[1]\n").unwrap();
+        let result = jsonrepair_stream_push_ex(stream, chunk.as_ptr(), &mut error);
+        assert_eq!(CStr::from_ptr(result).to_str().unwrap(), "[1]");
+        jsonrepair_free(result);
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_salvage() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayError as u32);
+        let input = CString::new("[1, garbage, 2]").unwrap();
+
+        jsonrepair_options_set_salvage(opts, JsonRepairSalvage::SalvageNull as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1,null,2]");
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_salvage(opts, JsonRepairSalvage::SalvageMarker as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"[1,{"$unrepairable":"garbage"},2]"#
+        );
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_salvage(opts, JsonRepairSalvage::SalvageFail as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert!(result.is_null());
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_output_format_json5() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_output_format(opts, JsonRepairOutputFormat::FormatJson5 as u32);
+        let input = CString::new("{\"name\": \"it's\", list: [1, 'a',]}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r"{name:'it\'s',list:[1,'a']}");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_comma_decimal() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_comma_decimal(opts, true);
+        for (input, want) in [
+            ("{\"price\": 3,14}", "{\"price\":3.14}"),
+            ("[3,14]", "[3,14]"),
+        ] {
+            let input = CString::new(input).unwrap();
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_list_repair_categories() {
+    unsafe {
+        let list = jsonrepair_list_repair_categories();
+        assert!(!list.is_null());
+        let items = std::slice::from_raw_parts((*list).items, (*list).len);
+        let names: Vec<String> = items.iter().map(|&s| c_str_to_string(s)).collect();
+        assert_eq!(names, jsonrepair::repair_categories());
+        assert!(names.iter().any(|n| n == "inserted missing comma"));
+        jsonrepair_string_list_free(list);
+    }
+}
+
+#[test]
+fn test_repair_split() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let input = CString::new("{a:1}{b:2}\n[1,2,]").unwrap();
+        let list = jsonrepair_repair_split(input.as_ptr(), ptr::null(), &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert!(!list.is_null());
+        let items = std::slice::from_raw_parts((*list).items, (*list).len);
+        let values: Vec<String> = items.iter().map(|&s| c_str_to_string(s)).collect();
+        assert_eq!(values, [r#"{"a":1}"#, r#"{"b":2}"#, "[1,2]"]);
+        jsonrepair_string_list_free(list);
+
+        let list = jsonrepair_repair_split(ptr::null(), ptr::null(), &mut error);
+        assert!(list.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        drop(CString::from_raw(error.message));
+        jsonrepair_string_list_free(ptr::null_mut());
+    }
+}
+
+#[test]
+#[cfg(target_pointer_width = "64")]
+fn test_offsets_and_lengths_are_64_bit() {
+    let err = JsonRepairError {
+        code: JsonRepairErrorCode::Ok,
+        message: ptr::null_mut(),
+        position: u32::MAX as usize + 1,
+    };
+    assert_eq!(std::mem::size_of_val(&err.position), 8);
+    assert_eq!(err.position, 1 << 32);
+    let list = JsonRepairStringList {
+        items: ptr::null_mut(),
+        len: 0,
+    };
+    assert_eq!(std::mem::size_of_val(&list.len), 8);
+    let statuses = JsonRepairValueStatusList {
+        items: ptr::null_mut(),
+        len: 0,
+    };
+    assert_eq!(std::mem::size_of_val(&statuses.len), 8);
+}
+
+#[test]
+fn test_repair_first() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let mut consumed = usize::MAX;
+        let input = CString::new("{a: 1} junk").unwrap();
+        let result =
+            jsonrepair_repair_first(input.as_ptr(), ptr::null(), &mut consumed, &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        assert_eq!(consumed, 6);
+        jsonrepair_free(result);
+
+        let input = CString::new("   ").unwrap();
+        let result =
+            jsonrepair_repair_first(input.as_ptr(), ptr::null(), &mut consumed, &mut error);
+        assert!(result.is_null());
+        assert_eq!(consumed, 0);
+        assert_eq!(error.code, JsonRepairErrorCode::UnexpectedEnd);
+        drop(CString::from_raw(error.message));
+    }
+}
+
+#[test]
+fn test_stream_push_validate() {
+    unsafe {
+        let stream = jsonrepair_stream_new(ptr::null());
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let chunk = CString::new("{\"a\":1}\n{b:2}\n").unwrap();
+        let list = jsonrepair_stream_push_validate(stream, chunk.as_ptr(), &mut error);
+        assert!(!list.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        let items = std::slice::from_raw_parts((*list).items, (*list).len);
+        assert_eq!(items.len(), 2);
+        assert!(items[0].valid);
+        assert_eq!(c_str_to_string(items[0].text), "{\"a\":1}");
+        assert!(!items[1].valid);
+        assert_eq!(items[1].error.code, JsonRepairErrorCode::InvalidJson);
+        assert_eq!(items[1].error.position, 1);
+        jsonrepair_value_status_list_free(list);
+
+        let list = jsonrepair_stream_flush_validate(stream, &mut error);
+        assert!(!list.is_null());
+        assert_eq!((*list).len, 0);
+        jsonrepair_value_status_list_free(list);
+
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_minify() {
+    unsafe {
+        let input = CString::new("{ \"a\": [1, 2] }").unwrap();
+        let result = jsonrepair_minify(input.as_ptr());
+        assert_eq!(c_str_to_string(result), r#"{"a":[1,2]}"#);
+        jsonrepair_free(result);
+
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let bad = CString::new("{a: 1}").unwrap();
+        assert!(jsonrepair_minify(bad.as_ptr()).is_null());
+        let result = jsonrepair_minify_ex(bad.as_ptr(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::InvalidJson);
+        assert_eq!(error.position, 1);
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_option_supported() {
+    assert!(jsonrepair_option_supported(
+        JsonRepairOption::OptionEnsureAscii as u32
+    ));
+    assert!(jsonrepair_option_supported(
+        JsonRepairOption::OptionErrorAsJson as u32
+    ));
+    assert_eq!(
+        jsonrepair_option_supported(JsonRepairOption::OptionLogging as u32),
+        cfg!(feature = "logging")
+    );
+    assert!(jsonrepair_option_supported(
+        JsonRepairOption::OptionBracketsAsObjects as u32
+    ));
+    assert!(!jsonrepair_option_supported(
+        JsonRepairOption::OptionBracketsAsObjects as u32 + 1
+    ));
+    assert!(!jsonrepair_option_supported(u32::MAX));
+}
+
+#[test]
+fn test_stream_stats() {
+    unsafe {
+        let stats = jsonrepair_stream_stats(ptr::null());
+        assert_eq!((stats.values, stats.bytes_in), (0, 0));
+
+        let stream = jsonrepair_stream_new(ptr::null());
+        for part in ["{a: 1}\n[1, 2,", "]\n{\"b\": 2}\n"] {
+            let chunk = CString::new(part).unwrap();
+            jsonrepair_free(jsonrepair_stream_push(stream, chunk.as_ptr()));
+        }
+        let stats = jsonrepair_stream_stats(stream);
+        assert_eq!(stats.values, 3);
+        assert_eq!(stats.repairs, 1);
+        assert_eq!(stats.bytes_in, 24);
+        assert_eq!(stats.bytes_out, 20);
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_strip_ellipsis() {
+    unsafe {
+        let input = CString::new(r#"{"items": [1, 2, ...], "more": "...", ...}"#).unwrap();
+        let opts = jsonrepair_options_new();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"items":[1,2],"more":"..."}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_strip_ellipsis(opts, false);
+        let input = CString::new("[1, 2, ...]").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[1,2,"..."]"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_negative_zero() {
+    unsafe {
+        let input = CString::new("[-0, -0.0, -0e5, -0.5]").unwrap();
+        let opts = jsonrepair_options_new();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[-0, -0.0, -0e5, -0.5]");
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_negative_zero(
+            opts,
+            JsonRepairNegativeZero::NegativeZeroNormalize as u32,
+        );
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[0,0.0,0e5,-0.5]");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_short_escapes() {
+    unsafe {
+        let input = CString::new("{\"a\": \"x\ty\"}").unwrap();
+        let opts = jsonrepair_options_new();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"x\ty"}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_short_escapes(opts, false);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"x\u0009y"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_both() {
+    unsafe {
+        let input = CString::new("{a: [1, 2], b: {}}").unwrap();
+        let mut pretty: *mut c_char = ptr::null_mut();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let compact =
+            jsonrepair_repair_both(input.as_ptr(), 2, ptr::null(), &mut pretty, &mut error);
+        assert_eq!(c_str_to_string(compact), r#"{"a":[1,2],"b":{}}"#);
+        assert_eq!(
+            c_str_to_string(pretty),
+            "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"
+        );
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        jsonrepair_free(compact);
+        jsonrepair_free(pretty);
+
+        let compact = jsonrepair_repair_both(ptr::null(), 2, ptr::null(), &mut pretty, &mut error);
+        assert!(compact.is_null());
+        assert!(pretty.is_null());
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_strip_trailing_line_ws() {
+    unsafe {
+        let input = CString::new("{\"code\":\"a = 1;  \\n  b\"}").unwrap();
+        let opts = jsonrepair_options_new();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"code":"a = 1;  \n  b"}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_strip_trailing_line_ws(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"code":"a = 1;\n  b"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_collapse_ws() {
+    unsafe {
+        let input = CString::new("{\"text\":\"a  \\t b\\n\\n  c\"}").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_collapse_ws(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"text":"a b\n\n c"}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_collapse_ws_newlines(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"text":"a b c"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_parens_as_arrays() {
+    unsafe {
+        let input = CString::new("(1, 2, 3)").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_parens_as_arrays(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1,2,3]");
+        jsonrepair_free(result);
+
+        let input = CString::new("{a: ObjectId('x'), b: (1,)}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"x","b":[1]}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_safe_integers() {
+    unsafe {
+        let input = CString::new("{id: 9007199254740993, n: 9007199254740991}").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_safe_integers(
+            opts,
+            JsonRepairSafeIntegers::SafeIntegersQuote as u32,
+        );
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"id":"9007199254740993","n":9007199254740991}"#
+        );
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_safe_integers(
+            opts,
+            JsonRepairSafeIntegers::SafeIntegersClamp as u32,
+        );
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"id":9007199254740991,"n":9007199254740991}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_extract_embedded() {
+    unsafe {
+        let input = CString::new("Here is the result: {a: [1, 2,]}. Thanks! [3]").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_extract_embedded(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":[1,2]}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_invalid_utf8_error_position() {
+    unsafe {
+        let input = CString::new(b"{\"a\": \"x\xff\"}".to_vec()).unwrap();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), ptr::null(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::InvalidUtf8);
+        assert_eq!(error.position, 8);
+        assert_eq!(
+            c_str_to_string(error.message),
+            "Invalid UTF-8 at position 8"
+        );
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_minimal_escapes() {
+    unsafe {
+        let input = CString::new(r#"{"a":"\/x\u0041\u00E9\u0022\u0009"}"#).unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_minimal_escapes(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"/xAé\"\t"}"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_ensure_ascii(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"/xA\u00E9\"\t"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+unsafe extern "C" fn collect_chunks(
+    data: *const c_char,
+    len: usize,
+    userdata: *mut c_void,
+) -> c_int {
+    let chunks = unsafe { &mut *(userdata as *mut Vec<Vec<u8>>) };
+    chunks.push(unsafe { std::slice::from_raw_parts(data as *const u8, len) }.to_vec());
+    0
+}
+
+unsafe extern "C" fn refuse_chunks(_: *const c_char, _: usize, _: *mut c_void) -> c_int {
+    1
+}
+
+#[test]
+fn test_repair_streaming_output() {
+    unsafe {
+        let body = format!("[{}]", "{k: 'v'},".repeat(20_000));
+        let input = CString::new(body.as_str()).unwrap();
+        let mut chunks: Vec<Vec<u8>> = Vec::new();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        assert!(jsonrepair_repair_streaming_output(
+            input.as_ptr(),
+            ptr::null(),
+            Some(collect_chunks),
+            &mut chunks as *mut Vec<Vec<u8>> as *mut c_void,
+            &mut error,
+        ));
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        // The output arrives in several chunks of at most 64 KiB while parsing.
+        assert!(chunks.len() > 1);
+        assert!(chunks.iter().all(|c| !c.is_empty() && c.len() <= 64 * 1024));
+        let whole = jsonrepair_repair(input.as_ptr());
+        assert_eq!(chunks.concat(), c_str_to_string(whole).into_bytes());
+        jsonrepair_free(whole);
+
+        // A non-zero return stops the repair.
+        assert!(!jsonrepair_repair_streaming_output(
+            input.as_ptr(),
+            ptr::null(),
+            Some(refuse_chunks),
+            ptr::null_mut(),
+            &mut error,
+        ));
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        assert!(c_str_to_string(error.message).contains("write callback returned 1"));
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_repair_with_comments() {
+    unsafe {
+        let input =
+            CString::new("{\n  // who\n  name: 'John',\n  age: 30, /* \"years\" */\n}").unwrap();
+        let mut comments: *mut c_char = ptr::null_mut();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result =
+            jsonrepair_repair_with_comments(input.as_ptr(), ptr::null(), &mut comments, &mut error);
+        assert_eq!(c_str_to_string(result), r#"{"name":"John","age":30}"#);
+        assert_eq!(
+            c_str_to_string(comments),
+            r#"{"/name":"who","/age":"\"years\""}"#
+        );
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        jsonrepair_free(result);
+        jsonrepair_free(comments);
+    }
+}
+
+#[test]
+fn test_strictness() {
+    unsafe {
+        let input = CString::new("{a: b: c, d: 1}").unwrap();
+        let opts = jsonrepair_options_new();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        assert_eq!(error.position, 4);
+        if !error.message.is_null() {
+            jsonrepair_free(error.message);
+        }
+
+        jsonrepair_options_set_strictness(opts, JsonRepairStrictness::StrictnessAggressive as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":{"b":"c"},"d":1}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_brackets_as_objects() {
+    unsafe {
+        let input = CString::new("[a: 1, b: 2]").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_strictness(opts, JsonRepairStrictness::StrictnessAggressive as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[{"a":1},{"b":2}]"#);
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_brackets_as_objects(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":1,"b":2}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_max_key_len() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_key_len(opts, 3);
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        let input = CString::new(r#"{"ab": 1, "abcd": 2}"#).unwrap();
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::KeyTooLong);
+        assert_eq!(error.position, 10);
+        if !error.message.is_null() {
+            jsonrepair_free(error.message);
+        }
+
+        jsonrepair_options_set_long_keys(opts, JsonRepairLongKeys::LongKeysTruncate as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"ab":1,"abc":2}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_case_insensitive_keywords() {
+    unsafe {
+        let input = CString::new("[TRUE, False, Null, NULL]").unwrap();
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayQuote as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[true,false,null,null]");
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_case_insensitive_keywords(opts, false);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"["TRUE",false,"Null","NULL"]"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_stream_finalize() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        assert!(jsonrepair_stream_finalize(ptr::null_mut(), &mut error).is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        jsonrepair_free(error.message);
+
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_max_repairs(opts, 2);
+        let stream = jsonrepair_stream_new(opts);
+        jsonrepair_options_free(opts);
+        jsonrepair_stream_set_recover(stream, true);
+
+        for doc in ["{a: 1}\n[1, 2", "{b: 2"] {
+            let chunk = CString::new(doc).unwrap();
+            jsonrepair_free(jsonrepair_stream_push(stream, chunk.as_ptr()));
+            let result = jsonrepair_stream_finalize(stream, &mut error);
+            assert_eq!(error.code, JsonRepairErrorCode::Ok);
+            assert!(!result.is_null());
+            jsonrepair_free(result);
+            let stats = jsonrepair_stream_stats(stream);
+            assert_eq!((stats.values, stats.bytes_in), (0, 0));
+        }
+
+        // A tail skipped by recovery can still be taken after the reset.
+        let chunk = CString::new("{a b c d e").unwrap();
+        jsonrepair_free(jsonrepair_stream_push(stream, chunk.as_ptr()));
+        assert!(jsonrepair_stream_finalize(stream, &mut error).is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        let list = jsonrepair_stream_take_skipped(stream);
+        assert_eq!((*list).len, 1);
+        jsonrepair_value_status_list_free(list);
+
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_trace() {
+    unsafe {
+        let input = CString::new("[1 2]").unwrap();
+        let opts = jsonrepair_options_new();
+        assert!(jsonrepair_options_take_trace(opts).is_null());
+
+        jsonrepair_options_set_trace(opts, true);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1,2]");
+        jsonrepair_free(result);
+        let trace = jsonrepair_options_take_trace(opts);
+        assert_eq!(
+            c_str_to_string(trace),
+            "@0 value: array\n@1 value: number\n@3 repair: inserted missing comma\n\
+             @3 value: number\n@5 end of array\n"
+        );
+        jsonrepair_free(trace);
+        let trace = jsonrepair_options_take_trace(opts);
+        assert_eq!(c_str_to_string(trace), "");
+        jsonrepair_free(trace);
+
+        jsonrepair_options_set_trace(opts, false);
+        assert!(jsonrepair_options_take_trace(opts).is_null());
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_alt_quote_chars() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayQuote as u32);
+        let pairs = CString::new("«»「」").unwrap();
+        jsonrepair_options_set_alt_quote_chars(opts, pairs.as_ptr());
+        let input = CString::new("{«a»: 「x」, b: \"«kept»\"}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"a":"x","b":"«kept»"}"#);
+        jsonrepair_free(result);
+        // NULL clears the list.
+        jsonrepair_options_set_alt_quote_chars(opts, ptr::null());
+        let input = CString::new("[«a»]").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"["«a»"]"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_changed() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        for (input, want) in [("{\"a\": [1, 2]}", false), ("{a: [1, 2]}", true)] {
+            let mut changed = !want;
+            let c_input = CString::new(input).unwrap();
+            let result =
+                jsonrepair_repair_changed(c_input.as_ptr(), ptr::null(), &mut changed, &mut error);
+            assert_eq!(error.code, JsonRepairErrorCode::Ok);
+            assert_eq!(changed, want, "{input}");
+            jsonrepair_free(result);
+        }
+
+        let result =
+            jsonrepair_repair_changed(ptr::null(), ptr::null(), ptr::null_mut(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_stream_flush_closes_nested_truncation() {
+    unsafe {
+        let stream = jsonrepair_stream_new(ptr::null());
+        let chunk = CString::new(r#"[{"a":[{"b":"#).unwrap();
+        assert!(jsonrepair_stream_push(stream, chunk.as_ptr()).is_null());
+        let tail = jsonrepair_stream_flush(stream);
+        assert_eq!(c_str_to_string(tail), r#"[{"a":[{"b":""}]}]"#);
+        jsonrepair_free(tail);
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_lines_to_array() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_lines_to_array(opts, true);
+        jsonrepair_options_set_max_repairs(opts, 2);
+        jsonrepair_options_set_salvage(opts, JsonRepairSalvage::SalvageNull as u32);
+        let input = CString::new("{a: 1\n\n{x y z w v}\n[1, 2,\n").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[{"a":1},null,[1,2]]"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_line_continuations() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{\"a\": \"foo\\\nbar\"}").unwrap();
+        for (mode, want) in [
+            (
+                JsonRepairLineContinuations::ContinuationElide as u32,
+                r#"{"a":"foobar"}"#,
+            ),
+            (
+                JsonRepairLineContinuations::ContinuationKeep as u32,
+                r#"{"a":"foo\nbar"}"#,
+            ),
+        ] {
+            jsonrepair_options_set_line_continuations(opts, mode);
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_features() {
+    unsafe {
+        let ptr = jsonrepair_features();
+        assert!(!ptr.is_null());
+        // Built once: every call returns the same string.
+        assert_eq!(ptr, jsonrepair_features());
+        let v: serde_json::Value = serde_json::from_str(&c_str_to_string(ptr)).unwrap();
+        assert_eq!(v["version"], env!("CARGO_PKG_VERSION"));
+        assert_eq!(v["features"]["logging"], cfg!(feature = "logging"));
+        assert_eq!(v["features"]["c_api"], true);
+        assert_eq!(v["target"]["os"], std::env::consts::OS);
+        assert_eq!(v["target"]["wasm"], false);
+        assert!(v["max_depth"].is_null());
+        assert_eq!(v["unwrap_escaped_max_depth"], 8);
+    }
+}
+
+#[test]
+fn test_drop_placeholder() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayDrop as u32);
+        let input = CString::new("[1, junk, 2]").unwrap();
+        for (placeholder, want) in [
+            ("{ \"dropped\": true }", r#"[1,{"dropped":true},2]"#),
+            ("n/a", r#"[1,"n/a",2]"#),
+        ] {
+            let placeholder = CString::new(placeholder).unwrap();
+            jsonrepair_options_set_drop_placeholder(opts, placeholder.as_ptr());
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        // NULL clears it: the token is dropped again.
+        jsonrepair_options_set_drop_placeholder(opts, ptr::null());
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[1,2]");
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_raw_message_safe() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_output_bom(opts, true);
+        jsonrepair_options_set_raw_message_safe(opts, true);
+
+        let input = CString::new("  {\"a\": [1, 2]}\n").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"a\":[1,2]}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_utf8_strictness() {
+    unsafe {
+        // `C0 AF` is an overlong encoding of `/`.
+        let input = CString::new(b"{\"a\": \"..\xC0\xAF\"}".to_vec()).unwrap();
+        let opts = jsonrepair_options_new();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::InvalidUtf8);
+        assert_eq!(error.position, 9);
+        jsonrepair_free(error.message);
+
+        jsonrepair_options_set_utf8_strictness(opts, JsonRepairUtf8Strictness::Utf8Lenient as u32);
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), "{\"a\": \"..\u{FFFD}\u{FFFD}\"}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_set_utf8_strictness(opts, JsonRepairUtf8Strictness::Utf8Strict as u32);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert!(result.is_null());
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_bracket_aliases() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let begin = CString::new("BEGIN").unwrap();
+        let end = CString::new("END").unwrap();
+        jsonrepair_options_add_bracket_alias(
+            opts,
+            begin.as_ptr(),
+            end.as_ptr(),
+            JsonRepairBracketKind::BracketObject as u32,
+        );
+        jsonrepair_options_add_bracket_alias(
+            opts,
+            ptr::null(),
+            end.as_ptr(),
+            JsonRepairBracketKind::BracketArray as u32,
+        );
+
+        let input = CString::new("BEGIN name: 'BEGIN END', n: 1 END").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "{\"name\":\"BEGIN END\",\"n\":1}");
+        jsonrepair_free(result);
+
+        jsonrepair_options_clear_bracket_aliases(opts);
+        jsonrepair_options_set_stray_tokens(opts, JsonRepairStrayTokens::StrayQuote as u32);
+        let input = CString::new("[BEGIN, END]").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), "[\"BEGIN\",\"END\"]");
+        jsonrepair_free(result);
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_skeleton() {
+    unsafe {
+        let input = CString::new("{id: 1, tags: ['a', 'b'], extra: [1, 'x']}").unwrap();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result = jsonrepair_repair_skeleton(input.as_ptr(), ptr::null(), &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"id":"number","tags":["string"],"extra":["number","string"]}"#
+        );
+        jsonrepair_free(result);
+
+        let result = jsonrepair_repair_skeleton(ptr::null(), ptr::null(), &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_stream_heartbeat() {
+    unsafe {
+        let stream = jsonrepair_stream_new(ptr::null());
+        jsonrepair_stream_set_heartbeat(stream, 8);
+
+        let mut got = Vec::new();
+        for part in ["{a: 1}\n{b: '", "long text", " more'}\n"] {
+            let chunk = CString::new(part).unwrap();
+            jsonrepair_free(jsonrepair_stream_push(stream, chunk.as_ptr()));
+            let list = jsonrepair_stream_take_heartbeats(stream);
+            let items = std::slice::from_raw_parts((*list).items, (*list).len);
+            got.extend(items.iter().map(|h| (h.bytes_in, h.buffered)));
+            jsonrepair_heartbeat_list_free(list);
+        }
+        assert_eq!(got, [(21, 14)]);
+        assert!(jsonrepair_stream_take_heartbeats(ptr::null_mut()).is_null());
+
+        jsonrepair_stream_free(stream);
+    }
+}
+
+#[test]
+fn test_eval_fractions() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_eval_fractions(opts, true);
+        for (input, want) in [
+            ("{\"ratio\": 3/4}", "{\"ratio\":0.75}"),
+            ("[1/2, /usr/bin]", "[0.5,\"/usr/bin\"]"),
+        ] {
+            let input = CString::new(input).unwrap();
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_affected_paths() {
+    unsafe {
+        let input = CString::new(r#"{"a": 1, "items": [1, 2, {"name": 'x'}]}"#).unwrap();
+        let mut paths: *mut JsonRepairStringList = ptr::null_mut();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let result =
+            jsonrepair_repair_affected_paths(input.as_ptr(), ptr::null(), &mut paths, &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"a":1,"items":[1,2,{"name":"x"}]}"#
+        );
+        let items = std::slice::from_raw_parts((*paths).items, (*paths).len);
+        let got: Vec<String> = items.iter().map(|&p| c_str_to_string(p)).collect();
+        if cfg!(feature = "logging") {
+            assert_eq!(got, [r#"$["items"][2]["name"]"#]);
+        }
+        jsonrepair_string_list_free(paths);
+        jsonrepair_free(result);
+
+        let result = jsonrepair_repair_affected_paths(
+            input.as_ptr(),
+            ptr::null(),
+            ptr::null_mut(),
+            &mut error,
+        );
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        jsonrepair_free(error.message);
+    }
+}
+
+#[test]
+fn test_fix_backslashes() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_fix_backslashes(opts, true);
+        let input = CString::new(r#"{"dir": "C:\Windows\System32", "tab": "a\tb"}"#).unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"dir":"C:\\Windows\\System32","tab":"a\tb"}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_stop_after_first() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stop_after_first(opts, true);
+        let input = CString::new("{id: 1, op: 'ping'} {id: 2} [oops").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"{"id":1,"op":"ping"}"#);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_max_input_bytes() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let input = CString::new("{a: 1}").unwrap();
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+
+        // Just under the cap: repaired.
+        jsonrepair_options_set_max_input_bytes(opts, 7);
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        jsonrepair_free(result);
+
+        // Just over the cap: rejected before repairing.
+        jsonrepair_options_set_max_input_bytes(opts, 5);
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::InputTooLarge);
+        assert_eq!(error.position, 5);
+        if !error.message.is_null() {
+            jsonrepair_free(error.message);
+        }
+
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_abbreviated_keywords() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_abbreviated_keywords(opts, true);
+        let input = CString::new("{ok: t, err: n, tag: tf}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"ok":true,"err":null,"tag":"tf"}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_envelope() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_envelope(opts, true);
+        let cases = [
+            ("[1, 2]", r#"{"value":[1, 2],"repaired":false,"repairs":0}"#),
+            ("{a: 1}", r#"{"value":{"a":1},"repaired":true,"repairs":1}"#),
+        ];
+        for (input, want) in cases {
+            let input = CString::new(input).unwrap();
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), want);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_repair_with_end() {
+    unsafe {
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let mut end = usize::MAX;
+        let input = CString::new("{a: 1} {b: 2} <EOF>").unwrap();
+        let result = jsonrepair_repair_with_end(input.as_ptr(), ptr::null(), &mut end, &mut error);
+        assert_eq!(error.code, JsonRepairErrorCode::Ok);
+        assert_eq!(c_str_to_string(result), r#"[{"a":1},{"b":2}]"#);
+        assert_eq!(end, 13);
+        jsonrepair_free(result);
+
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_stop_after_first(opts, true);
+        let result = jsonrepair_repair_with_end(input.as_ptr(), opts, &mut end, &mut error);
+        assert_eq!(c_str_to_string(result), r#"{"a":1}"#);
+        assert_eq!(end, 6);
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+unsafe extern "C" fn format_ones(raw: *const c_char, _userdata: *mut c_void) -> *const c_char {
+    match unsafe { CStr::from_ptr(raw) }.to_bytes() {
+        b"0" => c"null".as_ptr(),
+        b"1" => c"1.0".as_ptr(),
+        _ => ptr::null(),
+    }
+}
+
+#[test]
+fn test_number_format_callback() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_number_format_callback(opts, Some(format_ones), ptr::null_mut());
+        let input = CString::new("[1, 2, '1']").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(c_str_to_string(result), r#"[1.0,2,"1"]"#);
+        jsonrepair_free(result);
+
+        // `null` is not a number, so the repair fails.
+        let mut error = JsonRepairError {
+            code: JsonRepairErrorCode::Ok,
+            message: ptr::null_mut(),
+            position: 0,
+        };
+        let input = CString::new("[0]").unwrap();
+        let result = jsonrepair_repair_ex(input.as_ptr(), opts, &mut error);
+        assert!(result.is_null());
+        assert_eq!(error.code, JsonRepairErrorCode::Parse);
+        let _ = CString::from_raw(error.message);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_null_tokens() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        let tokens = [c"N/A".as_ptr(), ptr::null(), c"-".as_ptr()];
+        jsonrepair_options_set_null_tokens(opts, tokens.as_ptr(), tokens.len());
+        let input = CString::new("{a: N/A, b: -, c: N/A yet}").unwrap();
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"a":null,"b":null,"c":"N/A yet"}"#
+        );
+        jsonrepair_free(result);
+
+        // NULL clears the list.
+        jsonrepair_options_set_null_tokens(opts, ptr::null(), 0);
+        let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+        assert_eq!(
+            c_str_to_string(result),
+            r#"{"a":"N/A","b":"","c":"N/A yet"}"#
+        );
+        jsonrepair_free(result);
+        jsonrepair_options_free(opts);
+    }
+}
+
+#[test]
+fn test_preset_storage() {
+    unsafe {
+        let opts = jsonrepair_options_new();
+        jsonrepair_options_set_ensure_ascii(opts, true);
+        jsonrepair_options_preset_storage(opts);
+        let inputs = [
+            "{\n  \"b\": 1.50E+03,\n  \"a\": \"caf\\u00e9\"\n}\n",
+            "{a: 'café', b: 1.5e3}",
+        ];
+        for input in inputs {
+            let input = CString::new(input).unwrap();
+            let result = jsonrepair_repair_with_options(input.as_ptr(), opts);
+            assert_eq!(c_str_to_string(result), r#"{"a":"café","b":1.5e3}"#);
+            jsonrepair_free(result);
+        }
+        jsonrepair_options_free(opts);
+    }
+}
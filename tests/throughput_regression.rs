@@ -0,0 +1,127 @@
+//! Throughput regression check against the floors in `benches/throughput_floors.txt`.
+//!
+//! Timing depends on the machine and build profile, so the test is ignored by default.
+//! Run it on an optimized build:
+//!
+//! ```text
+//! cargo test --release --features llm-compat --test throughput_regression -- --ignored --nocapture
+//! ```
+//!
+//! `JR_PERF_SCALE` multiplies every floor (e.g. `0.5` on a slow CI runner).
+
+use jsonrepair::options::EngineKind;
+use jsonrepair::{Options, repair_to_string};
+use std::time::{Duration, Instant};
+
+#[path = "../benches/corpus/mod.rs"]
+mod corpus;
+
+const FLOORS: &str = include_str!("../benches/throughput_floors.txt");
+
+// Best throughput in MiB/s over repeated runs of at least `budget` in total.
+fn best_mib_per_s(input: &str, opts: &Options, budget: Duration) -> f64 {
+    let started = Instant::now();
+    let mut best = Duration::MAX;
+    let mut runs = 0;
+    while runs < 3 || started.elapsed() < budget {
+        let t = Instant::now();
+        let out = repair_to_string(std::hint::black_box(input), opts).unwrap();
+        std::hint::black_box(out);
+        best = best.min(t.elapsed());
+        runs += 1;
+    }
+    input.len() as f64 / (1024.0 * 1024.0) / best.as_secs_f64()
+}
+
+#[test]
+#[ignore = "timing-sensitive; run on a release build with --ignored"]
+fn throughput_stays_above_floors() {
+    let scale = std::env::var("JR_PERF_SCALE")
+        .ok()
+        .and_then(|v| v.parse::<f64>().ok())
+        .unwrap_or(1.0);
+    let mut failures = Vec::new();
+    for line in FLOORS.lines() {
+        let line = line.trim();
+        if line.is_empty() || line.starts_with('#') {
+            continue;
+        }
+        let fields: Vec<&str> = line.split_whitespace().collect();
+        let [name, engine, floor, _baseline] = fields[..] else {
+            panic!("malformed floor line {line:?}");
+        };
+        let engine = match engine {
+            "recursive" => EngineKind::Recursive,
+            "llm" if cfg!(feature = "llm-compat") => EngineKind::LlmCompat,
+            "llm" => continue,
+            _ => panic!("unknown engine {engine:?}"),
+        };
+        let floor: f64 = floor.parse().unwrap();
+        let opts = corpus::options(engine);
+        let input = corpus::corpus(name, corpus::CORPUS_BYTES);
+        let got = best_mib_per_s(&input, &opts, Duration::from_millis(500));
+        println!("{name:<12} {engine:?}: {got:8.1} MiB/s (floor {floor})");
+        if got < floor * scale {
+            failures.push(format!(
+                "{name}/{engine:?}: {got:.1} MiB/s < {:.1}",
+                floor * scale
+            ));
+        }
+    }
+    assert!(failures.is_empty(), "throughput regressed: {failures:#?}");
+}
+
+#[test]
+fn corpora_are_deterministic_and_repairable() {
+    for name in corpus::NAMES {
+        let input = corpus::corpus(name, 64 * 1024);
+        assert!(input.len() >= 64 * 1024, "{name}");
+        assert_eq!(input, corpus::corpus(name, 64 * 1024), "{name}");
+        let out = repair_to_string(&input, &corpus::options(EngineKind::Auto)).unwrap();
+        assert!(
+            jsonrepair::minify(&out).is_ok(),
+            "{name} repairs to valid JSON"
+        );
+    }
+}
+
+// Arrays and objects nested `depth` levels deep, each ending in a trailing comma, the
+// `trailing` shape of `benches/nested_commas_bench.rs`.
+fn nested_trailing_commas(depth: usize) -> String {
+    let mut s = String::new();
+    for i in 0..depth {
+        s.push_str(if i % 2 == 0 { "[1, " } else { "{k: " });
+    }
+    s.push('0');
+    for i in (0..depth).rev() {
+        s.push_str(if i % 2 == 0 { ", 2,]" } else { ", b: 3,}" });
+    }
+    s
+}
+
+#[test]
+#[ignore = "timing-sensitive; run on a release build with --ignored"]
+fn nested_comma_repair_scales_linearly() {
+    // Four times the depth should take about four times as long; a quadratic pass would
+    // take sixteen.
+    for (engine, enabled) in [
+        (EngineKind::Recursive, true),
+        (EngineKind::LlmCompat, cfg!(feature = "llm-compat")),
+    ] {
+        if !enabled {
+            continue;
+        }
+        let opts = Options {
+            engine,
+            ..Options::default()
+        };
+        let budget = Duration::from_millis(300);
+        let small = best_mib_per_s(&nested_trailing_commas(8_000), &opts, budget);
+        let large = best_mib_per_s(&nested_trailing_commas(32_000), &opts, budget);
+        println!("nested commas {engine:?}: {small:8.1} -> {large:8.1} MiB/s");
+        assert!(
+            large > small / 2.0,
+            "{engine:?}: {small:.1} MiB/s at depth 8000 but {large:.1} at 32000"
+        );
+    }
+}